@@ -6,10 +6,12 @@ package spanmetricsconnector // import "github.com/open-telemetry/opentelemetry-
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/collector/confmap/xconfmap"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metrics"
 )
@@ -29,6 +31,10 @@ var defaultDeltaTimestampCacheSize = 1000
 type Dimension struct {
 	Name    string  `mapstructure:"name"`
 	Default *string `mapstructure:"default"`
+	// Hash replaces the value of the dimension by its hash, computed with either
+	// the sha256 or fnv function, e.g. to analyze the cardinality of a user id
+	// without exposing it in the metrics. Optional, the value is kept by default.
+	Hash string `mapstructure:"hash"`
 	// prevent unkeyed literal initialization
 	_ struct{}
 }
@@ -91,7 +97,24 @@ type Config struct {
 
 	IncludeInstrumentationScope []string `mapstructure:"include_instrumentation_scope"`
 
+	// InstrumentationScopeAttributes is the list of instrumentation scope attributes added as dimensions to
+	// the metrics, e.g. ["telemetry.sdk.language"]. Attributes missing from the scope of a span are omitted.
+	// Optional. No scope attribute is added when empty.
+	InstrumentationScopeAttributes []string `mapstructure:"instrumentation_scope_attributes"`
+
+	// IncludeSpanKinds restricts the generation of metrics to the spans of the listed kinds, e.g. ["SPAN_KIND_SERVER", "SPAN_KIND_CONSUMER"].
+	// The "SPAN_KIND_" prefix is optional and the kinds are case-insensitive. Spans of other kinds skip aggregation entirely.
+	// Optional. Metrics are generated for spans of all kinds when empty.
+	IncludeSpanKinds []string `mapstructure:"include_span_kinds"`
+
 	AggregationCardinalityLimit int `mapstructure:"aggregation_cardinality_limit"`
+
+	// Debug defines the configuration for the debug handler exposing the active series.
+	Debug DebugConfig `mapstructure:"debug"`
+
+	// SortOutput emits the resource metrics and their data points sorted by key on each flush, so
+	// the output is the same for the same spans. Sorting costs CPU with a high cardinality.
+	SortOutput bool `mapstructure:"sort_output"`
 }
 
 type HistogramConfig struct {
@@ -133,6 +156,17 @@ type EventsConfig struct {
 	_ struct{}
 }
 
+type DebugConfig struct {
+	// Enabled registers a handler dumping the active series keys, their last seen time and the cache
+	// statistics with an extension able to serve it, to help debugging cardinality issues.
+	Enabled bool `mapstructure:"enabled"`
+	// Path is the path the handler is registered at.
+	// Optional. See defaultDebugPath in debug.go for the default value.
+	Path string `mapstructure:"path"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
 var _ xconfmap.Validator = (*Config)(nil)
 
 // Validate checks if the processor configuration is valid
@@ -143,6 +177,9 @@ func (c Config) Validate() error {
 	if err := validateEventDimensions(c.Events.Enabled, c.Events.Dimensions); err != nil {
 		return fmt.Errorf("failed validating event dimensions: %w", err)
 	}
+	if err := validateDimensionHashes(c.Dimensions, c.CallsDimensions, c.Histogram.Dimensions, c.Events.Dimensions); err != nil {
+		return fmt.Errorf("failed validating dimensions: %w", err)
+	}
 
 	if c.Histogram.Explicit != nil && c.Histogram.Exponential != nil {
 		return errors.New("use either `explicit` or `exponential` buckets histogram")
@@ -163,6 +200,16 @@ func (c Config) Validate() error {
 		)
 	}
 
+	if c.Debug.Path != "" && !strings.HasPrefix(c.Debug.Path, "/") {
+		return fmt.Errorf("invalid debug path: %q, the path should start with '/'", c.Debug.Path)
+	}
+
+	for _, kind := range c.IncludeSpanKinds {
+		if _, ok := parseSpanKind(kind); !ok {
+			return fmt.Errorf("invalid include_span_kinds: %q is not a span kind", kind)
+		}
+	}
+
 	if c.AggregationCardinalityLimit < 0 {
 		return fmt.Errorf("invalid aggregation_cardinality_limit: %v, the limit should be positive", c.AggregationCardinalityLimit)
 	}
@@ -179,6 +226,28 @@ func (c Config) GetAggregationTemporality() pmetric.AggregationTemporality {
 	return pmetric.AggregationTemporalityCumulative
 }
 
+// GetDebugPath returns the path the debug handler is registered at.
+func (c Config) GetDebugPath() string {
+	if c.Debug.Path != "" {
+		return c.Debug.Path
+	}
+	return defaultDebugPath
+}
+
+// GetIncludedSpanKinds returns the span kinds metrics are generated for, or nil if metrics are generated for all span kinds.
+func (c Config) GetIncludedSpanKinds() map[ptrace.SpanKind]struct{} {
+	if len(c.IncludeSpanKinds) == 0 {
+		return nil
+	}
+	kinds := make(map[ptrace.SpanKind]struct{}, len(c.IncludeSpanKinds))
+	for _, kind := range c.IncludeSpanKinds {
+		if k, ok := parseSpanKind(kind); ok {
+			kinds[k] = struct{}{}
+		}
+	}
+	return kinds
+}
+
 func (c Config) GetDeltaTimestampCacheSize() int {
 	if c.TimestampCacheSize != nil {
 		return *c.TimestampCacheSize
@@ -186,6 +255,26 @@ func (c Config) GetDeltaTimestampCacheSize() int {
 	return defaultDeltaTimestampCacheSize
 }
 
+// parseSpanKind parses a span kind such as "SPAN_KIND_SERVER" or "server".
+func parseSpanKind(kind string) (ptrace.SpanKind, bool) {
+	switch strings.TrimPrefix(strings.ToUpper(kind), "SPAN_KIND_") {
+	case "UNSPECIFIED":
+		return ptrace.SpanKindUnspecified, true
+	case "INTERNAL":
+		return ptrace.SpanKindInternal, true
+	case "SERVER":
+		return ptrace.SpanKindServer, true
+	case "CLIENT":
+		return ptrace.SpanKindClient, true
+	case "PRODUCER":
+		return ptrace.SpanKindProducer, true
+	case "CONSUMER":
+		return ptrace.SpanKindConsumer, true
+	default:
+		return ptrace.SpanKindUnspecified, false
+	}
+}
+
 // validateDimensions checks duplicates for reserved dimensions and additional dimensions.
 func validateDimensions(dimensions []Dimension) error {
 	labelNames := make(map[string]struct{})
@@ -213,3 +302,23 @@ func validateEventDimensions(enabled bool, dimensions []Dimension) error {
 	}
 	return validateDimensions(dimensions)
 }
+
+// validateDimensionHashes checks the hash functions of the dimensions are supported, and that a dimension
+// configured in several lists is hashed the same way in all of them.
+func validateDimensionHashes(dimensionLists ...[]Dimension) error {
+	hashes := make(map[string]string)
+	for _, dimensions := range dimensionLists {
+		for _, d := range dimensions {
+			switch d.Hash {
+			case "", hashSHA256, hashFNV:
+			default:
+				return fmt.Errorf("unsupported hash %q for dimension %s, use either %q or %q", d.Hash, d.Name, hashSHA256, hashFNV)
+			}
+			if hash, ok := hashes[d.Name]; ok && hash != d.Hash {
+				return fmt.Errorf("dimension %s is configured with different hashes", d.Name)
+			}
+			hashes[d.Name] = d.Hash
+		}
+	}
+	return nil
+}
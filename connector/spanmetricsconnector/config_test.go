@@ -16,6 +16,7 @@ import (
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.opentelemetry.io/collector/confmap/xconfmap"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metrics"
@@ -174,6 +175,58 @@ func TestLoadConfig(t *testing.T) {
 				Namespace:                DefaultNamespace,
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "debug"),
+			expected: &Config{
+				AggregationTemporality:   "AGGREGATION_TEMPORALITY_CUMULATIVE",
+				ResourceMetricsCacheSize: defaultResourceMetricsCacheSize,
+				MetricsFlushInterval:     60 * time.Second,
+				Histogram:                HistogramConfig{Disable: false, Unit: defaultUnit},
+				Namespace:                DefaultNamespace,
+				Debug:                    DebugConfig{Enabled: true, Path: "/debug/spanmetrics/traces"},
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_debug_path"),
+			errorMessage: "invalid debug path: \"debug\", the path should start with '/'",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "include_span_kinds"),
+			expected: &Config{
+				AggregationTemporality:   "AGGREGATION_TEMPORALITY_CUMULATIVE",
+				ResourceMetricsCacheSize: defaultResourceMetricsCacheSize,
+				MetricsFlushInterval:     60 * time.Second,
+				Histogram:                HistogramConfig{Disable: false, Unit: defaultUnit},
+				Namespace:                DefaultNamespace,
+				IncludeSpanKinds:         []string{"SPAN_KIND_SERVER", "consumer"},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "sort_output"),
+			expected: &Config{
+				AggregationTemporality:   "AGGREGATION_TEMPORALITY_CUMULATIVE",
+				ResourceMetricsCacheSize: defaultResourceMetricsCacheSize,
+				MetricsFlushInterval:     60 * time.Second,
+				Histogram:                HistogramConfig{Disable: false, Unit: defaultUnit},
+				Namespace:                DefaultNamespace,
+				SortOutput:               true,
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "invalid_include_span_kinds"),
+			errorMessage: "invalid include_span_kinds: \"REMOTE\" is not a span kind",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "instrumentation_scope_attributes"),
+			expected: &Config{
+				AggregationTemporality:         "AGGREGATION_TEMPORALITY_CUMULATIVE",
+				ResourceMetricsCacheSize:       defaultResourceMetricsCacheSize,
+				MetricsFlushInterval:           60 * time.Second,
+				Histogram:                      HistogramConfig{Disable: false, Unit: defaultUnit},
+				Namespace:                      DefaultNamespace,
+				InstrumentationScopeAttributes: []string{"telemetry.sdk.language", "telemetry.sdk.name"},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -379,6 +432,47 @@ func TestConfigValidate(t *testing.T) {
 			},
 			expectedErr: "failed validating dimensions: duplicate dimension name service.name",
 		},
+		{
+			name: "hashed dimensions",
+			config: Config{
+				ResourceMetricsCacheSize: 1000,
+				MetricsFlushInterval:     60 * time.Second,
+				Dimensions: []Dimension{
+					{Name: "user.id", Hash: "sha256"},
+					{Name: "session.id", Hash: "fnv"},
+				},
+				CallsDimensions: []Dimension{
+					{Name: "user.id", Hash: "sha256"},
+				},
+			},
+		},
+		{
+			name: "unsupported dimension hash",
+			config: Config{
+				ResourceMetricsCacheSize: 1000,
+				MetricsFlushInterval:     60 * time.Second,
+				Dimensions: []Dimension{
+					{Name: "user.id", Hash: "md5"},
+				},
+			},
+			expectedErr: `failed validating dimensions: unsupported hash "md5" for dimension user.id, use either "sha256" or "fnv"`,
+		},
+		{
+			name: "dimension hashed differently",
+			config: Config{
+				ResourceMetricsCacheSize: 1000,
+				MetricsFlushInterval:     60 * time.Second,
+				Dimensions: []Dimension{
+					{Name: "user.id", Hash: "sha256"},
+				},
+				Histogram: HistogramConfig{
+					Dimensions: []Dimension{
+						{Name: "user.id"},
+					},
+				},
+			},
+			expectedErr: "failed validating dimensions: dimension user.id is configured with different hashes",
+		},
 		{
 			name: "events enabled with no dimensions",
 			config: Config{
@@ -403,3 +497,15 @@ func TestConfigValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestGetIncludedSpanKinds(t *testing.T) {
+	cfg := Config{}
+	assert.Nil(t, cfg.GetIncludedSpanKinds())
+
+	cfg.IncludeSpanKinds = []string{"SPAN_KIND_SERVER", "consumer", "Client"}
+	assert.Equal(t, map[ptrace.SpanKind]struct{}{
+		ptrace.SpanKindServer:   {},
+		ptrace.SpanKindConsumer: {},
+		ptrace.SpanKindClient:   {},
+	}, cfg.GetIncludedSpanKinds())
+}
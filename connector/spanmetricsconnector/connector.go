@@ -6,6 +6,7 @@ package spanmetricsconnector // import "github.com/open-telemetry/opentelemetry-
 import (
 	"bytes"
 	"context"
+	"slices"
 	"sync"
 	"time"
 
@@ -21,6 +22,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/cache"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metrics"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/coreinternal/traceutil"
 	utilattri "github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdatautil"
@@ -53,11 +55,16 @@ type connectorImp struct {
 	logger *zap.Logger
 	config Config
 
+	telemetryBuilder *metadata.TelemetryBuilder
+
 	metricsConsumer consumer.Metrics
 
 	// Additional dimensions to add to metrics.
 	dimensions []utilattri.Dimension
 
+	// Hash function of each hashed dimension, nil if no dimension is hashed.
+	dimensionHashes map[string]string
+
 	resourceMetrics *cache.Cache[resourceKey, *resourceMetrics]
 
 	resourceMetricsKeyAttributes map[string]struct{}
@@ -82,6 +89,9 @@ type connectorImp struct {
 
 	events EventsConfig
 
+	// Span kinds metrics are generated for, nil if metrics are generated for all span kinds.
+	includedSpanKinds map[ptrace.SpanKind]struct{}
+
 	// Tracks the last TimestampUnixNano for delta metrics so that they represent an uninterrupted series. Unused for cumulative span metrics.
 	lastDeltaTimestamps *simplelru.LRU[metrics.Key, pcommon.Timestamp]
 }
@@ -93,6 +103,8 @@ type resourceMetrics struct {
 	attributes pcommon.Map
 	// lastSeen captures when the last data points for this resource were recorded.
 	lastSeen time.Time
+	// seriesLastSeen captures when each series was last updated, only tracked when the debug handler is enabled.
+	seriesLastSeen map[debugSeriesKey]time.Time
 }
 
 func newDimensions(cfgDims []Dimension) []utilattri.Dimension {
@@ -110,7 +122,8 @@ func newDimensions(cfgDims []Dimension) []utilattri.Dimension {
 	return dims
 }
 
-func newConnector(logger *zap.Logger, config component.Config, clock clockwork.Clock) (*connectorImp, error) {
+func newConnector(set component.TelemetrySettings, config component.Config, clock clockwork.Clock) (*connectorImp, error) {
+	logger := set.Logger
 	logger.Info("Building spanmetrics connector")
 	cfg := config.(*Config)
 	if cfg.DimensionsCacheSize != 0 {
@@ -138,12 +151,19 @@ func newConnector(logger *zap.Logger, config component.Config, clock clockwork.C
 		}
 	}
 
+	telemetryBuilder, err := metadata.NewTelemetryBuilder(set)
+	if err != nil {
+		return nil, err
+	}
+
 	return &connectorImp{
 		logger:                       logger,
 		config:                       *cfg,
+		telemetryBuilder:             telemetryBuilder,
 		resourceMetrics:              resourceMetricsCache,
 		resourceMetricsKeyAttributes: resourceMetricsKeyAttributes,
 		dimensions:                   newDimensions(cfg.Dimensions),
+		dimensionHashes:              newDimensionHashes(cfg.Dimensions, cfg.CallsDimensions, cfg.Histogram.Dimensions, cfg.Events.Dimensions),
 		keyBuf:                       bytes.NewBuffer(make([]byte, 0, 1024)),
 		lastDeltaTimestamps:          lastDeltaTimestamps,
 		clock:                        clock,
@@ -153,6 +173,7 @@ func newConnector(logger *zap.Logger, config component.Config, clock clockwork.C
 		callsDimensions:              newDimensions(cfg.CallsDimensions),
 		durationDimensions:           newDimensions(cfg.Histogram.Dimensions),
 		events:                       cfg.Events,
+		includedSpanKinds:            cfg.GetIncludedSpanKinds(),
 	}, nil
 }
 
@@ -203,9 +224,13 @@ func durationsToUnits(vs []time.Duration, unitDivider int64) []float64 {
 }
 
 // Start implements the component.Component interface.
-func (p *connectorImp) Start(ctx context.Context, _ component.Host) error {
+func (p *connectorImp) Start(ctx context.Context, host component.Host) error {
 	p.logger.Info("Starting spanmetrics connector")
 
+	if p.config.Debug.Enabled {
+		p.registerDebugHandler(host)
+	}
+
 	p.started = true
 	go func() {
 		for {
@@ -231,6 +256,7 @@ func (p *connectorImp) Shutdown(context.Context) error {
 			p.done <- struct{}{}
 			p.started = false
 		}
+		p.telemetryBuilder.Shutdown()
 	})
 	return nil
 }
@@ -242,10 +268,13 @@ func (*connectorImp) Capabilities() consumer.Capabilities {
 
 // ConsumeTraces implements the consumer.Traces interface.
 // It aggregates the trace data to generate metrics.
-func (p *connectorImp) ConsumeTraces(_ context.Context, traces ptrace.Traces) error {
+func (p *connectorImp) ConsumeTraces(ctx context.Context, traces ptrace.Traces) error {
 	p.lock.Lock()
-	p.aggregateMetrics(traces)
+	skipped := p.aggregateMetrics(traces)
 	p.lock.Unlock()
+	if skipped > 0 {
+		p.telemetryBuilder.ConnectorSpanmetricsSkippedSpans.Add(ctx, skipped)
+	}
 	return nil
 }
 
@@ -269,7 +298,8 @@ func (p *connectorImp) buildMetrics() pmetric.Metrics {
 	m := pmetric.NewMetrics()
 	timestamp := pcommon.NewTimestampFromTime(p.clock.Now())
 
-	p.resourceMetrics.ForEach(func(_ resourceKey, rawMetrics *resourceMetrics) {
+	sorted := p.config.SortOutput
+	p.forEachResourceMetrics(sorted, func(rawMetrics *resourceMetrics) {
 		rm := m.ResourceMetrics().AppendEmpty()
 		rawMetrics.attributes.CopyTo(rm.Resource().Attributes())
 
@@ -301,21 +331,21 @@ func (p *connectorImp) buildMetrics() pmetric.Metrics {
 		sums := rawMetrics.sums
 		metric := sm.Metrics().AppendEmpty()
 		metric.SetName(buildMetricName(metricsNamespace, metricNameCalls))
-		sums.BuildMetrics(metric, timestamp, timeStampGenerator, p.config.GetAggregationTemporality())
+		sums.BuildMetrics(metric, timestamp, timeStampGenerator, p.config.GetAggregationTemporality(), sorted)
 
 		if !p.config.Histogram.Disable {
 			histograms := rawMetrics.histograms
 			metric = sm.Metrics().AppendEmpty()
 			metric.SetName(buildMetricName(metricsNamespace, metricNameDuration))
 			metric.SetUnit(p.config.Histogram.Unit.String())
-			histograms.BuildMetrics(metric, timestamp, timeStampGenerator, p.config.GetAggregationTemporality())
+			histograms.BuildMetrics(metric, timestamp, timeStampGenerator, p.config.GetAggregationTemporality(), sorted)
 		}
 
 		events := rawMetrics.events
 		if p.events.Enabled {
 			metric = sm.Metrics().AppendEmpty()
 			metric.SetName(buildMetricName(metricsNamespace, metricNameEvents))
-			events.BuildMetrics(metric, timestamp, timeStampGenerator, p.config.GetAggregationTemporality())
+			events.BuildMetrics(metric, timestamp, timeStampGenerator, p.config.GetAggregationTemporality(), sorted)
 		}
 
 		for mk := range deltaMetricKeys {
@@ -327,6 +357,32 @@ func (p *connectorImp) buildMetrics() pmetric.Metrics {
 	return m
 }
 
+// forEachResourceMetrics calls fn for each of the resources, in the order of their
+// keys when sorted so that the resource metrics are built in a deterministic order.
+func (p *connectorImp) forEachResourceMetrics(sorted bool, fn func(*resourceMetrics)) {
+	if !sorted {
+		p.resourceMetrics.ForEach(func(_ resourceKey, rawMetrics *resourceMetrics) {
+			fn(rawMetrics)
+		})
+		return
+	}
+
+	type entry struct {
+		key        resourceKey
+		rawMetrics *resourceMetrics
+	}
+	var entries []entry
+	p.resourceMetrics.ForEach(func(k resourceKey, rawMetrics *resourceMetrics) {
+		entries = append(entries, entry{key: k, rawMetrics: rawMetrics})
+	})
+	slices.SortFunc(entries, func(a, b entry) int {
+		return bytes.Compare(a.key[:], b.key[:])
+	})
+	for _, e := range entries {
+		fn(e.rawMetrics)
+	}
+}
+
 func (p *connectorImp) resetState() {
 	// If delta metrics, reset accumulated data
 	if p.config.GetAggregationTemporality() == pmetric.AggregationTemporalityDelta {
@@ -367,8 +423,13 @@ func (p *connectorImp) resetState() {
 // Each metric is identified by a key that is built from the service name
 // and span metadata such as name, kind, status_code and any additional
 // dimensions the user has configured.
-func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
-	startTimestamp := pcommon.NewTimestampFromTime(p.clock.Now())
+//
+// Spans of the kinds metrics are not generated for are skipped before
+// any key is built, the number of skipped spans is returned.
+func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) int64 {
+	var skipped int64
+	now := p.clock.Now()
+	startTimestamp := pcommon.NewTimestampFromTime(now)
 	for i := 0; i < traces.ResourceSpans().Len(); i++ {
 		rspans := traces.ResourceSpans().At(i)
 		resourceAttr := rspans.Resource().Attributes()
@@ -377,10 +438,9 @@ func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
 			continue
 		}
 
-		rm := p.getOrCreateResourceMetrics(resourceAttr)
-		sums := rm.sums
-		histograms := rm.histograms
-		events := rm.events
+		// the resource metrics are only created once a span is aggregated, so
+		// that resources with only skipped spans do not produce empty metrics
+		var rm *resourceMetrics
 
 		unitDivider := unitDivider(p.config.Histogram.Unit)
 		serviceName := serviceAttr.Str()
@@ -390,6 +450,17 @@ func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
 			spans := ils.Spans()
 			for k := 0; k < spans.Len(); k++ {
 				span := spans.At(k)
+				if !p.isSpanKindIncluded(span.Kind()) {
+					skipped++
+					continue
+				}
+				if rm == nil {
+					rm = p.getOrCreateResourceMetrics(resourceAttr)
+				}
+				sums := rm.sums
+				histograms := rm.histograms
+				events := rm.events
+
 				// Protect against end timestamps before start timestamps. Assume 0 duration.
 				duration := float64(0)
 				startTime := span.StartTimestamp()
@@ -400,7 +471,7 @@ func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
 
 				callsDimensions := p.dimensions
 				callsDimensions = append(callsDimensions, p.callsDimensions...)
-				key := p.buildKey(serviceName, span, callsDimensions, resourceAttr)
+				key := p.buildKey(serviceName, span, callsDimensions, resourceAttr, ils.Scope())
 				attributesFun := func() pcommon.Map {
 					return p.buildAttributes(serviceName, span, resourceAttr, callsDimensions, ils.Scope())
 				}
@@ -411,12 +482,13 @@ func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
 					s.AddExemplar(span.TraceID(), span.SpanID(), duration)
 				}
 				s.Add(1)
+				p.recordSeen(rm, metricNameCalls, key, limitReached, now)
 
 				// aggregate histogram metrics
 				if !p.config.Histogram.Disable {
 					durationDimensions := p.dimensions
 					durationDimensions = append(durationDimensions, p.durationDimensions...)
-					durationKey := p.buildKey(serviceName, span, durationDimensions, resourceAttr)
+					durationKey := p.buildKey(serviceName, span, durationDimensions, resourceAttr, ils.Scope())
 					attributesFun = func() pcommon.Map {
 						return p.buildAttributes(serviceName, span, resourceAttr, durationDimensions, ils.Scope())
 					}
@@ -425,6 +497,7 @@ func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
 						p.addExemplar(span, duration, h)
 					}
 					h.Observe(duration)
+					p.recordSeen(rm, metricNameDuration, durationKey, durationLimitReached, now)
 				}
 
 				// aggregate events metrics
@@ -444,7 +517,7 @@ func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
 							return true
 						})
 
-						eKey := p.buildKey(serviceName, span, eDimensions, rscAndEventAttrs)
+						eKey := p.buildKey(serviceName, span, eDimensions, rscAndEventAttrs, ils.Scope())
 						attributesFun = func() pcommon.Map {
 							return p.buildAttributes(serviceName, span, rscAndEventAttrs, eDimensions, ils.Scope())
 						}
@@ -453,11 +526,22 @@ func (p *connectorImp) aggregateMetrics(traces ptrace.Traces) {
 							e.AddExemplar(span.TraceID(), span.SpanID(), duration)
 						}
 						e.Add(1)
+						p.recordSeen(rm, metricNameEvents, eKey, eventLimitReached, now)
 					}
 				}
 			}
 		}
 	}
+	return skipped
+}
+
+// isSpanKindIncluded returns true if metrics are generated for spans of the given kind.
+func (p *connectorImp) isSpanKindIncluded(kind ptrace.SpanKind) bool {
+	if p.includedSpanKinds == nil {
+		return true
+	}
+	_, ok := p.includedSpanKinds[kind]
+	return ok
 }
 
 func (p *connectorImp) addExemplar(span ptrace.Span, duration float64, h metrics.Histogram) {
@@ -496,6 +580,9 @@ func (p *connectorImp) getOrCreateResourceMetrics(attr pcommon.Map) *resourceMet
 			events:     metrics.NewSumMetrics(p.config.Exemplars.MaxPerDataPoint, p.config.AggregationCardinalityLimit),
 			attributes: attr,
 		}
+		if p.config.Debug.Enabled {
+			v.seriesLastSeen = make(map[debugSeriesKey]time.Time)
+		}
 		p.resourceMetrics.Add(key, v)
 	}
 
@@ -525,7 +612,7 @@ func (p *connectorImp) buildAttributes(
 	instrumentationScope pcommon.InstrumentationScope,
 ) pcommon.Map {
 	attr := pcommon.NewMap()
-	attr.EnsureCapacity(4 + len(dimensions))
+	attr.EnsureCapacity(4 + len(dimensions) + len(p.config.InstrumentationScopeAttributes))
 	if !contains(p.config.ExcludeDimensions, serviceNameKey) {
 		attr.PutStr(serviceNameKey, serviceName)
 	}
@@ -546,14 +633,24 @@ func (p *connectorImp) buildAttributes(
 		}
 	}
 
-	addResourceAttributes(&attr, dimensions, span, resourceAttrs)
+	for _, name := range p.config.InstrumentationScopeAttributes {
+		if v, ok := instrumentationScope.Attributes().Get(name); ok {
+			v.CopyTo(attr.PutEmpty(name))
+		}
+	}
+
+	addResourceAttributes(&attr, dimensions, p.dimensionHashes, span, resourceAttrs)
 
 	return attr
 }
 
-func addResourceAttributes(attrs *pcommon.Map, dimensions []utilattri.Dimension, span ptrace.Span, resourceAttrs pcommon.Map) {
+func addResourceAttributes(attrs *pcommon.Map, dimensions []utilattri.Dimension, hashes map[string]string, span ptrace.Span, resourceAttrs pcommon.Map) {
 	for _, d := range dimensions {
-		if v, ok := utilattri.GetDimensionValue(d, span.Attributes(), resourceAttrs); ok {
+		if v, ok := getDimensionValue(d, span.Attributes(), resourceAttrs); ok {
+			if hash, hashed := hashes[d.Name]; hashed {
+				attrs.PutStr(d.Name, hashDimensionValue(hash, v.AsString()))
+				continue
+			}
 			v.CopyTo(attrs.PutEmpty(d.Name))
 		}
 	}
@@ -569,9 +666,17 @@ func concatDimensionValue(dest *bytes.Buffer, value string, prefixSep bool) {
 // buildKey builds the metric key from the service name and span metadata such as name, kind, status_code and
 // will attempt to add any additional dimensions the user has configured that match the span's attributes
 // or resource/event attributes. If the dimension exists in both, the span's attributes, being the most specific, takes precedence.
+// The values of hashed dimensions are replaced by their hash, so that the keys exposed by the debug handler do not hold them.
+// The configured instrumentation scope attributes found in the scope of the span are added last.
 //
 // The metric key is a simple concatenation of dimension values, delimited by a null character.
-func (p *connectorImp) buildKey(serviceName string, span ptrace.Span, optionalDims []utilattri.Dimension, resourceOrEventAttrs pcommon.Map) metrics.Key {
+func (p *connectorImp) buildKey(
+	serviceName string,
+	span ptrace.Span,
+	optionalDims []utilattri.Dimension,
+	resourceOrEventAttrs pcommon.Map,
+	instrumentationScope pcommon.InstrumentationScope,
+) metrics.Key {
 	p.keyBuf.Reset()
 
 	if !contains(p.config.ExcludeDimensions, serviceNameKey) {
@@ -588,7 +693,17 @@ func (p *connectorImp) buildKey(serviceName string, span ptrace.Span, optionalDi
 	}
 
 	for _, d := range optionalDims {
-		if v, ok := utilattri.GetDimensionValue(d, span.Attributes(), resourceOrEventAttrs); ok {
+		if v, ok := getDimensionValue(d, span.Attributes(), resourceOrEventAttrs); ok {
+			value := v.AsString()
+			if hash, hashed := p.dimensionHashes[d.Name]; hashed {
+				value = hashDimensionValue(hash, value)
+			}
+			concatDimensionValue(p.keyBuf, value, true)
+		}
+	}
+
+	for _, name := range p.config.InstrumentationScopeAttributes {
+		if v, ok := instrumentationScope.Attributes().Get(name); ok {
 			concatDimensionValue(p.keyBuf, v.AsString(), true)
 		}
 	}
@@ -15,6 +15,7 @@ import (
 	"github.com/lightstep/go-expohisto/structure"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/connector/connectortest"
 	"go.opentelemetry.io/collector/consumer"
@@ -23,6 +24,8 @@ import (
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
 	conventions "go.opentelemetry.io/otel/semconv/v1.27.0"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -30,6 +33,7 @@ import (
 	"go.uber.org/zap/zaptest/observer"
 	"google.golang.org/grpc/metadata"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metadatatest"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metrics"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdatautil"
 )
@@ -458,6 +462,13 @@ func disabledHistogramsConfig() HistogramConfig {
 	}
 }
 
+// newTestTelemetrySettings returns nop telemetry settings logging to the test output.
+func newTestTelemetrySettings(t *testing.T) component.TelemetrySettings {
+	set := componenttest.NewNopTelemetrySettings()
+	set.Logger = zaptest.NewLogger(t)
+	return set
+}
+
 func newConnectorImp(defaultNullValue *string, histogramConfig func() HistogramConfig, exemplarsConfig func() ExemplarsConfig, eventsConfig func() EventsConfig, temporality string, expiration time.Duration, resourceMetricsKeyAttributes []string, deltaTimestampCacheSize int, clock clockwork.Clock, excludedDimensions ...string) (*connectorImp, error) {
 	cfg := &Config{
 		AggregationTemporality:       temporality,
@@ -488,7 +499,7 @@ func newConnectorImp(defaultNullValue *string, histogramConfig func() HistogramC
 		MetricsFlushInterval: time.Nanosecond,
 	}
 
-	c, err := newConnector(zap.NewNop(), cfg, clock)
+	c, err := newConnector(componenttest.NewNopTelemetrySettings(), cfg, clock)
 	if err != nil {
 		return nil, err
 	}
@@ -503,16 +514,16 @@ func stringp(str string) *string {
 func TestBuildKeySameServiceNameCharSequence(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 
 	span0 := ptrace.NewSpan()
 	span0.SetName("c")
-	k0 := c.buildKey("ab", span0, nil, pcommon.NewMap())
+	k0 := c.buildKey("ab", span0, nil, pcommon.NewMap(), pcommon.NewInstrumentationScope())
 
 	span1 := ptrace.NewSpan()
 	span1.SetName("bc")
-	k1 := c.buildKey("a", span1, nil, pcommon.NewMap())
+	k1 := c.buildKey("a", span1, nil, pcommon.NewMap(), pcommon.NewInstrumentationScope())
 
 	assert.NotEqual(t, k0, k1)
 	assert.Equal(t, metrics.Key("ab\u0000c\u0000SPAN_KIND_UNSPECIFIED\u0000STATUS_CODE_UNSET"), k0)
@@ -523,12 +534,12 @@ func TestBuildKeyExcludeDimensionsAll(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.ExcludeDimensions = []string{"span.kind", "service.name", "span.name", "status.code"}
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 
 	span0 := ptrace.NewSpan()
 	span0.SetName("spanName")
-	k0 := c.buildKey("serviceName", span0, nil, pcommon.NewMap())
+	k0 := c.buildKey("serviceName", span0, nil, pcommon.NewMap(), pcommon.NewInstrumentationScope())
 	assert.Equal(t, metrics.Key(""), k0)
 }
 
@@ -536,19 +547,37 @@ func TestBuildKeyExcludeWrongDimensions(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
 	cfg.ExcludeDimensions = []string{"span.kind", "service.name.wrong.name", "span.name", "status.code"}
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 
 	span0 := ptrace.NewSpan()
 	span0.SetName("spanName")
-	k0 := c.buildKey("serviceName", span0, nil, pcommon.NewMap())
+	k0 := c.buildKey("serviceName", span0, nil, pcommon.NewMap(), pcommon.NewInstrumentationScope())
 	assert.Equal(t, metrics.Key("serviceName"), k0)
 }
 
+func TestBuildKeyWithInstrumentationScopeAttributes(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.InstrumentationScopeAttributes = []string{"telemetry.sdk.language", "missing"}
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
+	require.NoError(t, err)
+
+	span0 := ptrace.NewSpan()
+	span0.SetName("c")
+	scope := pcommon.NewInstrumentationScope()
+	scope.Attributes().PutStr("telemetry.sdk.language", "go")
+	k0 := c.buildKey("ab", span0, nil, pcommon.NewMap(), scope)
+	assert.Equal(t, metrics.Key("ab\u0000c\u0000SPAN_KIND_UNSPECIFIED\u0000STATUS_CODE_UNSET\u0000go"), k0)
+
+	k1 := c.buildKey("ab", span0, nil, pcommon.NewMap(), pcommon.NewInstrumentationScope())
+	assert.Equal(t, metrics.Key("ab\u0000c\u0000SPAN_KIND_UNSPECIFIED\u0000STATUS_CODE_UNSET"), k1)
+}
+
 func TestBuildKeyWithDimensions(t *testing.T) {
 	factory := NewFactory()
 	cfg := factory.CreateDefaultConfig().(*Config)
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 
 	defaultFoo := pcommon.NewValueStr("bar")
@@ -617,7 +646,7 @@ func TestBuildKeyWithDimensions(t *testing.T) {
 			span0 := ptrace.NewSpan()
 			assert.NoError(t, span0.Attributes().FromRaw(tc.spanAttrMap))
 			span0.SetName("c")
-			key := c.buildKey("ab", span0, tc.optionalDims, resAttr)
+			key := c.buildKey("ab", span0, tc.optionalDims, resAttr, pcommon.NewInstrumentationScope())
 			assert.Equal(t, metrics.Key(tc.wantKey), key)
 		})
 	}
@@ -689,7 +718,7 @@ func TestConnectorCapabilities(t *testing.T) {
 	cfg := factory.CreateDefaultConfig().(*Config)
 
 	// Test
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	// Override the default no-op consumer for testing.
 	c.metricsConsumer = new(consumertest.MetricsSink)
 	assert.NoError(t, err)
@@ -1047,6 +1076,30 @@ func TestResourceMetricsKeyAttributes(t *testing.T) {
 	assert.Equal(t, 2, p.resourceMetrics.Len())
 }
 
+func TestBuildMetricsSortOutput(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), nil)
+	reversed := func(td ptrace.Traces) ptrace.Traces {
+		out := ptrace.NewTraces()
+		for i := td.ResourceSpans().Len() - 1; i >= 0; i-- {
+			td.ResourceSpans().At(i).CopyTo(out.ResourceSpans().AppendEmpty())
+		}
+		return out
+	}
+
+	var outputs [][]byte
+	for _, traces := range []ptrace.Traces{buildSampleTrace(), reversed(buildSampleTrace())} {
+		p, err := newConnectorImp(stringp("defaultNullValue"), explicitHistogramsConfig, disabledExemplarsConfig, disabledEventsConfig, cumulative, 0, []string{}, 1000, clockwork.NewFakeClockAt(time.Unix(1700000000, 0)))
+		require.NoError(t, err)
+		p.config.SortOutput = true
+
+		require.NoError(t, p.ConsumeTraces(ctx, traces))
+		out, err := (&pmetric.JSONMarshaler{}).MarshalMetrics(p.buildMetrics())
+		require.NoError(t, err)
+		outputs = append(outputs, out)
+	}
+	assert.Equal(t, string(outputs[0]), string(outputs[1]))
+}
+
 func BenchmarkConnectorConsumeTraces(b *testing.B) {
 	// Prepare
 	conn, err := newConnectorImp(stringp("defaultNullValue"), explicitHistogramsConfig, disabledExemplarsConfig, disabledEventsConfig, cumulative, 0, []string{}, 1000, clockwork.NewFakeClock())
@@ -1129,6 +1182,39 @@ func TestExcludeDimensionsConsumeTraces(t *testing.T) {
 	}
 }
 
+func TestIncludeSpanKindsConsumeTraces(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.IncludeSpanKinds = []string{"CLIENT"}
+	p, err := newConnector(tel.NewTelemetrySettings(), cfg, clockwork.NewFakeClock())
+	require.NoError(t, err)
+
+	require.NoError(t, p.ConsumeTraces(context.Background(), buildSampleTrace()))
+
+	// only service-a has a client span, service-b is skipped entirely
+	metrics := p.buildMetrics()
+	require.Equal(t, 1, metrics.ResourceMetrics().Len())
+	rm := metrics.ResourceMetrics().At(0)
+	serviceName, ok := rm.Resource().Attributes().Get(serviceNameKey)
+	require.True(t, ok)
+	assert.Equal(t, "service-a", serviceName.Str())
+
+	calls := rm.ScopeMetrics().At(0).Metrics().At(0)
+	require.Equal(t, 1, calls.Sum().DataPoints().Len())
+	kind, ok := calls.Sum().DataPoints().At(0).Attributes().Get(spanKindKey)
+	require.True(t, ok)
+	assert.Equal(t, "SPAN_KIND_CLIENT", kind.Str())
+
+	metadatatest.AssertEqualConnectorSpanmetricsSkippedSpans(t, tel, []metricdata.DataPoint[int64]{
+		{
+			Value: 2,
+		},
+	}, metricdatatest.IgnoreTimestamp())
+	require.NoError(t, p.Shutdown(context.Background()))
+}
+
 func TestConnectorConsumeTracesEvictedCacheKey(t *testing.T) {
 	// Prepare
 	traces0 := ptrace.NewTraces()
@@ -1517,7 +1603,7 @@ func TestSpanMetrics_Events(t *testing.T) {
 			factory := NewFactory()
 			cfg := factory.CreateDefaultConfig().(*Config)
 			cfg.Events = tt.eventsConfig
-			c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+			c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 			require.NoError(t, err)
 			err = c.ConsumeTraces(context.Background(), buildSampleTrace())
 			require.NoError(t, err)
@@ -1871,7 +1957,7 @@ func TestSeparateDimensions(t *testing.T) {
 	cfg.Dimensions = []Dimension{{Name: stringAttrName, Default: nil}}
 	cfg.CallsDimensions = []Dimension{{Name: intAttrName, Default: stringp("0")}}
 	cfg.Histogram.Dimensions = []Dimension{{Name: doubleAttrName, Default: stringp("0.0")}}
-	c, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 	err = c.ConsumeTraces(context.Background(), buildSampleTrace())
 	require.NoError(t, err)
@@ -1904,6 +1990,94 @@ func TestSeparateDimensions(t *testing.T) {
 	}
 }
 
+func TestHashedDimensions(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Namespace = ""
+	cfg.Dimensions = []Dimension{{Name: stringAttrName, Hash: hashSHA256}}
+	cfg.CallsDimensions = []Dimension{{Name: intAttrName, Default: stringp("0"), Hash: hashFNV}}
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
+	require.NoError(t, err)
+	err = c.ConsumeTraces(context.Background(), buildSampleTrace())
+	require.NoError(t, err)
+
+	wantString := hashDimensionValue(hashSHA256, "stringAttrValue")
+	metrics := c.buildMetrics()
+	calls := 0
+	for i := 0; i < metrics.ResourceMetrics().Len(); i++ {
+		ism := metrics.ResourceMetrics().At(i).ScopeMetrics()
+		for ilmC := 0; ilmC < ism.Len(); ilmC++ {
+			m := ism.At(ilmC).Metrics()
+			for mC := 0; mC < m.Len(); mC++ {
+				metric := m.At(mC)
+				if metric.Name() != metricNameCalls {
+					continue
+				}
+				for idp := 0; idp < metric.Sum().DataPoints().Len(); idp++ {
+					attrs := metric.Sum().DataPoints().At(idp).Attributes()
+					v, ok := attrs.Get(stringAttrName)
+					require.True(t, ok)
+					assert.Equal(t, wantString, v.Str())
+					v, ok = attrs.Get(intAttrName)
+					require.True(t, ok)
+					assert.Equal(t, hashDimensionValue(hashFNV, "99"), v.Str())
+					calls++
+				}
+			}
+		}
+	}
+	assert.Positive(t, calls)
+}
+
+func TestDerivedDimensions(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Namespace = ""
+	cfg.Dimensions = []Dimension{{Name: httpResponseStatusClassKey}, {Name: rpcGRPCStatusClassKey}}
+	c, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
+	require.NoError(t, err)
+
+	traces := ptrace.NewTraces()
+	rs := traces.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().PutStr(string(conventions.ServiceNameKey), "service-a")
+	spans := rs.ScopeSpans().AppendEmpty().Spans()
+	for _, code := range []int64{200, 201, 503} {
+		span := spans.AppendEmpty()
+		span.SetName("GET /")
+		span.Attributes().PutInt("http.response.status_code", code)
+	}
+	span := spans.AppendEmpty()
+	span.SetName("grpc")
+	span.Attributes().PutInt("rpc.grpc.status_code", 13)
+	require.NoError(t, c.ConsumeTraces(context.Background(), traces))
+
+	// the 200 and 201 responses share the same series
+	got := map[string]int{}
+	metrics := c.buildMetrics()
+	for i := 0; i < metrics.ResourceMetrics().Len(); i++ {
+		ism := metrics.ResourceMetrics().At(i).ScopeMetrics()
+		for ilmC := 0; ilmC < ism.Len(); ilmC++ {
+			m := ism.At(ilmC).Metrics()
+			for mC := 0; mC < m.Len(); mC++ {
+				metric := m.At(mC)
+				if metric.Name() != metricNameCalls {
+					continue
+				}
+				for idp := 0; idp < metric.Sum().DataPoints().Len(); idp++ {
+					dp := metric.Sum().DataPoints().At(idp)
+					if v, ok := dp.Attributes().Get(httpResponseStatusClassKey); ok {
+						got[v.Str()]++
+					}
+					if v, ok := dp.Attributes().Get(rpcGRPCStatusClassKey); ok {
+						got[v.Str()]++
+					}
+				}
+			}
+		}
+	}
+	assert.Equal(t, map[string]int{"2xx": 1, "5xx": 1, grpcStatusClassServerError: 1}, got)
+}
+
 // Clock where Now() always returns a greater value than the previous return value
 type alwaysIncreasingClock struct {
 	clockwork.Clock
@@ -1999,6 +2173,26 @@ func TestBuildAttributes_InstrumentationScope(t *testing.T) {
 				instrumentationScopeNameKey: "express",
 			},
 		},
+		{
+			name: "with instrumentation scope attributes included in config",
+			instrumentationScope: func() pcommon.InstrumentationScope {
+				scope := pcommon.NewInstrumentationScope()
+				scope.SetName("express")
+				scope.Attributes().PutStr("telemetry.sdk.language", "nodejs")
+				scope.Attributes().PutStr("telemetry.sdk.name", "opentelemetry")
+				return scope
+			}(),
+			config: Config{
+				InstrumentationScopeAttributes: []string{"telemetry.sdk.language", "missing"},
+			},
+			want: map[string]string{
+				serviceNameKey:           "test_service",
+				spanNameKey:              "test_span",
+				spanKindKey:              "SPAN_KIND_INTERNAL",
+				statusCodeKey:            "STATUS_CODE_UNSET",
+				"telemetry.sdk.language": "nodejs",
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -2028,7 +2222,7 @@ func TestConnectorWithCardinalityLimit(t *testing.T) {
 		{Name: "region"},
 	}
 
-	connector, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	connector, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 
 	require.NotNil(t, connector)
@@ -2166,7 +2360,7 @@ func TestConnectorWithCardinalityLimitForEvents(t *testing.T) {
 		{Name: "event.name"},
 	}
 
-	connector, err := newConnector(zaptest.NewLogger(t), cfg, clockwork.NewFakeClock())
+	connector, err := newConnector(newTestTelemetrySettings(t), cfg, clockwork.NewFakeClock())
 	require.NoError(t, err)
 	require.NotNil(t, connector)
 
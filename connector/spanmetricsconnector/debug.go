@@ -0,0 +1,131 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanmetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector"
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metrics"
+)
+
+const defaultDebugPath = "/debug/spanmetrics"
+
+// handlerRegistrar is implemented by extensions able to serve additional debug handlers
+// next to their own pages, such as a zPages style extension.
+type handlerRegistrar interface {
+	RegisterHandler(pattern string, handler http.Handler) error
+}
+
+type debugCacheStats struct {
+	Size     int `json:"size"`
+	Capacity int `json:"capacity"`
+}
+
+type debugSeries struct {
+	Metric   string    `json:"metric"`
+	Key      string    `json:"key"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+type debugResource struct {
+	Attributes map[string]any `json:"attributes"`
+	Series     []debugSeries  `json:"series"`
+}
+
+type debugSnapshot struct {
+	ResourceMetricsCache debugCacheStats  `json:"resource_metrics_cache"`
+	DeltaTimestampCache  *debugCacheStats `json:"delta_timestamp_cache,omitempty"`
+	Resources            []debugResource  `json:"resources"`
+}
+
+// registerDebugHandler registers the debug handler with the first extension of the host able to serve it.
+func (p *connectorImp) registerDebugHandler(host component.Host) {
+	for id, ext := range host.GetExtensions() {
+		r, ok := ext.(handlerRegistrar)
+		if !ok {
+			continue
+		}
+		if err := r.RegisterHandler(p.config.GetDebugPath(), http.HandlerFunc(p.serveDebug)); err != nil {
+			p.logger.Warn("Failed to register debug handler", zap.Stringer("extension", id), zap.Error(err))
+			continue
+		}
+		p.logger.Info("Registered debug handler", zap.Stringer("extension", id), zap.String("path", p.config.GetDebugPath()))
+		return
+	}
+	p.logger.Info("No extension available to serve the debug handler, it will not be exposed")
+}
+
+// recordSeen tracks the last time a series was updated, only when the debug handler is enabled.
+// Once the cardinality limit is reached the data goes to the overflow series, which is tracked
+// instead of key.
+func (*connectorImp) recordSeen(rm *resourceMetrics, metric string, key metrics.Key, limitReached bool, now time.Time) {
+	if rm.seriesLastSeen == nil {
+		return
+	}
+	if limitReached {
+		key = overflowKey
+	}
+	rm.seriesLastSeen[debugSeriesKey{metric: metric, key: key}] = now
+}
+
+type debugSeriesKey struct {
+	metric string
+	key    metrics.Key
+}
+
+func (p *connectorImp) snapshot() debugSnapshot {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	s := debugSnapshot{
+		ResourceMetricsCache: debugCacheStats{
+			Size:     p.resourceMetrics.Len(),
+			Capacity: p.config.ResourceMetricsCacheSize,
+		},
+		Resources: []debugResource{},
+	}
+	if p.lastDeltaTimestamps != nil {
+		s.DeltaTimestampCache = &debugCacheStats{
+			Size:     p.lastDeltaTimestamps.Len(),
+			Capacity: p.config.GetDeltaTimestampCacheSize(),
+		}
+	}
+	p.resourceMetrics.ForEach(func(_ resourceKey, rm *resourceMetrics) {
+		r := debugResource{
+			Attributes: rm.attributes.AsRaw(),
+			Series:     make([]debugSeries, 0, len(rm.seriesLastSeen)),
+		}
+		for k, t := range rm.seriesLastSeen {
+			r.Series = append(r.Series, debugSeries{
+				Metric:   k.metric,
+				Key:      strings.ReplaceAll(string(k.key), metricKeySeparator, "|"),
+				LastSeen: t,
+			})
+		}
+		sort.Slice(r.Series, func(i, j int) bool {
+			if r.Series[i].Metric != r.Series[j].Metric {
+				return r.Series[i].Metric < r.Series[j].Metric
+			}
+			return r.Series[i].Key < r.Series[j].Key
+		})
+		s.Resources = append(s.Resources, r)
+	})
+	return s
+}
+
+func (p *connectorImp) serveDebug(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(p.snapshot()); err != nil {
+		p.logger.Debug("Failed to write debug response", zap.Error(err))
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanmetricsconnector
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+type registrarExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+	handlers map[string]http.Handler
+}
+
+func (e *registrarExtension) RegisterHandler(pattern string, handler http.Handler) error {
+	e.handlers[pattern] = handler
+	return nil
+}
+
+type extensionsHost struct {
+	extensions map[component.ID]component.Component
+}
+
+func (h *extensionsHost) GetExtensions() map[component.ID]component.Component {
+	return h.extensions
+}
+
+func TestDebugHandler(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Debug.Enabled = true
+	clock := clockwork.NewFakeClock()
+	c, err := newConnector(componenttest.NewNopTelemetrySettings(), cfg, clock)
+	require.NoError(t, err)
+	c.metricsConsumer = consumertest.NewNop()
+
+	ext := &registrarExtension{handlers: map[string]http.Handler{}}
+	host := &extensionsHost{extensions: map[component.ID]component.Component{
+		component.MustNewID("zpages"): ext,
+	}}
+	require.NoError(t, c.Start(context.Background(), host))
+	defer func() { require.NoError(t, c.Shutdown(context.Background())) }()
+	require.Contains(t, ext.handlers, defaultDebugPath)
+
+	require.NoError(t, c.ConsumeTraces(context.Background(), buildSampleTrace()))
+
+	rec := httptest.NewRecorder()
+	ext.handlers[defaultDebugPath].ServeHTTP(rec, httptest.NewRequest(http.MethodGet, defaultDebugPath, http.NoBody))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var snapshot debugSnapshot
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &snapshot))
+	assert.Equal(t, 2, snapshot.ResourceMetricsCache.Size)
+	assert.Equal(t, defaultResourceMetricsCacheSize, snapshot.ResourceMetricsCache.Capacity)
+	assert.Nil(t, snapshot.DeltaTimestampCache)
+	require.Len(t, snapshot.Resources, 2)
+	for _, r := range snapshot.Resources {
+		require.NotEmpty(t, r.Series)
+		for _, s := range r.Series {
+			assert.Contains(t, []string{metricNameCalls, metricNameDuration}, s.Metric)
+			assert.NotContains(t, s.Key, metricKeySeparator)
+			assert.True(t, s.LastSeen.Equal(clock.Now()))
+		}
+	}
+}
+
+func TestDebugDisabledDoesNotTrackSeries(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	c, err := newConnector(componenttest.NewNopTelemetrySettings(), cfg, clockwork.NewFakeClock())
+	require.NoError(t, err)
+
+	require.NoError(t, c.ConsumeTraces(context.Background(), buildSampleTrace()))
+	c.resourceMetrics.ForEach(func(_ resourceKey, rm *resourceMetrics) {
+		assert.Nil(t, rm.seriesLastSeen)
+	})
+}
+
+func TestDebugTracksOverflowSeries(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Debug.Enabled = true
+	cfg.AggregationCardinalityLimit = 1
+	c, err := newConnector(componenttest.NewNopTelemetrySettings(), cfg, clockwork.NewFakeClock())
+	require.NoError(t, err)
+
+	traces := ptrace.NewTraces()
+	rspans := traces.ResourceSpans().AppendEmpty()
+	rspans.Resource().Attributes().PutStr("service.name", "service1")
+	spans := rspans.ScopeSpans().AppendEmpty().Spans()
+	for _, name := range []string{"operation1", "operation2", "operation3"} {
+		spans.AppendEmpty().SetName(name)
+	}
+	require.NoError(t, c.ConsumeTraces(context.Background(), traces))
+
+	// the spans beyond the limit are tracked as the overflow series they are exported as
+	require.Equal(t, 1, c.resourceMetrics.Len())
+	c.resourceMetrics.ForEach(func(_ resourceKey, rm *resourceMetrics) {
+		assert.Len(t, rm.seriesLastSeen, 4)
+		assert.Contains(t, rm.seriesLastSeen, debugSeriesKey{metric: metricNameCalls, key: overflowKey})
+		assert.Contains(t, rm.seriesLastSeen, debugSeriesKey{metric: metricNameDuration, key: overflowKey})
+	})
+}
@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanmetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector"
+
+import (
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	utilattri "github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdatautil"
+)
+
+const (
+	httpResponseStatusClassKey = "http.response.status_class"
+	rpcGRPCStatusClassKey      = "rpc.grpc.status_class"
+
+	grpcStatusClassOK          = "ok"
+	grpcStatusClassClientError = "client_error"
+	grpcStatusClassServerError = "server_error"
+)
+
+// derivedDimensions computes the value of the dimensions that can be derived from other span attributes when the
+// span does not have the attribute itself, to break down the metrics by error class without the cardinality of
+// the raw status codes.
+var derivedDimensions = map[string]func(spanAttrs pcommon.Map) (string, bool){
+	httpResponseStatusClassKey: deriveHTTPStatusClass,
+	rpcGRPCStatusClassKey:      deriveGRPCStatusClass,
+}
+
+// grpcServerErrors are the gRPC status codes reported as errors by servers, the others being caused by the client.
+// See https://opentelemetry.io/docs/specs/semconv/rpc/grpc/#grpc-status.
+var grpcServerErrors = map[int64]struct{}{
+	2:  {}, // UNKNOWN
+	4:  {}, // DEADLINE_EXCEEDED
+	12: {}, // UNIMPLEMENTED
+	13: {}, // INTERNAL
+	14: {}, // UNAVAILABLE
+	15: {}, // DATA_LOSS
+}
+
+// getDimensionValue returns the value of the dimension d from the span attributes, then derived from the other span
+// attributes, then from the resource or event attributes, and falls back to its default.
+func getDimensionValue(d utilattri.Dimension, spanAttrs, resourceOrEventAttrs pcommon.Map) (pcommon.Value, bool) {
+	if derive, ok := derivedDimensions[d.Name]; ok {
+		if _, found := spanAttrs.Get(d.Name); !found {
+			if value, derived := derive(spanAttrs); derived {
+				return pcommon.NewValueStr(value), true
+			}
+		}
+	}
+	return utilattri.GetDimensionValue(d, spanAttrs, resourceOrEventAttrs)
+}
+
+// deriveHTTPStatusClass returns the class, e.g. "4xx", of the HTTP response status code of the span, read from the
+// http.status_code attribute of the older semantic conventions when http.response.status_code is missing.
+func deriveHTTPStatusClass(spanAttrs pcommon.Map) (string, bool) {
+	code, ok := intAttribute(spanAttrs, "http.response.status_code")
+	if !ok {
+		code, ok = intAttribute(spanAttrs, "http.status_code")
+	}
+	if !ok || code < 100 || code > 599 {
+		return "", false
+	}
+	return strconv.FormatInt(code/100, 10) + "xx", true
+}
+
+// deriveGRPCStatusClass returns "ok", "client_error" or "server_error" depending on the gRPC status code of the span.
+func deriveGRPCStatusClass(spanAttrs pcommon.Map) (string, bool) {
+	code, ok := intAttribute(spanAttrs, "rpc.grpc.status_code")
+	if !ok || code < 0 || code > 16 {
+		return "", false
+	}
+	if code == 0 {
+		return grpcStatusClassOK, true
+	}
+	if _, ok := grpcServerErrors[code]; ok {
+		return grpcStatusClassServerError, true
+	}
+	return grpcStatusClassClientError, true
+}
+
+// intAttribute returns the integer value of the attribute key, which may also be recorded as a string.
+func intAttribute(attrs pcommon.Map, key string) (int64, bool) {
+	v, ok := attrs.Get(key)
+	if !ok {
+		return 0, false
+	}
+	switch v.Type() {
+	case pcommon.ValueTypeInt:
+		return v.Int(), true
+	case pcommon.ValueTypeStr:
+		code, err := strconv.ParseInt(v.Str(), 10, 64)
+		return code, err == nil
+	default:
+		return 0, false
+	}
+}
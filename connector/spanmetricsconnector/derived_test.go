@@ -0,0 +1,98 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanmetricsconnector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	utilattri "github.com/open-telemetry/opentelemetry-collector-contrib/internal/pdatautil"
+)
+
+func TestGetDimensionValueDerived(t *testing.T) {
+	defaultValue := pcommon.NewValueStr("unknown")
+	tests := []struct {
+		name      string
+		dimension string
+		spanAttrs map[string]any
+		want      string
+		wantOK    bool
+	}{
+		{
+			name:      "http status class",
+			dimension: httpResponseStatusClassKey,
+			spanAttrs: map[string]any{"http.response.status_code": 404},
+			want:      "4xx",
+			wantOK:    true,
+		},
+		{
+			name:      "http status class from legacy string attribute",
+			dimension: httpResponseStatusClassKey,
+			spanAttrs: map[string]any{"http.status_code": "503"},
+			want:      "5xx",
+			wantOK:    true,
+		},
+		{
+			name:      "http status class attribute takes precedence",
+			dimension: httpResponseStatusClassKey,
+			spanAttrs: map[string]any{"http.response.status_code": 200, httpResponseStatusClassKey: "success"},
+			want:      "success",
+			wantOK:    true,
+		},
+		{
+			name:      "invalid http status code falls back to the default",
+			dimension: httpResponseStatusClassKey,
+			spanAttrs: map[string]any{"http.response.status_code": 42},
+			want:      "unknown",
+			wantOK:    true,
+		},
+		{
+			name:      "grpc ok",
+			dimension: rpcGRPCStatusClassKey,
+			spanAttrs: map[string]any{"rpc.grpc.status_code": 0},
+			want:      grpcStatusClassOK,
+			wantOK:    true,
+		},
+		{
+			name:      "grpc client error",
+			dimension: rpcGRPCStatusClassKey,
+			spanAttrs: map[string]any{"rpc.grpc.status_code": 5},
+			want:      grpcStatusClassClientError,
+			wantOK:    true,
+		},
+		{
+			name:      "grpc server error",
+			dimension: rpcGRPCStatusClassKey,
+			spanAttrs: map[string]any{"rpc.grpc.status_code": 14},
+			want:      grpcStatusClassServerError,
+			wantOK:    true,
+		},
+		{
+			name:      "missing grpc status code falls back to the default",
+			dimension: rpcGRPCStatusClassKey,
+			spanAttrs: map[string]any{},
+			want:      "unknown",
+			wantOK:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spanAttrs := pcommon.NewMap()
+			assert.NoError(t, spanAttrs.FromRaw(tt.spanAttrs))
+			d := utilattri.Dimension{Name: tt.dimension, Value: &defaultValue}
+			v, ok := getDimensionValue(d, spanAttrs, pcommon.NewMap())
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, v.AsString())
+		})
+	}
+}
+
+func TestGetDimensionValueNotDerived(t *testing.T) {
+	spanAttrs := pcommon.NewMap()
+	spanAttrs.PutStr("http.response.status_code", "not a number")
+	_, ok := getDimensionValue(utilattri.Dimension{Name: httpResponseStatusClassKey}, spanAttrs, pcommon.NewMap())
+	assert.False(t, ok)
+}
@@ -0,0 +1,48 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanmetricsconnector // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector"
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+)
+
+const (
+	hashSHA256 = "sha256"
+	hashFNV    = "fnv"
+)
+
+// newDimensionHashes returns the hash function of each hashed dimension, or nil if no dimension is hashed.
+func newDimensionHashes(dimensionLists ...[]Dimension) map[string]string {
+	var hashes map[string]string
+	for _, dimensions := range dimensionLists {
+		for _, d := range dimensions {
+			if d.Hash == "" {
+				continue
+			}
+			if hashes == nil {
+				hashes = make(map[string]string)
+			}
+			hashes[d.Name] = d.Hash
+		}
+	}
+	return hashes
+}
+
+// hashDimensionValue returns the hex encoded hash of value, the SHA-256 digest for sha256 and the 64-bit FNV-1a
+// hash for fnv.
+func hashDimensionValue(hash, value string) string {
+	switch hash {
+	case hashSHA256:
+		sum := sha256.Sum256([]byte(value))
+		return hex.EncodeToString(sum[:])
+	case hashFNV:
+		h := fnv.New64a()
+		_, _ = h.Write([]byte(value))
+		return hex.EncodeToString(h.Sum(nil))
+	default:
+		return value
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package spanmetricsconnector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashDimensionValue(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		hash  string
+		value string
+		want  string
+	}{
+		{
+			name:  "sha256",
+			hash:  hashSHA256,
+			value: "user-42",
+			want:  "6d894aa3ee802549d7f340e7c1cf0d1c1cb14cd84f768d92ffaa6785337c4997",
+		},
+		{
+			name:  "fnv",
+			hash:  hashFNV,
+			value: "user-42",
+			want:  "32c6d7a54d35dacb",
+		},
+		{
+			name:  "not hashed",
+			value: "user-42",
+			want:  "user-42",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := hashDimensionValue(tc.hash, tc.value)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -4,6 +4,9 @@
 package metrics // import "github.com/open-telemetry/opentelemetry-collector-contrib/connector/spanmetricsconnector/internal/metrics"
 
 import (
+	"iter"
+	"maps"
+	"slices"
 	"sort"
 
 	"github.com/lightstep/go-expohisto/structure"
@@ -18,7 +21,7 @@ type Key string
 
 type HistogramMetrics interface {
 	GetOrCreate(key Key, attributesFun BuildAttributesFun, startTimestamp pcommon.Timestamp) (Histogram, bool)
-	BuildMetrics(pmetric.Metric, pcommon.Timestamp, func(Key, pcommon.Timestamp) pcommon.Timestamp, pmetric.AggregationTemporality)
+	BuildMetrics(pmetric.Metric, pcommon.Timestamp, func(Key, pcommon.Timestamp) pcommon.Timestamp, pmetric.AggregationTemporality, bool)
 	ClearExemplars()
 }
 
@@ -130,11 +133,12 @@ func (m *explicitHistogramMetrics) BuildMetrics(
 	timestamp pcommon.Timestamp,
 	startTimeStampGenerator func(Key, pcommon.Timestamp) pcommon.Timestamp,
 	temporality pmetric.AggregationTemporality,
+	sorted bool,
 ) {
 	metric.SetEmptyHistogram().SetAggregationTemporality(temporality)
 	dps := metric.Histogram().DataPoints()
 	dps.EnsureCapacity(len(m.metrics))
-	for k, h := range m.metrics {
+	for k, h := range entries(m.metrics, sorted) {
 		dp := dps.AppendEmpty()
 		startTimestamp := startTimeStampGenerator(k, h.startTimestamp)
 		dp.SetStartTimestamp(startTimestamp)
@@ -205,11 +209,12 @@ func (m *exponentialHistogramMetrics) BuildMetrics(
 	timestamp pcommon.Timestamp,
 	startTimeStampGenerator func(Key, pcommon.Timestamp) pcommon.Timestamp,
 	temporality pmetric.AggregationTemporality,
+	sorted bool,
 ) {
 	metric.SetEmptyExponentialHistogram().SetAggregationTemporality(temporality)
 	dps := metric.ExponentialHistogram().DataPoints()
 	dps.EnsureCapacity(len(m.metrics))
-	for k, e := range m.metrics {
+	for k, e := range entries(m.metrics, sorted) {
 		dp := dps.AppendEmpty()
 		startTimestamp := startTimeStampGenerator(k, e.startTimestamp)
 		dp.SetStartTimestamp(startTimestamp)
@@ -380,13 +385,14 @@ func (m *SumMetrics) BuildMetrics(
 	timestamp pcommon.Timestamp,
 	startTimeStampGenerator func(Key, pcommon.Timestamp) pcommon.Timestamp,
 	temporality pmetric.AggregationTemporality,
+	sorted bool,
 ) {
 	metric.SetEmptySum().SetIsMonotonic(true)
 	metric.Sum().SetAggregationTemporality(temporality)
 
 	dps := metric.Sum().DataPoints()
 	dps.EnsureCapacity(len(m.metrics))
-	for k, s := range m.metrics {
+	for k, s := range entries(m.metrics, sorted) {
 		dp := dps.AppendEmpty()
 		startTimeStamp := startTimeStampGenerator(k, s.startTimestamp)
 		dp.SetStartTimestamp(startTimeStamp)
@@ -410,3 +416,19 @@ func (m *SumMetrics) ClearExemplars() {
 		sum.exemplars = pmetric.NewExemplarSlice()
 	}
 }
+
+// entries iterates over the metrics of m, in the order of their keys when sorted so that
+// the data points are built in a deterministic order, at the cost of sorting the keys.
+func entries[V any](m map[Key]V, sorted bool) iter.Seq2[Key, V] {
+	if !sorted {
+		return maps.All(m)
+	}
+	keys := slices.Sorted(maps.Keys(m))
+	return func(yield func(Key, V) bool) {
+		for _, k := range keys {
+			if !yield(k, m[k]) {
+				return
+			}
+		}
+	}
+}
@@ -522,7 +522,7 @@ func TestSumMetrics_BuildMetrics(t *testing.T) {
 			startTimestamp := func(Key, pcommon.Timestamp) pcommon.Timestamp { return 0 }
 			timestamp := pcommon.Timestamp(1000)
 
-			sm.BuildMetrics(metric, timestamp, startTimestamp, tt.temporality)
+			sm.BuildMetrics(metric, timestamp, startTimestamp, tt.temporality, false)
 
 			assert.Equal(t, pmetric.MetricTypeSum, metric.Type())
 			assert.Equal(t, tt.temporality, metric.Sum().AggregationTemporality())
@@ -543,6 +543,29 @@ func TestSumMetrics_BuildMetrics(t *testing.T) {
 	}
 }
 
+func TestSumMetrics_BuildMetricsSorted(t *testing.T) {
+	sm := SumMetrics{metrics: make(map[Key]*Sum)}
+	keys := []Key{"key3", "key1", "key4", "key2", "key0"}
+	for i, k := range keys {
+		sm.metrics[k] = &Sum{
+			count:      uint64(i),
+			attributes: pcommon.NewMap(),
+			exemplars:  pmetric.NewExemplarSlice(),
+		}
+	}
+	startTimestamp := func(Key, pcommon.Timestamp) pcommon.Timestamp { return 0 }
+
+	metric := pmetric.NewMetric()
+	sm.BuildMetrics(metric, pcommon.Timestamp(1000), startTimestamp, pmetric.AggregationTemporalityCumulative, true)
+
+	var values []int64
+	dps := metric.Sum().DataPoints()
+	for i := 0; i < dps.Len(); i++ {
+		values = append(values, dps.At(i).IntValue())
+	}
+	assert.Equal(t, []int64{4, 1, 3, 0, 2}, values)
+}
+
 func TestSumMetrics_ClearExemplars(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,90 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// bodyJSONLLogRecord is the JSON object written for every log record by the
+// body_jsonl marshaler.
+type bodyJSONLLogRecord struct {
+	// Timestamp is the time of the record, its observed time when unset, in RFC 3339.
+	Timestamp          string         `json:"timestamp"`
+	SeverityText       string         `json:"severity_text,omitempty"`
+	SeverityNumber     int32          `json:"severity_number,omitempty"`
+	TraceID            string         `json:"trace_id,omitempty"`
+	SpanID             string         `json:"span_id,omitempty"`
+	ResourceAttributes map[string]any `json:"resource_attributes,omitempty"`
+	Attributes         map[string]any `json:"attributes,omitempty"`
+	Body               any            `json:"body"`
+}
+
+// bodyJSONLMarshaler writes one JSON object per log record, one per line, as
+// expected by most query engines and bulk importers.
+type bodyJSONLMarshaler struct{}
+
+func (*bodyJSONLMarshaler) format() string {
+	return "jsonl"
+}
+
+func newBodyJSONLMarshaler() bodyJSONLMarshaler {
+	return bodyJSONLMarshaler{}
+}
+
+func (bodyJSONLMarshaler) MarshalLogs(ld plog.Logs) ([]byte, error) {
+	buf := bytes.Buffer{}
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resourceAttrs := rl.Resource().Attributes().AsRaw()
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			logs := sls.At(j).LogRecords()
+			for k := 0; k < logs.Len(); k++ {
+				lr := logs.At(k)
+				ts := lr.Timestamp()
+				if ts == 0 {
+					ts = lr.ObservedTimestamp()
+				}
+				record := bodyJSONLLogRecord{
+					Timestamp:          ts.AsTime().UTC().Format(time.RFC3339Nano),
+					SeverityText:       lr.SeverityText(),
+					SeverityNumber:     int32(lr.SeverityNumber()),
+					ResourceAttributes: resourceAttrs,
+					Attributes:         lr.Attributes().AsRaw(),
+					Body:               lr.Body().AsRaw(),
+				}
+				if traceID := lr.TraceID(); !traceID.IsEmpty() {
+					record.TraceID = traceID.String()
+				}
+				if spanID := lr.SpanID(); !spanID.IsEmpty() {
+					record.SpanID = spanID.String()
+				}
+				// Encode terminates every object with a newline.
+				if err := enc.Encode(record); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (s bodyJSONLMarshaler) MarshalTraces(_ ptrace.Traces) ([]byte, error) {
+	return nil, fmt.Errorf("traces can't be marshaled into %s format", s.format())
+}
+
+func (s bodyJSONLMarshaler) MarshalMetrics(_ pmetric.Metrics) ([]byte, error) {
+	return nil, fmt.Errorf("metrics can't be marshaled into %s format", s.format())
+}
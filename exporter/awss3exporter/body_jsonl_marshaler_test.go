@@ -0,0 +1,56 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestBodyJSONLMarshaler(t *testing.T) {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "checkout")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+
+	lr := lrs.AppendEmpty()
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Date(2024, 1, 10, 10, 30, 40, 100, time.UTC)))
+	lr.SetSeverityText("ERROR")
+	lr.SetSeverityNumber(plog.SeverityNumberError)
+	lr.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	lr.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+	lr.Attributes().PutInt("http.status_code", 500)
+	lr.Body().SetStr("payment <failed>")
+
+	// Without a timestamp, the observed one is written.
+	lr = lrs.AppendEmpty()
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Date(2024, 1, 10, 10, 30, 41, 0, time.UTC)))
+	lr.Body().SetEmptyMap().PutBool("retry", true)
+
+	m := newBodyJSONLMarshaler()
+	assert.Equal(t, "jsonl", m.format())
+	buf, err := m.MarshalLogs(logs)
+	require.NoError(t, err)
+	assert.Equal(t,
+		`{"timestamp":"2024-01-10T10:30:40.0000001Z","severity_text":"ERROR","severity_number":17,`+
+			`"trace_id":"0102030405060708090a0b0c0d0e0f10","span_id":"0102030405060708",`+
+			`"resource_attributes":{"service.name":"checkout"},"attributes":{"http.status_code":500},"body":"payment <failed>"}`+"\n"+
+			`{"timestamp":"2024-01-10T10:30:41Z","resource_attributes":{"service.name":"checkout"},"body":{"retry":true}}`+"\n",
+		string(buf))
+}
+
+func TestBodyJSONLMarshalerUnsupportedSignals(t *testing.T) {
+	m := newBodyJSONLMarshaler()
+	_, err := m.MarshalTraces(ptrace.NewTraces())
+	assert.EqualError(t, err, "traces can't be marshaled into jsonl format")
+	_, err = m.MarshalMetrics(pmetric.NewMetrics())
+	assert.EqualError(t, err, "metrics can't be marshaled into jsonl format")
+}
@@ -0,0 +1,91 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"
+
+import (
+	"bytes"
+	"compress/gzip"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// recordCompressingMarshaler compresses every resource as a gzip member of its
+// own. The concatenation of the members is a valid gzip stream, decompressed
+// as a whole by the usual readers, while consumers knowing the offsets of the
+// members can decompress any of them on its own, e.g. after a range read.
+type recordCompressingMarshaler struct {
+	marshaler marshaler
+}
+
+func newRecordCompressingMarshaler(m marshaler) marshaler {
+	return &recordCompressingMarshaler{marshaler: m}
+}
+
+func (r *recordCompressingMarshaler) MarshalTraces(td ptrace.Traces) ([]byte, error) {
+	buf := bytes.Buffer{}
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		record := ptrace.NewTraces()
+		td.ResourceSpans().At(i).CopyTo(record.ResourceSpans().AppendEmpty())
+		b, err := r.marshaler.MarshalTraces(record)
+		if err != nil {
+			return nil, err
+		}
+		if err = writeGzipMember(&buf, b); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *recordCompressingMarshaler) MarshalLogs(ld plog.Logs) ([]byte, error) {
+	buf := bytes.Buffer{}
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		record := plog.NewLogs()
+		ld.ResourceLogs().At(i).CopyTo(record.ResourceLogs().AppendEmpty())
+		b, err := r.marshaler.MarshalLogs(record)
+		if err != nil {
+			return nil, err
+		}
+		if err = writeGzipMember(&buf, b); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (r *recordCompressingMarshaler) MarshalMetrics(md pmetric.Metrics) ([]byte, error) {
+	buf := bytes.Buffer{}
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		record := pmetric.NewMetrics()
+		md.ResourceMetrics().At(i).CopyTo(record.ResourceMetrics().AppendEmpty())
+		b, err := r.marshaler.MarshalMetrics(record)
+		if err != nil {
+			return nil, err
+		}
+		if err = writeGzipMember(&buf, b); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// writeGzipMember appends record to buf as a complete gzip member. Empty
+// records, e.g. of resources without data for the passthrough marshaler, are
+// skipped.
+func writeGzipMember(buf *bytes.Buffer, record []byte) error {
+	if len(record) == 0 {
+		return nil
+	}
+	zipper := gzip.NewWriter(buf)
+	if _, err := zipper.Write(record); err != nil {
+		return err
+	}
+	return zipper.Close()
+}
+
+func (r *recordCompressingMarshaler) format() string {
+	return r.marshaler.format()
+}
@@ -0,0 +1,59 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestRecordCompressingMarshaler(t *testing.T) {
+	m, err := newMarshaler(OtlpJSON, zap.NewNop())
+	require.NoError(t, err)
+	compressing := newRecordCompressingMarshaler(newFramedMarshaler(m, FramingNewline))
+	assert.Equal(t, "json", compressing.format())
+
+	logs := newFramingTestLogs()
+	buf, err := compressing.MarshalLogs(logs)
+	require.NoError(t, err)
+
+	t.Run("members", func(t *testing.T) {
+		src := bufio.NewReader(bytes.NewReader(buf))
+		reader, err := gzip.NewReader(src)
+		require.NoError(t, err)
+		unmarshaler := plog.JSONUnmarshaler{}
+		var records int
+		for ; err != io.EOF; records++ {
+			require.NoError(t, err)
+			reader.Multistream(false)
+			member, readErr := io.ReadAll(reader)
+			require.NoError(t, readErr)
+			record, unmarshalErr := unmarshaler.UnmarshalLogs(bytes.TrimSuffix(member, []byte("\n")))
+			require.NoError(t, unmarshalErr)
+			expected := plog.NewLogs()
+			logs.ResourceLogs().At(records).CopyTo(expected.ResourceLogs().AppendEmpty())
+			assert.Equal(t, expected, record)
+			err = reader.Reset(src)
+		}
+		assert.Equal(t, 2, records)
+	})
+
+	t.Run("whole stream", func(t *testing.T) {
+		reader, err := gzip.NewReader(bytes.NewReader(buf))
+		require.NoError(t, err)
+		raw, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		framed, err := newFramedMarshaler(m, FramingNewline).MarshalLogs(logs)
+		require.NoError(t, err)
+		assert.Equal(t, framed, raw)
+	})
+}
@@ -5,18 +5,63 @@ package awss3exporter // import "github.com/open-telemetry/opentelemetry-collect
 
 import (
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/config/configopaque"
+	"go.opentelemetry.io/collector/config/configretry"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.uber.org/multierr"
 )
 
 const (
-	DefaultRetryMode        = "standard"
-	DefaultRetryMaxAttempts = 3
-	DefaultRetryMaxBackoff  = 20 * time.Second
+	DefaultRetryMode           = "standard"
+	DefaultRetryMaxAttempts    = 3
+	DefaultRetryMaxBackoff     = 20 * time.Second
+	DefaultConsolidationDelay  = 5 * time.Minute
+	DefaultParquetRowGroupSize = 100_000
+	DefaultDrainTimeout        = 30 * time.Second
+
+	DefaultAggregationMaxSize       = 16 << 20
+	DefaultAggregationInterval      = time.Minute
+	DefaultAggregationMaxMemorySize = 64 << 20
+	DefaultAggregationMaxSpillSize  = 1 << 30
+
+	DefaultFailoverFailureThreshold = 3
+	DefaultFailoverFailbackInterval = time.Minute
+)
+
+// hivePartitionFormat is the partition format of the PartitionSchemeHive scheme.
+const hivePartitionFormat = "year=%Y/month=%m/day=%d/hour=%H"
+
+// PartitionScheme is the layout of the time partition of the S3 keys.
+type PartitionScheme string
+
+const (
+	// PartitionSchemeStrftime formats the partition with S3PartitionFormat.
+	PartitionSchemeStrftime PartitionScheme = "strftime"
+	// PartitionSchemeHive partitions by hour as Hive-style key=value directories,
+	// e.g. "year=2024/month=06/day=01/hour=13".
+	PartitionSchemeHive PartitionScheme = "hive"
+)
+
+// PartitionTimeSource is the time the time partition of the S3 keys is derived from.
+type PartitionTimeSource string
+
+const (
+	// PartitionTimeUpload partitions by the time of the upload.
+	PartitionTimeUpload PartitionTimeSource = "upload"
+	// PartitionTimeEarliestRecord partitions by the earliest timestamp of the records
+	// of the object.
+	PartitionTimeEarliestRecord PartitionTimeSource = "earliest_record"
+	// PartitionTimeLatestRecord partitions by the latest timestamp of the records of
+	// the object.
+	PartitionTimeLatestRecord PartitionTimeSource = "latest_record"
 )
 
 // S3UploaderConfig contains aws s3 uploader related config to controls things
@@ -25,28 +70,84 @@ type S3UploaderConfig struct {
 	Region string `mapstructure:"region"`
 	// S3Bucket is the bucket name to be uploaded to.
 	S3Bucket string `mapstructure:"s3_bucket"`
-	// S3Prefix is the key (directory) prefix to written to inside the bucket
+	// S3Prefix is the key (directory) prefix to written to inside the bucket. It may reference
+	// resource attributes as {attribute} placeholders, e.g. "{service.name}/{deployment.environment}".
 	S3Prefix string `mapstructure:"s3_prefix"`
 	// S3PartitionFormat is used to provide the rollup on how data is written. Uses [strftime](https://www.man7.org/linux/man-pages/man3/strftime.3.html) formatting.
 	S3PartitionFormat string `mapstructure:"s3_partition_format"`
+	// S3PartitionScheme is the layout of the partition, either "strftime", formatting it with
+	// S3PartitionFormat, or "hive". Defaults to "strftime".
+	S3PartitionScheme PartitionScheme `mapstructure:"s3_partition_scheme"`
+	// S3PartitionBySignal adds a signal=logs, signal=metrics or signal=traces partition
+	// ahead of the time partition of the "hive" S3PartitionScheme.
+	S3PartitionBySignal bool `mapstructure:"s3_partition_by_signal"`
+	// S3PartitionTimeSource is the time the partition is derived from: "upload", or the
+	// "earliest_record" or "latest_record" timestamp of the records of the object, so that
+	// late data lands in the partition of its time. Defaults to "upload".
+	S3PartitionTimeSource PartitionTimeSource `mapstructure:"s3_partition_time_source"`
 	// FilePrefix is the filename prefix used for the file to avoid any potential collisions.
 	FilePrefix string `mapstructure:"file_prefix"`
 	// Endpoint is the URL used for communicated with S3.
 	Endpoint string `mapstructure:"endpoint"`
+	// AWSProfile is the named profile of the shared config and credentials files
+	// the credentials and settings are loaded from, instead of the default one.
+	AWSProfile string `mapstructure:"aws_profile"`
+	// SharedConfigFiles replaces the shared config files the profiles are loaded
+	// from, defaults to ~/.aws/config.
+	SharedConfigFiles []string `mapstructure:"shared_config_files"`
+	// SharedCredentialsFiles replaces the shared credentials files the profiles are
+	// loaded from, defaults to ~/.aws/credentials.
+	SharedCredentialsFiles []string `mapstructure:"shared_credentials_files"`
 	// RoleArn is the role policy to use when interacting with S3
 	RoleArn string `mapstructure:"role_arn"`
+	// ExternalID is the external ID passed when assuming RoleArn, as required by
+	// the trust policies of cross-account roles.
+	ExternalID string `mapstructure:"external_id"`
+	// RoleSessionName identifies the session of the assumed role in CloudTrail.
+	RoleSessionName string `mapstructure:"role_session_name"`
+	// RoleSessionDuration is the lifetime of the credentials of the assumed role,
+	// left to STS when zero.
+	RoleSessionDuration time.Duration `mapstructure:"role_session_duration"`
+	// WebIdentityTokenFile is the path of a web identity token, such as the one
+	// projected by IRSA in EKS, used to assume RoleArn with AssumeRoleWithWebIdentity.
+	WebIdentityTokenFile string `mapstructure:"web_identity_token_file"`
+	// STSRegion is the region of the STS endpoint used to assume RoleArn,
+	// defaults to the region of the exporter.
+	STSRegion string `mapstructure:"sts_region"`
+	// STSEndpoint overrides the STS endpoint used to assume RoleArn.
+	STSEndpoint string `mapstructure:"sts_endpoint"`
 	// S3ForcePathStyle sets the value for force path style.
 	S3ForcePathStyle bool `mapstructure:"s3_force_path_style"`
 	// DisableSLL forces communication to happen via HTTP instead of HTTPS.
 	DisableSSL bool `mapstructure:"disable_ssl"`
-	// ACL is the canned ACL to use when uploading objects.
+	// ACL is the canned ACL to use when uploading objects. It is not sent when
+	// ObjectOwnership is BucketOwnerEnforced.
 	ACL string `mapstructure:"acl"`
+	// ObjectOwnership is the object ownership of the bucket: BucketOwnerEnforced,
+	// BucketOwnerPreferred or ObjectWriter. ACLs are disabled on the buckets with
+	// BucketOwnerEnforced, which reject the uploads with a canned ACL, so ACL is not
+	// sent for them. It is also set on the bucket created by EnsureBucket.
+	ObjectOwnership string `mapstructure:"object_ownership"`
+	// RequesterPays acknowledges that the requests are charged to the requester, as
+	// required by the buckets configured with Requester Pays.
+	RequesterPays bool `mapstructure:"requester_pays"`
 
 	StorageClass string `mapstructure:"storage_class"`
 	// Compression sets the algorithm used to process the payload
 	// before uploading to S3.
 	// Valid values are: `gzip` or no value set.
 	Compression configcompression.Type `mapstructure:"compression"`
+	// CompressionMinSize is the payload size, in bytes, below which objects are
+	// uploaded uncompressed since the compression overhead outweighs the savings.
+	// Zero compresses every payload.
+	CompressionMinSize int `mapstructure:"compression_min_size"`
+	// CompressPerRecord compresses every resource of a payload as a gzip member of
+	// its own, the object being their concatenation, so that consumers can
+	// decompress a range of the object without reading it from its start.
+	CompressPerRecord bool `mapstructure:"compress_per_record"`
+	// MaxObjectSize is the size, in bytes, above which the marshaled data of a batch is
+	// split into several objects at its resource boundaries. Zero disables splitting.
+	MaxObjectSize int64 `mapstructure:"max_object_size"`
 
 	// RetryMode specifies the retry mode for S3 client, default is "standard".
 	// Valid values are: "standard", "adaptive", or "nop".
@@ -61,8 +162,80 @@ type S3UploaderConfig struct {
 
 	// UniqueKeyFuncName specifies a function to use for generating a unique string as part of the S3 key.
 	// If unspecified, a default function will be used that generates a random string.
-	// Valid values are: "uuidv7"
+	// Valid values are: "uuidv7", "uuidv5", derived from the content of the object, or
+	// "sequence", a monotonic sequence number per key prefix.
 	UniqueKeyFuncName string `mapstructure:"unique_key_func_name"`
+
+	// ServerSideEncryption is the server side encryption applied to the uploaded objects.
+	// Valid values are: "AES256", "aws:kms", "aws:kms:dsse" or no value set.
+	ServerSideEncryption string `mapstructure:"server_side_encryption"`
+	// SSEKMSKeyID is the KMS key used when ServerSideEncryption is KMS based,
+	// as a key ID, key ARN or alias ARN. If unspecified, the AWS managed key is used.
+	SSEKMSKeyID string `mapstructure:"sse_kms_key_id"`
+	// BucketKeyEnabled uses an S3 Bucket Key for the "aws:kms" ServerSideEncryption,
+	// reducing the number of requests to KMS, and their cost.
+	BucketKeyEnabled bool `mapstructure:"bucket_key_enabled"`
+	// SSECustomerKey is the base64 encoded 256-bit key the uploaded objects are encrypted
+	// with by S3 (SSE-C), for the accounts where KMS cannot be used.
+	SSECustomerKey configopaque.String `mapstructure:"sse_customer_key"`
+	// SSECustomerKeyProvider is the extension providing the SSE-C key of every upload, in
+	// place of SSECustomerKey, e.g. to fetch it from a secret store. It must implement
+	// SSECustomerKeyProvider.
+	SSECustomerKeyProvider *component.ID `mapstructure:"sse_customer_key_provider"`
+
+	// ObjectLockMode is the S3 Object Lock retention mode of the uploaded objects,
+	// "GOVERNANCE" or "COMPLIANCE", for buckets with Object Lock enabled.
+	ObjectLockMode string `mapstructure:"object_lock_mode"`
+	// ObjectLockRetention is the duration, from their upload, the uploaded objects
+	// are retained for under ObjectLockMode.
+	ObjectLockRetention time.Duration `mapstructure:"object_lock_retention"`
+
+	// ObjectTags are the tags of the uploaded objects, sent as x-amz-tagging, e.g. to
+	// scope lifecycle rules or for cost allocation.
+	ObjectTags map[string]string `mapstructure:"object_tags"`
+	// ObjectMetadata is the user metadata of the uploaded objects, sent as x-amz-meta-* headers.
+	ObjectMetadata map[string]string `mapstructure:"object_metadata"`
+
+	// EnsureBucket creates S3Bucket at start when it does not exist, in Region, with
+	// ServerSideEncryption as its default encryption and BucketLifecycleRules as its
+	// lifecycle configuration. An existing bucket is left untouched.
+	EnsureBucket bool `mapstructure:"ensure_bucket"`
+	// BucketLifecycleRules are the lifecycle rules of the bucket created by EnsureBucket.
+	BucketLifecycleRules []BucketLifecycleRule `mapstructure:"bucket_lifecycle_rules"`
+
+	// LocalDirectory writes the objects to this directory, as files at their bucket and
+	// key within it, instead of uploading them to S3, e.g. to validate the partitioning,
+	// marshaling and compression settings in CI without AWS credentials.
+	LocalDirectory string `mapstructure:"local_directory"`
+
+	// MaxConcurrentUploads limits the number of objects uploaded at once by the exporter.
+	// Zero does not limit them.
+	MaxConcurrentUploads int `mapstructure:"max_concurrent_uploads"`
+	// UploadTimeout bounds the upload of every object, its retries included, separately
+	// from the timeout of the export. Zero does not bound it.
+	UploadTimeout time.Duration `mapstructure:"upload_timeout"`
+	// SurfaceThrottling stops the S3 client from retrying the requests throttled by S3,
+	// e.g. with 503 SlowDown, and fails the export with a throttle error honoring the
+	// Retry-After of the response instead, for retry_on_failure to retry it later.
+	SurfaceThrottling bool `mapstructure:"surface_throttling"`
+}
+
+// BucketLifecycleRule is a lifecycle rule of the bucket created by EnsureBucket.
+type BucketLifecycleRule struct {
+	// ID identifies the rule in the lifecycle configuration of the bucket.
+	ID string `mapstructure:"id"`
+	// Prefix restricts the rule to the keys starting with it.
+	// Defaults to S3Prefix.
+	Prefix string `mapstructure:"prefix"`
+	// ExpirationDays is the number of days after which objects are deleted.
+	// Disabled when 0.
+	ExpirationDays int32 `mapstructure:"expiration_days"`
+	// TransitionDays is the number of days after which objects are moved
+	// to TransitionStorageClass. Disabled when 0.
+	TransitionDays         int32  `mapstructure:"transition_days"`
+	TransitionStorageClass string `mapstructure:"transition_storage_class"`
+	// prevent unkeyed literal initialization
+	_ struct{}
 }
 
 type MarshalerType string
@@ -72,6 +245,110 @@ const (
 	OtlpJSON     MarshalerType = "otlp_json"
 	SumoIC       MarshalerType = "sumo_ic"
 	Body         MarshalerType = "body"
+	BodyJSONL    MarshalerType = "body_jsonl"
+	Parquet      MarshalerType = "parquet"
+	Passthrough  MarshalerType = "passthrough"
+)
+
+// ParquetConfig controls the Parquet files written by the parquet marshaler.
+type ParquetConfig struct {
+	// RowGroupSize is the maximum number of rows, i.e. log records or data points,
+	// of a row group.
+	RowGroupSize int `mapstructure:"row_group_size"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// PassthroughConfig controls the objects written by the passthrough marshaler.
+type PassthroughConfig struct {
+	// FileExtension is the extension of the objects, "bin" when empty.
+	FileExtension string `mapstructure:"file_extension"`
+	// ContentType is the content type of the objects, "application/octet-stream" when empty.
+	ContentType string `mapstructure:"content_type"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+func (c PassthroughConfig) fileExtension() string {
+	if c.FileExtension == "" {
+		return "bin"
+	}
+	return c.FileExtension
+}
+
+func (c PassthroughConfig) contentType() string {
+	if c.ContentType == "" {
+		return "application/octet-stream"
+	}
+	return c.ContentType
+}
+
+// SignalEncoding is the encoding extension of the objects of a signal.
+type SignalEncoding struct {
+	// Encoding is the encoding extension marshaling the signal.
+	Encoding *component.ID `mapstructure:"encoding"`
+	// FileExtension is the file extension of the objects, as encoding_file_extension.
+	FileExtension string `mapstructure:"file_extension"`
+}
+
+// SignalEncodings sets the encoding extension of each signal, e.g. to write the
+// logs as text while the traces are kept as OTLP.
+type SignalEncodings struct {
+	Logs    SignalEncoding `mapstructure:"logs"`
+	Metrics SignalEncoding `mapstructure:"metrics"`
+	Traces  SignalEncoding `mapstructure:"traces"`
+}
+
+func (c *SignalEncodings) validate() error {
+	var errs error
+	for _, signal := range []struct {
+		name     string
+		encoding SignalEncoding
+	}{{"logs", c.Logs}, {"metrics", c.Metrics}, {"traces", c.Traces}} {
+		if signal.encoding.Encoding == nil && signal.encoding.FileExtension != "" {
+			errs = multierr.Append(errs, fmt.Errorf("encodings::%s::file_extension requires encoding", signal.name))
+		}
+	}
+	return errs
+}
+
+// signalEncoding returns the encoding extension of the signal and the file
+// extension of its objects, the encoding being nil when the signal is marshaled
+// by the marshaler.
+func (c *Config) signalEncoding(signal string) (*component.ID, string) {
+	var encoding SignalEncoding
+	switch signal {
+	case "logs":
+		encoding = c.Encodings.Logs
+	case "metrics":
+		encoding = c.Encodings.Metrics
+	case "traces":
+		encoding = c.Encodings.Traces
+	}
+	if encoding.Encoding != nil {
+		return encoding.Encoding, encoding.FileExtension
+	}
+	return c.Encoding, c.EncodingFileExtension
+}
+
+// contentType returns the content type of the uploaded objects of the signal,
+// empty when it is left to S3.
+func (c *Config) contentType(signal string) string {
+	if encoding, _ := c.signalEncoding(signal); encoding == nil && c.MarshalerName == Passthrough {
+		return c.Passthrough.contentType()
+	}
+	return ""
+}
+
+// FramingType is the framing of the records within the uploaded objects.
+type FramingType string
+
+const (
+	// FramingNewline writes every resource as a record followed by a newline.
+	FramingNewline FramingType = "newline"
+	// FramingLengthPrefixed writes every resource as a record preceded by its
+	// size, as an unsigned varint.
+	FramingLengthPrefixed FramingType = "length_prefixed"
 )
 
 // ResourceAttrsToS3 defines the mapping of S3 uploading configuration values to resource attribute values.
@@ -80,21 +357,162 @@ type ResourceAttrsToS3 struct {
 	S3Bucket string `mapstructure:"s3_bucket"`
 	// S3Prefix indicates the mapping of the key (directory) prefix used for writing into the bucket to a specific resource attribute value.
 	S3Prefix string `mapstructure:"s3_prefix"`
+	// SSEKMSEncryptionContext maps SSE-KMS encryption context keys to the resource attribute
+	// holding their value, so decrypt permissions can be scoped by resource, e.g. per tenant.
+	SSEKMSEncryptionContext map[string]string `mapstructure:"sse_kms_encryption_context"`
+	// ObjectTags maps object tag keys to the resource attribute holding their value,
+	// taking precedence over the static S3UploaderConfig.ObjectTags.
+	ObjectTags map[string]string `mapstructure:"object_tags"`
+	// ObjectMetadata maps object user metadata keys to the resource attribute holding
+	// their value, taking precedence over the static S3UploaderConfig.ObjectMetadata.
+	ObjectMetadata map[string]string `mapstructure:"object_metadata"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// ClientMetadataToS3 defines the mapping of S3 uploading configuration values to the
+// metadata of the clients the data was received from, e.g. the headers of their requests.
+type ClientMetadataToS3 struct {
+	// S3Bucket is the client metadata key holding the name of the bucket used for uploading.
+	S3Bucket string `mapstructure:"s3_bucket"`
+	// S3Prefix is the client metadata key holding the key (directory) prefix used for writing into the bucket.
+	S3Prefix string `mapstructure:"s3_prefix"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// ConsolidationConfig controls the merging of the objects written during an hour
+// into a single object once that hour is over.
+type ConsolidationConfig struct {
+	// Enabled turns on the hourly consolidation of uploaded objects.
+	Enabled bool `mapstructure:"enabled"`
+	// Delay is how long to wait after the end of an hour before consolidating it,
+	// so that uploads still in flight for that hour are included.
+	Delay time.Duration `mapstructure:"delay"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// AggregationConfig controls the buffering of the marshaled payloads, uploaded as a
+// single object once they reach MaxSize or once Interval elapsed.
+type AggregationConfig struct {
+	// Enabled turns on the aggregation of the payloads before upload.
+	Enabled bool `mapstructure:"enabled"`
+	// MaxSize is the size, in bytes, at which the payloads of an object are uploaded.
+	MaxSize int64 `mapstructure:"max_size"`
+	// Interval is the maximum time a payload is buffered before being uploaded.
+	Interval time.Duration `mapstructure:"interval"`
+	// MaxMemorySize is the size, in bytes, of the payloads buffered in memory across
+	// objects, above which they are spilled to SpillDirectory, or rejected without it.
+	MaxMemorySize int64 `mapstructure:"max_memory_size"`
+	// SpillDirectory holds the payloads spilled to disk. Spilling is disabled when empty.
+	SpillDirectory string `mapstructure:"spill_directory"`
+	// MaxSpillSize is the size, in bytes, of the payloads spilled to disk above which
+	// payloads are rejected.
+	MaxSpillSize int64 `mapstructure:"max_spill_size"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// NotificationConfig configures the message published after every successful upload,
+// describing the uploaded object. At most one destination can be set.
+type NotificationConfig struct {
+	// SQSQueueURL is the URL of the SQS queue the notifications are sent to.
+	SQSQueueURL string `mapstructure:"sqs_queue_url"`
+	// SNSTopicARN is the ARN of the SNS topic the notifications are published to.
+	SNSTopicARN string `mapstructure:"sns_topic_arn"`
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// FailoverConfig configures the secondary bucket the uploads fail over to once the
+// uploads to the primary bucket keep failing. Failover is disabled without S3Bucket.
+type FailoverConfig struct {
+	// S3Bucket is the name of the secondary bucket.
+	S3Bucket string `mapstructure:"s3_bucket"`
+	// Region is the region of the secondary bucket, the region of the primary one when empty.
+	Region string `mapstructure:"region"`
+	// Endpoint overrides the endpoint of the secondary bucket. It is not inherited
+	// from the primary bucket, whose endpoint is usually bound to its region.
+	Endpoint string `mapstructure:"endpoint"`
+	// FailureThreshold is the number of consecutive failed uploads to the primary
+	// bucket after which the uploads fail over.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// FailbackInterval is how often an upload is tried on the primary bucket while
+	// failed over, the uploads failing back once one succeeds.
+	FailbackInterval time.Duration `mapstructure:"failback_interval"`
 	// prevent unkeyed literal initialization
 	_ struct{}
 }
 
+func (c *FailoverConfig) enabled() bool {
+	return c.S3Bucket != ""
+}
+
+// failoverConfig returns the configuration of the uploads to the failover bucket,
+// the one of the primary bucket with the bucket, region and endpoint replaced.
+func (c *Config) failoverConfig() *Config {
+	conf := *c
+	conf.S3Uploader.S3Bucket = c.Failover.S3Bucket
+	if c.Failover.Region != "" {
+		conf.S3Uploader.Region = c.Failover.Region
+	}
+	conf.S3Uploader.Endpoint = c.Failover.Endpoint
+	return &conf
+}
+
+func (c *FailoverConfig) validate() error {
+	if !c.enabled() {
+		if c.Region != "" || c.Endpoint != "" {
+			return errors.New("failover region and endpoint require failover s3_bucket")
+		}
+		return nil
+	}
+	var errs error
+	if c.FailureThreshold <= 0 {
+		errs = multierr.Append(errs, errors.New("failover failure_threshold must be positive"))
+	}
+	if c.FailbackInterval <= 0 {
+		errs = multierr.Append(errs, errors.New("failover failback_interval must be positive"))
+	}
+	return errs
+}
+
 // Config contains the main configuration options for the s3 exporter
 type Config struct {
 	QueueSettings   exporterhelper.QueueBatchConfig `mapstructure:"sending_queue"`
 	TimeoutSettings exporterhelper.TimeoutConfig    `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct.
+	BackOffConfig   configretry.BackOffConfig       `mapstructure:"retry_on_failure"`
 	S3Uploader      S3UploaderConfig                `mapstructure:"s3uploader"`
 	MarshalerName   MarshalerType                   `mapstructure:"marshaler"`
+	// Framing writes every resource as a record of its own, framed as by
+	// Kinesis Data Firehose. The objects hold a single payload when empty.
+	Framing FramingType `mapstructure:"framing"`
+	// Parquet configures the parquet marshaler.
+	Parquet ParquetConfig `mapstructure:"parquet"`
+	// Passthrough configures the passthrough marshaler.
+	Passthrough PassthroughConfig `mapstructure:"passthrough"`
 
 	// Encoding to apply. If present, overrides the marshaler configuration option.
-	Encoding              *component.ID     `mapstructure:"encoding"`
-	EncodingFileExtension string            `mapstructure:"encoding_file_extension"`
-	ResourceAttrsToS3     ResourceAttrsToS3 `mapstructure:"resource_attrs_to_s3"`
+	Encoding              *component.ID `mapstructure:"encoding"`
+	EncodingFileExtension string        `mapstructure:"encoding_file_extension"`
+	// Encodings overrides Encoding, and the marshaler, for each signal.
+	Encodings         SignalEncodings   `mapstructure:"encodings"`
+	ResourceAttrsToS3 ResourceAttrsToS3 `mapstructure:"resource_attrs_to_s3"`
+	// ClientMetadataToS3 selects the bucket and prefix from the client metadata, when
+	// the resource attributes of ResourceAttrsToS3 are missing.
+	ClientMetadataToS3 ClientMetadataToS3 `mapstructure:"client_metadata_to_s3"`
+	// Consolidation merges the objects of each hour into a single object.
+	Consolidation ConsolidationConfig `mapstructure:"consolidation"`
+	// Aggregation buffers the payloads of small batches into larger objects.
+	Aggregation AggregationConfig `mapstructure:"aggregation"`
+	// Notification publishes a message describing every uploaded object.
+	Notification NotificationConfig `mapstructure:"notification"`
+	// Failover uploads to a secondary bucket while the primary one is failing.
+	Failover FailoverConfig `mapstructure:"failover"`
+	// DrainTimeout bounds the time spent on shutdown flushing the data still being exported,
+	// the sending queue included. The data not flushed by then is dropped. Zero waits for it all.
+	DrainTimeout time.Duration `mapstructure:"drain_timeout"`
 }
 
 func (c *Config) Validate() error {
@@ -119,7 +537,15 @@ func (c *Config) Validate() error {
 	}
 
 	validUniqueKeyFuncs := map[string]bool{
-		"uuidv7": true,
+		"uuidv7":   true,
+		"uuidv5":   true,
+		"sequence": true,
+	}
+
+	validServerSideEncryptions := map[string]bool{
+		"AES256":       true,
+		"aws:kms":      true,
+		"aws:kms:dsse": true,
 	}
 
 	if c.S3Uploader.Region == "" {
@@ -129,6 +555,34 @@ func (c *Config) Validate() error {
 		errs = multierr.Append(errs, errors.New("bucket or endpoint is required"))
 	}
 
+	if _, err := parsePrefixTemplate(c.S3Uploader.S3Prefix); err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("invalid s3_prefix: %w", err))
+	}
+
+	switch c.S3Uploader.S3PartitionScheme {
+	case "", PartitionSchemeStrftime, PartitionSchemeHive:
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("invalid s3_partition_scheme %q, must be either %q or %q",
+			c.S3Uploader.S3PartitionScheme, PartitionSchemeStrftime, PartitionSchemeHive))
+	}
+	if c.S3Uploader.S3PartitionBySignal && c.S3Uploader.S3PartitionScheme != PartitionSchemeHive {
+		errs = multierr.Append(errs, errors.New("s3_partition_by_signal requires the hive s3_partition_scheme"))
+	}
+	switch c.S3Uploader.S3PartitionTimeSource {
+	case "", PartitionTimeUpload:
+	case PartitionTimeEarliestRecord, PartitionTimeLatestRecord:
+		// Both merge objects written at the same time, whatever the time of their records.
+		if c.Consolidation.Enabled {
+			errs = multierr.Append(errs, errors.New("consolidation requires the upload s3_partition_time_source"))
+		}
+		if c.Aggregation.Enabled {
+			errs = multierr.Append(errs, errors.New("aggregation requires the upload s3_partition_time_source"))
+		}
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("invalid s3_partition_time_source %q, must be either %q, %q or %q",
+			c.S3Uploader.S3PartitionTimeSource, PartitionTimeUpload, PartitionTimeEarliestRecord, PartitionTimeLatestRecord))
+	}
+
 	if !validStorageClasses[c.S3Uploader.StorageClass] {
 		errs = multierr.Append(errs, errors.New("invalid StorageClass"))
 	}
@@ -137,6 +591,12 @@ func (c *Config) Validate() error {
 		errs = multierr.Append(errs, errors.New("invalid ACL"))
 	}
 
+	switch s3types.ObjectOwnership(c.S3Uploader.ObjectOwnership) {
+	case "", s3types.ObjectOwnershipBucketOwnerEnforced, s3types.ObjectOwnershipBucketOwnerPreferred, s3types.ObjectOwnershipObjectWriter:
+	default:
+		errs = multierr.Append(errs, errors.New("invalid object_ownership, must be either 'BucketOwnerEnforced', 'BucketOwnerPreferred' or 'ObjectWriter'"))
+	}
+
 	compression := c.S3Uploader.Compression
 	if compression.IsCompressed() {
 		if compression != configcompression.TypeGzip {
@@ -148,6 +608,37 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	if c.S3Uploader.CompressionMinSize < 0 {
+		errs = multierr.Append(errs, errors.New("compression_min_size must not be negative"))
+	} else if c.S3Uploader.CompressionMinSize > 0 && !compression.IsCompressed() {
+		errs = multierr.Append(errs, errors.New("compression_min_size requires compression"))
+	}
+
+	if c.S3Uploader.CompressPerRecord {
+		if !compression.IsCompressed() {
+			errs = multierr.Append(errs, errors.New("compress_per_record requires compression"))
+		}
+		if c.S3Uploader.CompressionMinSize > 0 {
+			errs = multierr.Append(errs, errors.New("compress_per_record cannot be combined with compression_min_size"))
+		}
+		if c.Consolidation.Enabled {
+			errs = multierr.Append(errs, errors.New("compress_per_record cannot be combined with consolidation"))
+		}
+	}
+
+	if c.S3Uploader.MaxObjectSize < 0 {
+		errs = multierr.Append(errs, errors.New("max_object_size must not be negative"))
+	}
+	if c.S3Uploader.MaxConcurrentUploads < 0 {
+		errs = multierr.Append(errs, errors.New("max_concurrent_uploads must not be negative"))
+	}
+	if c.S3Uploader.UploadTimeout < 0 {
+		errs = multierr.Append(errs, errors.New("upload_timeout must not be negative"))
+	}
+	if c.S3Uploader.SurfaceThrottling && !c.BackOffConfig.Enabled {
+		errs = multierr.Append(errs, errors.New("surface_throttling requires retry_on_failure to be enabled"))
+	}
+
 	if c.S3Uploader.RetryMode != "nop" && c.S3Uploader.RetryMode != "standard" && c.S3Uploader.RetryMode != "adaptive" {
 		errs = multierr.Append(errs, errors.New("invalid retry mode, must be either 'standard', 'adaptive' or 'nop'"))
 	}
@@ -155,5 +646,320 @@ func (c *Config) Validate() error {
 	if c.S3Uploader.UniqueKeyFuncName != "" && !validUniqueKeyFuncs[c.S3Uploader.UniqueKeyFuncName] {
 		errs = multierr.Append(errs, errors.New("invalid UniqueKeyFuncName"))
 	}
+
+	sse := c.S3Uploader.ServerSideEncryption
+	if sse != "" && !validServerSideEncryptions[sse] {
+		errs = multierr.Append(errs, errors.New("invalid ServerSideEncryption"))
+	}
+	if !strings.HasPrefix(sse, "aws:kms") {
+		if c.S3Uploader.SSEKMSKeyID != "" {
+			errs = multierr.Append(errs, errors.New("sse_kms_key_id requires a KMS based server_side_encryption"))
+		}
+		if len(c.ResourceAttrsToS3.SSEKMSEncryptionContext) > 0 {
+			errs = multierr.Append(errs, errors.New("sse_kms_encryption_context requires a KMS based server_side_encryption"))
+		}
+	}
+	if c.S3Uploader.BucketKeyEnabled && sse != "aws:kms" {
+		errs = multierr.Append(errs, errors.New("bucket_key_enabled requires the aws:kms server_side_encryption"))
+	}
+	errs = multierr.Append(errs, c.S3Uploader.validateSSECustomerKey())
+
+	switch c.S3Uploader.ObjectLockMode {
+	case "":
+		if c.S3Uploader.ObjectLockRetention != 0 {
+			errs = multierr.Append(errs, errors.New("object_lock_retention requires object_lock_mode"))
+		}
+	case "GOVERNANCE", "COMPLIANCE":
+		if c.S3Uploader.ObjectLockRetention <= 0 {
+			errs = multierr.Append(errs, errors.New("object_lock_mode requires a positive object_lock_retention"))
+		}
+		if c.Consolidation.Enabled {
+			// the consolidation deletes the objects it merged, which are locked
+			errs = multierr.Append(errs, errors.New("consolidation cannot be combined with object_lock_mode"))
+		}
+	default:
+		errs = multierr.Append(errs, errors.New("invalid object_lock_mode, must be either 'GOVERNANCE' or 'COMPLIANCE'"))
+	}
+	for key, attr := range c.ResourceAttrsToS3.SSEKMSEncryptionContext {
+		if key == "" || attr == "" {
+			errs = multierr.Append(errs, errors.New("sse_kms_encryption_context keys and resource attributes must not be empty"))
+			break
+		}
+	}
+
+	errs = multierr.Append(errs, c.validateObjectTags())
+	errs = multierr.Append(errs, c.Encodings.validate())
+
+	switch c.Framing {
+	case "", FramingNewline, FramingLengthPrefixed:
+	default:
+		errs = multierr.Append(errs, fmt.Errorf("invalid framing %q, must be either %q or %q", c.Framing, FramingNewline, FramingLengthPrefixed))
+	}
+	if c.Framing != "" && c.Encoding == nil && (c.MarshalerName == SumoIC || c.MarshalerName == Body || c.MarshalerName == BodyJSONL || c.MarshalerName == Passthrough) {
+		errs = multierr.Append(errs, errors.New("framing is not supported by the marshaler"))
+	}
+
+	if c.Encoding == nil && c.MarshalerName == Parquet {
+		if c.Framing != "" {
+			errs = multierr.Append(errs, errors.New("framing is not supported by the marshaler"))
+		}
+		if compression.IsCompressed() {
+			errs = multierr.Append(errs, errors.New("parquet marshaler does not support compression, its files are compressed internally"))
+		}
+		if c.Consolidation.Enabled {
+			errs = multierr.Append(errs, errors.New("consolidation cannot be combined with the parquet marshaler"))
+		}
+		if c.Aggregation.Enabled {
+			errs = multierr.Append(errs, errors.New("aggregation cannot be combined with the parquet marshaler"))
+		}
+	}
+	if c.Parquet.RowGroupSize <= 0 {
+		errs = multierr.Append(errs, errors.New("parquet row_group_size must be positive"))
+	}
+
+	errs = multierr.Append(errs, c.validateEnsureBucket())
+	errs = multierr.Append(errs, c.S3Uploader.validateRole())
+	errs = multierr.Append(errs, c.Aggregation.validate())
+	if c.Aggregation.Enabled && c.S3Uploader.MaxObjectSize > 0 && c.Aggregation.MaxSize > c.S3Uploader.MaxObjectSize {
+		errs = multierr.Append(errs, errors.New("aggregation max_size must not be greater than max_object_size"))
+	}
+	errs = multierr.Append(errs, c.Failover.validate())
+
+	if c.S3Uploader.LocalDirectory != "" {
+		if c.S3Uploader.EnsureBucket {
+			errs = multierr.Append(errs, errors.New("local_directory cannot be combined with ensure_bucket"))
+		}
+		if c.Notification.SQSQueueURL != "" || c.Notification.SNSTopicARN != "" {
+			errs = multierr.Append(errs, errors.New("local_directory cannot be combined with notification"))
+		}
+		if c.Consolidation.Enabled {
+			errs = multierr.Append(errs, errors.New("local_directory cannot be combined with consolidation"))
+		}
+		if c.Failover.enabled() {
+			errs = multierr.Append(errs, errors.New("local_directory cannot be combined with failover"))
+		}
+	}
+
+	if c.DrainTimeout < 0 {
+		errs = multierr.Append(errs, errors.New("drain_timeout must not be negative"))
+	}
+
+	if c.Notification.SQSQueueURL != "" && c.Notification.SNSTopicARN != "" {
+		errs = multierr.Append(errs, errors.New("notification sqs_queue_url and sns_topic_arn are mutually exclusive"))
+	}
+
+	if c.Consolidation.Enabled {
+		if len(c.ResourceAttrsToS3.SSEKMSEncryptionContext) > 0 {
+			errs = multierr.Append(errs, errors.New("consolidation cannot be combined with sse_kms_encryption_context"))
+		}
+		if len(c.ResourceAttrsToS3.ObjectTags) > 0 || len(c.ResourceAttrsToS3.ObjectMetadata) > 0 {
+			errs = multierr.Append(errs, errors.New("consolidation cannot be combined with resource_attrs_to_s3 object_tags or object_metadata"))
+		}
+		if !strings.Contains(c.S3Uploader.partitionFormat(""), "%H") {
+			errs = multierr.Append(errs, errors.New("consolidation requires s3_partition_format to partition by hour (%H)"))
+		}
+		if c.Notification.SQSQueueURL != "" || c.Notification.SNSTopicARN != "" {
+			errs = multierr.Append(errs, errors.New("consolidation cannot be combined with notification"))
+		}
+		if c.Consolidation.Delay < 0 || c.Consolidation.Delay >= time.Hour {
+			errs = multierr.Append(errs, errors.New("consolidation delay must be between 0 and 1h"))
+		}
+		if c.Failover.enabled() {
+			errs = multierr.Append(errs, errors.New("consolidation cannot be combined with failover"))
+		}
+		if c.S3Uploader.sseCustomerKeyEnabled() {
+			errs = multierr.Append(errs, errors.New("consolidation cannot be combined with sse_customer_key"))
+		}
+	}
+	return errs
+}
+
+// maxObjectTags is the maximum number of tags of an S3 object.
+const maxObjectTags = 10
+
+func (c *Config) validateObjectTags() error {
+	var errs error
+	tags := make(map[string]struct{}, len(c.S3Uploader.ObjectTags)+len(c.ResourceAttrsToS3.ObjectTags))
+	for key := range c.S3Uploader.ObjectTags {
+		if key == "" {
+			errs = multierr.Append(errs, errors.New("object_tags keys must not be empty"))
+		}
+		tags[key] = struct{}{}
+	}
+	for key, attr := range c.ResourceAttrsToS3.ObjectTags {
+		if key == "" || attr == "" {
+			errs = multierr.Append(errs, errors.New("resource_attrs_to_s3 object_tags keys and resource attributes must not be empty"))
+		}
+		tags[key] = struct{}{}
+	}
+	for key := range tags {
+		if strings.HasPrefix(key, "aws:") {
+			errs = multierr.Append(errs, fmt.Errorf("object tag %q must not use the reserved aws: prefix", key))
+		}
+	}
+	if len(tags) > maxObjectTags {
+		errs = multierr.Append(errs, fmt.Errorf("objects can have at most %d tags, got %d", maxObjectTags, len(tags)))
+	}
+	for key := range c.S3Uploader.ObjectMetadata {
+		if key == "" {
+			errs = multierr.Append(errs, errors.New("object_metadata keys must not be empty"))
+		}
+	}
+	for key, attr := range c.ResourceAttrsToS3.ObjectMetadata {
+		if key == "" || attr == "" {
+			errs = multierr.Append(errs, errors.New("resource_attrs_to_s3 object_metadata keys and resource attributes must not be empty"))
+		}
+	}
+	return errs
+}
+
+func (c *AggregationConfig) validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	var errs error
+	if c.MaxSize <= 0 {
+		errs = multierr.Append(errs, errors.New("aggregation max_size must be positive"))
+	}
+	if c.Interval <= 0 {
+		errs = multierr.Append(errs, errors.New("aggregation interval must be positive"))
+	}
+	if c.MaxMemorySize < c.MaxSize {
+		errs = multierr.Append(errs, errors.New("aggregation max_memory_size must not be lower than max_size"))
+	}
+	if c.SpillDirectory != "" && c.MaxSpillSize <= 0 {
+		errs = multierr.Append(errs, errors.New("aggregation max_spill_size must be positive"))
+	}
+	return errs
+}
+
+func (c *Config) validateEnsureBucket() error {
+	var errs error
+	validTransitionStorageClasses := map[string]bool{
+		"STANDARD_IA":         true,
+		"ONEZONE_IA":          true,
+		"INTELLIGENT_TIERING": true,
+		"GLACIER":             true,
+		"GLACIER_IR":          true,
+		"DEEP_ARCHIVE":        true,
+	}
+
+	if !c.S3Uploader.EnsureBucket {
+		if len(c.S3Uploader.BucketLifecycleRules) > 0 {
+			errs = multierr.Append(errs, errors.New("bucket_lifecycle_rules requires ensure_bucket"))
+		}
+		return errs
+	}
+	if c.S3Uploader.S3Bucket == "" {
+		errs = multierr.Append(errs, errors.New("ensure_bucket requires s3_bucket"))
+	}
+	ids := make(map[string]bool, len(c.S3Uploader.BucketLifecycleRules))
+	for _, rule := range c.S3Uploader.BucketLifecycleRules {
+		if rule.ID == "" || ids[rule.ID] {
+			errs = multierr.Append(errs, errors.New("bucket lifecycle rule ids must be unique and not empty"))
+		}
+		ids[rule.ID] = true
+		if rule.ExpirationDays < 0 || rule.TransitionDays < 0 {
+			errs = multierr.Append(errs, fmt.Errorf("bucket lifecycle rule %q days must not be negative", rule.ID))
+		}
+		if rule.ExpirationDays == 0 && rule.TransitionDays == 0 {
+			errs = multierr.Append(errs, fmt.Errorf("bucket lifecycle rule %q requires expiration_days or transition_days", rule.ID))
+		}
+		if rule.TransitionDays > 0 && !validTransitionStorageClasses[rule.TransitionStorageClass] {
+			errs = multierr.Append(errs, fmt.Errorf("bucket lifecycle rule %q has an invalid transition_storage_class", rule.ID))
+		}
+		if rule.TransitionDays > 0 && rule.ExpirationDays > 0 && rule.ExpirationDays <= rule.TransitionDays {
+			errs = multierr.Append(errs, fmt.Errorf("bucket lifecycle rule %q expiration_days must be after transition_days", rule.ID))
+		}
+	}
+	return errs
+}
+
+// validateSSECustomerKey checks that the customer provided key, when enabled, is a
+// valid key and is not combined with another server side encryption or plain HTTP.
+func (c *S3UploaderConfig) validateSSECustomerKey() error {
+	if !c.sseCustomerKeyEnabled() {
+		return nil
+	}
+	var errs error
+	if c.SSECustomerKey != "" {
+		if c.SSECustomerKeyProvider != nil {
+			errs = multierr.Append(errs, errors.New("sse_customer_key and sse_customer_key_provider are mutually exclusive"))
+		} else if _, err := decodeSSECustomerKey(string(c.SSECustomerKey)); err != nil {
+			errs = multierr.Append(errs, err)
+		}
+	}
+	if c.ServerSideEncryption != "" {
+		errs = multierr.Append(errs, errors.New("sse_customer_key cannot be combined with server_side_encryption"))
+	}
+	if c.DisableSSL {
+		// S3 rejects the keys sent over plain HTTP.
+		errs = multierr.Append(errs, errors.New("sse_customer_key requires SSL"))
+	}
+	return errs
+}
+
+// acl returns the canned ACL sent with the uploads, none when ACLs are disabled
+// on the bucket.
+func (c *S3UploaderConfig) acl() string {
+	if s3types.ObjectOwnership(c.ObjectOwnership) == s3types.ObjectOwnershipBucketOwnerEnforced {
+		return ""
+	}
+	return c.ACL
+}
+
+// requestPayer returns the payer of the requests, the bucket owner when empty.
+func (c *S3UploaderConfig) requestPayer() s3types.RequestPayer {
+	if c.RequesterPays {
+		return s3types.RequestPayerRequester
+	}
+	return ""
+}
+
+// partitionFormat returns the strftime format of the time partition of the keys of
+// signal, one of "logs", "metrics" or "traces".
+func (c *S3UploaderConfig) partitionFormat(signal string) string {
+	if c.S3PartitionScheme != PartitionSchemeHive {
+		return c.S3PartitionFormat
+	}
+	if c.S3PartitionBySignal {
+		return "signal=" + signal + "/" + hivePartitionFormat
+	}
+	return hivePartitionFormat
+}
+
+// batchAttributes returns the resource attributes the data is grouped by before being uploaded, so that each
+// upload holds resources sharing the same key prefix.
+func (c *Config) batchAttributes() []string {
+	var attrs []string
+	if t, err := parsePrefixTemplate(c.S3Uploader.S3Prefix); err == nil && t != nil {
+		for _, attr := range t.attributes {
+			if !slices.Contains(attrs, attr) {
+				attrs = append(attrs, attr)
+			}
+		}
+	}
+	if c.ResourceAttrsToS3.S3Prefix != "" && !slices.Contains(attrs, c.ResourceAttrsToS3.S3Prefix) {
+		attrs = append(attrs, c.ResourceAttrsToS3.S3Prefix)
+	}
+	return attrs
+}
+
+// validateRole checks the options of the assumption of RoleArn.
+func (c *S3UploaderConfig) validateRole() error {
+	if c.RoleArn == "" {
+		if c.ExternalID != "" || c.RoleSessionName != "" || c.RoleSessionDuration != 0 ||
+			c.WebIdentityTokenFile != "" || c.STSRegion != "" || c.STSEndpoint != "" {
+			return errors.New("role assumption options require role_arn")
+		}
+		return nil
+	}
+	var errs error
+	if c.ExternalID != "" && c.WebIdentityTokenFile != "" {
+		errs = multierr.Append(errs, errors.New("external_id cannot be combined with web_identity_token_file"))
+	}
+	if c.RoleSessionDuration != 0 && (c.RoleSessionDuration < 15*time.Minute || c.RoleSessionDuration > 12*time.Hour) {
+		errs = multierr.Append(errs, errors.New("role_session_duration must be between 15m and 12h"))
+	}
 	return errs
 }
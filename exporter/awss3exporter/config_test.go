@@ -5,13 +5,17 @@ package awss3exporter
 
 import (
 	"errors"
+	"fmt"
 	"path/filepath"
 	"testing"
 	"time"
 
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/config/configretry"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
 	"go.opentelemetry.io/collector/otelcol/otelcoltest"
 	"go.uber.org/multierr"
@@ -36,10 +40,13 @@ func TestLoadConfig(t *testing.T) {
 	queueCfg := exporterhelper.NewDefaultQueueConfig()
 	queueCfg.Enabled = false
 	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
 
 	assert.Equal(t, &Config{
 		QueueSettings:         queueCfg,
 		TimeoutSettings:       timeoutCfg,
+		BackOffConfig:         backOffCfg,
 		Encoding:              &encoding,
 		EncodingFileExtension: "baz",
 		S3Uploader: S3UploaderConfig{
@@ -52,6 +59,19 @@ func TestLoadConfig(t *testing.T) {
 			RetryMaxBackoff:   DefaultRetryMaxBackoff,
 		},
 		MarshalerName: "otlp_json",
+		Consolidation: ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
 	}, e,
 	)
 }
@@ -78,12 +98,15 @@ func TestConfig(t *testing.T) {
 	timeoutCfg := exporterhelper.TimeoutConfig{
 		Timeout: 8,
 	}
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
 
 	e := cfg.Exporters[component.MustNewID("awss3")].(*Config)
 
 	assert.Equal(t, &Config{
 		QueueSettings:   queueCfg,
 		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
 		S3Uploader: S3UploaderConfig{
 			Region:            "us-east-1",
 			S3Bucket:          "foo",
@@ -96,6 +119,19 @@ func TestConfig(t *testing.T) {
 			RetryMaxBackoff:   DefaultRetryMaxBackoff,
 		},
 		MarshalerName: "otlp_json",
+		Consolidation: ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
 	}, e,
 	)
 }
@@ -117,6 +153,8 @@ func TestConfigS3StorageClass(t *testing.T) {
 	queueCfg := exporterhelper.NewDefaultQueueConfig()
 	queueCfg.Enabled = false
 	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
 
 	assert.Equal(t, &Config{
 		S3Uploader: S3UploaderConfig{
@@ -132,11 +170,37 @@ func TestConfigS3StorageClass(t *testing.T) {
 		},
 		QueueSettings:   queueCfg,
 		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
 		MarshalerName:   "otlp_json",
+		Consolidation:   ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
 	}, e,
 	)
 }
 
+func TestConfigS3ObjectOwnership(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	c.S3Uploader.ACL = "bucket-owner-full-control"
+	assert.Equal(t, "bucket-owner-full-control", c.S3Uploader.acl())
+	assert.Empty(t, c.S3Uploader.requestPayer())
+
+	c.S3Uploader.ObjectOwnership = "BucketOwnerEnforced"
+	c.S3Uploader.RequesterPays = true
+	assert.Empty(t, c.S3Uploader.acl(), "ACLs are disabled on the bucket")
+	assert.Equal(t, s3types.RequestPayerRequester, c.S3Uploader.requestPayer())
+}
+
 func TestConfigS3ACL(t *testing.T) {
 	factories, err := otelcoltest.NopFactories()
 	assert.NoError(t, err)
@@ -154,6 +218,8 @@ func TestConfigS3ACL(t *testing.T) {
 	queueCfg := exporterhelper.NewDefaultQueueConfig()
 	queueCfg.Enabled = false
 	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
 
 	assert.Equal(t, &Config{
 		S3Uploader: S3UploaderConfig{
@@ -170,7 +236,21 @@ func TestConfigS3ACL(t *testing.T) {
 		},
 		QueueSettings:   queueCfg,
 		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
 		MarshalerName:   "otlp_json",
+		Consolidation:   ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
 	}, e,
 	)
 }
@@ -192,6 +272,8 @@ func TestConfigS3ACLDefined(t *testing.T) {
 	queueCfg := exporterhelper.NewDefaultQueueConfig()
 	queueCfg.Enabled = false
 	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
 
 	assert.Equal(t, &Config{
 		S3Uploader: S3UploaderConfig{
@@ -208,7 +290,21 @@ func TestConfigS3ACLDefined(t *testing.T) {
 		},
 		QueueSettings:   queueCfg,
 		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
 		MarshalerName:   "otlp_json",
+		Consolidation:   ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
 	}, e,
 	)
 }
@@ -228,12 +324,15 @@ func TestConfigForS3CompatibleSystems(t *testing.T) {
 	queueCfg := exporterhelper.NewDefaultQueueConfig()
 	queueCfg.Enabled = false
 	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
 
 	e := cfg.Exporters[component.MustNewID("awss3")].(*Config)
 
 	assert.Equal(t, &Config{
 		QueueSettings:   queueCfg,
 		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
 		S3Uploader: S3UploaderConfig{
 			Region:            "us-east-1",
 			S3Bucket:          "foo",
@@ -248,6 +347,19 @@ func TestConfigForS3CompatibleSystems(t *testing.T) {
 			RetryMaxBackoff:   DefaultRetryMaxBackoff,
 		},
 		MarshalerName: "otlp_json",
+		Consolidation: ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
 	}, e,
 	)
 }
@@ -323,211 +435,1140 @@ func TestConfig_Validate(t *testing.T) {
 			}(),
 			errExpected: errors.New("region is required"),
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := tt.config.Validate()
-			require.Equal(t, tt.errExpected, err)
-		})
-	}
-}
-
-func TestMarshallerName(t *testing.T) {
-	factories, err := otelcoltest.NopFactories()
-	assert.NoError(t, err)
-
-	factory := NewFactory()
-	factories.Exporters[factory.Type()] = factory
-	cfg, err := otelcoltest.LoadConfigAndValidate(
-		filepath.Join("testdata", "marshaler.yaml"), factories)
-
-	require.NoError(t, err)
-	require.NotNil(t, cfg)
-
-	queueCfg := exporterhelper.NewDefaultQueueConfig()
-	queueCfg.Enabled = false
-	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
-
-	e := cfg.Exporters[component.MustNewID("awss3")].(*Config)
-
-	assert.Equal(t, &Config{
-		QueueSettings:   queueCfg,
-		TimeoutSettings: timeoutCfg,
-		S3Uploader: S3UploaderConfig{
-			Region:            "us-east-1",
-			S3Bucket:          "foo",
-			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
-			StorageClass:      "STANDARD",
-			RetryMode:         DefaultRetryMode,
-			RetryMaxAttempts:  DefaultRetryMaxAttempts,
-			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		{
+			name: "consolidation without hourly partition",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.S3PartitionFormat = "%Y/%m/%d"
+				c.Consolidation.Enabled = true
+				return c
+			}(),
+			errExpected: errors.New("consolidation requires s3_partition_format to partition by hour (%H)"),
 		},
-		MarshalerName: "sumo_ic",
-	}, e,
-	)
-
-	e = cfg.Exporters[component.MustNewIDWithName("awss3", "proto")].(*Config)
-
-	assert.Equal(t, &Config{
-		QueueSettings:   queueCfg,
-		TimeoutSettings: timeoutCfg,
-		S3Uploader: S3UploaderConfig{
-			Region:            "us-east-1",
-			S3Bucket:          "bar",
-			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
-			StorageClass:      "STANDARD",
-			RetryMode:         DefaultRetryMode,
-			RetryMaxAttempts:  DefaultRetryMaxAttempts,
-			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		{
+			name: "consolidation with hive partition",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.S3PartitionFormat = "%Y/%m/%d"
+				c.S3Uploader.S3PartitionScheme = PartitionSchemeHive
+				c.Consolidation.Enabled = true
+				return c
+			}(),
 		},
-		MarshalerName: "otlp_proto",
-	}, e,
-	)
-}
-
-func TestCompressionName(t *testing.T) {
-	factories, err := otelcoltest.NopFactories()
-	assert.NoError(t, err)
-
-	factory := NewFactory()
-	factories.Exporters[factory.Type()] = factory
-	cfg, err := otelcoltest.LoadConfigAndValidate(
-		filepath.Join("testdata", "compression.yaml"), factories)
-
-	require.NoError(t, err)
-	require.NotNil(t, cfg)
-
-	queueCfg := exporterhelper.NewDefaultQueueConfig()
-	queueCfg.Enabled = false
-	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
-
-	e := cfg.Exporters[component.MustNewID("awss3")].(*Config)
-
-	assert.Equal(t, &Config{
-		QueueSettings:   queueCfg,
-		TimeoutSettings: timeoutCfg,
-		S3Uploader: S3UploaderConfig{
-			Region:            "us-east-1",
-			S3Bucket:          "foo",
-			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
-			Compression:       "gzip",
-			StorageClass:      "STANDARD",
-			RetryMode:         DefaultRetryMode,
-			RetryMaxAttempts:  DefaultRetryMaxAttempts,
-			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		{
+			name: "invalid partition scheme",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.S3PartitionScheme = "iso8601"
+				return c
+			}(),
+			errExpected: errors.New(`invalid s3_partition_scheme "iso8601", must be either "strftime" or "hive"`),
 		},
-		MarshalerName: "otlp_json",
-	}, e,
-	)
-
-	e = cfg.Exporters[component.MustNewIDWithName("awss3", "proto")].(*Config)
-
-	assert.Equal(t, &Config{
-		QueueSettings:   queueCfg,
-		TimeoutSettings: timeoutCfg,
-		S3Uploader: S3UploaderConfig{
-			Region:            "us-east-1",
-			S3Bucket:          "bar",
-			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
-			Compression:       "none",
-			StorageClass:      "STANDARD",
-			RetryMode:         DefaultRetryMode,
-			RetryMaxAttempts:  DefaultRetryMaxAttempts,
-			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		{
+			name: "partition by signal without hive partition",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.S3PartitionBySignal = true
+				return c
+			}(),
+			errExpected: errors.New("s3_partition_by_signal requires the hive s3_partition_scheme"),
 		},
-		MarshalerName: "otlp_proto",
-	}, e,
-	)
-}
-
-func TestResourceAttrsToS3(t *testing.T) {
-	factories, err := otelcoltest.NopFactories()
-	assert.NoError(t, err)
-
-	factory := NewFactory()
-	factories.Exporters[factory.Type()] = factory
-	cfg, err := otelcoltest.LoadConfigAndValidate(
-		filepath.Join("testdata", "config-s3_resource-attrs-to-s3.yaml"), factories)
-
-	require.NoError(t, err)
-	require.NotNil(t, cfg)
-
-	queueCfg := exporterhelper.NewDefaultQueueConfig()
-	queueCfg.Enabled = false
-	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
-
-	e := cfg.Exporters[component.MustNewID("awss3")].(*Config)
-
-	assert.Equal(t, &Config{
-		QueueSettings:   queueCfg,
-		TimeoutSettings: timeoutCfg,
-		S3Uploader: S3UploaderConfig{
-			Region:            "us-east-1",
-			S3Bucket:          "foo",
-			S3Prefix:          "bar",
-			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
-			Endpoint:          "http://endpoint.com",
-			StorageClass:      "STANDARD",
-			RetryMode:         DefaultRetryMode,
-			RetryMaxAttempts:  DefaultRetryMaxAttempts,
-			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		{
+			name: "valid aggregation",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Aggregation.Enabled = true
+				c.Aggregation.SpillDirectory = "/var/lib/otelcol/awss3"
+				return c
+			}(),
+			errExpected: nil,
 		},
-		MarshalerName: "otlp_json",
-		ResourceAttrsToS3: ResourceAttrsToS3{
-			S3Bucket: "com.awss3.bucket",
-			S3Prefix: "com.awss3.prefix",
+		{
+			name: "aggregation memory below max size",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Aggregation.Enabled = true
+				c.Aggregation.MaxMemorySize = 1 << 20
+				return c
+			}(),
+			errExpected: errors.New("aggregation max_memory_size must not be lower than max_size"),
 		},
-	}, e,
-	)
-}
-
-func TestRetry(t *testing.T) {
-	factories, err := otelcoltest.NopFactories()
-	assert.NoError(t, err)
-
-	factory := NewFactory()
-	factories.Exporters[factory.Type()] = factory
-	cfg, err := otelcoltest.LoadConfigAndValidate(
-		filepath.Join("testdata", "retry.yaml"), factories)
-
-	require.NoError(t, err)
-	require.NotNil(t, cfg)
-
-	queueCfg := exporterhelper.NewDefaultQueueConfig()
-	queueCfg.Enabled = false
-	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
-
-	e := cfg.Exporters[component.MustNewID("awss3")].(*Config)
-
-	assert.Equal(t, &Config{
-		QueueSettings:   queueCfg,
-		TimeoutSettings: timeoutCfg,
-		S3Uploader: S3UploaderConfig{
-			Region:            "us-east-1",
-			S3Bucket:          "foo",
-			S3Prefix:          "bar",
-			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
-			Endpoint:          "http://endpoint.com",
-			StorageClass:      "STANDARD_IA",
-			RetryMode:         "standard",
-			RetryMaxAttempts:  5,
-			RetryMaxBackoff:   30 * time.Second,
+		{
+			name: "aggregation without interval",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Aggregation.Enabled = true
+				c.Aggregation.Interval = 0
+				return c
+			}(),
+			errExpected: errors.New("aggregation interval must be positive"),
 		},
-		MarshalerName: "otlp_json",
-	}, e,
-	)
-}
-
-func TestConfigS3UniqueKeyFunc(t *testing.T) {
-	factories, err := otelcoltest.NopFactories()
-	assert.NoError(t, err)
-
-	factory := NewFactory()
-	factories.Exporters[factory.Type()] = factory
-	// https://github.com/open-telemetry/opentelemetry-collector-contrib/issues/33594
-	cfg, err := otelcoltest.LoadConfigAndValidate(
-		filepath.Join("testdata", "config-s3_unique_key_func.yaml"), factories)
+		{
+			name: "aggregation above max object size",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.MaxObjectSize = 1 << 20
+				c.Aggregation.Enabled = true
+				return c
+			}(),
+			errExpected: errors.New("aggregation max_size must not be greater than max_object_size"),
+		},
+		{
+			name: "aggregation with parquet",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.MarshalerName = Parquet
+				c.Aggregation.Enabled = true
+				return c
+			}(),
+			errExpected: errors.New("aggregation cannot be combined with the parquet marshaler"),
+		},
+		{
+			name: "framing with the passthrough marshaler",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.MarshalerName = Passthrough
+				c.Framing = FramingNewline
+				return c
+			}(),
+			errExpected: errors.New("framing is not supported by the marshaler"),
+		},
+		{
+			name: "notification to a queue and a topic",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Notification.SQSQueueURL = "https://sqs.us-east-1.amazonaws.com/123456789012/uploads"
+				c.Notification.SNSTopicARN = "arn:aws:sns:us-east-1:123456789012:uploads"
+				return c
+			}(),
+			errExpected: errors.New("notification sqs_queue_url and sns_topic_arn are mutually exclusive"),
+		},
+		{
+			name: "notification with consolidation",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Notification.SNSTopicARN = "arn:aws:sns:us-east-1:123456789012:uploads"
+				c.Consolidation.Enabled = true
+				return c
+			}(),
+			errExpected: errors.New("consolidation cannot be combined with notification"),
+		},
+		{
+			name: "role options without role_arn",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ExternalID = "my-external-id"
+				return c
+			}(),
+			errExpected: errors.New("role assumption options require role_arn"),
+		},
+		{
+			name: "external id with web identity",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.RoleArn = "arn:aws:iam::123456789012:role/exporter"
+				c.S3Uploader.ExternalID = "my-external-id"
+				c.S3Uploader.WebIdentityTokenFile = "/var/run/secrets/eks.amazonaws.com/serviceaccount/token"
+				return c
+			}(),
+			errExpected: errors.New("external_id cannot be combined with web_identity_token_file"),
+		},
+		{
+			name: "role session too short",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.RoleArn = "arn:aws:iam::123456789012:role/exporter"
+				c.S3Uploader.RoleSessionDuration = time.Minute
+				return c
+			}(),
+			errExpected: errors.New("role_session_duration must be between 15m and 12h"),
+		},
+		{
+			name: "invalid object lock mode",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ObjectLockMode = "LEGAL_HOLD"
+				c.S3Uploader.ObjectLockRetention = time.Hour
+				return c
+			}(),
+			errExpected: errors.New("invalid object_lock_mode, must be either 'GOVERNANCE' or 'COMPLIANCE'"),
+		},
+		{
+			name: "object lock without retention",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ObjectLockMode = "COMPLIANCE"
+				return c
+			}(),
+			errExpected: errors.New("object_lock_mode requires a positive object_lock_retention"),
+		},
+		{
+			name: "object lock retention without mode",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ObjectLockRetention = time.Hour
+				return c
+			}(),
+			errExpected: errors.New("object_lock_retention requires object_lock_mode"),
+		},
+		{
+			name: "object lock with consolidation",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ObjectLockMode = "GOVERNANCE"
+				c.S3Uploader.ObjectLockRetention = 24 * time.Hour
+				c.Consolidation.Enabled = true
+				return c
+			}(),
+			errExpected: errors.New("consolidation cannot be combined with object_lock_mode"),
+		},
+		{
+			name: "negative drain timeout",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.DrainTimeout = -time.Second
+				return c
+			}(),
+			errExpected: errors.New("drain_timeout must not be negative"),
+		},
+		{
+			name: "consolidation delay too long",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Consolidation.Enabled = true
+				c.Consolidation.Delay = 2 * time.Hour
+				return c
+			}(),
+			errExpected: errors.New("consolidation delay must be between 0 and 1h"),
+		},
+		{
+			name: "valid failover",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Failover.S3Bucket = "bar"
+				c.Failover.Region = "eu-west-1"
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "failover region without bucket",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Failover.Region = "eu-west-1"
+				return c
+			}(),
+			errExpected: errors.New("failover region and endpoint require failover s3_bucket"),
+		},
+		{
+			name: "failover threshold and interval not positive",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Failover.S3Bucket = "bar"
+				c.Failover.FailureThreshold = 0
+				c.Failover.FailbackInterval = 0
+				return c
+			}(),
+			errExpected: multierr.Combine(
+				errors.New("failover failure_threshold must be positive"),
+				errors.New("failover failback_interval must be positive"),
+			),
+		},
+		{
+			name: "failover with consolidation",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Failover.S3Bucket = "bar"
+				c.Consolidation.Enabled = true
+				return c
+			}(),
+			errExpected: errors.New("consolidation cannot be combined with failover"),
+		},
+		{
+			name: "valid kms encryption context",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ServerSideEncryption = "aws:kms"
+				c.S3Uploader.SSEKMSKeyID = "arn:aws:kms:us-east-1:123456789012:key/my-key"
+				c.ResourceAttrsToS3.SSEKMSEncryptionContext = map[string]string{"tenant": "tenant.id"}
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "invalid server side encryption",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ServerSideEncryption = "rot13"
+				return c
+			}(),
+			errExpected: errors.New("invalid ServerSideEncryption"),
+		},
+		{
+			name: "encryption context without kms",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ServerSideEncryption = "AES256"
+				c.ResourceAttrsToS3.SSEKMSEncryptionContext = map[string]string{"tenant": "tenant.id"}
+				return c
+			}(),
+			errExpected: errors.New("sse_kms_encryption_context requires a KMS based server_side_encryption"),
+		},
+		{
+			name: "encryption context with consolidation",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ServerSideEncryption = "aws:kms"
+				c.ResourceAttrsToS3.SSEKMSEncryptionContext = map[string]string{"tenant": "tenant.id"}
+				c.Consolidation.Enabled = true
+				return c
+			}(),
+			errExpected: errors.New("consolidation cannot be combined with sse_kms_encryption_context"),
+		},
+		{
+			name: "valid object tags and metadata",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ObjectTags = map[string]string{"team": "platform", "cost-center": "observability"}
+				c.S3Uploader.ObjectMetadata = map[string]string{"environment": "prod"}
+				c.ResourceAttrsToS3.ObjectTags = map[string]string{"team": "team.name", "service": "service.name"}
+				c.ResourceAttrsToS3.ObjectMetadata = map[string]string{"service": "service.name"}
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "too many object tags",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ObjectTags = map[string]string{}
+				for i := 0; i < 9; i++ {
+					c.S3Uploader.ObjectTags[fmt.Sprintf("static-%d", i)] = "value"
+				}
+				c.ResourceAttrsToS3.ObjectTags = map[string]string{"static-0": "attr", "team": "team.name", "service": "service.name"}
+				return c
+			}(),
+			errExpected: errors.New("objects can have at most 10 tags, got 11"),
+		},
+		{
+			name: "reserved object tag",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ObjectTags = map[string]string{"aws:team": "platform"}
+				return c
+			}(),
+			errExpected: errors.New(`object tag "aws:team" must not use the reserved aws: prefix`),
+		},
+		{
+			name: "empty object metadata attribute",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.ResourceAttrsToS3.ObjectMetadata = map[string]string{"service": ""}
+				return c
+			}(),
+			errExpected: errors.New("resource_attrs_to_s3 object_metadata keys and resource attributes must not be empty"),
+		},
+		{
+			name: "object tags from attributes with consolidation",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.ResourceAttrsToS3.ObjectTags = map[string]string{"team": "team.name"}
+				c.Consolidation.Enabled = true
+				return c
+			}(),
+			errExpected: errors.New("consolidation cannot be combined with resource_attrs_to_s3 object_tags or object_metadata"),
+		},
+		{
+			name: "ensure bucket",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.EnsureBucket = true
+				c.S3Uploader.BucketLifecycleRules = []BucketLifecycleRule{
+					{ID: "expire", ExpirationDays: 30},
+					{ID: "archive", TransitionDays: 7, TransitionStorageClass: "GLACIER", ExpirationDays: 365},
+				}
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "ensure bucket without bucket",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.Endpoint = "http://example.com"
+				c.S3Uploader.EnsureBucket = true
+				return c
+			}(),
+			errExpected: errors.New("ensure_bucket requires s3_bucket"),
+		},
+		{
+			name: "lifecycle rules without ensure bucket",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.BucketLifecycleRules = []BucketLifecycleRule{{ID: "expire", ExpirationDays: 30}}
+				return c
+			}(),
+			errExpected: errors.New("bucket_lifecycle_rules requires ensure_bucket"),
+		},
+		{
+			name: "invalid lifecycle rules",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.EnsureBucket = true
+				c.S3Uploader.BucketLifecycleRules = []BucketLifecycleRule{
+					{ID: "empty"},
+					{ID: "archive", TransitionDays: 7, TransitionStorageClass: "STANDARD"},
+					{ID: "short", TransitionDays: 7, TransitionStorageClass: "GLACIER", ExpirationDays: 7},
+				}
+				return c
+			}(),
+			errExpected: multierr.Combine(
+				errors.New(`bucket lifecycle rule "empty" requires expiration_days or transition_days`),
+				errors.New(`bucket lifecycle rule "archive" has an invalid transition_storage_class`),
+				errors.New(`bucket lifecycle rule "short" expiration_days must be after transition_days`),
+			),
+		},
+		{
+			name: "compression min size",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.Compression = configcompression.TypeGzip
+				c.S3Uploader.CompressionMinSize = 1024
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "negative compression min size",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.Compression = configcompression.TypeGzip
+				c.S3Uploader.CompressionMinSize = -1
+				return c
+			}(),
+			errExpected: errors.New("compression_min_size must not be negative"),
+		},
+		{
+			name: "local directory",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.LocalDirectory = "/tmp/awss3"
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "local directory with remote features",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.LocalDirectory = "/tmp/awss3"
+				c.S3Uploader.EnsureBucket = true
+				c.Notification.SQSQueueURL = "https://sqs.us-east-1.amazonaws.com/123456789012/uploads"
+				c.Failover.S3Bucket = "bar"
+				return c
+			}(),
+			errExpected: multierr.Combine(
+				errors.New("local_directory cannot be combined with ensure_bucket"),
+				errors.New("local_directory cannot be combined with notification"),
+				errors.New("local_directory cannot be combined with failover"),
+			),
+		},
+		{
+			name: "invalid partition time source",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.S3PartitionTimeSource = "record"
+				return c
+			}(),
+			errExpected: errors.New(`invalid s3_partition_time_source "record", must be either "upload", "earliest_record" or "latest_record"`),
+		},
+		{
+			name: "record partition time with consolidation and aggregation",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.S3PartitionTimeSource = PartitionTimeLatestRecord
+				c.Consolidation.Enabled = true
+				c.Aggregation.Enabled = true
+				return c
+			}(),
+			errExpected: multierr.Combine(
+				errors.New("consolidation requires the upload s3_partition_time_source"),
+				errors.New("aggregation requires the upload s3_partition_time_source"),
+			),
+		},
+		{
+			name: "invalid object ownership",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ObjectOwnership = "BucketOwner"
+				return c
+			}(),
+			errExpected: errors.New("invalid object_ownership, must be either 'BucketOwnerEnforced', 'BucketOwnerPreferred' or 'ObjectWriter'"),
+		},
+		{
+			name: "negative upload limits",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.MaxConcurrentUploads = -1
+				c.S3Uploader.UploadTimeout = -time.Second
+				return c
+			}(),
+			errExpected: multierr.Combine(
+				errors.New("max_concurrent_uploads must not be negative"),
+				errors.New("upload_timeout must not be negative"),
+			),
+		},
+		{
+			name: "negative max object size",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.MaxObjectSize = -1
+				return c
+			}(),
+			errExpected: errors.New("max_object_size must not be negative"),
+		},
+		{
+			name: "compression min size without compression",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.CompressionMinSize = 1024
+				return c
+			}(),
+			errExpected: errors.New("compression_min_size requires compression"),
+		},
+		{
+			name: "bucket key with kms",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ServerSideEncryption = "aws:kms"
+				c.S3Uploader.BucketKeyEnabled = true
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "bucket key without kms",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ServerSideEncryption = "aws:kms:dsse"
+				c.S3Uploader.BucketKeyEnabled = true
+				return c
+			}(),
+			errExpected: errors.New("bucket_key_enabled requires the aws:kms server_side_encryption"),
+		},
+		{
+			name: "sse customer key",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.SSECustomerKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "invalid sse customer key",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.SSECustomerKey = "MDEyMzQ1Njc4OWFiY2RlZg=="
+				return c
+			}(),
+			errExpected: errors.New("sse_customer_key must be a base64 encoded 256-bit key"),
+		},
+		{
+			name: "sse customer key and provider",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.SSECustomerKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+				id := component.MustNewID("keys")
+				c.S3Uploader.SSECustomerKeyProvider = &id
+				return c
+			}(),
+			errExpected: errors.New("sse_customer_key and sse_customer_key_provider are mutually exclusive"),
+		},
+		{
+			name: "sse customer key provider with server side encryption",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.ServerSideEncryption = "AES256"
+				id := component.MustNewID("keys")
+				c.S3Uploader.SSECustomerKeyProvider = &id
+				return c
+			}(),
+			errExpected: errors.New("sse_customer_key cannot be combined with server_side_encryption"),
+		},
+		{
+			name: "sse customer key without ssl",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.SSECustomerKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+				c.S3Uploader.DisableSSL = true
+				return c
+			}(),
+			errExpected: errors.New("sse_customer_key requires SSL"),
+		},
+		{
+			name: "length prefixed framing",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.MarshalerName = OtlpProtobuf
+				c.Framing = FramingLengthPrefixed
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "invalid framing",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Framing = "crlf"
+				return c
+			}(),
+			errExpected: errors.New(`invalid framing "crlf", must be either "newline" or "length_prefixed"`),
+		},
+		{
+			name: "framing with the body marshaler",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.MarshalerName = Body
+				c.Framing = FramingNewline
+				return c
+			}(),
+			errExpected: errors.New("framing is not supported by the marshaler"),
+		},
+		{
+			name: "prefix template",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.S3Prefix = "{service.name}/{deployment.environment}"
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "invalid prefix template",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.S3Prefix = "{service.name/"
+				return c
+			}(),
+			errExpected: fmt.Errorf("invalid s3_prefix: %w", errors.New(`unclosed '{' in "{service.name/"`)),
+		},
+		{
+			name: "parquet marshaler",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.MarshalerName = Parquet
+				c.Parquet.RowGroupSize = 1000
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "parquet marshaler with compression, framing and consolidation",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.S3PartitionFormat = "%Y/%m/%d/%H"
+				c.S3Uploader.Compression = configcompression.TypeGzip
+				c.MarshalerName = Parquet
+				c.Framing = FramingNewline
+				c.Consolidation.Enabled = true
+				return c
+			}(),
+			errExpected: multierr.Combine(
+				errors.New("framing is not supported by the marshaler"),
+				errors.New("parquet marshaler does not support compression, its files are compressed internally"),
+				errors.New("consolidation cannot be combined with the parquet marshaler"),
+			),
+		},
+		{
+			name: "parquet row group size not positive",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Parquet.RowGroupSize = 0
+				return c
+			}(),
+			errExpected: errors.New("parquet row_group_size must be positive"),
+		},
+		{
+			name: "compress per record",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.Compression = configcompression.TypeGzip
+				c.S3Uploader.CompressPerRecord = true
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "compress per record without compression",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.S3PartitionFormat = "%Y/%m/%d/%H"
+				c.S3Uploader.CompressPerRecord = true
+				c.Consolidation.Enabled = true
+				return c
+			}(),
+			errExpected: multierr.Combine(
+				errors.New("compress_per_record requires compression"),
+				errors.New("compress_per_record cannot be combined with consolidation"),
+			),
+		},
+		{
+			name: "surface throttling",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.SurfaceThrottling = true
+				c.BackOffConfig.Enabled = true
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "surface throttling without retry on failure",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.S3Uploader.SurfaceThrottling = true
+				return c
+			}(),
+			errExpected: errors.New("surface_throttling requires retry_on_failure to be enabled"),
+		},
+		{
+			name: "signal encodings",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				id := component.MustNewID("text_encoding")
+				c.Encodings.Logs = SignalEncoding{Encoding: &id, FileExtension: "txt"}
+				return c
+			}(),
+			errExpected: nil,
+		},
+		{
+			name: "signal encoding file extension without encoding",
+			config: func() *Config {
+				c := createDefaultConfig().(*Config)
+				c.S3Uploader.S3Bucket = "foo"
+				c.Encodings.Traces.FileExtension = "txt"
+				return c
+			}(),
+			errExpected: errors.New("encodings::traces::file_extension requires encoding"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			require.Equal(t, tt.errExpected, err)
+		})
+	}
+}
+
+func TestSignalEncoding(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	encoding, fileExtension := c.signalEncoding("logs")
+	assert.Nil(t, encoding)
+	assert.Empty(t, fileExtension)
+
+	global := component.MustNewID("otlp_encoding")
+	c.Encoding = &global
+	c.EncodingFileExtension = "pb"
+	text := component.MustNewID("text_encoding")
+	c.Encodings.Logs = SignalEncoding{Encoding: &text, FileExtension: "txt"}
+
+	encoding, fileExtension = c.signalEncoding("logs")
+	assert.Equal(t, &text, encoding)
+	assert.Equal(t, "txt", fileExtension)
+
+	encoding, fileExtension = c.signalEncoding("traces")
+	assert.Equal(t, &global, encoding)
+	assert.Equal(t, "pb", fileExtension)
+}
+
+func TestMarshallerName(t *testing.T) {
+	factories, err := otelcoltest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[factory.Type()] = factory
+	cfg, err := otelcoltest.LoadConfigAndValidate(
+		filepath.Join("testdata", "marshaler.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	queueCfg := exporterhelper.NewDefaultQueueConfig()
+	queueCfg.Enabled = false
+	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
+
+	e := cfg.Exporters[component.MustNewID("awss3")].(*Config)
+
+	assert.Equal(t, &Config{
+		QueueSettings:   queueCfg,
+		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
+		S3Uploader: S3UploaderConfig{
+			Region:            "us-east-1",
+			S3Bucket:          "foo",
+			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			StorageClass:      "STANDARD",
+			RetryMode:         DefaultRetryMode,
+			RetryMaxAttempts:  DefaultRetryMaxAttempts,
+			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		},
+		MarshalerName: "sumo_ic",
+		Consolidation: ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
+	}, e,
+	)
+
+	e = cfg.Exporters[component.MustNewIDWithName("awss3", "proto")].(*Config)
+
+	assert.Equal(t, &Config{
+		QueueSettings:   queueCfg,
+		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
+		S3Uploader: S3UploaderConfig{
+			Region:            "us-east-1",
+			S3Bucket:          "bar",
+			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			StorageClass:      "STANDARD",
+			RetryMode:         DefaultRetryMode,
+			RetryMaxAttempts:  DefaultRetryMaxAttempts,
+			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		},
+		MarshalerName: "otlp_proto",
+		Consolidation: ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
+	}, e,
+	)
+
+	e = cfg.Exporters[component.MustNewIDWithName("awss3", "parquet")].(*Config)
+
+	assert.Equal(t, &Config{
+		QueueSettings:   queueCfg,
+		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
+		S3Uploader: S3UploaderConfig{
+			Region:            "us-east-1",
+			S3Bucket:          "baz",
+			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			StorageClass:      "STANDARD",
+			RetryMode:         DefaultRetryMode,
+			RetryMaxAttempts:  DefaultRetryMaxAttempts,
+			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		},
+		MarshalerName: "parquet",
+		Consolidation: ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: 5000},
+		DrainTimeout: DefaultDrainTimeout,
+	}, e,
+	)
+}
+
+func TestCompressionName(t *testing.T) {
+	factories, err := otelcoltest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[factory.Type()] = factory
+	cfg, err := otelcoltest.LoadConfigAndValidate(
+		filepath.Join("testdata", "compression.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	queueCfg := exporterhelper.NewDefaultQueueConfig()
+	queueCfg.Enabled = false
+	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
+
+	e := cfg.Exporters[component.MustNewID("awss3")].(*Config)
+
+	assert.Equal(t, &Config{
+		QueueSettings:   queueCfg,
+		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
+		S3Uploader: S3UploaderConfig{
+			Region:            "us-east-1",
+			S3Bucket:          "foo",
+			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			Compression:       "gzip",
+			StorageClass:      "STANDARD",
+			RetryMode:         DefaultRetryMode,
+			RetryMaxAttempts:  DefaultRetryMaxAttempts,
+			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		},
+		MarshalerName: "otlp_json",
+		Consolidation: ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
+	}, e,
+	)
+
+	e = cfg.Exporters[component.MustNewIDWithName("awss3", "proto")].(*Config)
+
+	assert.Equal(t, &Config{
+		QueueSettings:   queueCfg,
+		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
+		S3Uploader: S3UploaderConfig{
+			Region:            "us-east-1",
+			S3Bucket:          "bar",
+			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			Compression:       "none",
+			StorageClass:      "STANDARD",
+			RetryMode:         DefaultRetryMode,
+			RetryMaxAttempts:  DefaultRetryMaxAttempts,
+			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		},
+		MarshalerName: "otlp_proto",
+		Consolidation: ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
+	}, e,
+	)
+}
+
+func TestResourceAttrsToS3(t *testing.T) {
+	factories, err := otelcoltest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[factory.Type()] = factory
+	cfg, err := otelcoltest.LoadConfigAndValidate(
+		filepath.Join("testdata", "config-s3_resource-attrs-to-s3.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	queueCfg := exporterhelper.NewDefaultQueueConfig()
+	queueCfg.Enabled = false
+	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
+
+	e := cfg.Exporters[component.MustNewID("awss3")].(*Config)
+
+	assert.Equal(t, &Config{
+		QueueSettings:   queueCfg,
+		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
+		S3Uploader: S3UploaderConfig{
+			Region:            "us-east-1",
+			S3Bucket:          "foo",
+			S3Prefix:          "bar",
+			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			Endpoint:          "http://endpoint.com",
+			StorageClass:      "STANDARD",
+			RetryMode:         DefaultRetryMode,
+			RetryMaxAttempts:  DefaultRetryMaxAttempts,
+			RetryMaxBackoff:   DefaultRetryMaxBackoff,
+		},
+		MarshalerName: "otlp_json",
+		Consolidation: ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
+		ResourceAttrsToS3: ResourceAttrsToS3{
+			S3Bucket: "com.awss3.bucket",
+			S3Prefix: "com.awss3.prefix",
+		},
+	}, e,
+	)
+}
+
+func TestRetry(t *testing.T) {
+	factories, err := otelcoltest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[factory.Type()] = factory
+	cfg, err := otelcoltest.LoadConfigAndValidate(
+		filepath.Join("testdata", "retry.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	queueCfg := exporterhelper.NewDefaultQueueConfig()
+	queueCfg.Enabled = false
+	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
+
+	e := cfg.Exporters[component.MustNewID("awss3")].(*Config)
+
+	assert.Equal(t, &Config{
+		QueueSettings:   queueCfg,
+		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
+		S3Uploader: S3UploaderConfig{
+			Region:            "us-east-1",
+			S3Bucket:          "foo",
+			S3Prefix:          "bar",
+			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			Endpoint:          "http://endpoint.com",
+			StorageClass:      "STANDARD_IA",
+			RetryMode:         "standard",
+			RetryMaxAttempts:  5,
+			RetryMaxBackoff:   30 * time.Second,
+		},
+		MarshalerName: "otlp_json",
+		Consolidation: ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
+	}, e,
+	)
+}
+
+func TestConfigS3UniqueKeyFunc(t *testing.T) {
+	factories, err := otelcoltest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[factory.Type()] = factory
+	// https://github.com/open-telemetry/opentelemetry-collector-contrib/issues/33594
+	cfg, err := otelcoltest.LoadConfigAndValidate(
+		filepath.Join("testdata", "config-s3_unique_key_func.yaml"), factories)
 
 	require.NoError(t, err)
 	require.NotNil(t, cfg)
@@ -536,6 +1577,8 @@ func TestConfigS3UniqueKeyFunc(t *testing.T) {
 	queueCfg := exporterhelper.NewDefaultQueueConfig()
 	queueCfg.Enabled = false
 	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
 
 	assert.Equal(t, &Config{
 		S3Uploader: S3UploaderConfig{
@@ -552,7 +1595,21 @@ func TestConfigS3UniqueKeyFunc(t *testing.T) {
 		},
 		QueueSettings:   queueCfg,
 		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
 		MarshalerName:   "otlp_json",
+		Consolidation:   ConsolidationConfig{Delay: DefaultConsolidationDelay},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		Parquet:      ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize},
+		DrainTimeout: DefaultDrainTimeout,
 	}, e,
 	)
 }
@@ -0,0 +1,114 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+)
+
+var errDrainExpired = errors.New("the drain timeout expired before the data could be flushed to S3")
+
+// drainer bounds the time spent flushing the data still being exported once the exporter
+// is shut down, queued data included, and counts the data that could not be flushed in time.
+// The zero value never expires.
+type drainer struct {
+	timeout time.Duration
+
+	mu      sync.Mutex
+	ctx     context.Context
+	cancel  context.CancelFunc
+	timer   *time.Timer
+	started bool
+
+	unflushedItems   atomic.Int64
+	unflushedUploads atomic.Int64
+}
+
+// context returns the context canceled once the drain timeout expires.
+func (d *drainer) context() context.Context {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.ctx == nil {
+		d.ctx, d.cancel = context.WithCancel(context.Background())
+	}
+	return d.ctx
+}
+
+// begin starts the drain timeout, which also expires once ctx is done.
+func (d *drainer) begin(ctx context.Context) {
+	d.context()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.started {
+		return
+	}
+	d.started = true
+	if d.timeout > 0 {
+		d.timer = time.AfterFunc(d.timeout, d.cancel)
+	}
+	context.AfterFunc(ctx, d.cancel)
+}
+
+// upload runs fn unless the drain timeout expired, canceling it when it expires.
+// The items of the uploads that did not complete in time are counted as unflushed.
+func (d *drainer) upload(ctx context.Context, items int, fn func(context.Context) error) error {
+	drainCtx := d.context()
+	if drainCtx.Err() != nil {
+		d.unflushed(items)
+		return consumererror.NewPermanent(errDrainExpired)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer context.AfterFunc(drainCtx, cancel)()
+
+	err := fn(ctx)
+	if err != nil && drainCtx.Err() != nil {
+		d.unflushed(items)
+		return consumererror.NewPermanent(errors.Join(errDrainExpired, err))
+	}
+	return err
+}
+
+func (d *drainer) unflushed(items int) {
+	d.unflushedItems.Add(int64(items))
+	d.unflushedUploads.Add(1)
+}
+
+// end stops the drain timeout and reports the data that could not be flushed.
+func (d *drainer) end(logger *zap.Logger) {
+	d.mu.Lock()
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	if d.cancel != nil {
+		d.cancel()
+	}
+	d.mu.Unlock()
+	if items := d.unflushedItems.Load(); items > 0 {
+		logger.Warn("Data could not be flushed to S3 before the drain timeout expired",
+			zap.Int64("items", items),
+			zap.Int64("uploads", d.unflushedUploads.Load()),
+			zap.Duration("drain_timeout", d.timeout))
+	}
+}
+
+// drainOnShutdown starts the drain timeout as soon as the exporter is shut down, so that
+// it also bounds the flush of the sending queue happening before the exporter shutdown.
+type drainOnShutdown struct {
+	component.Component
+	drain *drainer
+}
+
+func (c *drainOnShutdown) Shutdown(ctx context.Context) error {
+	c.drain.begin(ctx)
+	return c.Component.Shutdown(ctx)
+}
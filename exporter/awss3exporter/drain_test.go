@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDrainerUpload(t *testing.T) {
+	t.Run("uploads until shutdown", func(t *testing.T) {
+		var d drainer
+		called := false
+		require.NoError(t, d.upload(context.Background(), 3, func(context.Context) error {
+			called = true
+			return nil
+		}))
+		assert.True(t, called)
+
+		errUpload := errors.New("upload failed")
+		err := d.upload(context.Background(), 3, func(context.Context) error { return errUpload })
+		assert.ErrorIs(t, err, errUpload)
+		assert.False(t, consumererror.IsPermanent(err))
+		assert.Zero(t, d.unflushedItems.Load())
+	})
+
+	t.Run("cancels uploads once the timeout expires", func(t *testing.T) {
+		d := drainer{timeout: 10 * time.Millisecond}
+		d.begin(context.Background())
+		err := d.upload(context.Background(), 3, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, errDrainExpired)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.True(t, consumererror.IsPermanent(err))
+
+		err = d.upload(context.Background(), 2, func(context.Context) error {
+			t.Fatal("unexpected upload after the drain timeout")
+			return nil
+		})
+		assert.ErrorIs(t, err, errDrainExpired)
+		assert.True(t, consumererror.IsPermanent(err))
+		assert.Equal(t, int64(5), d.unflushedItems.Load())
+		assert.Equal(t, int64(2), d.unflushedUploads.Load())
+	})
+
+	t.Run("expires with the shutdown context", func(t *testing.T) {
+		var d drainer
+		ctx, cancel := context.WithCancel(context.Background())
+		d.begin(ctx)
+		require.NoError(t, d.upload(context.Background(), 1, func(context.Context) error { return nil }))
+		cancel()
+		require.Eventually(t, func() bool { return d.context().Err() != nil }, time.Second, time.Millisecond)
+		assert.ErrorIs(t, d.upload(context.Background(), 1, func(context.Context) error { return nil }), errDrainExpired)
+	})
+}
+
+func TestDrainerEnd(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	d := drainer{timeout: time.Second}
+	d.begin(context.Background())
+	d.end(zap.New(core))
+	assert.Zero(t, logs.Len())
+
+	d.unflushed(4)
+	d.unflushed(6)
+	d.end(zap.New(core))
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, int64(10), fields["items"])
+	assert.Equal(t, int64(2), fields["uploads"])
+}
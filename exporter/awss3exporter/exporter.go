@@ -7,24 +7,38 @@ import (
 	"context"
 	"fmt"
 
+	"go.opentelemetry.io/collector/client"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componentstatus"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/multierr"
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
 )
 
 type s3Exporter struct {
-	config     *Config
-	signalType string
-	uploader   upload.Manager
+	config       *Config
+	signalType   string
+	uploader     upload.Manager
+	consolidator *upload.Consolidator
+	// aggregator buffers the payloads before they are uploaded, nil when the aggregation is disabled.
+	aggregator *upload.Aggregator
 	logger     *zap.Logger
 	marshaler  marshaler
+	// telemetrySettings are the settings of the telemetry of the uploads, reported once started.
+	telemetrySettings component.TelemetrySettings
+	telemetry         *metadata.TelemetryBuilder
+	// prefixTemplate renders the key prefix of each upload, nil if S3Prefix does not reference resource attributes.
+	prefixTemplate *prefixTemplate
+	// drain bounds the flush of the data still being exported on shutdown.
+	drain drainer
 }
 
 func newS3Exporter(
@@ -33,14 +47,16 @@ func newS3Exporter(
 	params exporter.Settings,
 ) *s3Exporter {
 	s3Exporter := &s3Exporter{
-		config:     config,
-		signalType: signalType,
-		logger:     params.Logger,
+		config:            config,
+		signalType:        signalType,
+		logger:            params.Logger,
+		telemetrySettings: params.TelemetrySettings,
+		drain:             drainer{timeout: config.DrainTimeout},
 	}
 	return s3Exporter
 }
 
-func (e *s3Exporter) getUploadOpts(res pcommon.Resource) *upload.UploadOptions {
+func (e *s3Exporter) getUploadOpts(ctx context.Context, res pcommon.Resource) *upload.UploadOptions {
 	s3Prefix := ""
 	s3Bucket := ""
 	if s3PrefixKey := e.config.ResourceAttrsToS3.S3Prefix; s3PrefixKey != "" {
@@ -48,73 +64,221 @@ func (e *s3Exporter) getUploadOpts(res pcommon.Resource) *upload.UploadOptions {
 			s3Prefix = value.AsString()
 		}
 	}
+	if s3Prefix == "" {
+		s3Prefix = clientMetadataValue(ctx, e.config.ClientMetadataToS3.S3Prefix)
+	}
+	if s3Prefix == "" && e.prefixTemplate != nil {
+		s3Prefix = e.prefixTemplate.render(res.Attributes())
+	}
 	if s3BucketKey := e.config.ResourceAttrsToS3.S3Bucket; s3BucketKey != "" {
 		if value, ok := res.Attributes().Get(s3BucketKey); ok {
 			s3Bucket = value.AsString()
 		}
 	}
+	if s3Bucket == "" {
+		s3Bucket = clientMetadataValue(ctx, e.config.ClientMetadataToS3.S3Bucket)
+	}
 	uploadOpts := &upload.UploadOptions{
 		OverrideBucket: s3Bucket,
 		OverridePrefix: s3Prefix,
 	}
+	uploadOpts.EncryptionContext = resourceAttrValues(res, e.config.ResourceAttrsToS3.SSEKMSEncryptionContext)
+	uploadOpts.Tags = resourceAttrValues(res, e.config.ResourceAttrsToS3.ObjectTags)
+	uploadOpts.Metadata = resourceAttrValues(res, e.config.ResourceAttrsToS3.ObjectMetadata)
 	return uploadOpts
 }
 
+// clientMetadataValue returns the first value of the client metadata key, or an
+// empty string when key is empty or missing from the metadata.
+func clientMetadataValue(ctx context.Context, key string) string {
+	if key == "" {
+		return ""
+	}
+	if values := client.FromContext(ctx).Metadata.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// resourceAttrValues returns the values of the resource attributes mapped by
+// keys, skipping the missing ones, or nil when none is present.
+func resourceAttrValues(res pcommon.Resource, keys map[string]string) map[string]string {
+	var values map[string]string
+	for key, attr := range keys {
+		if value, ok := res.Attributes().Get(attr); ok {
+			if values == nil {
+				values = make(map[string]string, len(keys))
+			}
+			values[key] = value.AsString()
+		}
+	}
+	return values
+}
+
 func (e *s3Exporter) start(ctx context.Context, host component.Host) error {
 	var m marshaler
 	var err error
-	if e.config.Encoding != nil {
-		if m, err = newMarshalerFromEncoding(e.config.Encoding, e.config.EncodingFileExtension, host, e.logger); err != nil {
+	if encoding, fileExtension := e.config.signalEncoding(e.signalType); encoding != nil {
+		if m, err = newMarshalerFromEncoding(encoding, fileExtension, host, e.logger); err != nil {
 			return err
 		}
+	} else if e.config.MarshalerName == Parquet {
+		m = newParquetMarshaler(e.config.Parquet)
+	} else if e.config.MarshalerName == Passthrough {
+		m = newPassthroughMarshaler(e.config.Passthrough)
 	} else {
 		if m, err = newMarshaler(e.config.MarshalerName, e.logger); err != nil {
 			return fmt.Errorf("unknown marshaler %q", e.config.MarshalerName)
 		}
 	}
 
+	m = newFramedMarshaler(m, e.config.Framing)
+	if e.config.S3Uploader.CompressPerRecord {
+		m = newRecordCompressingMarshaler(m)
+	}
 	e.marshaler = m
 
-	up, err := newUploadManager(ctx, e.config, e.signalType, m.format())
-	if err != nil {
+	if e.prefixTemplate, err = parsePrefixTemplate(e.config.S3Uploader.S3Prefix); err != nil {
 		return err
 	}
-	e.uploader = up
-	return nil
-}
 
-func (*s3Exporter) Capabilities() consumer.Capabilities {
-	return consumer.Capabilities{MutatesData: false}
-}
+	var failoverConf *Config
+	if e.config.Failover.enabled() {
+		failoverConf = e.config.failoverConfig()
+	}
 
-func (e *s3Exporter) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
-	buf, err := e.marshaler.MarshalMetrics(md)
+	if e.config.S3Uploader.EnsureBucket {
+		if err = ensureBucket(ctx, e.config, e.logger); err != nil {
+			return err
+		}
+		if failoverConf != nil {
+			if err = ensureBucket(ctx, failoverConf, e.logger); err != nil {
+				return err
+			}
+		}
+	}
+
+	if dir := e.config.S3Uploader.LocalDirectory; dir != "" {
+		e.logger.Warn("Writing the objects to a local directory instead of uploading them to S3", zap.String("directory", dir))
+	}
+
+	if e.config.S3Uploader.ACL != "" && e.config.S3Uploader.acl() == "" {
+		e.logger.Warn("Not sending the acl, ACLs are disabled on the buckets with the BucketOwnerEnforced object_ownership",
+			zap.String("acl", e.config.S3Uploader.ACL))
+	}
+
+	var opts []upload.ManagerOpt
+	if e.config.Consolidation.Enabled {
+		c, err := newConsolidator(ctx, e.config, e.signalType, m.format(), e.logger)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, upload.WithUploadObserver(c.Track))
+		e.consolidator = c
+	}
+
+	if e.config.S3Uploader.sseCustomerKeyEnabled() {
+		key, err := newSSECustomerKey(&e.config.S3Uploader, host)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, upload.WithSSECustomerKey(key))
+	}
+
+	notifier, err := newNotifier(ctx, e.config)
 	if err != nil {
 		return err
 	}
+	if notifier != nil {
+		opts = append(opts, upload.WithNotifier(notifier, e.logger))
+	}
 
-	uploadOpts := e.getUploadOpts(md.ResourceMetrics().At(0).Resource())
-	return e.uploader.Upload(ctx, buf, uploadOpts)
-}
+	if e.telemetry, err = metadata.NewTelemetryBuilder(e.telemetrySettings); err != nil {
+		return err
+	}
+	opts = append(opts, upload.WithTelemetry(e.telemetry))
 
-func (e *s3Exporter) ConsumeLogs(ctx context.Context, logs plog.Logs) error {
-	buf, err := e.marshaler.MarshalLogs(logs)
+	up, err := newUploadManager(ctx, e.config, e.signalType, m.format(), opts...)
 	if err != nil {
 		return err
 	}
+	if failoverConf != nil {
+		secondary, err := newUploadManager(ctx, failoverConf, e.signalType, m.format(), opts...)
+		if err != nil {
+			return err
+		}
+		up = upload.NewFailover(up, secondary, e.config.Failover.FailureThreshold, e.config.Failover.FailbackInterval,
+			func(err error) { e.reportFailover(host, err) })
+	}
+	e.uploader = up
 
-	uploadOpts := e.getUploadOpts(logs.ResourceLogs().At(0).Resource())
+	if e.config.Aggregation.Enabled {
+		e.aggregator = newAggregator(up, e.config, m.format(), e.logger)
+		e.aggregator.Start(ctx)
+		e.uploader = e.aggregator
+	}
 
-	return e.uploader.Upload(ctx, buf, uploadOpts)
+	if e.consolidator != nil {
+		e.consolidator.Start(ctx)
+	}
+	return nil
 }
 
-func (e *s3Exporter) ConsumeTraces(ctx context.Context, traces ptrace.Traces) error {
-	buf, err := e.marshaler.MarshalTraces(traces)
+// reportFailover reports the failover to the secondary bucket, caused by err, as a
+// recoverable error of the component, and the failback when err is nil.
+func (e *s3Exporter) reportFailover(host component.Host, err error) {
 	if err != nil {
-		return err
+		e.logger.Warn("Failing over to the secondary bucket",
+			zap.String("bucket", e.config.Failover.S3Bucket), zap.Error(err))
+		componentstatus.ReportStatus(host, componentstatus.NewRecoverableErrorEvent(
+			fmt.Errorf("uploading to failover bucket %q: %w", e.config.Failover.S3Bucket, err)))
+		return
 	}
+	e.logger.Info("Failing back to the primary bucket", zap.String("bucket", e.config.S3Uploader.S3Bucket))
+	componentstatus.ReportStatus(host, componentstatus.NewEvent(componentstatus.StatusOK))
+}
 
-	uploadOpts := e.getUploadOpts(traces.ResourceSpans().At(0).Resource())
+func (e *s3Exporter) shutdown(ctx context.Context) error {
+	defer e.drain.end(e.logger)
+	var errs error
+	if e.aggregator != nil {
+		// The aggregated payloads are uploaded within the drain timeout, before the
+		// consolidator stops tracking the uploaded objects.
+		aggCtx, cancel := context.WithCancel(ctx)
+		stop := context.AfterFunc(e.drain.context(), cancel)
+		errs = multierr.Append(errs, e.aggregator.Shutdown(aggCtx))
+		stop()
+		cancel()
+	}
+	if e.consolidator != nil {
+		errs = multierr.Append(errs, e.consolidator.Shutdown(ctx))
+	}
+	if e.telemetry != nil {
+		e.telemetry.Shutdown()
+	}
+	return errs
+}
 
-	return e.uploader.Upload(ctx, buf, uploadOpts)
+// upload uploads the marshaled items, within the drain timeout once shutting down.
+func (e *s3Exporter) upload(ctx context.Context, items int, buf []byte, uploadOpts *upload.UploadOptions) error {
+	uploadOpts.Records = items
+	return e.drain.upload(ctx, items, func(ctx context.Context) error {
+		return e.uploader.Upload(ctx, buf, uploadOpts)
+	})
+}
+
+func (*s3Exporter) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: false}
+}
+
+func (e *s3Exporter) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	return export(ctx, e, md, metricsSignal, e.marshaler.MarshalMetrics)
+}
+
+func (e *s3Exporter) ConsumeLogs(ctx context.Context, logs plog.Logs) error {
+	return export(ctx, e, logs, logsSignal, e.marshaler.MarshalLogs)
+}
+
+func (e *s3Exporter) ConsumeTraces(ctx context.Context, traces ptrace.Traces) error {
+	return export(ctx, e, traces, tracesSignal, e.marshaler.MarshalTraces)
 }
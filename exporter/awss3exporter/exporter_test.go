@@ -6,12 +6,19 @@ package awss3exporter
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/exporter/exportertest"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
 )
 
@@ -29,7 +36,7 @@ type testWriter struct {
 
 func (testWriter *testWriter) Upload(_ context.Context, buf []byte, uploadOpts *upload.UploadOptions) error {
 	assert.Equal(testWriter.t, testLogs, buf)
-	assert.Equal(testWriter.t, &upload.UploadOptions{OverridePrefix: ""}, uploadOpts)
+	assert.Equal(testWriter.t, &upload.UploadOptions{OverridePrefix: "", Records: 1}, uploadOpts)
 	return nil
 }
 
@@ -63,7 +70,7 @@ type testWriterWithResourceAttrs struct {
 
 func (testWriterWO *testWriterWithResourceAttrs) Upload(_ context.Context, buf []byte, uploadOpts *upload.UploadOptions) error {
 	assert.Equal(testWriterWO.t, testLogs, buf)
-	assert.Equal(testWriterWO.t, &upload.UploadOptions{OverridePrefix: overridePrefix}, uploadOpts)
+	assert.Equal(testWriterWO.t, &upload.UploadOptions{OverridePrefix: overridePrefix, Records: 1}, uploadOpts)
 	return nil
 }
 
@@ -92,7 +99,7 @@ type testWriterWithBucketAndPrefix struct {
 
 func (testWriterWBP *testWriterWithBucketAndPrefix) Upload(_ context.Context, buf []byte, uploadOpts *upload.UploadOptions) error {
 	assert.Equal(testWriterWBP.t, testLogs, buf)
-	assert.Equal(testWriterWBP.t, &upload.UploadOptions{OverrideBucket: overrideBucket, OverridePrefix: overridePrefix}, uploadOpts)
+	assert.Equal(testWriterWBP.t, &upload.UploadOptions{OverrideBucket: overrideBucket, OverridePrefix: overridePrefix, Records: 1}, uploadOpts)
 	return nil
 }
 
@@ -115,3 +122,147 @@ func TestLogWithBucketAndPrefixAttrs(t *testing.T) {
 	exporter := getLogExporterWithBucketAndPrefixAttrs(t)
 	assert.NoError(t, exporter.ConsumeLogs(context.Background(), logs))
 }
+
+type testWriterWithClientMetadata struct {
+	t *testing.T
+}
+
+func (testWriterWCM *testWriterWithClientMetadata) Upload(_ context.Context, buf []byte, uploadOpts *upload.UploadOptions) error {
+	assert.Equal(testWriterWCM.t, testLogs, buf)
+	// the resource attribute takes precedence over the client metadata
+	assert.Equal(testWriterWCM.t, &upload.UploadOptions{OverrideBucket: "tenant-a", OverridePrefix: overridePrefix, Records: 1}, uploadOpts)
+	return nil
+}
+
+func TestLogWithClientMetadata(t *testing.T) {
+	logs := getTestLogs(t)
+	marshaler, _ := newMarshaler("otlp_json", zap.NewNop())
+	config := createDefaultConfig().(*Config)
+	config.ResourceAttrsToS3.S3Prefix = s3PrefixKey
+	config.ClientMetadataToS3.S3Bucket = "X-Scope-OrgID"
+	config.ClientMetadataToS3.S3Prefix = "X-Tenant-Prefix"
+	exporter := &s3Exporter{
+		config:    config,
+		uploader:  &testWriterWithClientMetadata{t},
+		logger:    zap.NewNop(),
+		marshaler: marshaler,
+	}
+	ctx := client.NewContext(context.Background(), client.Info{
+		Metadata: client.NewMetadata(map[string][]string{
+			"x-scope-orgid":   {"tenant-a", "tenant-b"},
+			"x-tenant-prefix": {"tenant-a/logs"},
+		}),
+	})
+	assert.NoError(t, exporter.ConsumeLogs(ctx, logs))
+}
+
+type testWriterWithPrefixTemplate struct {
+	t *testing.T
+}
+
+func (testWriterWPT *testWriterWithPrefixTemplate) Upload(_ context.Context, buf []byte, uploadOpts *upload.UploadOptions) error {
+	assert.Equal(testWriterWPT.t, testLogs, buf)
+	assert.Equal(testWriterWPT.t, &upload.UploadOptions{OverridePrefix: "logs/logfile/" + overridePrefix + "/unknown", Records: 1}, uploadOpts)
+	return nil
+}
+
+func TestLogWithPrefixTemplate(t *testing.T) {
+	logs := getTestLogs(t)
+	marshaler, _ := newMarshaler("otlp_json", zap.NewNop())
+	config := createDefaultConfig().(*Config)
+	config.S3Uploader.S3Prefix = "logs/{_sourceCategory}/{" + s3PrefixKey + "}/{not.present}"
+	prefixTemplate, err := parsePrefixTemplate(config.S3Uploader.S3Prefix)
+	require.NoError(t, err)
+	exporter := &s3Exporter{
+		config:         config,
+		uploader:       &testWriterWithPrefixTemplate{t},
+		logger:         zap.NewNop(),
+		marshaler:      marshaler,
+		prefixTemplate: prefixTemplate,
+	}
+	assert.NoError(t, exporter.ConsumeLogs(context.Background(), logs))
+}
+
+type testWriterWithEncryptionContext struct {
+	t *testing.T
+}
+
+func (testWriterWEC *testWriterWithEncryptionContext) Upload(_ context.Context, buf []byte, uploadOpts *upload.UploadOptions) error {
+	assert.Equal(testWriterWEC.t, testLogs, buf)
+	assert.Equal(testWriterWEC.t, &upload.UploadOptions{
+		EncryptionContext: map[string]string{"host": overridePrefix, "category": "logfile"},
+		Records:           1,
+	}, uploadOpts)
+	return nil
+}
+
+func TestLogWithEncryptionContext(t *testing.T) {
+	logs := getTestLogs(t)
+	marshaler, _ := newMarshaler("otlp_json", zap.NewNop())
+	config := createDefaultConfig().(*Config)
+	config.ResourceAttrsToS3.SSEKMSEncryptionContext = map[string]string{
+		"host":     s3PrefixKey,
+		"category": "_sourceCategory",
+		"missing":  "not.present",
+	}
+	exporter := &s3Exporter{
+		config:    config,
+		uploader:  &testWriterWithEncryptionContext{t},
+		logger:    zap.NewNop(),
+		marshaler: marshaler,
+	}
+	assert.NoError(t, exporter.ConsumeLogs(context.Background(), logs))
+}
+
+type testWriterWithObjectTags struct {
+	t *testing.T
+}
+
+func (testWriterWOT *testWriterWithObjectTags) Upload(_ context.Context, buf []byte, uploadOpts *upload.UploadOptions) error {
+	assert.Equal(testWriterWOT.t, testLogs, buf)
+	assert.Equal(testWriterWOT.t, &upload.UploadOptions{
+		Tags:     map[string]string{"host": overridePrefix},
+		Metadata: map[string]string{"category": "logfile"},
+		Records:  1,
+	}, uploadOpts)
+	return nil
+}
+
+func TestLogWithObjectTags(t *testing.T) {
+	logs := getTestLogs(t)
+	marshaler, _ := newMarshaler("otlp_json", zap.NewNop())
+	config := createDefaultConfig().(*Config)
+	config.ResourceAttrsToS3.ObjectTags = map[string]string{
+		"host":    s3PrefixKey,
+		"missing": "not.present",
+	}
+	config.ResourceAttrsToS3.ObjectMetadata = map[string]string{
+		"category": "_sourceCategory",
+	}
+	exporter := &s3Exporter{
+		config:    config,
+		uploader:  &testWriterWithObjectTags{t},
+		logger:    zap.NewNop(),
+		marshaler: marshaler,
+	}
+	assert.NoError(t, exporter.ConsumeLogs(context.Background(), logs))
+}
+
+func TestLogToLocalDirectory(t *testing.T) {
+	dir := t.TempDir()
+	config := createDefaultConfig().(*Config)
+	config.S3Uploader.S3Bucket = "my-bucket"
+	config.S3Uploader.S3Prefix = "logs"
+	config.S3Uploader.LocalDirectory = dir
+	exporter := newS3Exporter(config, "logs", exportertest.NewNopSettings(metadata.Type))
+	require.NoError(t, exporter.start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, exporter.ConsumeLogs(context.Background(), getTestLogs(t)))
+	require.NoError(t, exporter.shutdown(context.Background()))
+
+	objects, err := filepath.Glob(filepath.Join(dir, "my-bucket", "logs", "year=*", "month=*", "day=*", "hour=*", "minute=*", "logs_*.json"))
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	content, err := os.ReadFile(objects[0])
+	require.NoError(t, err)
+	assert.Equal(t, testLogs, content)
+}
@@ -8,6 +8,7 @@ import (
 	"errors"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configretry"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/exporter"
 	"go.opentelemetry.io/collector/exporter/exporterhelper"
@@ -49,10 +50,14 @@ func createDefaultConfig() component.Config {
 	queueCfg := exporterhelper.NewDefaultQueueConfig()
 	queueCfg.Enabled = false
 	timeoutCfg := exporterhelper.NewDefaultTimeoutConfig()
+	// The S3 client retries the uploads itself.
+	backOffCfg := configretry.NewDefaultBackOffConfig()
+	backOffCfg.Enabled = false
 
 	return &Config{
 		QueueSettings:   queueCfg,
 		TimeoutSettings: timeoutCfg,
+		BackOffConfig:   backOffCfg,
 		S3Uploader: S3UploaderConfig{
 			Region:            "us-east-1",
 			S3PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
@@ -62,6 +67,23 @@ func createDefaultConfig() component.Config {
 			RetryMaxBackoff:   DefaultRetryMaxBackoff,
 		},
 		MarshalerName: "otlp_json",
+		Parquet: ParquetConfig{
+			RowGroupSize: DefaultParquetRowGroupSize,
+		},
+		Consolidation: ConsolidationConfig{
+			Delay: DefaultConsolidationDelay,
+		},
+		Aggregation: AggregationConfig{
+			MaxSize:       DefaultAggregationMaxSize,
+			Interval:      DefaultAggregationInterval,
+			MaxMemorySize: DefaultAggregationMaxMemorySize,
+			MaxSpillSize:  DefaultAggregationMaxSpillSize,
+		},
+		Failover: FailoverConfig{
+			FailureThreshold: DefaultFailoverFailureThreshold,
+			FailbackInterval: DefaultFailoverFailbackInterval,
+		},
+		DrainTimeout: DefaultDrainTimeout,
 	}
 }
 
@@ -80,20 +102,21 @@ func createLogsExporter(ctx context.Context,
 		config,
 		s3Exporter.ConsumeLogs,
 		exporterhelper.WithStart(s3Exporter.start),
+		exporterhelper.WithShutdown(s3Exporter.shutdown),
 		exporterhelper.WithQueue(cfg.QueueSettings),
 		exporterhelper.WithTimeout(cfg.TimeoutSettings),
+		exporterhelper.WithRetry(cfg.BackOffConfig),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	if cfg.ResourceAttrsToS3.S3Prefix == "" {
-		return logsExporter, err
-	}
-
 	wrapped := &baseLogsExporter{
-		Component: logsExporter,
-		Logs:      batchperresourceattr.NewBatchPerResourceLogs(cfg.ResourceAttrsToS3.S3Prefix, logsExporter),
+		Component: &drainOnShutdown{Component: logsExporter, drain: &s3Exporter.drain},
+		Logs:      logsExporter,
+	}
+	if batchAttributes := cfg.batchAttributes(); len(batchAttributes) > 0 {
+		wrapped.Logs = batchperresourceattr.NewMultiBatchPerResourceLogs(batchAttributes, logsExporter)
 	}
 	return wrapped, nil
 }
@@ -109,28 +132,33 @@ func createMetricsExporter(ctx context.Context,
 
 	s3Exporter := newS3Exporter(cfg, "metrics", params)
 
-	if config.(*Config).MarshalerName == SumoIC {
+	encoding, _ := cfg.signalEncoding("metrics")
+	if encoding == nil && cfg.MarshalerName == SumoIC {
 		return nil, errors.New("metrics are not supported by sumo_ic output format")
 	}
+	if encoding == nil && cfg.MarshalerName == Passthrough {
+		return nil, errors.New("metrics are not supported by passthrough output format")
+	}
 
 	metricsExporter, err := exporterhelper.NewMetrics(ctx, params,
 		config,
 		s3Exporter.ConsumeMetrics,
 		exporterhelper.WithStart(s3Exporter.start),
+		exporterhelper.WithShutdown(s3Exporter.shutdown),
 		exporterhelper.WithQueue(cfg.QueueSettings),
 		exporterhelper.WithTimeout(cfg.TimeoutSettings),
+		exporterhelper.WithRetry(cfg.BackOffConfig),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	if cfg.ResourceAttrsToS3.S3Prefix == "" {
-		return metricsExporter, err
-	}
-
 	wrapped := &baseMetricsExporter{
-		Component: metricsExporter,
-		Metrics:   batchperresourceattr.NewBatchPerResourceMetrics(cfg.ResourceAttrsToS3.S3Prefix, metricsExporter),
+		Component: &drainOnShutdown{Component: metricsExporter, drain: &s3Exporter.drain},
+		Metrics:   metricsExporter,
+	}
+	if batchAttributes := cfg.batchAttributes(); len(batchAttributes) > 0 {
+		wrapped.Metrics = batchperresourceattr.NewMultiBatchPerResourceMetrics(batchAttributes, metricsExporter)
 	}
 	return wrapped, nil
 }
@@ -146,29 +174,37 @@ func createTracesExporter(ctx context.Context,
 
 	s3Exporter := newS3Exporter(cfg, "traces", params)
 
-	if config.(*Config).MarshalerName == SumoIC {
+	encoding, _ := cfg.signalEncoding("traces")
+	if encoding == nil && cfg.MarshalerName == SumoIC {
 		return nil, errors.New("traces are not supported by sumo_ic output format")
 	}
+	if encoding == nil && cfg.MarshalerName == Parquet {
+		return nil, errors.New("traces are not supported by parquet output format")
+	}
+	if encoding == nil && cfg.MarshalerName == Passthrough {
+		return nil, errors.New("traces are not supported by passthrough output format")
+	}
 
 	tracesExporter, err := exporterhelper.NewTraces(ctx,
 		params,
 		config,
 		s3Exporter.ConsumeTraces,
 		exporterhelper.WithStart(s3Exporter.start),
+		exporterhelper.WithShutdown(s3Exporter.shutdown),
 		exporterhelper.WithQueue(cfg.QueueSettings),
 		exporterhelper.WithTimeout(cfg.TimeoutSettings),
+		exporterhelper.WithRetry(cfg.BackOffConfig),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	if cfg.ResourceAttrsToS3.S3Prefix == "" {
-		return tracesExporter, err
-	}
-
 	wrapped := &baseTracesExporter{
-		Component: tracesExporter,
-		Traces:    batchperresourceattr.NewBatchPerResourceTraces(cfg.ResourceAttrsToS3.S3Prefix, tracesExporter),
+		Component: &drainOnShutdown{Component: tracesExporter, drain: &s3Exporter.drain},
+		Traces:    tracesExporter,
+	}
+	if batchAttributes := cfg.batchAttributes(); len(batchAttributes) > 0 {
+		wrapped.Traces = batchperresourceattr.NewMultiBatchPerResourceTraces(batchAttributes, tracesExporter)
 	}
 	return wrapped, nil
 }
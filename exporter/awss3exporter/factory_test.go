@@ -67,4 +67,29 @@ func TestUnsupportedMarshalerOptions(t *testing.T) {
 		cfg)
 	assert.Error(t, err)
 	require.Nil(t, exp2)
+
+	cfg = createDefaultConfig()
+	cfg.(*Config).MarshalerName = Parquet
+	exp3, err := createTracesExporter(
+		context.Background(),
+		exportertest.NewNopSettings(metadata.Type),
+		cfg)
+	assert.Error(t, err)
+	require.Nil(t, exp3)
+
+	cfg = createDefaultConfig()
+	cfg.(*Config).MarshalerName = Passthrough
+	exp4, err := createMetricsExporter(
+		context.Background(),
+		exportertest.NewNopSettings(metadata.Type),
+		cfg)
+	assert.Error(t, err)
+	require.Nil(t, exp4)
+
+	exp5, err := createTracesExporter(
+		context.Background(),
+		exportertest.NewNopSettings(metadata.Type),
+		cfg)
+	assert.Error(t, err)
+	require.Nil(t, exp5)
 }
@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"
+
+import (
+	"bytes"
+	"encoding/binary"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// framedMarshaler writes every resource as a record of its own, framed the way
+// Kinesis Data Firehose frames the records it delivers to S3, e.g. those of
+// CloudWatch metric streams.
+type framedMarshaler struct {
+	marshaler marshaler
+	framing   FramingType
+}
+
+func newFramedMarshaler(m marshaler, framing FramingType) marshaler {
+	if framing == "" {
+		return m
+	}
+	return &framedMarshaler{marshaler: m, framing: framing}
+}
+
+func (f *framedMarshaler) MarshalTraces(td ptrace.Traces) ([]byte, error) {
+	buf := bytes.Buffer{}
+	for i := 0; i < td.ResourceSpans().Len(); i++ {
+		record := ptrace.NewTraces()
+		td.ResourceSpans().At(i).CopyTo(record.ResourceSpans().AppendEmpty())
+		b, err := f.marshaler.MarshalTraces(record)
+		if err != nil {
+			return nil, err
+		}
+		f.frame(&buf, b)
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *framedMarshaler) MarshalLogs(ld plog.Logs) ([]byte, error) {
+	buf := bytes.Buffer{}
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		record := plog.NewLogs()
+		ld.ResourceLogs().At(i).CopyTo(record.ResourceLogs().AppendEmpty())
+		b, err := f.marshaler.MarshalLogs(record)
+		if err != nil {
+			return nil, err
+		}
+		f.frame(&buf, b)
+	}
+	return buf.Bytes(), nil
+}
+
+func (f *framedMarshaler) MarshalMetrics(md pmetric.Metrics) ([]byte, error) {
+	buf := bytes.Buffer{}
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		record := pmetric.NewMetrics()
+		md.ResourceMetrics().At(i).CopyTo(record.ResourceMetrics().AppendEmpty())
+		b, err := f.marshaler.MarshalMetrics(record)
+		if err != nil {
+			return nil, err
+		}
+		f.frame(&buf, b)
+	}
+	return buf.Bytes(), nil
+}
+
+// frame appends record to buf, preceded by its size as a varint when length
+// prefixed, or followed by a newline otherwise.
+func (f *framedMarshaler) frame(buf *bytes.Buffer, record []byte) {
+	if f.framing == FramingLengthPrefixed {
+		buf.Write(binary.AppendUvarint(nil, uint64(len(record))))
+		buf.Write(record)
+		return
+	}
+	// the OTLP JSON marshalers never write a newline within a record
+	buf.Write(record)
+	buf.WriteByte('\n')
+}
+
+func (f *framedMarshaler) format() string {
+	return f.marshaler.format()
+}
@@ -0,0 +1,107 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+func newFramingTestLogs() plog.Logs {
+	logs := plog.NewLogs()
+	for _, service := range []string{"a", "b"} {
+		rl := logs.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("service.name", service)
+		rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStr("log of " + service)
+	}
+	return logs
+}
+
+func TestFramedMarshaler(t *testing.T) {
+	t.Run("no framing", func(t *testing.T) {
+		m, err := newMarshaler(OtlpJSON, zap.NewNop())
+		require.NoError(t, err)
+		assert.Same(t, m, newFramedMarshaler(m, ""))
+	})
+
+	t.Run("newline", func(t *testing.T) {
+		m, err := newMarshaler(OtlpJSON, zap.NewNop())
+		require.NoError(t, err)
+		framed := newFramedMarshaler(m, FramingNewline)
+		assert.Equal(t, "json", framed.format())
+
+		logs := newFramingTestLogs()
+		buf, err := framed.MarshalLogs(logs)
+		require.NoError(t, err)
+
+		scanner := bufio.NewScanner(bytes.NewReader(buf))
+		unmarshaler := plog.JSONUnmarshaler{}
+		var records int
+		for ; scanner.Scan(); records++ {
+			record, err := unmarshaler.UnmarshalLogs(scanner.Bytes())
+			require.NoError(t, err)
+			require.Equal(t, 1, record.ResourceLogs().Len())
+			expected := plog.NewLogs()
+			logs.ResourceLogs().At(records).CopyTo(expected.ResourceLogs().AppendEmpty())
+			assert.Equal(t, expected, record)
+		}
+		require.NoError(t, scanner.Err())
+		assert.Equal(t, 2, records)
+	})
+
+	t.Run("length prefixed", func(t *testing.T) {
+		m, err := newMarshaler(OtlpProtobuf, zap.NewNop())
+		require.NoError(t, err)
+		framed := newFramedMarshaler(m, FramingLengthPrefixed)
+
+		logs := newFramingTestLogs()
+		buf, err := framed.MarshalLogs(logs)
+		require.NoError(t, err)
+
+		reader := bytes.NewReader(buf)
+		unmarshaler := plog.ProtoUnmarshaler{}
+		var records int
+		for ; reader.Len() > 0; records++ {
+			size, err := binary.ReadUvarint(reader)
+			require.NoError(t, err)
+			b := make([]byte, size)
+			_, err = reader.Read(b)
+			require.NoError(t, err)
+			record, err := unmarshaler.UnmarshalLogs(b)
+			require.NoError(t, err)
+			expected := plog.NewLogs()
+			logs.ResourceLogs().At(records).CopyTo(expected.ResourceLogs().AppendEmpty())
+			assert.Equal(t, expected, record)
+		}
+		assert.Equal(t, 2, records)
+	})
+
+	t.Run("traces and metrics", func(t *testing.T) {
+		m, err := newMarshaler(OtlpJSON, zap.NewNop())
+		require.NoError(t, err)
+		framed := newFramedMarshaler(m, FramingNewline)
+
+		traces := ptrace.NewTraces()
+		traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("a")
+		traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("b")
+		buf, err := framed.MarshalTraces(traces)
+		require.NoError(t, err)
+		assert.Equal(t, 2, bytes.Count(buf, []byte("\n")))
+
+		metrics := pmetric.NewMetrics()
+		metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetName("a")
+		buf, err = framed.MarshalMetrics(metrics)
+		require.NoError(t, err)
+		assert.Equal(t, 1, bytes.Count(buf, []byte("\n")))
+	})
+}
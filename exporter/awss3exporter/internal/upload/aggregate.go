@@ -0,0 +1,349 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// ErrAggregationFull is returned for the payloads that cannot be buffered because
+// the memory limit, or the spill limit when spilling to disk, is reached.
+var ErrAggregationFull = errors.New("the aggregation buffer is full")
+
+// aggregate holds the payloads of an object being aggregated, in memory or,
+// once spilled, in a file.
+type aggregate struct {
+	opts     *UploadOptions
+	records  int
+	mem      []byte
+	spill    *os.File
+	spilled  int64
+	deadline time.Time
+}
+
+func (g *aggregate) size() int64 {
+	if g.spill != nil {
+		return g.spilled
+	}
+	return int64(len(g.mem))
+}
+
+// Aggregator buffers the payloads uploaded with the same options and uploads them
+// as a single object once they reach a maximum size or once an interval elapsed,
+// reducing the number of objects, and of PUT requests, for small payloads.
+type Aggregator struct {
+	next          Manager
+	maxSize       int64
+	interval      time.Duration
+	lineDelimited bool
+	spillDir      string
+	maxMemorySize int64
+	maxSpillSize  int64
+	logger        *zap.Logger
+
+	mu sync.Mutex
+	// open holds the aggregates receiving the payloads, by options.
+	open map[string]*aggregate
+	// failed holds the aggregates whose upload failed, retried once their deadline is over.
+	failed  []*aggregate
+	memory  int64
+	spilled int64
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var _ Manager = (*Aggregator)(nil)
+
+type AggregatorOpt func(*Aggregator)
+
+// WithLineDelimitedPayloads terminates every aggregated payload with a newline,
+// so that the JSON documents of the payloads are on separate lines.
+func WithLineDelimitedPayloads() AggregatorOpt {
+	return func(a *Aggregator) {
+		a.lineDelimited = true
+	}
+}
+
+// WithSpill moves the aggregates to files in dir once the payloads buffered in
+// memory exceed maxMemorySize, and rejects the payloads once the spilled ones
+// exceed maxSpillSize. Without it, the payloads are rejected once the ones in
+// memory exceed maxMemorySize.
+func WithSpill(dir string, maxSpillSize int64) AggregatorOpt {
+	return func(a *Aggregator) {
+		a.spillDir = dir
+		a.maxSpillSize = maxSpillSize
+	}
+}
+
+func NewAggregator(
+	next Manager,
+	maxSize int64,
+	interval time.Duration,
+	maxMemorySize int64,
+	logger *zap.Logger,
+	opts ...AggregatorOpt,
+) *Aggregator {
+	a := &Aggregator{
+		next:          next,
+		maxSize:       maxSize,
+		interval:      interval,
+		maxMemorySize: maxMemorySize,
+		logger:        logger,
+		open:          make(map[string]*aggregate),
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// Upload buffers data, and uploads the object it belongs to once it reaches the
+// maximum size. Uploads that fail are retried after the interval, so data is only
+// rejected when it cannot be buffered.
+func (a *Aggregator) Upload(ctx context.Context, data []byte, opts *UploadOptions) error {
+	if len(data) == 0 {
+		return nil
+	}
+	key, err := aggregationKey(opts)
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	g, ok := a.open[key]
+	if !ok {
+		g = &aggregate{opts: opts, deadline: time.Now().Add(a.interval)}
+		a.open[key] = g
+	}
+	if err = a.append(g, data); err != nil {
+		if g.size() == 0 {
+			delete(a.open, key)
+		}
+		a.mu.Unlock()
+		return err
+	}
+	if opts != nil {
+		g.records += opts.Records
+	}
+	full := g.size() >= a.maxSize
+	if full {
+		delete(a.open, key)
+	}
+	a.mu.Unlock()
+
+	if full {
+		a.flush(ctx, g)
+	}
+	return nil
+}
+
+// append adds data to g, spilling it to a file once the memory limit is reached.
+// It must be called with the lock held.
+func (a *Aggregator) append(g *aggregate, data []byte) error {
+	n := int64(len(data))
+	newline := a.lineDelimited && data[len(data)-1] != '\n'
+	if newline {
+		n++
+	}
+	if g.spill == nil && a.memory+n > a.maxMemorySize {
+		if a.spillDir == "" {
+			return ErrAggregationFull
+		}
+		if err := a.spillAggregate(g); err != nil {
+			return err
+		}
+	}
+	if g.spill == nil {
+		g.mem = append(g.mem, data...)
+		if newline {
+			g.mem = append(g.mem, '\n')
+		}
+		a.memory += n
+		return nil
+	}
+
+	if a.spilled+n > a.maxSpillSize {
+		return ErrAggregationFull
+	}
+	if _, err := g.spill.Write(data); err != nil {
+		return fmt.Errorf("failed to spill the aggregated payloads: %w", err)
+	}
+	if newline {
+		if _, err := g.spill.Write([]byte{'\n'}); err != nil {
+			return fmt.Errorf("failed to spill the aggregated payloads: %w", err)
+		}
+	}
+	g.spilled += n
+	a.spilled += n
+	return nil
+}
+
+// spillAggregate moves the payloads of g to a file in the spill directory.
+// It must be called with the lock held.
+func (a *Aggregator) spillAggregate(g *aggregate) error {
+	n := int64(len(g.mem))
+	if a.spilled+n > a.maxSpillSize {
+		return ErrAggregationFull
+	}
+	f, err := os.CreateTemp(a.spillDir, "awss3-aggregate-*")
+	if err != nil {
+		return fmt.Errorf("failed to spill the aggregated payloads: %w", err)
+	}
+	if _, err = f.Write(g.mem); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return fmt.Errorf("failed to spill the aggregated payloads: %w", err)
+	}
+	a.memory -= n
+	a.spilled += n
+	g.mem = nil
+	g.spill = f
+	g.spilled = n
+	return nil
+}
+
+// flush uploads g and releases its payloads, or keeps it to be retried after
+// the interval when the upload fails.
+func (a *Aggregator) flush(ctx context.Context, g *aggregate) {
+	if err := a.upload(ctx, g); err != nil {
+		a.logger.Error("Failed to upload the aggregated payloads, retrying after the interval",
+			zap.Int64("size", g.size()),
+			zap.Duration("interval", a.interval),
+			zap.Error(err))
+		a.mu.Lock()
+		g.deadline = time.Now().Add(a.interval)
+		a.failed = append(a.failed, g)
+		a.mu.Unlock()
+		return
+	}
+	a.release(g)
+}
+
+func (a *Aggregator) upload(ctx context.Context, g *aggregate) error {
+	data := g.mem
+	if g.spill != nil {
+		var err error
+		if data, err = os.ReadFile(g.spill.Name()); err != nil {
+			return fmt.Errorf("failed to read the spilled payloads: %w", err)
+		}
+	}
+	opts := g.opts
+	if opts != nil {
+		// The object holds the records of all the aggregated payloads.
+		aggregated := *opts
+		aggregated.Records = g.records
+		opts = &aggregated
+	}
+	return a.next.Upload(ctx, data, opts)
+}
+
+// release frees the memory or the file holding the payloads of g.
+func (a *Aggregator) release(g *aggregate) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if g.spill != nil {
+		a.spilled -= g.spilled
+		_ = g.spill.Close()
+		if err := os.Remove(g.spill.Name()); err != nil {
+			a.logger.Warn("Failed to remove the spilled payloads", zap.String("path", g.spill.Name()), zap.Error(err))
+		}
+		return
+	}
+	a.memory -= int64(len(g.mem))
+}
+
+// FlushDue uploads the aggregates whose interval is over at now.
+func (a *Aggregator) FlushDue(ctx context.Context, now time.Time) {
+	for _, g := range a.take(func(g *aggregate) bool { return !now.Before(g.deadline) }) {
+		a.flush(ctx, g)
+	}
+}
+
+// take removes the aggregates matching due and returns them.
+func (a *Aggregator) take(due func(*aggregate) bool) []*aggregate {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// The failed aggregates come first, as they hold the oldest payloads.
+	var taken []*aggregate
+	failed := a.failed[:0]
+	for _, g := range a.failed {
+		if due(g) {
+			taken = append(taken, g)
+		} else {
+			failed = append(failed, g)
+		}
+	}
+	a.failed = failed
+	for key, g := range a.open {
+		if due(g) {
+			delete(a.open, key)
+			taken = append(taken, g)
+		}
+	}
+	return taken
+}
+
+// Start runs the periodic upload of the aggregates whose interval is over
+// until Shutdown is called.
+func (a *Aggregator) Start(ctx context.Context) {
+	ctx, a.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	a.done = make(chan struct{})
+	// Check a few times per interval to bound how late aggregates are uploaded.
+	tick := max(a.interval/10, 100*time.Millisecond)
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(tick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case now := <-ticker.C:
+				a.FlushDue(ctx, now)
+			}
+		}
+	}()
+}
+
+// Shutdown stops the periodic uploads and uploads all the aggregates, failed
+// ones included. The payloads that could not be uploaded are dropped.
+func (a *Aggregator) Shutdown(ctx context.Context) error {
+	if a.cancel != nil {
+		a.cancel()
+		<-a.done
+	}
+	var errs error
+	var size int64
+	for _, g := range a.take(func(*aggregate) bool { return true }) {
+		if err := a.upload(ctx, g); err != nil {
+			errs = multierr.Append(errs, err)
+			size += g.size()
+		}
+		a.release(g)
+	}
+	if errs != nil {
+		return fmt.Errorf("failed to upload %d bytes of aggregated payloads: %w", size, errs)
+	}
+	return nil
+}
+
+// aggregationKey identifies the options of the payloads aggregated together.
+func aggregationKey(opts *UploadOptions) (string, error) {
+	// Maps are marshaled with sorted keys, so equal options have equal keys.
+	key, err := json.Marshal(opts)
+	if err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
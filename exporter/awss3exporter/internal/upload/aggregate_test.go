@@ -0,0 +1,218 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// recordingManager records the uploads, failing them while err is set.
+type recordingManager struct {
+	mu      sync.Mutex
+	uploads map[string]string
+	err     error
+}
+
+func newRecordingManager() *recordingManager {
+	return &recordingManager{uploads: make(map[string]string)}
+}
+
+func (m *recordingManager) Upload(_ context.Context, data []byte, opts *UploadOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	prefix := ""
+	if opts != nil {
+		prefix = opts.OverridePrefix
+	}
+	m.uploads[prefix] += string(data) + "|"
+	return nil
+}
+
+func (m *recordingManager) failWith(err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.err = err
+}
+
+func (m *recordingManager) recorded() map[string]string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	uploads := make(map[string]string, len(m.uploads))
+	for k, v := range m.uploads {
+		uploads[k] = v
+	}
+	return uploads
+}
+
+func TestAggregatorUpload(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	tenantA := &UploadOptions{OverridePrefix: "a"}
+	tenantB := &UploadOptions{OverridePrefix: "b"}
+
+	t.Run("uploads once the max size is reached", func(t *testing.T) {
+		t.Parallel()
+
+		m := newRecordingManager()
+		a := NewAggregator(m, 12, time.Hour, 1024, zap.NewNop(), WithLineDelimitedPayloads())
+
+		require.NoError(t, a.Upload(ctx, []byte(`{"a":1}`), tenantA))
+		require.NoError(t, a.Upload(ctx, []byte(`{"b":1}`), tenantB))
+		assert.Empty(t, m.recorded())
+
+		require.NoError(t, a.Upload(ctx, []byte("{\"a\":2}\n"), &UploadOptions{OverridePrefix: "a"}))
+		assert.Equal(t, map[string]string{"a": "{\"a\":1}\n{\"a\":2}\n|"}, m.recorded())
+		assert.Equal(t, int64(len("{\"b\":1}\n")), a.memory)
+	})
+
+	t.Run("uploads once the interval is over", func(t *testing.T) {
+		t.Parallel()
+
+		m := newRecordingManager()
+		a := NewAggregator(m, 1024, time.Minute, 1024, zap.NewNop())
+
+		require.NoError(t, a.Upload(ctx, []byte("a1"), tenantA))
+		require.NoError(t, a.Upload(ctx, []byte("a2"), tenantA))
+
+		a.FlushDue(ctx, time.Now())
+		assert.Empty(t, m.recorded())
+
+		a.FlushDue(ctx, time.Now().Add(time.Minute))
+		assert.Equal(t, map[string]string{"a": "a1a2|"}, m.recorded())
+		assert.Zero(t, a.memory)
+	})
+
+	t.Run("rejects payloads beyond the memory limit", func(t *testing.T) {
+		t.Parallel()
+
+		m := newRecordingManager()
+		a := NewAggregator(m, 8, time.Minute, 8, zap.NewNop())
+
+		require.NoError(t, a.Upload(ctx, []byte("a1"), tenantA))
+		require.NoError(t, a.Upload(ctx, []byte("b1b1b1"), tenantB))
+		require.ErrorIs(t, a.Upload(ctx, []byte("a2"), tenantA), ErrAggregationFull)
+		require.ErrorIs(t, a.Upload(ctx, []byte("c1"), &UploadOptions{OverridePrefix: "c"}), ErrAggregationFull)
+		assert.Len(t, a.open, 2)
+	})
+
+	t.Run("spills payloads beyond the memory limit", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		m := newRecordingManager()
+		a := NewAggregator(m, 1024, time.Minute, 4, zap.NewNop(), WithSpill(dir, 8))
+
+		require.NoError(t, a.Upload(ctx, []byte("a1"), tenantA))
+		require.NoError(t, a.Upload(ctx, []byte("a2"), tenantA))
+		require.NoError(t, a.Upload(ctx, []byte("a3"), tenantA))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+		assert.Zero(t, a.memory)
+		assert.Equal(t, int64(6), a.spilled)
+
+		require.NoError(t, a.Upload(ctx, []byte("b1"), tenantB))
+		require.ErrorIs(t, a.Upload(ctx, []byte("a4a4a4"), tenantA), ErrAggregationFull)
+
+		a.FlushDue(ctx, time.Now().Add(time.Minute))
+		assert.Equal(t, map[string]string{"a": "a1a2a3|", "b": "b1|"}, m.recorded())
+		entries, err = os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+		assert.Zero(t, a.spilled)
+	})
+
+	t.Run("retries failed uploads after the interval", func(t *testing.T) {
+		t.Parallel()
+
+		m := newRecordingManager()
+		a := NewAggregator(m, 4, time.Minute, 1024, zap.NewNop())
+
+		m.failWith(errors.New("unavailable"))
+		require.NoError(t, a.Upload(ctx, []byte("a1a1"), tenantA))
+		assert.Len(t, a.failed, 1)
+
+		m.failWith(nil)
+		require.NoError(t, a.Upload(ctx, []byte("a2"), tenantA))
+		a.FlushDue(ctx, time.Now())
+		assert.Empty(t, m.recorded())
+
+		a.FlushDue(ctx, time.Now().Add(time.Minute))
+		assert.Equal(t, map[string]string{"a": "a1a1|a2|"}, m.recorded())
+		assert.Empty(t, a.failed)
+		assert.Zero(t, a.memory)
+	})
+
+	t.Run("uploads the records of all the payloads", func(t *testing.T) {
+		t.Parallel()
+
+		notifier := &recordingNotifier{}
+		next := &notifyingManager{notifier: notifier}
+		a := NewAggregator(next, 1024, time.Minute, 1024, zap.NewNop())
+
+		require.NoError(t, a.Upload(ctx, []byte("a1"), &UploadOptions{OverridePrefix: "a", Records: 2}))
+		require.NoError(t, a.Upload(ctx, []byte("a2"), &UploadOptions{OverridePrefix: "a", Records: 3}))
+		assert.Len(t, a.open, 1)
+
+		a.FlushDue(ctx, time.Now().Add(time.Minute))
+		require.Len(t, notifier.notifications, 1)
+		assert.Equal(t, 5, notifier.notifications[0].Records)
+	})
+}
+
+// notifyingManager notifies the uploads without storing them.
+type notifyingManager struct {
+	notifier Notifier
+}
+
+func (m *notifyingManager) Upload(ctx context.Context, data []byte, opts *UploadOptions) error {
+	return m.notifier.Notify(ctx, Notification{Key: opts.OverridePrefix, Records: opts.Records, Size: len(data)})
+}
+
+func TestAggregatorShutdown(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("uploads all the payloads", func(t *testing.T) {
+		t.Parallel()
+
+		m := newRecordingManager()
+		a := NewAggregator(m, 1024, time.Hour, 1024, zap.NewNop())
+		a.Start(ctx)
+
+		require.NoError(t, a.Upload(ctx, []byte("a1"), &UploadOptions{OverridePrefix: "a"}))
+		require.NoError(t, a.Upload(ctx, []byte("b1"), &UploadOptions{OverridePrefix: "b"}))
+		require.NoError(t, a.Shutdown(ctx))
+		assert.Equal(t, map[string]string{"a": "a1|", "b": "b1|"}, m.recorded())
+	})
+
+	t.Run("drops the payloads that cannot be uploaded", func(t *testing.T) {
+		t.Parallel()
+
+		dir := t.TempDir()
+		m := newRecordingManager()
+		m.failWith(errors.New("unavailable"))
+		a := NewAggregator(m, 1024, time.Hour, 0, zap.NewNop(), WithSpill(dir, 1024))
+
+		require.NoError(t, a.Upload(ctx, []byte("a1"), nil))
+		require.EqualError(t, a.Shutdown(ctx), "failed to upload 2 bytes of aggregated payloads: unavailable")
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"go.uber.org/zap"
+)
+
+// defaultRegion is the region in which buckets are created without a location constraint.
+const defaultRegion = "us-east-1"
+
+// BucketAPI is the subset of the S3 client used to create a missing bucket.
+type BucketAPI interface {
+	HeadBucket(ctx context.Context, params *s3.HeadBucketInput, optFns ...func(*s3.Options)) (*s3.HeadBucketOutput, error)
+	CreateBucket(ctx context.Context, params *s3.CreateBucketInput, optFns ...func(*s3.Options)) (*s3.CreateBucketOutput, error)
+	PutBucketEncryption(ctx context.Context, params *s3.PutBucketEncryptionInput, optFns ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error)
+	PutBucketLifecycleConfiguration(ctx context.Context, params *s3.PutBucketLifecycleConfigurationInput, optFns ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error)
+}
+
+var _ BucketAPI = (*s3.Client)(nil)
+
+// BucketSettings are applied to a bucket created by EnsureBucket.
+type BucketSettings struct {
+	Region string
+	// SSE is the default server side encryption of the bucket, left to the
+	// S3 defaults when empty.
+	SSE      s3types.ServerSideEncryption
+	KMSKeyID string
+	// BucketKey enables the S3 Bucket Key of the default KMS based encryption.
+	BucketKey bool
+	// LifecycleRules are set as the lifecycle configuration of the bucket.
+	LifecycleRules []s3types.LifecycleRule
+	// ObjectLock enables S3 Object Lock on the bucket, which can only be done
+	// when it is created.
+	ObjectLock bool
+	// ObjectOwnership is the object ownership of the bucket, left to the S3
+	// defaults when empty.
+	ObjectOwnership s3types.ObjectOwnership
+}
+
+// EnsureBucket creates the bucket, with its default encryption and lifecycle rules, if it
+// does not exist. An existing bucket is left untouched.
+func EnsureBucket(ctx context.Context, client BucketAPI, bucket string, settings BucketSettings, logger *zap.Logger) error {
+	_, err := client.HeadBucket(ctx, &s3.HeadBucketInput{Bucket: aws.String(bucket)})
+	if err == nil {
+		return nil
+	}
+	var notFound *s3types.NotFound
+	var noSuchBucket *s3types.NoSuchBucket
+	if !errors.As(err, &notFound) && !errors.As(err, &noSuchBucket) {
+		return fmt.Errorf("failed to check bucket %q: %w", bucket, err)
+	}
+
+	logger.Info("Creating missing bucket", zap.String("bucket", bucket), zap.String("region", settings.Region))
+	input := &s3.CreateBucketInput{Bucket: aws.String(bucket)}
+	if settings.Region != "" && settings.Region != defaultRegion {
+		input.CreateBucketConfiguration = &s3types.CreateBucketConfiguration{
+			LocationConstraint: s3types.BucketLocationConstraint(settings.Region),
+		}
+	}
+	if settings.ObjectLock {
+		input.ObjectLockEnabledForBucket = aws.Bool(true)
+	}
+	input.ObjectOwnership = settings.ObjectOwnership
+	if _, err = client.CreateBucket(ctx, input); err != nil {
+		var ownedByYou *s3types.BucketAlreadyOwnedByYou
+		if !errors.As(err, &ownedByYou) {
+			return fmt.Errorf("failed to create bucket %q: %w", bucket, err)
+		}
+		// another exporter created the bucket in the meantime, and is
+		// responsible for its settings
+		return nil
+	}
+
+	if settings.SSE != "" {
+		rule := &s3types.ServerSideEncryptionByDefault{SSEAlgorithm: settings.SSE}
+		if settings.KMSKeyID != "" {
+			rule.KMSMasterKeyID = aws.String(settings.KMSKeyID)
+		}
+		encryption := s3types.ServerSideEncryptionRule{ApplyServerSideEncryptionByDefault: rule}
+		if settings.BucketKey {
+			encryption.BucketKeyEnabled = aws.Bool(true)
+		}
+		if _, err = client.PutBucketEncryption(ctx, &s3.PutBucketEncryptionInput{
+			Bucket: aws.String(bucket),
+			ServerSideEncryptionConfiguration: &s3types.ServerSideEncryptionConfiguration{
+				Rules: []s3types.ServerSideEncryptionRule{encryption},
+			},
+		}); err != nil {
+			return fmt.Errorf("failed to set default encryption of bucket %q: %w", bucket, err)
+		}
+	}
+
+	if len(settings.LifecycleRules) > 0 {
+		if _, err = client.PutBucketLifecycleConfiguration(ctx, &s3.PutBucketLifecycleConfigurationInput{
+			Bucket:                 aws.String(bucket),
+			LifecycleConfiguration: &s3types.BucketLifecycleConfiguration{Rules: settings.LifecycleRules},
+		}); err != nil {
+			return fmt.Errorf("failed to set lifecycle rules of bucket %q: %w", bucket, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeBucketAPI records the bucket operations, headErr and createErr are
+// returned by HeadBucket and CreateBucket.
+type fakeBucketAPI struct {
+	headErr   error
+	createErr error
+
+	created    *s3.CreateBucketInput
+	encryption *s3.PutBucketEncryptionInput
+	lifecycle  *s3.PutBucketLifecycleConfigurationInput
+}
+
+func (f *fakeBucketAPI) HeadBucket(context.Context, *s3.HeadBucketInput, ...func(*s3.Options)) (*s3.HeadBucketOutput, error) {
+	return &s3.HeadBucketOutput{}, f.headErr
+}
+
+func (f *fakeBucketAPI) CreateBucket(_ context.Context, in *s3.CreateBucketInput, _ ...func(*s3.Options)) (*s3.CreateBucketOutput, error) {
+	f.created = in
+	return &s3.CreateBucketOutput{}, f.createErr
+}
+
+func (f *fakeBucketAPI) PutBucketEncryption(_ context.Context, in *s3.PutBucketEncryptionInput, _ ...func(*s3.Options)) (*s3.PutBucketEncryptionOutput, error) {
+	f.encryption = in
+	return &s3.PutBucketEncryptionOutput{}, nil
+}
+
+func (f *fakeBucketAPI) PutBucketLifecycleConfiguration(_ context.Context, in *s3.PutBucketLifecycleConfigurationInput, _ ...func(*s3.Options)) (*s3.PutBucketLifecycleConfigurationOutput, error) {
+	f.lifecycle = in
+	return &s3.PutBucketLifecycleConfigurationOutput{}, nil
+}
+
+func TestEnsureBucket(t *testing.T) {
+	rules := []s3types.LifecycleRule{{
+		ID:         aws.String("expire"),
+		Status:     s3types.ExpirationStatusEnabled,
+		Expiration: &s3types.LifecycleExpiration{Days: aws.Int32(30)},
+	}}
+
+	t.Run("existing bucket is left untouched", func(t *testing.T) {
+		client := &fakeBucketAPI{}
+		require.NoError(t, EnsureBucket(context.Background(), client, "bucket", BucketSettings{
+			Region:         "eu-central-1",
+			SSE:            s3types.ServerSideEncryptionAes256,
+			LifecycleRules: rules,
+		}, zap.NewNop()))
+		assert.Nil(t, client.created)
+		assert.Nil(t, client.encryption)
+		assert.Nil(t, client.lifecycle)
+	})
+
+	t.Run("missing bucket is created with its settings", func(t *testing.T) {
+		client := &fakeBucketAPI{headErr: &s3types.NotFound{}}
+		require.NoError(t, EnsureBucket(context.Background(), client, "bucket", BucketSettings{
+			Region:          "eu-central-1",
+			SSE:             s3types.ServerSideEncryptionAwsKms,
+			KMSKeyID:        "key",
+			BucketKey:       true,
+			LifecycleRules:  rules,
+			ObjectLock:      true,
+			ObjectOwnership: s3types.ObjectOwnershipBucketOwnerEnforced,
+		}, zap.NewNop()))
+
+		require.NotNil(t, client.created)
+		assert.Equal(t, "bucket", aws.ToString(client.created.Bucket))
+		assert.Equal(t, s3types.BucketLocationConstraint("eu-central-1"), client.created.CreateBucketConfiguration.LocationConstraint)
+		assert.True(t, aws.ToBool(client.created.ObjectLockEnabledForBucket))
+		assert.Equal(t, s3types.ObjectOwnershipBucketOwnerEnforced, client.created.ObjectOwnership)
+
+		require.NotNil(t, client.encryption)
+		byDefault := client.encryption.ServerSideEncryptionConfiguration.Rules[0].ApplyServerSideEncryptionByDefault
+		assert.Equal(t, s3types.ServerSideEncryptionAwsKms, byDefault.SSEAlgorithm)
+		assert.Equal(t, "key", aws.ToString(byDefault.KMSMasterKeyID))
+		assert.True(t, aws.ToBool(client.encryption.ServerSideEncryptionConfiguration.Rules[0].BucketKeyEnabled))
+
+		require.NotNil(t, client.lifecycle)
+		assert.Equal(t, rules, client.lifecycle.LifecycleConfiguration.Rules)
+	})
+
+	t.Run("default region has no location constraint", func(t *testing.T) {
+		client := &fakeBucketAPI{headErr: &s3types.NoSuchBucket{}}
+		require.NoError(t, EnsureBucket(context.Background(), client, "bucket", BucketSettings{Region: "us-east-1"}, zap.NewNop()))
+		require.NotNil(t, client.created)
+		assert.Nil(t, client.created.CreateBucketConfiguration)
+		assert.Nil(t, client.created.ObjectLockEnabledForBucket)
+		assert.Empty(t, client.created.ObjectOwnership)
+		assert.Nil(t, client.encryption)
+		assert.Nil(t, client.lifecycle)
+	})
+
+	t.Run("bucket created concurrently", func(t *testing.T) {
+		client := &fakeBucketAPI{headErr: &s3types.NotFound{}, createErr: &s3types.BucketAlreadyOwnedByYou{}}
+		require.NoError(t, EnsureBucket(context.Background(), client, "bucket", BucketSettings{
+			SSE: s3types.ServerSideEncryptionAes256,
+		}, zap.NewNop()))
+		assert.Nil(t, client.encryption)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		client := &fakeBucketAPI{headErr: errors.New("forbidden")}
+		assert.ErrorContains(t, EnsureBucket(context.Background(), client, "bucket", BucketSettings{}, zap.NewNop()), "failed to check bucket")
+		assert.Nil(t, client.created)
+
+		client = &fakeBucketAPI{headErr: &s3types.NotFound{}, createErr: errors.New("denied")}
+		assert.ErrorContains(t, EnsureBucket(context.Background(), client, "bucket", BucketSettings{}, zap.NewNop()), "failed to create bucket")
+	})
+}
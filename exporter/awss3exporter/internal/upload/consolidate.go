@@ -0,0 +1,470 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/tilinna/clock"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.uber.org/zap"
+)
+
+const (
+	consolidatedKeyMarker = "_consolidated_"
+	manifestSuffix        = ".manifest.json"
+	// maxDeleteObjects is the maximum number of keys accepted by a single DeleteObjects call.
+	maxDeleteObjects = 1000
+)
+
+// ConsolidationAPI is the subset of the S3 client used to merge the objects of an hour.
+type ConsolidationAPI interface {
+	ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error)
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	PutObject(ctx context.Context, params *s3.PutObjectInput, optFns ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	DeleteObjects(ctx context.Context, params *s3.DeleteObjectsInput, optFns ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error)
+}
+
+var _ ConsolidationAPI = (*s3.Client)(nil)
+
+// manifest records the intent of a consolidation so that the parts
+// are only ever removed once the consolidated object is known to exist.
+type manifest struct {
+	Target string   `json:"target"`
+	Size   int64    `json:"size"`
+	Parts  []string `json:"parts"`
+}
+
+type consolidationTarget struct {
+	bucket string
+	prefix string
+	hour   time.Time
+}
+
+// Consolidator merges the small objects written during an hour into a single
+// object once the hour is over, and removes the merged parts afterwards.
+type Consolidator struct {
+	bucket       string
+	builder      *PartitionKeyBuilder
+	client       ConsolidationAPI
+	storageClass s3types.StorageClass
+	sse          s3types.ServerSideEncryption
+	kmsKeyID     string
+	bucketKey    bool
+	tags         map[string]string
+	metadata     map[string]string
+	contentType  string
+	requestPayer s3types.RequestPayer
+	delay        time.Duration
+	logger       *zap.Logger
+	// lineDelimited keeps the parts on separate lines.
+	lineDelimited bool
+
+	mu      sync.Mutex
+	pending map[consolidationTarget]struct{}
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+type ConsolidatorOpt func(*Consolidator)
+
+// WithConsolidatedContentType sets the content type of the consolidated objects.
+func WithConsolidatedContentType(contentType string) ConsolidatorOpt {
+	return func(c *Consolidator) {
+		c.contentType = contentType
+	}
+}
+
+// WithConsolidatedEncryption sets the server side encryption applied to the
+// consolidated objects.
+func WithConsolidatedEncryption(sse s3types.ServerSideEncryption, kmsKeyID string, bucketKey bool) ConsolidatorOpt {
+	return func(c *Consolidator) {
+		c.sse = sse
+		c.kmsKeyID = kmsKeyID
+		c.bucketKey = bucketKey
+	}
+}
+
+// WithConsolidatedTags sets the tags and the user metadata of the consolidated
+// objects.
+func WithConsolidatedTags(tags, metadata map[string]string) ConsolidatorOpt {
+	return func(c *Consolidator) {
+		c.tags = tags
+		c.metadata = metadata
+	}
+}
+
+// WithConsolidatedRequestPayer sets the payer of the requests of the consolidation.
+func WithConsolidatedRequestPayer(payer s3types.RequestPayer) ConsolidatorOpt {
+	return func(c *Consolidator) {
+		c.requestPayer = payer
+	}
+}
+
+// WithLineDelimitedParts terminates every merged part with a newline, unless it
+// already ends with one, e.g. for the JSON documents.
+func WithLineDelimitedParts() ConsolidatorOpt {
+	return func(c *Consolidator) {
+		c.lineDelimited = true
+	}
+}
+
+func NewConsolidator(
+	bucket string,
+	builder *PartitionKeyBuilder,
+	client ConsolidationAPI,
+	storageClass s3types.StorageClass,
+	delay time.Duration,
+	logger *zap.Logger,
+	opts ...ConsolidatorOpt,
+) *Consolidator {
+	c := &Consolidator{
+		bucket:       bucket,
+		builder:      builder,
+		client:       client,
+		storageClass: storageClass,
+		delay:        delay,
+		logger:       logger,
+		pending:      make(map[consolidationTarget]struct{}),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Track records that an object was written at ts so that its hour
+// is consolidated once it has passed.
+func (c *Consolidator) Track(bucket, prefix string, ts time.Time) {
+	if bucket == "" {
+		bucket = c.bucket
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pending[consolidationTarget{bucket: bucket, prefix: prefix, hour: ts.Truncate(time.Hour)}] = struct{}{}
+}
+
+// Start runs the consolidation loop in the background until Shutdown is called.
+func (c *Consolidator) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(context.WithoutCancel(ctx))
+	c.done = make(chan struct{})
+	go func() {
+		defer close(c.done)
+		for {
+			now := clock.Now(ctx)
+			next := now.Truncate(time.Hour).Add(time.Hour + c.delay)
+			if next.Sub(now) > time.Hour {
+				next = next.Add(-time.Hour)
+			}
+			timer := clock.NewTimer(ctx, next.Sub(now))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			if err := c.ConsolidatePending(ctx, clock.Now(ctx).Add(-c.delay)); err != nil {
+				c.logger.Warn("Failed to consolidate objects, will retry on the next hour", zap.Error(err))
+			}
+		}
+	}()
+}
+
+// Shutdown stops the consolidation loop. Hours that were not yet
+// consolidated are left untouched in the bucket.
+func (c *Consolidator) Shutdown(ctx context.Context) error {
+	if c.cancel == nil {
+		return nil
+	}
+	c.cancel()
+	select {
+	case <-c.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ConsolidatePending consolidates every tracked hour that ended before now.
+// Hours that fail to consolidate remain tracked and are retried on the next call.
+func (c *Consolidator) ConsolidatePending(ctx context.Context, now time.Time) error {
+	c.mu.Lock()
+	var due []consolidationTarget
+	for t := range c.pending {
+		if !t.hour.Add(time.Hour).After(now) {
+			due = append(due, t)
+		}
+	}
+	c.mu.Unlock()
+
+	sort.Slice(due, func(i, j int) bool { return due[i].hour.Before(due[j].hour) })
+
+	var errs []error
+	for _, t := range due {
+		if err := c.consolidate(ctx, t); err != nil {
+			errs = append(errs, fmt.Errorf("bucket %q hour %s: %w", t.bucket, t.hour.Format(time.RFC3339), err))
+			continue
+		}
+		c.mu.Lock()
+		delete(c.pending, t)
+		c.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+func (c *Consolidator) consolidate(ctx context.Context, t consolidationTarget) error {
+	listPrefix, dir := c.hourPrefix(t.hour, t.prefix)
+	target := dir + c.builder.FilePrefix + c.builder.Metadata + consolidatedKeyMarker + t.hour.Format("2006010215") + c.builder.suffix(c.builder.Compression)
+	manifestKey := target + manifestSuffix
+
+	keys, err := c.list(ctx, t.bucket, listPrefix)
+	if err != nil {
+		return err
+	}
+
+	// A manifest left behind by an earlier attempt means the parts it lists
+	// may already be merged into the target; finish or roll back that attempt first.
+	// The attempt only counts as committed once the target has the recorded size.
+	if _, ok := keys[manifestKey]; ok {
+		var m manifest
+		if err = c.readManifest(ctx, t.bucket, manifestKey, &m); err != nil {
+			return err
+		}
+		if size, ok := keys[m.Target]; ok && size == m.Size {
+			merged := withoutKey(m.Parts, m.Target)
+			if err = c.deleteKeys(ctx, t.bucket, merged); err != nil {
+				return err
+			}
+			for _, p := range merged {
+				delete(keys, p)
+			}
+		}
+		if err = c.deleteKeys(ctx, t.bucket, []string{manifestKey}); err != nil {
+			return err
+		}
+	}
+
+	parts := c.parts(keys)
+	if _, ok := keys[target]; ok {
+		// Objects uploaded after an earlier consolidation are appended to it.
+		parts = append([]string{target}, parts...)
+	}
+	if len(parts) < 2 {
+		return nil
+	}
+
+	merged, err := c.merge(ctx, t.bucket, parts)
+	if err != nil {
+		return err
+	}
+	content, err := compress(c.builder.Compression, merged)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(manifest{Target: target, Size: int64(len(content)), Parts: parts})
+	if err != nil {
+		return err
+	}
+	if err = c.put(ctx, t.bucket, manifestKey, body, ""); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
+	encoding := ""
+	if c.builder.Compression.IsCompressed() {
+		encoding = string(c.builder.Compression)
+	}
+	if err = c.put(ctx, t.bucket, target, content, encoding); err != nil {
+		return fmt.Errorf("failed to upload consolidated object: %w", err)
+	}
+
+	if err = c.deleteKeys(ctx, t.bucket, withoutKey(parts, target)); err != nil {
+		return err
+	}
+	return c.deleteKeys(ctx, t.bucket, []string{manifestKey})
+}
+
+// hourPrefix returns the key prefix shared by every object written during the hour,
+// and the directory the consolidated object is written to.
+func (c *Consolidator) hourPrefix(hour time.Time, overridePrefix string) (string, string) {
+	first := c.builder.bucketKeyPrefix(hour, overridePrefix)
+	last := c.builder.bucketKeyPrefix(hour.Add(time.Hour-time.Second), overridePrefix)
+	if first == last {
+		return first + "/", first + "/"
+	}
+	i := 0
+	for i < len(first) && i < len(last) && first[i] == last[i] {
+		i++
+	}
+	common := first[:i]
+	return common, common[:strings.LastIndex(common, "/")+1]
+}
+
+// list returns the size of every object stored under prefix, keyed by object key.
+func (c *Consolidator) list(ctx context.Context, bucket, prefix string) (map[string]int64, error) {
+	keys := make(map[string]int64)
+	p := s3.NewListObjectsV2Paginator(c.client, &s3.ListObjectsV2Input{
+		Bucket:       aws.String(bucket),
+		Prefix:       aws.String(prefix),
+		RequestPayer: c.requestPayer,
+	})
+	for p.HasMorePages() {
+		page, err := p.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects: %w", err)
+		}
+		for _, obj := range page.Contents {
+			keys[aws.ToString(obj.Key)] = aws.ToInt64(obj.Size)
+		}
+	}
+	return keys, nil
+}
+
+// parts returns the keys of the objects written by this exporter that
+// have not been consolidated yet, in upload order where the key allows it.
+func (c *Consolidator) parts(keys map[string]int64) []string {
+	base := c.builder.FilePrefix + c.builder.Metadata + "_"
+	var parts []string
+	for k := range keys {
+		name := path.Base(k)
+		if !strings.HasPrefix(name, base) ||
+			strings.HasPrefix(name, c.builder.FilePrefix+c.builder.Metadata+consolidatedKeyMarker) ||
+			strings.HasSuffix(name, manifestSuffix) {
+			continue
+		}
+		parts = append(parts, k)
+	}
+	sort.Strings(parts)
+	return parts
+}
+
+func (c *Consolidator) merge(ctx context.Context, bucket string, keys []string) ([]byte, error) {
+	var buf bytes.Buffer
+	for _, k := range keys {
+		data, err := c.get(ctx, bucket, k)
+		if err != nil {
+			return nil, err
+		}
+		// Parts below the compression threshold are stored uncompressed.
+		if c.builder.Compression == configcompression.TypeGzip && isGzip(data) {
+			if data, err = gunzip(data); err != nil {
+				return nil, fmt.Errorf("failed to decompress %q: %w", k, err)
+			}
+		}
+		buf.Write(data)
+		// Keep JSON documents on separate lines so the result stays readable
+		// by line oriented tools such as Athena.
+		if c.lineDelimited && len(data) > 0 && data[len(data)-1] != '\n' {
+			buf.WriteByte('\n')
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *Consolidator) readManifest(ctx context.Context, bucket, key string, m *manifest) error {
+	data, err := c.get(ctx, bucket, key)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(data, m); err != nil {
+		return fmt.Errorf("invalid manifest %q: %w", key, err)
+	}
+	return nil
+}
+
+func (c *Consolidator) get(ctx context.Context, bucket, key string) ([]byte, error) {
+	out, err := c.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		RequestPayer: c.requestPayer,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %q: %w", key, err)
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+func (c *Consolidator) put(ctx context.Context, bucket, key string, body []byte, encoding string) error {
+	input := &s3.PutObjectInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		Body:            bytes.NewReader(body),
+		ContentEncoding: aws.String(encoding),
+		StorageClass:    c.storageClass,
+		RequestPayer:    c.requestPayer,
+		Metadata:        c.metadata,
+		Tagging:         encodeTagging(c.tags),
+	}
+	if c.contentType != "" {
+		input.ContentType = aws.String(c.contentType)
+	}
+	if err := applyServerSideEncryption(input, c.sse, c.kmsKeyID, c.bucketKey, nil); err != nil {
+		return err
+	}
+	_, err := c.client.PutObject(ctx, input)
+	return err
+}
+
+func (c *Consolidator) deleteKeys(ctx context.Context, bucket string, keys []string) error {
+	for len(keys) > 0 {
+		n := min(len(keys), maxDeleteObjects)
+		objects := make([]s3types.ObjectIdentifier, 0, n)
+		for _, k := range keys[:n] {
+			objects = append(objects, s3types.ObjectIdentifier{Key: aws.String(k)})
+		}
+		out, err := c.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket:       aws.String(bucket),
+			Delete:       &s3types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+			RequestPayer: c.requestPayer,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete objects: %w", err)
+		}
+		if len(out.Errors) > 0 {
+			return fmt.Errorf("failed to delete %q: %s", aws.ToString(out.Errors[0].Key), aws.ToString(out.Errors[0].Message))
+		}
+		keys = keys[n:]
+	}
+	return nil
+}
+
+func withoutKey(keys []string, key string) []string {
+	out := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k != key {
+			out = append(out, k)
+		}
+	}
+	return out
+}
+
+// isGzip reports whether data starts with the gzip magic number.
+func isGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+func gunzip(data []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(zr)
+}
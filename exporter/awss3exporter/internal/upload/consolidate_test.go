@@ -0,0 +1,313 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.uber.org/zap"
+)
+
+// memoryS3 is an in memory implementation of the S3 operations
+// used by the consolidator.
+type memoryS3 struct {
+	mu        sync.Mutex
+	objects   map[string][]byte
+	tagging   map[string]string
+	failPutOn string
+}
+
+func newMemoryS3(objects map[string]string) *memoryS3 {
+	m := &memoryS3{objects: make(map[string][]byte), tagging: make(map[string]string)}
+	for k, v := range objects {
+		m.objects[k] = []byte(v)
+	}
+	return m
+}
+
+func (m *memoryS3) keys() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	keys := make([]string, 0, len(m.objects))
+	for k := range m.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (m *memoryS3) ListObjectsV2(_ context.Context, in *s3.ListObjectsV2Input, _ ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := &s3.ListObjectsV2Output{}
+	for k, v := range m.objects {
+		if strings.HasPrefix(k, aws.ToString(in.Bucket)+"/"+aws.ToString(in.Prefix)) {
+			out.Contents = append(out.Contents, s3types.Object{
+				Key:  aws.String(strings.TrimPrefix(k, aws.ToString(in.Bucket)+"/")),
+				Size: aws.Int64(int64(len(v))),
+			})
+		}
+	}
+	return out, nil
+}
+
+func (m *memoryS3) GetObject(_ context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key)]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (m *memoryS3) PutObject(_ context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	if m.failPutOn != "" && strings.HasSuffix(aws.ToString(in.Key), m.failPutOn) {
+		return nil, errors.New("put failed")
+	}
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key)] = data
+	m.tagging[aws.ToString(in.Bucket)+"/"+aws.ToString(in.Key)] = aws.ToString(in.Tagging)
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (m *memoryS3) DeleteObjects(_ context.Context, in *s3.DeleteObjectsInput, _ ...func(*s3.Options)) (*s3.DeleteObjectsOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, obj := range in.Delete.Objects {
+		delete(m.objects, aws.ToString(in.Bucket)+"/"+aws.ToString(obj.Key))
+	}
+	return &s3.DeleteObjectsOutput{}, nil
+}
+
+func TestConsolidatorHourPrefix(t *testing.T) {
+	t.Parallel()
+
+	hour := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		format     string
+		listPrefix string
+		dir        string
+	}{
+		{
+			format:     "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			listPrefix: "telemetry/year=2024/month=01/day=10/hour=10/minute=",
+			dir:        "telemetry/year=2024/month=01/day=10/hour=10/",
+		},
+		{
+			format:     "%Y/%m/%d/%H/%M",
+			listPrefix: "telemetry/2024/01/10/10/",
+			dir:        "telemetry/2024/01/10/10/",
+		},
+		{
+			format:     "%Y/%m/%d/%H",
+			listPrefix: "telemetry/2024/01/10/10/",
+			dir:        "telemetry/2024/01/10/10/",
+		},
+	} {
+		t.Run(tc.format, func(t *testing.T) {
+			t.Parallel()
+
+			c := NewConsolidator("my-bucket", &PartitionKeyBuilder{
+				PartitionPrefix: "telemetry",
+				PartitionFormat: tc.format,
+			}, newMemoryS3(nil), "", 0, zap.NewNop())
+
+			listPrefix, dir := c.hourPrefix(hour, "")
+			assert.Equal(t, tc.listPrefix, listPrefix)
+			assert.Equal(t, tc.dir, dir)
+		})
+	}
+}
+
+func TestConsolidatorConsolidatePending(t *testing.T) {
+	t.Parallel()
+
+	const dir = "my-bucket/telemetry/year=2024/month=01/day=10/hour=10/"
+	const target = dir + "signal-data-logs_consolidated_2024011010.json"
+	hour := time.Date(2024, 1, 10, 10, 0, 0, 0, time.UTC)
+
+	newConsolidator := func(store *memoryS3, compression configcompression.Type, opts ...ConsolidatorOpt) *Consolidator {
+		return NewConsolidator("my-bucket", &PartitionKeyBuilder{
+			PartitionPrefix: "telemetry",
+			PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			FilePrefix:      "signal-data-",
+			Metadata:        "logs",
+			FileFormat:      "json",
+			Compression:     compression,
+		}, store, "STANDARD", 0, zap.NewNop(), append([]ConsolidatorOpt{WithLineDelimitedParts()}, opts...)...)
+	}
+
+	t.Run("merges parts and deletes them", func(t *testing.T) {
+		t.Parallel()
+
+		store := newMemoryS3(map[string]string{
+			dir + "minute=01/signal-data-logs_1.json":  `{"a":1}`,
+			dir + "minute=30/signal-data-logs_2.json":  `{"a":2}`,
+			dir + "minute=30/signal-data-trace_3.json": `{"other":3}`,
+		})
+		c := newConsolidator(store, "")
+		c.Track("", "", hour.Add(10*time.Minute))
+
+		require.NoError(t, c.ConsolidatePending(context.Background(), hour.Add(time.Hour)))
+		assert.Equal(t, []string{
+			dir + "minute=30/signal-data-trace_3.json",
+			target,
+		}, store.keys())
+		assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n", string(store.objects[target]))
+	})
+
+	t.Run("merges the framed parts as they are", func(t *testing.T) {
+		t.Parallel()
+
+		// length prefixed records, a newline in between would be read as the size of a record
+		first, second := "\x07{\"a\":1}", "\x07{\"a\":2}"
+		store := newMemoryS3(map[string]string{
+			dir + "minute=01/signal-data-logs_1.json": first,
+			dir + "minute=30/signal-data-logs_2.json": second,
+		})
+		c := NewConsolidator("my-bucket", &PartitionKeyBuilder{
+			PartitionPrefix: "telemetry",
+			PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			FilePrefix:      "signal-data-",
+			Metadata:        "logs",
+			FileFormat:      "json",
+		}, store, "STANDARD", 0, zap.NewNop())
+		c.Track("", "", hour.Add(10*time.Minute))
+
+		require.NoError(t, c.ConsolidatePending(context.Background(), hour.Add(time.Hour)))
+		assert.Equal(t, first+second, string(store.objects[target]))
+	})
+
+	t.Run("tags the consolidated object", func(t *testing.T) {
+		t.Parallel()
+
+		store := newMemoryS3(map[string]string{
+			dir + "minute=01/signal-data-logs_1.json": `{"a":1}`,
+			dir + "minute=30/signal-data-logs_2.json": `{"a":2}`,
+		})
+		c := newConsolidator(store, "", WithConsolidatedTags(map[string]string{"team": "platform"}, nil))
+		c.Track("", "", hour)
+
+		require.NoError(t, c.ConsolidatePending(context.Background(), hour.Add(time.Hour)))
+		assert.Equal(t, []string{target}, store.keys())
+		assert.Equal(t, "team=platform", store.tagging[target])
+	})
+
+	t.Run("waits for the hour to be over", func(t *testing.T) {
+		t.Parallel()
+
+		store := newMemoryS3(map[string]string{
+			dir + "minute=01/signal-data-logs_1.json": `{"a":1}`,
+			dir + "minute=30/signal-data-logs_2.json": `{"a":2}`,
+		})
+		c := newConsolidator(store, "")
+		c.Track("", "", hour.Add(10*time.Minute))
+
+		require.NoError(t, c.ConsolidatePending(context.Background(), hour.Add(59*time.Minute)))
+		assert.Len(t, store.keys(), 2)
+	})
+
+	t.Run("keeps parts when upload fails", func(t *testing.T) {
+		t.Parallel()
+
+		store := newMemoryS3(map[string]string{
+			dir + "minute=01/signal-data-logs_1.json": `{"a":1}`,
+			dir + "minute=30/signal-data-logs_2.json": `{"a":2}`,
+		})
+		store.failPutOn = "_consolidated_2024011010.json"
+		c := newConsolidator(store, "")
+		c.Track("", "", hour.Add(10*time.Minute))
+
+		require.Error(t, c.ConsolidatePending(context.Background(), hour.Add(time.Hour)))
+		assert.Equal(t, []string{
+			dir + "minute=01/signal-data-logs_1.json",
+			dir + "minute=30/signal-data-logs_2.json",
+			target + manifestSuffix,
+		}, store.keys())
+
+		// The hour is retried and the stale manifest is rolled back.
+		store.failPutOn = ""
+		require.NoError(t, c.ConsolidatePending(context.Background(), hour.Add(2*time.Hour)))
+		assert.Equal(t, []string{target}, store.keys())
+		assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n", string(store.objects[target]))
+	})
+
+	t.Run("completes a committed manifest", func(t *testing.T) {
+		t.Parallel()
+
+		store := newMemoryS3(map[string]string{
+			dir + "minute=01/signal-data-logs_1.json": `{"a":1}`,
+			dir + "minute=30/signal-data-logs_2.json": `{"a":2}`,
+			target:                  "{\"a\":1}\n",
+			target + manifestSuffix: `{"target":"telemetry/year=2024/month=01/day=10/hour=10/signal-data-logs_consolidated_2024011010.json","size":8,"parts":["telemetry/year=2024/month=01/day=10/hour=10/minute=01/signal-data-logs_1.json"]}`,
+		})
+		c := newConsolidator(store, "")
+		c.Track("", "", hour.Add(10*time.Minute))
+
+		require.NoError(t, c.ConsolidatePending(context.Background(), hour.Add(time.Hour)))
+		assert.Equal(t, []string{target}, store.keys())
+		assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n", string(store.objects[target]))
+	})
+
+	t.Run("merges compressed parts", func(t *testing.T) {
+		t.Parallel()
+
+		first, err := compress(configcompression.TypeGzip, []byte(`{"a":1}`))
+		require.NoError(t, err)
+		second, err := compress(configcompression.TypeGzip, []byte(`{"a":2}`))
+		require.NoError(t, err)
+
+		store := newMemoryS3(nil)
+		store.objects[dir+"minute=01/signal-data-logs_1.json.gz"] = first
+		store.objects[dir+"minute=02/signal-data-logs_2.json.gz"] = second
+		c := newConsolidator(store, configcompression.TypeGzip)
+		c.Track("", "", hour)
+
+		require.NoError(t, c.ConsolidatePending(context.Background(), hour.Add(time.Hour)))
+		require.Equal(t, []string{target + ".gz"}, store.keys())
+		data, err := gunzip(store.objects[target+".gz"])
+		require.NoError(t, err)
+		assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n", string(data))
+	})
+
+	t.Run("merges parts uploaded below the compression min size", func(t *testing.T) {
+		t.Parallel()
+
+		first, err := compress(configcompression.TypeGzip, []byte(`{"a":1}`))
+		require.NoError(t, err)
+
+		store := newMemoryS3(map[string]string{
+			dir + "minute=02/signal-data-logs_2.json": `{"a":2}`,
+		})
+		store.objects[dir+"minute=01/signal-data-logs_1.json.gz"] = first
+		c := newConsolidator(store, configcompression.TypeGzip)
+		c.Track("", "", hour)
+
+		require.NoError(t, c.ConsolidatePending(context.Background(), hour.Add(time.Hour)))
+		require.Equal(t, []string{target + ".gz"}, store.keys())
+		data, err := gunzip(store.objects[target+".gz"])
+		require.NoError(t, err)
+		assert.Equal(t, "{\"a\":1}\n{\"a\":2}\n", string(data))
+	})
+}
@@ -0,0 +1,128 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/tilinna/clock"
+)
+
+// Failover uploads to a primary manager, and to a secondary one once the uploads
+// to the primary failed a number of times in a row, e.g. during an outage of its
+// region. While failed over, an upload is tried on the primary again every failback
+// interval, and the uploads go back to the primary once one succeeds.
+type Failover struct {
+	primary   Manager
+	secondary Manager
+	threshold int
+	interval  time.Duration
+	// onChange is called with the error that caused the failover when failing over,
+	// and with nil when failing back.
+	onChange func(err error)
+
+	mu         sync.Mutex
+	failures   int
+	failedOver bool
+	// nextProbe is the time at which the primary is tried again while failed over.
+	nextProbe time.Time
+}
+
+var _ Manager = (*Failover)(nil)
+
+func NewFailover(primary, secondary Manager, threshold int, interval time.Duration, onChange func(err error)) *Failover {
+	return &Failover{
+		primary:   primary,
+		secondary: secondary,
+		threshold: threshold,
+		interval:  interval,
+		onChange:  onChange,
+	}
+}
+
+// Upload uploads data to the primary manager, unless failed over, in which case it
+// is uploaded to the secondary one. The bucket override of opts only applies to the
+// primary, everything is uploaded to the bucket of the secondary.
+func (f *Failover) Upload(ctx context.Context, data []byte, opts *UploadOptions) error {
+	if f.usePrimary(ctx) {
+		err := f.primary.Upload(ctx, data, opts)
+		if !f.primaryFailed(ctx, err) {
+			return err
+		}
+	}
+	var secondaryOpts *UploadOptions
+	if opts != nil {
+		o := *opts
+		o.OverrideBucket = ""
+		secondaryOpts = &o
+	}
+	return f.secondary.Upload(ctx, data, secondaryOpts)
+}
+
+// FailedOver tells whether the uploads go to the secondary manager.
+func (f *Failover) FailedOver() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failedOver
+}
+
+// usePrimary tells whether an upload goes to the primary manager, either because
+// it is not failed over or because it is time to probe it.
+func (f *Failover) usePrimary(ctx context.Context) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.failedOver {
+		return true
+	}
+	now := clock.Now(ctx)
+	if now.Before(f.nextProbe) {
+		return false
+	}
+	// A single upload probes the primary per interval.
+	f.nextProbe = now.Add(f.interval)
+	return true
+}
+
+// primaryFailed records the outcome of an upload to the primary manager, failing
+// over or back as needed, and tells whether the upload has to go to the secondary.
+func (f *Failover) primaryFailed(ctx context.Context, err error) bool {
+	if errors.Is(err, context.Canceled) {
+		// The upload was abandoned, it tells nothing about the primary.
+		return false
+	}
+
+	f.mu.Lock()
+	var changed error
+	failback := false
+	switch {
+	case err == nil:
+		f.failures = 0
+		failback = f.failedOver
+		f.failedOver = false
+	case f.failedOver:
+		// The probe failed, the primary is still unavailable.
+	default:
+		f.failures++
+		if f.failures >= f.threshold {
+			f.failedOver = true
+			f.nextProbe = clock.Now(ctx).Add(f.interval)
+			changed = fmt.Errorf("failed over after %d consecutive upload failures: %w", f.failures, err)
+		}
+	}
+	failedOver := f.failedOver
+	f.mu.Unlock()
+
+	if f.onChange != nil {
+		if changed != nil {
+			f.onChange(changed)
+		} else if failback {
+			f.onChange(nil)
+		}
+	}
+	return failedOver
+}
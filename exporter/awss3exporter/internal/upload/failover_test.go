@@ -0,0 +1,105 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tilinna/clock"
+)
+
+func TestFailover(t *testing.T) {
+	t.Parallel()
+
+	primary, secondary := newRecordingManager(), newRecordingManager()
+	var changes []error
+	f := NewFailover(primary, secondary, 2, time.Minute, func(err error) { changes = append(changes, err) })
+	mc := clock.NewMock(time.Date(2024, 0o1, 10, 10, 30, 40, 100, time.UTC))
+	ctx := clock.Context(context.Background(), mc)
+	opts := &UploadOptions{OverridePrefix: "p"}
+
+	require.NoError(t, f.Upload(ctx, []byte("a"), opts))
+
+	outage := errors.New("service unavailable")
+	primary.failWith(outage)
+	require.ErrorIs(t, f.Upload(ctx, []byte("b"), opts), outage, "below the threshold, the failure is returned")
+	assert.False(t, f.FailedOver())
+	require.NoError(t, f.Upload(ctx, []byte("c"), opts), "the upload reaching the threshold goes to the secondary")
+	assert.True(t, f.FailedOver())
+	require.Len(t, changes, 1)
+	assert.ErrorIs(t, changes[0], outage)
+
+	// The primary is not tried again before the failback interval.
+	primary.failWith(nil)
+	require.NoError(t, f.Upload(ctx, []byte("d"), opts))
+	mc.Add(time.Minute)
+	require.NoError(t, f.Upload(ctx, []byte("e"), opts), "the probe succeeds")
+	assert.False(t, f.FailedOver())
+	require.Len(t, changes, 2)
+	assert.NoError(t, changes[1])
+	require.NoError(t, f.Upload(ctx, []byte("f"), opts))
+
+	assert.Equal(t, map[string]string{"p": "a|e|f|"}, primary.recorded())
+	assert.Equal(t, map[string]string{"p": "c|d|"}, secondary.recorded())
+}
+
+func TestFailoverFailedProbe(t *testing.T) {
+	t.Parallel()
+
+	outage := errors.New("service unavailable")
+	primary, secondary := newRecordingManager(), newRecordingManager()
+	primary.failWith(outage)
+	var changes int
+	f := NewFailover(primary, secondary, 1, time.Minute, func(error) { changes++ })
+	mc := clock.NewMock(time.Date(2024, 0o1, 10, 10, 30, 40, 100, time.UTC))
+	ctx := clock.Context(context.Background(), mc)
+
+	require.NoError(t, f.Upload(ctx, []byte("a"), nil))
+	mc.Add(time.Minute)
+	require.NoError(t, f.Upload(ctx, []byte("b"), nil), "the failed probe is uploaded to the secondary")
+	assert.True(t, f.FailedOver())
+	assert.Equal(t, 1, changes, "a failed probe does not fail over again")
+	assert.Equal(t, map[string]string{"": "a|b|"}, secondary.recorded())
+}
+
+func TestFailoverIgnoresBucketOverride(t *testing.T) {
+	t.Parallel()
+
+	var buckets []string
+	secondary := uploadFunc(func(_ context.Context, _ []byte, opts *UploadOptions) error {
+		buckets = append(buckets, opts.OverrideBucket)
+		return nil
+	})
+	primary := newRecordingManager()
+	primary.failWith(errors.New("service unavailable"))
+	f := NewFailover(primary, secondary, 1, time.Minute, nil)
+
+	opts := &UploadOptions{OverrideBucket: "tenant-bucket"}
+	require.NoError(t, f.Upload(context.Background(), []byte("a"), opts))
+	assert.Equal(t, []string{""}, buckets)
+	assert.Equal(t, "tenant-bucket", opts.OverrideBucket, "the options of the caller are left untouched")
+}
+
+func TestFailoverIgnoresCanceledUploads(t *testing.T) {
+	t.Parallel()
+
+	primary, secondary := newRecordingManager(), newRecordingManager()
+	primary.failWith(context.Canceled)
+	f := NewFailover(primary, secondary, 1, time.Minute, nil)
+
+	require.ErrorIs(t, f.Upload(context.Background(), []byte("a"), nil), context.Canceled)
+	assert.False(t, f.FailedOver())
+	assert.Empty(t, secondary.recorded())
+}
+
+type uploadFunc func(ctx context.Context, data []byte, opts *UploadOptions) error
+
+func (f uploadFunc) Upload(ctx context.Context, data []byte, opts *UploadOptions) error {
+	return f(ctx, data, opts)
+}
@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"go.uber.org/zap"
+)
+
+// Notification describes an object uploaded by the manager, so that downstream
+// loaders can process it without polling the bucket.
+type Notification struct {
+	Bucket string `json:"bucket"`
+	Key    string `json:"key"`
+	// Records is the number of records, spans, data points or log records, in the object.
+	Records int `json:"records"`
+	// Size is the size of the object, in bytes, as stored.
+	Size int `json:"size"`
+}
+
+// Notifier publishes the notification of an uploaded object.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+type sqsAPI interface {
+	SendMessage(ctx context.Context, params *sqs.SendMessageInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageOutput, error)
+}
+
+type snsAPI interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+type sqsNotifier struct {
+	client   sqsAPI
+	queueURL string
+}
+
+// NewSQSNotifier returns a notifier sending the notifications as JSON messages
+// to the SQS queue at queueURL.
+func NewSQSNotifier(client sqsAPI, queueURL string) Notifier {
+	return &sqsNotifier{client: client, queueURL: queueURL}
+}
+
+func (n *sqsNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	_, err = n.client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(n.queueURL),
+		MessageBody: aws.String(string(body)),
+	})
+	return err
+}
+
+type snsNotifier struct {
+	client   snsAPI
+	topicARN string
+}
+
+// NewSNSNotifier returns a notifier publishing the notifications as JSON messages
+// to the SNS topic topicARN.
+func NewSNSNotifier(client snsAPI, topicARN string) Notifier {
+	return &snsNotifier{client: client, topicARN: topicARN}
+}
+
+func (n *snsNotifier) Notify(ctx context.Context, notification Notification) error {
+	body, err := json.Marshal(notification)
+	if err != nil {
+		return err
+	}
+	_, err = n.client.Publish(ctx, &sns.PublishInput{
+		TopicArn: aws.String(n.topicARN),
+		Message:  aws.String(string(body)),
+	})
+	return err
+}
+
+// WithNotifier publishes a notification with notifier after every successful
+// upload. Notifications that fail are logged and dropped rather than failing
+// the upload, which would be retried and duplicate the object.
+func WithNotifier(notifier Notifier, logger *zap.Logger) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.notifier = notifier
+		s3m.logger = logger
+	}
+}
+
+func (sw *s3manager) notify(ctx context.Context, n Notification) {
+	if sw.notifier == nil {
+		return
+	}
+	if err := sw.notifier.Notify(ctx, n); err != nil {
+		sw.logger.Warn("Failed to publish the upload notification",
+			zap.String("bucket", n.Bucket),
+			zap.String("key", n.Key),
+			zap.Error(err))
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tilinna/clock"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakeSQS struct {
+	input *sqs.SendMessageInput
+}
+
+func (f *fakeSQS) SendMessage(_ context.Context, params *sqs.SendMessageInput, _ ...func(*sqs.Options)) (*sqs.SendMessageOutput, error) {
+	f.input = params
+	return &sqs.SendMessageOutput{}, nil
+}
+
+type fakeSNS struct {
+	input *sns.PublishInput
+}
+
+func (f *fakeSNS) Publish(_ context.Context, params *sns.PublishInput, _ ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	f.input = params
+	return &sns.PublishOutput{}, nil
+}
+
+type recordingNotifier struct {
+	notifications []Notification
+	err           error
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, n Notification) error {
+	r.notifications = append(r.notifications, n)
+	return r.err
+}
+
+func TestNotifiers(t *testing.T) {
+	t.Parallel()
+
+	n := Notification{Bucket: "my-bucket", Key: "logs/file.json.gz", Records: 3, Size: 42}
+	expected := `{"bucket":"my-bucket","key":"logs/file.json.gz","records":3,"size":42}`
+
+	t.Run("sqs", func(t *testing.T) {
+		t.Parallel()
+
+		client := &fakeSQS{}
+		require.NoError(t, NewSQSNotifier(client, "https://sqs.local/uploads").Notify(context.Background(), n))
+		assert.Equal(t, "https://sqs.local/uploads", aws.ToString(client.input.QueueUrl))
+		assert.JSONEq(t, expected, aws.ToString(client.input.MessageBody))
+	})
+
+	t.Run("sns", func(t *testing.T) {
+		t.Parallel()
+
+		client := &fakeSNS{}
+		require.NoError(t, NewSNSNotifier(client, "arn:aws:sns:local:0:uploads").Notify(context.Background(), n))
+		assert.Equal(t, "arn:aws:sns:local:0:uploads", aws.ToString(client.input.TopicArn))
+		assert.JSONEq(t, expected, aws.ToString(client.input.Message))
+	})
+}
+
+func TestS3ManagerNotification(t *testing.T) {
+	t.Parallel()
+
+	newManager := func(t *testing.T, status int, notifier Notifier, logger *zap.Logger) Manager {
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			_ = r.Body.Close()
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(s.Close)
+		return NewS3Manager(
+			"my-bucket",
+			&PartitionKeyBuilder{
+				PartitionPrefix: "telemetry",
+				PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+				FilePrefix:      "signal-data-",
+				Metadata:        "noop",
+				FileFormat:      "json",
+				Compression:     configcompression.TypeGzip,
+				UniqueKeyFunc: func() string {
+					return "random"
+				},
+			},
+			s3.New(s3.Options{
+				BaseEndpoint: aws.String(s.URL),
+				Region:       "local",
+				UsePathStyle: true,
+			}),
+			"STANDARD",
+			WithNotifier(notifier, logger),
+		)
+	}
+	ctx := clock.Context(context.Background(), clock.NewMock(time.Date(2024, 0o1, 10, 10, 30, 40, 100, time.UTC)))
+
+	t.Run("notifies the uploaded object", func(t *testing.T) {
+		t.Parallel()
+
+		notifier := &recordingNotifier{}
+		m := newManager(t, http.StatusOK, notifier, zap.NewNop())
+		require.NoError(t, m.Upload(ctx, []byte("hello world"), &UploadOptions{OverrideBucket: "other-bucket", Records: 2}))
+
+		require.Len(t, notifier.notifications, 1)
+		n := notifier.notifications[0]
+		assert.Equal(t, "other-bucket", n.Bucket)
+		assert.Equal(t, "telemetry/year=2024/month=01/day=10/hour=10/minute=30/signal-data-noop_random.json.gz", n.Key)
+		assert.Equal(t, 2, n.Records)
+		assert.Positive(t, n.Size)
+	})
+
+	t.Run("does not notify failed uploads", func(t *testing.T) {
+		t.Parallel()
+
+		notifier := &recordingNotifier{}
+		m := newManager(t, http.StatusForbidden, notifier, zap.NewNop())
+		require.Error(t, m.Upload(ctx, []byte("hello world"), nil))
+		assert.Empty(t, notifier.notifications)
+	})
+
+	t.Run("does not fail the upload when the notification fails", func(t *testing.T) {
+		t.Parallel()
+
+		core, logs := observer.New(zap.WarnLevel)
+		notifier := &recordingNotifier{err: errors.New("throttled")}
+		m := newManager(t, http.StatusOK, notifier, zap.New(core))
+		require.NoError(t, m.Upload(ctx, []byte("hello world"), nil))
+		assert.Len(t, notifier.notifications, 1)
+		assert.Equal(t, 1, logs.FilterMessage("Failed to publish the upload notification").Len())
+	})
+}
@@ -4,8 +4,10 @@
 package upload // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
 
 import (
+	"fmt"
 	"math/rand/v2"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -41,10 +43,22 @@ type PartitionKeyBuilder struct {
 	// generating a new unique string to avoid collisions on file upload
 	// across many different instances.
 	UniqueKeyFunc func() string
+	// ObjectKeyFunc, when set, generates the unique string from the prefix of the
+	// key and the content of the object instead of UniqueKeyFunc, e.g. so that the
+	// re-exports of the same data write the same object.
+	ObjectKeyFunc func(keyPrefix string, data []byte) string
 }
 
 func (pki *PartitionKeyBuilder) Build(ts time.Time, overridePrefix string) string {
-	return pki.bucketKeyPrefix(ts, overridePrefix) + "/" + pki.fileName()
+	return pki.build(ts, overridePrefix, pki.Compression, nil)
+}
+
+// build returns the key of the object of data written with the given
+// compression, which may differ from the configured one for payloads too small
+// to be compressed.
+func (pki *PartitionKeyBuilder) build(ts time.Time, overridePrefix string, compression configcompression.Type, data []byte) string {
+	prefix := pki.bucketKeyPrefix(ts, overridePrefix)
+	return prefix + "/" + pki.fileName(compression, prefix, data)
 }
 
 func (pki *PartitionKeyBuilder) bucketKeyPrefix(ts time.Time, overridePrefix string) string {
@@ -60,24 +74,33 @@ func (pki *PartitionKeyBuilder) bucketKeyPrefix(ts time.Time, overridePrefix str
 	return prefix + timefmt.Format(ts, pki.PartitionFormat)
 }
 
-func (pki *PartitionKeyBuilder) fileName() string {
+func (pki *PartitionKeyBuilder) fileName(compression configcompression.Type, keyPrefix string, data []byte) string {
+	return pki.FilePrefix + pki.Metadata + "_" + pki.uniqueKey(keyPrefix, data) + pki.suffix(compression)
+}
+
+func (pki *PartitionKeyBuilder) suffix(compression configcompression.Type) string {
 	var suffix string
 
 	if pki.FileFormat != "" {
 		suffix = "." + pki.FileFormat
 	}
 
-	if ext, ok := compressionFileExtensions[pki.Compression]; ok {
+	if ext, ok := compressionFileExtensions[compression]; ok {
 		suffix += ext
 	}
 
-	return pki.FilePrefix + pki.Metadata + "_" + pki.uniqueKey() + suffix
+	return suffix
 }
 
-func (pki *PartitionKeyBuilder) uniqueKey() string {
+func (pki *PartitionKeyBuilder) uniqueKey(keyPrefix string, data []byte) string {
 	// If a custom function is provided, use it to generate the unique key.
 	// If it fails, fall back to the default random integer generation
 	// so that uploads are not blocked.
+	if pki.ObjectKeyFunc != nil {
+		if k := pki.ObjectKeyFunc(keyPrefix, data); k != "" {
+			return k
+		}
+	}
 	if pki.UniqueKeyFunc != nil {
 		if k := pki.UniqueKeyFunc(); k != "" {
 			return k
@@ -95,6 +118,57 @@ func GenerateUUIDv7() string {
 	return id.String()
 }
 
+// contentKeyNamespace is the namespace of the UUIDv5 generated from the content
+// of the objects.
+var contentKeyNamespace = uuid.NewSHA1(uuid.NameSpaceURL, []byte("https://github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"))
+
+// GenerateUUIDv5 returns the UUIDv5 of the content of an object, the same for
+// identical data so that exporting them again overwrites the same object.
+func GenerateUUIDv5(_ string, data []byte) string {
+	return uuid.NewSHA1(contentKeyNamespace, data).String()
+}
+
+// SequenceKeys generates a monotonic sequence number for each key prefix.
+// The sequence of a prefix continues from the Unix time in milliseconds when it
+// is behind it, so that it keeps increasing across restarts of the collector
+// as long as less than a thousand objects per second are written to the prefix.
+type SequenceKeys struct {
+	mu   sync.Mutex
+	last map[string]int64
+	now  func() time.Time
+}
+
+// NewSequenceKeys returns the sequence numbers of the key prefixes.
+func NewSequenceKeys() *SequenceKeys {
+	return &SequenceKeys{
+		last: make(map[string]int64),
+		now:  time.Now,
+	}
+}
+
+// maxIdleSequences is the number of sequences above which the sequences behind
+// the current time, which restart from it, are forgotten.
+const maxIdleSequences = 128
+
+// Next returns the next sequence number of keyPrefix, zero padded so that the
+// keys sort in the order of the sequence.
+func (s *SequenceKeys) Next(keyPrefix string, _ []byte) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now().UnixMilli()
+	next := max(s.last[keyPrefix]+1, now)
+	if len(s.last) > maxIdleSequences {
+		for prefix, last := range s.last {
+			if last < now {
+				delete(s.last, prefix)
+			}
+		}
+	}
+	s.last[keyPrefix] = next
+	return fmt.Sprintf("%019d", next)
+}
+
 func (*PartitionKeyBuilder) randInt() string {
 	// This follows the original "uniqueness" algorithm
 	// to avoid collisions on file uploads across different nodes.
@@ -4,6 +4,7 @@
 package upload
 
 import (
+	"strconv"
 	"testing"
 	"time"
 
@@ -211,7 +212,7 @@ func TestPartitionKeyInputsFilename(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			assert.Equal(t, tc.expect, tc.inputs.fileName(), "Must match the expected value")
+			assert.Equal(t, tc.expect, tc.inputs.fileName(tc.inputs.Compression, "", nil), "Must match the expected value")
 		})
 	}
 }
@@ -224,7 +225,7 @@ func TestPartitionKeyInputsUniqueKey(t *testing.T) {
 
 	seen := make(map[string]struct{})
 	for i := 0; i < 500; i++ {
-		uv := (&PartitionKeyBuilder{}).uniqueKey()
+		uv := (&PartitionKeyBuilder{}).uniqueKey("", nil)
 		_, ok := seen[uv]
 		assert.False(t, ok, "Must not have repeated partition key %q", uv)
 		seen[uv] = struct{}{}
@@ -235,7 +236,7 @@ func TestPartitionKeyInputsUniqueKey(t *testing.T) {
 	seen = make(map[string]struct{})
 	lastKey := ""
 	for i := 0; i < 500; i++ {
-		uv := (&PartitionKeyBuilder{UniqueKeyFunc: GenerateUUIDv7}).uniqueKey()
+		uv := (&PartitionKeyBuilder{UniqueKeyFunc: GenerateUUIDv7}).uniqueKey("", nil)
 		_, ok := seen[uv]
 		assert.False(t, ok, "Must not have repeated partition key %q", uv)
 		seen[uv] = struct{}{}
@@ -270,7 +271,48 @@ func TestPartitionKeyInputsUniqueKey(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			assert.Regexp(t, tc.match, tc.inputs.fileName(), "Must match the expected regex pattern")
+			assert.Regexp(t, tc.match, tc.inputs.fileName(tc.inputs.Compression, "", nil), "Must match the expected regex pattern")
 		})
 	}
 }
+
+func TestGenerateUUIDv5(t *testing.T) {
+	t.Parallel()
+
+	key := GenerateUUIDv5("telemetry/year=2024", []byte("hello world"))
+	assert.Regexp(t, "^[0-9a-f]{8}-[0-9a-f]{4}-5[0-9a-f]{3}-[0-9a-f]{4}-[0-9a-f]{12}$", key)
+	assert.Equal(t, key, GenerateUUIDv5("telemetry/year=2025", []byte("hello world")), "identical data must have the same key")
+	assert.NotEqual(t, key, GenerateUUIDv5("telemetry/year=2024", []byte("hello world!")))
+
+	builder := &PartitionKeyBuilder{
+		PartitionFormat: "year=%Y",
+		Metadata:        "logs",
+		FileFormat:      "json",
+		ObjectKeyFunc:   GenerateUUIDv5,
+	}
+	ts := time.Date(2024, 0o1, 10, 10, 30, 40, 100, time.UTC)
+	assert.Equal(t, "year=2024/logs_"+key+".json", builder.build(ts, "", "", []byte("hello world")))
+}
+
+func TestSequenceKeys(t *testing.T) {
+	t.Parallel()
+
+	now := time.UnixMilli(1704882640000)
+	keys := NewSequenceKeys()
+	keys.now = func() time.Time { return now }
+
+	assert.Equal(t, "0000001704882640000", keys.Next("a", nil))
+	assert.Equal(t, "0000001704882640001", keys.Next("a", nil))
+	assert.Equal(t, "0000001704882640000", keys.Next("b", nil), "every prefix has a sequence of its own")
+	assert.Equal(t, "0000001704882640002", keys.Next("a", nil))
+
+	now = now.Add(time.Second)
+	assert.Equal(t, "0000001704882641000", keys.Next("a", nil), "the sequence catches up with the time")
+
+	for i := 0; i <= maxIdleSequences; i++ {
+		keys.Next(strconv.Itoa(i), nil)
+	}
+	now = now.Add(time.Second)
+	keys.Next("a", nil)
+	assert.Len(t, keys.last, 1, "the sequences behind the time are forgotten")
+}
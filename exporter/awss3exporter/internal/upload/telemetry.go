@@ -0,0 +1,136 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	"github.com/tilinna/clock"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/metadata"
+)
+
+const (
+	errorClassThrottle     = "throttle"
+	errorClassAccessDenied = "access_denied"
+	errorClassNotFound     = "not_found"
+	errorClassTimeout      = "timeout"
+	errorClassOther        = "other"
+)
+
+var (
+	successAttrs = metric.WithAttributeSet(attribute.NewSet(attribute.String("outcome", "success")))
+	failureAttrs = metric.WithAttributeSet(attribute.NewSet(attribute.String("outcome", "failure")))
+)
+
+// WithTelemetry reports the duration, size, retries, throttles and failures of
+// the uploads with the instruments of telemetry.
+func WithTelemetry(telemetry *metadata.TelemetryBuilder) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.telemetry = telemetry
+		s3m.uploader.ClientOptions = append(s3m.uploader.ClientOptions, func(o *s3.Options) {
+			o.Retryer = &countingRetryer{
+				Retryer:   o.Retryer,
+				retries:   telemetry.Awss3UploadRetries,
+				throttles: telemetry.Awss3UploadThrottles,
+			}
+		})
+	}
+}
+
+// record reports an upload that started at start and failed with err, if any.
+func (sw *s3manager) record(ctx context.Context, start time.Time, size int, err error) {
+	if sw.telemetry == nil {
+		return
+	}
+	duration := clock.Since(ctx, start).Seconds()
+	if err != nil {
+		class := errorClass(err)
+		if class == errorClassThrottle {
+			// The retried throttled requests are counted by the retryer.
+			sw.telemetry.Awss3UploadThrottles.Add(ctx, 1)
+		}
+		sw.telemetry.Awss3UploadDuration.Record(ctx, duration, failureAttrs)
+		sw.telemetry.Awss3UploadFailures.Add(ctx, 1, metric.WithAttributes(attribute.String("error_class", class)))
+		return
+	}
+	sw.telemetry.Awss3UploadDuration.Record(ctx, duration, successAttrs)
+	sw.telemetry.Awss3UploadSize.Record(ctx, int64(size))
+}
+
+// errorClass classifies the error of an upload, so that throttling, which
+// resolves itself, can be told apart from misconfigured permissions.
+func errorClass(err error) string {
+	if retry.IsErrorThrottles(retry.DefaultThrottles).IsErrorThrottle(err).Bool() {
+		return errorClassThrottle
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "AccessDenied", "AllAccessDisabled", "InvalidAccessKeyId", "SignatureDoesNotMatch", "ExpiredToken", "InvalidToken":
+			return errorClassAccessDenied
+		case "NoSuchBucket":
+			return errorClassNotFound
+		}
+	}
+	var respErr *awshttp.ResponseError
+	if errors.As(err, &respErr) {
+		switch respErr.HTTPStatusCode() {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return errorClassThrottle
+		case http.StatusUnauthorized, http.StatusForbidden:
+			return errorClassAccessDenied
+		case http.StatusNotFound:
+			return errorClassNotFound
+		}
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return errorClassTimeout
+	}
+	return errorClassOther
+}
+
+// countingRetryer counts the retries of the requests allowed by the retryer it
+// wraps, and those of the throttled requests among them.
+type countingRetryer struct {
+	aws.Retryer
+	retries   metric.Int64Counter
+	throttles metric.Int64Counter
+}
+
+var _ aws.RetryerV2 = (*countingRetryer)(nil)
+
+func (r *countingRetryer) GetAttemptToken(ctx context.Context) (func(error) error, error) {
+	if v2, ok := r.Retryer.(aws.RetryerV2); ok {
+		return v2.GetAttemptToken(ctx)
+	}
+	return r.GetInitialToken(), nil
+}
+
+func (r *countingRetryer) GetRetryToken(ctx context.Context, opErr error) (func(error) error, error) {
+	release, err := r.Retryer.GetRetryToken(ctx, opErr)
+	if err == nil {
+		r.retries.Add(ctx, 1)
+		if errorClass(opErr) == errorClassThrottle {
+			r.throttles.Add(ctx, 1)
+		}
+	}
+	return release, err
+}
@@ -0,0 +1,150 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tilinna/clock"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/metadatatest"
+)
+
+func TestErrorClass(t *testing.T) {
+	t.Parallel()
+
+	responseError := func(status int) error {
+		return &awshttp.ResponseError{ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: status}},
+			Err:      errors.New("failed"),
+		}}
+	}
+	for _, tc := range []struct {
+		err      error
+		expected string
+	}{
+		{err: &smithy.GenericAPIError{Code: "SlowDown"}, expected: "throttle"},
+		{err: &smithy.GenericAPIError{Code: "AccessDenied"}, expected: "access_denied"},
+		{err: &smithy.GenericAPIError{Code: "NoSuchBucket"}, expected: "not_found"},
+		{err: responseError(http.StatusServiceUnavailable), expected: "throttle"},
+		{err: responseError(http.StatusForbidden), expected: "access_denied"},
+		{err: fmt.Errorf("upload failed: %w", context.DeadlineExceeded), expected: "timeout"},
+		{err: errors.New("connection reset"), expected: "other"},
+	} {
+		assert.Equal(t, tc.expected, errorClass(tc.err), tc.err.Error())
+	}
+}
+
+func TestS3ManagerTelemetry(t *testing.T) {
+	t.Parallel()
+
+	newManager := func(t *testing.T, handler http.HandlerFunc, telemetry *metadata.TelemetryBuilder) Manager {
+		s := httptest.NewServer(handler)
+		t.Cleanup(s.Close)
+		return NewS3Manager(
+			"my-bucket",
+			&PartitionKeyBuilder{
+				PartitionPrefix: "telemetry",
+				PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+				FilePrefix:      "signal-data-",
+				Metadata:        "noop",
+				FileFormat:      "json",
+				Compression:     configcompression.TypeGzip,
+			},
+			s3.New(s3.Options{
+				BaseEndpoint: aws.String(s.URL),
+				Region:       "local",
+				UsePathStyle: true,
+				Retryer: retry.NewStandard(func(o *retry.StandardOptions) {
+					o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+				}),
+			}),
+			"STANDARD",
+			WithTelemetry(telemetry),
+		)
+	}
+	ctx := clock.Context(context.Background(), clock.NewMock(time.Date(2024, 0o1, 10, 10, 30, 40, 100, time.UTC)))
+
+	t.Run("records retried uploads", func(t *testing.T) {
+		t.Parallel()
+
+		tt := componenttest.NewTelemetry()
+		t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+		telemetry, err := metadata.NewTelemetryBuilder(tt.NewTelemetrySettings())
+		require.NoError(t, err)
+		defer telemetry.Shutdown()
+
+		var requests atomic.Int32
+		m := newManager(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			if requests.Add(1) <= 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				_, _ = io.WriteString(w, "<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>")
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}, telemetry)
+		require.NoError(t, m.Upload(ctx, []byte("hello world"), nil))
+
+		metadatatest.AssertEqualAwss3UploadRetries(t, tt, []metricdata.DataPoint[int64]{{Value: 2}}, metricdatatest.IgnoreTimestamp())
+		metadatatest.AssertEqualAwss3UploadThrottles(t, tt, []metricdata.DataPoint[int64]{{Value: 2}}, metricdatatest.IgnoreTimestamp())
+		metadatatest.AssertEqualAwss3UploadDuration(t, tt, []metricdata.HistogramDataPoint[float64]{
+			{Attributes: attribute.NewSet(attribute.String("outcome", "success"))},
+		}, metricdatatest.IgnoreTimestamp(), metricdatatest.IgnoreValue())
+		metadatatest.AssertEqualAwss3UploadSize(t, tt, []metricdata.HistogramDataPoint[int64]{{}},
+			metricdatatest.IgnoreTimestamp(), metricdatatest.IgnoreValue())
+		_, err = tt.GetMetric("otelcol_awss3_upload_failures")
+		require.Error(t, err, "no upload failed")
+	})
+
+	t.Run("records failed uploads by error class", func(t *testing.T) {
+		t.Parallel()
+
+		tt := componenttest.NewTelemetry()
+		t.Cleanup(func() { require.NoError(t, tt.Shutdown(context.Background())) })
+		telemetry, err := metadata.NewTelemetryBuilder(tt.NewTelemetrySettings())
+		require.NoError(t, err)
+		defer telemetry.Shutdown()
+
+		m := newManager(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = io.WriteString(w, "<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>")
+		}, telemetry)
+		require.Error(t, m.Upload(ctx, []byte("hello world"), nil))
+
+		metadatatest.AssertEqualAwss3UploadFailures(t, tt, []metricdata.DataPoint[int64]{
+			{Attributes: attribute.NewSet(attribute.String("error_class", "access_denied")), Value: 1},
+		}, metricdatatest.IgnoreTimestamp())
+		metadatatest.AssertEqualAwss3UploadDuration(t, tt, []metricdata.HistogramDataPoint[float64]{
+			{Attributes: attribute.NewSet(attribute.String("outcome", "failure"))},
+		}, metricdatatest.IgnoreTimestamp(), metricdatatest.IgnoreValue())
+		_, err = tt.GetMetric("otelcol_awss3_upload_retries")
+		require.Error(t, err, "access denied is not retried")
+		_, err = tt.GetMetric("otelcol_awss3_upload_throttles")
+		require.Error(t, err, "access denied is not throttled")
+	})
+}
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/tilinna/clock"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// WithThrottleSurfacing stops the S3 client from retrying the throttled requests,
+// the uploads failing at once with an error carrying the delay of the Retry-After
+// header of the response, if any, for the sending queue to retry them later.
+func WithThrottleSurfacing() func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.surfaceThrottling = true
+		s3m.uploader.ClientOptions = append(s3m.uploader.ClientOptions, func(o *s3.Options) {
+			o.Retryer = &throttleSurfacingRetryer{Retryer: o.Retryer}
+		})
+	}
+}
+
+// surfaceThrottle returns err as a throttle error of the sending queue, delayed
+// by the Retry-After of the response, when the request was throttled.
+func surfaceThrottle(ctx context.Context, err error) error {
+	if errorClass(err) != errorClassThrottle {
+		return err
+	}
+	return exporterhelper.NewThrottleRetry(err, retryAfter(ctx, err))
+}
+
+// retryAfter returns the delay of the Retry-After header of the response of err,
+// given either in seconds or as a date, zero when there is none.
+func retryAfter(ctx context.Context, err error) time.Duration {
+	var respErr *awshttp.ResponseError
+	if !errors.As(err, &respErr) || respErr.Response == nil || respErr.Response.Response == nil {
+		return 0
+	}
+	header := respErr.Response.Header.Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+	if date, err := http.ParseTime(header); err == nil {
+		return max(date.Sub(clock.Now(ctx)), 0)
+	}
+	return 0
+}
+
+// throttleSurfacingRetryer does not retry the throttled requests, retrying the
+// others as the retryer it wraps.
+type throttleSurfacingRetryer struct {
+	aws.Retryer
+}
+
+var _ aws.RetryerV2 = (*throttleSurfacingRetryer)(nil)
+
+func (r *throttleSurfacingRetryer) IsErrorRetryable(err error) bool {
+	if errorClass(err) == errorClassThrottle {
+		return false
+	}
+	return r.Retryer.IsErrorRetryable(err)
+}
+
+func (r *throttleSurfacingRetryer) GetAttemptToken(ctx context.Context) (func(error) error, error) {
+	if v2, ok := r.Retryer.(aws.RetryerV2); ok {
+		return v2.GetAttemptToken(ctx)
+	}
+	return r.GetInitialToken(), nil
+}
@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package upload
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tilinna/clock"
+	"go.opentelemetry.io/collector/config/configcompression"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+)
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 0o1, 10, 10, 30, 40, 0, time.UTC)
+	ctx := clock.Context(context.Background(), clock.NewMock(now))
+	responseError := func(retryAfter string) error {
+		header := http.Header{}
+		if retryAfter != "" {
+			header.Set("Retry-After", retryAfter)
+		}
+		return &awshttp.ResponseError{ResponseError: &smithyhttp.ResponseError{
+			Response: &smithyhttp.Response{Response: &http.Response{StatusCode: http.StatusServiceUnavailable, Header: header}},
+			Err:      errors.New("slow down"),
+		}}
+	}
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected time.Duration
+	}{
+		{name: "seconds", err: responseError("7"), expected: 7 * time.Second},
+		{name: "date", err: responseError(now.Add(time.Minute).Format(http.TimeFormat)), expected: time.Minute},
+		{name: "past date", err: responseError(now.Add(-time.Minute).Format(http.TimeFormat)), expected: 0},
+		{name: "invalid", err: responseError("soon"), expected: 0},
+		{name: "no header", err: responseError(""), expected: 0},
+		{name: "no response", err: errors.New("connection reset"), expected: 0},
+	} {
+		assert.Equal(t, tc.expected, retryAfter(ctx, tc.err), tc.name)
+	}
+}
+
+func TestS3ManagerThrottleSurfacing(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		requests.Add(1)
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = io.WriteString(w, "<Error><Code>SlowDown</Code><Message>Please reduce your request rate.</Message></Error>")
+	}))
+	t.Cleanup(s.Close)
+
+	m := NewS3Manager(
+		"my-bucket",
+		&PartitionKeyBuilder{
+			PartitionPrefix: "throttle",
+			PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			FilePrefix:      "signal-data-",
+			Metadata:        "noop",
+			FileFormat:      "json",
+			Compression:     configcompression.TypeGzip,
+		},
+		s3.New(s3.Options{
+			BaseEndpoint: aws.String(s.URL),
+			Region:       "local",
+			UsePathStyle: true,
+			Retryer: retry.NewStandard(func(o *retry.StandardOptions) {
+				o.Backoff = retry.BackoffDelayerFunc(func(int, error) (time.Duration, error) { return 0, nil })
+			}),
+		}),
+		"STANDARD",
+		WithThrottleSurfacing(),
+	)
+
+	err := m.Upload(context.Background(), []byte("hello world"), nil)
+	require.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load(), "the throttled request must not be retried by the client")
+	assert.False(t, consumererror.IsPermanent(err))
+	assert.ErrorContains(t, err, "Throttle (3s)")
+}
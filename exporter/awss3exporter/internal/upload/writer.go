@@ -7,6 +7,14 @@ import (
 	"bytes"
 	"compress/gzip"
 	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
@@ -14,6 +22,9 @@ import (
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/tilinna/clock"
 	"go.opentelemetry.io/collector/config/configcompression"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/metadata"
 )
 
 type Manager interface {
@@ -22,17 +33,62 @@ type Manager interface {
 
 type ManagerOpt func(Manager)
 
+// compressionMetadataKey is the object metadata key holding the compression
+// applied to an object when compression can be skipped for small payloads.
+const compressionMetadataKey = "compression"
+
 type UploadOptions struct {
 	OverrideBucket string
 	OverridePrefix string
+	// PartitionTime is the time the partition of the object is derived from, the
+	// time of the upload when zero.
+	PartitionTime time.Time
+	// EncryptionContext is the SSE-KMS encryption context used for the object.
+	EncryptionContext map[string]string
+	// Tags are the tags of the object, on top of the ones of the manager.
+	Tags map[string]string
+	// Metadata is the user metadata of the object, on top of the one of the manager.
+	Metadata map[string]string
+	// Records is the number of records in the uploaded data, reported in the upload
+	// notification. It does not take part in the aggregation of the payloads.
+	Records int `json:"-"`
 }
 
 type s3manager struct {
-	bucket       string
-	builder      *PartitionKeyBuilder
-	uploader     *manager.Uploader
-	storageClass s3types.StorageClass
-	acl          s3types.ObjectCannedACL
+	bucket         string
+	builder        *PartitionKeyBuilder
+	uploader       *manager.Uploader
+	storageClass   s3types.StorageClass
+	acl            s3types.ObjectCannedACL
+	requestPayer   s3types.RequestPayer
+	sse            s3types.ServerSideEncryption
+	kmsKeyID       string
+	bucketKey      bool
+	sseCustomerKey SSECustomerKeyFunc
+	lockMode       s3types.ObjectLockMode
+	lockFor        time.Duration
+	tags           map[string]string
+	metadata       map[string]string
+	observer       func(bucket, prefix string, ts time.Time)
+	contentType    string
+	notifier       Notifier
+	logger         *zap.Logger
+	telemetry      *metadata.TelemetryBuilder
+	// localDir is the directory the objects are written to instead of S3, if any.
+	localDir string
+	// slots limits the number of concurrent uploads to its capacity, unlimited when nil.
+	slots chan struct{}
+	// timeout bounds every upload, retries included, when positive.
+	timeout time.Duration
+	// compressionMinSize is the payload size below which objects are
+	// uploaded uncompressed.
+	compressionMinSize int
+	// precompressed uploads the payloads as is, they are already compressed with
+	// the compression of the builder.
+	precompressed bool
+	// surfaceThrottling returns the throttled uploads as throttle errors of the
+	// sending queue.
+	surfaceThrottling bool
 }
 
 var _ Manager = (*s3manager)(nil)
@@ -58,41 +114,133 @@ func (sw *s3manager) Upload(ctx context.Context, data []byte, opts *UploadOption
 		return nil
 	}
 
-	content, err := sw.contentBuffer(data)
-	if err != nil {
-		return err
+	compression := sw.builder.Compression
+	var objectTags, objectMetadata map[string]string
+	if opts != nil {
+		objectTags, objectMetadata = opts.Tags, opts.Metadata
+	}
+	metadata := mergeAttributes(sw.metadata, objectMetadata)
+	if compression.IsCompressed() && sw.compressionMinSize > 0 {
+		// Record the decision so that readers can tell skipped compression
+		// apart from a misconfigured exporter.
+		if len(data) < sw.compressionMinSize {
+			compression = ""
+		}
+		metadata = mergeAttributes(metadata, map[string]string{compressionMetadataKey: compressionDecision(compression)})
+	}
+
+	content := data
+	var err error
+	if !sw.precompressed {
+		if content, err = compress(compression, data); err != nil {
+			return err
+		}
 	}
 
 	encoding := ""
-	if sw.builder.Compression.IsCompressed() {
-		encoding = string(sw.builder.Compression)
+	if compression.IsCompressed() {
+		encoding = string(compression)
 	}
 
 	now := clock.Now(ctx)
 
 	overridePrefix := ""
 	overrideBucket := sw.bucket
+	var encryptionContext map[string]string
+	records := 0
 	if opts != nil {
 		overridePrefix = opts.OverridePrefix
 		if opts.OverrideBucket != "" {
 			overrideBucket = opts.OverrideBucket
 		}
+		encryptionContext = opts.EncryptionContext
+		records = opts.Records
+	}
+
+	partitionTime := now
+	if opts != nil && !opts.PartitionTime.IsZero() {
+		// The partition is formatted in the location of the time of the upload.
+		partitionTime = opts.PartitionTime.In(now.Location())
 	}
 
-	_, err = sw.uploader.Upload(ctx, &s3.PutObjectInput{
+	key := sw.builder.build(partitionTime, overridePrefix, compression, data)
+	input := &s3.PutObjectInput{
 		Bucket:          aws.String(overrideBucket),
-		Key:             aws.String(sw.builder.Build(now, overridePrefix)),
-		Body:            content,
+		Key:             aws.String(key),
+		Body:            bytes.NewReader(content),
 		ContentEncoding: aws.String(encoding),
 		StorageClass:    sw.storageClass,
 		ACL:             sw.acl,
+		RequestPayer:    sw.requestPayer,
+		Metadata:        metadata,
+		Tagging:         encodeTagging(mergeAttributes(sw.tags, objectTags)),
+	}
+	if sw.contentType != "" {
+		input.ContentType = aws.String(sw.contentType)
+	}
+	if err = applyServerSideEncryption(input, sw.sse, sw.kmsKeyID, sw.bucketKey, encryptionContext); err != nil {
+		return err
+	}
+	if sw.sseCustomerKey != nil {
+		if err = applySSECustomerKey(ctx, input, sw.sseCustomerKey); err != nil {
+			return err
+		}
+	}
+	if sw.lockMode != "" {
+		input.ObjectLockMode = sw.lockMode
+		input.ObjectLockRetainUntilDate = aws.Time(now.Add(sw.lockFor))
+		// S3 requires the integrity of the locked objects to be checked.
+		input.ChecksumAlgorithm = s3types.ChecksumAlgorithmCrc32
+	}
+
+	if sw.slots != nil {
+		select {
+		case sw.slots <- struct{}{}:
+			defer func() { <-sw.slots }()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	start := clock.Now(ctx)
+	if sw.localDir != "" {
+		err = writeLocal(sw.localDir, overrideBucket, key, content)
+	} else {
+		err = sw.put(ctx, input)
+	}
+	sw.record(ctx, start, len(content), err)
+	if err != nil {
+		if sw.surfaceThrottling {
+			return surfaceThrottle(ctx, err)
+		}
+		return err
+	}
+
+	if sw.observer != nil {
+		sw.observer(overrideBucket, overridePrefix, partitionTime)
+	}
+	sw.notify(ctx, Notification{
+		Bucket:  overrideBucket,
+		Key:     key,
+		Records: records,
+		Size:    len(content),
 	})
+	return nil
+}
 
+// put uploads the object of input within the upload timeout, if any.
+func (sw *s3manager) put(ctx context.Context, input *s3.PutObjectInput) error {
+	if sw.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sw.timeout)
+		defer cancel()
+	}
+	_, err := sw.uploader.Upload(ctx, input)
 	return err
 }
 
-func (sw *s3manager) contentBuffer(raw []byte) (*bytes.Buffer, error) {
-	switch sw.builder.Compression {
+func compress(compression configcompression.Type, raw []byte) ([]byte, error) {
+	switch compression {
 	case configcompression.TypeGzip:
 		content := bytes.NewBuffer(nil)
 
@@ -104,12 +252,21 @@ func (sw *s3manager) contentBuffer(raw []byte) (*bytes.Buffer, error) {
 			return nil, err
 		}
 
-		return content, nil
+		return content.Bytes(), nil
 	default:
-		return bytes.NewBuffer(raw), nil
+		return raw, nil
 	}
 }
 
+// compressionDecision returns the compression metadata value of an object
+// written with the given compression.
+func compressionDecision(compression configcompression.Type) string {
+	if !compression.IsCompressed() {
+		return "none"
+	}
+	return string(compression)
+}
+
 func WithACL(acl s3types.ObjectCannedACL) func(Manager) {
 	return func(m Manager) {
 		s3m, ok := m.(*s3manager)
@@ -119,3 +276,259 @@ func WithACL(acl s3types.ObjectCannedACL) func(Manager) {
 		s3m.acl = acl
 	}
 }
+
+// WithRequestPayer sets the payer of the uploads, e.g. to upload to the buckets
+// configured with Requester Pays.
+func WithRequestPayer(payer s3types.RequestPayer) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.requestPayer = payer
+	}
+}
+
+// WithServerSideEncryption sets the server side encryption applied to the
+// uploaded objects, and the KMS key used when it is KMS based, with an S3
+// Bucket Key when bucketKey is set.
+func WithServerSideEncryption(sse s3types.ServerSideEncryption, kmsKeyID string, bucketKey bool) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.sse = sse
+		s3m.kmsKeyID = kmsKeyID
+		s3m.bucketKey = bucketKey
+	}
+}
+
+// applyServerSideEncryption sets the server side encryption fields of input.
+// The bucket key and the encryption context are only sent for KMS based
+// encryption, the latter encoded as the base64 of its JSON representation as
+// expected by S3.
+func applyServerSideEncryption(input *s3.PutObjectInput, sse s3types.ServerSideEncryption, kmsKeyID string, bucketKey bool, encryptionContext map[string]string) error {
+	if sse == "" {
+		return nil
+	}
+	input.ServerSideEncryption = sse
+	if sse == s3types.ServerSideEncryptionAes256 {
+		return nil
+	}
+	if kmsKeyID != "" {
+		input.SSEKMSKeyId = aws.String(kmsKeyID)
+	}
+	if bucketKey {
+		input.BucketKeyEnabled = aws.Bool(true)
+	}
+	if len(encryptionContext) > 0 {
+		raw, err := json.Marshal(encryptionContext)
+		if err != nil {
+			return err
+		}
+		input.SSEKMSEncryptionContext = aws.String(base64.StdEncoding.EncodeToString(raw))
+	}
+	return nil
+}
+
+// SSECustomerKeyFunc returns the 256-bit key an object is encrypted with by S3.
+type SSECustomerKeyFunc func(ctx context.Context) ([]byte, error)
+
+// WithSSECustomerKey encrypts the uploaded objects with the customer provided key
+// returned by key for every upload (SSE-C).
+func WithSSECustomerKey(key SSECustomerKeyFunc) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.sseCustomerKey = key
+	}
+}
+
+// applySSECustomerKey sets the SSE-C fields of input, with the key encoded as base64
+// along with its MD5 digest as expected by S3.
+func applySSECustomerKey(ctx context.Context, input *s3.PutObjectInput, key SSECustomerKeyFunc) error {
+	raw, err := key(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get the SSE-C key: %w", err)
+	}
+	if len(raw) != 32 {
+		return fmt.Errorf("the SSE-C key must be 256 bits, got %d", len(raw)*8)
+	}
+	digest := md5.Sum(raw) //nolint:gosec // S3 checks the integrity of the key with its MD5 digest
+	input.SSECustomerAlgorithm = aws.String(string(s3types.ServerSideEncryptionAes256))
+	input.SSECustomerKey = aws.String(base64.StdEncoding.EncodeToString(raw))
+	input.SSECustomerKeyMD5 = aws.String(base64.StdEncoding.EncodeToString(digest[:]))
+	return nil
+}
+
+// WithObjectLock retains the uploaded objects under the S3 Object Lock mode for
+// retention from their upload. It has no effect when mode is empty.
+func WithObjectLock(mode s3types.ObjectLockMode, retention time.Duration) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.lockMode = mode
+		s3m.lockFor = retention
+	}
+}
+
+// WithLocalDirectory writes the objects to dir, as files at their bucket and key
+// within it, instead of uploading them to S3.
+func WithLocalDirectory(dir string) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.localDir = dir
+	}
+}
+
+// writeLocal writes the content of the object at key in bucket to its file in dir.
+func writeLocal(dir, bucket, key string, content []byte) error {
+	name := filepath.Join(bucket, filepath.FromSlash(key))
+	// The bucket and the key can come from the resource attributes, they must not
+	// write outside of dir.
+	if !filepath.IsLocal(name) {
+		return fmt.Errorf("object %q of bucket %q is outside of the local directory", key, bucket)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, content, 0o600)
+}
+
+// WithMaxConcurrentUploads limits the number of objects uploaded at once, the
+// uploads over the limit waiting for one to complete. It has no effect when max
+// is not positive.
+func WithMaxConcurrentUploads(maxUploads int) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok || maxUploads <= 0 {
+			return
+		}
+		s3m.slots = make(chan struct{}, maxUploads)
+	}
+}
+
+// WithUploadTimeout bounds the upload of every object, its retries and the parts
+// of a multipart upload included, independently of the timeout of the export.
+func WithUploadTimeout(timeout time.Duration) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.timeout = timeout
+	}
+}
+
+// WithContentType sets the content type of the uploaded objects.
+func WithContentType(contentType string) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.contentType = contentType
+	}
+}
+
+// WithObjectTags sets the tags of every uploaded object, e.g. for lifecycle rules
+// or cost allocation.
+func WithObjectTags(tags map[string]string) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.tags = tags
+	}
+}
+
+// WithObjectMetadata sets the user metadata of every uploaded object.
+func WithObjectMetadata(metadata map[string]string) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.metadata = metadata
+	}
+}
+
+// mergeAttributes returns the union of base and override, the latter taking
+// precedence, or nil when both are empty. Neither of them is modified.
+func mergeAttributes(base, override map[string]string) map[string]string {
+	if len(override) == 0 {
+		return base
+	}
+	if len(base) == 0 {
+		return override
+	}
+	merged := make(map[string]string, len(base)+len(override))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range override {
+		merged[k] = v
+	}
+	return merged
+}
+
+// encodeTagging encodes tags as the URL query parameters expected by S3 in
+// the x-amz-tagging header, or returns nil when there is no tag.
+func encodeTagging(tags map[string]string) *string {
+	if len(tags) == 0 {
+		return nil
+	}
+	values := make(url.Values, len(tags))
+	for k, v := range tags {
+		values.Set(k, v)
+	}
+	return aws.String(values.Encode())
+}
+
+// WithPrecompressed uploads the payloads as is, with the key extension and the
+// Content-Encoding of the compression of the key builder, the payloads being
+// already compressed with it, e.g. as several gzip members.
+func WithPrecompressed() func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.precompressed = true
+	}
+}
+
+// WithCompressionMinSize uploads the payloads smaller than size bytes
+// uncompressed, and records the compression of every object in its
+// metadata.
+func WithCompressionMinSize(size int) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.compressionMinSize = size
+	}
+}
+
+// WithUploadObserver registers a function that is called with the bucket,
+// prefix override and partition time of every successfully uploaded object.
+func WithUploadObserver(observer func(bucket, prefix string, ts time.Time)) func(Manager) {
+	return func(m Manager) {
+		s3m, ok := m.(*s3manager)
+		if !ok {
+			return
+		}
+		s3m.observer = observer
+	}
+}
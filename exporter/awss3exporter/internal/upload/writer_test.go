@@ -6,9 +6,14 @@ package upload
 import (
 	"compress/gzip"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -16,6 +21,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"github.com/tilinna/clock"
 	"go.opentelemetry.io/collector/config/configcompression"
 )
@@ -45,6 +51,17 @@ func TestS3ManagerUpload(t *testing.T) {
 		errVal       string
 		storageClass string
 		uploadOpts   *UploadOptions
+		sse          s3types.ServerSideEncryption
+		kmsKeyID     string
+		bucketKey    bool
+		minSize      int
+		tags         map[string]string
+		metadata     map[string]string
+		contentType  string
+		lockMode     s3types.ObjectLockMode
+		lockFor      time.Duration
+		requestPayer s3types.RequestPayer
+		sseCustomer  SSECustomerKeyFunc
 	}{
 		{
 			name: "successful upload",
@@ -259,6 +276,260 @@ func TestS3ManagerUpload(t *testing.T) {
 			storageClass: "STANDARD_IA",
 			uploadOpts:   &UploadOptions{OverrideBucket: "custom-bucket"},
 		},
+		{
+			name: "upload with kms encryption context",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+					_ = r.Body.Close()
+
+					assert.Equal(t, "aws:kms", r.Header.Get("x-amz-server-side-encryption"))
+					assert.Equal(t, "my-key", r.Header.Get("x-amz-server-side-encryption-aws-kms-key-id"))
+					// base64 of {"tenant":"acme"}
+					assert.Equal(t, "eyJ0ZW5hbnQiOiJhY21lIn0=", r.Header.Get("x-amz-server-side-encryption-context"))
+				})
+			},
+			data:       []byte("hello world"),
+			errVal:     "",
+			sse:        s3types.ServerSideEncryptionAwsKms,
+			kmsKeyID:   "my-key",
+			uploadOpts: &UploadOptions{EncryptionContext: map[string]string{"tenant": "acme"}},
+		},
+		{
+			name: "upload with kms bucket key",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+					_ = r.Body.Close()
+
+					assert.Equal(t, "aws:kms", r.Header.Get("x-amz-server-side-encryption"))
+					assert.Equal(t, "arn:aws:kms:eu-central-1:123456789012:alias/telemetry", r.Header.Get("x-amz-server-side-encryption-aws-kms-key-id"))
+					assert.Equal(t, "true", r.Header.Get("x-amz-server-side-encryption-bucket-key-enabled"))
+				})
+			},
+			data:      []byte("hello world"),
+			errVal:    "",
+			sse:       s3types.ServerSideEncryptionAwsKms,
+			kmsKeyID:  "arn:aws:kms:eu-central-1:123456789012:alias/telemetry",
+			bucketKey: true,
+		},
+		{
+			name: "encryption context ignored without kms",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+					_ = r.Body.Close()
+
+					assert.Equal(t, "AES256", r.Header.Get("x-amz-server-side-encryption"))
+					assert.Empty(t, r.Header.Get("x-amz-server-side-encryption-context"))
+				})
+			},
+			data:       []byte("hello world"),
+			errVal:     "",
+			sse:        s3types.ServerSideEncryptionAes256,
+			uploadOpts: &UploadOptions{EncryptionContext: map[string]string{"tenant": "acme"}},
+		},
+		{
+			name: "compression skipped below min size",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					assert.Equal(
+						t,
+						"/my-bucket/telemetry/year=2024/month=01/day=10/hour=10/minute=30/signal-data-noop_random.metrics",
+						r.URL.Path,
+						"Must not have the compression extension",
+					)
+					assert.Empty(t, r.Header.Get("Content-Encoding"))
+					assert.Equal(t, "none", r.Header.Get("x-amz-meta-compression"))
+
+					data, err := io.ReadAll(r.Body)
+					_ = r.Body.Close()
+					assert.NoError(t, err)
+					assert.Equal(t, []byte("hello world"), data)
+				})
+			},
+			compression: configcompression.TypeGzip,
+			minSize:     1024,
+			data:        []byte("hello world"),
+			errVal:      "",
+		},
+		{
+			name: "compression applied above min size",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					assert.Equal(
+						t,
+						"/my-bucket/telemetry/year=2024/month=01/day=10/hour=10/minute=30/signal-data-noop_random.metrics.gz",
+						r.URL.Path,
+						"Must match the expected path",
+					)
+					assert.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+					assert.Equal(t, "gzip", r.Header.Get("x-amz-meta-compression"))
+
+					gr, err := gzip.NewReader(r.Body)
+					if !assert.NoError(t, err, "Must not error creating gzip reader") {
+						return
+					}
+					data, err := io.ReadAll(gr)
+					_ = r.Body.Close()
+					assert.NoError(t, err)
+					assert.Equal(t, []byte("hello world"), data)
+				})
+			},
+			compression: configcompression.TypeGzip,
+			minSize:     4,
+			data:        []byte("hello world"),
+			errVal:      "",
+		},
+		{
+			name: "upload with tags and metadata",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+					_ = r.Body.Close()
+
+					assert.Equal(t, "cost-center=observability&service=checkout&team=payments+eu", r.Header.Get("x-amz-tagging"))
+					assert.Equal(t, "prod", r.Header.Get("x-amz-meta-environment"))
+					assert.Equal(t, "checkout", r.Header.Get("x-amz-meta-service"))
+					assert.Equal(t, "none", r.Header.Get("x-amz-meta-compression"))
+				})
+			},
+			compression: configcompression.TypeGzip,
+			minSize:     1024,
+			data:        []byte("hello world"),
+			errVal:      "",
+			tags:        map[string]string{"cost-center": "observability", "team": "platform"},
+			metadata:    map[string]string{"environment": "prod", "compression": "ignored"},
+			uploadOpts: &UploadOptions{
+				Tags:     map[string]string{"team": "payments eu", "service": "checkout"},
+				Metadata: map[string]string{"service": "checkout"},
+			},
+		},
+		{
+			name: "upload without tags",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+					_ = r.Body.Close()
+
+					_, ok := r.Header["X-Amz-Tagging"]
+					assert.False(t, ok, "Must not send an empty tag set")
+				})
+			},
+			data:       []byte("hello world"),
+			errVal:     "",
+			uploadOpts: &UploadOptions{Tags: map[string]string{}},
+		},
+		{
+			name: "upload with content type",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+					_ = r.Body.Close()
+
+					assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"), "Must match the content type")
+				})
+			},
+			data:        []byte("hello world"),
+			errVal:      "",
+			contentType: "application/x-ndjson",
+		},
+		{
+			name: "upload with object lock",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+					_ = r.Body.Close()
+
+					retainUntil := time.Date(2024, 0o1, 11, 10, 30, 40, 100, time.Local).UTC()
+					assert.Equal(t, "COMPLIANCE", r.Header.Get("X-Amz-Object-Lock-Mode"), "Must match the object lock mode")
+					assert.Equal(t, retainUntil.Format("2006-01-02T15:04:05Z"), r.Header.Get("X-Amz-Object-Lock-Retain-Until-Date"), "Must retain the object for a day")
+					assert.Equal(t, "CRC32", r.Header.Get("X-Amz-Sdk-Checksum-Algorithm"), "Must check the integrity of the object")
+				})
+			},
+			data:     []byte("hello world"),
+			errVal:   "",
+			lockMode: s3types.ObjectLockModeCompliance,
+			lockFor:  24 * time.Hour,
+		},
+		{
+			name: "upload partitioned by record time",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+					_ = r.Body.Close()
+
+					assert.Equal(
+						t,
+						"/my-bucket/telemetry/year=2024/month=01/day=09/hour=23/minute=05/signal-data-noop_random.metrics",
+						r.URL.Path,
+						"Must partition by the time of the records",
+					)
+				})
+			},
+			data:       []byte("hello world"),
+			errVal:     "",
+			uploadOpts: &UploadOptions{PartitionTime: time.Date(2024, 0o1, 9, 23, 5, 0, 0, time.Local)},
+		},
+		{
+			name: "requester pays upload",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+					_ = r.Body.Close()
+
+					assert.Equal(t, "requester", r.Header.Get("X-Amz-Request-Payer"), "Must acknowledge the requester pays")
+				})
+			},
+			data:         []byte("hello world"),
+			errVal:       "",
+			requestPayer: s3types.RequestPayerRequester,
+		},
+		{
+			name: "customer provided key",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+					_, _ = io.Copy(io.Discard, r.Body)
+					_ = r.Body.Close()
+
+					assert.Equal(t, "AES256", r.Header.Get("x-amz-server-side-encryption-customer-algorithm"))
+					assert.Equal(t, "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=", r.Header.Get("x-amz-server-side-encryption-customer-key"))
+					assert.Equal(t, "hRasmdxgYDKV3nvbahU1MA==", r.Header.Get("x-amz-server-side-encryption-customer-key-MD5"))
+					assert.Empty(t, r.Header.Get("x-amz-server-side-encryption"))
+				})
+			},
+			data:   []byte("hello world"),
+			errVal: "",
+			sseCustomer: func(context.Context) ([]byte, error) {
+				return []byte("0123456789abcdef0123456789abcdef"), nil
+			},
+		},
+		{
+			name: "customer provided key failure",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+					assert.Fail(t, "must not upload without the key")
+				})
+			},
+			data:   []byte("hello world"),
+			errVal: "failed to get the SSE-C key: secret store unavailable",
+			sseCustomer: func(context.Context) ([]byte, error) {
+				return nil, errors.New("secret store unavailable")
+			},
+		},
+		{
+			name: "customer provided key of invalid size",
+			handler: func(t *testing.T) http.Handler {
+				return http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {
+					assert.Fail(t, "must not upload with an invalid key")
+				})
+			},
+			data:   []byte("hello world"),
+			errVal: "the SSE-C key must be 256 bits, got 128",
+			sseCustomer: func(context.Context) ([]byte, error) {
+				return []byte("0123456789abcdef"), nil
+			},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
@@ -285,6 +556,14 @@ func TestS3ManagerUpload(t *testing.T) {
 				}),
 				"STANDARD_IA",
 				WithACL(s3types.ObjectCannedACLPrivate),
+				WithServerSideEncryption(tc.sse, tc.kmsKeyID, tc.bucketKey),
+				WithCompressionMinSize(tc.minSize),
+				WithObjectTags(tc.tags),
+				WithObjectMetadata(tc.metadata),
+				WithContentType(tc.contentType),
+				WithObjectLock(tc.lockMode, tc.lockFor),
+				WithRequestPayer(tc.requestPayer),
+				WithSSECustomerKey(tc.sseCustomer),
 			)
 
 			// Using a mocked virtual clock to fix the timestamp used
@@ -300,3 +579,140 @@ func TestS3ManagerUpload(t *testing.T) {
 		})
 	}
 }
+
+func TestS3ManagerLocalDirectory(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	sm := NewS3Manager(
+		"my-bucket",
+		&PartitionKeyBuilder{
+			PartitionPrefix: "telemetry",
+			PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			FilePrefix:      "signal-data-",
+			Metadata:        "noop",
+			FileFormat:      "metrics",
+			Compression:     configcompression.TypeGzip,
+			UniqueKeyFunc: func() string {
+				return "random"
+			},
+		},
+		s3.New(s3.Options{Region: "local"}),
+		"STANDARD",
+		WithLocalDirectory(dir),
+	)
+	ctx := clock.Context(context.Background(), clock.NewMock(time.Date(2024, 0o1, 10, 10, 30, 40, 100, time.Local)))
+
+	require.NoError(t, sm.Upload(ctx, []byte("hello world"), nil))
+	f, err := os.Open(filepath.Join(dir, "my-bucket", "telemetry", "year=2024", "month=01", "day=10", "hour=10", "minute=30", "signal-data-noop_random.metrics.gz"))
+	require.NoError(t, err)
+	defer f.Close()
+	gr, err := gzip.NewReader(f)
+	require.NoError(t, err)
+	data, err := io.ReadAll(gr)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hello world"), data, "the object is written as it would be uploaded")
+
+	err = sm.Upload(ctx, []byte("hello world"), &UploadOptions{OverridePrefix: "../.."})
+	assert.ErrorContains(t, err, "is outside of the local directory")
+}
+
+func TestS3ManagerPrecompressed(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	sm := NewS3Manager(
+		"my-bucket",
+		&PartitionKeyBuilder{
+			PartitionPrefix: "telemetry",
+			PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+			FilePrefix:      "signal-data-",
+			Metadata:        "noop",
+			FileFormat:      "metrics",
+			Compression:     configcompression.TypeGzip,
+			UniqueKeyFunc: func() string {
+				return "random"
+			},
+		},
+		s3.New(s3.Options{Region: "local"}),
+		"STANDARD",
+		WithLocalDirectory(dir),
+		WithPrecompressed(),
+	)
+	ctx := clock.Context(context.Background(), clock.NewMock(time.Date(2024, 0o1, 10, 10, 30, 40, 100, time.Local)))
+
+	content, err := compress(configcompression.TypeGzip, []byte("hello world"))
+	require.NoError(t, err)
+	require.NoError(t, sm.Upload(ctx, content, nil))
+	data, err := os.ReadFile(filepath.Join(dir, "my-bucket", "telemetry", "year=2024", "month=01", "day=10", "hour=10", "minute=30", "signal-data-noop_random.metrics.gz"))
+	require.NoError(t, err)
+	assert.Equal(t, content, data, "the payload is not compressed again")
+}
+
+func TestS3ManagerUploadLimits(t *testing.T) {
+	t.Parallel()
+
+	newManager := func(t *testing.T, handler http.HandlerFunc, opts ...ManagerOpt) Manager {
+		s := httptest.NewServer(handler)
+		t.Cleanup(s.Close)
+		return NewS3Manager(
+			"my-bucket",
+			&PartitionKeyBuilder{
+				PartitionPrefix: "telemetry",
+				PartitionFormat: "year=%Y/month=%m/day=%d/hour=%H/minute=%M",
+				FilePrefix:      "signal-data-",
+				Metadata:        "noop",
+				FileFormat:      "metrics",
+			},
+			s3.New(s3.Options{
+				BaseEndpoint: aws.String(s.URL),
+				Region:       "local",
+				UsePathStyle: true,
+				Retryer:      aws.NopRetryer{},
+			}),
+			"STANDARD",
+			opts...,
+		)
+	}
+
+	t.Run("max concurrent uploads", func(t *testing.T) {
+		t.Parallel()
+
+		var inFlight, maxInFlight atomic.Int32
+		m := newManager(t, func(_ http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				peak := maxInFlight.Load()
+				if n <= peak || maxInFlight.CompareAndSwap(peak, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+		}, WithMaxConcurrentUploads(2))
+
+		var wg sync.WaitGroup
+		for range 6 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				assert.NoError(t, m.Upload(context.Background(), []byte("hello world"), nil))
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(2), maxInFlight.Load())
+	})
+
+	t.Run("upload timeout", func(t *testing.T) {
+		t.Parallel()
+
+		m := newManager(t, func(_ http.ResponseWriter, r *http.Request) {
+			_, _ = io.Copy(io.Discard, r.Body)
+			<-r.Context().Done()
+		}, WithUploadTimeout(50*time.Millisecond))
+
+		err := m.Upload(context.Background(), []byte("hello world"), nil)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
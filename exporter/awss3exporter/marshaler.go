@@ -58,6 +58,10 @@ func newMarshaler(mType MarshalerType, logger *zap.Logger) (marshaler, error) {
 		exportbodyMarshaler := newbodyMarshaler()
 		marshaler.logsMarshaler = &exportbodyMarshaler
 		marshaler.fileFormat = exportbodyMarshaler.format()
+	case BodyJSONL:
+		bodyJSONLMarshaler := newBodyJSONLMarshaler()
+		marshaler.logsMarshaler = &bodyJSONLMarshaler
+		marshaler.fileFormat = bodyJSONLMarshaler.format()
 	default:
 		return nil, ErrUnknownMarshaler
 	}
@@ -0,0 +1,274 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/parquet-go/parquet-go"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var errParquetTracesUnsupported = errors.New("traces are not supported by the parquet marshaler")
+
+// parquetLogRecord is a row of the Parquet schema of logs, one per log record.
+type parquetLogRecord struct {
+	ResourceAttributes   map[string]string `parquet:"resource_attributes"`
+	ScopeName            string            `parquet:"scope_name"`
+	ScopeVersion         string            `parquet:"scope_version"`
+	TimeUnixNano         int64             `parquet:"time_unix_nano"`
+	ObservedTimeUnixNano int64             `parquet:"observed_time_unix_nano"`
+	SeverityNumber       int32             `parquet:"severity_number"`
+	SeverityText         string            `parquet:"severity_text"`
+	Body                 string            `parquet:"body"`
+	Attributes           map[string]string `parquet:"attributes"`
+	TraceID              string            `parquet:"trace_id"`
+	SpanID               string            `parquet:"span_id"`
+	Flags                uint32            `parquet:"flags"`
+}
+
+// parquetDataPoint is a row of the Parquet schema of metrics, one per data point. The
+// columns that do not apply to the type of the metric are left null.
+type parquetDataPoint struct {
+	ResourceAttributes     map[string]string `parquet:"resource_attributes"`
+	ScopeName              string            `parquet:"scope_name"`
+	ScopeVersion           string            `parquet:"scope_version"`
+	MetricName             string            `parquet:"metric_name"`
+	MetricDescription      string            `parquet:"metric_description"`
+	MetricUnit             string            `parquet:"metric_unit"`
+	MetricType             string            `parquet:"metric_type"`
+	AggregationTemporality string            `parquet:"aggregation_temporality,optional"`
+	IsMonotonic            *bool             `parquet:"is_monotonic,optional"`
+	Attributes             map[string]string `parquet:"attributes"`
+	StartTimeUnixNano      int64             `parquet:"start_time_unix_nano"`
+	TimeUnixNano           int64             `parquet:"time_unix_nano"`
+	Flags                  uint32            `parquet:"flags"`
+	// Gauge and Sum
+	AsDouble *float64 `parquet:"as_double,optional"`
+	AsInt    *int64   `parquet:"as_int,optional"`
+	// Histogram, ExponentialHistogram and Summary
+	Count *uint64  `parquet:"count,optional"`
+	Sum   *float64 `parquet:"sum,optional"`
+	// Histogram and ExponentialHistogram
+	Min *float64 `parquet:"min,optional"`
+	Max *float64 `parquet:"max,optional"`
+	// Histogram
+	BucketCounts   []uint64  `parquet:"bucket_counts,list,optional"`
+	ExplicitBounds []float64 `parquet:"explicit_bounds,list,optional"`
+	// ExponentialHistogram
+	Scale                *int32   `parquet:"scale,optional"`
+	ZeroCount            *uint64  `parquet:"zero_count,optional"`
+	PositiveOffset       *int32   `parquet:"positive_offset,optional"`
+	PositiveBucketCounts []uint64 `parquet:"positive_bucket_counts,list,optional"`
+	NegativeOffset       *int32   `parquet:"negative_offset,optional"`
+	NegativeBucketCounts []uint64 `parquet:"negative_bucket_counts,list,optional"`
+	// Summary
+	Quantiles      []float64 `parquet:"quantiles,list,optional"`
+	QuantileValues []float64 `parquet:"quantile_values,list,optional"`
+}
+
+// parquetMarshaler writes logs and metrics as Parquet files with one row per log
+// record or data point, so that they can be queried in place, e.g. by Athena.
+type parquetMarshaler struct {
+	rowGroupSize int64
+}
+
+func newParquetMarshaler(cfg ParquetConfig) *parquetMarshaler {
+	return &parquetMarshaler{rowGroupSize: int64(cfg.RowGroupSize)}
+}
+
+func (*parquetMarshaler) format() string {
+	return "parquet"
+}
+
+func (*parquetMarshaler) MarshalTraces(ptrace.Traces) ([]byte, error) {
+	return nil, errParquetTracesUnsupported
+}
+
+func (m *parquetMarshaler) MarshalLogs(ld plog.Logs) ([]byte, error) {
+	var rows []parquetLogRecord
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		rl := ld.ResourceLogs().At(i)
+		resourceAttrs := attributesToMap(rl.Resource().Attributes())
+		for j := 0; j < rl.ScopeLogs().Len(); j++ {
+			sl := rl.ScopeLogs().At(j)
+			for k := 0; k < sl.LogRecords().Len(); k++ {
+				lr := sl.LogRecords().At(k)
+				rows = append(rows, parquetLogRecord{
+					ResourceAttributes:   resourceAttrs,
+					ScopeName:            sl.Scope().Name(),
+					ScopeVersion:         sl.Scope().Version(),
+					TimeUnixNano:         int64(lr.Timestamp()),
+					ObservedTimeUnixNano: int64(lr.ObservedTimestamp()),
+					SeverityNumber:       int32(lr.SeverityNumber()),
+					SeverityText:         lr.SeverityText(),
+					Body:                 lr.Body().AsString(),
+					Attributes:           attributesToMap(lr.Attributes()),
+					TraceID:              lr.TraceID().String(),
+					SpanID:               lr.SpanID().String(),
+					Flags:                uint32(lr.Flags()),
+				})
+			}
+		}
+	}
+	return writeParquet(rows, m.rowGroupSize)
+}
+
+func (m *parquetMarshaler) MarshalMetrics(md pmetric.Metrics) ([]byte, error) {
+	var rows []parquetDataPoint
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		rm := md.ResourceMetrics().At(i)
+		resourceAttrs := attributesToMap(rm.Resource().Attributes())
+		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
+			sm := rm.ScopeMetrics().At(j)
+			for k := 0; k < sm.Metrics().Len(); k++ {
+				metric := sm.Metrics().At(k)
+				base := parquetDataPoint{
+					ResourceAttributes: resourceAttrs,
+					ScopeName:          sm.Scope().Name(),
+					ScopeVersion:       sm.Scope().Version(),
+					MetricName:         metric.Name(),
+					MetricDescription:  metric.Description(),
+					MetricUnit:         metric.Unit(),
+					MetricType:         metric.Type().String(),
+				}
+				rows = appendDataPoints(rows, base, metric)
+			}
+		}
+	}
+	return writeParquet(rows, m.rowGroupSize)
+}
+
+// appendDataPoints appends a row per data point of metric to rows, each starting
+// from base.
+func appendDataPoints(rows []parquetDataPoint, base parquetDataPoint, metric pmetric.Metric) []parquetDataPoint {
+	switch metric.Type() {
+	case pmetric.MetricTypeGauge:
+		rows = appendNumberDataPoints(rows, base, metric.Gauge().DataPoints())
+	case pmetric.MetricTypeSum:
+		base.AggregationTemporality = metric.Sum().AggregationTemporality().String()
+		base.IsMonotonic = ptr(metric.Sum().IsMonotonic())
+		rows = appendNumberDataPoints(rows, base, metric.Sum().DataPoints())
+	case pmetric.MetricTypeHistogram:
+		base.AggregationTemporality = metric.Histogram().AggregationTemporality().String()
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			row := dataPointRow(base, dp.Attributes(), dp.StartTimestamp(), dp.Timestamp(), dp.Flags())
+			row.Count = ptr(dp.Count())
+			if dp.HasSum() {
+				row.Sum = ptr(dp.Sum())
+			}
+			if dp.HasMin() {
+				row.Min = ptr(dp.Min())
+			}
+			if dp.HasMax() {
+				row.Max = ptr(dp.Max())
+			}
+			row.BucketCounts = dp.BucketCounts().AsRaw()
+			row.ExplicitBounds = dp.ExplicitBounds().AsRaw()
+			rows = append(rows, row)
+		}
+	case pmetric.MetricTypeExponentialHistogram:
+		base.AggregationTemporality = metric.ExponentialHistogram().AggregationTemporality().String()
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			row := dataPointRow(base, dp.Attributes(), dp.StartTimestamp(), dp.Timestamp(), dp.Flags())
+			row.Count = ptr(dp.Count())
+			if dp.HasSum() {
+				row.Sum = ptr(dp.Sum())
+			}
+			if dp.HasMin() {
+				row.Min = ptr(dp.Min())
+			}
+			if dp.HasMax() {
+				row.Max = ptr(dp.Max())
+			}
+			row.Scale = ptr(dp.Scale())
+			row.ZeroCount = ptr(dp.ZeroCount())
+			row.PositiveOffset = ptr(dp.Positive().Offset())
+			row.PositiveBucketCounts = dp.Positive().BucketCounts().AsRaw()
+			row.NegativeOffset = ptr(dp.Negative().Offset())
+			row.NegativeBucketCounts = dp.Negative().BucketCounts().AsRaw()
+			rows = append(rows, row)
+		}
+	case pmetric.MetricTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			dp := dps.At(i)
+			row := dataPointRow(base, dp.Attributes(), dp.StartTimestamp(), dp.Timestamp(), dp.Flags())
+			row.Count = ptr(dp.Count())
+			row.Sum = ptr(dp.Sum())
+			for j := 0; j < dp.QuantileValues().Len(); j++ {
+				qv := dp.QuantileValues().At(j)
+				row.Quantiles = append(row.Quantiles, qv.Quantile())
+				row.QuantileValues = append(row.QuantileValues, qv.Value())
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func appendNumberDataPoints(rows []parquetDataPoint, base parquetDataPoint, dps pmetric.NumberDataPointSlice) []parquetDataPoint {
+	for i := 0; i < dps.Len(); i++ {
+		dp := dps.At(i)
+		row := dataPointRow(base, dp.Attributes(), dp.StartTimestamp(), dp.Timestamp(), dp.Flags())
+		switch dp.ValueType() {
+		case pmetric.NumberDataPointValueTypeDouble:
+			row.AsDouble = ptr(dp.DoubleValue())
+		case pmetric.NumberDataPointValueTypeInt:
+			row.AsInt = ptr(dp.IntValue())
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func dataPointRow(base parquetDataPoint, attrs pcommon.Map, start, ts pcommon.Timestamp, flags pmetric.DataPointFlags) parquetDataPoint {
+	row := base
+	row.Attributes = attributesToMap(attrs)
+	row.StartTimeUnixNano = int64(start)
+	row.TimeUnixNano = int64(ts)
+	row.Flags = uint32(flags)
+	return row
+}
+
+// writeParquet writes rows as a Snappy compressed Parquet file, with at most
+// rowGroupSize rows per row group.
+func writeParquet[T any](rows []T, rowGroupSize int64) ([]byte, error) {
+	buf := bytes.Buffer{}
+	options := []parquet.WriterOption{parquet.Compression(&parquet.Snappy)}
+	if rowGroupSize > 0 {
+		options = append(options, parquet.MaxRowsPerRowGroup(rowGroupSize))
+	}
+	w := parquet.NewGenericWriter[T](&buf, options...)
+	if _, err := w.Write(rows); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// attributesToMap flattens attrs to their string representation, Athena mapping
+// them to a map<string,string> column.
+func attributesToMap(attrs pcommon.Map) map[string]string {
+	m := make(map[string]string, attrs.Len())
+	attrs.Range(func(k string, v pcommon.Value) bool {
+		m[k] = v.AsString()
+		return true
+	})
+	return m
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestParquetMarshalLogs(t *testing.T) {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().PutStr("service.name", "checkout")
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName("scope")
+	sl.Scope().SetVersion("1.0")
+	lr := sl.LogRecords().AppendEmpty()
+	lr.SetTimestamp(1000)
+	lr.SetObservedTimestamp(2000)
+	lr.SetSeverityNumber(plog.SeverityNumberError)
+	lr.SetSeverityText("ERROR")
+	lr.Body().SetStr("payment failed")
+	lr.Attributes().PutInt("http.status_code", 502)
+	lr.SetTraceID(pcommon.TraceID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	lr.SetSpanID(pcommon.SpanID{1, 2, 3, 4, 5, 6, 7, 8})
+	sl.LogRecords().AppendEmpty().Body().SetStr("no trace")
+
+	m := newParquetMarshaler(ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize})
+	assert.Equal(t, "parquet", m.format())
+	buf, err := m.MarshalLogs(logs)
+	require.NoError(t, err)
+
+	rows, err := parquet.Read[parquetLogRecord](bytes.NewReader(buf), int64(len(buf)))
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, parquetLogRecord{
+		ResourceAttributes:   map[string]string{"service.name": "checkout"},
+		ScopeName:            "scope",
+		ScopeVersion:         "1.0",
+		TimeUnixNano:         1000,
+		ObservedTimeUnixNano: 2000,
+		SeverityNumber:       int32(plog.SeverityNumberError),
+		SeverityText:         "ERROR",
+		Body:                 "payment failed",
+		Attributes:           map[string]string{"http.status_code": "502"},
+		TraceID:              "0102030405060708090a0b0c0d0e0f10",
+		SpanID:               "0102030405060708",
+	}, rows[0])
+	assert.Equal(t, "no trace", rows[1].Body)
+	assert.Empty(t, rows[1].TraceID)
+}
+
+func TestParquetMarshalMetrics(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	rm := metrics.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().PutStr("service.name", "checkout")
+	sm := rm.ScopeMetrics().AppendEmpty()
+
+	sum := sm.Metrics().AppendEmpty()
+	sum.SetName("requests")
+	sum.SetUnit("{request}")
+	sum.SetEmptySum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	sum.Sum().SetIsMonotonic(true)
+	dp := sum.Sum().DataPoints().AppendEmpty()
+	dp.SetStartTimestamp(1000)
+	dp.SetTimestamp(2000)
+	dp.SetIntValue(42)
+	dp.Attributes().PutStr("method", "GET")
+
+	gauge := sm.Metrics().AppendEmpty()
+	gauge.SetName("temperature")
+	gauge.SetEmptyGauge().DataPoints().AppendEmpty().SetDoubleValue(21.5)
+
+	histogram := sm.Metrics().AppendEmpty()
+	histogram.SetName("latency")
+	histogram.SetEmptyHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	hdp := histogram.Histogram().DataPoints().AppendEmpty()
+	hdp.SetCount(3)
+	hdp.SetSum(6)
+	hdp.ExplicitBounds().FromRaw([]float64{1, 5})
+	hdp.BucketCounts().FromRaw([]uint64{1, 1, 1})
+
+	exponential := sm.Metrics().AppendEmpty()
+	exponential.SetName("size")
+	edp := exponential.SetEmptyExponentialHistogram().DataPoints().AppendEmpty()
+	edp.SetCount(2)
+	edp.SetScale(2)
+	edp.SetZeroCount(1)
+	edp.Positive().SetOffset(3)
+	edp.Positive().BucketCounts().FromRaw([]uint64{1})
+
+	summary := sm.Metrics().AppendEmpty()
+	summary.SetName("duration")
+	sdp := summary.SetEmptySummary().DataPoints().AppendEmpty()
+	sdp.SetCount(10)
+	sdp.SetSum(100)
+	qv := sdp.QuantileValues().AppendEmpty()
+	qv.SetQuantile(0.5)
+	qv.SetValue(9)
+
+	m := newParquetMarshaler(ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize})
+	buf, err := m.MarshalMetrics(metrics)
+	require.NoError(t, err)
+
+	rows, err := parquet.Read[parquetDataPoint](bytes.NewReader(buf), int64(len(buf)))
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+
+	assert.Equal(t, "requests", rows[0].MetricName)
+	assert.Equal(t, "{request}", rows[0].MetricUnit)
+	assert.Equal(t, "Sum", rows[0].MetricType)
+	assert.Equal(t, "Cumulative", rows[0].AggregationTemporality)
+	assert.Equal(t, ptr(true), rows[0].IsMonotonic)
+	assert.Equal(t, map[string]string{"service.name": "checkout"}, rows[0].ResourceAttributes)
+	assert.Equal(t, map[string]string{"method": "GET"}, rows[0].Attributes)
+	assert.Equal(t, int64(1000), rows[0].StartTimeUnixNano)
+	assert.Equal(t, int64(2000), rows[0].TimeUnixNano)
+	assert.Equal(t, ptr(int64(42)), rows[0].AsInt)
+	assert.Nil(t, rows[0].AsDouble)
+
+	assert.Equal(t, "Gauge", rows[1].MetricType)
+	assert.Empty(t, rows[1].AggregationTemporality)
+	assert.Nil(t, rows[1].IsMonotonic)
+	assert.Equal(t, ptr(21.5), rows[1].AsDouble)
+
+	assert.Equal(t, "Histogram", rows[2].MetricType)
+	assert.Equal(t, ptr(uint64(3)), rows[2].Count)
+	assert.Equal(t, ptr(6.0), rows[2].Sum)
+	assert.Nil(t, rows[2].Min)
+	assert.Equal(t, []float64{1, 5}, rows[2].ExplicitBounds)
+	assert.Equal(t, []uint64{1, 1, 1}, rows[2].BucketCounts)
+
+	assert.Equal(t, "ExponentialHistogram", rows[3].MetricType)
+	assert.Equal(t, ptr(int32(2)), rows[3].Scale)
+	assert.Equal(t, ptr(uint64(1)), rows[3].ZeroCount)
+	assert.Equal(t, ptr(int32(3)), rows[3].PositiveOffset)
+	assert.Equal(t, []uint64{1}, rows[3].PositiveBucketCounts)
+	assert.Empty(t, rows[3].NegativeBucketCounts)
+
+	assert.Equal(t, "Summary", rows[4].MetricType)
+	assert.Equal(t, ptr(uint64(10)), rows[4].Count)
+	assert.Equal(t, []float64{0.5}, rows[4].Quantiles)
+	assert.Equal(t, []float64{9}, rows[4].QuantileValues)
+}
+
+func TestParquetRowGroupSize(t *testing.T) {
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for i := 0; i < 5; i++ {
+		records.AppendEmpty().Body().SetInt(int64(i))
+	}
+
+	buf, err := newParquetMarshaler(ParquetConfig{RowGroupSize: 2}).MarshalLogs(logs)
+	require.NoError(t, err)
+
+	f, err := parquet.OpenFile(bytes.NewReader(buf), int64(len(buf)))
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), f.NumRows())
+	assert.Len(t, f.RowGroups(), 3)
+}
+
+func TestParquetMarshalTraces(t *testing.T) {
+	_, err := newParquetMarshaler(ParquetConfig{RowGroupSize: DefaultParquetRowGroupSize}).MarshalTraces(ptrace.NewTraces())
+	assert.ErrorIs(t, err, errParquetTracesUnsupported)
+}
@@ -0,0 +1,61 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"
+
+import (
+	"bytes"
+	"fmt"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// passthroughMarshaler writes the original bytes received by the collector, held
+// in the log bodies by the receivers preserving them, untouched and without
+// separators, for a byte-exact archival.
+type passthroughMarshaler struct {
+	fileExtension string
+}
+
+func newPassthroughMarshaler(conf PassthroughConfig) *passthroughMarshaler {
+	return &passthroughMarshaler{fileExtension: conf.fileExtension()}
+}
+
+func (m *passthroughMarshaler) format() string {
+	return m.fileExtension
+}
+
+func (m *passthroughMarshaler) MarshalLogs(ld plog.Logs) ([]byte, error) {
+	buf := bytes.Buffer{}
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			logs := sls.At(j).LogRecords()
+			for k := 0; k < logs.Len(); k++ {
+				body := logs.At(k).Body()
+				switch body.Type() {
+				case pcommon.ValueTypeBytes:
+					buf.Write(body.Bytes().AsRaw())
+				case pcommon.ValueTypeStr:
+					buf.WriteString(body.Str())
+				default:
+					// Any other body would have to be encoded, so its bytes would not be the original ones.
+					return nil, fmt.Errorf("log record body of type %s can't be passed through, it must hold the original bytes or string", body.Type())
+				}
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (m *passthroughMarshaler) MarshalTraces(_ ptrace.Traces) ([]byte, error) {
+	return nil, fmt.Errorf("traces can't be marshaled into %s format", m.format())
+}
+
+func (m *passthroughMarshaler) MarshalMetrics(_ pmetric.Metrics) ([]byte, error) {
+	return nil, fmt.Errorf("metrics can't be marshaled into %s format", m.format())
+}
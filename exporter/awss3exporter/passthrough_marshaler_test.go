@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestPassthroughMarshaler(t *testing.T) {
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Body().SetEmptyBytes().FromRaw([]byte{0x1f, 0x8b, 0x00, '\n'})
+	records.AppendEmpty().Body().SetStr("line without newline")
+	records = logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Body().SetEmptyBytes().FromRaw([]byte("{\"a\":1}\r\n"))
+
+	m := newPassthroughMarshaler(PassthroughConfig{})
+	assert.Equal(t, "bin", m.format())
+	body, err := m.MarshalLogs(logs)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("\x1f\x8b\x00\nline without newline{\"a\":1}\r\n"), body)
+
+	body, err = m.MarshalLogs(plog.NewLogs())
+	require.NoError(t, err)
+	assert.Empty(t, body)
+}
+
+func TestPassthroughMarshalerRejectsEncodedBodies(t *testing.T) {
+	logs := plog.NewLogs()
+	records := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	records.AppendEmpty().Body().SetEmptyBytes().FromRaw([]byte("raw"))
+	records.AppendEmpty().Body().SetEmptyMap().PutStr("key", "value")
+
+	m := newPassthroughMarshaler(PassthroughConfig{FileExtension: "log.gz"})
+	assert.Equal(t, "log.gz", m.format())
+	_, err := m.MarshalLogs(logs)
+	assert.EqualError(t, err, "log record body of type Map can't be passed through, it must hold the original bytes or string")
+
+	_, err = m.MarshalTraces(ptrace.NewTraces())
+	assert.Error(t, err)
+	_, err = m.MarshalMetrics(pmetric.NewMetrics())
+	assert.Error(t, err)
+}
+
+func TestPassthroughContentType(t *testing.T) {
+	c := createDefaultConfig().(*Config)
+	assert.Empty(t, c.contentType("logs"))
+
+	c.MarshalerName = Passthrough
+	assert.Equal(t, "application/octet-stream", c.contentType("logs"))
+
+	c.Passthrough.ContentType = "application/x-ndjson"
+	assert.Equal(t, "application/x-ndjson", c.contentType("logs"))
+
+	id := component.MustNewID("text_encoding")
+	c.Encodings.Logs.Encoding = &id
+	assert.Empty(t, c.contentType("logs"), "the encoding of the logs overrides the marshaler")
+}
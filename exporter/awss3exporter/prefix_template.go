@@ -0,0 +1,78 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"
+
+import (
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// missingAttributeValue replaces the placeholders of the resource attributes a resource does not have.
+const missingAttributeValue = "unknown"
+
+// prefixTemplate is a key prefix referencing resource attributes as {attribute} placeholders,
+// e.g. "{service.name}/{deployment.environment}".
+type prefixTemplate struct {
+	// literals surround the placeholders, there is always one more literal than attributes.
+	literals   []string
+	attributes []string
+}
+
+// parsePrefixTemplate parses prefix, returning nil if it does not reference any resource attribute.
+func parsePrefixTemplate(prefix string) (*prefixTemplate, error) {
+	if !strings.ContainsAny(prefix, "{}") {
+		return nil, nil
+	}
+	t := &prefixTemplate{}
+	rest := prefix
+	for {
+		start := strings.IndexByte(rest, '{')
+		end := strings.IndexByte(rest, '}')
+		if start < 0 {
+			if end >= 0 {
+				return nil, fmt.Errorf("unexpected '}' in %q", prefix)
+			}
+			t.literals = append(t.literals, rest)
+			return t, nil
+		}
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed '{' in %q", prefix)
+		}
+		if end < start {
+			return nil, fmt.Errorf("unexpected '}' in %q", prefix)
+		}
+		attribute := rest[start+1 : end]
+		if attribute == "" {
+			return nil, fmt.Errorf("empty placeholder in %q", prefix)
+		}
+		if strings.IndexByte(attribute, '{') >= 0 {
+			return nil, fmt.Errorf("unexpected '{' in %q", prefix)
+		}
+		t.literals = append(t.literals, rest[:start])
+		t.attributes = append(t.attributes, attribute)
+		rest = rest[end+1:]
+	}
+}
+
+// render returns the prefix with the placeholders replaced by the values of the resource attributes.
+func (t *prefixTemplate) render(attrs pcommon.Map) string {
+	sb := strings.Builder{}
+	for i, attribute := range t.attributes {
+		sb.WriteString(t.literals[i])
+		if value, ok := attrs.Get(attribute); ok && value.AsString() != "" {
+			sb.WriteString(value.AsString())
+		} else {
+			sb.WriteString(missingAttributeValue)
+		}
+	}
+	sb.WriteString(t.literals[len(t.literals)-1])
+	return sb.String()
+}
+
+// staticPrefix returns the part of the prefix preceding the first placeholder, shared by every rendered prefix.
+func (t *prefixTemplate) staticPrefix() string {
+	return t.literals[0]
+}
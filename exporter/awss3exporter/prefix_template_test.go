@@ -0,0 +1,73 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestParsePrefixTemplate(t *testing.T) {
+	tests := []struct {
+		prefix         string
+		wantAttributes []string
+		wantStatic     string
+		wantErr        string
+	}{
+		{prefix: "logs/static"},
+		{
+			prefix:         "{service.name}/{deployment.environment}/",
+			wantAttributes: []string{"service.name", "deployment.environment"},
+			wantStatic:     "",
+		},
+		{
+			prefix:         "logs/{service.name}",
+			wantAttributes: []string{"service.name"},
+			wantStatic:     "logs/",
+		},
+		{prefix: "logs/{}", wantErr: `empty placeholder in "logs/{}"`},
+		{prefix: "logs/{service.name", wantErr: `unclosed '{' in "logs/{service.name"`},
+		{prefix: "logs/service.name}", wantErr: `unexpected '}' in "logs/service.name}"`},
+		{prefix: "logs/{{service.name}}", wantErr: `unexpected '{' in "logs/{{service.name}}"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			tmpl, err := parsePrefixTemplate(tt.prefix)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantAttributes == nil {
+				assert.Nil(t, tmpl)
+				return
+			}
+			require.NotNil(t, tmpl)
+			assert.Equal(t, tt.wantAttributes, tmpl.attributes)
+			assert.Equal(t, tt.wantStatic, tmpl.staticPrefix())
+		})
+	}
+}
+
+func TestPrefixTemplateRender(t *testing.T) {
+	tmpl, err := parsePrefixTemplate("{service.name}/{deployment.environment}/{host.cpu}")
+	require.NoError(t, err)
+
+	attrs := pcommon.NewMap()
+	attrs.PutStr("service.name", "checkout")
+	attrs.PutInt("host.cpu", 4)
+	assert.Equal(t, "checkout/unknown/4", tmpl.render(attrs))
+}
+
+func TestBatchAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	assert.Empty(t, cfg.batchAttributes())
+
+	cfg.S3Uploader.S3Prefix = "{service.name}/{deployment.environment}/{service.name}"
+	cfg.ResourceAttrsToS3.S3Prefix = "tenant"
+	assert.Equal(t, []string{"service.name", "deployment.environment", "tenant"}, cfg.batchAttributes())
+}
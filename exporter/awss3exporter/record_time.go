@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"
+
+import (
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// timeRange is the range of the timestamps of records, the records without a
+// timestamp being ignored.
+type timeRange struct {
+	earliest pcommon.Timestamp
+	latest   pcommon.Timestamp
+}
+
+func (r *timeRange) add(ts pcommon.Timestamp) {
+	if ts == 0 {
+		return
+	}
+	if r.earliest == 0 || ts < r.earliest {
+		r.earliest = ts
+	}
+	if ts > r.latest {
+		r.latest = ts
+	}
+}
+
+// partitionTime returns the time of the records the partition is derived from, or
+// the zero time to derive it from the time of the upload.
+func (r timeRange) partitionTime(source PartitionTimeSource) time.Time {
+	var ts pcommon.Timestamp
+	switch source {
+	case PartitionTimeEarliestRecord:
+		ts = r.earliest
+	case PartitionTimeLatestRecord:
+		ts = r.latest
+	}
+	if ts == 0 {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// logsTimeRange returns the range of the timestamps of the log records, their
+// observed timestamp standing in for the missing ones.
+func logsTimeRange(ld plog.Logs) timeRange {
+	var r timeRange
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+				if ts := lr.Timestamp(); ts != 0 {
+					r.add(ts)
+				} else {
+					r.add(lr.ObservedTimestamp())
+				}
+			}
+		}
+	}
+	return r
+}
+
+// tracesTimeRange returns the range of the start timestamps of the spans.
+func tracesTimeRange(td ptrace.Traces) timeRange {
+	var r timeRange
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				r.add(spans.At(k).StartTimestamp())
+			}
+		}
+	}
+	return r
+}
+
+// metricsTimeRange returns the range of the timestamps of the data points.
+func metricsTimeRange(md pmetric.Metrics) timeRange {
+	var r timeRange
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				m := metrics.At(k)
+				switch m.Type() {
+				case pmetric.MetricTypeGauge:
+					addNumberDataPoints(&r, m.Gauge().DataPoints())
+				case pmetric.MetricTypeSum:
+					addNumberDataPoints(&r, m.Sum().DataPoints())
+				case pmetric.MetricTypeHistogram:
+					dps := m.Histogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						r.add(dps.At(l).Timestamp())
+					}
+				case pmetric.MetricTypeExponentialHistogram:
+					dps := m.ExponentialHistogram().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						r.add(dps.At(l).Timestamp())
+					}
+				case pmetric.MetricTypeSummary:
+					dps := m.Summary().DataPoints()
+					for l := 0; l < dps.Len(); l++ {
+						r.add(dps.At(l).Timestamp())
+					}
+				}
+			}
+		}
+	}
+	return r
+}
+
+func addNumberDataPoints(r *timeRange, dps pmetric.NumberDataPointSlice) {
+	for i := 0; i < dps.Len(); i++ {
+		r.add(dps.At(i).Timestamp())
+	}
+}
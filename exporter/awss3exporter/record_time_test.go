@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+var (
+	earliestRecord = time.Date(2024, 1, 10, 9, 58, 0, 0, time.UTC)
+	latestRecord   = time.Date(2024, 1, 10, 10, 2, 0, 0, time.UTC)
+)
+
+func TestLogsTimeRange(t *testing.T) {
+	logs := plog.NewLogs()
+	lrs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty().SetTimestamp(pcommon.NewTimestampFromTime(latestRecord))
+	// The observed timestamp stands in for the missing timestamp.
+	lrs.AppendEmpty().SetObservedTimestamp(pcommon.NewTimestampFromTime(earliestRecord))
+	// Records without any timestamp are ignored.
+	lrs.AppendEmpty()
+
+	r := logsTimeRange(logs)
+	assert.Equal(t, earliestRecord, r.partitionTime(PartitionTimeEarliestRecord))
+	assert.Equal(t, latestRecord, r.partitionTime(PartitionTimeLatestRecord))
+	assert.True(t, r.partitionTime(PartitionTimeUpload).IsZero())
+	assert.True(t, logsTimeRange(plog.NewLogs()).partitionTime(PartitionTimeEarliestRecord).IsZero(), "no record, the upload time is used")
+}
+
+func TestTracesTimeRange(t *testing.T) {
+	traces := ptrace.NewTraces()
+	spans := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	spans.AppendEmpty().SetStartTimestamp(pcommon.NewTimestampFromTime(latestRecord))
+	spans.AppendEmpty().SetStartTimestamp(pcommon.NewTimestampFromTime(earliestRecord))
+
+	r := tracesTimeRange(traces)
+	assert.Equal(t, earliestRecord, r.partitionTime(PartitionTimeEarliestRecord))
+	assert.Equal(t, latestRecord, r.partitionTime(PartitionTimeLatestRecord))
+}
+
+func TestMetricsTimeRange(t *testing.T) {
+	metrics := pmetric.NewMetrics()
+	ms := metrics.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	ms.AppendEmpty().SetEmptyGauge().DataPoints().AppendEmpty().SetTimestamp(pcommon.NewTimestampFromTime(latestRecord))
+	ms.AppendEmpty().SetEmptyHistogram().DataPoints().AppendEmpty().SetTimestamp(pcommon.NewTimestampFromTime(earliestRecord))
+	ms.AppendEmpty().SetEmptySummary().DataPoints().AppendEmpty().SetTimestamp(pcommon.NewTimestampFromTime(earliestRecord.Add(time.Minute)))
+
+	r := metricsTimeRange(metrics)
+	assert.Equal(t, earliestRecord, r.partitionTime(PartitionTimeEarliestRecord))
+	assert.Equal(t, latestRecord, r.partitionTime(PartitionTimeLatestRecord))
+}
+
+func TestLogPartitionedByRecordTime(t *testing.T) {
+	logs := plog.NewLogs()
+	lrs := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty().SetTimestamp(pcommon.NewTimestampFromTime(earliestRecord))
+	lrs.AppendEmpty().SetTimestamp(pcommon.NewTimestampFromTime(latestRecord))
+
+	for _, tc := range []struct {
+		source   PartitionTimeSource
+		expected time.Time
+	}{
+		{source: "", expected: time.Time{}},
+		{source: PartitionTimeEarliestRecord, expected: earliestRecord},
+		{source: PartitionTimeLatestRecord, expected: latestRecord},
+	} {
+		t.Run(string(tc.source), func(t *testing.T) {
+			writer := &objectsWriter{}
+			exporter := getSplitExporter(t, 0, writer)
+			exporter.config.S3Uploader.S3PartitionTimeSource = tc.source
+			require.NoError(t, exporter.ConsumeLogs(context.Background(), logs))
+			require.Len(t, writer.opts, 1)
+			assert.Equal(t, tc.expected, writer.opts[0].PartitionTime)
+		})
+	}
+}
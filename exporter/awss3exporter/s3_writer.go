@@ -12,23 +12,32 @@ import (
 	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
 )
 
-func newUploadManager(
-	ctx context.Context,
-	conf *Config,
-	metadata string,
-	format string,
-) (upload.Manager, error) {
+// loadAWSConfig loads the AWS configuration shared by the clients of the exporter.
+func loadAWSConfig(ctx context.Context, conf *Config) (aws.Config, error) {
 	configOpts := []func(*config.LoadOptions) error{}
 
 	if region := conf.S3Uploader.Region; region != "" {
 		configOpts = append(configOpts, config.WithRegion(region))
 	}
 
+	if profile := conf.S3Uploader.AWSProfile; profile != "" {
+		configOpts = append(configOpts, config.WithSharedConfigProfile(profile))
+	}
+	if files := conf.S3Uploader.SharedConfigFiles; len(files) > 0 {
+		configOpts = append(configOpts, config.WithSharedConfigFiles(files))
+	}
+	if files := conf.S3Uploader.SharedCredentialsFiles; len(files) > 0 {
+		configOpts = append(configOpts, config.WithSharedCredentialsFiles(files))
+	}
+
 	switch conf.S3Uploader.RetryMode {
 	case "nop":
 		configOpts = append(configOpts, config.WithRetryer(func() aws.Retryer {
@@ -38,7 +47,48 @@ func newUploadManager(
 		configOpts = append(configOpts, config.WithRetryMode(aws.RetryMode(conf.S3Uploader.RetryMode)))
 	}
 
-	cfg, err := config.LoadDefaultConfig(ctx, configOpts...)
+	return config.LoadDefaultConfig(ctx, configOpts...)
+}
+
+// newRoleCredentials returns the credentials of the role conf.RoleArn, assumed
+// with the web identity token file when set, with the credentials of cfg otherwise.
+func newRoleCredentials(cfg aws.Config, conf *S3UploaderConfig) aws.CredentialsProvider {
+	client := sts.NewFromConfig(cfg, func(o *sts.Options) {
+		if conf.STSRegion != "" {
+			o.Region = conf.STSRegion
+		}
+		if conf.STSEndpoint != "" {
+			o.BaseEndpoint = aws.String(conf.STSEndpoint)
+		}
+	})
+
+	if conf.WebIdentityTokenFile != "" {
+		return aws.NewCredentialsCache(stscreds.NewWebIdentityRoleProvider(
+			client,
+			conf.RoleArn,
+			stscreds.IdentityTokenFile(conf.WebIdentityTokenFile),
+			func(o *stscreds.WebIdentityRoleOptions) {
+				o.RoleSessionName = conf.RoleSessionName
+				o.Duration = conf.RoleSessionDuration
+			},
+		))
+	}
+
+	return aws.NewCredentialsCache(stscreds.NewAssumeRoleProvider(client, conf.RoleArn, func(o *stscreds.AssumeRoleOptions) {
+		if conf.ExternalID != "" {
+			o.ExternalID = aws.String(conf.ExternalID)
+		}
+		if conf.RoleSessionName != "" {
+			o.RoleSessionName = conf.RoleSessionName
+		}
+		if conf.RoleSessionDuration != 0 {
+			o.Duration = conf.RoleSessionDuration
+		}
+	}))
+}
+
+func newS3Client(ctx context.Context, conf *Config) (*s3.Client, error) {
+	cfg, err := loadAWSConfig(ctx, conf)
 	if err != nil {
 		return nil, err
 	}
@@ -60,9 +110,10 @@ func newUploadManager(
 		})
 	}
 
-	if arn := conf.S3Uploader.RoleArn; arn != "" {
+	if conf.S3Uploader.RoleArn != "" {
+		credentials := newRoleCredentials(cfg, &conf.S3Uploader)
 		s3Opts = append(s3Opts, func(o *s3.Options) {
-			o.Credentials = stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), arn)
+			o.Credentials = credentials
 		})
 	}
 
@@ -72,33 +123,236 @@ func newUploadManager(
 		})
 	}
 
-	var managerOpts []upload.ManagerOpt
-	if conf.S3Uploader.ACL != "" {
-		managerOpts = append(managerOpts,
-			upload.WithACL(s3types.ObjectCannedACL(conf.S3Uploader.ACL)))
+	return s3.NewFromConfig(cfg, s3Opts...), nil
+}
+
+// newNotifier returns the notifier of the uploaded objects, nil when no
+// notification destination is configured.
+func newNotifier(ctx context.Context, conf *Config) (upload.Notifier, error) {
+	queueURL, topicARN := conf.Notification.SQSQueueURL, conf.Notification.SNSTopicARN
+	if queueURL == "" && topicARN == "" {
+		return nil, nil
 	}
+	cfg, err := loadAWSConfig(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+	if conf.S3Uploader.RoleArn != "" {
+		cfg.Credentials = newRoleCredentials(cfg, &conf.S3Uploader)
+	}
+	if queueURL != "" {
+		return upload.NewSQSNotifier(sqs.NewFromConfig(cfg), queueURL), nil
+	}
+	return upload.NewSNSNotifier(sns.NewFromConfig(cfg), topicARN), nil
+}
 
+func newPartitionKeyBuilder(conf *Config, metadata, format string) *upload.PartitionKeyBuilder {
 	var uniqueKeyFunc func() string
+	var objectKeyFunc func(string, []byte) string
 	switch conf.S3Uploader.UniqueKeyFuncName {
 	case "uuidv7":
 		uniqueKeyFunc = upload.GenerateUUIDv7
+	case "uuidv5":
+		objectKeyFunc = upload.GenerateUUIDv5
+	case "sequence":
+		objectKeyFunc = upload.NewSequenceKeys().Next
 	default:
 		uniqueKeyFunc = nil
 	}
 
+	return &upload.PartitionKeyBuilder{
+		PartitionPrefix: conf.S3Uploader.S3Prefix,
+		PartitionFormat: conf.S3Uploader.partitionFormat(metadata),
+		FilePrefix:      conf.S3Uploader.FilePrefix,
+		Metadata:        metadata,
+		FileFormat:      format,
+		Compression:     conf.S3Uploader.Compression,
+		UniqueKeyFunc:   uniqueKeyFunc,
+		ObjectKeyFunc:   objectKeyFunc,
+	}
+}
+
+func newUploadManager(
+	ctx context.Context,
+	conf *Config,
+	metadata string,
+	format string,
+	opts ...upload.ManagerOpt,
+) (upload.Manager, error) {
+	client, err := newS3Client(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	var managerOpts []upload.ManagerOpt
+	if acl := conf.S3Uploader.acl(); acl != "" {
+		managerOpts = append(managerOpts,
+			upload.WithACL(s3types.ObjectCannedACL(acl)))
+	}
+	if payer := conf.S3Uploader.requestPayer(); payer != "" {
+		managerOpts = append(managerOpts,
+			upload.WithRequestPayer(payer))
+	}
+	if sse := conf.S3Uploader.ServerSideEncryption; sse != "" {
+		managerOpts = append(managerOpts,
+			upload.WithServerSideEncryption(s3types.ServerSideEncryption(sse), conf.S3Uploader.SSEKMSKeyID, conf.S3Uploader.BucketKeyEnabled))
+	}
+
+	if mode := conf.S3Uploader.ObjectLockMode; mode != "" {
+		managerOpts = append(managerOpts,
+			upload.WithObjectLock(s3types.ObjectLockMode(mode), conf.S3Uploader.ObjectLockRetention))
+	}
+
+	if dir := conf.S3Uploader.LocalDirectory; dir != "" {
+		managerOpts = append(managerOpts,
+			upload.WithLocalDirectory(dir))
+	}
+
+	if conf.S3Uploader.MaxConcurrentUploads > 0 {
+		managerOpts = append(managerOpts,
+			upload.WithMaxConcurrentUploads(conf.S3Uploader.MaxConcurrentUploads))
+	}
+	if conf.S3Uploader.UploadTimeout > 0 {
+		managerOpts = append(managerOpts,
+			upload.WithUploadTimeout(conf.S3Uploader.UploadTimeout))
+	}
+
+	if conf.S3Uploader.SurfaceThrottling {
+		managerOpts = append(managerOpts,
+			upload.WithThrottleSurfacing())
+	}
+
+	if conf.S3Uploader.CompressPerRecord {
+		managerOpts = append(managerOpts,
+			upload.WithPrecompressed())
+	}
+	if conf.S3Uploader.CompressionMinSize > 0 {
+		managerOpts = append(managerOpts,
+			upload.WithCompressionMinSize(conf.S3Uploader.CompressionMinSize))
+	}
+	if len(conf.S3Uploader.ObjectTags) > 0 {
+		managerOpts = append(managerOpts,
+			upload.WithObjectTags(conf.S3Uploader.ObjectTags))
+	}
+	if len(conf.S3Uploader.ObjectMetadata) > 0 {
+		managerOpts = append(managerOpts,
+			upload.WithObjectMetadata(conf.S3Uploader.ObjectMetadata))
+	}
+	if contentType := conf.contentType(metadata); contentType != "" {
+		managerOpts = append(managerOpts,
+			upload.WithContentType(contentType))
+	}
+
+	managerOpts = append(managerOpts, opts...)
+
 	return upload.NewS3Manager(
 		conf.S3Uploader.S3Bucket,
-		&upload.PartitionKeyBuilder{
-			PartitionPrefix: conf.S3Uploader.S3Prefix,
-			PartitionFormat: conf.S3Uploader.S3PartitionFormat,
-			FilePrefix:      conf.S3Uploader.FilePrefix,
-			Metadata:        metadata,
-			FileFormat:      format,
-			Compression:     conf.S3Uploader.Compression,
-			UniqueKeyFunc:   uniqueKeyFunc,
-		},
-		s3.NewFromConfig(cfg, s3Opts...),
+		newPartitionKeyBuilder(conf, metadata, format),
+		client,
 		s3types.StorageClass(conf.S3Uploader.StorageClass),
 		managerOpts...,
 	), nil
 }
+
+func newConsolidator(
+	ctx context.Context,
+	conf *Config,
+	metadata string,
+	format string,
+	logger *zap.Logger,
+) (*upload.Consolidator, error) {
+	client, err := newS3Client(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []upload.ConsolidatorOpt{
+		upload.WithConsolidatedEncryption(s3types.ServerSideEncryption(conf.S3Uploader.ServerSideEncryption), conf.S3Uploader.SSEKMSKeyID, conf.S3Uploader.BucketKeyEnabled),
+		upload.WithConsolidatedTags(conf.S3Uploader.ObjectTags, conf.S3Uploader.ObjectMetadata),
+		upload.WithConsolidatedContentType(conf.contentType(metadata)),
+		upload.WithConsolidatedRequestPayer(conf.S3Uploader.requestPayer()),
+	}
+	// the framed records are merged as they are, a newline would corrupt the
+	// length prefixed ones
+	if format == "json" && conf.Framing == "" {
+		opts = append(opts, upload.WithLineDelimitedParts())
+	}
+
+	return upload.NewConsolidator(
+		conf.S3Uploader.S3Bucket,
+		newPartitionKeyBuilder(conf, metadata, format),
+		client,
+		s3types.StorageClass(conf.S3Uploader.StorageClass),
+		conf.Consolidation.Delay,
+		logger,
+		opts...,
+	), nil
+}
+
+// newAggregator aggregates the payloads uploaded through next, keeping the JSON
+// documents of the unframed payloads on separate lines as the consolidation does.
+// The framed and the compressed payloads are concatenated as they are, a newline
+// would corrupt the length prefixed records and the stream of gzip members.
+func newAggregator(next upload.Manager, conf *Config, format string, logger *zap.Logger) *upload.Aggregator {
+	var opts []upload.AggregatorOpt
+	if format == "json" && conf.Framing == "" && !conf.S3Uploader.CompressPerRecord {
+		opts = append(opts, upload.WithLineDelimitedPayloads())
+	}
+	if dir := conf.Aggregation.SpillDirectory; dir != "" {
+		opts = append(opts, upload.WithSpill(dir, conf.Aggregation.MaxSpillSize))
+	}
+	return upload.NewAggregator(
+		next,
+		conf.Aggregation.MaxSize,
+		conf.Aggregation.Interval,
+		conf.Aggregation.MaxMemorySize,
+		logger,
+		opts...,
+	)
+}
+
+func newBucketSettings(conf *Config) upload.BucketSettings {
+	settings := upload.BucketSettings{
+		Region:    conf.S3Uploader.Region,
+		SSE:       s3types.ServerSideEncryption(conf.S3Uploader.ServerSideEncryption),
+		KMSKeyID:  conf.S3Uploader.SSEKMSKeyID,
+		BucketKey: conf.S3Uploader.BucketKeyEnabled,
+		// the uploads of locked objects fail on buckets without Object Lock
+		ObjectLock:      conf.S3Uploader.ObjectLockMode != "",
+		ObjectOwnership: s3types.ObjectOwnership(conf.S3Uploader.ObjectOwnership),
+	}
+	for _, r := range conf.S3Uploader.BucketLifecycleRules {
+		prefix := r.Prefix
+		if prefix == "" {
+			prefix = conf.S3Uploader.S3Prefix
+			if t, err := parsePrefixTemplate(prefix); err == nil && t != nil {
+				prefix = t.staticPrefix()
+			}
+		}
+		rule := s3types.LifecycleRule{
+			ID:     aws.String(r.ID),
+			Status: s3types.ExpirationStatusEnabled,
+			Filter: &s3types.LifecycleRuleFilter{Prefix: aws.String(prefix)},
+		}
+		if r.ExpirationDays > 0 {
+			rule.Expiration = &s3types.LifecycleExpiration{Days: aws.Int32(r.ExpirationDays)}
+		}
+		if r.TransitionDays > 0 {
+			rule.Transitions = []s3types.Transition{{
+				Days:         aws.Int32(r.TransitionDays),
+				StorageClass: s3types.TransitionStorageClass(r.TransitionStorageClass),
+			}}
+		}
+		settings.LifecycleRules = append(settings.LifecycleRules, rule)
+	}
+	return settings
+}
+
+func ensureBucket(ctx context.Context, conf *Config, logger *zap.Logger) error {
+	client, err := newS3Client(ctx, conf)
+	if err != nil {
+		return err
+	}
+
+	return upload.EnsureBucket(ctx, client, conf.S3Uploader.S3Bucket, newBucketSettings(conf), logger)
+}
@@ -4,11 +4,30 @@
 package awss3exporter
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/config/configcompression"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
 )
 
 func TestNewUploadManager(t *testing.T) {
@@ -58,3 +77,276 @@ func TestNewUploadManager(t *testing.T) {
 		})
 	}
 }
+
+func TestNewPartitionKeyBuilderPartitionScheme(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, time.June, 1, 13, 45, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		name     string
+		uploader S3UploaderConfig
+		expected string
+	}{
+		{
+			name:     "strftime",
+			uploader: S3UploaderConfig{S3Prefix: "otel", S3PartitionFormat: "%Y/%m/%d/%H/%M"},
+			expected: "otel/2024/06/01/13/45",
+		},
+		{
+			name:     "hive",
+			uploader: S3UploaderConfig{S3Prefix: "otel", S3PartitionFormat: "%Y/%m/%d/%H/%M", S3PartitionScheme: PartitionSchemeHive},
+			expected: "otel/year=2024/month=06/day=01/hour=13",
+		},
+		{
+			name:     "hive by signal",
+			uploader: S3UploaderConfig{S3Prefix: "otel", S3PartitionScheme: PartitionSchemeHive, S3PartitionBySignal: true},
+			expected: "otel/signal=traces/year=2024/month=06/day=01/hour=13",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			builder := newPartitionKeyBuilder(&Config{S3Uploader: tc.uploader}, "traces", "json")
+			key := builder.Build(ts, "")
+			assert.True(t, strings.HasPrefix(key, tc.expected+"/traces_"), "unexpected key %q", key)
+		})
+	}
+}
+
+func TestNewBucketSettings(t *testing.T) {
+	t.Parallel()
+
+	conf := &Config{
+		S3Uploader: S3UploaderConfig{
+			Region:               "eu-central-1",
+			S3Bucket:             "my-awesome-bucket",
+			S3Prefix:             "opentelemetry",
+			ServerSideEncryption: "aws:kms",
+			SSEKMSKeyID:          "key",
+			BucketKeyEnabled:     true,
+			EnsureBucket:         true,
+			BucketLifecycleRules: []BucketLifecycleRule{
+				{ID: "expire", ExpirationDays: 30},
+				{ID: "archive", Prefix: "archive/", TransitionDays: 7, TransitionStorageClass: "GLACIER"},
+			},
+		},
+	}
+
+	settings := newBucketSettings(conf)
+	assert.Equal(t, upload.BucketSettings{
+		Region:    "eu-central-1",
+		SSE:       s3types.ServerSideEncryptionAwsKms,
+		KMSKeyID:  "key",
+		BucketKey: true,
+		LifecycleRules: []s3types.LifecycleRule{
+			{
+				ID:         aws.String("expire"),
+				Status:     s3types.ExpirationStatusEnabled,
+				Filter:     &s3types.LifecycleRuleFilter{Prefix: aws.String("opentelemetry")},
+				Expiration: &s3types.LifecycleExpiration{Days: aws.Int32(30)},
+			},
+			{
+				ID:     aws.String("archive"),
+				Status: s3types.ExpirationStatusEnabled,
+				Filter: &s3types.LifecycleRuleFilter{Prefix: aws.String("archive/")},
+				Transitions: []s3types.Transition{{
+					Days:         aws.Int32(7),
+					StorageClass: s3types.TransitionStorageClassGlacier,
+				}},
+			},
+		},
+	}, settings)
+}
+
+func TestNewBucketSettingsPrefixTemplate(t *testing.T) {
+	t.Parallel()
+
+	conf := &Config{
+		S3Uploader: S3UploaderConfig{
+			S3Bucket:             "my-awesome-bucket",
+			S3Prefix:             "opentelemetry/{service.name}",
+			EnsureBucket:         true,
+			BucketLifecycleRules: []BucketLifecycleRule{{ID: "expire", ExpirationDays: 30}},
+		},
+	}
+
+	settings := newBucketSettings(conf)
+	assert.Len(t, settings.LifecycleRules, 1)
+	assert.Equal(t, &s3types.LifecycleRuleFilter{Prefix: aws.String("opentelemetry/")}, settings.LifecycleRules[0].Filter)
+}
+
+func TestLoadAWSConfigProfile(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(configFile, []byte("[profile telemetry]\nregion = ap-south-1\n"), 0o600))
+	credentialsFile := filepath.Join(dir, "credentials")
+	require.NoError(t, os.WriteFile(credentialsFile,
+		[]byte("[telemetry]\naws_access_key_id = AKID\naws_secret_access_key = SECRET\n"), 0o600))
+
+	cfg, err := loadAWSConfig(context.Background(), &Config{
+		S3Uploader: S3UploaderConfig{
+			AWSProfile:             "telemetry",
+			SharedConfigFiles:      []string{configFile},
+			SharedCredentialsFiles: []string{credentialsFile},
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ap-south-1", cfg.Region)
+	creds, err := cfg.Credentials.Retrieve(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "AKID", creds.AccessKeyID)
+	assert.Equal(t, "SECRET", creds.SecretAccessKey)
+
+	_, err = loadAWSConfig(context.Background(), &Config{
+		S3Uploader: S3UploaderConfig{
+			AWSProfile:        "unknown",
+			SharedConfigFiles: []string{configFile},
+		},
+	})
+	assert.ErrorContains(t, err, "unknown")
+}
+
+func TestNewRoleCredentials(t *testing.T) {
+	t.Parallel()
+
+	newSTS := func(t *testing.T) (*httptest.Server, chan url.Values) {
+		requests := make(chan url.Values, 1)
+		s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !assert.NoError(t, r.ParseForm()) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			requests <- r.PostForm
+			action := r.PostForm.Get("Action")
+			w.Header().Set("Content-Type", "text/xml")
+			_, _ = fmt.Fprintf(w, `<%[1]sResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/"><%[1]sResult><Credentials>`+
+				`<AccessKeyId>AKID</AccessKeyId><SecretAccessKey>SECRET</SecretAccessKey><SessionToken>TOKEN</SessionToken>`+
+				`<Expiration>2100-01-01T00:00:00Z</Expiration></Credentials></%[1]sResult></%[1]sResponse>`, action)
+		}))
+		t.Cleanup(s.Close)
+		return s, requests
+	}
+	cfg := aws.Config{
+		Region:      "us-east-1",
+		Credentials: credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	}
+
+	t.Run("assume role", func(t *testing.T) {
+		t.Parallel()
+
+		s, requests := newSTS(t)
+		creds, err := newRoleCredentials(cfg, &S3UploaderConfig{
+			RoleArn:             "arn:aws:iam::123456789012:role/exporter",
+			ExternalID:          "my-external-id",
+			RoleSessionName:     "otel-exporter",
+			RoleSessionDuration: time.Hour,
+			STSRegion:           "eu-west-1",
+			STSEndpoint:         s.URL,
+		}).Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "AKID", creds.AccessKeyID)
+
+		form := <-requests
+		assert.Equal(t, "AssumeRole", form.Get("Action"))
+		assert.Equal(t, "arn:aws:iam::123456789012:role/exporter", form.Get("RoleArn"))
+		assert.Equal(t, "my-external-id", form.Get("ExternalId"))
+		assert.Equal(t, "otel-exporter", form.Get("RoleSessionName"))
+		assert.Equal(t, "3600", form.Get("DurationSeconds"))
+	})
+
+	t.Run("web identity", func(t *testing.T) {
+		t.Parallel()
+
+		tokenFile := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenFile, []byte("my-token"), 0o600))
+
+		s, requests := newSTS(t)
+		creds, err := newRoleCredentials(cfg, &S3UploaderConfig{
+			RoleArn:              "arn:aws:iam::123456789012:role/exporter",
+			WebIdentityTokenFile: tokenFile,
+			STSEndpoint:          s.URL,
+		}).Retrieve(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, "TOKEN", creds.SessionToken)
+
+		form := <-requests
+		assert.Equal(t, "AssumeRoleWithWebIdentity", form.Get("Action"))
+		assert.Equal(t, "arn:aws:iam::123456789012:role/exporter", form.Get("RoleArn"))
+		assert.Equal(t, "my-token", form.Get("WebIdentityToken"))
+	})
+}
+
+// objectRecorder records the objects uploaded by an aggregator.
+type objectRecorder struct {
+	objects [][]byte
+}
+
+func (r *objectRecorder) Upload(_ context.Context, data []byte, _ *upload.UploadOptions) error {
+	r.objects = append(r.objects, bytes.Clone(data))
+	return nil
+}
+
+// aggregate returns the object uploaded by the aggregator of conf for payloads.
+func aggregate(t *testing.T, conf *Config, format string, payloads ...[]byte) []byte {
+	recorder := &objectRecorder{}
+	a := newAggregator(recorder, conf, format, zap.NewNop())
+	for _, p := range payloads {
+		require.NoError(t, a.Upload(context.Background(), p, nil))
+	}
+	require.NoError(t, a.Shutdown(context.Background()))
+	require.Len(t, recorder.objects, 1)
+	return recorder.objects[0]
+}
+
+func TestNewAggregatorPayloads(t *testing.T) {
+	t.Run("json documents on separate lines", func(t *testing.T) {
+		conf := createDefaultConfig().(*Config)
+		object := aggregate(t, conf, "json", []byte(`{"a":1}`), []byte(`{"b":1}`))
+		assert.Equal(t, "{\"a\":1}\n{\"b\":1}\n", string(object))
+	})
+
+	t.Run("length prefixed records", func(t *testing.T) {
+		conf := createDefaultConfig().(*Config)
+		conf.Framing = FramingLengthPrefixed
+		frame := func(record string) []byte {
+			return append(binary.AppendUvarint(nil, uint64(len(record))), record...)
+		}
+		object := aggregate(t, conf, "json", frame(`{"a":1}`), frame(`{"b":1}`))
+
+		var records []string
+		for r := bytes.NewReader(object); r.Len() > 0; {
+			size, err := binary.ReadUvarint(r)
+			require.NoError(t, err)
+			record := make([]byte, size)
+			_, err = r.Read(record)
+			require.NoError(t, err)
+			records = append(records, string(record))
+		}
+		assert.Equal(t, []string{`{"a":1}`, `{"b":1}`}, records)
+	})
+	t.Run("records compressed as gzip members", func(t *testing.T) {
+		conf := createDefaultConfig().(*Config)
+		conf.S3Uploader.Compression = configcompression.TypeGzip
+		conf.S3Uploader.CompressPerRecord = true
+		m, err := newMarshaler(OtlpJSON, zap.NewNop())
+		require.NoError(t, err)
+		compressing := newRecordCompressingMarshaler(m)
+		logs := newFramingTestLogs()
+		first, err := compressing.MarshalLogs(logs)
+		require.NoError(t, err)
+		second, err := compressing.MarshalLogs(logs)
+		require.NoError(t, err)
+		object := aggregate(t, conf, compressing.format(), first, second)
+
+		// the object is a valid stream of gzip members, decompressed as a whole
+		decompress := func(data []byte) []byte {
+			reader, err := gzip.NewReader(bytes.NewReader(data))
+			require.NoError(t, err)
+			raw, err := io.ReadAll(reader)
+			require.NoError(t, err)
+			return raw
+		}
+		payload := decompress(first)
+		assert.Equal(t, append(bytes.Clone(payload), payload...), decompress(object))
+	})
+}
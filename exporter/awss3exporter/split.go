@@ -0,0 +1,159 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
+)
+
+// signal gives access to the resources of the data of a signal, so that it can be
+// split at their boundaries.
+type signal[T any] struct {
+	// resources returns the number of resources of data.
+	resources func(data T) int
+	// resource returns the i-th resource of data.
+	resource func(data T, i int) pcommon.Resource
+	// slice copies the resources [from, to) of data.
+	slice func(data T, from, to int) T
+	// items returns the number of log records, data points or spans of data.
+	items func(data T) int
+	// partial wraps the error of an upload with the data left to export.
+	partial func(err error, data T) error
+	// timeRange returns the range of the timestamps of the records of data.
+	timeRange func(data T) timeRange
+}
+
+var logsSignal = signal[plog.Logs]{
+	resources: func(ld plog.Logs) int { return ld.ResourceLogs().Len() },
+	resource:  func(ld plog.Logs, i int) pcommon.Resource { return ld.ResourceLogs().At(i).Resource() },
+	slice: func(ld plog.Logs, from, to int) plog.Logs {
+		part := plog.NewLogs()
+		for i := from; i < to; i++ {
+			ld.ResourceLogs().At(i).CopyTo(part.ResourceLogs().AppendEmpty())
+		}
+		return part
+	},
+	items: func(ld plog.Logs) int { return ld.LogRecordCount() },
+	partial: func(err error, ld plog.Logs) error {
+		return consumererror.NewLogs(err, ld)
+	},
+	timeRange: logsTimeRange,
+}
+
+var metricsSignal = signal[pmetric.Metrics]{
+	resources: func(md pmetric.Metrics) int { return md.ResourceMetrics().Len() },
+	resource:  func(md pmetric.Metrics, i int) pcommon.Resource { return md.ResourceMetrics().At(i).Resource() },
+	slice: func(md pmetric.Metrics, from, to int) pmetric.Metrics {
+		part := pmetric.NewMetrics()
+		for i := from; i < to; i++ {
+			md.ResourceMetrics().At(i).CopyTo(part.ResourceMetrics().AppendEmpty())
+		}
+		return part
+	},
+	items: func(md pmetric.Metrics) int { return md.DataPointCount() },
+	partial: func(err error, md pmetric.Metrics) error {
+		return consumererror.NewMetrics(err, md)
+	},
+	timeRange: metricsTimeRange,
+}
+
+var tracesSignal = signal[ptrace.Traces]{
+	resources: func(td ptrace.Traces) int { return td.ResourceSpans().Len() },
+	resource:  func(td ptrace.Traces, i int) pcommon.Resource { return td.ResourceSpans().At(i).Resource() },
+	slice: func(td ptrace.Traces, from, to int) ptrace.Traces {
+		part := ptrace.NewTraces()
+		for i := from; i < to; i++ {
+			td.ResourceSpans().At(i).CopyTo(part.ResourceSpans().AppendEmpty())
+		}
+		return part
+	},
+	items: func(td ptrace.Traces) int { return td.SpanCount() },
+	partial: func(err error, td ptrace.Traces) error {
+		return consumererror.NewTraces(err, td)
+	},
+	timeRange: tracesTimeRange,
+}
+
+// objectPart is a part of the data of a signal uploaded as an object of its own.
+type objectPart[T any] struct {
+	data T
+	// from is the index of the first resource of the part in the data it was split from.
+	from int
+	buf  []byte
+}
+
+// export marshals and uploads data, split into several objects at the resource
+// boundaries when it marshals to more than MaxObjectSize bytes. The objects are
+// uploaded in order, the data of the ones left to upload being returned with the
+// error of a failed upload so that only they are retried.
+func export[T any](ctx context.Context, e *s3Exporter, data T, s signal[T], marshal func(T) ([]byte, error)) error {
+	buf, err := marshal(data)
+	if err != nil {
+		return err
+	}
+
+	maxSize := e.config.S3Uploader.MaxObjectSize
+	if maxSize <= 0 || int64(len(buf)) <= maxSize || s.resources(data) < 2 {
+		return e.upload(ctx, s.items(data), buf, uploadOptions(ctx, e, data, s))
+	}
+
+	parts, err := split(e, objectPart[T]{data: data, buf: buf}, s, marshal, nil)
+	if err != nil {
+		return err
+	}
+	for i, part := range parts {
+		if err := e.upload(ctx, s.items(part.data), part.buf, uploadOptions(ctx, e, part.data, s)); err != nil {
+			if i == 0 {
+				return err
+			}
+			return s.partial(err, s.slice(data, part.from, s.resources(data)))
+		}
+	}
+	return nil
+}
+
+// uploadOptions returns the options of the upload of data, routed by its first
+// resource and partitioned by the time of its records when configured so.
+func uploadOptions[T any](ctx context.Context, e *s3Exporter, data T, s signal[T]) *upload.UploadOptions {
+	uploadOpts := e.getUploadOpts(ctx, s.resource(data, 0))
+	switch source := e.config.S3Uploader.S3PartitionTimeSource; source {
+	case PartitionTimeEarliestRecord, PartitionTimeLatestRecord:
+		uploadOpts.PartitionTime = s.timeRange(data).partitionTime(source)
+	}
+	return uploadOpts
+}
+
+// split halves part at its resource boundaries until every part marshals to at most
+// MaxObjectSize bytes or holds a single resource, and appends them to parts in order.
+func split[T any](e *s3Exporter, part objectPart[T], s signal[T], marshal func(T) ([]byte, error), parts []objectPart[T]) ([]objectPart[T], error) {
+	n := s.resources(part.data)
+	if int64(len(part.buf)) <= e.config.S3Uploader.MaxObjectSize {
+		return append(parts, part), nil
+	}
+	if n < 2 {
+		e.logger.Warn("Uploading a resource larger than max_object_size as an object of its own",
+			zap.Int("size", len(part.buf)), zap.Int64("max_object_size", e.config.S3Uploader.MaxObjectSize))
+		return append(parts, part), nil
+	}
+	for _, half := range [][2]int{{0, n / 2}, {n / 2, n}} {
+		data := s.slice(part.data, half[0], half[1])
+		buf, err := marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		if parts, err = split(e, objectPart[T]{data: data, from: part.from + half[0], buf: buf}, s, marshal, parts); err != nil {
+			return nil, err
+		}
+	}
+	return parts, nil
+}
@@ -0,0 +1,116 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
+)
+
+// objectsWriter records the uploaded objects, failing the uploads from failFrom on.
+type objectsWriter struct {
+	objects  [][]byte
+	opts     []*upload.UploadOptions
+	failFrom int
+}
+
+func (w *objectsWriter) Upload(_ context.Context, buf []byte, uploadOpts *upload.UploadOptions) error {
+	if w.failFrom > 0 && len(w.objects) >= w.failFrom {
+		return errors.New("upload failed")
+	}
+	w.objects = append(w.objects, buf)
+	w.opts = append(w.opts, uploadOpts)
+	return nil
+}
+
+func getSplitLogs(resources int) plog.Logs {
+	logs := plog.NewLogs()
+	for i := 0; i < resources; i++ {
+		rl := logs.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().PutStr("_sourceHost", fmt.Sprintf("host-%d", i))
+		lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+		lr.Body().SetStr(strings.Repeat("x", 1000))
+	}
+	return logs
+}
+
+func getSplitExporter(t *testing.T, maxObjectSize int64, writer *objectsWriter) *s3Exporter {
+	marshaler, err := newMarshaler("otlp_json", zap.NewNop())
+	require.NoError(t, err)
+	config := createDefaultConfig().(*Config)
+	config.S3Uploader.MaxObjectSize = maxObjectSize
+	config.ResourceAttrsToS3.S3Prefix = "_sourceHost"
+	return &s3Exporter{
+		config:    config,
+		uploader:  writer,
+		logger:    zap.NewNop(),
+		marshaler: marshaler,
+	}
+}
+
+func TestLogSplitByMaxObjectSize(t *testing.T) {
+	writer := &objectsWriter{}
+	exporter := getSplitExporter(t, 2500, writer)
+	require.NoError(t, exporter.ConsumeLogs(context.Background(), getSplitLogs(5)))
+
+	require.Len(t, writer.objects, 3)
+	var hosts []string
+	for i, object := range writer.objects {
+		assert.LessOrEqual(t, len(object), 2500)
+		logs, err := (&plog.JSONUnmarshaler{}).UnmarshalLogs(object)
+		require.NoError(t, err)
+		assert.Equal(t, logs.LogRecordCount(), writer.opts[i].Records)
+		for j := 0; j < logs.ResourceLogs().Len(); j++ {
+			host, _ := logs.ResourceLogs().At(j).Resource().Attributes().Get("_sourceHost")
+			hosts = append(hosts, host.Str())
+		}
+	}
+	assert.Equal(t, []string{"host-0", "host-1", "host-2", "host-3", "host-4"}, hosts, "the resources keep their order")
+	assert.Equal(t, "host-0", writer.opts[0].OverridePrefix)
+	assert.Equal(t, "host-2", writer.opts[1].OverridePrefix, "each object is routed by its first resource")
+}
+
+func TestLogSplitKeepsLargeResource(t *testing.T) {
+	writer := &objectsWriter{}
+	exporter := getSplitExporter(t, 100, writer)
+	require.NoError(t, exporter.ConsumeLogs(context.Background(), getSplitLogs(2)))
+
+	require.Len(t, writer.objects, 2, "a resource larger than max_object_size is not split")
+	for _, object := range writer.objects {
+		assert.Greater(t, len(object), 100)
+	}
+}
+
+func TestLogSplitNotNeeded(t *testing.T) {
+	writer := &objectsWriter{}
+	exporter := getSplitExporter(t, 1<<20, writer)
+	require.NoError(t, exporter.ConsumeLogs(context.Background(), getSplitLogs(5)))
+	require.Len(t, writer.objects, 1)
+}
+
+func TestLogSplitRetriesRemainingParts(t *testing.T) {
+	writer := &objectsWriter{failFrom: 2}
+	exporter := getSplitExporter(t, 1500, writer)
+	err := exporter.ConsumeLogs(context.Background(), getSplitLogs(4))
+	require.Error(t, err)
+	require.Len(t, writer.objects, 2)
+
+	var logsErr consumererror.Logs
+	require.ErrorAs(t, err, &logsErr)
+	remaining := logsErr.Data()
+	require.Equal(t, 2, remaining.ResourceLogs().Len(), "only the objects not uploaded are retried")
+	host, _ := remaining.ResourceLogs().At(0).Resource().Attributes().Get("_sourceHost")
+	assert.Equal(t, "host-2", host.Str())
+}
@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter // import "github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter"
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/awss3exporter/internal/upload"
+)
+
+// sseCustomerKeySize is the size, in bytes, of the keys of SSE-C.
+const sseCustomerKeySize = 32
+
+// SSECustomerKeyProvider is implemented by the extensions referenced by
+// sse_customer_key_provider, providing the 256-bit key the uploaded objects are
+// encrypted with. It is called for every upload, so that the key can be rotated.
+type SSECustomerKeyProvider interface {
+	SSECustomerKey(ctx context.Context) ([]byte, error)
+}
+
+// sseCustomerKeyEnabled tells whether the uploaded objects are encrypted with a
+// customer provided key.
+func (c *S3UploaderConfig) sseCustomerKeyEnabled() bool {
+	return c.SSECustomerKey != "" || c.SSECustomerKeyProvider != nil
+}
+
+// decodeSSECustomerKey decodes the base64 encoded SSE-C key.
+func decodeSSECustomerKey(key string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(raw) != sseCustomerKeySize {
+		return nil, fmt.Errorf("sse_customer_key must be a base64 encoded %d-bit key", sseCustomerKeySize*8)
+	}
+	return raw, nil
+}
+
+// newSSECustomerKey returns the function providing the SSE-C key of the uploads,
+// either the configured key or the one of the provider extension.
+func newSSECustomerKey(conf *S3UploaderConfig, host component.Host) (upload.SSECustomerKeyFunc, error) {
+	if conf.SSECustomerKeyProvider == nil {
+		raw, err := decodeSSECustomerKey(string(conf.SSECustomerKey))
+		if err != nil {
+			return nil, err
+		}
+		return func(context.Context) ([]byte, error) { return raw, nil }, nil
+	}
+	ext, ok := host.GetExtensions()[*conf.SSECustomerKeyProvider]
+	if !ok {
+		return nil, fmt.Errorf("unknown sse_customer_key_provider %q", conf.SSECustomerKeyProvider)
+	}
+	provider, ok := ext.(SSECustomerKeyProvider)
+	if !ok {
+		return nil, fmt.Errorf("extension %q does not provide SSE-C keys", conf.SSECustomerKeyProvider)
+	}
+	return provider.SSECustomerKey, nil
+}
@@ -0,0 +1,66 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package awss3exporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+type keyProviderExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+	key []byte
+}
+
+func (e keyProviderExtension) SSECustomerKey(context.Context) ([]byte, error) {
+	return e.key, nil
+}
+
+type hostWithKeyProvider struct {
+	provider component.Component
+}
+
+func (h hostWithKeyProvider) GetExtensions() map[component.ID]component.Component {
+	return map[component.ID]component.Component{
+		component.MustNewID("keys"): h.provider,
+	}
+}
+
+func TestNewSSECustomerKey(t *testing.T) {
+	id := component.MustNewID("keys")
+
+	{
+		conf := &S3UploaderConfig{SSECustomerKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="}
+		key, err := newSSECustomerKey(conf, componenttest.NewNopHost())
+		require.NoError(t, err)
+		raw, err := key(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []byte("0123456789abcdef0123456789abcdef"), raw)
+	}
+	{
+		conf := &S3UploaderConfig{SSECustomerKeyProvider: &id}
+		host := hostWithKeyProvider{provider: keyProviderExtension{key: []byte("fedcba9876543210fedcba9876543210")}}
+		key, err := newSSECustomerKey(conf, host)
+		require.NoError(t, err)
+		raw, err := key(context.Background())
+		require.NoError(t, err)
+		assert.Equal(t, []byte("fedcba9876543210fedcba9876543210"), raw)
+	}
+	{
+		conf := &S3UploaderConfig{SSECustomerKeyProvider: &id}
+		_, err := newSSECustomerKey(conf, componenttest.NewNopHost())
+		assert.EqualError(t, err, `unknown sse_customer_key_provider "keys"`)
+	}
+	{
+		conf := &S3UploaderConfig{SSECustomerKeyProvider: &id}
+		_, err := newSSECustomerKey(conf, hostWithKeyProvider{provider: encodingExtension{}})
+		assert.EqualError(t, err, `extension "keys" does not provide SSE-C keys`)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	gojson "github.com/goccy/go-json"
+	jsoniter "github.com/json-iterator/go"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -51,6 +52,9 @@ func BenchmarkUnmarshalLogs(b *testing.B) {
 		"1000_record": {
 			nRecords: 1_000,
 		},
+		"10000_record": {
+			nRecords: 10_000,
+		},
 	}
 
 	u := ResourceLogsUnmarshaler{
@@ -68,3 +72,37 @@ func BenchmarkUnmarshalLogs(b *testing.B) {
 		})
 	}
 }
+
+// BenchmarkDecodeRecords compares decoding the records one at a time,
+// as done by UnmarshalLogs, with materializing the whole records array.
+func BenchmarkDecodeRecords(b *testing.B) {
+	buf := newBuf(b, 10_000)
+
+	b.Run("materialized", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var records struct {
+				Records []azureLogRecord `json:"records"`
+			}
+			iter := jsoniter.ConfigFastest.BorrowIterator(buf)
+			iter.ReadVal(&records)
+			require.NoError(b, iter.Error)
+			jsoniter.ConfigFastest.ReturnIterator(iter)
+		}
+	})
+
+	b.Run("streaming", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			iter := jsoniter.ConfigFastest.BorrowIterator(buf)
+			for field := iter.ReadObject(); field != ""; field = iter.ReadObject() {
+				for iter.ReadArray() {
+					var record azureLogRecord
+					iter.ReadVal(&record)
+				}
+			}
+			require.NoError(b, iter.Error)
+			jsoniter.ConfigFastest.ReturnIterator(iter)
+		}
+	})
+}
@@ -8,10 +8,12 @@ import (
 	"fmt"
 	"net"
 	"net/url"
+	"regexp"
 	"strconv"
 	"strings"
 
 	gojson "github.com/goccy/go-json"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	conventions "go.opentelemetry.io/otel/semconv/v1.27.0"
 )
@@ -30,6 +32,13 @@ const (
 	categoryAppServiceHTTPLogs                 = "AppServiceHTTPLogs"
 	categoryAppServiceIPSecAuditLogs           = "AppServiceIPSecAuditLogs"
 	categoryAppServicePlatformLogs             = "AppServicePlatformLogs"
+	categoryKubeAudit                          = "kube-audit"
+	categoryKubeAuditAdmin                     = "kube-audit-admin"
+	categoryNetworkSecurityGroupFlowEvent      = "NetworkSecurityGroupFlowEvent"
+	categoryAdministrative                     = "Administrative"
+	categoryPolicy                             = "Policy"
+	categorySecurity                           = "Security"
+	categorySQLSecurityAuditEvents             = "SQLSecurityAuditEvents"
 
 	// attributeAzureRef holds the request tracking reference, also
 	// placed in the request header "X-Azure-Ref".
@@ -76,48 +85,202 @@ const (
 
 	// attributeAzureFrontDoorWAFAction holds the action taken on the request.
 	attributeAzureFrontDoorWAFAction = "azure.frontdoor.waf.action"
+
+	// attributeAzureFrontDoorWAFRuleSetName holds the name of the managed
+	// rule set of the WAF rule, e.g. Microsoft_DefaultRuleSet.
+	attributeAzureFrontDoorWAFRuleSetName = "azure.frontdoor.waf.rule.set.name"
+
+	// attributeAzureFrontDoorWAFRuleSetVersion holds the version of the
+	// managed rule set of the WAF rule.
+	attributeAzureFrontDoorWAFRuleSetVersion = "azure.frontdoor.waf.rule.set.version"
+
+	// attributeAzureFrontDoorWAFRuleGroup holds the group of the managed WAF
+	// rule, e.g. SQLI.
+	attributeAzureFrontDoorWAFRuleGroup = "azure.frontdoor.waf.rule.group"
+
+	// attributeAzureFrontDoorWAFRuleID holds the ID of the managed WAF rule.
+	attributeAzureFrontDoorWAFRuleID = "azure.frontdoor.waf.rule.id"
+
+	// attributeAzureFrontDoorWAFMatchVariableNames holds the names of the
+	// request variables that matched the WAF rule.
+	attributeAzureFrontDoorWAFMatchVariableNames = "azure.frontdoor.waf.match.variable.names"
+
+	// attributeAzureFrontDoorWAFMatchVariableValues holds the values of the
+	// request variables that matched the WAF rule, in the same order as
+	// their names.
+	attributeAzureFrontDoorWAFMatchVariableValues = "azure.frontdoor.waf.match.variable.values"
+
+	// attributeAzureFrontDoorWAFMessage holds the message describing the
+	// match of the WAF rule.
+	attributeAzureFrontDoorWAFMessage = "azure.frontdoor.waf.message"
+
+	// attributeAzureFrontDoorWAFData holds the data of the request that
+	// matched the WAF rule.
+	attributeAzureFrontDoorWAFData = "azure.frontdoor.waf.data"
 )
 
-var (
-	errStillToImplement    = errors.New("still to implement")
-	errUnsupportedCategory = errors.New("category not supported")
+const (
+	// network security group flow log attributes
+
+	// attributeAzureNSGRuleName holds the name of the rule that allowed
+	// or denied the flow.
+	attributeAzureNSGRuleName = "azure.nsg.rule.name"
+
+	// attributeAzureNSGFlowMAC holds the MAC address of the network
+	// interface the flow was collected on.
+	attributeAzureNSGFlowMAC = "azure.nsg.flow.mac"
+
+	// attributeAzureNSGFlowDirection holds the direction of the flow,
+	// either "inbound" or "outbound".
+	attributeAzureNSGFlowDirection = "azure.nsg.flow.direction"
+
+	// attributeAzureNSGFlowDecision holds whether the flow was "allow"ed
+	// or "deny"ed.
+	attributeAzureNSGFlowDecision = "azure.nsg.flow.decision"
+
+	// attributeAzureNSGFlowState holds the state of the flow, either
+	// "begin", "continue" or "end". Only present in version 2.
+	attributeAzureNSGFlowState = "azure.nsg.flow.state"
+
+	// attributeAzureNSGFlowPacketsSent holds the number of packets sent
+	// from source to destination since the last update.
+	attributeAzureNSGFlowPacketsSent = "azure.nsg.flow.packets.sent"
+
+	// attributeAzureNSGFlowBytesSent holds the number of bytes sent
+	// from source to destination since the last update.
+	attributeAzureNSGFlowBytesSent = "azure.nsg.flow.bytes.sent"
+
+	// attributeAzureNSGFlowPacketsReceived holds the number of packets
+	// sent from destination to source since the last update.
+	attributeAzureNSGFlowPacketsReceived = "azure.nsg.flow.packets.received"
+
+	// attributeAzureNSGFlowBytesReceived holds the number of bytes
+	// sent from destination to source since the last update.
+	attributeAzureNSGFlowBytesReceived = "azure.nsg.flow.bytes.received"
 )
 
-func addRecordAttributes(category string, data []byte, record plog.LogRecord) error {
-	var err error
+const (
+	// kubernetes audit attributes
 
-	switch category {
-	case categoryAzureCdnAccessLog:
-		err = addAzureCdnAccessLogProperties(data, record)
-	case categoryFrontDoorAccessLog:
-		err = addFrontDoorAccessLogProperties(data, record)
-	case categoryFrontDoorHealthProbeLog:
-		err = addFrontDoorHealthProbeLogProperties(data, record)
-	case categoryFrontdoorWebApplicationFirewallLog:
-		err = addFrontDoorWAFLogProperties(data, record)
-	case categoryAppServiceAppLogs:
-		err = addAppServiceAppLogsProperties(data, record)
-	case categoryAppServiceAuditLogs:
-		err = addAppServiceAuditLogsProperties(data, record)
-	case categoryAppServiceAuthenticationLogs:
-		err = addAppServiceAuthenticationLogsProperties(data, record)
-	case categoryAppServiceConsoleLogs:
-		err = addAppServiceConsoleLogsProperties(data, record)
-	case categoryAppServiceHTTPLogs:
-		err = addAppServiceHTTPLogsProperties(data, record)
-	case categoryAppServiceIPSecAuditLogs:
-		err = addAppServiceIPSecAuditLogsProperties(data, record)
-	case categoryAppServicePlatformLogs:
-		err = addAppServicePlatformLogsProperties(data, record)
-	default:
-		err = errUnsupportedCategory
-	}
+	// attributeK8sAuditID holds the unique audit ID generated for each request.
+	attributeK8sAuditID = "k8s.audit.id"
 
-	if err != nil {
-		return fmt.Errorf("failed to parse logs from category %q: %w", category, err)
-	}
-	return nil
-}
+	// attributeK8sAuditLevel holds the audit level the event was generated at.
+	attributeK8sAuditLevel = "k8s.audit.level"
+
+	// attributeK8sAuditStage holds the stage of the request handling when
+	// the event was generated.
+	attributeK8sAuditStage = "k8s.audit.stage"
+
+	// attributeK8sAuditVerb holds the Kubernetes verb associated with the
+	// request, e.g. "get", "list" or "create".
+	attributeK8sAuditVerb = "k8s.audit.verb"
+
+	// attributeK8sAuditUserName holds the name of the authenticated user
+	// that made the request.
+	attributeK8sAuditUserName = "k8s.audit.user.name"
+
+	// attributeK8sAuditUserGroups holds the groups the authenticated user
+	// is a member of.
+	attributeK8sAuditUserGroups = "k8s.audit.user.groups"
+
+	// attributeK8sAuditObjectResource holds the resource of the object
+	// targeted by the request, e.g. "pods".
+	attributeK8sAuditObjectResource = "k8s.audit.object.resource"
+
+	// attributeK8sAuditObjectSubresource holds the subresource of the
+	// object targeted by the request, e.g. "status".
+	attributeK8sAuditObjectSubresource = "k8s.audit.object.subresource"
+
+	// attributeK8sAuditObjectName holds the name of the object targeted
+	// by the request.
+	attributeK8sAuditObjectName = "k8s.audit.object.name"
+
+	// attributeK8sAuditObjectAPIGroup holds the API group of the object
+	// targeted by the request.
+	attributeK8sAuditObjectAPIGroup = "k8s.audit.object.api_group"
+
+	// attributeK8sAuditObjectAPIVersion holds the API version of the object
+	// targeted by the request.
+	attributeK8sAuditObjectAPIVersion = "k8s.audit.object.api_version"
+)
+
+const (
+	// activity log attributes
+
+	// attributeEndUserID holds the caller of the operation, the user
+	// principal name of a user or the application ID of a service principal.
+	attributeEndUserID = "enduser.id"
+
+	// attributeEndUserRole holds the role that granted the caller the
+	// permission to perform the operation.
+	attributeEndUserRole = "enduser.role"
+
+	// attributeAzureActivityIdentityAppID holds the ID of the application
+	// used to perform the operation, taken from the "appid" claim.
+	attributeAzureActivityIdentityAppID = "azure.activity.identity.app_id"
+
+	// attributeAzureActivityIdentityObjectID holds the object ID of the
+	// caller in Microsoft Entra ID, taken from the "oid" claim.
+	attributeAzureActivityIdentityObjectID = "azure.activity.identity.object_id"
+
+	// attributeAzureActivityAuthorizationAction holds the RBAC action the
+	// caller was authorized for, e.g. "Microsoft.Compute/virtualMachines/write".
+	attributeAzureActivityAuthorizationAction = "azure.activity.authorization.action"
+
+	// attributeAzureActivityAuthorizationScope holds the scope the caller
+	// was authorized on.
+	attributeAzureActivityAuthorizationScope = "azure.activity.authorization.scope"
+
+	// attributeAzureActivityStatus holds the status of the operation,
+	// e.g. "Started", "Succeeded" or "Failed".
+	attributeAzureActivityStatus = "azure.activity.status"
+
+	// attributeAzureActivitySubStatus holds the HTTP status of the
+	// operation, e.g. "Created" or "OK".
+	attributeAzureActivitySubStatus = "azure.activity.sub_status"
+)
+
+const (
+	// sql auditing attributes
+
+	// attributeAzureSQLAuditActionID holds the ID of the audited action,
+	// e.g. "BCM" for a completed batch.
+	attributeAzureSQLAuditActionID = "azure.sql.audit.action.id"
+
+	// attributeAzureSQLAuditActionName holds the name of the audited
+	// action, e.g. "BATCH COMPLETED".
+	attributeAzureSQLAuditActionName = "azure.sql.audit.action.name"
+
+	// attributeAzureSQLAuditSucceeded holds whether the audited action
+	// succeeded.
+	attributeAzureSQLAuditSucceeded = "azure.sql.audit.succeeded"
+
+	// attributeAzureSQLAuditClassType holds the type of the entity the
+	// action was performed on, e.g. "DATABASE" or "BATCH".
+	attributeAzureSQLAuditClassType = "azure.sql.audit.class_type"
+
+	// attributeAzureSQLAuditDatabasePrincipalName holds the database user
+	// the action was performed as.
+	attributeAzureSQLAuditDatabasePrincipalName = "azure.sql.audit.database_principal.name"
+
+	// attributeAzureSQLAuditApplicationName holds the name of the client
+	// application that performed the action.
+	attributeAzureSQLAuditApplicationName = "azure.sql.audit.application.name"
+
+	// attributeAzureSQLAuditSessionID holds the ID of the session the
+	// action was performed in.
+	attributeAzureSQLAuditSessionID = "azure.sql.audit.session.id"
+
+	// attributeAzureSQLAuditAffectedRows holds the number of rows
+	// affected by the action.
+	attributeAzureSQLAuditAffectedRows = "azure.sql.audit.affected_rows"
+)
+
+var (
+	errStillToImplement    = errors.New("still to implement")
+	errUnsupportedCategory = errors.New("category not supported")
+)
 
 // putInt parses value as an int and puts it in the record
 func putInt(field, value string, record plog.LogRecord) error {
@@ -208,6 +371,26 @@ func addRequestURIProperties(uri string, record plog.LogRecord) error {
 	return nil
 }
 
+// httpProtocolVersion normalizes the HTTP version, reported as a four
+// components version such as "2.0.0.0", to the form of the semantic
+// conventions: "1.0", "1.1", "2" or "3". A version that cannot be
+// parsed is returned as is.
+func httpProtocolVersion(version string) string {
+	parts := strings.Split(strings.TrimPrefix(version, "HTTP/"), ".")
+	for _, part := range parts {
+		if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+			return version
+		}
+	}
+	if parts[0] != "0" && parts[0] != "1" {
+		return parts[0]
+	}
+	if len(parts) == 1 {
+		return parts[0] + ".0"
+	}
+	return parts[0] + "." + parts[1]
+}
+
 // addSecurityProtocolProperties based on the security protocol
 func addSecurityProtocolProperties(securityProtocol string, record plog.LogRecord) error {
 	if securityProtocol == "" {
@@ -446,7 +629,7 @@ func addFrontDoorAccessLogProperties(data []byte, record plog.LogRecord) error {
 
 	putStr(attributeAzureRef, properties.TrackingReference, record)
 	putStr(string(conventions.HTTPRequestMethodKey), properties.HTTPMethod, record)
-	putStr(string(conventions.NetworkProtocolVersionKey), properties.HTTPVersion, record)
+	putStr(string(conventions.NetworkProtocolVersionKey), httpProtocolVersion(properties.HTTPVersion), record)
 	putStr(string(conventions.NetworkProtocolNameKey), properties.RequestProtocol, record)
 	putStr(attributeTLSServerName, properties.SNI, record)
 	putStr(string(conventions.UserAgentOriginalKey), properties.UserAgent, record)
@@ -481,14 +664,31 @@ type frontDoorWAFLogProperties struct {
 	Host              string `json:"host"`
 	TrackingReference string `json:"trackingReference"`
 	PolicyMode        string `json:"policyMode"`
+	Details           struct {
+		Matches []struct {
+			MatchVariableName  string `json:"matchVariableName"`
+			MatchVariableValue string `json:"matchVariableValue"`
+		} `json:"matches"`
+		Msg  string `json:"msg"`
+		Data string `json:"data"`
+	} `json:"details"`
 }
 
-// addFrontDoorWAFLogProperties parses the Front Door access log, and adds
-// the relevant attributes to the record
-func addFrontDoorWAFLogProperties(data []byte, record plog.LogRecord) error {
+// wafManagedRuleName matches the names of the rules of the managed rule
+// sets, made of the rule set name and version, the rule group, and the rule
+// ID, e.g. Microsoft_DefaultRuleSet-2.1-SQLI-942100.
+var wafManagedRuleName = regexp.MustCompile(`^(\w+RuleSet)-(\d+(?:\.\d+)*)-(\w+)-(\w+)$`)
+
+// frontDoorWAFLogProcessor maps the Front Door WAF records. Their severity is
+// Error when the request was blocked, and Info otherwise.
+type frontDoorWAFLogProcessor struct{}
+
+// AddAttributes parses the Front Door WAF log, and adds the relevant
+// attributes to the record.
+func (frontDoorWAFLogProcessor) AddAttributes(data []byte, record plog.LogRecord) error {
 	var properties frontDoorWAFLogProperties
 	if err := gojson.Unmarshal(data, &properties); err != nil {
-		return fmt.Errorf("failed to parse AzureCdnAccessLog properties: %w", err)
+		return fmt.Errorf("failed to parse FrontDoorWebApplicationFirewallLog properties: %w", err)
 	}
 
 	if err := putInt(string(conventions.ClientPortKey), properties.ClientPort, record); err != nil {
@@ -507,10 +707,47 @@ func addFrontDoorWAFLogProperties(data []byte, record plog.LogRecord) error {
 	putStr(attributeAzureFrontDoorWAFPolicyMode, properties.PolicyMode, record)
 	putStr(attributeAzureFrontDoorWAFRuleName, properties.RuleName, record)
 	putStr(attributeAzureFrontDoorWAFAction, properties.Action, record)
+	if parts := wafManagedRuleName.FindStringSubmatch(properties.RuleName); parts != nil {
+		putStr(attributeAzureFrontDoorWAFRuleSetName, parts[1], record)
+		putStr(attributeAzureFrontDoorWAFRuleSetVersion, parts[2], record)
+		putStr(attributeAzureFrontDoorWAFRuleGroup, parts[3], record)
+		putStr(attributeAzureFrontDoorWAFRuleID, parts[4], record)
+	}
+
+	if len(properties.Details.Matches) > 0 {
+		names := record.Attributes().PutEmptySlice(attributeAzureFrontDoorWAFMatchVariableNames)
+		values := record.Attributes().PutEmptySlice(attributeAzureFrontDoorWAFMatchVariableValues)
+		for _, match := range properties.Details.Matches {
+			names.AppendEmpty().SetStr(match.MatchVariableName)
+			values.AppendEmpty().SetStr(match.MatchVariableValue)
+		}
+	}
+	putStr(attributeAzureFrontDoorWAFMessage, properties.Details.Msg, record)
+	putStr(attributeAzureFrontDoorWAFData, properties.Details.Data, record)
 
 	return nil
 }
 
+// Severity returns Error for the blocked requests, and Info for the requests
+// on which another action was taken.
+func (frontDoorWAFLogProcessor) Severity(data []byte) (plog.SeverityNumber, bool) {
+	var properties struct {
+		Action string `json:"action"`
+	}
+	if err := gojson.Unmarshal(data, &properties); err != nil || properties.Action == "" {
+		return plog.SeverityNumberUnspecified, false
+	}
+	if strings.EqualFold(properties.Action, "Block") {
+		return plog.SeverityNumberError, true
+	}
+	return plog.SeverityNumberInfo, true
+}
+
+// Body leaves the body unset.
+func (frontDoorWAFLogProcessor) Body([]byte, pcommon.Value) error {
+	return nil
+}
+
 // addAppServiceAppLogsProperties parses the App Service access log, and adds
 // the relevant attributes to the record
 func addAppServiceAppLogsProperties(_ []byte, _ plog.LogRecord) error {
@@ -559,3 +796,400 @@ func addAppServicePlatformLogsProperties(_ []byte, _ plog.LogRecord) error {
 	// TODO @constanca-m implement this the same way as addAzureCdnAccessLogProperties
 	return errStillToImplement
 }
+
+// See https://learn.microsoft.com/en-us/azure/aks/monitor-aks-reference#resource-logs.
+type kubeAuditProperties struct {
+	Log         string `json:"log"`
+	Pod         string `json:"pod"`
+	ContainerID string `json:"containerID"`
+}
+
+// See https://kubernetes.io/docs/reference/config-api/apiserver-audit.v1/#audit-k8s-io-v1-Event.
+type kubeAuditEvent struct {
+	AuditID    string `json:"auditID"`
+	Level      string `json:"level"`
+	Stage      string `json:"stage"`
+	RequestURI string `json:"requestURI"`
+	Verb       string `json:"verb"`
+	User       struct {
+		Username string   `json:"username"`
+		Groups   []string `json:"groups"`
+	} `json:"user"`
+	SourceIPs []string `json:"sourceIPs"`
+	UserAgent string   `json:"userAgent"`
+	ObjectRef *struct {
+		Resource    string `json:"resource"`
+		Namespace   string `json:"namespace"`
+		Name        string `json:"name"`
+		APIGroup    string `json:"apiGroup"`
+		APIVersion  string `json:"apiVersion"`
+		Subresource string `json:"subresource"`
+	} `json:"objectRef"`
+	ResponseStatus *struct {
+		Code int64 `json:"code"`
+	} `json:"responseStatus"`
+}
+
+// addKubeAuditProperties parses the AKS control plane audit log, which
+// embeds the Kubernetes audit event as a JSON string, and adds the
+// relevant attributes to the record
+func addKubeAuditProperties(data []byte, record plog.LogRecord) error {
+	var properties kubeAuditProperties
+	if err := gojson.Unmarshal(data, &properties); err != nil {
+		return fmt.Errorf("failed to parse kube-audit properties: %w", err)
+	}
+
+	var event kubeAuditEvent
+	if err := gojson.Unmarshal([]byte(properties.Log), &event); err != nil {
+		return fmt.Errorf(`failed to parse kubernetes audit event from "log" field: %w`, err)
+	}
+
+	if err := addRequestURIProperties(event.RequestURI, record); err != nil {
+		return fmt.Errorf(`failed to handle "requestURI" field: %w`, err)
+	}
+
+	putStr(attributeK8sAuditID, event.AuditID, record)
+	putStr(attributeK8sAuditLevel, event.Level, record)
+	putStr(attributeK8sAuditStage, event.Stage, record)
+	putStr(attributeK8sAuditVerb, event.Verb, record)
+	putStr(attributeK8sAuditUserName, event.User.Username, record)
+	if len(event.User.Groups) > 0 {
+		groups := record.Attributes().PutEmptySlice(attributeK8sAuditUserGroups)
+		groups.EnsureCapacity(len(event.User.Groups))
+		for _, group := range event.User.Groups {
+			groups.AppendEmpty().SetStr(group)
+		}
+	}
+	if len(event.SourceIPs) > 0 {
+		putStr(string(conventions.ClientAddressKey), event.SourceIPs[0], record)
+	}
+	putStr(string(conventions.UserAgentOriginalKey), event.UserAgent, record)
+
+	if event.ObjectRef != nil {
+		putStr(attributeK8sAuditObjectResource, event.ObjectRef.Resource, record)
+		putStr(attributeK8sAuditObjectSubresource, event.ObjectRef.Subresource, record)
+		putStr(attributeK8sAuditObjectName, event.ObjectRef.Name, record)
+		putStr(attributeK8sAuditObjectAPIGroup, event.ObjectRef.APIGroup, record)
+		putStr(attributeK8sAuditObjectAPIVersion, event.ObjectRef.APIVersion, record)
+		putStr(string(conventions.K8SNamespaceNameKey), event.ObjectRef.Namespace, record)
+	}
+	if event.ResponseStatus != nil && event.ResponseStatus.Code != 0 {
+		record.Attributes().PutInt(string(conventions.HTTPResponseStatusCodeKey), event.ResponseStatus.Code)
+	}
+
+	putStr(string(conventions.K8SPodNameKey), properties.Pod, record)
+	putStr(string(conventions.ContainerIDKey), properties.ContainerID, record)
+
+	return nil
+}
+
+// See https://learn.microsoft.com/en-us/azure/network-watcher/nsg-flow-logs-overview#log-format.
+type nsgFlowLogProperties struct {
+	Version int `json:"Version"`
+	Flows   []struct {
+		Rule  string `json:"rule"`
+		Flows []struct {
+			MAC        string   `json:"mac"`
+			FlowTuples []string `json:"flowTuples"`
+		} `json:"flows"`
+	} `json:"flows"`
+}
+
+// nsgFlowTuple is a single flow of the network security group flow log,
+// decoded from its comma separated representation.
+type nsgFlowTuple struct {
+	rule       string
+	mac        string
+	timestamp  int64
+	fields     []string
+	hasTraffic bool
+}
+
+var (
+	nsgFlowProtocols = map[string]string{"T": "tcp", "U": "udp"}
+	nsgFlowDirection = map[string]string{"I": "inbound", "O": "outbound"}
+	nsgFlowDecisions = map[string]string{"A": "allow", "D": "deny"}
+	nsgFlowStates    = map[string]string{"B": "begin", "C": "continue", "E": "end"}
+)
+
+const (
+	nsgFlowTupleV1Fields = 8
+	nsgFlowTupleV2Fields = 13
+)
+
+// parseNSGFlowTuples parses the network security group flow log
+// properties into the list of flows it contains.
+func parseNSGFlowTuples(data []byte) ([]nsgFlowTuple, error) {
+	var properties nsgFlowLogProperties
+	if err := gojson.Unmarshal(data, &properties); err != nil {
+		return nil, fmt.Errorf("failed to parse NetworkSecurityGroupFlowEvent properties: %w", err)
+	}
+
+	var tuples []nsgFlowTuple
+	for _, ruleFlows := range properties.Flows {
+		for _, macFlows := range ruleFlows.Flows {
+			for _, raw := range macFlows.FlowTuples {
+				fields := strings.Split(raw, ",")
+				if len(fields) != nsgFlowTupleV1Fields && len(fields) != nsgFlowTupleV2Fields {
+					return nil, fmt.Errorf("flow tuple %q has %d fields, expects %d or %d", raw, len(fields), nsgFlowTupleV1Fields, nsgFlowTupleV2Fields)
+				}
+				ts, err := strconv.ParseInt(fields[0], 10, 64)
+				if err != nil {
+					return nil, fmt.Errorf("failed to get timestamp of flow tuple %q: %w", raw, err)
+				}
+				tuples = append(tuples, nsgFlowTuple{
+					rule:       ruleFlows.Rule,
+					mac:        macFlows.MAC,
+					timestamp:  ts,
+					fields:     fields,
+					hasTraffic: len(fields) == nsgFlowTupleV2Fields,
+				})
+			}
+		}
+	}
+	return tuples, nil
+}
+
+// addNSGFlowTupleProperties adds the attributes of a single flow
+// to the record
+func addNSGFlowTupleProperties(tuple nsgFlowTuple, record plog.LogRecord) error {
+	f := tuple.fields
+
+	putStr(string(conventions.SourceAddressKey), f[1], record)
+	putStr(string(conventions.DestinationAddressKey), f[2], record)
+	if err := putInt(string(conventions.SourcePortKey), f[3], record); err != nil {
+		return err
+	}
+	if err := putInt(string(conventions.DestinationPortKey), f[4], record); err != nil {
+		return err
+	}
+	putMapped := func(field, value string, values map[string]string) {
+		if mapped, ok := values[value]; ok {
+			record.Attributes().PutStr(field, mapped)
+		} else {
+			putStr(field, value, record)
+		}
+	}
+	putMapped(string(conventions.NetworkTransportKey), f[5], nsgFlowProtocols)
+	putMapped(attributeAzureNSGFlowDirection, f[6], nsgFlowDirection)
+	putMapped(attributeAzureNSGFlowDecision, f[7], nsgFlowDecisions)
+	putStr(attributeAzureNSGRuleName, tuple.rule, record)
+	putStr(attributeAzureNSGFlowMAC, tuple.mac, record)
+
+	if !tuple.hasTraffic {
+		return nil
+	}
+	putMapped(attributeAzureNSGFlowState, f[8], nsgFlowStates)
+	// Traffic counters are empty for flows in the "begin" state.
+	for i, field := range []string{
+		attributeAzureNSGFlowPacketsSent,
+		attributeAzureNSGFlowBytesSent,
+		attributeAzureNSGFlowPacketsReceived,
+		attributeAzureNSGFlowBytesReceived,
+	} {
+		if value := f[9+i]; value != "" {
+			if err := putInt(field, value, record); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// See https://learn.microsoft.com/en-us/azure/azure-sql/database/audit-log-format.
+// The "succeeded" field is sent as the string "true" or "false", but is
+// accepted as a boolean too.
+type sqlSecurityAuditEventProperties struct {
+	ActionID              string `json:"action_id"`
+	ActionName            string `json:"action_name"`
+	Succeeded             any    `json:"succeeded"`
+	ClassTypeDescription  string `json:"class_type_description"`
+	SessionID             *int64 `json:"session_id"`
+	ServerPrincipalName   string `json:"server_principal_name"`
+	DatabasePrincipalName string `json:"database_principal_name"`
+	ServerInstanceName    string `json:"server_instance_name"`
+	DatabaseName          string `json:"database_name"`
+	ObjectName            string `json:"object_name"`
+	Statement             string `json:"statement"`
+	ApplicationName       string `json:"application_name"`
+	ClientIP              string `json:"client_ip"`
+	AffectedRows          *int64 `json:"affected_rows"`
+}
+
+// sqlSecurityAuditEventsProcessor maps the Azure SQL audit records. Their
+// severity is Info when the audited action succeeded, and Warn otherwise.
+type sqlSecurityAuditEventsProcessor struct{}
+
+// AddAttributes parses the Azure SQL audit record and adds the relevant
+// attributes to the record.
+func (sqlSecurityAuditEventsProcessor) AddAttributes(data []byte, record plog.LogRecord) error {
+	var properties sqlSecurityAuditEventProperties
+	if err := gojson.Unmarshal(data, &properties); err != nil {
+		return fmt.Errorf("failed to parse SQLSecurityAuditEvents properties: %w", err)
+	}
+	succeeded, hasOutcome, err := parseSQLAuditSucceeded(properties.Succeeded)
+	if err != nil {
+		return err
+	}
+
+	record.Attributes().PutStr(string(conventions.DBSystemKey), conventions.DBSystemMSSQL.Value.AsString())
+	putStr(string(conventions.DBQueryTextKey), properties.Statement, record)
+	putStr(string(conventions.DBNamespaceKey), properties.DatabaseName, record)
+	putStr(string(conventions.DBCollectionNameKey), properties.ObjectName, record)
+	putStr(string(conventions.ServerAddressKey), properties.ServerInstanceName, record)
+	putStr(string(conventions.ClientAddressKey), properties.ClientIP, record)
+	putStr(attributeEndUserID, properties.ServerPrincipalName, record)
+	putStr(attributeAzureSQLAuditDatabasePrincipalName, properties.DatabasePrincipalName, record)
+	// action IDs are padded with spaces to 4 characters
+	putStr(attributeAzureSQLAuditActionID, strings.TrimSpace(properties.ActionID), record)
+	putStr(attributeAzureSQLAuditActionName, properties.ActionName, record)
+	putStr(attributeAzureSQLAuditClassType, properties.ClassTypeDescription, record)
+	putStr(attributeAzureSQLAuditApplicationName, properties.ApplicationName, record)
+	if properties.SessionID != nil {
+		record.Attributes().PutInt(attributeAzureSQLAuditSessionID, *properties.SessionID)
+	}
+	if properties.AffectedRows != nil {
+		record.Attributes().PutInt(attributeAzureSQLAuditAffectedRows, *properties.AffectedRows)
+	}
+
+	if hasOutcome {
+		record.Attributes().PutBool(attributeAzureSQLAuditSucceeded, succeeded)
+	}
+	return nil
+}
+
+// Severity returns the severity matching the outcome of the audited action.
+func (sqlSecurityAuditEventsProcessor) Severity(data []byte) (plog.SeverityNumber, bool) {
+	var properties struct {
+		Succeeded any `json:"succeeded"`
+	}
+	if err := gojson.Unmarshal(data, &properties); err != nil {
+		return plog.SeverityNumberUnspecified, false
+	}
+	succeeded, hasOutcome, err := parseSQLAuditSucceeded(properties.Succeeded)
+	if err != nil || !hasOutcome {
+		return plog.SeverityNumberUnspecified, false
+	}
+	if succeeded {
+		return plog.SeverityNumberInfo, true
+	}
+	return plog.SeverityNumberWarn, true
+}
+
+// Body leaves the body unset.
+func (sqlSecurityAuditEventsProcessor) Body([]byte, pcommon.Value) error {
+	return nil
+}
+
+// parseSQLAuditSucceeded returns the outcome held in the "succeeded"
+// field, and whether the field is set.
+func parseSQLAuditSucceeded(value any) (bool, bool, error) {
+	switch v := value.(type) {
+	case nil:
+		return false, false, nil
+	case bool:
+		return v, true, nil
+	case string:
+		if v == "" {
+			return false, false, nil
+		}
+		succeeded, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, false, fmt.Errorf(`failed to parse "succeeded" field %q: %w`, v, err)
+		}
+		return succeeded, true, nil
+	default:
+		return false, false, fmt.Errorf(`unexpected type %T for "succeeded" field`, value)
+	}
+}
+
+const (
+	activityLogClaimAppID    = "appid"
+	activityLogClaimObjectID = "http://schemas.microsoft.com/identity/claims/objectidentifier"
+	activityLogClaimUPN      = "http://schemas.xmlsoap.org/ws/2005/05/identity/claims/upn"
+	activityLogClaimShortOID = "oid"
+	activityLogClaimShortUPN = "upn"
+	activityLogRegionGlobal  = "global"
+)
+
+// See https://learn.microsoft.com/en-us/azure/azure-monitor/essentials/activity-log-schema#schema-from-storage-account-and-event-hubs.
+type activityLogProperties struct {
+	StatusCode string `json:"statusCode"`
+}
+
+// isActivityLogCategory returns true if the category is one of the
+// Activity Log categories handled by addActivityLogAttributes.
+func isActivityLogCategory(category string) bool {
+	switch category {
+	case categoryAdministrative, categoryPolicy, categorySecurity:
+		return true
+	default:
+		return false
+	}
+}
+
+// addActivityLogAttributes adds the attributes of the Activity Log
+// record to the log record. Unlike the resource logs, Activity Logs
+// keep the caller and its authorization in the identity field of
+// the common schema rather than in the properties.
+func addActivityLogAttributes(log azureLogRecord, record plog.LogRecord) error {
+	if len(log.Properties) > 0 {
+		var properties activityLogProperties
+		if err := gojson.Unmarshal(log.Properties, &properties); err != nil {
+			return fmt.Errorf("failed to parse logs from category %q: %w", log.Category, err)
+		}
+		putStr(attributeAzureActivitySubStatus, properties.StatusCode, record)
+	}
+
+	if log.ResultType != nil {
+		putStr(attributeAzureActivityStatus, *log.ResultType, record)
+	}
+	putStr(string(conventions.CloudAccountIDKey), parseResourceID(log.ResourceID).subscriptionID, record)
+	if log.Location != nil && !strings.EqualFold(*log.Location, activityLogRegionGlobal) {
+		putStr(string(conventions.CloudRegionKey), *log.Location, record)
+	}
+	if log.CallerIPAddress != nil {
+		putStr(string(conventions.ClientAddressKey), *log.CallerIPAddress, record)
+	}
+
+	if log.Identity == nil {
+		return nil
+	}
+	identity, _ := (*log.Identity).(map[string]any)
+
+	authorization, _ := identity["authorization"].(map[string]any)
+	putStr(attributeAzureActivityAuthorizationAction, stringValue(authorization, "action"), record)
+	putStr(attributeAzureActivityAuthorizationScope, stringValue(authorization, "scope"), record)
+	evidence, _ := authorization["evidence"].(map[string]any)
+	putStr(attributeEndUserRole, stringValue(evidence, "role"), record)
+
+	claims, _ := identity["claims"].(map[string]any)
+	appID := stringValue(claims, activityLogClaimAppID)
+	putStr(attributeAzureActivityIdentityAppID, appID, record)
+	putStr(attributeAzureActivityIdentityObjectID, firstStringValue(claims, activityLogClaimObjectID, activityLogClaimShortOID), record)
+	// users are identified by their user principal name, service principals
+	// only have their application ID
+	caller := firstStringValue(claims, activityLogClaimUPN, activityLogClaimShortUPN)
+	if caller == "" {
+		caller = appID
+	}
+	putStr(attributeEndUserID, caller, record)
+
+	return nil
+}
+
+// stringValue returns the value of key in m if it is a string.
+func stringValue(m map[string]any, key string) string {
+	value, _ := m[key].(string)
+	return value
+}
+
+// firstStringValue returns the first non empty string value of keys in m.
+func firstStringValue(m map[string]any, keys ...string) string {
+	for _, key := range keys {
+		if value := stringValue(m, key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -4,6 +4,7 @@
 package azurelogs
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -166,6 +167,137 @@ func TestAddSecurityProtocolProperties(t *testing.T) {
 	}
 }
 
+func TestHTTPProtocolVersion(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"1.0.0.0":  "1.0",
+		"1.1.0.0":  "1.1",
+		"2.0.0.0":  "2",
+		"3.0.0.0":  "3",
+		"HTTP/1.1": "1.1",
+		"HTTP/2":   "2",
+		"1":        "1.0",
+		"N/A":      "N/A",
+		"":         "",
+	}
+
+	for version, expected := range tests {
+		t.Run(version, func(t *testing.T) {
+			require.Equal(t, expected, httpProtocolVersion(version))
+		})
+	}
+}
+
+func TestParseSQLAuditSucceeded(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		value      any
+		succeeded  bool
+		hasOutcome bool
+		expectsErr string
+	}{
+		"true string":  {value: "true", succeeded: true, hasOutcome: true},
+		"false string": {value: "false", hasOutcome: true},
+		"boolean":      {value: true, succeeded: true, hasOutcome: true},
+		"missing":      {value: nil},
+		"empty":        {value: ""},
+		"invalid":      {value: "maybe", expectsErr: `failed to parse "succeeded" field "maybe"`},
+		"number":       {value: float64(1), expectsErr: `unexpected type float64 for "succeeded" field`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			succeeded, hasOutcome, err := parseSQLAuditSucceeded(test.value)
+			if test.expectsErr != "" {
+				require.ErrorContains(t, err, test.expectsErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.succeeded, succeeded)
+			require.Equal(t, test.hasOutcome, hasOutcome)
+		})
+	}
+}
+
+func TestFrontDoorWAFLogSeverity(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		properties string
+		severity   plog.SeverityNumber
+		ok         bool
+	}{
+		"block":          {properties: `{"action":"Block"}`, severity: plog.SeverityNumberError, ok: true},
+		"block lower":    {properties: `{"action":"block"}`, severity: plog.SeverityNumberError, ok: true},
+		"log":            {properties: `{"action":"Log"}`, severity: plog.SeverityNumberInfo, ok: true},
+		"anomaly scored": {properties: `{"action":"AnomalyScoring"}`, severity: plog.SeverityNumberInfo, ok: true},
+		"missing":        {properties: `{}`},
+		"invalid":        {properties: `{"action":`},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			severity, ok := frontDoorWAFLogProcessor{}.Severity([]byte(test.properties))
+			require.Equal(t, test.ok, ok)
+			require.Equal(t, test.severity, severity)
+		})
+	}
+}
+
+func TestFrontDoorWAFLogRuleName(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		ruleName string
+		expected map[string]any
+	}{
+		"managed rule": {
+			ruleName: "Microsoft_DefaultRuleSet-2.1-SQLI-942100",
+			expected: map[string]any{
+				attributeAzureFrontDoorWAFRuleName:       "Microsoft_DefaultRuleSet-2.1-SQLI-942100",
+				attributeAzureFrontDoorWAFRuleSetName:    "Microsoft_DefaultRuleSet",
+				attributeAzureFrontDoorWAFRuleSetVersion: "2.1",
+				attributeAzureFrontDoorWAFRuleGroup:      "SQLI",
+				attributeAzureFrontDoorWAFRuleID:         "942100",
+			},
+		},
+		"bot manager rule": {
+			ruleName: "Microsoft_BotManagerRuleSet-1.0-GoodBots-Bot100200",
+			expected: map[string]any{
+				attributeAzureFrontDoorWAFRuleName:       "Microsoft_BotManagerRuleSet-1.0-GoodBots-Bot100200",
+				attributeAzureFrontDoorWAFRuleSetName:    "Microsoft_BotManagerRuleSet",
+				attributeAzureFrontDoorWAFRuleSetVersion: "1.0",
+				attributeAzureFrontDoorWAFRuleGroup:      "GoodBots",
+				attributeAzureFrontDoorWAFRuleID:         "Bot100200",
+			},
+		},
+		"custom rule": {
+			ruleName: "BlockCountries",
+			expected: map[string]any{
+				attributeAzureFrontDoorWAFRuleName: "BlockCountries",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			record := plog.NewLogRecord()
+			properties := `{"clientPort":"443","requestUri":"https://example.com/","ruleName":"` + test.ruleName + `"}`
+			err := frontDoorWAFLogProcessor{}.AddAttributes([]byte(properties), record)
+			require.NoError(t, err)
+			rule := map[string]any{}
+			for key, value := range record.Attributes().AsRaw() {
+				if strings.HasPrefix(key, "azure.frontdoor.waf.rule.") {
+					rule[key] = value
+				}
+			}
+			require.Equal(t, test.expected, rule)
+		})
+	}
+}
+
 func TestHandleDestination(t *testing.T) {
 	t.Parallel()
 
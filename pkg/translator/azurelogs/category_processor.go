@@ -0,0 +1,99 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"maps"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// CategoryLogProcessor maps the properties of the records of a category to
+// their log records. The common schema fields of the records, such as the
+// timestamp, the Level and the operation name, are handled by the unmarshaler.
+type CategoryLogProcessor interface {
+	// AddAttributes adds the attributes held in the properties of a record
+	// to its log record. When it fails, the error is logged and the
+	// common schema attributes are not added.
+	AddAttributes(properties []byte, record plog.LogRecord) error
+	// Severity returns the severity of a record without a Level nor a
+	// severity from the SeverityMapping, and false if it has none.
+	Severity(properties []byte) (plog.SeverityNumber, bool)
+	// Body sets the body of the log record of a record, left empty
+	// otherwise.
+	Body(properties []byte, body pcommon.Value) error
+}
+
+// CategoryLogProcessorFunc is a CategoryLogProcessor that only adds
+// attributes, leaving the severity and the body of the log records unset.
+type CategoryLogProcessorFunc func(properties []byte, record plog.LogRecord) error
+
+var _ CategoryLogProcessor = CategoryLogProcessorFunc(nil)
+
+// AddAttributes calls f.
+func (f CategoryLogProcessorFunc) AddAttributes(properties []byte, record plog.LogRecord) error {
+	return f(properties, record)
+}
+
+// Severity returns no severity.
+func (CategoryLogProcessorFunc) Severity([]byte) (plog.SeverityNumber, bool) {
+	return plog.SeverityNumberUnspecified, false
+}
+
+// Body leaves the body unset.
+func (CategoryLogProcessorFunc) Body([]byte, pcommon.Value) error {
+	return nil
+}
+
+// CategoryRegistry holds the CategoryLogProcessor of each supported category.
+// The records of the categories without a processor keep their raw fields in
+// the body of their log records.
+type CategoryRegistry struct {
+	processors map[string]CategoryLogProcessor
+}
+
+// NewCategoryRegistry returns a registry holding the processors of the
+// categories supported by this package.
+func NewCategoryRegistry() *CategoryRegistry {
+	return &CategoryRegistry{processors: maps.Clone(builtinCategories)}
+}
+
+// Register sets the processor of category, replacing the processor of a
+// category supported by this package, including the Activity Log and
+// network security group flow log categories, if any. Register is not
+// safe to call once the registry is used by an unmarshaler.
+func (r *CategoryRegistry) Register(category string, processor CategoryLogProcessor) {
+	r.processors[category] = processor
+}
+
+// lookup returns the processor of category, if any.
+func (r *CategoryRegistry) lookup(category string) (CategoryLogProcessor, bool) {
+	processor, ok := r.processors[category]
+	return processor, ok
+}
+
+// defaultCategories is used by the unmarshalers without a registry.
+var defaultCategories = NewCategoryRegistry()
+
+// builtinCategories are the processors of the categories supported by this
+// package. The Activity Log categories, whose fields are outside of the
+// properties, and the network security group flow logs, which hold many
+// flows per record, are handled by the unmarshaler.
+var builtinCategories = map[string]CategoryLogProcessor{
+	categoryAzureCdnAccessLog:                  CategoryLogProcessorFunc(addAzureCdnAccessLogProperties),
+	categoryFrontDoorAccessLog:                 CategoryLogProcessorFunc(addFrontDoorAccessLogProperties),
+	categoryFrontDoorHealthProbeLog:            CategoryLogProcessorFunc(addFrontDoorHealthProbeLogProperties),
+	categoryFrontdoorWebApplicationFirewallLog: frontDoorWAFLogProcessor{},
+	categoryAppServiceAppLogs:                  CategoryLogProcessorFunc(addAppServiceAppLogsProperties),
+	categoryAppServiceAuditLogs:                CategoryLogProcessorFunc(addAppServiceAuditLogsProperties),
+	categoryAppServiceAuthenticationLogs:       CategoryLogProcessorFunc(addAppServiceAuthenticationLogsProperties),
+	categoryAppServiceConsoleLogs:              CategoryLogProcessorFunc(addAppServiceConsoleLogsProperties),
+	categoryAppServiceHTTPLogs:                 CategoryLogProcessorFunc(addAppServiceHTTPLogsProperties),
+	categoryAppServiceIPSecAuditLogs:           CategoryLogProcessorFunc(addAppServiceIPSecAuditLogsProperties),
+	categoryAppServicePlatformLogs:             CategoryLogProcessorFunc(addAppServicePlatformLogsProperties),
+	categoryKubeAudit:                          CategoryLogProcessorFunc(addKubeAuditProperties),
+	categoryKubeAuditAdmin:                     CategoryLogProcessorFunc(addKubeAuditProperties),
+	categorySQLSecurityAuditEvents:             sqlSecurityAuditEventsProcessor{},
+}
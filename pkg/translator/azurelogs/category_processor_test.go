@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"errors"
+	"testing"
+
+	gojson "github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// messageProcessor maps records holding a message and a status.
+type messageProcessor struct{}
+
+type messageProperties struct {
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+func (messageProcessor) AddAttributes(data []byte, record plog.LogRecord) error {
+	var properties messageProperties
+	if err := gojson.Unmarshal(data, &properties); err != nil {
+		return err
+	}
+	putStr("status", properties.Status, record)
+	return nil
+}
+
+func (messageProcessor) Severity(data []byte) (plog.SeverityNumber, bool) {
+	var properties messageProperties
+	if err := gojson.Unmarshal(data, &properties); err != nil || properties.Status != "failed" {
+		return plog.SeverityNumberUnspecified, false
+	}
+	return plog.SeverityNumberError, true
+}
+
+func (messageProcessor) Body(data []byte, body pcommon.Value) error {
+	var properties messageProperties
+	if err := gojson.Unmarshal(data, &properties); err != nil {
+		return err
+	}
+	body.SetStr(properties.Message)
+	return nil
+}
+
+func TestCategoryRegistry(t *testing.T) {
+	t.Parallel()
+
+	registry := NewCategoryRegistry()
+	registry.Register("CustomEvents", messageProcessor{})
+	registry.Register(categoryKubeAudit, CategoryLogProcessorFunc(func([]byte, plog.LogRecord) error {
+		return errors.New("replaced")
+	}))
+
+	_, ok := registry.lookup("CustomEvents")
+	assert.True(t, ok)
+	_, ok = defaultCategories.lookup("CustomEvents")
+	assert.False(t, ok, "registering must not change the default registry")
+
+	u := &ResourceLogsUnmarshaler{
+		Version:    testBuildInfo.Version,
+		Logger:     zap.NewNop(),
+		Categories: registry,
+	}
+
+	logs, err := u.UnmarshalLogs([]byte(`{"records": [
+		{"time": "2025-06-03T14:21:07Z", "resourceId": "/RESOURCE_ID", "operationName": "Run", "category": "CustomEvents",
+		 "properties": {"message": "job failed", "status": "failed"}},
+		{"time": "2025-06-03T14:21:08Z", "resourceId": "/RESOURCE_ID", "operationName": "Run", "category": "CustomEvents", "Level": "Warning",
+		 "properties": {"message": "job failed again", "status": "failed"}}
+	]}`))
+	require.NoError(t, err)
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+
+	record := records.At(0)
+	assert.Equal(t, "job failed", record.Body().Str())
+	assert.Equal(t, plog.SeverityNumberError, record.SeverityNumber())
+	assert.Equal(t, map[string]any{
+		"status":                    "failed",
+		attributeAzureCategory:      "CustomEvents",
+		attributeAzureOperationName: "Run",
+	}, record.Attributes().AsRaw())
+	// the Level of the record takes precedence
+	assert.Equal(t, plog.SeverityNumberWarn, records.At(1).SeverityNumber())
+
+	logs, err = u.UnmarshalLogs([]byte(`{"records": [
+		{"time": "2025-06-03T14:21:07Z", "resourceId": "/RESOURCE_ID", "operationName": "Read", "category": "kube-audit",
+		 "properties": {"log": "{}"}}
+	]}`))
+	require.NoError(t, err)
+	record = logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Zero(t, record.Attributes().Len(), "the replaced processor must be used")
+}
+
+func TestCategoryLogProcessorFunc(t *testing.T) {
+	t.Parallel()
+
+	processor := CategoryLogProcessorFunc(func(_ []byte, record plog.LogRecord) error {
+		record.Attributes().PutStr("key", "value")
+		return nil
+	})
+
+	record := plog.NewLogRecord()
+	require.NoError(t, processor.AddAttributes(nil, record))
+	assert.Equal(t, map[string]any{"key": "value"}, record.Attributes().AsRaw())
+
+	_, ok := processor.Severity(nil)
+	assert.False(t, ok)
+
+	require.NoError(t, processor.Body(nil, record.Body()))
+	assert.Equal(t, pcommon.ValueTypeEmpty, record.Body().Type())
+}
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"context"
+	"net"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/attribute"
+	conventions "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.uber.org/zap"
+)
+
+// GeoIPResolver resolves the geographical location of an IP address. Its
+// method matches the one of the providers of the geoipprocessor, so that
+// their databases can be reused without a second pass over the logs.
+type GeoIPResolver interface {
+	// Location returns the geo attributes of the IP address, e.g.
+	// geo.country.iso_code, or an error when it cannot be resolved.
+	Location(context.Context, net.IP) (attribute.Set, error)
+}
+
+// geoIPAddressKeys are the attributes holding the caller IP of a log
+// record, in order of precedence.
+var geoIPAddressKeys = []string{
+	string(conventions.ClientAddressKey),
+	string(conventions.SourceAddressKey),
+}
+
+// addGeoAttributes adds the location of the caller IP of the log records
+// starting at index first, taken from their attributes or otherwise from
+// the callerIpAddress of the record. Addresses that cannot be resolved are
+// skipped.
+func (r ResourceLogsUnmarshaler) addGeoAttributes(log azureLogRecord, records plog.LogRecordSlice, first int) {
+	for i := first; i < records.Len(); i++ {
+		lr := records.At(i)
+		ip := callerIP(log, lr.Attributes())
+		if ip == nil {
+			continue
+		}
+		location, err := r.GeoIP.Location(context.Background(), ip)
+		if err != nil {
+			r.Logger.Debug("unable to resolve the location of the caller IP",
+				zap.Stringer("ip", ip),
+				zap.Error(err),
+			)
+			continue
+		}
+		putGeoAttributes(location, lr.Attributes())
+	}
+}
+
+// callerIP returns the caller IP of a log record, or nil when it has none.
+func callerIP(log azureLogRecord, attrs pcommon.Map) net.IP {
+	for _, key := range geoIPAddressKeys {
+		if value, ok := attrs.Get(key); ok {
+			if ip := net.ParseIP(value.Str()); ip != nil {
+				return ip
+			}
+		}
+	}
+	if log.CallerIPAddress != nil {
+		return net.ParseIP(*log.CallerIPAddress)
+	}
+	return nil
+}
+
+// putGeoAttributes copies the location attributes to attrs.
+func putGeoAttributes(location attribute.Set, attrs pcommon.Map) {
+	for iter := location.Iter(); iter.Next(); {
+		kv := iter.Attribute()
+		key := string(kv.Key)
+		switch kv.Value.Type() {
+		case attribute.STRING:
+			attrs.PutStr(key, kv.Value.AsString())
+		case attribute.INT64:
+			attrs.PutInt(key, kv.Value.AsInt64())
+		case attribute.FLOAT64:
+			attrs.PutDouble(key, kv.Value.AsFloat64())
+		case attribute.BOOL:
+			attrs.PutBool(key, kv.Value.AsBool())
+		default:
+			attrs.PutStr(key, kv.Value.Emit())
+		}
+	}
+}
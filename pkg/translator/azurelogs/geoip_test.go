@@ -0,0 +1,113 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+type fakeGeoIPResolver map[string]attribute.Set
+
+func (f fakeGeoIPResolver) Location(_ context.Context, ip net.IP) (attribute.Set, error) {
+	location, ok := f[ip.String()]
+	if !ok {
+		return attribute.Set{}, errors.New("no metadata found")
+	}
+	return location, nil
+}
+
+func TestUnmarshalLogs_GeoIP(t *testing.T) {
+	t.Parallel()
+
+	payload := `{"records": [
+		{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/SUBSCRIPTIONS/SUB/RESOURCEGROUPS/GROUP/PROVIDERS/MICROSOFT.WEB/SITES/APP", "category": "Administrative", "operationName": "Write", "callerIpAddress": "81.2.69.142"},
+		{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/SUBSCRIPTIONS/SUB/RESOURCEGROUPS/GROUP/PROVIDERS/MICROSOFT.WEB/SITES/APP", "category": "Unknown", "operationName": "Read", "callerIpAddress": "2001:db8::1"},
+		{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/SUBSCRIPTIONS/SUB/RESOURCEGROUPS/GROUP/PROVIDERS/MICROSOFT.WEB/SITES/APP", "category": "Administrative", "operationName": "Write", "callerIpAddress": "10.0.0.1"},
+		{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/SUBSCRIPTIONS/SUB/RESOURCEGROUPS/GROUP/PROVIDERS/MICROSOFT.WEB/SITES/APP", "category": "Administrative", "operationName": "Write"}
+	]}`
+
+	u := &ResourceLogsUnmarshaler{
+		Version: testBuildInfo.Version,
+		Logger:  zap.NewNop(),
+		GeoIP: fakeGeoIPResolver{
+			"81.2.69.142": attribute.NewSet(
+				attribute.String("geo.country.iso_code", "GB"),
+				attribute.Float64("geo.location.lat", 51.5142),
+			),
+			"2001:db8::1": attribute.NewSet(attribute.String("geo.country.iso_code", "SE")),
+		},
+	}
+
+	logs, err := u.UnmarshalLogs([]byte(payload))
+	require.NoError(t, err)
+	require.Equal(t, 4, logs.LogRecordCount())
+	records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+
+	attrs := records.At(0).Attributes().AsRaw()
+	assert.Equal(t, "81.2.69.142", attrs["client.address"])
+	assert.Equal(t, "GB", attrs["geo.country.iso_code"])
+	assert.Equal(t, 51.5142, attrs["geo.location.lat"])
+
+	// the callerIpAddress is used for the records without address attributes
+	attrs = records.At(1).Attributes().AsRaw()
+	assert.Equal(t, "SE", attrs["geo.country.iso_code"])
+
+	// addresses that cannot be resolved and records without caller IP are left as is
+	for i := 2; i < records.Len(); i++ {
+		assert.NotContains(t, records.At(i).Attributes().AsRaw(), "geo.country.iso_code")
+	}
+}
+
+func TestCallerIP(t *testing.T) {
+	t.Parallel()
+
+	ptr := func(s string) *string { return &s }
+	tests := map[string]struct {
+		log      azureLogRecord
+		attrs    map[string]any
+		expected string
+	}{
+		"client address takes precedence": {
+			log:      azureLogRecord{CallerIPAddress: ptr("192.0.2.3")},
+			attrs:    map[string]any{"client.address": "192.0.2.1", "source.address": "192.0.2.2"},
+			expected: "192.0.2.1",
+		},
+		"source address": {
+			attrs:    map[string]any{"client.address": "frontdoor.example.com", "source.address": "192.0.2.2"},
+			expected: "192.0.2.2",
+		},
+		"caller ip address": {
+			log:      azureLogRecord{CallerIPAddress: ptr("192.0.2.3")},
+			expected: "192.0.2.3",
+		},
+		"invalid caller ip address": {
+			log: azureLogRecord{CallerIPAddress: ptr("unknown")},
+		},
+		"no caller ip": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			attrs := pcommon.NewMap()
+			require.NoError(t, attrs.FromRaw(test.attrs))
+			ip := callerIP(test.log, attrs)
+			if test.expected == "" {
+				assert.Nil(t, ip)
+				return
+			}
+			assert.Equal(t, test.expected, ip.String())
+		})
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import "strings"
+
+// resourceGroups groups the telemetry of the records of a payload by resource,
+// so that each resource appears once in the output however its records are
+// interleaved. Azure resource IDs are case insensitive, and the same resource
+// often appears with different casings, e.g. in upper case in the resource
+// logs and in the casing it was created with in the activity logs, so the
+// records are grouped regardless of the case of their resource ID. The groups
+// are kept in the order of first appearance, so that the output is
+// deterministic.
+type resourceGroups[T any] struct {
+	newGroup func() T
+	// index holds the position of the group of each upper cased resource ID
+	index       map[string]int
+	resourceIDs []string
+	groups      []T
+}
+
+func newResourceGroups[T any](newGroup func() T) *resourceGroups[T] {
+	return &resourceGroups[T]{
+		newGroup: newGroup,
+		index:    make(map[string]int),
+	}
+}
+
+// get returns the group of resourceID, creating it on its first appearance.
+func (g *resourceGroups[T]) get(resourceID string) T {
+	key := strings.ToUpper(resourceID)
+	if i, ok := g.index[key]; ok {
+		return g.groups[i]
+	}
+	group := g.newGroup()
+	g.index[key] = len(g.groups)
+	g.resourceIDs = append(g.resourceIDs, resourceID)
+	g.groups = append(g.groups, group)
+	return group
+}
+
+// each calls fn with every group, and the resource ID as it first appeared,
+// in the order of first appearance.
+func (g *resourceGroups[T]) each(fn func(resourceID string, group T)) {
+	for i, group := range g.groups {
+		fn(g.resourceIDs[i], group)
+	}
+}
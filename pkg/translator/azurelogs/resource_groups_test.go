@@ -0,0 +1,29 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResourceGroups(t *testing.T) {
+	t.Parallel()
+
+	groups := newResourceGroups(func() *[]int { return &[]int{} })
+	for i, resourceID := range []string{"/b", "/A", "/B", "/a", "/c", "/b"} {
+		group := groups.get(resourceID)
+		*group = append(*group, i)
+	}
+
+	var resourceIDs []string
+	var grouped [][]int
+	groups.each(func(resourceID string, group *[]int) {
+		resourceIDs = append(resourceIDs, resourceID)
+		grouped = append(grouped, *group)
+	})
+	assert.Equal(t, []string{"/b", "/A", "/c"}, resourceIDs)
+	assert.Equal(t, [][]int{{0, 2, 5}, {1, 3}, {4}}, grouped)
+}
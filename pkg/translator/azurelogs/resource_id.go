@@ -0,0 +1,79 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/otel/semconv/v1.27.0"
+)
+
+const (
+	// attributeCloudResourceGroup holds the resource group of the
+	// resource the logs originate from.
+	attributeCloudResourceGroup = "cloud.resource_group"
+
+	// attributeAzureResourceProviderNamespace holds the namespace of
+	// the resource provider, e.g. "MICROSOFT.COMPUTE".
+	attributeAzureResourceProviderNamespace = "azure.resource.provider.namespace"
+
+	// attributeAzureResourceName holds the name of the resource the
+	// logs originate from, the last name of nested resources.
+	attributeAzureResourceName = "azure.resource.name"
+
+	resourceIDSubscriptions  = "subscriptions"
+	resourceIDResourceGroups = "resourceGroups"
+	resourceIDProviders      = "providers"
+)
+
+// resourceID holds the parts of an Azure Resource Manager resource ID,
+// /subscriptions/{id}/resourceGroups/{group}/providers/{namespace}/{type}/{name}.
+// Any of them may be empty as not all resource IDs hold all of them.
+type resourceID struct {
+	subscriptionID    string
+	resourceGroup     string
+	providerNamespace string
+	name              string
+}
+
+// parseResourceID decomposes an Azure resource ID. The segment keys are
+// matched case-insensitively since Azure does not preserve their case.
+func parseResourceID(id string) resourceID {
+	var parsed resourceID
+	segments := strings.Split(strings.Trim(id, "/"), "/")
+	for i := 0; i+1 < len(segments); i += 2 {
+		key, value := segments[i], segments[i+1]
+		switch {
+		case strings.EqualFold(key, resourceIDSubscriptions):
+			parsed.subscriptionID = value
+		case strings.EqualFold(key, resourceIDResourceGroups):
+			parsed.resourceGroup = value
+		case strings.EqualFold(key, resourceIDProviders):
+			// the provider namespace is followed by type and name pairs,
+			// the name of the last pair is the name of the resource
+			parsed.providerNamespace = value
+			parsed.name = ""
+		case parsed.providerNamespace != "":
+			parsed.name = value
+		}
+	}
+	return parsed
+}
+
+// addResourceIDAttributes adds the parts of the resource ID to the
+// resource attributes.
+func addResourceIDAttributes(id string, attrs pcommon.Map) {
+	parsed := parseResourceID(id)
+	putStrIfNotEmpty(string(conventions.CloudAccountIDKey), parsed.subscriptionID, attrs)
+	putStrIfNotEmpty(attributeCloudResourceGroup, parsed.resourceGroup, attrs)
+	putStrIfNotEmpty(attributeAzureResourceProviderNamespace, parsed.providerNamespace, attrs)
+	putStrIfNotEmpty(attributeAzureResourceName, parsed.name, attrs)
+}
+
+func putStrIfNotEmpty(key, value string, attrs pcommon.Map) {
+	if value != "" {
+		attrs.PutStr(key, value)
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseResourceID(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		id       string
+		expected resourceID
+	}{
+		"resource": {
+			id: "/SUBSCRIPTIONS/8A6D2F1E/RESOURCEGROUPS/RG-PROD/PROVIDERS/MICROSOFT.COMPUTE/VIRTUALMACHINES/VM-01",
+			expected: resourceID{
+				subscriptionID:    "8A6D2F1E",
+				resourceGroup:     "RG-PROD",
+				providerNamespace: "MICROSOFT.COMPUTE",
+				name:              "VM-01",
+			},
+		},
+		"nested_resource": {
+			id: "/subscriptions/8a6d2f1e/resourceGroups/rg-prod/providers/Microsoft.Sql/servers/sql-01/databases/db-01",
+			expected: resourceID{
+				subscriptionID:    "8a6d2f1e",
+				resourceGroup:     "rg-prod",
+				providerNamespace: "Microsoft.Sql",
+				name:              "db-01",
+			},
+		},
+		"extension_resource": {
+			id: "/subscriptions/8a6d2f1e/resourceGroups/rg-prod/providers/Microsoft.Compute/virtualMachines/vm-01/providers/Microsoft.Insights/diagnosticSettings/ds-01",
+			expected: resourceID{
+				subscriptionID:    "8a6d2f1e",
+				resourceGroup:     "rg-prod",
+				providerNamespace: "Microsoft.Insights",
+				name:              "ds-01",
+			},
+		},
+		"subscription_provider": {
+			id: "/subscriptions/8a6d2f1e/providers/Microsoft.Security/locations/centralus/alerts/alert-01",
+			expected: resourceID{
+				subscriptionID:    "8a6d2f1e",
+				providerNamespace: "Microsoft.Security",
+				name:              "alert-01",
+			},
+		},
+		"resource_group": {
+			id: "/subscriptions/8a6d2f1e/resourceGroups/rg-prod",
+			expected: resourceID{
+				subscriptionID: "8a6d2f1e",
+				resourceGroup:  "rg-prod",
+			},
+		},
+		"tenant": {
+			id: "/tenants/72f988bf/providers/Microsoft.aadiam",
+			expected: resourceID{
+				providerNamespace: "Microsoft.aadiam",
+			},
+		},
+		"missing_subscription_id": {
+			id:       "/subscriptions",
+			expected: resourceID{},
+		},
+		"not_a_resource_id": {
+			id:       "/RESOURCE_ID",
+			expected: resourceID{},
+		},
+		"empty": {
+			id:       "",
+			expected: resourceID{},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, test.expected, parseResourceID(test.id))
+		})
+	}
+}
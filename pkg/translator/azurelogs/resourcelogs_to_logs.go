@@ -4,10 +4,15 @@
 package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	gojson "github.com/goccy/go-json"
@@ -44,10 +49,12 @@ const (
 
 var errMissingTimestamp = errors.New("missing timestamp")
 
-// as exported via an Azure Event Hub
-type azureRecords struct {
-	Records []azureLogRecord `json:"records"`
-}
+// DefaultMaxDecompressedSize is the size, in bytes, above which a gzip
+// compressed payload is rejected when no other size is configured.
+const DefaultMaxDecompressedSize = 64 * 1024 * 1024
+
+// gzipMagic are the first bytes of a gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
 
 // azureLogRecord represents a single Azure log following
 // the common schema:
@@ -66,6 +73,8 @@ type azureLogRecord struct {
 	DurationMs        *json.Number    `json:"durationMs"`
 	CallerIPAddress   *string         `json:"callerIpAddress"`
 	CorrelationID     *string         `json:"correlationId"`
+	OperationID       *string         `json:"operationId"`
+	ParentID          *string         `json:"parentId"`
 	Identity          *any            `json:"identity"`
 	Level             *json.Number    `json:"Level"`
 	Location          *string         `json:"location"`
@@ -74,87 +83,562 @@ type azureLogRecord struct {
 
 var _ plog.Unmarshaler = (*ResourceLogsUnmarshaler)(nil)
 
+// ErrorMode defines how UnmarshalLogs handles records that cannot be decoded.
+type ErrorMode string
+
+const (
+	// ErrorModeStrict fails the entire payload on the first record that
+	// cannot be decoded. It is the default.
+	ErrorModeStrict ErrorMode = "strict"
+	// ErrorModePermissive skips the records that cannot be decoded and
+	// returns the logs of the other records alongside a *PartialError.
+	ErrorModePermissive ErrorMode = "permissive"
+)
+
+// RawRecordMode defines where the original JSON of each record is kept.
+type RawRecordMode string
+
+const (
+	// RawRecordModeNone does not keep the original record. It is the default.
+	RawRecordModeNone RawRecordMode = "none"
+	// RawRecordModeBody replaces the body of the log records with the
+	// original record.
+	RawRecordModeBody RawRecordMode = "body"
+	// RawRecordModeAttribute keeps the original record in the azure.raw
+	// attribute of the log records.
+	RawRecordModeAttribute RawRecordMode = "attribute"
+
+	// DefaultRawRecordMaxSize is the size, in bytes, above which the
+	// original record is truncated when no other size is configured.
+	DefaultRawRecordMaxSize = 64 * 1024
+
+	// attributeAzureRaw holds the original record in RawRecordModeAttribute.
+	attributeAzureRaw = "azure.raw"
+	// attributeAzureRawTruncated is set when the original record exceeds
+	// the maximum size and was truncated.
+	attributeAzureRawTruncated = "azure.raw.truncated"
+)
+
+// RecordError is the error of a single record that was skipped in
+// permissive mode.
+type RecordError struct {
+	// Index is the position of the record in the records array
+	Index int
+	Err   error
+}
+
+func (e RecordError) Error() string {
+	return fmt.Sprintf("record %d: %v", e.Index, e.Err)
+}
+
+func (e RecordError) Unwrap() error {
+	return e.Err
+}
+
+// PartialError is returned in permissive mode when some records were
+// skipped. The logs of the other records are still returned.
+type PartialError struct {
+	Records []RecordError
+}
+
+func (e *PartialError) Error() string {
+	msgs := make([]string, len(e.Records))
+	for i, record := range e.Records {
+		msgs[i] = record.Error()
+	}
+	return fmt.Sprintf("skipped %d malformed record(s): %s", len(e.Records), strings.Join(msgs, "; "))
+}
+
+func (e *PartialError) Unwrap() []error {
+	errs := make([]error, len(e.Records))
+	for i, record := range e.Records {
+		errs[i] = record
+	}
+	return errs
+}
+
+// TruncationReason is the limit a truncated payload exceeded.
+type TruncationReason string
+
+const (
+	// TruncationReasonMaxRecords is the reason of the payloads holding
+	// more than MaxRecords records.
+	TruncationReasonMaxRecords TruncationReason = "max_records"
+	// TruncationReasonMaxPayloadSize is the reason of the payloads whose
+	// records are larger than MaxPayloadSize bytes.
+	TruncationReasonMaxPayloadSize TruncationReason = "max_payload_size"
+
+	// attributeAzureTruncationReason holds the limit a truncated payload
+	// exceeded on the truncation marker record.
+	attributeAzureTruncationReason = "azure.truncation.reason"
+	// attributeAzureTruncationProcessedRecords holds the number of records
+	// of a truncated payload that were processed.
+	attributeAzureTruncationProcessedRecords = "azure.truncation.processed_records"
+	// attributeAzureTruncationDroppedRecords holds the number of records of
+	// a truncated payload that were dropped.
+	attributeAzureTruncationDroppedRecords = "azure.truncation.dropped_records"
+)
+
+// TruncationError is returned when a payload exceeds MaxRecords or
+// MaxPayloadSize. Unlike a *PartialError or a decoding error, it does not
+// mean the payload is corrupt: the records up to the limit are returned, and
+// the dropped ones could be processed with higher limits.
+type TruncationError struct {
+	Reason TruncationReason
+	// Processed is the number of records before the limit
+	Processed int
+	// Dropped is the number of records beyond the limit
+	Dropped int
+}
+
+func (e *TruncationError) Error() string {
+	return fmt.Sprintf("payload truncated (%s): %d record(s) processed, %d record(s) dropped", e.Reason, e.Processed, e.Dropped)
+}
+
+// SeverityMapping assigns a severity to the records that do not hold a
+// Level, or whose Level does not map to a severity.
+type SeverityMapping struct {
+	// ResultTypes maps the result type of a record to a severity. Besides
+	// exact result types, e.g. "Failed", HTTP status classes such as "5xx"
+	// match the 3 digit result types of that class. Exact result types take
+	// precedence over the classes, and result types over categories.
+	ResultTypes map[string]plog.SeverityNumber
+	// Categories maps the category of a record to a severity.
+	Categories map[string]plog.SeverityNumber
+}
+
+// severity returns the severity of the record, if any is mapped for it.
+func (m SeverityMapping) severity(log azureLogRecord) (plog.SeverityNumber, bool) {
+	if log.ResultType != nil {
+		resultType := *log.ResultType
+		if severity, ok := m.ResultTypes[resultType]; ok {
+			return severity, true
+		}
+		if isStatusCode(resultType) {
+			if severity, ok := m.ResultTypes[resultType[:1]+"xx"]; ok {
+				return severity, true
+			}
+		}
+	}
+	severity, ok := m.Categories[log.Category]
+	return severity, ok
+}
+
+// isStatusCode reports whether s looks like an HTTP status code.
+func isStatusCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 type ResourceLogsUnmarshaler struct {
 	Version     string
 	Logger      *zap.Logger
 	TimeFormats []string
+	// CategoryTimeFormats are the time layouts tried first for the
+	// records of a category, before TimeFormats and ISO 8601, for the
+	// categories that do not ship ISO 8601 timestamps.
+	CategoryTimeFormats map[string][]string
+	// ErrorMode defines how records that cannot be decoded are
+	// handled, defaults to ErrorModeStrict.
+	ErrorMode ErrorMode
+	// LegacyResourceAttributes only sets the full resource ID as the
+	// cloud.resource_id resource attribute, instead of also adding its
+	// subscription, resource group, provider namespace and name.
+	LegacyResourceAttributes bool
+	// SeverityMapping assigns a severity to the records without a
+	// Level, which are Unspecified otherwise.
+	SeverityMapping SeverityMapping
+	// RawRecordMode keeps the original JSON of each record in the body
+	// or in an attribute of its log records, alongside the attributes
+	// extracted from it. Defaults to RawRecordModeNone.
+	RawRecordMode RawRecordMode
+	// RawRecordMaxSize is the size, in bytes, above which the original
+	// record is truncated. Defaults to DefaultRawRecordMaxSize.
+	RawRecordMaxSize int
+	// MaxDecompressedSize is the size, in bytes, above which a gzip
+	// compressed payload is rejected once decompressed. Defaults to
+	// DefaultMaxDecompressedSize.
+	MaxDecompressedSize int64
+	// ResourceAttributes are static attributes set on every resource,
+	// e.g. the Event Hub namespace and consumer group the payload was
+	// consumed from. They do not override the attributes derived from
+	// the records.
+	ResourceAttributes map[string]string
+	// Categories holds the processors mapping the records of each
+	// category to log records. Defaults to the categories supported by
+	// this package, see NewCategoryRegistry to register more.
+	Categories *CategoryRegistry
+	// TraceContext sets the trace and span IDs of the log records from
+	// the operationId, or otherwise correlationId, and parentId of the
+	// records, when they are valid W3C trace context IDs.
+	TraceContext bool
+	// MaxRecords is the number of records processed per payload, the
+	// records beyond it are dropped. Unlimited when 0.
+	MaxRecords int
+	// MaxPayloadSize is the size, in bytes, of the records processed per
+	// payload, the records beyond it are dropped. Unlimited when 0.
+	MaxPayloadSize int64
+	// Normalization normalizes the units and types of the durations and
+	// byte counts of the records. Disabled by default.
+	Normalization UnitNormalization
+	// GeoIP, when set, adds the location of the caller IP of the log
+	// records, from their client.address or source.address attribute or
+	// otherwise the callerIpAddress of the record, to their attributes.
+	GeoIP GeoIPResolver
 }
 
+// UnmarshalLogs decodes the records exported via an Azure Event Hub, e.g. {"records": [...]}.
+// The records array is decoded one record at a time rather than being materialized as a
+// whole, so that the peak memory of large batches stays close to the size of the payload.
+//
+// In permissive mode, records that cannot be decoded or converted are skipped and a
+// *PartialError holding their index is returned alongside the logs of the other records.
+// A payload that is not valid JSON always fails entirely.
+//
+// Gzip compressed payloads, as delivered by Event Hub capture and some forwarders, are
+// detected by their magic bytes and decompressed before being decoded.
+//
+// Payloads exceeding MaxRecords or MaxPayloadSize are processed up to the limit, a
+// truncation marker record is appended, and a *TruncationError is returned alongside the
+// logs, joined with the *PartialError in permissive mode, if any.
 func (r ResourceLogsUnmarshaler) UnmarshalLogs(buf []byte) (plog.Logs, error) {
+	buf, err := decompress(buf, r.MaxDecompressedSize)
+	if err != nil {
+		return plog.Logs{}, err
+	}
+
 	iter := jsoniter.ConfigFastest.BorrowIterator(buf)
 	defer jsoniter.ConfigFastest.ReturnIterator(iter)
 
-	var azureLogs azureRecords
-	iter.ReadVal(&azureLogs)
+	permissive := r.ErrorMode == ErrorModePermissive
+	keepRaw := r.RawRecordMode == RawRecordModeBody || r.RawRecordMode == RawRecordModeAttribute
+	var partialErr *PartialError
+	var truncErr *TruncationError
+	var payloadSize int64
+	truncate := func(index int, reason TruncationReason) {
+		truncErr = &TruncationError{Reason: reason, Processed: index, Dropped: 1}
+		r.Logger.Warn("Truncating payload", zap.String("reason", string(reason)), zap.Int("processed", index))
+	}
+	skip := func(index int, err error) {
+		if partialErr == nil {
+			partialErr = &PartialError{}
+		}
+		partialErr.Records = append(partialErr.Records, RecordError{Index: index, Err: err})
+		r.Logger.Warn("Skipping malformed record", zap.Int("index", index), zap.Error(err))
+	}
+
+	allResourceScopeLogs := newResourceGroups(func() plog.ScopeLogs {
+		scopeLogs := plog.NewScopeLogs()
+		scopeLogs.SetSchemaUrl(schemaURL())
+		scopeLogs.Scope().SetName(scopeName)
+		scopeLogs.Scope().SetVersion(r.Version)
+		return scopeLogs
+	})
+	for field := iter.ReadObject(); field != ""; field = iter.ReadObject() {
+		if !strings.EqualFold(field, "records") {
+			iter.Skip()
+			continue
+		}
+		for index := 0; iter.ReadArray(); index++ {
+			if truncErr == nil && r.MaxRecords > 0 && index >= r.MaxRecords {
+				truncate(index, TruncationReasonMaxRecords)
+				iter.Skip()
+				continue
+			}
+			if truncErr != nil {
+				truncErr.Dropped++
+				iter.Skip()
+				continue
+			}
+			var log azureLogRecord
+			var raw []byte
+			if permissive || keepRaw || r.MaxPayloadSize > 0 {
+				// the record is first delimited so that decoding
+				// errors do not leave the iterator in a broken state
+				raw = iter.SkipAndReturnBytes()
+				if iter.Error != nil {
+					break
+				}
+				if r.MaxPayloadSize > 0 {
+					if payloadSize+int64(len(raw)) > r.MaxPayloadSize {
+						truncate(index, TruncationReasonMaxPayloadSize)
+						continue
+					}
+					payloadSize += int64(len(raw))
+				}
+				if err := jsoniter.ConfigFastest.Unmarshal(raw, &log); err != nil {
+					if permissive {
+						skip(index, err)
+						continue
+					}
+					return plog.Logs{}, fmt.Errorf("JSON parse failed: %w", err)
+				}
+				if !keepRaw {
+					raw = nil
+				}
+			} else {
+				iter.ReadVal(&log)
+				if iter.Error != nil {
+					break
+				}
+			}
+			if err := r.addLogRecord(log, raw, allResourceScopeLogs); err != nil {
+				if permissive {
+					skip(index, err)
+					continue
+				}
+				return plog.Logs{}, err
+			}
+		}
+	}
 
 	if iter.Error != nil {
 		return plog.Logs{}, fmt.Errorf("JSON parse failed: %w", iter.Error)
 	}
 
-	allResourceScopeLogs := map[string]plog.ScopeLogs{}
-	for _, log := range azureLogs.Records {
-		scopeLogs, found := allResourceScopeLogs[log.ResourceID]
-		if !found {
-			scopeLogs = plog.NewScopeLogs()
-			scopeLogs.Scope().SetName(scopeName)
-			scopeLogs.Scope().SetVersion(r.Version)
-			allResourceScopeLogs[log.ResourceID] = scopeLogs
+	l := plog.NewLogs()
+	allResourceScopeLogs.each(func(resourceID string, scopeLogs plog.ScopeLogs) {
+		rl := l.ResourceLogs().AppendEmpty()
+		rl.SetSchemaUrl(schemaURL())
+		rl.Resource().Attributes().PutStr(string(conventions.CloudProviderKey), conventions.CloudProviderAzure.Value.AsString())
+		rl.Resource().Attributes().PutStr(string(conventions.CloudResourceIDKey), resourceID)
+		rl.Resource().Attributes().PutStr(string(conventions.EventNameKey), "az.resource.log")
+		if !r.LegacyResourceAttributes {
+			addResourceIDAttributes(resourceID, rl.Resource().Attributes())
 		}
-
-		nanos, err := getTimestamp(log, r.TimeFormats...)
-		if err != nil {
-			r.Logger.Warn("Unable to convert timestamp from log", zap.String("timestamp", log.Time))
-			continue
+		for key, value := range r.ResourceAttributes {
+			if _, ok := rl.Resource().Attributes().Get(key); !ok {
+				rl.Resource().Attributes().PutStr(key, value)
+			}
 		}
+		scopeLogs.MoveTo(rl.ScopeLogs().AppendEmpty())
+	})
+	if truncErr != nil {
+		r.addTruncationMarker(l, truncErr)
+	}
+	if useLatestSemconvGate.IsEnabled() {
+		upgradeLogs(l)
+	}
 
-		lr := scopeLogs.LogRecords().AppendEmpty()
-		lr.SetTimestamp(nanos)
+	switch {
+	case partialErr != nil && truncErr != nil:
+		return l, errors.Join(partialErr, truncErr)
+	case partialErr != nil:
+		return l, partialErr
+	case truncErr != nil:
+		return l, truncErr
+	}
+	return l, nil
+}
 
-		if log.Level != nil {
-			severity := asSeverity(*log.Level)
-			lr.SetSeverityNumber(severity)
-			lr.SetSeverityText(log.Level.String())
-		}
+// addTruncationMarker appends a record marking the truncation of the payload,
+// in a resource of its own as it does not belong to any Azure resource.
+func (r ResourceLogsUnmarshaler) addTruncationMarker(l plog.Logs, truncErr *TruncationError) {
+	rl := l.ResourceLogs().AppendEmpty()
+	rl.SetSchemaUrl(schemaURL())
+	rl.Resource().Attributes().PutStr(string(conventions.CloudProviderKey), conventions.CloudProviderAzure.Value.AsString())
+	for key, value := range r.ResourceAttributes {
+		rl.Resource().Attributes().PutStr(key, value)
+	}
+	scopeLogs := rl.ScopeLogs().AppendEmpty()
+	scopeLogs.SetSchemaUrl(schemaURL())
+	scopeLogs.Scope().SetName(scopeName)
+	scopeLogs.Scope().SetVersion(r.Version)
+
+	lr := scopeLogs.LogRecords().AppendEmpty()
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	lr.SetSeverityNumber(plog.SeverityNumberWarn)
+	lr.Body().SetStr(truncErr.Error())
+	lr.Attributes().PutStr(attributeAzureTruncationReason, string(truncErr.Reason))
+	lr.Attributes().PutInt(attributeAzureTruncationProcessedRecords, int64(truncErr.Processed))
+	lr.Attributes().PutInt(attributeAzureTruncationDroppedRecords, int64(truncErr.Dropped))
+}
 
-		err = addRecordAttributes(log.Category, log.Properties, lr)
-		if err != nil {
-			if errors.Is(err, errStillToImplement) || errors.Is(err, errUnsupportedCategory) {
-				// TODO @constanca-m This will be removed once the categories
-				// are properly mapped to the semantic conventions in
-				// category_logs.go
-				err = lr.Body().FromRaw(extractRawAttributes(log))
-				if err != nil {
-					return plog.Logs{}, err
-				}
-				continue
-			}
+// decompress returns the decompressed payload when buf is gzip compressed,
+// and buf itself otherwise. Payloads larger than maxSize bytes once
+// decompressed, or DefaultMaxDecompressedSize when it is not set, are
+// rejected.
+func decompress(buf []byte, maxSize int64) ([]byte, error) {
+	if !bytes.HasPrefix(buf, gzipMagic) {
+		return buf, nil
+	}
+	if maxSize <= 0 {
+		maxSize = DefaultMaxDecompressedSize
+	}
 
-			correlationID := "unknown"
-			if log.CorrelationID != nil {
-				correlationID = *log.CorrelationID
-			}
+	reader, err := gzip.NewReader(bytes.NewReader(buf))
+	if err != nil {
+		return nil, fmt.Errorf("gzip decompression failed: %w", err)
+	}
+	defer reader.Close()
+	decompressed, err := io.ReadAll(io.LimitReader(reader, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("gzip decompression failed: %w", err)
+	}
+	if int64(len(decompressed)) > maxSize {
+		return nil, fmt.Errorf("decompressed payload exceeds the maximum size of %d bytes", maxSize)
+	}
+	return decompressed, nil
+}
+
+// addLogRecord converts a single Azure log record into log records appended to the scope
+// logs of its resource. The original record, raw, is kept on them when it is not nil.
+// Only errors that should abort the whole batch are returned.
+func (r ResourceLogsUnmarshaler) addLogRecord(log azureLogRecord, raw []byte, allResourceScopeLogs *resourceGroups[plog.ScopeLogs]) error {
+	scopeLogs := allResourceScopeLogs.get(log.ResourceID)
+	if r.GeoIP != nil {
+		first := scopeLogs.LogRecords().Len()
+		defer func() {
+			r.addGeoAttributes(log, scopeLogs.LogRecords(), first)
+		}()
+	}
+	if raw != nil {
+		first := scopeLogs.LogRecords().Len()
+		defer func() {
+			r.addRawRecord(raw, scopeLogs.LogRecords(), first)
+		}()
+	}
+
+	nanos, err := getTimestamp(log, r.timeFormats(log.Category)...)
+	if err != nil {
+		r.Logger.Warn("Unable to convert timestamp from log", zap.String("timestamp", log.Time))
+		return nil
+	}
+
+	processor, registered := r.categories().lookup(log.Category)
+	if !registered && log.Category == categoryNetworkSecurityGroupFlowEvent {
+		// Each flow log record holds many flows, one log record is created per flow
+		if err = addNSGFlowLogRecords(log, nanos, scopeLogs.LogRecords()); err != nil {
 			r.Logger.Error(
 				"unable to convert log record",
 				zap.String("category", log.Category),
 				zap.String("resource id", log.ResourceID),
-				zap.String("correlation id", correlationID),
 				zap.Error(err),
 			)
-		} else {
-			addCommonSchema(log, lr)
 		}
+		return nil
 	}
 
-	l := plog.NewLogs()
-	for resourceID, scopeLogs := range allResourceScopeLogs {
-		rl := l.ResourceLogs().AppendEmpty()
-		rl.Resource().Attributes().PutStr(string(conventions.CloudProviderKey), conventions.CloudProviderAzure.Value.AsString())
-		rl.Resource().Attributes().PutStr(string(conventions.CloudResourceIDKey), resourceID)
-		rl.Resource().Attributes().PutStr(string(conventions.EventNameKey), "az.resource.log")
-		scopeLogs.MoveTo(rl.ScopeLogs().AppendEmpty())
+	lr := scopeLogs.LogRecords().AppendEmpty()
+	lr.SetTimestamp(nanos)
+	if r.TraceContext {
+		setTraceContext(log, lr)
 	}
 
-	return l, nil
+	if log.Level != nil {
+		severity := asSeverity(*log.Level)
+		lr.SetSeverityNumber(severity)
+		lr.SetSeverityText(log.Level.String())
+	}
+	if lr.SeverityNumber() == plog.SeverityNumberUnspecified {
+		if severity, ok := r.SeverityMapping.severity(log); ok {
+			lr.SetSeverityNumber(severity)
+		} else if registered {
+			if severity, ok := processor.Severity(log.Properties); ok {
+				lr.SetSeverityNumber(severity)
+			}
+		}
+	}
+
+	switch {
+	case registered:
+		err = processRecord(processor, log, lr)
+	case isActivityLogCategory(log.Category):
+		err = addActivityLogAttributes(log, lr)
+	default:
+		err = fmt.Errorf("failed to parse logs from category %q: %w", log.Category, errUnsupportedCategory)
+	}
+	if err != nil {
+		if errors.Is(err, errStillToImplement) || errors.Is(err, errUnsupportedCategory) {
+			// TODO @constanca-m This will be removed once the categories
+			// are properly mapped to the semantic conventions in
+			// category_logs.go
+			if err = lr.Body().FromRaw(extractRawAttributes(log)); err != nil {
+				return err
+			}
+			r.Normalization.normalize(log, lr)
+			return nil
+		}
+
+		correlationID := "unknown"
+		if log.CorrelationID != nil {
+			correlationID = *log.CorrelationID
+		}
+		r.Logger.Error(
+			"unable to convert log record",
+			zap.String("category", log.Category),
+			zap.String("resource id", log.ResourceID),
+			zap.String("correlation id", correlationID),
+			zap.Error(err),
+		)
+	} else {
+		addCommonSchema(log, lr)
+		r.Normalization.normalize(log, lr)
+	}
+	return nil
+}
+
+// categories returns the registry of the supported categories.
+func (r ResourceLogsUnmarshaler) categories() *CategoryRegistry {
+	if r.Categories != nil {
+		return r.Categories
+	}
+	return defaultCategories
+}
+
+// processRecord adds the attributes and the body of the log record of a
+// record with the processor of its category.
+func processRecord(processor CategoryLogProcessor, log azureLogRecord, record plog.LogRecord) error {
+	if err := processor.AddAttributes(log.Properties, record); err != nil {
+		return fmt.Errorf("failed to parse logs from category %q: %w", log.Category, err)
+	}
+	if err := processor.Body(log.Properties, record.Body()); err != nil {
+		return fmt.Errorf("failed to set the body of logs from category %q: %w", log.Category, err)
+	}
+	return nil
+}
+
+// addRawRecord keeps the original record on the log records starting at index first,
+// truncated to the maximum size.
+func (r ResourceLogsUnmarshaler) addRawRecord(raw []byte, records plog.LogRecordSlice, first int) {
+	maxSize := r.RawRecordMaxSize
+	if maxSize <= 0 {
+		maxSize = DefaultRawRecordMaxSize
+	}
+	raw = bytes.TrimSpace(raw)
+	truncated := len(raw) > maxSize
+	if truncated {
+		raw = raw[:maxSize]
+	}
+	value := string(raw)
+
+	for i := first; i < records.Len(); i++ {
+		lr := records.At(i)
+		if r.RawRecordMode == RawRecordModeBody {
+			lr.Body().SetStr(value)
+		} else {
+			lr.Attributes().PutStr(attributeAzureRaw, value)
+		}
+		if truncated {
+			lr.Attributes().PutBool(attributeAzureRawTruncated, true)
+		}
+	}
+}
+
+// timeFormats returns the time layouts to try for the records of category.
+func (r ResourceLogsUnmarshaler) timeFormats(category string) []string {
+	categoryFormats, ok := r.CategoryTimeFormats[category]
+	if !ok {
+		return r.TimeFormats
+	}
+	return append(slices.Clip(categoryFormats), r.TimeFormats...)
 }
 
 func getTimestamp(record azureLogRecord, formats ...string) (pcommon.Timestamp, error) {
@@ -193,7 +677,7 @@ func asTimestamp(s string, formats ...string) (pcommon.Timestamp, error) {
 // valid, then the 'Unspecified' value is returned.
 func asSeverity(number json.Number) plog.SeverityNumber {
 	switch number.String() {
-	case "Informational":
+	case "Information", "Informational":
 		return plog.SeverityNumberInfo
 	case "Warning":
 		return plog.SeverityNumberWarn
@@ -225,6 +709,31 @@ func addCommonSchema(log azureLogRecord, record plog.LogRecord) {
 	// TODO Keep adding other common fields, like tenant ID
 }
 
+// addNSGFlowLogRecords appends one log record per flow of the network
+// security group flow log. Nothing is appended if any of the flows is invalid.
+func addNSGFlowLogRecords(log azureLogRecord, nanos pcommon.Timestamp, records plog.LogRecordSlice) error {
+	tuples, err := parseNSGFlowTuples(log.Properties)
+	if err != nil {
+		return fmt.Errorf("failed to parse logs from category %q: %w", log.Category, err)
+	}
+
+	flows := plog.NewLogRecordSlice()
+	flows.EnsureCapacity(len(tuples))
+	for _, tuple := range tuples {
+		lr := flows.AppendEmpty()
+		lr.SetTimestamp(nanos)
+		if tuple.timestamp > 0 {
+			lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Unix(tuple.timestamp, 0)))
+		}
+		if err := addNSGFlowTupleProperties(tuple, lr); err != nil {
+			return fmt.Errorf("failed to parse logs from category %q: %w", log.Category, err)
+		}
+		addCommonSchema(log, lr)
+	}
+	flows.MoveAndAppendTo(records)
+	return nil
+}
+
 func extractRawAttributes(log azureLogRecord) map[string]any {
 	attrs := map[string]any{}
 
@@ -4,10 +4,16 @@
 package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -331,6 +337,10 @@ func TestUnmarshalLogs_FrontDoorWebApplicationFirewallLog(t *testing.T) {
 			logFilename:      "valid_1.json",
 			expectedFilename: "valid_1_expected.yaml",
 		},
+		"valid_2": {
+			logFilename:      "valid_2.json",
+			expectedFilename: "valid_2_expected.yaml",
+		},
 	}
 
 	u := &ResourceLogsUnmarshaler{
@@ -372,6 +382,210 @@ func TestUnmarshalLogs_FrontDoorAccessLog(t *testing.T) {
 			logFilename:      "valid_1.json",
 			expectedFilename: "valid_1_expected.yaml",
 		},
+		"valid_2": {
+			logFilename:      "valid_2.json",
+			expectedFilename: "valid_2_expected.yaml",
+		},
+	}
+
+	u := &ResourceLogsUnmarshaler{
+		Version: testBuildInfo.Version,
+		Logger:  zap.NewNop(),
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, test.logFilename))
+			require.NoError(t, err)
+
+			logs, err := u.UnmarshalLogs(data)
+
+			if test.expectsErr != "" {
+				require.ErrorContains(t, err, test.expectsErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			expectedLogs, err := golden.ReadLogs(filepath.Join(dir, test.expectedFilename))
+			require.NoError(t, err)
+			require.NoError(t, plogtest.CompareLogs(expectedLogs, logs, plogtest.IgnoreResourceLogsOrder()))
+		})
+	}
+}
+
+func TestUnmarshalLogs_KubeAudit(t *testing.T) {
+	t.Parallel()
+
+	dir := "testdata/kubeaudit"
+	tests := map[string]struct {
+		logFilename      string
+		expectedFilename string
+		expectsErr       string
+	}{
+		"valid_1": {
+			logFilename:      "valid_1.json",
+			expectedFilename: "valid_1_expected.yaml",
+		},
+		"valid_2": {
+			logFilename:      "valid_2.json",
+			expectedFilename: "valid_2_expected.yaml",
+		},
+		"invalid_log": {
+			logFilename:      "invalid_log.json",
+			expectedFilename: "invalid_log_expected.yaml",
+		},
+	}
+
+	u := &ResourceLogsUnmarshaler{
+		Version: testBuildInfo.Version,
+		Logger:  zap.NewNop(),
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, test.logFilename))
+			require.NoError(t, err)
+
+			logs, err := u.UnmarshalLogs(data)
+
+			if test.expectsErr != "" {
+				require.ErrorContains(t, err, test.expectsErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			expectedLogs, err := golden.ReadLogs(filepath.Join(dir, test.expectedFilename))
+			require.NoError(t, err)
+			require.NoError(t, plogtest.CompareLogs(expectedLogs, logs, plogtest.IgnoreResourceLogsOrder()))
+		})
+	}
+}
+
+func TestUnmarshalLogs_SQLSecurityAuditEvents(t *testing.T) {
+	t.Parallel()
+
+	dir := "testdata/sqlsecurityauditevents"
+	tests := map[string]struct {
+		logFilename      string
+		expectedFilename string
+		expectsErr       string
+	}{
+		"valid_1": {
+			logFilename:      "valid_1.json",
+			expectedFilename: "valid_1_expected.yaml",
+		},
+		"valid_2": {
+			logFilename:      "valid_2.json",
+			expectedFilename: "valid_2_expected.yaml",
+		},
+		"invalid_succeeded": {
+			logFilename:      "invalid_succeeded.json",
+			expectedFilename: "invalid_succeeded_expected.yaml",
+		},
+	}
+
+	u := &ResourceLogsUnmarshaler{
+		Version: testBuildInfo.Version,
+		Logger:  zap.NewNop(),
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, test.logFilename))
+			require.NoError(t, err)
+
+			logs, err := u.UnmarshalLogs(data)
+
+			if test.expectsErr != "" {
+				require.ErrorContains(t, err, test.expectsErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			expectedLogs, err := golden.ReadLogs(filepath.Join(dir, test.expectedFilename))
+			require.NoError(t, err)
+			require.NoError(t, plogtest.CompareLogs(expectedLogs, logs, plogtest.IgnoreResourceLogsOrder()))
+		})
+	}
+}
+
+func TestUnmarshalLogs_ActivityLog(t *testing.T) {
+	t.Parallel()
+
+	dir := "testdata/activitylog"
+	tests := map[string]struct {
+		logFilename      string
+		expectedFilename string
+		expectsErr       string
+	}{
+		"valid_1": {
+			logFilename:      "valid_1.json",
+			expectedFilename: "valid_1_expected.yaml",
+		},
+		"valid_2": {
+			logFilename:      "valid_2.json",
+			expectedFilename: "valid_2_expected.yaml",
+		},
+		"valid_3": {
+			logFilename:      "valid_3.json",
+			expectedFilename: "valid_3_expected.yaml",
+		},
+		"invalid_properties": {
+			logFilename:      "invalid_properties.json",
+			expectedFilename: "invalid_properties_expected.yaml",
+		},
+	}
+
+	u := &ResourceLogsUnmarshaler{
+		Version: testBuildInfo.Version,
+		Logger:  zap.NewNop(),
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, test.logFilename))
+			require.NoError(t, err)
+
+			logs, err := u.UnmarshalLogs(data)
+
+			if test.expectsErr != "" {
+				require.ErrorContains(t, err, test.expectsErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			expectedLogs, err := golden.ReadLogs(filepath.Join(dir, test.expectedFilename))
+			require.NoError(t, err)
+			require.NoError(t, plogtest.CompareLogs(expectedLogs, logs, plogtest.IgnoreResourceLogsOrder()))
+		})
+	}
+}
+
+func TestUnmarshalLogs_NetworkSecurityGroupFlowEvent(t *testing.T) {
+	t.Parallel()
+
+	dir := "testdata/networksecuritygroupflowevent"
+	tests := map[string]struct {
+		logFilename      string
+		expectedFilename string
+		expectsErr       string
+	}{
+		"valid_1": {
+			logFilename:      "valid_1.json",
+			expectedFilename: "valid_1_expected.yaml",
+		},
+		"valid_2": {
+			logFilename:      "valid_2.json",
+			expectedFilename: "valid_2_expected.yaml",
+		},
+		"invalid_tuple": {
+			logFilename:      "invalid_tuple.json",
+			expectedFilename: "invalid_tuple_expected.yaml",
+		},
 	}
 
 	u := &ResourceLogsUnmarshaler{
@@ -482,3 +696,692 @@ func TestUnmarshalLogs_Files(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalLogs_Payload(t *testing.T) {
+	t.Parallel()
+
+	record := `{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/test", "category": "AppServiceAppLogs", "operationName": "AppLog"}`
+	tests := map[string]struct {
+		payload     string
+		expectedLen int
+		expectedErr string
+	}{
+		"records": {
+			payload:     `{"records": [` + record + `,` + record + `]}`,
+			expectedLen: 2,
+		},
+		"unknown_fields": {
+			payload:     `{"other": {"records": [` + record + `]}, "records": [` + record + `], "last": [1, 2]}`,
+			expectedLen: 1,
+		},
+		"case_insensitive_records": {
+			payload:     `{"Records": [` + record + `]}`,
+			expectedLen: 1,
+		},
+		"null_records": {
+			payload: `{"records": null}`,
+		},
+		"empty_object": {
+			payload: `{}`,
+		},
+		"not_an_object": {
+			payload:     `[` + record + `]`,
+			expectedErr: "JSON parse failed",
+		},
+		"truncated_records": {
+			payload:     `{"records": [` + record + `,` + record[:20],
+			expectedErr: "JSON parse failed",
+		},
+	}
+
+	u := &ResourceLogsUnmarshaler{
+		Version: testBuildInfo.Version,
+		Logger:  zap.NewNop(),
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			logs, err := u.UnmarshalLogs([]byte(test.payload))
+			if test.expectedErr != "" {
+				require.ErrorContains(t, err, test.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, test.expectedLen, logs.LogRecordCount())
+		})
+	}
+}
+
+func TestUnmarshalLogs_ErrorMode(t *testing.T) {
+	t.Parallel()
+
+	record := `{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/test", "category": "AppServiceAppLogs", "operationName": "AppLog"}`
+	malformed := `{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/test", "category": 1}`
+	payload := `{"records": [` + record + `,` + malformed + `,` + record + `,` + malformed + `]}`
+
+	tests := map[string]struct {
+		mode            ErrorMode
+		payload         string
+		expectedLen     int
+		expectedIndexes []int
+		expectsErr      string
+	}{
+		"default": {
+			payload:    payload,
+			expectsErr: "JSON parse failed",
+		},
+		"strict": {
+			mode:       ErrorModeStrict,
+			payload:    payload,
+			expectsErr: "JSON parse failed",
+		},
+		"permissive": {
+			mode:            ErrorModePermissive,
+			payload:         payload,
+			expectedLen:     2,
+			expectedIndexes: []int{1, 3},
+		},
+		"permissive_valid": {
+			mode:        ErrorModePermissive,
+			payload:     `{"records": [` + record + `]}`,
+			expectedLen: 1,
+		},
+		"permissive_invalid_json": {
+			mode:       ErrorModePermissive,
+			payload:    `{"records": [` + record + `,{"time": `,
+			expectsErr: "JSON parse failed",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := &ResourceLogsUnmarshaler{
+				Version:   testBuildInfo.Version,
+				Logger:    zap.NewNop(),
+				ErrorMode: test.mode,
+			}
+
+			logs, err := u.UnmarshalLogs([]byte(test.payload))
+			if test.expectsErr != "" {
+				require.ErrorContains(t, err, test.expectsErr)
+				return
+			}
+
+			require.Equal(t, test.expectedLen, logs.LogRecordCount())
+			if len(test.expectedIndexes) == 0 {
+				require.NoError(t, err)
+				return
+			}
+
+			var partialErr *PartialError
+			require.ErrorAs(t, err, &partialErr)
+			indexes := make([]int, 0, len(partialErr.Records))
+			for _, recordErr := range partialErr.Records {
+				indexes = append(indexes, recordErr.Index)
+			}
+			require.Equal(t, test.expectedIndexes, indexes)
+			require.ErrorContains(t, err, "skipped 2 malformed record(s)")
+		})
+	}
+}
+
+func TestUnmarshalLogs_Limits(t *testing.T) {
+	t.Parallel()
+
+	record := `{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/test", "category": "AppServiceAppLogs", "operationName": "AppLog"}`
+	malformed := `{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/test", "category": 1}`
+	payload := `{"records": [` + strings.Join([]string{record, record, record, record}, ",") + `]}`
+
+	tests := map[string]struct {
+		unmarshaler       ResourceLogsUnmarshaler
+		payload           string
+		expectedRecords   int
+		expectedTruncated *TruncationError
+		expectsPartial    bool
+	}{
+		"unlimited": {
+			payload:         payload,
+			expectedRecords: 4,
+		},
+		"within_limits": {
+			unmarshaler:     ResourceLogsUnmarshaler{MaxRecords: 4, MaxPayloadSize: int64(len(payload))},
+			payload:         payload,
+			expectedRecords: 4,
+		},
+		"max_records": {
+			unmarshaler:       ResourceLogsUnmarshaler{MaxRecords: 3},
+			payload:           payload,
+			expectedRecords:   3,
+			expectedTruncated: &TruncationError{Reason: TruncationReasonMaxRecords, Processed: 3, Dropped: 1},
+		},
+		"max_payload_size": {
+			unmarshaler:       ResourceLogsUnmarshaler{MaxPayloadSize: int64(2*len(record) + 1)},
+			payload:           payload,
+			expectedRecords:   2,
+			expectedTruncated: &TruncationError{Reason: TruncationReasonMaxPayloadSize, Processed: 2, Dropped: 2},
+		},
+		"permissive_max_records": {
+			unmarshaler: ResourceLogsUnmarshaler{ErrorMode: ErrorModePermissive, MaxRecords: 2},
+			payload:     `{"records": [` + strings.Join([]string{malformed, record, record}, ",") + `]}`,
+			// the malformed record counts towards the limit
+			expectedRecords:   1,
+			expectedTruncated: &TruncationError{Reason: TruncationReasonMaxRecords, Processed: 2, Dropped: 1},
+			expectsPartial:    true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := test.unmarshaler
+			u.Version = testBuildInfo.Version
+			u.Logger = zap.NewNop()
+
+			logs, err := u.UnmarshalLogs([]byte(test.payload))
+
+			var partialErr *PartialError
+			require.Equal(t, test.expectsPartial, errors.As(err, &partialErr))
+			if test.expectedTruncated == nil {
+				if !test.expectsPartial {
+					require.NoError(t, err)
+				}
+				require.Equal(t, test.expectedRecords, logs.LogRecordCount())
+				return
+			}
+
+			var truncErr *TruncationError
+			require.ErrorAs(t, err, &truncErr)
+			require.Equal(t, test.expectedTruncated, truncErr)
+
+			// the records up to the limit are followed by the marker record
+			require.Equal(t, test.expectedRecords+1, logs.LogRecordCount())
+			marker := logs.ResourceLogs().At(logs.ResourceLogs().Len() - 1)
+			_, hasResourceID := marker.Resource().Attributes().Get(string(conventions.CloudResourceIDKey))
+			require.False(t, hasResourceID)
+			lr := marker.ScopeLogs().At(0).LogRecords().At(0)
+			require.Equal(t, plog.SeverityNumberWarn, lr.SeverityNumber())
+			require.Equal(t, map[string]any{
+				attributeAzureTruncationReason:           string(test.expectedTruncated.Reason),
+				attributeAzureTruncationProcessedRecords: int64(test.expectedTruncated.Processed),
+				attributeAzureTruncationDroppedRecords:   int64(test.expectedTruncated.Dropped),
+			}, lr.Attributes().AsRaw())
+		})
+	}
+}
+
+func TestUnmarshalLogs_ResourceGrouping(t *testing.T) {
+	t.Parallel()
+
+	record := `{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": %q, "category": "AppServiceAppLogs", "operationName": "AppLog"}`
+	payload := `{"records": [` + strings.Join([]string{
+		fmt.Sprintf(record, "/SUBSCRIPTIONS/1/RESOURCEGROUPS/A/PROVIDERS/MICROSOFT.WEB/SITES/B"),
+		fmt.Sprintf(record, "/subscriptions/1/resourceGroups/a/providers/Microsoft.Web/sites/c"),
+		fmt.Sprintf(record, "/subscriptions/1/resourceGroups/a/providers/Microsoft.Web/sites/b"),
+		fmt.Sprintf(record, "/SUBSCRIPTIONS/1/RESOURCEGROUPS/A/PROVIDERS/MICROSOFT.WEB/SITES/C"),
+		fmt.Sprintf(record, "/SUBSCRIPTIONS/1/RESOURCEGROUPS/A/PROVIDERS/MICROSOFT.WEB/SITES/B"),
+	}, ",") + `]}`
+
+	u := ResourceLogsUnmarshaler{Version: testBuildInfo.Version, Logger: zap.NewNop()}
+	logs, err := u.UnmarshalLogs([]byte(payload))
+	require.NoError(t, err)
+
+	// one resource per resource ID regardless of its case, in the order of first appearance
+	require.Equal(t, 2, logs.ResourceLogs().Len())
+	expected := []struct {
+		resourceID string
+		records    int
+	}{
+		{resourceID: "/SUBSCRIPTIONS/1/RESOURCEGROUPS/A/PROVIDERS/MICROSOFT.WEB/SITES/B", records: 3},
+		{resourceID: "/subscriptions/1/resourceGroups/a/providers/Microsoft.Web/sites/c", records: 2},
+	}
+	for i, e := range expected {
+		rl := logs.ResourceLogs().At(i)
+		resourceID, ok := rl.Resource().Attributes().Get(string(conventions.CloudResourceIDKey))
+		require.True(t, ok)
+		assert.Equal(t, e.resourceID, resourceID.Str())
+		require.Equal(t, 1, rl.ScopeLogs().Len())
+		assert.Equal(t, e.records, rl.ScopeLogs().At(0).LogRecords().Len())
+	}
+}
+
+func TestUnmarshalLogs_Normalization(t *testing.T) {
+	t.Parallel()
+
+	// a supported category, and a category kept in the body
+	payload := `{"records": [
+		{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/test", "category": "AppServiceAppLogs", "operationName": "AppLog", "durationMs": 250},
+		{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/test", "category": "Unknown", "operationName": "Op", "properties": {"latency": "20", "sentBytes": "512"}}
+	]}`
+
+	tests := map[string]struct {
+		normalization     UnitNormalization
+		expectedDurations []any
+		expectedSentBytes any
+	}{
+		"disabled": {
+			expectedDurations: []any{nil, nil},
+			expectedSentBytes: "512",
+		},
+		"enabled": {
+			normalization:     UnitNormalization{Duration: true, ByteCounts: true},
+			expectedDurations: []any{0.25, 0.02},
+			expectedSentBytes: int64(512),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := ResourceLogsUnmarshaler{
+				Version:       testBuildInfo.Version,
+				Logger:        zap.NewNop(),
+				Normalization: test.normalization,
+			}
+			logs, err := u.UnmarshalLogs([]byte(payload))
+			require.NoError(t, err)
+
+			records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+			require.Equal(t, 2, records.Len())
+			for i, expected := range test.expectedDurations {
+				duration, found := records.At(i).Attributes().Get(attributeAzureDuration)
+				if expected == nil {
+					assert.False(t, found)
+					continue
+				}
+				require.True(t, found)
+				assert.InDelta(t, expected, duration.Double(), 1e-9)
+			}
+			properties, found := records.At(1).Body().Map().Get(azureProperties)
+			require.True(t, found)
+			sentBytes, found := properties.Map().Get("sentBytes")
+			require.True(t, found)
+			assert.Equal(t, test.expectedSentBytes, sentBytes.AsRaw())
+		})
+	}
+}
+
+func TestUnmarshalLogs_LegacyResourceAttributes(t *testing.T) {
+	t.Parallel()
+
+	payload := `{"records": [{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/SUBSCRIPTIONS/SUB/RESOURCEGROUPS/GROUP/PROVIDERS/MICROSOFT.WEB/SITES/APP", "category": "AppServiceAppLogs", "operationName": "AppLog"}]}`
+
+	tests := map[string]struct {
+		legacy       bool
+		expectedKeys []string
+	}{
+		"default": {
+			expectedKeys: []string{
+				"cloud.provider",
+				"cloud.resource_id",
+				"event.name",
+				"cloud.account.id",
+				"cloud.resource_group",
+				"azure.resource.provider.namespace",
+				"azure.resource.name",
+			},
+		},
+		"legacy": {
+			legacy: true,
+			expectedKeys: []string{
+				"cloud.provider",
+				"cloud.resource_id",
+				"event.name",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := &ResourceLogsUnmarshaler{
+				Version:                  testBuildInfo.Version,
+				Logger:                   zap.NewNop(),
+				LegacyResourceAttributes: test.legacy,
+			}
+
+			logs, err := u.UnmarshalLogs([]byte(payload))
+			require.NoError(t, err)
+			require.Equal(t, 1, logs.ResourceLogs().Len())
+
+			var keys []string
+			logs.ResourceLogs().At(0).Resource().Attributes().Range(func(k string, _ pcommon.Value) bool {
+				keys = append(keys, k)
+				return true
+			})
+			require.Equal(t, test.expectedKeys, keys)
+		})
+	}
+}
+
+func TestUnmarshalLogs_ResourceAttributes(t *testing.T) {
+	t.Parallel()
+
+	payload := `{"records": [
+		{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/SUBSCRIPTIONS/SUB/RESOURCEGROUPS/GROUP/PROVIDERS/MICROSOFT.WEB/SITES/APP1", "category": "AppServiceAppLogs", "operationName": "AppLog"},
+		{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/SUBSCRIPTIONS/SUB/RESOURCEGROUPS/GROUP/PROVIDERS/MICROSOFT.WEB/SITES/APP2", "category": "AppServiceAppLogs", "operationName": "AppLog"}
+	]}`
+
+	u := &ResourceLogsUnmarshaler{
+		Version: testBuildInfo.Version,
+		Logger:  zap.NewNop(),
+		ResourceAttributes: map[string]string{
+			"azure.eventhub.namespace":      "telemetry",
+			"azure.eventhub.consumer.group": "$Default",
+			// derived attributes are not overridden
+			"cloud.provider": "other",
+		},
+	}
+
+	logs, err := u.UnmarshalLogs([]byte(payload))
+	require.NoError(t, err)
+	require.Equal(t, 2, logs.ResourceLogs().Len())
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		attrs := logs.ResourceLogs().At(i).Resource().Attributes().AsRaw()
+		assert.Equal(t, "telemetry", attrs["azure.eventhub.namespace"])
+		assert.Equal(t, "$Default", attrs["azure.eventhub.consumer.group"])
+		assert.Equal(t, "azure", attrs["cloud.provider"])
+	}
+}
+
+func TestUnmarshalLogs_TraceContext(t *testing.T) {
+	t.Parallel()
+
+	payload := `{"records": [{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/SUBSCRIPTIONS/SUB/RESOURCEGROUPS/GROUP/PROVIDERS/MICROSOFT.INSIGHTS/COMPONENTS/APP", "category": "AppTraces", "operationName": "Trace", "operationId": "4bf92f3577b34da6a3ce929d0e0e4736", "parentId": "00f067aa0ba902b7"}]}`
+
+	tests := map[string]struct {
+		traceContext    bool
+		expectedTraceID string
+		expectedSpanID  string
+	}{
+		"disabled": {},
+		"enabled": {
+			traceContext:    true,
+			expectedTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+			expectedSpanID:  "00f067aa0ba902b7",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := &ResourceLogsUnmarshaler{
+				Version:      testBuildInfo.Version,
+				Logger:       zap.NewNop(),
+				TraceContext: test.traceContext,
+			}
+
+			logs, err := u.UnmarshalLogs([]byte(payload))
+			require.NoError(t, err)
+			require.Equal(t, 1, logs.LogRecordCount())
+
+			lr := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			if test.expectedTraceID == "" {
+				assert.True(t, lr.TraceID().IsEmpty())
+				assert.True(t, lr.SpanID().IsEmpty())
+				return
+			}
+			assert.Equal(t, test.expectedTraceID, lr.TraceID().String())
+			assert.Equal(t, test.expectedSpanID, lr.SpanID().String())
+		})
+	}
+}
+
+func TestUnmarshalLogs_SeverityMapping(t *testing.T) {
+	t.Parallel()
+
+	mapping := SeverityMapping{
+		ResultTypes: map[string]plog.SeverityNumber{
+			"Failed": plog.SeverityNumberError,
+			"404":    plog.SeverityNumberInfo,
+			"4xx":    plog.SeverityNumberWarn,
+			"5xx":    plog.SeverityNumberError,
+		},
+		Categories: map[string]plog.SeverityNumber{
+			"AppServiceHTTPLogs": plog.SeverityNumberDebug,
+		},
+	}
+
+	tests := map[string]struct {
+		record   string
+		mapping  SeverityMapping
+		expected plog.SeverityNumber
+	}{
+		"no_mapping": {
+			record:   `"category": "AppServiceHTTPLogs", "resultType": "Failed"`,
+			expected: plog.SeverityNumberUnspecified,
+		},
+		"level": {
+			record:   `"category": "AppServiceHTTPLogs", "resultType": "Failed", "Level": "Warning"`,
+			mapping:  mapping,
+			expected: plog.SeverityNumberWarn,
+		},
+		"result_type": {
+			record:   `"category": "AppServiceHTTPLogs", "resultType": "Failed"`,
+			mapping:  mapping,
+			expected: plog.SeverityNumberError,
+		},
+		"exact_status_code": {
+			record:   `"category": "AppServiceHTTPLogs", "resultType": "404"`,
+			mapping:  mapping,
+			expected: plog.SeverityNumberInfo,
+		},
+		"status_class": {
+			record:   `"category": "AppServiceHTTPLogs", "resultType": "403"`,
+			mapping:  mapping,
+			expected: plog.SeverityNumberWarn,
+		},
+		"category": {
+			record:   `"category": "AppServiceHTTPLogs", "resultType": "200"`,
+			mapping:  mapping,
+			expected: plog.SeverityNumberDebug,
+		},
+		"unmapped": {
+			record:   `"category": "AppServiceAppLogs", "resultType": "Succeeded"`,
+			mapping:  mapping,
+			expected: plog.SeverityNumberUnspecified,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := &ResourceLogsUnmarshaler{
+				Version:         testBuildInfo.Version,
+				Logger:          zap.NewNop(),
+				SeverityMapping: test.mapping,
+			}
+
+			payload := `{"records": [{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/test", "operationName": "op", ` + test.record + `}]}`
+			logs, err := u.UnmarshalLogs([]byte(payload))
+			require.NoError(t, err)
+			require.Equal(t, 1, logs.LogRecordCount())
+			lr := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+			require.Equal(t, test.expected, lr.SeverityNumber())
+		})
+	}
+}
+
+func TestUnmarshalLogs_RawRecord(t *testing.T) {
+	t.Parallel()
+
+	record := `{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/test", "category": "AppServiceAppLogs", "operationName": "AppLog"}`
+	payload := `{"records": [ ` + record + ` ]}`
+
+	tests := map[string]struct {
+		mode              RawRecordMode
+		maxSize           int
+		expectedBody      string
+		expectedAttribute string
+		expectedTruncated bool
+	}{
+		"none": {
+			mode: RawRecordModeNone,
+		},
+		"body": {
+			mode:         RawRecordModeBody,
+			expectedBody: record,
+		},
+		"attribute": {
+			mode:              RawRecordModeAttribute,
+			expectedAttribute: record,
+		},
+		"truncated": {
+			mode:              RawRecordModeAttribute,
+			maxSize:           10,
+			expectedAttribute: record[:10],
+			expectedTruncated: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := &ResourceLogsUnmarshaler{
+				Version:          testBuildInfo.Version,
+				Logger:           zap.NewNop(),
+				RawRecordMode:    test.mode,
+				RawRecordMaxSize: test.maxSize,
+			}
+
+			logs, err := u.UnmarshalLogs([]byte(payload))
+			require.NoError(t, err)
+			require.Equal(t, 1, logs.LogRecordCount())
+			lr := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+
+			if test.expectedBody != "" {
+				require.Equal(t, test.expectedBody, lr.Body().Str())
+			} else {
+				require.NotEqual(t, pcommon.ValueTypeStr, lr.Body().Type())
+			}
+
+			raw, found := lr.Attributes().Get(attributeAzureRaw)
+			require.Equal(t, test.expectedAttribute != "", found)
+			if found {
+				require.Equal(t, test.expectedAttribute, raw.Str())
+			}
+
+			_, truncated := lr.Attributes().Get(attributeAzureRawTruncated)
+			require.Equal(t, test.expectedTruncated, truncated)
+		})
+	}
+}
+
+func TestUnmarshalLogs_RawRecordFlowEvents(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "networksecuritygroupflowevent", "valid_1.json"))
+	require.NoError(t, err)
+
+	u := &ResourceLogsUnmarshaler{
+		Version:       testBuildInfo.Version,
+		Logger:        zap.NewNop(),
+		RawRecordMode: RawRecordModeAttribute,
+	}
+	logs, err := u.UnmarshalLogs(data)
+	require.NoError(t, err)
+	require.Greater(t, logs.LogRecordCount(), 1)
+
+	// every flow of a record holds the record it originates from
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		records := logs.ResourceLogs().At(i).ScopeLogs().At(0).LogRecords()
+		for j := 0; j < records.Len(); j++ {
+			raw, found := records.At(j).Attributes().Get(attributeAzureRaw)
+			require.True(t, found)
+			require.True(t, json.Valid([]byte(raw.Str())))
+		}
+	}
+}
+
+func TestUnmarshalLogs_Gzip(t *testing.T) {
+	t.Parallel()
+
+	payload := []byte(`{"records": [{"time": "2024-04-24T12:06:12.0000000Z", "resourceId": "/test", "category": "AppServiceAppLogs", "operationName": "AppLog"}]}`)
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write(payload)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	tests := map[string]struct {
+		payload    []byte
+		maxSize    int64
+		expectsErr string
+	}{
+		"uncompressed": {
+			payload: payload,
+		},
+		"compressed": {
+			payload: compressed.Bytes(),
+		},
+		"exact_max_size": {
+			payload: compressed.Bytes(),
+			maxSize: int64(len(payload)),
+		},
+		"exceeds_max_size": {
+			payload:    compressed.Bytes(),
+			maxSize:    int64(len(payload)) - 1,
+			expectsErr: "exceeds the maximum size",
+		},
+		"corrupted": {
+			payload:    compressed.Bytes()[:len(compressed.Bytes())/2],
+			expectsErr: "gzip decompression failed",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			u := &ResourceLogsUnmarshaler{
+				Version:             testBuildInfo.Version,
+				Logger:              zap.NewNop(),
+				MaxDecompressedSize: test.maxSize,
+			}
+
+			logs, err := u.UnmarshalLogs(test.payload)
+			if test.expectsErr != "" {
+				require.ErrorContains(t, err, test.expectsErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, 1, logs.LogRecordCount())
+		})
+	}
+}
+
+func TestUnmarshalLogs_CategoryTimeFormats(t *testing.T) {
+	t.Parallel()
+
+	u := &ResourceLogsUnmarshaler{
+		Version:     testBuildInfo.Version,
+		Logger:      zap.NewNop(),
+		TimeFormats: []string{"01/02/2006 15:04:05"},
+		CategoryTimeFormats: map[string][]string{
+			"SQLSecurityAuditEvents": {"2006-01-02 15:04:05.000"},
+		},
+	}
+
+	payload := `{"records": [
+		{"time": "2024-11-20 13:57:18.123", "resourceId": "/sql", "category": "SQLSecurityAuditEvents", "operationName": "AuditEvent"},
+		{"time": "11/20/2024 13:57:18", "resourceId": "/sql", "category": "SQLSecurityAuditEvents", "operationName": "AuditEvent"},
+		{"time": "2024-11-20T13:57:18Z", "resourceId": "/sql", "category": "SQLSecurityAuditEvents", "operationName": "AuditEvent"},
+		{"time": "2024-11-20 13:57:18.123", "resourceId": "/web", "category": "AppServiceAppLogs", "operationName": "AppLog"},
+		{"time": "11/20/2024 13:57:18", "resourceId": "/web", "category": "AppServiceAppLogs", "operationName": "AppLog"}
+	]}`
+
+	logs, err := u.UnmarshalLogs([]byte(payload))
+	require.NoError(t, err)
+
+	timestamps := map[string][]time.Time{}
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		rl := logs.ResourceLogs().At(i)
+		resourceID, _ := rl.Resource().Attributes().Get(string(conventions.CloudResourceIDKey))
+		records := rl.ScopeLogs().At(0).LogRecords()
+		for j := 0; j < records.Len(); j++ {
+			timestamps[resourceID.Str()] = append(timestamps[resourceID.Str()], records.At(j).Timestamp().AsTime())
+		}
+	}
+
+	// the category layouts are tried first, then the common layouts and ISO 8601
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 11, 20, 13, 57, 18, 123000000, time.UTC),
+		time.Date(2024, 11, 20, 13, 57, 18, 0, time.UTC),
+		time.Date(2024, 11, 20, 13, 57, 18, 0, time.UTC),
+	}, timestamps["/sql"])
+	// the category layouts do not apply to other categories
+	assert.Equal(t, []time.Time{
+		time.Date(2024, 11, 20, 13, 57, 18, 0, time.UTC),
+	}, timestamps["/web"])
+}
@@ -0,0 +1,207 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	jsoniter "github.com/json-iterator/go"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	conventions "go.opentelemetry.io/otel/semconv/v1.27.0"
+	"go.uber.org/zap"
+)
+
+// metricsScopeName is the name of the scope of the translated metrics.
+const metricsScopeName = "otelcol/azureresourcemetrics"
+
+// azureMetricRecord represents a single Azure metric record, as exported by
+// the diagnostic settings. The aggregations are over the time grain of the
+// record, ending at its time. Any of them may be missing.
+type azureMetricRecord struct {
+	Time       string   `json:"time"`
+	ResourceID string   `json:"resourceId"`
+	MetricName string   `json:"metricName"`
+	TimeGrain  string   `json:"timeGrain"`
+	Total      *float64 `json:"total"`
+	Count      *float64 `json:"count"`
+	Minimum    *float64 `json:"minimum"`
+	Maximum    *float64 `json:"maximum"`
+	Average    *float64 `json:"average"`
+}
+
+var _ pmetric.Unmarshaler = (*ResourceMetricsUnmarshaler)(nil)
+
+// ResourceMetricsUnmarshaler translates the metric records streamed by the
+// Azure diagnostic settings. Each aggregation of a record becomes a metric
+// named after the metric of the record and the aggregation, e.g.
+// "percentage_cpu_average". The total and count are delta sums, the minimum,
+// maximum and average are gauges.
+type ResourceMetricsUnmarshaler struct {
+	Version     string
+	Logger      *zap.Logger
+	TimeFormats []string
+	// LegacyResourceAttributes only sets the full resource ID as the
+	// cloud.resource_id resource attribute, instead of also adding its
+	// subscription, resource group, provider namespace and name.
+	LegacyResourceAttributes bool
+	// MaxDecompressedSize is the size, in bytes, above which a gzip
+	// compressed payload is rejected once decompressed. Defaults to
+	// DefaultMaxDecompressedSize.
+	MaxDecompressedSize int64
+	// ResourceAttributes are static attributes set on every resource.
+	// They do not override the attributes derived from the records.
+	ResourceAttributes map[string]string
+}
+
+// UnmarshalMetrics converts the metric records of buf into metrics, grouped
+// by the resource ID of the records. Records without a valid time or time
+// grain are skipped. Gzip compressed payloads are decompressed first.
+func (r ResourceMetricsUnmarshaler) UnmarshalMetrics(buf []byte) (pmetric.Metrics, error) {
+	buf, err := decompress(buf, r.MaxDecompressedSize)
+	if err != nil {
+		return pmetric.Metrics{}, err
+	}
+
+	iter := jsoniter.ConfigFastest.BorrowIterator(buf)
+	defer jsoniter.ConfigFastest.ReturnIterator(iter)
+
+	allResourceScopeMetrics := newResourceGroups(func() pmetric.ScopeMetrics {
+		scopeMetrics := pmetric.NewScopeMetrics()
+		scopeMetrics.SetSchemaUrl(schemaURL())
+		scopeMetrics.Scope().SetName(metricsScopeName)
+		scopeMetrics.Scope().SetVersion(r.Version)
+		return scopeMetrics
+	})
+	for field := iter.ReadObject(); field != ""; field = iter.ReadObject() {
+		if !strings.EqualFold(field, "records") {
+			iter.Skip()
+			continue
+		}
+		for iter.ReadArray() {
+			var record azureMetricRecord
+			iter.ReadVal(&record)
+			if iter.Error != nil {
+				break
+			}
+			r.addMetrics(record, allResourceScopeMetrics.get(record.ResourceID).Metrics())
+		}
+	}
+	if iter.Error != nil {
+		return pmetric.Metrics{}, fmt.Errorf("JSON parse failed: %w", iter.Error)
+	}
+
+	md := pmetric.NewMetrics()
+	allResourceScopeMetrics.each(func(resourceID string, scopeMetrics pmetric.ScopeMetrics) {
+		if scopeMetrics.Metrics().Len() == 0 {
+			return
+		}
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.SetSchemaUrl(schemaURL())
+		attrs := rm.Resource().Attributes()
+		attrs.PutStr(string(conventions.CloudProviderKey), conventions.CloudProviderAzure.Value.AsString())
+		putStrIfNotEmpty(string(conventions.CloudResourceIDKey), resourceID, attrs)
+		if !r.LegacyResourceAttributes {
+			addResourceIDAttributes(resourceID, attrs)
+		}
+		for key, value := range r.ResourceAttributes {
+			if _, ok := attrs.Get(key); !ok {
+				attrs.PutStr(key, value)
+			}
+		}
+		scopeMetrics.MoveTo(rm.ScopeMetrics().AppendEmpty())
+	})
+	return md, nil
+}
+
+// addMetrics appends a metric per aggregation of record to metrics.
+func (r ResourceMetricsUnmarshaler) addMetrics(record azureMetricRecord, metrics pmetric.MetricSlice) {
+	nanos, err := asTimestamp(record.Time, r.TimeFormats...)
+	if err != nil {
+		r.Logger.Warn("Unable to convert timestamp from metric", zap.String("timestamp", record.Time))
+		return
+	}
+	grain, err := parseTimeGrain(record.TimeGrain)
+	if err != nil {
+		r.Logger.Warn("Unable to convert time grain from metric",
+			zap.String("metric", record.MetricName),
+			zap.String("time grain", record.TimeGrain),
+			zap.Error(err))
+		return
+	}
+	start := pcommon.NewTimestampFromTime(nanos.AsTime().Add(-grain))
+	name := strings.ToLower(strings.ReplaceAll(record.MetricName, " ", "_"))
+
+	if record.Total != nil {
+		metric := metrics.AppendEmpty()
+		metric.SetName(name + "_total")
+		sum := metric.SetEmptySum()
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		setDataPoint(sum.DataPoints().AppendEmpty(), start, nanos, *record.Total)
+	}
+	if record.Count != nil {
+		metric := metrics.AppendEmpty()
+		metric.SetName(name + "_count")
+		sum := metric.SetEmptySum()
+		sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		sum.SetIsMonotonic(true)
+		setDataPoint(sum.DataPoints().AppendEmpty(), start, nanos, *record.Count)
+	}
+	for _, gauge := range []struct {
+		aggregation string
+		value       *float64
+	}{
+		{"minimum", record.Minimum},
+		{"maximum", record.Maximum},
+		{"average", record.Average},
+	} {
+		if gauge.value == nil {
+			continue
+		}
+		metric := metrics.AppendEmpty()
+		metric.SetName(name + "_" + gauge.aggregation)
+		setDataPoint(metric.SetEmptyGauge().DataPoints().AppendEmpty(), start, nanos, *gauge.value)
+	}
+}
+
+func setDataPoint(dp pmetric.NumberDataPoint, start, ts pcommon.Timestamp, value float64) {
+	dp.SetStartTimestamp(start)
+	dp.SetTimestamp(ts)
+	dp.SetDoubleValue(value)
+}
+
+var (
+	timeGrainPattern = regexp.MustCompile(`^P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+
+	errInvalidTimeGrain = errors.New("invalid ISO 8601 duration")
+)
+
+// parseTimeGrain parses the ISO 8601 duration of a time grain, e.g. "PT1M"
+// or "P1D".
+func parseTimeGrain(grain string) (time.Duration, error) {
+	matches := timeGrainPattern.FindStringSubmatch(strings.ToUpper(grain))
+	if matches == nil {
+		return 0, errInvalidTimeGrain
+	}
+	var duration time.Duration
+	for i, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute, time.Second} {
+		if matches[i+1] == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(matches[i+1], 10, 64)
+		if err != nil {
+			return 0, errInvalidTimeGrain
+		}
+		duration += time.Duration(n) * unit
+	}
+	if duration <= 0 {
+		return 0, errInvalidTimeGrain
+	}
+	return duration, nil
+}
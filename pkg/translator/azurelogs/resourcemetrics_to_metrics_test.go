@@ -0,0 +1,159 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/golden"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatatest/pmetrictest"
+)
+
+func TestUnmarshalMetrics(t *testing.T) {
+	t.Parallel()
+
+	dir := "testdata/metrics"
+	tests := map[string]struct {
+		metricFilename   string
+		expectedFilename string
+		expectsErr       string
+	}{
+		"valid": {
+			metricFilename:   "valid.json",
+			expectedFilename: "valid_expected.yaml",
+		},
+		"invalid_time_grain": {
+			metricFilename:   "invalid_time_grain.json",
+			expectedFilename: "invalid_time_grain_expected.yaml",
+		},
+	}
+
+	u := &ResourceMetricsUnmarshaler{
+		Version: testBuildInfo.Version,
+		Logger:  zap.NewNop(),
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(dir, test.metricFilename))
+			require.NoError(t, err)
+
+			metrics, err := u.UnmarshalMetrics(data)
+
+			if test.expectsErr != "" {
+				require.ErrorContains(t, err, test.expectsErr)
+				return
+			}
+
+			require.NoError(t, err)
+
+			expectedMetrics, err := golden.ReadMetrics(filepath.Join(dir, test.expectedFilename))
+			require.NoError(t, err)
+			require.NoError(t, pmetrictest.CompareMetrics(expectedMetrics, metrics,
+				pmetrictest.IgnoreResourceMetricsOrder(),
+				pmetrictest.IgnoreStartTimestamp(),
+				pmetrictest.IgnoreTimestamp()))
+		})
+	}
+}
+
+func TestUnmarshalMetrics_Timestamps(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile(filepath.Join("testdata/metrics", "valid.json"))
+	require.NoError(t, err)
+
+	u := &ResourceMetricsUnmarshaler{Logger: zap.NewNop()}
+	metrics, err := u.UnmarshalMetrics(data)
+	require.NoError(t, err)
+
+	end := time.Date(2025, 6, 20, 14, 5, 0, 0, time.UTC)
+	rm := metrics.ResourceMetrics().At(0)
+	id, _ := rm.Resource().Attributes().Get("cloud.resource_id")
+	require.Contains(t, id.Str(), "VIRTUALMACHINES/TEST-VM")
+
+	ms := rm.ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 10, ms.Len())
+	cpu := ms.At(0).Sum().DataPoints().At(0)
+	assert.Equal(t, pcommon.NewTimestampFromTime(end), cpu.Timestamp())
+	assert.Equal(t, pcommon.NewTimestampFromTime(end.Add(-time.Minute)), cpu.StartTimestamp())
+	network := ms.At(5).Sum().DataPoints().At(0)
+	assert.Equal(t, "network_in_total_total", ms.At(5).Name())
+	assert.Equal(t, pcommon.NewTimestampFromTime(end.Add(-5*time.Minute)), network.StartTimestamp())
+}
+
+func TestUnmarshalMetrics_Gzip(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile(filepath.Join("testdata/metrics", "valid.json"))
+	require.NoError(t, err)
+
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err = gz.Write(data)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	u := &ResourceMetricsUnmarshaler{Logger: zap.NewNop()}
+	expected, err := u.UnmarshalMetrics(data)
+	require.NoError(t, err)
+	metrics, err := u.UnmarshalMetrics(compressed.Bytes())
+	require.NoError(t, err)
+	require.NoError(t, pmetrictest.CompareMetrics(expected, metrics))
+
+	u.MaxDecompressedSize = 16
+	_, err = u.UnmarshalMetrics(compressed.Bytes())
+	require.Error(t, err)
+}
+
+func TestUnmarshalMetrics_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	u := &ResourceMetricsUnmarshaler{Logger: zap.NewNop()}
+	_, err := u.UnmarshalMetrics([]byte(`{"records": [{"time": 1}]}`))
+	require.ErrorContains(t, err, "JSON parse failed")
+}
+
+func TestParseTimeGrain(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]struct {
+		grain      string
+		expected   time.Duration
+		expectsErr bool
+	}{
+		"one_minute":   {grain: "PT1M", expected: time.Minute},
+		"five_minutes": {grain: "PT5M", expected: 5 * time.Minute},
+		"one_hour":     {grain: "PT1H", expected: time.Hour},
+		"one_day":      {grain: "P1D", expected: 24 * time.Hour},
+		"combined":     {grain: "P1DT1H30M15S", expected: 25*time.Hour + 30*time.Minute + 15*time.Second},
+		"lowercase":    {grain: "pt30s", expected: 30 * time.Second},
+		"empty":        {grain: "", expectsErr: true},
+		"zero":         {grain: "PT0M", expectsErr: true},
+		"no_units":     {grain: "PT", expectsErr: true},
+		"not_iso":      {grain: "1 minute", expectsErr: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			grain, err := parseTimeGrain(test.grain)
+			if test.expectsErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, grain)
+		})
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"go.opentelemetry.io/collector/featuregate"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/otel/semconv/v1.27.0"
+	latest "go.opentelemetry.io/otel/semconv/v1.34.0"
+)
+
+var useLatestSemconvGate = featuregate.GlobalRegistry().MustRegister(
+	"pkg.translator.azurelogs.UseLatestSemconv",
+	featuregate.StageAlpha,
+	featuregate.WithRegisterDescription("When enabled, the Azure logs are translated with the attribute names of the "+
+		"latest supported semantic conventions, instead of the legacy ones"),
+	featuregate.WithRegisterFromVersion("v0.131.0"),
+)
+
+// latestAttributeNames maps the legacy attribute names renamed by the latest
+// supported semantic conventions to their new name.
+var latestAttributeNames = map[string]string{
+	string(conventions.DBSystemKey):     string(latest.DBSystemNameKey),
+	string(conventions.CodeFilepathKey): string(latest.CodeFilePathKey),
+	string(conventions.CodeFunctionKey): string(latest.CodeFunctionNameKey),
+	attributeEndUserRole:                string(latest.UserRolesKey),
+}
+
+// latestDBSystemNames maps the legacy db.system values to their
+// db.system.name value in the latest supported semantic conventions.
+var latestDBSystemNames = map[string]string{
+	conventions.DBSystemMSSQL.Value.AsString(): latest.DBSystemNameMicrosoftSQLServer.Value.AsString(),
+}
+
+// schemaURL returns the URL of the semantic conventions the attribute names
+// follow.
+func schemaURL() string {
+	if useLatestSemconvGate.IsEnabled() {
+		return latest.SchemaURL
+	}
+	return conventions.SchemaURL
+}
+
+// upgradeLogs renames the legacy attributes of the log records, and of the
+// map bodies holding the raw fields of the records, to their name in the
+// latest supported semantic conventions.
+func upgradeLogs(logs plog.Logs) {
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		scopeLogs := logs.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < scopeLogs.Len(); j++ {
+			records := scopeLogs.At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+				upgradeAttributes(record.Attributes())
+				if record.Body().Type() == pcommon.ValueTypeMap {
+					upgradeAttributes(record.Body().Map())
+				}
+			}
+		}
+	}
+}
+
+// upgradeAttributes renames the legacy attributes of attrs to their name in
+// the latest supported semantic conventions.
+func upgradeAttributes(attrs pcommon.Map) {
+	for legacyName, name := range latestAttributeNames {
+		value, ok := attrs.Get(legacyName)
+		if !ok {
+			continue
+		}
+		switch name {
+		case string(latest.DBSystemNameKey):
+			system := value.AsString()
+			if latestName, ok := latestDBSystemNames[system]; ok {
+				system = latestName
+			}
+			attrs.PutStr(name, system)
+		case string(latest.UserRolesKey):
+			// user.roles is a list of roles, while enduser.role held a
+			// single one
+			attrs.PutEmptySlice(name).AppendEmpty().SetStr(value.AsString())
+		default:
+			value.CopyTo(attrs.PutEmpty(name))
+		}
+		attrs.Remove(legacyName)
+	}
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/featuregate"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	conventions "go.opentelemetry.io/otel/semconv/v1.27.0"
+	latest "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.uber.org/zap"
+)
+
+func setUseLatestSemconv(t *testing.T, enabled bool) {
+	require.NoError(t, featuregate.GlobalRegistry().Set(useLatestSemconvGate.ID(), enabled))
+	t.Cleanup(func() {
+		require.NoError(t, featuregate.GlobalRegistry().Set(useLatestSemconvGate.ID(), false))
+	})
+}
+
+func TestSchemaURL(t *testing.T) {
+	assert.Equal(t, conventions.SchemaURL, schemaURL())
+	setUseLatestSemconv(t, true)
+	assert.Equal(t, latest.SchemaURL, schemaURL())
+}
+
+// The feature gate is global, so the tests enabling it must not run in
+// parallel with the other tests.
+func TestUnmarshalLogs_UseLatestSemconv(t *testing.T) {
+	setUseLatestSemconv(t, true)
+
+	tests := map[string]struct {
+		logFilename string
+		check       func(t *testing.T, attrs, body pcommon.Map)
+	}{
+		"sql_audit": {
+			logFilename: "sqlsecurityauditevents/valid_1.json",
+			check: func(t *testing.T, attrs, _ pcommon.Map) {
+				_, ok := attrs.Get(string(conventions.DBSystemKey))
+				assert.False(t, ok)
+				system, ok := attrs.Get(string(latest.DBSystemNameKey))
+				require.True(t, ok)
+				assert.Equal(t, "microsoft.sql_server", system.Str())
+			},
+		},
+		"activity_log": {
+			logFilename: "activitylog/valid_1.json",
+			check: func(t *testing.T, attrs, _ pcommon.Map) {
+				_, ok := attrs.Get(attributeEndUserRole)
+				assert.False(t, ok)
+				roles, ok := attrs.Get(string(latest.UserRolesKey))
+				require.True(t, ok)
+				assert.Equal(t, []any{"Contributor"}, roles.Slice().AsRaw())
+			},
+		},
+		"app_service_body": {
+			logFilename: "log-appserviceapplogs.json",
+			check: func(t *testing.T, _, body pcommon.Map) {
+				_, ok := body.Get(string(conventions.CodeFilepathKey))
+				assert.False(t, ok)
+				_, ok = body.Get(string(latest.CodeFilePathKey))
+				assert.True(t, ok)
+				_, ok = body.Get(string(latest.CodeFunctionNameKey))
+				assert.True(t, ok)
+			},
+		},
+	}
+
+	u := &ResourceLogsUnmarshaler{
+		Version: testBuildInfo.Version,
+		Logger:  zap.NewNop(),
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", test.logFilename))
+			require.NoError(t, err)
+
+			logs, err := u.UnmarshalLogs(data)
+			require.NoError(t, err)
+			require.Positive(t, logs.LogRecordCount())
+
+			rl := logs.ResourceLogs().At(0)
+			assert.Equal(t, latest.SchemaURL, rl.SchemaUrl())
+			assert.Equal(t, latest.SchemaURL, rl.ScopeLogs().At(0).SchemaUrl())
+			record := rl.ScopeLogs().At(0).LogRecords().At(0)
+			body := pcommon.NewMap()
+			if record.Body().Type() == pcommon.ValueTypeMap {
+				body = record.Body().Map()
+			}
+			test.check(t, record.Attributes(), body)
+		})
+	}
+}
+
+func TestUpgradeAttributes(t *testing.T) {
+	t.Parallel()
+
+	attrs := pcommon.NewMap()
+	attrs.PutStr(string(conventions.DBSystemKey), "postgresql")
+	attrs.PutStr(string(conventions.CodeFunctionKey), "main")
+	attrs.PutStr(string(conventions.ClientAddressKey), "10.0.0.1")
+
+	upgradeAttributes(attrs)
+
+	assert.Equal(t, map[string]any{
+		string(latest.DBSystemNameKey):     "postgresql",
+		string(latest.CodeFunctionNameKey): "main",
+		string(latest.ClientAddressKey):    "10.0.0.1",
+	}, attrs.AsRaw())
+}
@@ -0,0 +1,63 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"encoding/hex"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// setTraceContext sets the trace and span IDs of the log record from the
+// operation, or otherwise correlation, ID and the parent ID of the record,
+// when they are valid W3C trace context IDs, as exported by Application
+// Insights. Other IDs, such as the GUIDs of the activity logs, are ignored.
+func setTraceContext(log azureLogRecord, lr plog.LogRecord) {
+	traceID, ok := pcommon.TraceID{}, false
+	if log.OperationID != nil {
+		traceID, ok = parseTraceID(*log.OperationID)
+	}
+	if !ok && log.CorrelationID != nil {
+		traceID, ok = parseTraceID(*log.CorrelationID)
+	}
+	if !ok {
+		return
+	}
+	lr.SetTraceID(traceID)
+	if log.ParentID != nil {
+		if spanID, ok := parseSpanID(*log.ParentID); ok {
+			lr.SetSpanID(spanID)
+		}
+	}
+}
+
+// parseTraceID parses a W3C trace ID, 32 hexadecimal characters that are
+// not all zero.
+func parseTraceID(s string) (pcommon.TraceID, bool) {
+	var id pcommon.TraceID
+	if !decodeID(s, id[:]) || id.IsEmpty() {
+		return pcommon.TraceID{}, false
+	}
+	return id, true
+}
+
+// parseSpanID parses a W3C span ID, 16 hexadecimal characters that are
+// not all zero.
+func parseSpanID(s string) (pcommon.SpanID, bool) {
+	var id pcommon.SpanID
+	if !decodeID(s, id[:]) || id.IsEmpty() {
+		return pcommon.SpanID{}, false
+	}
+	return id, true
+}
+
+// decodeID decodes the hexadecimal s into dst, which it must exactly fill.
+func decodeID(s string, dst []byte) bool {
+	if len(s) != hex.EncodedLen(len(dst)) {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
@@ -0,0 +1,77 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestSetTraceContext(t *testing.T) {
+	t.Parallel()
+
+	ptr := func(s string) *string { return &s }
+	traceID := pcommon.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36}
+	spanID := pcommon.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7}
+
+	tests := map[string]struct {
+		log             azureLogRecord
+		expectedTraceID pcommon.TraceID
+		expectedSpanID  pcommon.SpanID
+	}{
+		"operation id and parent id": {
+			log: azureLogRecord{
+				OperationID: ptr("4bf92f3577b34da6a3ce929d0e0e4736"),
+				ParentID:    ptr("00f067aa0ba902b7"),
+			},
+			expectedTraceID: traceID,
+			expectedSpanID:  spanID,
+		},
+		"operation id takes precedence over correlation id": {
+			log: azureLogRecord{
+				OperationID:   ptr("4bf92f3577b34da6a3ce929d0e0e4736"),
+				CorrelationID: ptr("0af7651916cd43dd8448eb211c80319c"),
+			},
+			expectedTraceID: traceID,
+		},
+		"correlation id": {
+			log: azureLogRecord{
+				OperationID:   ptr("not-a-trace-id"),
+				CorrelationID: ptr("4bf92f3577b34da6a3ce929d0e0e4736"),
+			},
+			expectedTraceID: traceID,
+		},
+		"guid correlation id": {
+			log: azureLogRecord{
+				CorrelationID: ptr("4bf92f35-77b3-4da6-a3ce-929d0e0e4736"),
+				ParentID:      ptr("00f067aa0ba902b7"),
+			},
+		},
+		"all zero trace id": {
+			log: azureLogRecord{
+				OperationID: ptr("00000000000000000000000000000000"),
+			},
+		},
+		"invalid parent id": {
+			log: azureLogRecord{
+				OperationID: ptr("4bf92f3577b34da6a3ce929d0e0e4736"),
+				ParentID:    ptr("|4bf92f3577b34da6a3ce929d0e0e4736.00f067aa0ba902b7."),
+			},
+			expectedTraceID: traceID,
+		},
+		"no ids": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			lr := plog.NewLogRecord()
+			setTraceContext(test.log, lr)
+			assert.Equal(t, test.expectedTraceID, lr.TraceID())
+			assert.Equal(t, test.expectedSpanID, lr.SpanID())
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs // import "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	gojson "github.com/goccy/go-json"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// attributeAzureDuration holds the duration of the operation of a
+// record, in seconds, whichever property and unit it was reported with.
+const attributeAzureDuration = "azure.duration"
+
+// durationProperty is a property holding the duration of the operation
+// of a record, in unit.
+type durationProperty struct {
+	name string
+	unit time.Duration
+}
+
+// durationProperties are the properties holding the duration of the
+// operation of a record, by order of precedence. Property names are case
+// sensitive, e.g. the timeTaken of the access logs is in seconds while
+// the TimeTaken of the App Service HTTP logs is in milliseconds.
+var durationProperties = []durationProperty{
+	{name: "durationMs", unit: time.Millisecond},
+	{name: "DurationMs", unit: time.Millisecond},
+	{name: "timeTaken", unit: time.Second},
+	{name: "TimeTaken", unit: time.Millisecond},
+	{name: "totalLatencyMilliseconds", unit: time.Millisecond},
+	{name: "latencyMs", unit: time.Millisecond},
+	{name: "latency", unit: time.Millisecond},
+}
+
+// UnitNormalization normalizes the units and types of the durations and
+// byte counts, which differ from one category to another.
+type UnitNormalization struct {
+	// Duration sets the duration of the operation of a record, taken from
+	// its durationMs field or from the first of its duration properties,
+	// such as timeTaken, DurationMs or latency, as the azure.duration
+	// attribute, a double in seconds.
+	Duration bool
+	// ByteCounts parses the byte counts reported as strings, i.e. the
+	// attributes and properties whose name contains "bytes", as ints.
+	ByteCounts bool
+}
+
+// normalize normalizes the durations and byte counts of the log record
+// of log.
+func (n UnitNormalization) normalize(log azureLogRecord, lr plog.LogRecord) {
+	if n.Duration {
+		if seconds, ok := recordDuration(log); ok {
+			lr.Attributes().PutDouble(attributeAzureDuration, seconds)
+		}
+	}
+	if n.ByteCounts {
+		parseByteCounts(lr.Attributes())
+		if lr.Body().Type() == pcommon.ValueTypeMap {
+			if properties, ok := lr.Body().Map().Get(azureProperties); ok && properties.Type() == pcommon.ValueTypeMap {
+				parseByteCounts(properties.Map())
+			}
+		}
+	}
+}
+
+// recordDuration returns the duration of the operation of log, in
+// seconds, if any.
+func recordDuration(log azureLogRecord) (float64, bool) {
+	if log.DurationMs != nil {
+		if ms, err := log.DurationMs.Float64(); err == nil {
+			return ms / 1e3, true
+		}
+	}
+	if len(log.Properties) == 0 {
+		return 0, false
+	}
+	var properties map[string]any
+	if err := gojson.Unmarshal(log.Properties, &properties); err != nil {
+		return 0, false
+	}
+	for _, property := range durationProperties {
+		value, found := properties[property.name]
+		if !found {
+			continue
+		}
+		if f, ok := tryParseFloat64(value); ok {
+			return f * property.unit.Seconds(), true
+		}
+	}
+	return 0, false
+}
+
+// parseByteCounts replaces the string values of the byte counts of m by
+// ints.
+func parseByteCounts(m pcommon.Map) {
+	m.Range(func(k string, v pcommon.Value) bool {
+		if v.Type() != pcommon.ValueTypeStr || !strings.Contains(strings.ToLower(k), "bytes") {
+			return true
+		}
+		if i, err := strconv.ParseInt(v.Str(), 10, 64); err == nil {
+			v.SetInt(i)
+		}
+		return true
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package azurelogs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+func TestRecordDuration(t *testing.T) {
+	t.Parallel()
+
+	durationMs := json.Number("1500")
+	tests := map[string]struct {
+		log             azureLogRecord
+		expected        float64
+		expectsDuration bool
+	}{
+		"duration ms field": {
+			log:             azureLogRecord{DurationMs: &durationMs, Properties: []byte(`{"timeTaken": "9"}`)},
+			expected:        1.5,
+			expectsDuration: true,
+		},
+		"time taken in seconds": {
+			log:             azureLogRecord{Properties: []byte(`{"timeTaken": "0.154"}`)},
+			expected:        0.154,
+			expectsDuration: true,
+		},
+		"time taken in milliseconds": {
+			log:             azureLogRecord{Properties: []byte(`{"TimeTaken": 250}`)},
+			expected:        0.25,
+			expectsDuration: true,
+		},
+		"duration ms property": {
+			log:             azureLogRecord{Properties: []byte(`{"DurationMs": 42, "latency": 7}`)},
+			expected:        0.042,
+			expectsDuration: true,
+		},
+		"latency": {
+			log:             azureLogRecord{Properties: []byte(`{"latency": "20"}`)},
+			expected:        0.02,
+			expectsDuration: true,
+		},
+		"invalid duration": {
+			log: azureLogRecord{Properties: []byte(`{"timeTaken": "fast"}`)},
+		},
+		"no duration": {
+			log: azureLogRecord{Properties: []byte(`{"other": 1}`)},
+		},
+		"properties not an object": {
+			log: azureLogRecord{Properties: []byte(`"text"`)},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			seconds, ok := recordDuration(test.log)
+			assert.Equal(t, test.expectsDuration, ok)
+			assert.InDelta(t, test.expected, seconds, 1e-9)
+		})
+	}
+}
+
+func TestParseByteCounts(t *testing.T) {
+	t.Parallel()
+
+	m := pcommon.NewMap()
+	m.PutStr("requestBytes", "512")
+	m.PutStr("BytesSent", "1024")
+	m.PutStr("ScBytes", "not a number")
+	m.PutStr("requestUri", "1")
+	m.PutInt("responseBytes", 10)
+
+	parseByteCounts(m)
+
+	assert.Equal(t, map[string]any{
+		"requestBytes":  int64(512),
+		"BytesSent":     int64(1024),
+		"ScBytes":       "not a number",
+		"requestUri":    "1",
+		"responseBytes": int64(10),
+	}, m.AsRaw())
+}
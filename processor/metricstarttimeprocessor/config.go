@@ -11,6 +11,7 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/filter/filterset"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/starttimemetric"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/subtractinitial"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/truereset"
@@ -20,8 +21,48 @@ import (
 type Config struct {
 	Strategy   string        `mapstructure:"strategy"`
 	GCInterval time.Duration `mapstructure:"gc_interval"`
+	// ResourceTTL removes the resources not reporting for the given duration from the state of
+	// the strategies, independently of the GCInterval. 0 leaves them to the gc.
+	ResourceTTL time.Duration `mapstructure:"resource_ttl"`
+	// MaxTrackedSeries bounds the number of timeseries tracked by each strategy, evicting
+	// the least recently used ones when exceeded. 0 means no bound.
+	MaxTrackedSeries int `mapstructure:"max_tracked_series"`
+	// RestartAttributes are the resource attributes whose change is treated as a reset of
+	// all the series of the resource by the stateful strategies.
+	RestartAttributes []string `mapstructure:"restart_attributes"`
+	// IdentityExcludedAttributes are the datapoint attributes left out of the identity of the
+	// series tracked by the stateful strategies, e.g. labels changing without the series restarting.
+	IdentityExcludedAttributes []string `mapstructure:"identity_excluded_attributes"`
+	// ConvertDeltaToCumulative converts the delta sums and histograms to cumulative ones with the
+	// stateful strategies. Delta metrics are passed through untouched otherwise.
+	ConvertDeltaToCumulative bool `mapstructure:"convert_delta_to_cumulative"`
+	// DryRun leaves the metrics unchanged, annotating their points with the start timestamp
+	// they would have been given and whether a reset was detected instead.
+	DryRun bool `mapstructure:"dry_run"`
 	// StartTimeMetricRegex only applies then the start_time_metric strategy is used
 	StartTimeMetricRegex string `mapstructure:"start_time_metric_regex"`
+	// MetricStrategies overrides Strategy for the metrics whose name matches
+	// their regex. The first matching entry applies.
+	MetricStrategies []MetricStrategy `mapstructure:"metric_strategies"`
+
+	// Include specifies a filter on the metrics whose start time should be adjusted.
+	// Exclude specifies a filter on the metrics whose start time should not be adjusted.
+	// If neither `include` nor `exclude` are set, all metrics are adjusted.
+	Include MatchMetrics `mapstructure:"include"`
+	Exclude MatchMetrics `mapstructure:"exclude"`
+}
+
+// MatchMetrics selects metrics by name.
+type MatchMetrics struct {
+	filterset.Config `mapstructure:",squash"`
+
+	Metrics []string `mapstructure:"metrics"`
+}
+
+// MetricStrategy is the strategy used for the metrics whose name matches MetricNameRegex.
+type MetricStrategy struct {
+	MetricNameRegex string `mapstructure:"metric_name_regex"`
+	Strategy        string `mapstructure:"strategy"`
 }
 
 var _ component.Config = (*Config)(nil)
@@ -35,23 +76,84 @@ func createDefaultConfig() component.Config {
 
 // Validate checks the configuration is valid
 func (cfg *Config) Validate() error {
-	switch cfg.Strategy {
-	case truereset.Type:
-	case subtractinitial.Type:
-	case starttimemetric.Type:
-	default:
-		return fmt.Errorf("%q is not a valid strategy", cfg.Strategy)
+	if err := validateStrategy(cfg.Strategy); err != nil {
+		return err
+	}
+	for _, ms := range cfg.MetricStrategies {
+		if err := validateStrategy(ms.Strategy); err != nil {
+			return err
+		}
+		if ms.MetricNameRegex == "" {
+			return errors.New("metric_strategies entries require a metric_name_regex")
+		}
+		if _, err := regexp.Compile(ms.MetricNameRegex); err != nil {
+			return err
+		}
 	}
 	if cfg.GCInterval <= 0 {
 		return errors.New("gc_interval must be positive")
 	}
+	if cfg.ResourceTTL < 0 {
+		return errors.New("resource_ttl must not be negative")
+	}
+	if cfg.MaxTrackedSeries < 0 {
+		return errors.New("max_tracked_series must not be negative")
+	}
+	for _, attr := range cfg.RestartAttributes {
+		if attr == "" {
+			return errors.New("restart_attributes entries must not be empty")
+		}
+	}
+	for _, attr := range cfg.IdentityExcludedAttributes {
+		if attr == "" {
+			return errors.New("identity_excluded_attributes entries must not be empty")
+		}
+	}
+	for _, mm := range []MatchMetrics{cfg.Include, cfg.Exclude} {
+		if len(mm.Metrics) > 0 && mm.MatchType == "" {
+			return errors.New("match_type must be set if metrics are supplied")
+		}
+		if mm.MatchType != "" && len(mm.Metrics) == 0 {
+			return errors.New("metrics must be supplied if match_type is set")
+		}
+		if mm.MatchType != "" {
+			if _, err := filterset.CreateFilterSet(mm.Metrics, &mm.Config); err != nil {
+				return err
+			}
+		}
+	}
 	if cfg.StartTimeMetricRegex != "" {
 		if _, err := regexp.Compile(cfg.StartTimeMetricRegex); err != nil {
 			return err
 		}
-		if cfg.Strategy != starttimemetric.Type {
+		if !cfg.usesStrategy(starttimemetric.Type) {
 			return errors.New("start_time_metric_regex can only be used with the start_time_metric strategy")
 		}
 	}
 	return nil
 }
+
+func validateStrategy(strategy string) error {
+	switch strategy {
+	case truereset.Type:
+	case truereset.ZeroPointType:
+	case subtractinitial.Type:
+	case starttimemetric.Type:
+	default:
+		return fmt.Errorf("%q is not a valid strategy", strategy)
+	}
+	return nil
+}
+
+// usesStrategy reports whether strategy is used for any metric.
+func (cfg *Config) usesStrategy(strategy string) bool {
+	if cfg.Strategy == strategy {
+		return true
+	}
+	for _, ms := range cfg.MetricStrategies {
+		if ms.Strategy == strategy {
+			return true
+		}
+	}
+	return false
+}
@@ -14,6 +14,7 @@ import (
 	"go.opentelemetry.io/collector/confmap/confmaptest"
 	"go.opentelemetry.io/collector/confmap/xconfmap"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/filter/filterset"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/starttimemetric"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/subtractinitial"
@@ -53,6 +54,97 @@ func TestLoadConfig(t *testing.T) {
 			id:           component.NewIDWithName(metadata.Type, "negative_interval"),
 			errorMessage: "gc_interval must be positive",
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "max_tracked_series"),
+			expected: &Config{
+				Strategy:         truereset.Type,
+				GCInterval:       10 * time.Minute,
+				MaxTrackedSeries: 100000,
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "negative_max_tracked_series"),
+			errorMessage: "max_tracked_series must not be negative",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "restart_attributes"),
+			expected: &Config{
+				Strategy:          truereset.Type,
+				GCInterval:        10 * time.Minute,
+				RestartAttributes: []string{"process.pid", "process.start_time"},
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "empty_restart_attribute"),
+			errorMessage: "restart_attributes entries must not be empty",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "identity_excluded_attributes"),
+			expected: &Config{
+				Strategy:                   truereset.Type,
+				GCInterval:                 10 * time.Minute,
+				IdentityExcludedAttributes: []string{"instance"},
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "resource_ttl"),
+			expected: &Config{
+				Strategy:    truereset.Type,
+				GCInterval:  10 * time.Minute,
+				ResourceTTL: 2 * time.Minute,
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "negative_resource_ttl"),
+			errorMessage: "resource_ttl must not be negative",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "empty_identity_excluded_attribute"),
+			errorMessage: "identity_excluded_attributes entries must not be empty",
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "convert_delta_to_cumulative"),
+			expected: &Config{
+				Strategy:                 subtractinitial.Type,
+				GCInterval:               10 * time.Minute,
+				ConvertDeltaToCumulative: true,
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "dry_run"),
+			expected: &Config{
+				Strategy:   truereset.Type,
+				GCInterval: 10 * time.Minute,
+				DryRun:     true,
+			},
+		},
+		{
+			id: component.NewIDWithName(metadata.Type, "include_exclude"),
+			expected: &Config{
+				Strategy:   truereset.Type,
+				GCInterval: 10 * time.Minute,
+				Include: MatchMetrics{
+					Config:  filterset.Config{MatchType: filterset.Regexp},
+					Metrics: []string{"_total$"},
+				},
+				Exclude: MatchMetrics{
+					Config:  filterset.Config{MatchType: filterset.Strict},
+					Metrics: []string{"http_requests_total"},
+				},
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "include_missing_match_type"),
+			errorMessage: "match_type must be set if metrics are supplied",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "exclude_missing_metrics"),
+			errorMessage: "metrics must be supplied if match_type is set",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "include_invalid_match_type"),
+			errorMessage: "unrecognized match_type: 'bad', valid types are: [regexp strict]",
+		},
 		{
 			id:           component.NewIDWithName(metadata.Type, "invalid_strategy"),
 			errorMessage: "\"bad\" is not a valid strategy",
@@ -64,6 +156,13 @@ func TestLoadConfig(t *testing.T) {
 				GCInterval: 10 * time.Minute,
 			},
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "true_reset_point_with_zeros"),
+			expected: &Config{
+				Strategy:   truereset.ZeroPointType,
+				GCInterval: 10 * time.Minute,
+			},
+		},
 		{
 			id: component.NewIDWithName(metadata.Type, "start_time_metric"),
 			expected: &Config{
@@ -80,6 +179,30 @@ func TestLoadConfig(t *testing.T) {
 			id:           component.NewIDWithName(metadata.Type, "regex_with_subtract_initial_point"),
 			errorMessage: "start_time_metric_regex can only be used with the start_time_metric strategy",
 		},
+		{
+			id: component.NewIDWithName(metadata.Type, "metric_strategies"),
+			expected: &Config{
+				Strategy:             truereset.Type,
+				GCInterval:           10 * time.Minute,
+				StartTimeMetricRegex: "^.+_process_start_time_seconds$",
+				MetricStrategies: []MetricStrategy{
+					{MetricNameRegex: "^container_", Strategy: subtractinitial.Type},
+					{MetricNameRegex: "^app_", Strategy: starttimemetric.Type},
+				},
+			},
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "metric_strategies_invalid_strategy"),
+			errorMessage: "\"bad\" is not a valid strategy",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "metric_strategies_missing_regex"),
+			errorMessage: "metric_strategies entries require a metric_name_regex",
+		},
+		{
+			id:           component.NewIDWithName(metadata.Type, "metric_strategies_invalid_regex"),
+			errorMessage: "error parsing regexp: missing closing ): `((((`",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,123 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metricstarttimeprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor"
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
+)
+
+// adjustedWouldBeAttribute is the datapoint attribute holding, in dry run, the start timestamp
+// the point would have been given in nanoseconds since the epoch.
+const adjustedWouldBeAttribute = "starttime.adjusted_would_be"
+
+// dryRun returns a ProcessMetricsFunc running adjustMetrics on a copy of the metrics, and
+// annotating the original points with the outcome of their adjustment instead of applying it.
+// The adjusters given to adjustMetrics are expected to mark the resets they detect with
+// datapointstorage.ResetDetectedAttribute.
+func dryRun(adjustMetrics processorhelper.ProcessMetricsFunc) processorhelper.ProcessMetricsFunc {
+	return func(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+		adjusted := pmetric.NewMetrics()
+		md.CopyTo(adjusted)
+		adjusted, err := adjustMetrics(ctx, adjusted)
+		if err != nil {
+			return md, err
+		}
+		annotateMetrics(md, adjusted)
+		return md, nil
+	}
+}
+
+// annotateMetrics annotates the points of md with the outcome of their adjustment in adjusted.
+// The adjusters keep the resources, scopes and metrics in place, only their points can be
+// reordered, dropped or added, so the points are matched by their attributes and timestamp.
+func annotateMetrics(md, adjusted pmetric.Metrics) {
+	rms, adjustedRms := md.ResourceMetrics(), adjusted.ResourceMetrics()
+	if rms.Len() != adjustedRms.Len() {
+		return
+	}
+	for i := range rms.Len() {
+		sms, adjustedSms := rms.At(i).ScopeMetrics(), adjustedRms.At(i).ScopeMetrics()
+		if sms.Len() != adjustedSms.Len() {
+			continue
+		}
+		for j := range sms.Len() {
+			metrics, adjustedMetrics := sms.At(j).Metrics(), adjustedSms.At(j).Metrics()
+			if metrics.Len() != adjustedMetrics.Len() {
+				continue
+			}
+			for k := range metrics.Len() {
+				annotateMetric(metrics.At(k), adjustedMetrics.At(k))
+			}
+		}
+	}
+}
+
+func annotateMetric(metric, adjusted pmetric.Metric) {
+	if metric.Type() != adjusted.Type() {
+		return
+	}
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		annotatePoints(metric.Sum().DataPoints(), adjusted.Sum().DataPoints())
+	case pmetric.MetricTypeHistogram:
+		annotatePoints(metric.Histogram().DataPoints(), adjusted.Histogram().DataPoints())
+	case pmetric.MetricTypeExponentialHistogram:
+		annotatePoints(metric.ExponentialHistogram().DataPoints(), adjusted.ExponentialHistogram().DataPoints())
+	case pmetric.MetricTypeSummary:
+		annotatePoints(metric.Summary().DataPoints(), adjusted.Summary().DataPoints())
+	}
+}
+
+// dataPoint is implemented by the datapoints of all the metric types.
+type dataPoint interface {
+	Attributes() pcommon.Map
+	StartTimestamp() pcommon.Timestamp
+	Timestamp() pcommon.Timestamp
+}
+
+// dataPointSlice is implemented by the datapoint slices of all the metric types.
+type dataPointSlice[P dataPoint] interface {
+	Len() int
+	At(int) P
+}
+
+type dataPointKey struct {
+	attributes [16]byte
+	timestamp  pcommon.Timestamp
+}
+
+type dataPointOutcome struct {
+	startTimestamp pcommon.Timestamp
+	reset          bool
+}
+
+// annotatePoints sets the reset detected attribute on all the points, and the adjusted would
+// be attribute on the points not dropped by the adjustment.
+func annotatePoints[P dataPoint, S dataPointSlice[P]](points, adjusted S) {
+	outcomes := make(map[dataPointKey]dataPointOutcome, adjusted.Len())
+	for i := range adjusted.Len() {
+		point := adjusted.At(i)
+		attrs := point.Attributes()
+		_, reset := attrs.Get(datapointstorage.ResetDetectedAttribute)
+		attrs.Remove(datapointstorage.ResetDetectedAttribute)
+		key := dataPointKey{attributes: pdatautil.MapHash(attrs), timestamp: point.Timestamp()}
+		outcomes[key] = dataPointOutcome{startTimestamp: point.StartTimestamp(), reset: reset}
+	}
+	for i := range points.Len() {
+		point := points.At(i)
+		attrs := point.Attributes()
+		outcome, ok := outcomes[dataPointKey{attributes: pdatautil.MapHash(attrs), timestamp: point.Timestamp()}]
+		if ok {
+			attrs.PutInt(adjustedWouldBeAttribute, int64(outcome.startTimestamp))
+		}
+		attrs.PutBool(datapointstorage.ResetDetectedAttribute, outcome.reset)
+	}
+}
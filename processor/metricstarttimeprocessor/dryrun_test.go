@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metricstarttimeprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/subtractinitial"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/testhelper"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/truereset"
+)
+
+func TestDryRun(t *testing.T) {
+	start := testhelper.TimestampFromMs(500)
+	tests := []struct {
+		name     string
+		strategy string
+		// start is the start timestamp of the points.
+		start pcommon.Timestamp
+		// expected are the adjusted would be attributes of the points, 0 for none.
+		expected []pcommon.Timestamp
+	}{
+		{
+			name:     "true reset",
+			strategy: truereset.Type,
+			start:    start,
+			expected: []pcommon.Timestamp{start, start, testhelper.TimestampFromMs(499)},
+		},
+		{
+			name:     "subtract initial",
+			strategy: subtractinitial.Type,
+			expected: []pcommon.Timestamp{0, testhelper.TimestampFromMs(1000), testhelper.TimestampFromMs(2999)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Strategy = tt.strategy
+			cfg.DryRun = true
+			require.NoError(t, cfg.Validate())
+
+			sink := new(consumertest.MetricsSink)
+			p, err := NewFactory().CreateMetrics(context.Background(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+			require.NoError(t, err)
+			require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+			defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+
+			// The last point is a reset.
+			values := []float64{10, 15, 3}
+			for i, value := range values {
+				md := testhelper.Metrics(
+					testhelper.SumMetric("requests_total", testhelper.DoublePoint(nil, tt.start, testhelper.TimestampFromMs(int64(i+1)*1000), value)),
+					testhelper.GaugeMetric("temperature", testhelper.DoublePoint(nil, 0, testhelper.TimestampFromMs(int64(i+1)*1000), value)),
+				)
+				require.NoError(t, p.ConsumeMetrics(context.Background(), md))
+			}
+
+			require.Len(t, sink.AllMetrics(), len(values))
+			for i, md := range sink.AllMetrics() {
+				metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+				require.Equal(t, 1, metrics.At(0).Sum().DataPoints().Len())
+				point := metrics.At(0).Sum().DataPoints().At(0)
+				// The points are left unchanged apart from the annotations.
+				assert.Equal(t, tt.start, point.StartTimestamp())
+				assert.Equal(t, values[i], point.DoubleValue())
+
+				reset, ok := point.Attributes().Get(datapointstorage.ResetDetectedAttribute)
+				require.True(t, ok)
+				assert.Equal(t, i == len(values)-1, reset.Bool())
+				adjusted, ok := point.Attributes().Get(adjustedWouldBeAttribute)
+				if tt.expected[i] == 0 {
+					assert.False(t, ok, "the dropped point is not annotated with the start timestamp")
+				} else {
+					require.True(t, ok)
+					assert.Equal(t, int64(tt.expected[i]), adjusted.Int())
+				}
+
+				// Gauges have no start timestamp to adjust.
+				assert.Equal(t, 0, metrics.At(1).Gauge().DataPoints().At(0).Attributes().Len())
+			}
+		})
+	}
+}
+
+func TestAnnotatePointsUnmatched(t *testing.T) {
+	points := pmetric.NewNumberDataPointSlice()
+	points.AppendEmpty().SetTimestamp(1)
+	adjusted := pmetric.NewNumberDataPointSlice()
+	adjusted.AppendEmpty().SetTimestamp(2)
+
+	annotatePoints(points, adjusted)
+	attrs := points.At(0).Attributes()
+	_, ok := attrs.Get(adjustedWouldBeAttribute)
+	assert.False(t, ok)
+	reset, ok := attrs.Get(datapointstorage.ResetDetectedAttribute)
+	require.True(t, ok)
+	assert.False(t, reset.Bool())
+}
@@ -5,13 +5,16 @@ package metricstarttimeprocessor // import "github.com/open-telemetry/openteleme
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.opentelemetry.io/collector/processor"
 	"go.opentelemetry.io/collector/processor/processorhelper"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/starttimemetric"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/subtractinitial"
@@ -35,26 +38,45 @@ func createMetricsProcessor(
 ) (processor.Metrics, error) {
 	rCfg := cfg.(*Config)
 
-	var adjustMetrics processorhelper.ProcessMetricsFunc
+	selected, err := newMetricFilter(rCfg)
+	if err != nil {
+		return nil, err
+	}
+	tel, err := newProcessorTelemetry(set.TelemetrySettings)
+	if err != nil {
+		return nil, err
+	}
 
-	switch rCfg.Strategy {
-	case truereset.Type:
-		adjuster := truereset.NewAdjuster(set.TelemetrySettings, rCfg.GCInterval)
-		adjustMetrics = adjuster.AdjustMetrics
-	case subtractinitial.Type:
-		adjuster := subtractinitial.NewAdjuster(set.TelemetrySettings, rCfg.GCInterval)
-		adjustMetrics = adjuster.AdjustMetrics
-	case starttimemetric.Type:
-		var startTimeMetricRegex *regexp.Regexp
-		var err error
-		if rCfg.StartTimeMetricRegex != "" {
-			startTimeMetricRegex, err = regexp.Compile(rCfg.StartTimeMetricRegex)
-			if err != nil {
+	var adjustMetrics processorhelper.ProcessMetricsFunc
+	if len(rCfg.MetricStrategies) == 0 {
+		if adjustMetrics, err = newAdjuster(set, rCfg, rCfg.Strategy, selected, tel); err != nil {
+			return nil, err
+		}
+	} else {
+		router, err := newStrategyRouter(rCfg)
+		if err != nil {
+			return nil, err
+		}
+		router.adjusters = make(map[string]processorhelper.ProcessMetricsFunc, len(router.strategies))
+		for _, strategy := range router.strategies {
+			include := router.filter(strategy)
+			if selected != nil {
+				routed := include
+				include = func(metric pmetric.Metric) bool {
+					return selected(metric) && routed(metric)
+				}
+			}
+			if router.adjusters[strategy], err = newAdjuster(set, rCfg, strategy, include, tel); err != nil {
 				return nil, err
 			}
 		}
-		adjuster := starttimemetric.NewAdjuster(set.TelemetrySettings, startTimeMetricRegex)
-		adjustMetrics = adjuster.AdjustMetrics
+		adjustMetrics = router.AdjustMetrics
+	}
+	if rCfg.DryRun {
+		adjustMetrics = dryRun(adjustMetrics)
+	}
+	if err = tel.registerCallbacks(); err != nil {
+		return nil, err
 	}
 
 	return processorhelper.NewMetrics(
@@ -63,5 +85,73 @@ func createMetricsProcessor(
 		cfg,
 		nextConsumer,
 		adjustMetrics,
-		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}))
+		processorhelper.WithCapabilities(consumer.Capabilities{MutatesData: true}),
+		processorhelper.WithShutdown(tel.shutdown))
+}
+
+// newAdjuster creates the adjuster of strategy, reporting its telemetry with tel. When include
+// is not nil, only the metrics it selects are adjusted.
+func newAdjuster(set processor.Settings, cfg *Config, strategy string, include func(pmetric.Metric) bool, tel *processorTelemetry) (processorhelper.ProcessMetricsFunc, error) {
+	switch strategy {
+	case truereset.Type, truereset.ZeroPointType:
+		opts := []truereset.Option{
+			truereset.WithCacheOptions(
+				datapointstorage.WithMaxSeries(cfg.MaxTrackedSeries),
+				datapointstorage.WithIdentityExcludedAttributes(cfg.IdentityExcludedAttributes),
+				datapointstorage.WithResourceTTL(cfg.ResourceTTL)),
+			truereset.WithRestartAttributes(cfg.RestartAttributes),
+			truereset.WithTelemetryBuilder(tel.builder),
+		}
+		if cfg.ConvertDeltaToCumulative {
+			opts = append(opts, truereset.WithDeltaToCumulative())
+		}
+		if cfg.DryRun {
+			opts = append(opts, truereset.WithResetAnnotation())
+		}
+		if strategy == truereset.ZeroPointType {
+			opts = append(opts, truereset.WithResetZeroPoints())
+		}
+		if include != nil {
+			opts = append(opts, truereset.WithMetricFilter(include))
+		}
+		adjuster := truereset.NewAdjuster(set.TelemetrySettings, cfg.GCInterval, opts...)
+		tel.track(strategy, adjuster)
+		return adjuster.AdjustMetrics, nil
+	case subtractinitial.Type:
+		opts := []subtractinitial.Option{
+			subtractinitial.WithCacheOptions(
+				datapointstorage.WithMaxSeries(cfg.MaxTrackedSeries),
+				datapointstorage.WithIdentityExcludedAttributes(cfg.IdentityExcludedAttributes),
+				datapointstorage.WithResourceTTL(cfg.ResourceTTL)),
+			subtractinitial.WithRestartAttributes(cfg.RestartAttributes),
+			subtractinitial.WithTelemetryBuilder(tel.builder),
+		}
+		if cfg.ConvertDeltaToCumulative {
+			opts = append(opts, subtractinitial.WithDeltaToCumulative())
+		}
+		if cfg.DryRun {
+			opts = append(opts, subtractinitial.WithResetAnnotation())
+		}
+		if include != nil {
+			opts = append(opts, subtractinitial.WithMetricFilter(include))
+		}
+		adjuster := subtractinitial.NewAdjuster(set.TelemetrySettings, cfg.GCInterval, opts...)
+		tel.track(strategy, adjuster)
+		return adjuster.AdjustMetrics, nil
+	case starttimemetric.Type:
+		var startTimeMetricRegex *regexp.Regexp
+		var err error
+		if cfg.StartTimeMetricRegex != "" {
+			startTimeMetricRegex, err = regexp.Compile(cfg.StartTimeMetricRegex)
+			if err != nil {
+				return nil, err
+			}
+		}
+		opts := []starttimemetric.Option{starttimemetric.WithTelemetryBuilder(tel.builder)}
+		if include != nil {
+			opts = append(opts, starttimemetric.WithMetricFilter(include))
+		}
+		return starttimemetric.NewAdjuster(set.TelemetrySettings, startTimeMetricRegex, opts...).AdjustMetrics, nil
+	}
+	return nil, fmt.Errorf("%q is not a valid strategy", strategy)
 }
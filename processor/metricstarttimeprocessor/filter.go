@@ -0,0 +1,34 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metricstarttimeprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor"
+
+import (
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/filter/filterset"
+)
+
+// newMetricFilter returns the filter selecting the metrics to adjust from the include and
+// exclude settings, nil when all the metrics are adjusted.
+func newMetricFilter(cfg *Config) (func(pmetric.Metric) bool, error) {
+	var includeFS, excludeFS filterset.FilterSet
+	var err error
+	if cfg.Include.MatchType != "" {
+		if includeFS, err = filterset.CreateFilterSet(cfg.Include.Metrics, &cfg.Include.Config); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Exclude.MatchType != "" {
+		if excludeFS, err = filterset.CreateFilterSet(cfg.Exclude.Metrics, &cfg.Exclude.Config); err != nil {
+			return nil, err
+		}
+	}
+	if includeFS == nil && excludeFS == nil {
+		return nil, nil
+	}
+	return func(metric pmetric.Metric) bool {
+		return (includeFS == nil || includeFS.Matches(metric.Name())) &&
+			(excludeFS == nil || !excludeFS.Matches(metric.Name()))
+	}, nil
+}
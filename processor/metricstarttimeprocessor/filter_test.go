@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metricstarttimeprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/internal/filter/filterset"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/subtractinitial"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/testhelper"
+)
+
+func TestNewMetricFilter(t *testing.T) {
+	tests := []struct {
+		name     string
+		include  MatchMetrics
+		exclude  MatchMetrics
+		selected []string
+		skipped  []string
+	}{
+		{
+			name:     "no filter",
+			selected: []string{"container_cpu_seconds_total", "http_requests_total"},
+		},
+		{
+			name:     "include strict",
+			include:  MatchMetrics{Config: filterset.Config{MatchType: filterset.Strict}, Metrics: []string{"http_requests_total"}},
+			selected: []string{"http_requests_total"},
+			skipped:  []string{"container_cpu_seconds_total", "http_requests"},
+		},
+		{
+			name:     "exclude regexp",
+			exclude:  MatchMetrics{Config: filterset.Config{MatchType: filterset.Regexp}, Metrics: []string{"^container_"}},
+			selected: []string{"http_requests_total"},
+			skipped:  []string{"container_cpu_seconds_total"},
+		},
+		{
+			name:     "include and exclude",
+			include:  MatchMetrics{Config: filterset.Config{MatchType: filterset.Regexp}, Metrics: []string{"_total$"}},
+			exclude:  MatchMetrics{Config: filterset.Config{MatchType: filterset.Strict}, Metrics: []string{"http_requests_total"}},
+			selected: []string{"container_cpu_seconds_total"},
+			skipped:  []string{"http_requests_total", "container_memory_bytes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := newMetricFilter(&Config{Include: tt.include, Exclude: tt.exclude})
+			require.NoError(t, err)
+			if tt.skipped == nil {
+				assert.Nil(t, filter)
+				return
+			}
+			metric := pmetric.NewMetric()
+			for _, name := range tt.selected {
+				metric.SetName(name)
+				assert.True(t, filter(metric), name)
+			}
+			for _, name := range tt.skipped {
+				metric.SetName(name)
+				assert.False(t, filter(metric), name)
+			}
+		})
+	}
+}
+
+func TestMetricFilterExclude(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Strategy = subtractinitial.Type
+	cfg.Exclude = MatchMetrics{Config: filterset.Config{MatchType: filterset.Strict}, Metrics: []string{"http_requests_total"}}
+	require.NoError(t, cfg.Validate())
+
+	sink := new(consumertest.MetricsSink)
+	p, err := NewFactory().CreateMetrics(context.Background(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), testhelper.Metrics(
+		testhelper.SumMetric("container_cpu_seconds_total", testhelper.DoublePoint(nil, 0, testhelper.TimestampFromMs(1000), 10)),
+		testhelper.SumMetric("http_requests_total", testhelper.DoublePoint(nil, testhelper.TimestampFromMs(500), testhelper.TimestampFromMs(1000), 5)),
+	)))
+	require.Len(t, sink.AllMetrics(), 1)
+
+	// the initial point of the adjusted metric is dropped, the excluded metric is left untouched
+	metrics := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+	assert.Equal(t, 0, metrics.At(0).Sum().DataPoints().Len())
+	require.Equal(t, 1, metrics.At(1).Sum().DataPoints().Len())
+	assert.Equal(t, testhelper.TimestampFromMs(500), metrics.At(1).Sum().DataPoints().At(0).StartTimestamp())
+	assert.Equal(t, 5.0, metrics.At(1).Sum().DataPoints().At(0).DoubleValue())
+}
@@ -4,7 +4,10 @@
 package datapointstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
 
 import (
+	"encoding/binary"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -26,8 +29,8 @@ import (
 // it is marked. Similarly, each time a timeseriesInfo is accessed, it is also marked.
 //
 // At the end of each StartTimeCache.Get(), if the last time the StartTimeCache was gc'd exceeds the 'gcInterval',
-// the StartTimeCache is locked and any timeseriesMaps that are unmarked are removed from the StartTimeCache
-// otherwise the timeseriesMap is gc'd
+// the StartTimeCache is locked and, one shard at a time, any timeseriesMaps that are unmarked are removed from
+// the shard otherwise the timeseriesMap is gc'd
 //
 // The gc for the timeseriesMap is straightforward - the map is locked and, for each timeseriesInfo
 // in the map, if it has not been marked, it is removed otherwise it is unmarked.
@@ -41,21 +44,152 @@ import (
 //    the gc of timeseriesMaps can be moved to the end of MetricsAdjuster().AdjustMetricSlice(). This
 //    approach requires adding 'lastGC' Time and (potentially) a gcInterval duration to
 //    timeseriesMap so the current approach is used instead.
+//
+// Notes on resource expiry:
+//
+// A resource that stops reporting, e.g. a scaled-down pod, stays in the StartTimeCache for one to two
+// gcIntervals. When the cache is created with a resource ttl, every StartTimeCache.Get() records the time
+// of the access to the timeseriesMap of the resource, and every half ttl the timeseriesMaps not accessed
+// for the ttl are removed, whatever the gcInterval.
+//
+// Notes on eviction:
+//
+// The gc only removes the timeseries that were not accessed for a whole gcInterval, a source
+// churning through many timeseries within that interval still grows the cache without bounds.
+// Every timeseries records the tick of its last access. When the cache is created with a maximum
+// number of timeseries, once StartTimeCache.Get() finds the cache tracking more
+// timeseries than the maximum, the least recently used timeseries are removed from all the
+// timeseriesMaps, down to nine tenths of the maximum so that the eviction does not run
+// again for every new timeseries. As the number of timeseries is only checked there, the
+// maximum may be exceeded by the new timeseries of a single resource until the next Get().
 
 // Cache maps from a resource to a map of timeseries instances for the resource.
 type Cache struct {
 	sync.RWMutex
-	// The mutex is used to protect access to the member fields. It is acquired for most of
-	// get() and also acquired by gc().
+	// The mutex is used to serialize gc() and evict(). The resources are spread over the
+	// shards, each protected by its own mutex, so that the resources of concurrent callers
+	// do not contend for the same lock.
+
+	gcInterval time.Duration
+	// lastGC is the time of the last gc in nanoseconds since the epoch, read without
+	// locking by every Get().
+	lastGC atomic.Int64
+	shards []*cacheShard
+
+	// resourceTTL is the time after which a resource not accessed anymore is removed, 0 to
+	// only remove it with the gc.
+	resourceTTL time.Duration
+	// lastExpiry is the time of the last removal of the expired resources in nanoseconds
+	// since the epoch.
+	lastExpiry atomic.Int64
 
-	gcInterval  time.Duration
-	lastGC      time.Time
+	// maxSeries is the maximum number of timeseries tracked across all resources, 0 for no maximum.
+	maxSeries int
+	usage     *seriesUsage
+	evictions atomic.Int64
+	// identityExcluded are the datapoint attributes left out of the identity of the timeseries.
+	identityExcluded []string
+}
+
+// cacheShard holds the timeseriesMaps of the resources whose hash maps to the shard.
+type cacheShard struct {
+	sync.RWMutex
 	resourceMap map[[16]byte]*TimeseriesMap
 }
 
+// defaultShards is the number of shards of a cache created without WithShards.
+const defaultShards = 32
+
+// seriesUsage tracks the number of timeseries of a cache and orders their accesses.
+type seriesUsage struct {
+	series atomic.Int64
+	tick   atomic.Uint64
+	// gcEvictions is the number of timeseries removed by the gc.
+	gcEvictions atomic.Int64
+}
+
+// CacheOption configures a Cache.
+type CacheOption func(*Cache)
+
+// WithMaxSeries bounds the number of timeseries tracked by the cache, evicting the least
+// recently used ones when it is exceeded. A non positive maxSeries leaves the cache unbounded.
+func WithMaxSeries(maxSeries int) CacheOption {
+	return func(c *Cache) {
+		c.maxSeries = max(maxSeries, 0)
+	}
+}
+
+// WithIdentityExcludedAttributes leaves the given datapoint attributes out of the identity of
+// the timeseries: points differing only by their values are points of the same timeseries.
+func WithIdentityExcludedAttributes(attributes []string) CacheOption {
+	return func(c *Cache) {
+		c.identityExcluded = attributes
+	}
+}
+
+// WithResourceTTL removes the resources not accessed for the given ttl, independently of and
+// usually sooner than the gc. A non positive ttl leaves the resources to the gc.
+func WithResourceTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.resourceTTL = max(ttl, 0)
+	}
+}
+
+// WithShards spreads the resources of the cache over the given number of shards, each with its
+// own lock. A non positive shards leaves the default number of shards.
+func WithShards(shards int) CacheOption {
+	return func(c *Cache) {
+		if shards > 0 {
+			c.shards = make([]*cacheShard, shards)
+		}
+	}
+}
+
 // NewCache creates a new (empty) JobsMap.
-func NewCache(gcInterval time.Duration) *Cache {
-	return &Cache{gcInterval: gcInterval, lastGC: time.Now(), resourceMap: make(map[[16]byte]*TimeseriesMap)}
+func NewCache(gcInterval time.Duration, opts ...CacheOption) *Cache {
+	c := &Cache{gcInterval: gcInterval, shards: make([]*cacheShard, defaultShards), usage: &seriesUsage{}}
+	c.lastGC.Store(time.Now().UnixNano())
+	c.lastExpiry.Store(time.Now().UnixNano())
+	for _, opt := range opts {
+		opt(c)
+	}
+	for i := range c.shards {
+		c.shards[i] = &cacheShard{resourceMap: make(map[[16]byte]*TimeseriesMap)}
+	}
+	return c
+}
+
+// shard returns the shard holding the timeseriesMap of the resource.
+func (c *Cache) shard(resourceHash [16]byte) *cacheShard {
+	return c.shards[binary.LittleEndian.Uint64(resourceHash[:8])%uint64(len(c.shards))]
+}
+
+// resources returns the number of resources tracked by the cache.
+func (c *Cache) resources() int {
+	var n int
+	for _, shard := range c.shards {
+		shard.RLock()
+		n += len(shard.resourceMap)
+		shard.RUnlock()
+	}
+	return n
+}
+
+// Series returns the number of timeseries tracked by the cache.
+func (c *Cache) Series() int64 {
+	return c.usage.series.Load()
+}
+
+// Evictions returns the number of timeseries evicted so far for exceeding the maximum number of
+// timeseries of the cache.
+func (c *Cache) Evictions() int64 {
+	return c.evictions.Load()
+}
+
+// GCEvictions returns the number of timeseries removed so far by the gc for not being accessed
+// for a whole gcInterval.
+func (c *Cache) GCEvictions() int64 {
+	return c.usage.gcEvictions.Load()
 }
 
 // Remove jobs and timeseries that have aged out.
@@ -63,53 +197,175 @@ func (c *Cache) gc() {
 	c.Lock()
 	defer c.Unlock()
 	// once the structure is locked, confirm that gc() is still necessary
-	if time.Since(c.lastGC) > c.gcInterval {
-		for sig, tsm := range c.resourceMap {
-			tsm.RLock()
-			tsmNotMarked := !tsm.Mark
-			// take a read lock here, no need to get a full lock as we have a lock on the JobsMap
-			tsm.RUnlock()
-			if tsmNotMarked {
-				delete(c.resourceMap, sig)
-			} else {
-				// a full lock will be obtained in here, if required.
-				tsm.GC()
-			}
+	if c.gcDue() {
+		for _, shard := range c.shards {
+			shard.gc(c.usage)
 		}
-		c.lastGC = time.Now()
+		c.lastGC.Store(time.Now().UnixNano())
 	}
 }
 
-// Speculatively check if gc() is necessary, recheck once the structure is locked
+// gc removes the unmarked timeseriesMaps of the shard, and gc's the others.
+func (s *cacheShard) gc(usage *seriesUsage) {
+	s.Lock()
+	defer s.Unlock()
+	for sig, tsm := range s.resourceMap {
+		tsm.RLock()
+		tsmNotMarked := !tsm.Mark
+		// take a read lock here, no need to get a full lock as we have a lock on the shard
+		tsm.RUnlock()
+		if tsmNotMarked {
+			s.remove(sig, tsm, usage)
+		} else {
+			// a full lock will be obtained in here, if required.
+			tsm.GC()
+		}
+	}
+}
+
+// remove removes the timeseriesMap of the resource from the shard, counting its timeseries as
+// removed by the gc.
+func (s *cacheShard) remove(sig [16]byte, tsm *TimeseriesMap, usage *seriesUsage) {
+	// This should only be invoked while holding the lock of the shard.
+	delete(s.resourceMap, sig)
+	tsm.RLock()
+	usage.series.Add(-int64(len(tsm.TsiMap)))
+	usage.gcEvictions.Add(int64(len(tsm.TsiMap)))
+	tsm.RUnlock()
+}
+
+// Remove the resources not accessed for the resourceTTL.
+func (c *Cache) expire() {
+	c.Lock()
+	defer c.Unlock()
+	// once the structure is locked, confirm that expire() is still necessary
+	if c.expiryDue() {
+		expiredBefore := time.Now().Add(-c.resourceTTL).UnixNano()
+		for _, shard := range c.shards {
+			shard.expire(expiredBefore, c.usage)
+		}
+		c.lastExpiry.Store(time.Now().UnixNano())
+	}
+}
+
+// expire removes the timeseriesMaps of the shard last accessed before expiredBefore.
+func (s *cacheShard) expire(expiredBefore int64, usage *seriesUsage) {
+	s.Lock()
+	defer s.Unlock()
+	for sig, tsm := range s.resourceMap {
+		if tsm.lastSeen.Load() < expiredBefore {
+			s.remove(sig, tsm, usage)
+		}
+	}
+}
+
+// Speculatively check if gc() or expire() are necessary, recheck once the structure is locked
 func (c *Cache) MaybeGC() {
-	c.RLock()
-	defer c.RUnlock()
-	if time.Since(c.lastGC) > c.gcInterval {
+	if c.gcDue() {
 		go c.gc()
 	}
+	if c.expiryDue() {
+		go c.expire()
+	}
+}
+
+// expiryDue reports whether the expired resources must be removed: with a resourceTTL, they
+// are looked for every half resourceTTL, so that a resource is removed at most one and a half
+// resourceTTL after its last access.
+func (c *Cache) expiryDue() bool {
+	return c.resourceTTL > 0 && time.Since(time.Unix(0, c.lastExpiry.Load())) > c.resourceTTL/2
+}
+
+// gcDue reports whether the last gc is older than the gcInterval.
+func (c *Cache) gcDue() bool {
+	return time.Since(time.Unix(0, c.lastGC.Load())) > c.gcInterval
 }
 
 // Fetches the TimeseriesMap for the given resource hash. Returns a new empty tsm and false if there was
 // a cache miss.
 // To add a datapoint to the cache, callers can add to the returned tsm directly.
 func (c *Cache) Get(resourceHash [16]byte) (*TimeseriesMap, bool) {
+	shard := c.shard(resourceHash)
 	// a read lock is taken here as we will not need to modify resourceMap if the target timeseriesMap is available.
-	c.RLock()
-	tsm, ok := c.resourceMap[resourceHash]
-	c.RUnlock()
+	shard.RLock()
+	tsm, ok := shard.resourceMap[resourceHash]
+	shard.RUnlock()
 	defer c.MaybeGC()
+	c.maybeEvict()
 	if ok {
 		tsm.Mark = true
+		tsm.lastSeen.Store(time.Now().UnixNano())
 		return tsm, ok
 	}
-	c.Lock()
-	defer c.Unlock()
+	shard.Lock()
+	defer shard.Unlock()
 	// Now that we've got an exclusive lock, check once more to ensure an entry wasn't created in the interim
 	// and then create a new timeseriesMap if required.
-	tsm2, ok2 := c.resourceMap[resourceHash]
+	tsm2, ok2 := shard.resourceMap[resourceHash]
 	if !ok2 {
 		tsm2 = newTimeseriesMap()
-		c.resourceMap[resourceHash] = tsm2
+		tsm2.usage = c.usage
+		tsm2.identityExcluded = c.identityExcluded
+		shard.resourceMap[resourceHash] = tsm2
 	}
+	tsm2.lastSeen.Store(time.Now().UnixNano())
 	return tsm2, ok
 }
+
+// maybeEvict evicts the least recently used timeseries when the cache tracks more than the
+// maximum number of timeseries.
+func (c *Cache) maybeEvict() {
+	if c.maxSeries == 0 || c.usage.series.Load() <= int64(c.maxSeries) {
+		return
+	}
+	c.evict()
+}
+
+// evict removes the least recently used timeseries until the cache tracks no more than nine
+// tenths of the maximum number of timeseries.
+func (c *Cache) evict() {
+	c.Lock()
+	defer c.Unlock()
+	// once the structure is locked, confirm that the eviction is still necessary
+	series := c.usage.series.Load()
+	if series <= int64(c.maxSeries) {
+		return
+	}
+	excess := series - int64(c.maxSeries-c.maxSeries/10)
+
+	type candidate struct {
+		tsm      *TimeseriesMap
+		key      TimeseriesKey
+		lastUsed uint64
+	}
+	var candidates []candidate
+	for _, shard := range c.shards {
+		shard.RLock()
+		for _, tsm := range shard.resourceMap {
+			tsm.RLock()
+			for key, tsi := range tsm.TsiMap {
+				candidates = append(candidates, candidate{tsm: tsm, key: key, lastUsed: tsi.lastUsed})
+			}
+			tsm.RUnlock()
+		}
+		shard.RUnlock()
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastUsed < candidates[j].lastUsed
+	})
+
+	var evicted int64
+	for _, cand := range candidates {
+		if evicted >= excess {
+			break
+		}
+		cand.tsm.Lock()
+		// the timeseries may have been accessed since it was collected, keep it then.
+		if tsi, ok := cand.tsm.TsiMap[cand.key]; ok && tsi.lastUsed == cand.lastUsed {
+			cand.tsm.delete(cand.key)
+			evicted++
+		}
+		cand.tsm.Unlock()
+	}
+	c.evictions.Add(evicted)
+}
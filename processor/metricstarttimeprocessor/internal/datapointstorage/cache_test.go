@@ -4,6 +4,8 @@
 package datapointstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/starttimecache"
 
 import (
+	"fmt"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -20,8 +22,9 @@ func TestStartTimeCache_NewStartTimeCache(t *testing.T) {
 
 	assert.NotNil(t, stc)
 	assert.Equal(t, gcInterval, stc.gcInterval)
-	assert.WithinDuration(t, time.Now(), stc.lastGC, time.Second)
-	assert.Empty(t, stc.resourceMap)
+	assert.WithinDuration(t, time.Now(), time.Unix(0, stc.lastGC.Load()), time.Second)
+	assert.Len(t, stc.shards, defaultShards)
+	assert.Zero(t, stc.resources())
 }
 
 func TestStartTimeCache_Get(t *testing.T) {
@@ -86,10 +89,192 @@ func TestStartTimeCache_MaybeGC(t *testing.T) {
 	// Sleep for the GC interval. Expect the next GC to delete the resourceMap entries.
 	time.Sleep(stc.gcInterval)
 	stc.gc()
-	assert.Empty(t, stc.resourceMap)
+	assert.Zero(t, stc.resources())
 
 	tsm4, ok3 := stc.Get(resourceHash)
 	assert.NotNil(t, tsm4)
 	assert.True(t, tsm4.Mark)
 	assert.False(t, ok3)
 }
+
+func TestStartTimeCache_MaxSeries(t *testing.T) {
+	stc := NewCache(time.Minute, WithMaxSeries(10))
+	resourceHash := [16]byte{1}
+	otherResourceHash := [16]byte{2}
+	metric := pmetric.NewMetric()
+	metric.SetName("test_metric")
+	metric.SetEmptySum()
+	attrs := func(i int) pcommon.Map {
+		m := pcommon.NewMap()
+		m.PutInt("i", int64(i))
+		return m
+	}
+
+	tsm, _ := stc.Get(resourceHash)
+	for i := range 6 {
+		tsm.Get(metric, attrs(i))
+	}
+	otherTsm, _ := stc.Get(otherResourceHash)
+	for i := range 5 {
+		otherTsm.Get(metric, attrs(i))
+	}
+	// Access the oldest timeseries again so that it is not evicted.
+	tsm.Get(metric, attrs(0))
+	assert.Equal(t, int64(11), stc.usage.series.Load())
+	assert.Zero(t, stc.Evictions())
+
+	// The next Get evicts the least recently used timeseries down to nine tenths of the maximum.
+	stc.Get(resourceHash)
+	assert.Equal(t, int64(9), stc.usage.series.Load())
+	assert.Equal(t, int64(2), stc.Evictions())
+	assert.Len(t, tsm.TsiMap, 4)
+	assert.Len(t, otherTsm.TsiMap, 5)
+	_, found := tsm.Get(metric, attrs(0))
+	assert.True(t, found)
+	_, found = tsm.Get(metric, attrs(1))
+	assert.False(t, found)
+
+	// Removing a resource removes its timeseries from the count.
+	stc.gcInterval = 0
+	otherTsm.Mark = false
+	stc.gc()
+	assert.Equal(t, int64(5), stc.Series())
+	assert.Equal(t, int64(5), stc.GCEvictions())
+}
+
+func TestStartTimeCache_NoMaxSeries(t *testing.T) {
+	stc := NewCache(time.Minute, WithMaxSeries(0))
+	assert.Zero(t, stc.maxSeries)
+
+	tsm, _ := stc.Get([16]byte{1})
+	metric := pmetric.NewMetric()
+	metric.SetName("test_metric")
+	metric.SetEmptySum()
+	for i := range 100 {
+		attrs := pcommon.NewMap()
+		attrs.PutInt("i", int64(i))
+		tsm.Get(metric, attrs)
+	}
+	stc.Get([16]byte{1})
+	assert.Len(t, tsm.TsiMap, 100)
+	assert.Equal(t, int64(100), stc.Series())
+	assert.Zero(t, stc.Evictions())
+}
+
+func TestStartTimeCache_IdentityExcludedAttributes(t *testing.T) {
+	stc := NewCache(time.Minute, WithIdentityExcludedAttributes([]string{"instance"}))
+
+	tsm, _ := stc.Get([16]byte{1})
+	metric := pmetric.NewMetric()
+	metric.SetName("test_metric")
+	metric.SetEmptySum()
+
+	attrs := pcommon.NewMap()
+	attrs.PutStr("instance", "a")
+	attrs.PutStr("code", "200")
+	tsi, found := tsm.Get(metric, attrs)
+	assert.False(t, found)
+
+	// The excluded attribute changed: same timeseries.
+	attrs.PutStr("instance", "b")
+	tsi2, found := tsm.Get(metric, attrs)
+	assert.True(t, found)
+	assert.Same(t, tsi, tsi2)
+
+	// Another attribute changed: different timeseries.
+	attrs.PutStr("code", "500")
+	_, found = tsm.Get(metric, attrs)
+	assert.False(t, found)
+	assert.Len(t, tsm.TsiMap, 2)
+}
+
+func TestStartTimeCache_Shards(t *testing.T) {
+	stc := NewCache(time.Minute, WithShards(4))
+	assert.Len(t, stc.shards, 4)
+
+	for i := range 64 {
+		resourceAttrs := pcommon.NewMap()
+		resourceAttrs.PutInt("i", int64(i))
+		stc.Get(pdatautil.MapHash(resourceAttrs))
+	}
+	assert.Equal(t, 64, stc.resources())
+	for _, shard := range stc.shards {
+		assert.NotEmpty(t, shard.resourceMap)
+	}
+
+	// A non positive number of shards leaves the default.
+	assert.Len(t, NewCache(time.Minute, WithShards(0)).shards, defaultShards)
+}
+
+// BenchmarkCacheGet measures the throughput of concurrent callers each adjusting the timeseries
+// of their own resources, with all the resources in a single shard and with the default shards.
+func BenchmarkCacheGet(b *testing.B) {
+	const (
+		resources          = 1000
+		seriesPerResources = 100
+	)
+	resourceHashes := make([][16]byte, resources)
+	for i := range resourceHashes {
+		resourceAttrs := pcommon.NewMap()
+		resourceAttrs.PutInt("resource", int64(i))
+		resourceHashes[i] = pdatautil.MapHash(resourceAttrs)
+	}
+	series := make([]pcommon.Map, seriesPerResources)
+	for i := range series {
+		series[i] = pcommon.NewMap()
+		series[i].PutInt("series", int64(i))
+	}
+	metric := pmetric.NewMetric()
+	metric.SetName("test_metric")
+	metric.SetEmptySum()
+
+	for _, shards := range []int{1, defaultShards} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			stc := NewCache(time.Hour, WithShards(shards))
+			var next atomic.Int64
+			b.ReportAllocs()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := int(next.Add(1))
+				for pb.Next() {
+					tsm, _ := stc.Get(resourceHashes[i%resources])
+					tsm.Lock()
+					tsm.Get(metric, series[i%seriesPerResources])
+					tsm.Unlock()
+					i++
+				}
+			})
+		})
+	}
+}
+
+func TestStartTimeCache_ResourceTTL(t *testing.T) {
+	stc := NewCache(time.Hour, WithResourceTTL(time.Millisecond))
+	metric := pmetric.NewMetric()
+	metric.SetName("test_metric")
+	metric.SetEmptySum()
+
+	tsm, _ := stc.Get([16]byte{1})
+	tsm.Lock()
+	tsm.Get(metric, pcommon.NewMap())
+	tsm.Unlock()
+	time.Sleep(2 * time.Millisecond)
+	stc.Get([16]byte{2})
+
+	// The first resource was not accessed for the ttl, the gc would keep it for the gcInterval.
+	stc.expire()
+	assert.Equal(t, 1, stc.resources())
+	assert.Zero(t, stc.Series())
+	assert.Equal(t, int64(1), stc.GCEvictions())
+	_, ok := stc.Get([16]byte{1})
+	assert.False(t, ok)
+}
+
+func TestStartTimeCache_NoResourceTTL(t *testing.T) {
+	stc := NewCache(time.Hour, WithResourceTTL(0))
+	stc.Get([16]byte{1})
+	time.Sleep(2 * time.Millisecond)
+	assert.False(t, stc.expiryDue())
+	stc.expire()
+	assert.Equal(t, 1, stc.resources())
+}
@@ -0,0 +1,110 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package datapointstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
+
+import (
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// AccumulateSum converts the delta sum metric to a cumulative one, adding its points to the
+// running totals of their timeseries kept in tsm. The start timestamp of the cumulative points
+// is the start timestamp of the first delta point of their timeseries. Points not more recent
+// than the last point accumulated for their timeseries cannot be accumulated without counting
+// their value twice and are dropped. It returns the number of converted points.
+func AccumulateSum(tsm *TimeseriesMap, metric pmetric.Metric) int {
+	sum := metric.Sum()
+	if sum.AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+		return 0
+	}
+	SortDataPoints(metric)
+	points := sum.DataPoints()
+	points.RemoveIf(func(point pmetric.NumberDataPoint) bool {
+		tsi, found := tsm.Get(metric, point.Attributes())
+		if !found || point.ValueType() != tsi.Number.ValueType {
+			// First point of the timeseries, or the type of its values changed: start over.
+			if point.StartTimestamp() == 0 {
+				point.SetStartTimestamp(point.Timestamp())
+			}
+			tsi.Number = NewNumberPoint(point)
+			return false
+		}
+		if point.Timestamp() <= tsi.Number.Timestamp {
+			return true
+		}
+
+		tsi.Number.Timestamp = point.Timestamp()
+		point.SetStartTimestamp(tsi.Number.StartTimestamp)
+		if point.Flags().NoRecordedValue() {
+			return false
+		}
+		switch point.ValueType() {
+		case pmetric.NumberDataPointValueTypeInt:
+			tsi.Number.IntValue += point.IntValue()
+			point.SetIntValue(tsi.Number.IntValue)
+		case pmetric.NumberDataPointValueTypeDouble:
+			tsi.Number.DoubleValue += point.DoubleValue()
+			point.SetDoubleValue(tsi.Number.DoubleValue)
+		}
+		return false
+	})
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	return points.Len()
+}
+
+// AccumulateHistogram converts the delta histogram metric to a cumulative one, as AccumulateSum
+// does for sums. A change of the bucket boundaries of a timeseries starts its accumulation over.
+func AccumulateHistogram(tsm *TimeseriesMap, metric pmetric.Metric) int {
+	histogram := metric.Histogram()
+	if histogram.AggregationTemporality() != pmetric.AggregationTemporalityDelta {
+		return 0
+	}
+	SortDataPoints(metric)
+	points := histogram.DataPoints()
+	points.RemoveIf(func(point pmetric.HistogramDataPoint) bool {
+		tsi, found := tsm.Get(metric, point.Attributes())
+		if !found || BoundsHash(point.ExplicitBounds()) != tsi.Histogram.BoundsHash ||
+			point.BucketCounts().Len() != len(tsi.Histogram.BucketCounts) {
+			// First point of the timeseries, or its buckets changed: start over.
+			if point.StartTimestamp() == 0 {
+				point.SetStartTimestamp(point.Timestamp())
+			}
+			tsi.Histogram = NewHistogramPoint(point)
+			return false
+		}
+		if point.Timestamp() <= tsi.Histogram.Timestamp {
+			return true
+		}
+
+		acc := tsi.Histogram
+		acc.Timestamp = point.Timestamp()
+		point.SetStartTimestamp(acc.StartTimestamp)
+		if point.Flags().NoRecordedValue() {
+			return false
+		}
+		acc.Count += point.Count()
+		acc.Sum += point.Sum()
+		if point.HasMin() && (!acc.HasMin || point.Min() < acc.Min) {
+			acc.Min, acc.HasMin = point.Min(), true
+		}
+		if point.HasMax() && (!acc.HasMax || point.Max() > acc.Max) {
+			acc.Max, acc.HasMax = point.Max(), true
+		}
+		for i := range acc.BucketCounts {
+			acc.BucketCounts[i] += point.BucketCounts().At(i)
+		}
+
+		point.SetCount(acc.Count)
+		point.SetSum(acc.Sum)
+		if acc.HasMin {
+			point.SetMin(acc.Min)
+		}
+		if acc.HasMax {
+			point.SetMax(acc.Max)
+		}
+		point.BucketCounts().FromRaw(acc.BucketCounts)
+		return false
+	})
+	histogram.SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	return points.Len()
+}
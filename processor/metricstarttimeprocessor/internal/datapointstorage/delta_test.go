@@ -0,0 +1,129 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package datapointstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func deltaSum(points ...func(pmetric.NumberDataPoint)) pmetric.Metric {
+	metric := pmetric.NewMetric()
+	metric.SetName("test_sum")
+	sum := metric.SetEmptySum()
+	sum.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	for _, point := range points {
+		point(sum.DataPoints().AppendEmpty())
+	}
+	return metric
+}
+
+func intPoint(start, ts pcommon.Timestamp, value int64) func(pmetric.NumberDataPoint) {
+	return func(dp pmetric.NumberDataPoint) {
+		dp.SetStartTimestamp(start)
+		dp.SetTimestamp(ts)
+		dp.SetIntValue(value)
+	}
+}
+
+func TestAccumulateSum(t *testing.T) {
+	tsm := newTimeseriesMap()
+
+	metric := deltaSum(intPoint(1, 2, 5))
+	assert.Equal(t, 1, AccumulateSum(tsm, metric))
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, metric.Sum().AggregationTemporality())
+	assert.Equal(t, int64(5), metric.Sum().DataPoints().At(0).IntValue())
+
+	// The points are accumulated in order of their timestamps.
+	metric = deltaSum(intPoint(3, 4, 2), intPoint(2, 3, 3))
+	assert.Equal(t, 2, AccumulateSum(tsm, metric))
+	points := metric.Sum().DataPoints()
+	require.Equal(t, 2, points.Len())
+	assert.Equal(t, pcommon.Timestamp(1), points.At(0).StartTimestamp())
+	assert.Equal(t, int64(8), points.At(0).IntValue())
+	assert.Equal(t, pcommon.Timestamp(1), points.At(1).StartTimestamp())
+	assert.Equal(t, int64(10), points.At(1).IntValue())
+
+	// A point without value keeps the accumulated total.
+	metric = deltaSum(func(dp pmetric.NumberDataPoint) {
+		intPoint(4, 5, 0)(dp)
+		dp.SetFlags(pmetric.DefaultDataPointFlags.WithNoRecordedValue(true))
+	})
+	assert.Equal(t, 1, AccumulateSum(tsm, metric))
+	assert.Equal(t, pcommon.Timestamp(1), metric.Sum().DataPoints().At(0).StartTimestamp())
+	metric = deltaSum(intPoint(5, 6, 1))
+	AccumulateSum(tsm, metric)
+	assert.Equal(t, int64(11), metric.Sum().DataPoints().At(0).IntValue())
+
+	// A point not more recent than the accumulation is dropped.
+	metric = deltaSum(intPoint(5, 6, 1))
+	assert.Equal(t, 0, AccumulateSum(tsm, metric))
+
+	// A change of the value type starts the accumulation over.
+	metric = deltaSum(func(dp pmetric.NumberDataPoint) {
+		dp.SetStartTimestamp(6)
+		dp.SetTimestamp(7)
+		dp.SetDoubleValue(1.5)
+	})
+	AccumulateSum(tsm, metric)
+	assert.Equal(t, pcommon.Timestamp(6), metric.Sum().DataPoints().At(0).StartTimestamp())
+	assert.Equal(t, 1.5, metric.Sum().DataPoints().At(0).DoubleValue())
+}
+
+func TestAccumulateSumCumulative(t *testing.T) {
+	tsm := newTimeseriesMap()
+	metric := deltaSum(intPoint(1, 2, 5))
+	metric.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityCumulative)
+	assert.Equal(t, 0, AccumulateSum(tsm, metric))
+	assert.Empty(t, tsm.TsiMap)
+}
+
+func TestAccumulateHistogram(t *testing.T) {
+	deltaHistogram := func(start, ts pcommon.Timestamp, bounds []float64, counts []uint64) pmetric.Metric {
+		metric := pmetric.NewMetric()
+		metric.SetName("test_histogram")
+		histogram := metric.SetEmptyHistogram()
+		histogram.SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+		dp := histogram.DataPoints().AppendEmpty()
+		dp.SetStartTimestamp(start)
+		dp.SetTimestamp(ts)
+		dp.ExplicitBounds().FromRaw(bounds)
+		dp.BucketCounts().FromRaw(counts)
+		var count uint64
+		for _, c := range counts {
+			count += c
+		}
+		dp.SetCount(count)
+		dp.SetSum(float64(count))
+		dp.SetMin(float64(ts))
+		dp.SetMax(float64(ts))
+		return metric
+	}
+	tsm := newTimeseriesMap()
+
+	metric := deltaHistogram(1, 2, []float64{1, 2}, []uint64{1, 0, 2})
+	assert.Equal(t, 1, AccumulateHistogram(tsm, metric))
+
+	metric = deltaHistogram(2, 3, []float64{1, 2}, []uint64{0, 4, 1})
+	assert.Equal(t, 1, AccumulateHistogram(tsm, metric))
+	dp := metric.Histogram().DataPoints().At(0)
+	assert.Equal(t, pmetric.AggregationTemporalityCumulative, metric.Histogram().AggregationTemporality())
+	assert.Equal(t, pcommon.Timestamp(1), dp.StartTimestamp())
+	assert.Equal(t, []uint64{1, 4, 3}, dp.BucketCounts().AsRaw())
+	assert.Equal(t, uint64(8), dp.Count())
+	assert.Equal(t, 8.0, dp.Sum())
+	assert.Equal(t, 2.0, dp.Min())
+	assert.Equal(t, 3.0, dp.Max())
+
+	// A change of the bounds starts the accumulation over.
+	metric = deltaHistogram(3, 4, []float64{1, 2, 5}, []uint64{1, 1, 1, 1})
+	AccumulateHistogram(tsm, metric)
+	dp = metric.Histogram().DataPoints().At(0)
+	assert.Equal(t, pcommon.Timestamp(3), dp.StartTimestamp())
+	assert.Equal(t, []uint64{1, 1, 1, 1}, dp.BucketCounts().AsRaw())
+}
@@ -0,0 +1,57 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package datapointstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
+
+import (
+	"math"
+)
+
+// ReaggregateExponentialHistogram re-aggregates the buckets of dp to the lower or equal scale
+// and to the wider or equal zeroThreshold, as a producer lowering its scale or widening its zero
+// bucket would: the buckets are merged pairwise once per scale step, and the buckets entirely
+// within the zero threshold are merged into the zero bucket.
+func ReaggregateExponentialHistogram(dp *ExponentialHistogramPoint, scale int32, zeroThreshold float64) {
+	if scale >= dp.Scale && zeroThreshold <= dp.ZeroThreshold {
+		return
+	}
+	shift := max(dp.Scale-scale, 0)
+	scale = dp.Scale - shift
+	zeroThreshold = max(zeroThreshold, dp.ZeroThreshold)
+	for _, buckets := range []*ExponentialBuckets{&dp.Positive, &dp.Negative} {
+		offset, counts := downscaleBuckets(*buckets, shift)
+		for len(counts) > 0 && bucketUpperBound(offset, scale) <= zeroThreshold {
+			dp.ZeroCount += counts[0]
+			counts = counts[1:]
+			offset++
+		}
+		buckets.Offset = offset
+		buckets.BucketCounts = counts
+	}
+	dp.Scale = scale
+	dp.ZeroThreshold = zeroThreshold
+}
+
+// downscaleBuckets returns the offset and the counts of the buckets once re-aggregated to a
+// scale lower by shift: the bucket of index i becomes the bucket of index i >> shift.
+func downscaleBuckets(buckets ExponentialBuckets, shift int32) (int32, []uint64) {
+	n := len(buckets.BucketCounts)
+	if n == 0 {
+		return 0, nil
+	}
+	if shift == 0 {
+		return buckets.Offset, buckets.BucketCounts
+	}
+	offset := buckets.Offset >> shift
+	last := (buckets.Offset + int32(n) - 1) >> shift
+	counts := make([]uint64, last-offset+1)
+	for i, count := range buckets.BucketCounts {
+		counts[(buckets.Offset+int32(i))>>shift-offset] += count
+	}
+	return offset, counts
+}
+
+// bucketUpperBound returns the absolute upper bound of the bucket of index at scale.
+func bucketUpperBound(index, scale int32) float64 {
+	return math.Exp2(float64(index+1) * math.Exp2(-float64(scale)))
+}
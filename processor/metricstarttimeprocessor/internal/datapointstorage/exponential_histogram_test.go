@@ -0,0 +1,41 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package datapointstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReaggregateExponentialHistogram(t *testing.T) {
+	dp := &ExponentialHistogramPoint{
+		Scale:     1,
+		ZeroCount: 1,
+		Positive:  ExponentialBuckets{Offset: -1, BucketCounts: []uint64{1, 2, 3, 4, 5}},
+		Negative:  ExponentialBuckets{Offset: -3, BucketCounts: []uint64{1, 1, 1}},
+	}
+
+	// Neither a higher scale nor a narrower zero threshold can be reached.
+	ReaggregateExponentialHistogram(dp, 2, 0)
+	assert.Equal(t, int32(1), dp.Scale)
+	assert.Equal(t, []uint64{1, 2, 3, 4, 5}, dp.Positive.BucketCounts)
+
+	// Indices -1..3 at scale 1 are -1, 0, 0, 1, 1 at scale 0, -3..-1 are -2, -1, -1.
+	ReaggregateExponentialHistogram(dp, 0, 0)
+	assert.Equal(t, int32(0), dp.Scale)
+	assert.Equal(t, int32(-1), dp.Positive.Offset)
+	assert.Equal(t, []uint64{1, 5, 9}, dp.Positive.BucketCounts)
+	assert.Equal(t, int32(-2), dp.Negative.Offset)
+	assert.Equal(t, []uint64{1, 2}, dp.Negative.BucketCounts)
+	assert.Equal(t, uint64(1), dp.ZeroCount)
+
+	// At scale 0, the buckets of index -1 and 0 are bounded by 1 and 2.
+	ReaggregateExponentialHistogram(dp, 0, 2)
+	assert.Equal(t, 2.0, dp.ZeroThreshold)
+	assert.Equal(t, int32(1), dp.Positive.Offset)
+	assert.Equal(t, []uint64{9}, dp.Positive.BucketCounts)
+	assert.Empty(t, dp.Negative.BucketCounts)
+	assert.Equal(t, uint64(1+1+5+1+2), dp.ZeroCount)
+}
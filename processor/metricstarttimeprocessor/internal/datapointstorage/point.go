@@ -0,0 +1,179 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package datapointstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/cespare/xxhash/v2"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// The points kept for the timeseries only hold the fields needed to detect the resets and to
+// adjust the following points: neither the attributes, already part of the identity of the
+// timeseries, nor the exemplars, the explicit bounds or the quantiles of the datapoints.
+
+// NumberPoint is the part of a number datapoint kept for its timeseries.
+type NumberPoint struct {
+	StartTimestamp pcommon.Timestamp
+	Timestamp      pcommon.Timestamp
+	ValueType      pmetric.NumberDataPointValueType
+	IntValue       int64
+	DoubleValue    float64
+}
+
+// NewNumberPoint returns the part of dp kept for its timeseries.
+func NewNumberPoint(dp pmetric.NumberDataPoint) *NumberPoint {
+	p := &NumberPoint{}
+	p.CopyFrom(dp)
+	return p
+}
+
+// CopyFrom replaces the fields of p with the ones of dp.
+func (p *NumberPoint) CopyFrom(dp pmetric.NumberDataPoint) {
+	*p = NumberPoint{
+		StartTimestamp: dp.StartTimestamp(),
+		Timestamp:      dp.Timestamp(),
+		ValueType:      dp.ValueType(),
+		IntValue:       dp.IntValue(),
+		DoubleValue:    dp.DoubleValue(),
+	}
+}
+
+// HistogramPoint is the part of a histogram datapoint kept for its timeseries.
+type HistogramPoint struct {
+	StartTimestamp pcommon.Timestamp
+	Timestamp      pcommon.Timestamp
+	Count          uint64
+	Sum            float64
+	Min            float64
+	Max            float64
+	HasMin         bool
+	HasMax         bool
+	// BoundsHash identifies the explicit bounds of the datapoint, see BoundsHash.
+	BoundsHash   uint64
+	BucketCounts []uint64
+}
+
+// NewHistogramPoint returns the part of dp kept for its timeseries.
+func NewHistogramPoint(dp pmetric.HistogramDataPoint) *HistogramPoint {
+	p := &HistogramPoint{}
+	p.CopyFrom(dp)
+	return p
+}
+
+// CopyFrom replaces the fields of p with the ones of dp, reusing the bucket counts of p.
+func (p *HistogramPoint) CopyFrom(dp pmetric.HistogramDataPoint) {
+	*p = HistogramPoint{
+		StartTimestamp: dp.StartTimestamp(),
+		Timestamp:      dp.Timestamp(),
+		Count:          dp.Count(),
+		Sum:            dp.Sum(),
+		Min:            dp.Min(),
+		Max:            dp.Max(),
+		HasMin:         dp.HasMin(),
+		HasMax:         dp.HasMax(),
+		BoundsHash:     BoundsHash(dp.ExplicitBounds()),
+		BucketCounts:   copyCounts(p.BucketCounts, dp.BucketCounts()),
+	}
+}
+
+// BoundsHash returns the hash of the explicit bounds of a histogram datapoint.
+func BoundsHash(bounds pcommon.Float64Slice) uint64 {
+	var d xxhash.Digest
+	d.Reset()
+	var buf [8]byte
+	for i := range bounds.Len() {
+		binary.LittleEndian.PutUint64(buf[:], math.Float64bits(bounds.At(i)))
+		_, _ = d.Write(buf[:])
+	}
+	return d.Sum64()
+}
+
+// ExponentialHistogramPoint is the part of an exponential histogram datapoint kept for its
+// timeseries.
+type ExponentialHistogramPoint struct {
+	StartTimestamp pcommon.Timestamp
+	Timestamp      pcommon.Timestamp
+	Count          uint64
+	Sum            float64
+	Scale          int32
+	ZeroThreshold  float64
+	ZeroCount      uint64
+	Positive       ExponentialBuckets
+	Negative       ExponentialBuckets
+}
+
+// ExponentialBuckets are the positive or negative buckets of an ExponentialHistogramPoint.
+type ExponentialBuckets struct {
+	Offset       int32
+	BucketCounts []uint64
+}
+
+// NewExponentialHistogramPoint returns the part of dp kept for its timeseries.
+func NewExponentialHistogramPoint(dp pmetric.ExponentialHistogramDataPoint) *ExponentialHistogramPoint {
+	p := &ExponentialHistogramPoint{}
+	p.CopyFrom(dp)
+	return p
+}
+
+// CopyFrom replaces the fields of p with the ones of dp, reusing the bucket counts of p.
+func (p *ExponentialHistogramPoint) CopyFrom(dp pmetric.ExponentialHistogramDataPoint) {
+	*p = ExponentialHistogramPoint{
+		StartTimestamp: dp.StartTimestamp(),
+		Timestamp:      dp.Timestamp(),
+		Count:          dp.Count(),
+		Sum:            dp.Sum(),
+		Scale:          dp.Scale(),
+		ZeroThreshold:  dp.ZeroThreshold(),
+		ZeroCount:      dp.ZeroCount(),
+		Positive: ExponentialBuckets{
+			Offset:       dp.Positive().Offset(),
+			BucketCounts: copyCounts(p.Positive.BucketCounts, dp.Positive().BucketCounts()),
+		},
+		Negative: ExponentialBuckets{
+			Offset:       dp.Negative().Offset(),
+			BucketCounts: copyCounts(p.Negative.BucketCounts, dp.Negative().BucketCounts()),
+		},
+	}
+}
+
+// SummaryPoint is the part of a summary datapoint kept for its timeseries.
+type SummaryPoint struct {
+	StartTimestamp pcommon.Timestamp
+	Timestamp      pcommon.Timestamp
+	Count          uint64
+	Sum            float64
+}
+
+// NewSummaryPoint returns the part of dp kept for its timeseries.
+func NewSummaryPoint(dp pmetric.SummaryDataPoint) *SummaryPoint {
+	p := &SummaryPoint{}
+	p.CopyFrom(dp)
+	return p
+}
+
+// CopyFrom replaces the fields of p with the ones of dp.
+func (p *SummaryPoint) CopyFrom(dp pmetric.SummaryDataPoint) {
+	*p = SummaryPoint{
+		StartTimestamp: dp.StartTimestamp(),
+		Timestamp:      dp.Timestamp(),
+		Count:          dp.Count(),
+		Sum:            dp.Sum(),
+	}
+}
+
+// copyCounts copies counts to dst, reusing its capacity.
+func copyCounts(dst []uint64, counts pcommon.UInt64Slice) []uint64 {
+	if counts.Len() == 0 {
+		return nil
+	}
+	dst = dst[:0]
+	for i := range counts.Len() {
+		dst = append(dst, counts.At(i))
+	}
+	return dst
+}
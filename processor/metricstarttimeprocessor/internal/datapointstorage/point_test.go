@@ -0,0 +1,69 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package datapointstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestHistogramPoint(t *testing.T) {
+	dp := pmetric.NewHistogramDataPoint()
+	dp.Attributes().PutStr("key", "value")
+	dp.Exemplars().AppendEmpty().SetDoubleValue(1)
+	dp.SetStartTimestamp(1)
+	dp.SetTimestamp(2)
+	dp.SetCount(3)
+	dp.SetSum(4)
+	dp.SetMin(0.5)
+	dp.ExplicitBounds().FromRaw([]float64{1, 2})
+	dp.BucketCounts().FromRaw([]uint64{1, 1, 1})
+
+	p := NewHistogramPoint(dp)
+	assert.Equal(t, &HistogramPoint{
+		StartTimestamp: 1,
+		Timestamp:      2,
+		Count:          3,
+		Sum:            4,
+		Min:            0.5,
+		HasMin:         true,
+		BoundsHash:     boundsHash([]float64{1, 2}),
+		BucketCounts:   []uint64{1, 1, 1},
+	}, p)
+
+	// The bucket counts are reused.
+	buckets := p.BucketCounts
+	dp.BucketCounts().FromRaw([]uint64{2, 2, 2})
+	p.CopyFrom(dp)
+	assert.Equal(t, []uint64{2, 2, 2}, p.BucketCounts)
+	assert.Same(t, &buckets[0], &p.BucketCounts[0])
+}
+
+func TestBoundsHash(t *testing.T) {
+	assert.Equal(t, boundsHash([]float64{1, 2}), boundsHash([]float64{1, 2}))
+	assert.NotEqual(t, boundsHash([]float64{1, 2}), boundsHash([]float64{1, 3}))
+	assert.NotEqual(t, boundsHash([]float64{1, 2}), boundsHash([]float64{1, 2, 3}))
+	assert.NotEqual(t, boundsHash(nil), boundsHash([]float64{0}))
+}
+
+func TestExponentialHistogramPoint(t *testing.T) {
+	dp := pmetric.NewExponentialHistogramDataPoint()
+	dp.Attributes().PutStr("key", "value")
+	dp.SetTimestamp(2)
+	dp.SetScale(3)
+	dp.SetZeroThreshold(0.1)
+	dp.SetZeroCount(4)
+	dp.Positive().SetOffset(-1)
+	dp.Positive().BucketCounts().FromRaw([]uint64{1, 2})
+
+	assert.Equal(t, &ExponentialHistogramPoint{
+		Timestamp:     2,
+		Scale:         3,
+		ZeroThreshold: 0.1,
+		ZeroCount:     4,
+		Positive:      ExponentialBuckets{Offset: -1, BucketCounts: []uint64{1, 2}},
+	}, NewExponentialHistogramPoint(dp))
+}
@@ -0,0 +1,54 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package datapointstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
+
+import (
+	"slices"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+)
+
+// ResourceHashes returns the hash identifying the resource, computed without its restartAttributes,
+// and the hash of the restartAttributes, whose change means that the resource restarted. The
+// restart hash is the zero value when restartAttributes is empty.
+func ResourceHashes(resource pcommon.Resource, restartAttributes []string) (resourceHash, restartHash [16]byte) {
+	if len(restartAttributes) == 0 {
+		return pdatautil.MapHash(resource.Attributes()), restartHash
+	}
+	identity := pcommon.NewMap()
+	restart := pcommon.NewMap()
+	resource.Attributes().Range(func(k string, v pcommon.Value) bool {
+		if slices.Contains(restartAttributes, k) {
+			v.CopyTo(restart.PutEmpty(k))
+		} else {
+			v.CopyTo(identity.PutEmpty(k))
+		}
+		return true
+	})
+	return pdatautil.MapHash(identity), pdatautil.MapHash(restart)
+}
+
+// ObserveRestartHash records the hash of the restart attributes of the resource. When it differs
+// from the previously recorded one, the resource restarted and the next point of each of its
+// timeseries is a reset.
+func (tsm *TimeseriesMap) ObserveRestartHash(restartHash [16]byte) {
+	// This should only be invoked while holding the lock of the timeseriesMap.
+	if restartHash == tsm.restartHash {
+		return
+	}
+	tsm.restartHash = restartHash
+	for _, tsi := range tsm.TsiMap {
+		tsi.restarted = true
+	}
+}
+
+// ConsumeRestart reports whether the resource of the timeseries restarted since the last point
+// adjusted against it, clearing the restart.
+func (tsi *TimeseriesInfo) ConsumeRestart() bool {
+	restarted := tsi.restarted
+	tsi.restarted = false
+	return restarted
+}
@@ -0,0 +1,53 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package datapointstorage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+)
+
+func TestResourceHashes(t *testing.T) {
+	resource := pcommon.NewResource()
+	resource.Attributes().PutStr("service.name", "svc")
+	resource.Attributes().PutInt("process.pid", 1)
+
+	resourceHash, restartHash := ResourceHashes(resource, nil)
+	assert.Equal(t, pdatautil.MapHash(resource.Attributes()), resourceHash)
+	assert.Zero(t, restartHash)
+
+	resourceHash, restartHash = ResourceHashes(resource, []string{"process.pid"})
+	identity := pcommon.NewMap()
+	identity.PutStr("service.name", "svc")
+	assert.Equal(t, pdatautil.MapHash(identity), resourceHash)
+	assert.NotZero(t, restartHash)
+
+	resource.Attributes().PutInt("process.pid", 2)
+	resourceHash2, restartHash2 := ResourceHashes(resource, []string{"process.pid"})
+	assert.Equal(t, resourceHash, resourceHash2)
+	assert.NotEqual(t, restartHash, restartHash2)
+}
+
+func TestTimeseriesMap_ObserveRestartHash(t *testing.T) {
+	tsm := newTimeseriesMap()
+	metric := pmetric.NewMetric()
+	metric.SetName("test_metric")
+	metric.SetEmptySum()
+
+	tsm.ObserveRestartHash([16]byte{1})
+	tsi, _ := tsm.Get(metric, pcommon.NewMap())
+	assert.False(t, tsi.ConsumeRestart())
+
+	tsm.ObserveRestartHash([16]byte{1})
+	assert.False(t, tsi.ConsumeRestart())
+
+	tsm.ObserveRestartHash([16]byte{2})
+	assert.True(t, tsi.ConsumeRestart())
+	assert.False(t, tsi.ConsumeRestart())
+}
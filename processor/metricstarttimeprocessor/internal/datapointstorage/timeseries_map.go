@@ -4,7 +4,9 @@
 package datapointstorage // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
 
 import (
+	"slices"
 	"sync"
+	"sync/atomic"
 
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -12,17 +14,27 @@ import (
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
 )
 
+// ResetDetectedAttribute is the datapoint attribute the adjusters mark the reset points with
+// when asked to, see the dry_run option of the processor.
+const ResetDetectedAttribute = "starttime.reset_detected"
+
 // AttributeHash is used to store a hash of attributes for a metric. See pdatautil.MapHash for more details.
 type AttributeHash [16]byte
 
 // TimeseriesInfo contains the information necessary to adjust from the initial point and to detect resets.
 type TimeseriesInfo struct {
 	Mark bool
+	// lastUsed is the access tick of the cache the timeseries was last accessed at,
+	// ordering the timeseries for the eviction of the least recently used ones.
+	lastUsed uint64
+	// restarted is set when the resource of the timeseries restarted, see ObserveRestartHash.
+	restarted bool
 
-	Number               pmetric.NumberDataPoint
-	Histogram            pmetric.HistogramDataPoint
-	ExponentialHistogram pmetric.ExponentialHistogramDataPoint
-	Summary              pmetric.SummaryDataPoint
+	// Only the point of the type of the timeseries is set.
+	Number               *NumberPoint
+	Histogram            *HistogramPoint
+	ExponentialHistogram *ExponentialHistogramPoint
+	Summary              *SummaryPoint
 }
 
 type TimeseriesKey struct {
@@ -40,37 +52,37 @@ type TimeseriesMap struct {
 
 	Mark   bool
 	TsiMap map[TimeseriesKey]*TimeseriesInfo
+	// restartHash is the hash of the restart attributes of the resource, see ObserveRestartHash.
+	restartHash [16]byte
+
+	// usage is shared by the timeseriesMaps of a cache, nil for a timeseriesMap
+	// outside of a cache.
+	usage *seriesUsage
+	// identityExcluded are the datapoint attributes left out of the identity of the timeseries.
+	identityExcluded []string
+	// lastSeen is the time of the last access to the timeseriesMap through its cache in
+	// nanoseconds since the epoch, see Cache.expire.
+	lastSeen atomic.Int64
 }
 
 // Get the TimeseriesInfo for the timeseries associated with the metric and label values.
 func (tsm *TimeseriesMap) Get(metric pmetric.Metric, kv pcommon.Map) (*TimeseriesInfo, bool) {
 	// This should only be invoked be functions called (directly or indirectly) by AdjustMetricSlice().
 	// The lock protecting tsm.tsiMap is acquired there.
-	name := metric.Name()
-	key := TimeseriesKey{
-		Name:       name,
-		Attributes: pdatautil.MapHash(kv),
-	}
-	switch metric.Type() {
-	case pmetric.MetricTypeHistogram:
-		// There are 2 types of Histograms whose aggregation temporality needs distinguishing:
-		// * CumulativeHistogram
-		// * GaugeHistogram
-		key.AggTemporality = metric.Histogram().AggregationTemporality()
-	case pmetric.MetricTypeExponentialHistogram:
-		// There are 2 types of ExponentialHistograms whose aggregation temporality needs distinguishing:
-		// * CumulativeHistogram
-		// * GaugeHistogram
-		key.AggTemporality = metric.ExponentialHistogram().AggregationTemporality()
-	}
-
+	key := tsm.key(metric, kv)
 	tsm.Mark = true
 	tsi, ok := tsm.TsiMap[key]
 	if !ok {
 		tsi = &TimeseriesInfo{}
 		tsm.TsiMap[key] = tsi
+		if tsm.usage != nil {
+			tsm.usage.series.Add(1)
+		}
 	}
 	tsi.Mark = true
+	if tsm.usage != nil {
+		tsi.lastUsed = tsm.usage.tick.Add(1)
+	}
 	return tsi, ok
 }
 
@@ -80,7 +92,10 @@ func (tsm *TimeseriesMap) GC() {
 	defer tsm.Unlock()
 	for ts, tsi := range tsm.TsiMap {
 		if !tsi.Mark {
-			delete(tsm.TsiMap, ts)
+			tsm.delete(ts)
+			if tsm.usage != nil {
+				tsm.usage.gcEvictions.Add(1)
+			}
 		} else {
 			tsi.Mark = false
 		}
@@ -88,36 +103,72 @@ func (tsm *TimeseriesMap) GC() {
 	tsm.Mark = false
 }
 
+// Remove forgets the timeseries associated with the metric and label values, if tracked.
+func (tsm *TimeseriesMap) Remove(metric pmetric.Metric, kv pcommon.Map) {
+	// This should only be invoked while holding the lock of the timeseriesMap.
+	tsm.delete(tsm.key(metric, kv))
+}
+
+// key returns the key of the timeseries associated with the metric and label values.
+func (tsm *TimeseriesMap) key(metric pmetric.Metric, kv pcommon.Map) TimeseriesKey {
+	name := metric.Name()
+	key := TimeseriesKey{
+		Name:       name,
+		Attributes: tsm.identityHash(kv),
+	}
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		// Delta sums accumulated into cumulative ones must not share the timeseries of the
+		// cumulative sums of the same name.
+		key.AggTemporality = metric.Sum().AggregationTemporality()
+	case pmetric.MetricTypeHistogram:
+		// There are 2 types of Histograms whose aggregation temporality needs distinguishing:
+		// * CumulativeHistogram
+		// * GaugeHistogram
+		key.AggTemporality = metric.Histogram().AggregationTemporality()
+	case pmetric.MetricTypeExponentialHistogram:
+		// There are 2 types of ExponentialHistograms whose aggregation temporality needs distinguishing:
+		// * CumulativeHistogram
+		// * GaugeHistogram
+		key.AggTemporality = metric.ExponentialHistogram().AggregationTemporality()
+	}
+	return key
+}
+
+// delete removes the timeseries of key, the caller holding the lock.
+func (tsm *TimeseriesMap) delete(key TimeseriesKey) {
+	if _, ok := tsm.TsiMap[key]; !ok {
+		return
+	}
+	delete(tsm.TsiMap, key)
+	if tsm.usage != nil {
+		tsm.usage.series.Add(-1)
+	}
+}
+
 // IsResetHistogram compares the given histogram datapoint h, to ref
 // and determines whether the metric has been reset based on the values.  It is
 // a reset if any of the bucket boundaries have changed, if any of the bucket
 // counts have decreased or if the total sum or count have decreased.
-func IsResetHistogram(h, ref pmetric.HistogramDataPoint) bool {
-	if h.Count() < ref.Count() {
+func IsResetHistogram(h pmetric.HistogramDataPoint, ref *HistogramPoint) bool {
+	if h.Count() < ref.Count {
 		return true
 	}
-	if h.Sum() < ref.Sum() {
+	if h.Sum() < ref.Sum {
 		return true
 	}
 
 	// Guard against bucket boundaries changes.
-	refBounds := ref.ExplicitBounds().AsRaw()
-	hBounds := h.ExplicitBounds().AsRaw()
-	if len(refBounds) != len(hBounds) {
+	if BoundsHash(h.ExplicitBounds()) != ref.BoundsHash {
 		return true
 	}
-	for i := range len(refBounds) {
-		if hBounds[i] != refBounds[i] {
-			return true
-		}
-	}
 
 	// We need to check individual buckets to make sure the counts are all increasing.
-	if ref.BucketCounts().Len() != h.BucketCounts().Len() {
+	if len(ref.BucketCounts) != h.BucketCounts().Len() {
 		return true
 	}
-	for i := range ref.BucketCounts().Len() {
-		if h.BucketCounts().At(i) < ref.BucketCounts().At(i) {
+	for i, count := range ref.BucketCounts {
+		if h.BucketCounts().At(i) < count {
 			return true
 		}
 	}
@@ -126,56 +177,120 @@ func IsResetHistogram(h, ref pmetric.HistogramDataPoint) bool {
 
 // IsResetExponentialHistogram compares the given exponential histogram
 // datapoint eh, to ref and determines whether the metric
-// has been reset based on the values.  It is a reset if any of the bucket
-// boundaries have changed, if any of the bucket counts have decreased or if the
-// total sum or count have decreased.
-func IsResetExponentialHistogram(eh, ref pmetric.ExponentialHistogramDataPoint) bool {
+// has been reset based on the values.  It is a reset if the total sum or count
+// have decreased, or if any of the bucket counts have decreased once the
+// buckets of ref are re-aggregated to the scale and zero threshold of eh. A
+// producer lowering its scale or widening its zero bucket merges buckets
+// without losing data, it is not a reset; raising its scale or narrowing its
+// zero bucket cannot happen without one.
+func IsResetExponentialHistogram(eh pmetric.ExponentialHistogramDataPoint, ref *ExponentialHistogramPoint) bool {
 	// Same as the histogram implementation
-	if eh.Count() < ref.Count() {
+	if eh.Count() < ref.Count {
 		return true
 	}
-	if eh.Sum() < ref.Sum() {
+	if eh.Sum() < ref.Sum {
 		return true
 	}
 
 	// Guard against bucket boundaries changes.
-	if ref.Scale() != eh.Scale() {
+	if eh.Scale() > ref.Scale || eh.ZeroThreshold() < ref.ZeroThreshold {
 		return true
 	}
+	shift := ref.Scale - eh.Scale()
 
 	// We need to check individual buckets to make sure the counts are all increasing.
-	if ref.Positive().BucketCounts().Len() != eh.Positive().BucketCounts().Len() {
-		return true
-	}
-	for i := range ref.Positive().BucketCounts().Len() {
-		if eh.Positive().BucketCounts().At(i) < ref.Positive().BucketCounts().At(i) {
-			return true
+	refZeroCount := ref.ZeroCount
+	for _, buckets := range []struct {
+		eh  pmetric.ExponentialHistogramDataPointBuckets
+		ref ExponentialBuckets
+	}{
+		{eh.Positive(), ref.Positive},
+		{eh.Negative(), ref.Negative},
+	} {
+		ehOffset, ehCounts := buckets.eh.Offset(), buckets.eh.BucketCounts()
+		refOffset, refCounts := downscaleBuckets(buckets.ref, shift)
+		for i, count := range refCounts {
+			index := refOffset + int32(i)
+			if bucketUpperBound(index, eh.Scale()) <= eh.ZeroThreshold() {
+				// The bucket is merged in the widened zero bucket of eh.
+				refZeroCount += count
+				continue
+			}
+			j := index - ehOffset
+			if j < 0 || int(j) >= ehCounts.Len() {
+				if count > 0 {
+					return true
+				}
+				continue
+			}
+			if ehCounts.At(int(j)) < count {
+				return true
+			}
 		}
 	}
-	if ref.Negative().BucketCounts().Len() != eh.Negative().BucketCounts().Len() {
-		return true
-	}
-	for i := range ref.Negative().BucketCounts().Len() {
-		if eh.Negative().BucketCounts().At(i) < ref.Negative().BucketCounts().At(i) {
-			return true
-		}
-	}
-
-	return false
+	return eh.ZeroCount() < refZeroCount
 }
 
 // IsResetSummary compares the given summary datapoint s to ref and
 // determines whether the metric has been reset based on the values.  It is a
 // reset if the count or sum has decreased.
-func IsResetSummary(s, ref pmetric.SummaryDataPoint) bool {
-	return s.Count() < ref.Count() || s.Sum() < ref.Sum()
+func IsResetSummary(s pmetric.SummaryDataPoint, ref *SummaryPoint) bool {
+	return s.Count() < ref.Count || s.Sum() < ref.Sum
 }
 
 // IsResetSum compares the given number datapoint s to ref and determines
 // whether the metric has been reset based on the values.  It is a reset if the
 // value has decreased.
-func IsResetSum(s, ref pmetric.NumberDataPoint) bool {
-	return s.DoubleValue() < ref.DoubleValue()
+func IsResetSum(s pmetric.NumberDataPoint, ref *NumberPoint) bool {
+	return s.DoubleValue() < ref.DoubleValue
+}
+
+// SortDataPoints sorts the points of metric by timestamp, keeping the order of
+// the points with the same timestamp. When a batch holds several points of the
+// same series, e.g. after batch processors recombined streams, each point is
+// then compared to the one preceding it in time rather than in the batch.
+func SortDataPoints(metric pmetric.Metric) {
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		metric.Sum().DataPoints().Sort(func(a, b pmetric.NumberDataPoint) bool {
+			return a.Timestamp() < b.Timestamp()
+		})
+	case pmetric.MetricTypeHistogram:
+		metric.Histogram().DataPoints().Sort(func(a, b pmetric.HistogramDataPoint) bool {
+			return a.Timestamp() < b.Timestamp()
+		})
+	case pmetric.MetricTypeExponentialHistogram:
+		metric.ExponentialHistogram().DataPoints().Sort(func(a, b pmetric.ExponentialHistogramDataPoint) bool {
+			return a.Timestamp() < b.Timestamp()
+		})
+	case pmetric.MetricTypeSummary:
+		metric.Summary().DataPoints().Sort(func(a, b pmetric.SummaryDataPoint) bool {
+			return a.Timestamp() < b.Timestamp()
+		})
+	}
+}
+
+// IsOutOfOrder reports whether a point with timestamp ts precedes ref, the
+// timestamp of the previous point of its series. Such a point cannot be
+// compared to the previous point to detect a reset.
+func IsOutOfOrder(ts, ref pcommon.Timestamp) bool {
+	return ts < ref
+}
+
+// identityHash returns the hash of the datapoint attributes kv identifying the timeseries,
+// computed without the attributes excluded from the identity.
+func (tsm *TimeseriesMap) identityHash(kv pcommon.Map) [16]byte {
+	if len(tsm.identityExcluded) == 0 {
+		return pdatautil.MapHash(kv)
+	}
+	identity := pcommon.NewMap()
+	kv.Range(func(k string, v pcommon.Value) bool {
+		if !slices.Contains(tsm.identityExcluded, k) {
+			v.CopyTo(identity.PutEmpty(k))
+		}
+		return true
+	})
+	return pdatautil.MapHash(identity)
 }
 
 func newTimeseriesMap() *TimeseriesMap {
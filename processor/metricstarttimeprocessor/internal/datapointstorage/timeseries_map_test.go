@@ -115,6 +115,12 @@ func TestNewTimeseriesMap(t *testing.T) {
 	assert.Empty(t, tsm.TsiMap)
 }
 
+func boundsHash(bounds []float64) uint64 {
+	s := pcommon.NewFloat64Slice()
+	s.FromRaw(bounds)
+	return BoundsHash(s)
+}
+
 func TestTimeseriesInfo_IsResetHistogram(t *testing.T) {
 	tests := []struct {
 		name          string
@@ -126,9 +132,9 @@ func TestTimeseriesInfo_IsResetHistogram(t *testing.T) {
 			name: "Count Decreased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Histogram = pmetric.NewHistogramDataPoint()
-				tsi.Histogram.SetCount(10)
-				tsi.Histogram.SetSum(50)
+				tsi.Histogram = &HistogramPoint{}
+				tsi.Histogram.Count = 10
+				tsi.Histogram.Sum = 50
 				return tsi
 			},
 			setupH: func() pmetric.HistogramDataPoint {
@@ -143,9 +149,9 @@ func TestTimeseriesInfo_IsResetHistogram(t *testing.T) {
 			name: "Sum Decreased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Histogram = pmetric.NewHistogramDataPoint()
-				tsi.Histogram.SetCount(10)
-				tsi.Histogram.SetSum(50)
+				tsi.Histogram = &HistogramPoint{}
+				tsi.Histogram.Count = 10
+				tsi.Histogram.Sum = 50
 				return tsi
 			},
 			setupH: func() pmetric.HistogramDataPoint {
@@ -160,10 +166,10 @@ func TestTimeseriesInfo_IsResetHistogram(t *testing.T) {
 			name: "Bounds Mismatch",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Histogram = pmetric.NewHistogramDataPoint()
-				tsi.Histogram.SetCount(10)
-				tsi.Histogram.SetSum(50)
-				tsi.Histogram.ExplicitBounds().FromRaw([]float64{1, 2, 3})
+				tsi.Histogram = &HistogramPoint{}
+				tsi.Histogram.Count = 10
+				tsi.Histogram.Sum = 50
+				tsi.Histogram.BoundsHash = boundsHash([]float64{1, 2, 3})
 				return tsi
 			},
 			setupH: func() pmetric.HistogramDataPoint {
@@ -179,11 +185,11 @@ func TestTimeseriesInfo_IsResetHistogram(t *testing.T) {
 			name: "Bucket Counts Decreased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Histogram = pmetric.NewHistogramDataPoint()
-				tsi.Histogram.SetCount(10)
-				tsi.Histogram.SetSum(50)
-				tsi.Histogram.ExplicitBounds().FromRaw([]float64{1, 2, 3})
-				tsi.Histogram.BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+				tsi.Histogram = &HistogramPoint{}
+				tsi.Histogram.Count = 10
+				tsi.Histogram.Sum = 50
+				tsi.Histogram.BoundsHash = boundsHash([]float64{1, 2, 3})
+				tsi.Histogram.BucketCounts = []uint64{1, 2, 3, 4}
 				return tsi
 			},
 			setupH: func() pmetric.HistogramDataPoint {
@@ -200,11 +206,11 @@ func TestTimeseriesInfo_IsResetHistogram(t *testing.T) {
 			name: "No Reset",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Histogram = pmetric.NewHistogramDataPoint()
-				tsi.Histogram.SetCount(10)
-				tsi.Histogram.SetSum(50)
-				tsi.Histogram.ExplicitBounds().FromRaw([]float64{1, 2, 3})
-				tsi.Histogram.BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+				tsi.Histogram = &HistogramPoint{}
+				tsi.Histogram.Count = 10
+				tsi.Histogram.Sum = 50
+				tsi.Histogram.BoundsHash = boundsHash([]float64{1, 2, 3})
+				tsi.Histogram.BucketCounts = []uint64{1, 2, 3, 4}
 				return tsi
 			},
 			setupH: func() pmetric.HistogramDataPoint {
@@ -221,11 +227,11 @@ func TestTimeseriesInfo_IsResetHistogram(t *testing.T) {
 			name: "Bucket Counts Length Mismatch",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Histogram = pmetric.NewHistogramDataPoint()
-				tsi.Histogram.SetCount(10)
-				tsi.Histogram.SetSum(50)
-				tsi.Histogram.ExplicitBounds().FromRaw([]float64{1, 2, 3})
-				tsi.Histogram.BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+				tsi.Histogram = &HistogramPoint{}
+				tsi.Histogram.Count = 10
+				tsi.Histogram.Sum = 50
+				tsi.Histogram.BoundsHash = boundsHash([]float64{1, 2, 3})
+				tsi.Histogram.BucketCounts = []uint64{1, 2, 3, 4}
 				return tsi
 			},
 			setupH: func() pmetric.HistogramDataPoint {
@@ -242,11 +248,11 @@ func TestTimeseriesInfo_IsResetHistogram(t *testing.T) {
 			name: "Zero Bucket Count",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Histogram = pmetric.NewHistogramDataPoint()
-				tsi.Histogram.SetCount(10)
-				tsi.Histogram.SetSum(50)
-				tsi.Histogram.ExplicitBounds().FromRaw([]float64{1, 2, 3})
-				tsi.Histogram.BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+				tsi.Histogram = &HistogramPoint{}
+				tsi.Histogram.Count = 10
+				tsi.Histogram.Sum = 50
+				tsi.Histogram.BoundsHash = boundsHash([]float64{1, 2, 3})
+				tsi.Histogram.BucketCounts = []uint64{1, 2, 3, 4}
 				return tsi
 			},
 			setupH: func() pmetric.HistogramDataPoint {
@@ -263,11 +269,11 @@ func TestTimeseriesInfo_IsResetHistogram(t *testing.T) {
 			name: "Zero Bucket Count but no change",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Histogram = pmetric.NewHistogramDataPoint()
-				tsi.Histogram.SetCount(0)
-				tsi.Histogram.SetSum(0)
-				tsi.Histogram.ExplicitBounds().FromRaw([]float64{1, 2, 3})
-				tsi.Histogram.BucketCounts().FromRaw([]uint64{0, 0, 0, 0})
+				tsi.Histogram = &HistogramPoint{}
+				tsi.Histogram.Count = 0
+				tsi.Histogram.Sum = 0
+				tsi.Histogram.BoundsHash = boundsHash([]float64{1, 2, 3})
+				tsi.Histogram.BucketCounts = []uint64{0, 0, 0, 0}
 				return tsi
 			},
 			setupH: func() pmetric.HistogramDataPoint {
@@ -302,9 +308,9 @@ func TestTimeseriesInfo_IsResetExponentialHistogram(t *testing.T) {
 			name: "Count Decreased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-				tsi.ExponentialHistogram.SetCount(10)
-				tsi.ExponentialHistogram.SetSum(50)
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
 				return tsi
 			},
 			setupEh: func() pmetric.ExponentialHistogramDataPoint {
@@ -319,9 +325,9 @@ func TestTimeseriesInfo_IsResetExponentialHistogram(t *testing.T) {
 			name: "Sum Decreased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-				tsi.ExponentialHistogram.SetCount(10)
-				tsi.ExponentialHistogram.SetSum(50)
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
 				return tsi
 			},
 			setupEh: func() pmetric.ExponentialHistogramDataPoint {
@@ -336,10 +342,10 @@ func TestTimeseriesInfo_IsResetExponentialHistogram(t *testing.T) {
 			name: "Positive Bucket Counts Decreased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-				tsi.ExponentialHistogram.SetCount(10)
-				tsi.ExponentialHistogram.SetSum(50)
-				tsi.ExponentialHistogram.Positive().BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.Positive.BucketCounts = []uint64{1, 2, 3, 4}
 				return tsi
 			},
 			setupEh: func() pmetric.ExponentialHistogramDataPoint {
@@ -355,10 +361,10 @@ func TestTimeseriesInfo_IsResetExponentialHistogram(t *testing.T) {
 			name: "Negative Bucket Counts Decreased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-				tsi.ExponentialHistogram.SetCount(10)
-				tsi.ExponentialHistogram.SetSum(50)
-				tsi.ExponentialHistogram.Negative().BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.Negative.BucketCounts = []uint64{1, 2, 3, 4}
 				return tsi
 			},
 			setupEh: func() pmetric.ExponentialHistogramDataPoint {
@@ -374,11 +380,11 @@ func TestTimeseriesInfo_IsResetExponentialHistogram(t *testing.T) {
 			name: "No Reset",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-				tsi.ExponentialHistogram.SetCount(10)
-				tsi.ExponentialHistogram.SetSum(50)
-				tsi.ExponentialHistogram.Positive().BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
-				tsi.ExponentialHistogram.Negative().BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.Positive.BucketCounts = []uint64{1, 2, 3, 4}
+				tsi.ExponentialHistogram.Negative.BucketCounts = []uint64{1, 2, 3, 4}
 				return tsi
 			},
 			setupEh: func() pmetric.ExponentialHistogramDataPoint {
@@ -395,10 +401,10 @@ func TestTimeseriesInfo_IsResetExponentialHistogram(t *testing.T) {
 			name: "Positive Bucket Counts Length Mismatch",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-				tsi.ExponentialHistogram.SetCount(10)
-				tsi.ExponentialHistogram.SetSum(50)
-				tsi.ExponentialHistogram.Positive().BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.Positive.BucketCounts = []uint64{1, 2, 3, 4}
 				return tsi
 			},
 			setupEh: func() pmetric.ExponentialHistogramDataPoint {
@@ -414,10 +420,10 @@ func TestTimeseriesInfo_IsResetExponentialHistogram(t *testing.T) {
 			name: "Negative Bucket Counts Length Mismatch",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-				tsi.ExponentialHistogram.SetCount(10)
-				tsi.ExponentialHistogram.SetSum(50)
-				tsi.ExponentialHistogram.Negative().BucketCounts().FromRaw([]uint64{1, 2, 3, 4})
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.Negative.BucketCounts = []uint64{1, 2, 3, 4}
 				return tsi
 			},
 			setupEh: func() pmetric.ExponentialHistogramDataPoint {
@@ -430,20 +436,149 @@ func TestTimeseriesInfo_IsResetExponentialHistogram(t *testing.T) {
 			expectedReset: true,
 		},
 		{
-			name: "Scale mismatch",
+			name: "Scale increased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-				tsi.ExponentialHistogram.SetCount(10)
-				tsi.ExponentialHistogram.SetSum(50)
-				tsi.ExponentialHistogram.SetScale(2)
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.Scale = 1
 				return tsi
 			},
 			setupEh: func() pmetric.ExponentialHistogramDataPoint {
 				eh := pmetric.NewExponentialHistogramDataPoint()
 				eh.SetCount(15)
 				eh.SetSum(60)
-				eh.SetScale(1)
+				eh.SetScale(2)
+				return eh
+			},
+			expectedReset: true,
+		},
+		{
+			name: "Scale decreased without data loss",
+			setupTsi: func() *TimeseriesInfo {
+				tsi := &TimeseriesInfo{}
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.Scale = 1
+				tsi.ExponentialHistogram.Positive.BucketCounts = []uint64{1, 2, 3, 4}
+				return tsi
+			},
+			setupEh: func() pmetric.ExponentialHistogramDataPoint {
+				eh := pmetric.NewExponentialHistogramDataPoint()
+				eh.SetCount(15)
+				eh.SetSum(60)
+				eh.SetScale(0)
+				eh.Positive().BucketCounts().FromRaw([]uint64{3, 8})
+				return eh
+			},
+			expectedReset: false,
+		},
+		{
+			name: "Scale decreased with bucket counts decreased",
+			setupTsi: func() *TimeseriesInfo {
+				tsi := &TimeseriesInfo{}
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.Scale = 1
+				tsi.ExponentialHistogram.Negative.Offset = -2
+				tsi.ExponentialHistogram.Negative.BucketCounts = []uint64{1, 2, 3, 4}
+				return tsi
+			},
+			setupEh: func() pmetric.ExponentialHistogramDataPoint {
+				eh := pmetric.NewExponentialHistogramDataPoint()
+				eh.SetCount(15)
+				eh.SetSum(60)
+				eh.SetScale(0)
+				eh.Negative().SetOffset(-1)
+				eh.Negative().BucketCounts().FromRaw([]uint64{3, 6})
+				return eh
+			},
+			expectedReset: true,
+		},
+		{
+			name: "Offset changed",
+			setupTsi: func() *TimeseriesInfo {
+				tsi := &TimeseriesInfo{}
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.Positive.Offset = 2
+				tsi.ExponentialHistogram.Positive.BucketCounts = []uint64{1, 1}
+				return tsi
+			},
+			setupEh: func() pmetric.ExponentialHistogramDataPoint {
+				eh := pmetric.NewExponentialHistogramDataPoint()
+				eh.SetCount(15)
+				eh.SetSum(60)
+				eh.Positive().BucketCounts().FromRaw([]uint64{1, 0, 1, 1})
+				return eh
+			},
+			expectedReset: false,
+		},
+		{
+			name: "Zero threshold widened",
+			setupTsi: func() *TimeseriesInfo {
+				tsi := &TimeseriesInfo{}
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.ZeroCount = 1
+				tsi.ExponentialHistogram.Positive.BucketCounts = []uint64{2, 3}
+				return tsi
+			},
+			setupEh: func() pmetric.ExponentialHistogramDataPoint {
+				eh := pmetric.NewExponentialHistogramDataPoint()
+				eh.SetCount(15)
+				eh.SetSum(60)
+				eh.SetZeroThreshold(2)
+				eh.SetZeroCount(3)
+				eh.Positive().BucketCounts().FromRaw([]uint64{0, 4})
+				return eh
+			},
+			expectedReset: false,
+		},
+		{
+			name: "Zero threshold widened with zero count decreased",
+			setupTsi: func() *TimeseriesInfo {
+				tsi := &TimeseriesInfo{}
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.ZeroCount = 1
+				tsi.ExponentialHistogram.Positive.BucketCounts = []uint64{2, 3}
+				return tsi
+			},
+			setupEh: func() pmetric.ExponentialHistogramDataPoint {
+				eh := pmetric.NewExponentialHistogramDataPoint()
+				eh.SetCount(15)
+				eh.SetSum(60)
+				eh.SetZeroThreshold(2)
+				eh.SetZeroCount(2)
+				eh.Positive().BucketCounts().FromRaw([]uint64{0, 4})
+				return eh
+			},
+			expectedReset: true,
+		},
+		{
+			name: "Zero threshold narrowed",
+			setupTsi: func() *TimeseriesInfo {
+				tsi := &TimeseriesInfo{}
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
+				tsi.ExponentialHistogram.ZeroThreshold = 2
+				tsi.ExponentialHistogram.ZeroCount = 3
+				return tsi
+			},
+			setupEh: func() pmetric.ExponentialHistogramDataPoint {
+				eh := pmetric.NewExponentialHistogramDataPoint()
+				eh.SetCount(15)
+				eh.SetSum(60)
+				eh.SetZeroThreshold(1)
+				eh.SetZeroCount(3)
 				return eh
 			},
 			expectedReset: true,
@@ -452,9 +587,9 @@ func TestTimeseriesInfo_IsResetExponentialHistogram(t *testing.T) {
 			name: "Reset on zero count",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-				tsi.ExponentialHistogram.SetCount(10)
-				tsi.ExponentialHistogram.SetSum(50)
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 10
+				tsi.ExponentialHistogram.Sum = 50
 				return tsi
 			},
 			setupEh: func() pmetric.ExponentialHistogramDataPoint {
@@ -469,9 +604,9 @@ func TestTimeseriesInfo_IsResetExponentialHistogram(t *testing.T) {
 			name: "Zero values but no reset",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-				tsi.ExponentialHistogram.SetCount(0)
-				tsi.ExponentialHistogram.SetSum(0)
+				tsi.ExponentialHistogram = &ExponentialHistogramPoint{}
+				tsi.ExponentialHistogram.Count = 0
+				tsi.ExponentialHistogram.Sum = 0
 				return tsi
 			},
 			setupEh: func() pmetric.ExponentialHistogramDataPoint {
@@ -504,9 +639,9 @@ func TestTimeseriesInfo_IsResetSummary(t *testing.T) {
 			name: "Count Decreased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Summary = pmetric.NewSummaryDataPoint()
-				tsi.Summary.SetCount(10)
-				tsi.Summary.SetSum(50)
+				tsi.Summary = &SummaryPoint{}
+				tsi.Summary.Count = 10
+				tsi.Summary.Sum = 50
 				return tsi
 			},
 			setupS: func() pmetric.SummaryDataPoint {
@@ -521,9 +656,9 @@ func TestTimeseriesInfo_IsResetSummary(t *testing.T) {
 			name: "Sum Decreased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Summary = pmetric.NewSummaryDataPoint()
-				tsi.Summary.SetCount(10)
-				tsi.Summary.SetSum(50)
+				tsi.Summary = &SummaryPoint{}
+				tsi.Summary.Count = 10
+				tsi.Summary.Sum = 50
 				return tsi
 			},
 			setupS: func() pmetric.SummaryDataPoint {
@@ -538,9 +673,9 @@ func TestTimeseriesInfo_IsResetSummary(t *testing.T) {
 			name: "No Reset",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Summary = pmetric.NewSummaryDataPoint()
-				tsi.Summary.SetCount(10)
-				tsi.Summary.SetSum(50)
+				tsi.Summary = &SummaryPoint{}
+				tsi.Summary.Count = 10
+				tsi.Summary.Sum = 50
 				return tsi
 			},
 			setupS: func() pmetric.SummaryDataPoint {
@@ -555,9 +690,9 @@ func TestTimeseriesInfo_IsResetSummary(t *testing.T) {
 			name: "Reset on zero count",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Summary = pmetric.NewSummaryDataPoint()
-				tsi.Summary.SetCount(10)
-				tsi.Summary.SetSum(50)
+				tsi.Summary = &SummaryPoint{}
+				tsi.Summary.Count = 10
+				tsi.Summary.Sum = 50
 				return tsi
 			},
 			setupS: func() pmetric.SummaryDataPoint {
@@ -590,8 +725,8 @@ func TestTimeseriesInfo_IsResetSum(t *testing.T) {
 			name: "Double Value decreased",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Number = pmetric.NewNumberDataPoint()
-				tsi.Number.SetDoubleValue(10)
+				tsi.Number = &NumberPoint{}
+				tsi.Number.DoubleValue = 10
 				return tsi
 			},
 			setupS: func() pmetric.NumberDataPoint {
@@ -605,8 +740,8 @@ func TestTimeseriesInfo_IsResetSum(t *testing.T) {
 			name: "No Reset",
 			setupTsi: func() *TimeseriesInfo {
 				tsi := &TimeseriesInfo{}
-				tsi.Number = pmetric.NewNumberDataPoint()
-				tsi.Number.SetDoubleValue(10)
+				tsi.Number = &NumberPoint{}
+				tsi.Number.DoubleValue = 10
 				return tsi
 			},
 			setupS: func() pmetric.NumberDataPoint {
@@ -626,3 +761,30 @@ func TestTimeseriesInfo_IsResetSum(t *testing.T) {
 		})
 	}
 }
+
+func TestSortDataPoints(t *testing.T) {
+	metric := pmetric.NewMetric()
+	sum := metric.SetEmptySum()
+	for i, ts := range []int64{3, 1, 2, 1} {
+		dp := sum.DataPoints().AppendEmpty()
+		dp.SetTimestamp(pcommon.Timestamp(ts))
+		dp.SetIntValue(int64(i))
+	}
+
+	SortDataPoints(metric)
+
+	var timestamps, values []int64
+	for i := 0; i < sum.DataPoints().Len(); i++ {
+		timestamps = append(timestamps, int64(sum.DataPoints().At(i).Timestamp()))
+		values = append(values, sum.DataPoints().At(i).IntValue())
+	}
+	assert.Equal(t, []int64{1, 1, 2, 3}, timestamps)
+	// points with the same timestamp keep their order
+	assert.Equal(t, []int64{1, 3, 2, 0}, values)
+}
+
+func TestIsOutOfOrder(t *testing.T) {
+	assert.True(t, IsOutOfOrder(1, 2))
+	assert.False(t, IsOutOfOrder(2, 2))
+	assert.False(t, IsOutOfOrder(3, 2))
+}
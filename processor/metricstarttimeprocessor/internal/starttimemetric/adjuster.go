@@ -12,7 +12,11 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/metadata"
 )
 
 const (
@@ -38,18 +42,42 @@ func init() {
 type Adjuster struct {
 	startTimeMetricRegex *regexp.Regexp
 	set                  component.TelemetrySettings
+	include              func(pmetric.Metric) bool
+	telemetry            *metadata.TelemetryBuilder
+}
+
+// Option configures an Adjuster.
+type Option func(*Adjuster)
+
+// WithMetricFilter restricts the adjustment to the metrics for which include returns true,
+// the other metrics are left untouched.
+func WithMetricFilter(include func(pmetric.Metric) bool) Option {
+	return func(a *Adjuster) {
+		a.include = include
+	}
+}
+
+// WithTelemetryBuilder records the adjusted datapoints with telemetry.
+func WithTelemetryBuilder(telemetry *metadata.TelemetryBuilder) Option {
+	return func(a *Adjuster) {
+		a.telemetry = telemetry
+	}
 }
 
 // NewAdjuster returns a new Adjuster which adjust metrics' start times based on the initial received points.
-func NewAdjuster(set component.TelemetrySettings, startTimeMetricRegex *regexp.Regexp) *Adjuster {
-	return &Adjuster{
+func NewAdjuster(set component.TelemetrySettings, startTimeMetricRegex *regexp.Regexp, opts ...Option) *Adjuster {
+	a := &Adjuster{
 		set:                  set,
 		startTimeMetricRegex: startTimeMetricRegex,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
 }
 
 // AdjustMetrics adjusts the start time of metrics based on a different metric in the batch.
-func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pmetric.Metrics, error) {
+func (a *Adjuster) AdjustMetrics(ctx context.Context, metrics pmetric.Metrics) (pmetric.Metrics, error) {
 	startTime, err := a.getStartTime(metrics)
 	if err != nil {
 		a.set.Logger.Debug("Couldn't get start time for metrics. Using fallback start time.", zap.Error(err), zap.Time("fallback_start_time", approximateCollectorStartTime))
@@ -57,12 +85,20 @@ func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pm
 	}
 
 	startTimeTs := timestampFromFloat64(startTime)
+	var adjusted int
 	for i := 0; i < metrics.ResourceMetrics().Len(); i++ {
 		rm := metrics.ResourceMetrics().At(i)
 		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
 			ilm := rm.ScopeMetrics().At(j)
 			for k := 0; k < ilm.Metrics().Len(); k++ {
 				metric := ilm.Metrics().At(k)
+				if a.include != nil && !a.include(metric) {
+					continue
+				}
+				if isDelta(metric) {
+					// Delta points carry their own start times, they are passed through untouched.
+					continue
+				}
 				switch metric.Type() {
 				case pmetric.MetricTypeGauge:
 					continue
@@ -73,6 +109,7 @@ func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pm
 						dp := dataPoints.At(l)
 						dp.SetStartTimestamp(startTimeTs)
 					}
+					adjusted += dataPoints.Len()
 
 				case pmetric.MetricTypeSummary:
 					dataPoints := metric.Summary().DataPoints()
@@ -80,6 +117,7 @@ func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pm
 						dp := dataPoints.At(l)
 						dp.SetStartTimestamp(startTimeTs)
 					}
+					adjusted += dataPoints.Len()
 
 				case pmetric.MetricTypeHistogram:
 					dataPoints := metric.Histogram().DataPoints()
@@ -87,6 +125,7 @@ func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pm
 						dp := dataPoints.At(l)
 						dp.SetStartTimestamp(startTimeTs)
 					}
+					adjusted += dataPoints.Len()
 
 				case pmetric.MetricTypeExponentialHistogram:
 					dataPoints := metric.ExponentialHistogram().DataPoints()
@@ -94,6 +133,7 @@ func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pm
 						dp := dataPoints.At(l)
 						dp.SetStartTimestamp(startTimeTs)
 					}
+					adjusted += dataPoints.Len()
 
 				default:
 					a.set.Logger.Warn("Unknown metric type", zap.String("type", metric.Type().String()))
@@ -101,10 +141,26 @@ func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pm
 			}
 		}
 	}
+	if a.telemetry != nil && adjusted > 0 {
+		a.telemetry.MetricstarttimeAdjustedDatapoints.Add(ctx, int64(adjusted), metric.WithAttributes(attribute.String("strategy", Type)))
+	}
 	// TODO: handle resets by factoring reset handling out of other strategies
 	return metrics, nil
 }
 
+// isDelta reports whether the metric has delta temporality.
+func isDelta(metric pmetric.Metric) bool {
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		return metric.Sum().AggregationTemporality() == pmetric.AggregationTemporalityDelta
+	case pmetric.MetricTypeHistogram:
+		return metric.Histogram().AggregationTemporality() == pmetric.AggregationTemporalityDelta
+	case pmetric.MetricTypeExponentialHistogram:
+		return metric.ExponentialHistogram().AggregationTemporality() == pmetric.AggregationTemporalityDelta
+	}
+	return false
+}
+
 func timestampFromFloat64(ts float64) pcommon.Timestamp {
 	secs := int64(ts)
 	nanos := int64((ts - float64(secs)) * 1e9)
@@ -5,14 +5,17 @@ package subtractinitial // import "github.com/open-telemetry/opentelemetry-colle
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/metadata"
 )
 
 // Type is the value users can use to configure the subtract initial point adjuster.
@@ -32,15 +35,81 @@ type Adjuster struct {
 	// timeseries provided to the adjuster for reset detection.
 	previousValueCache *datapointstorage.Cache
 	set                component.TelemetrySettings
+	include            func(pmetric.Metric) bool
+	cacheOpts          []datapointstorage.CacheOption
+	// restartAttributes are the resource attributes whose change is a reset of all the
+	// timeseries of the resource.
+	restartAttributes []string
+	telemetry         *metadata.TelemetryBuilder
+	// deltaToCumulative converts the delta sums and histograms to cumulative ones instead of
+	// passing them through.
+	deltaToCumulative bool
+	// annotateResets marks the reset points with datapointstorage.ResetDetectedAttribute.
+	annotateResets bool
+}
+
+// Option configures an Adjuster.
+type Option func(*Adjuster)
+
+// WithMetricFilter restricts the adjustment to the metrics for which include returns true,
+// the other metrics are left untouched.
+func WithMetricFilter(include func(pmetric.Metric) bool) Option {
+	return func(a *Adjuster) {
+		a.include = include
+	}
+}
+
+// WithCacheOptions configures both the reference and the previous value caches.
+func WithCacheOptions(opts ...datapointstorage.CacheOption) Option {
+	return func(a *Adjuster) {
+		a.cacheOpts = append(a.cacheOpts, opts...)
+	}
+}
+
+// WithRestartAttributes treats a change of the values of the given resource attributes, e.g.
+// process.pid, as a reset of all the timeseries of the resource, whatever their values. The
+// attributes are left out of the identity of the resource.
+func WithRestartAttributes(attributes []string) Option {
+	return func(a *Adjuster) {
+		a.restartAttributes = attributes
+	}
+}
+
+// WithTelemetryBuilder records the adjusted datapoints and the detected resets with telemetry.
+func WithTelemetryBuilder(telemetry *metadata.TelemetryBuilder) Option {
+	return func(a *Adjuster) {
+		a.telemetry = telemetry
+	}
+}
+
+// WithDeltaToCumulative converts the delta sums and histograms to cumulative ones, accumulating
+// them in the reference cache of the adjuster, instead of passing them through untouched. The
+// converted points are not adjusted further: their first point is kept.
+func WithDeltaToCumulative() Option {
+	return func(a *Adjuster) {
+		a.deltaToCumulative = true
+	}
+}
+
+// WithResetAnnotation marks the points detected as resets with the
+// datapointstorage.ResetDetectedAttribute attribute.
+func WithResetAnnotation() Option {
+	return func(a *Adjuster) {
+		a.annotateResets = true
+	}
 }
 
 // NewAdjuster returns a new Adjuster which adjust metrics' start times based on the initial received points.
-func NewAdjuster(set component.TelemetrySettings, gcInterval time.Duration) *Adjuster {
-	return &Adjuster{
-		referenceCache:     datapointstorage.NewCache(gcInterval),
-		previousValueCache: datapointstorage.NewCache(gcInterval),
-		set:                set,
+func NewAdjuster(set component.TelemetrySettings, gcInterval time.Duration, opts ...Option) *Adjuster {
+	a := &Adjuster{
+		set: set,
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	a.referenceCache = datapointstorage.NewCache(gcInterval, a.cacheOpts...)
+	a.previousValueCache = datapointstorage.NewCache(gcInterval, a.cacheOpts...)
+	return a
 }
 
 // AdjustMetrics adjusts the start time of metrics based on the initial received
@@ -56,10 +125,10 @@ func NewAdjuster(set component.TelemetrySettings, gcInterval time.Duration) *Adj
 // current point will be reported as is, and the reference point will be
 // updated. The function returns a new pmetric.Metrics containing the adjusted
 // metrics.
-func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pmetric.Metrics, error) {
+func (a *Adjuster) AdjustMetrics(ctx context.Context, metrics pmetric.Metrics) (pmetric.Metrics, error) {
 	for i := 0; i < metrics.ResourceMetrics().Len(); i++ {
 		rm := metrics.ResourceMetrics().At(i)
-		attrHash := pdatautil.MapHash(rm.Resource().Attributes())
+		attrHash, restartHash := datapointstorage.ResourceHashes(rm.Resource(), a.restartAttributes)
 		referenceTsm, _ := a.referenceCache.Get(attrHash)
 		previousValueTsm, _ := a.previousValueCache.Get(attrHash)
 
@@ -67,23 +136,37 @@ func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pm
 		// nothing else can modify the data used for adjustment.
 		referenceTsm.Lock()
 		previousValueTsm.Lock()
+		referenceTsm.ObserveRestartHash(restartHash)
 		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
 			ilm := rm.ScopeMetrics().At(j)
 			for k := range ilm.Metrics().Len() {
 				metric := ilm.Metrics().At(k)
+				if a.include != nil && !a.include(metric) {
+					continue
+				}
+				var adjusted, resets int
 				switch dataType := metric.Type(); dataType {
 				case pmetric.MetricTypeHistogram:
-					adjustMetricHistogram(referenceTsm, previousValueTsm, metric)
+					if a.deltaToCumulative && metric.Histogram().AggregationTemporality() == pmetric.AggregationTemporalityDelta {
+						adjusted = datapointstorage.AccumulateHistogram(referenceTsm, metric)
+						break
+					}
+					adjusted, resets = a.adjustMetricHistogram(referenceTsm, previousValueTsm, metric)
 
 				case pmetric.MetricTypeSummary:
-					adjustMetricSummary(referenceTsm, previousValueTsm, metric)
+					adjusted, resets = a.adjustMetricSummary(referenceTsm, previousValueTsm, metric)
 
 				case pmetric.MetricTypeSum:
-					adjustMetricSum(referenceTsm, previousValueTsm, metric)
+					if a.deltaToCumulative && metric.Sum().AggregationTemporality() == pmetric.AggregationTemporalityDelta {
+						adjusted = datapointstorage.AccumulateSum(referenceTsm, metric)
+						break
+					}
+					adjusted, resets = a.adjustMetricSum(referenceTsm, previousValueTsm, metric)
 
 				case pmetric.MetricTypeExponentialHistogram:
-					adjustMetricExponentialHistogram(referenceTsm, previousValueTsm, metric)
+					adjusted, resets = a.adjustMetricExponentialHistogram(referenceTsm, previousValueTsm, metric)
 				}
+				a.record(ctx, metric.Type(), adjusted, resets)
 			}
 		}
 		referenceTsm.Unlock()
@@ -93,14 +176,56 @@ func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pm
 	return metrics, nil
 }
 
-func adjustMetricHistogram(referenceTsm, previousValueTsm *datapointstorage.TimeseriesMap, metric pmetric.Metric) {
+// TrackedSeries returns the number of series the adjuster keeps the reference point of.
+func (a *Adjuster) TrackedSeries() int64 {
+	return a.referenceCache.Series()
+}
+
+// EvictedSeries returns the number of series whose reference point was removed so far by the gc
+// and for exceeding the maximum number of series.
+func (a *Adjuster) EvictedSeries() (gc, maxSeries int64) {
+	return a.referenceCache.GCEvictions(), a.referenceCache.Evictions()
+}
+
+// record records the datapoints adjusted and the resets detected in a metric of metricType.
+func (a *Adjuster) record(ctx context.Context, metricType pmetric.MetricType, adjusted, resets int) {
+	if a.telemetry == nil {
+		return
+	}
+	strategy := attribute.String("strategy", Type)
+	if adjusted > 0 {
+		a.telemetry.MetricstarttimeAdjustedDatapoints.Add(ctx, int64(adjusted), metric.WithAttributes(strategy))
+	}
+	if resets > 0 {
+		a.telemetry.MetricstarttimeResets.Add(ctx, int64(resets), metric.WithAttributes(strategy,
+			attribute.String("metric_type", strings.ToLower(metricType.String()))))
+	}
+}
+
+// forgetIfStale forgets the series of a stale marker, so that it is re-anchored as a new series
+// when it returns, and reports whether the point is one; the marker is reported unchanged.
+func forgetIfStale(referenceTsm, previousValueTsm *datapointstorage.TimeseriesMap, metric pmetric.Metric, flags pmetric.DataPointFlags, attributes pcommon.Map) bool {
+	if !flags.NoRecordedValue() {
+		return false
+	}
+	referenceTsm.Remove(metric, attributes)
+	previousValueTsm.Remove(metric, attributes)
+	return true
+}
+
+func (a *Adjuster) adjustMetricHistogram(referenceTsm, previousValueTsm *datapointstorage.TimeseriesMap, metric pmetric.Metric) (adjusted, resets int) {
 	histogram := metric.Histogram()
 	if histogram.AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
 		// Only dealing with CumulativeDistributions.
-		return
+		return 0, 0
 	}
 
+	datapointstorage.SortDataPoints(metric)
 	histogram.DataPoints().RemoveIf(func(currentDist pmetric.HistogramDataPoint) bool {
+		if forgetIfStale(referenceTsm, previousValueTsm, metric, currentDist.Flags(), currentDist.Attributes()) {
+			return false
+		}
+
 		pointStartTime := currentDist.StartTimestamp()
 		if pointStartTime != 0 && pointStartTime != currentDist.Timestamp() {
 			// Report point as is.
@@ -108,53 +233,70 @@ func adjustMetricHistogram(referenceTsm, previousValueTsm *datapointstorage.Time
 		}
 
 		referenceTsi, found := referenceTsm.Get(metric, currentDist.Attributes())
-		// previousTsi always exists when reference Tsi is found
-		previousTsi, _ := previousValueTsm.Get(metric, currentDist.Attributes())
-		if !found {
+		// The caches evict their least recently used timeseries independently, the timeseries
+		// is then started over from the current point.
+		previousTsi, previousFound := previousValueTsm.Get(metric, currentDist.Attributes())
+		if !found || !previousFound {
 			// First time we see this point. Skip it and use as a reference point for the next points.
-			referenceTsi.Histogram = pmetric.NewHistogramDataPoint()
-			minimalHistogramCopyTo(currentDist, referenceTsi.Histogram)
+			referenceTsi.Histogram = datapointstorage.NewHistogramPoint(currentDist)
 			// Use the timestamp of the dropped point as the start timestamp for future points.
-			referenceTsi.Histogram.SetStartTimestamp(referenceTsi.Histogram.Timestamp())
-			previousTsi.Histogram = pmetric.NewHistogramDataPoint()
-			minimalHistogramCopyTo(currentDist, previousTsi.Histogram)
+			referenceTsi.Histogram.StartTimestamp = referenceTsi.Histogram.Timestamp
+			previousTsi.Histogram = datapointstorage.NewHistogramPoint(currentDist)
 			return true
 		}
 
 		// Adjust the datapoint based on the reference value.
-		currentDist.SetStartTimestamp(referenceTsi.Histogram.StartTimestamp())
-		if currentDist.Flags().NoRecordedValue() {
+		currentDist.SetStartTimestamp(referenceTsi.Histogram.StartTimestamp)
+
+		if datapointstorage.IsOutOfOrder(currentDist.Timestamp(), previousTsi.Histogram.Timestamp) {
+			// The point is reported against the reference without being compared to the more recent
+			// previous point, or dropped when it precedes the reference too.
+			if currentDist.Timestamp() < referenceTsi.Histogram.StartTimestamp {
+				return true
+			}
+			subtractHistogramDataPoint(currentDist, referenceTsi.Histogram)
+			adjusted++
 			return false
 		}
 
-		if datapointstorage.IsResetHistogram(currentDist, previousTsi.Histogram) {
+		if referenceTsi.ConsumeRestart() || datapointstorage.IsResetHistogram(currentDist, previousTsi.Histogram) {
+			resets++
 			// reset re-initialize everything and use the non adjusted points start time.
 			resetStartTimeStamp := pcommon.NewTimestampFromTime(currentDist.Timestamp().AsTime().Add(-1 * time.Millisecond))
 			currentDist.SetStartTimestamp(resetStartTimeStamp)
+			if a.annotateResets {
+				currentDist.Attributes().PutBool(datapointstorage.ResetDetectedAttribute, true)
+			}
 
 			// Update the reference value with the metric point.
-			referenceTsi.Histogram = pmetric.NewHistogramDataPoint()
-			previousTsi.Histogram = pmetric.NewHistogramDataPoint()
-			referenceTsi.Histogram.SetStartTimestamp(resetStartTimeStamp)
-			currentDist.ExplicitBounds().CopyTo(referenceTsi.Histogram.ExplicitBounds())
-			referenceTsi.Histogram.BucketCounts().FromRaw(make([]uint64, currentDist.BucketCounts().Len()))
-			minimalHistogramCopyTo(currentDist, previousTsi.Histogram)
+			referenceTsi.Histogram = &datapointstorage.HistogramPoint{
+				StartTimestamp: resetStartTimeStamp,
+				BucketCounts:   make([]uint64, currentDist.BucketCounts().Len()),
+			}
+			previousTsi.Histogram.CopyFrom(currentDist)
 		} else {
-			minimalHistogramCopyTo(currentDist, previousTsi.Histogram)
+			previousTsi.Histogram.CopyFrom(currentDist)
 			subtractHistogramDataPoint(currentDist, referenceTsi.Histogram)
 		}
+		adjusted++
 		return false
 	})
+	return adjusted, resets
 }
 
-func adjustMetricExponentialHistogram(referenceTsm, previousValueTsm *datapointstorage.TimeseriesMap, metric pmetric.Metric) {
+func (a *Adjuster) adjustMetricExponentialHistogram(referenceTsm, previousValueTsm *datapointstorage.TimeseriesMap, metric pmetric.Metric) (adjusted, resets int) {
 	histogram := metric.ExponentialHistogram()
 	if histogram.AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
 		// Only dealing with CumulativeDistributions.
-		return
+		return 0, 0
 	}
 
+	datapointstorage.SortDataPoints(metric)
 	histogram.DataPoints().RemoveIf(func(currentDist pmetric.ExponentialHistogramDataPoint) bool {
+		if forgetIfStale(referenceTsm, previousValueTsm, metric, currentDist.Flags(), currentDist.Attributes()) {
+			return false
+		}
+
 		pointStartTime := currentDist.StartTimestamp()
 		if pointStartTime != 0 && pointStartTime != currentDist.Timestamp() {
 			// Report point as is.
@@ -162,53 +304,74 @@ func adjustMetricExponentialHistogram(referenceTsm, previousValueTsm *datapoints
 		}
 
 		referenceTsi, found := referenceTsm.Get(metric, currentDist.Attributes())
-		// previousTsi always exists when reference Tsi is found
-		previousTsi, _ := previousValueTsm.Get(metric, currentDist.Attributes())
-		if !found {
+		// The caches evict their least recently used timeseries independently, the timeseries
+		// is then started over from the current point.
+		previousTsi, previousFound := previousValueTsm.Get(metric, currentDist.Attributes())
+		if !found || !previousFound {
 			// First time we see this point. Skip it and use as a reference point for the next points.
-			referenceTsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-			minimalExponentialHistogramCopyTo(currentDist, referenceTsi.ExponentialHistogram)
+			referenceTsi.ExponentialHistogram = datapointstorage.NewExponentialHistogramPoint(currentDist)
 			// Use the timestamp of the dropped point as the start timestamp for future points.
-			referenceTsi.ExponentialHistogram.SetStartTimestamp(referenceTsi.ExponentialHistogram.Timestamp())
-			previousTsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-			minimalExponentialHistogramCopyTo(currentDist, previousTsi.ExponentialHistogram)
+			referenceTsi.ExponentialHistogram.StartTimestamp = referenceTsi.ExponentialHistogram.Timestamp
+			previousTsi.ExponentialHistogram = datapointstorage.NewExponentialHistogramPoint(currentDist)
 			return true
 		}
 
 		// Adjust the datapoint based on the reference value.
-		currentDist.SetStartTimestamp(referenceTsi.ExponentialHistogram.StartTimestamp())
-		if currentDist.Flags().NoRecordedValue() {
+		currentDist.SetStartTimestamp(referenceTsi.ExponentialHistogram.StartTimestamp)
+
+		if datapointstorage.IsOutOfOrder(currentDist.Timestamp(), previousTsi.ExponentialHistogram.Timestamp) {
+			// The point is reported against the reference without being compared to the more recent
+			// previous point, or dropped when it precedes the reference too.
+			if currentDist.Timestamp() < referenceTsi.ExponentialHistogram.StartTimestamp {
+				return true
+			}
+			subtractExponentialHistogramDataPoint(currentDist, referenceTsi.ExponentialHistogram)
+			adjusted++
 			return false
 		}
 
-		if datapointstorage.IsResetExponentialHistogram(currentDist, previousTsi.ExponentialHistogram) {
+		if referenceTsi.ConsumeRestart() || datapointstorage.IsResetExponentialHistogram(currentDist, previousTsi.ExponentialHistogram) {
+			resets++
 			// reset re-initialize everything and use the non adjusted points start time.
 			resetStartTimeStamp := pcommon.NewTimestampFromTime(currentDist.Timestamp().AsTime().Add(-1 * time.Millisecond))
 			currentDist.SetStartTimestamp(resetStartTimeStamp)
+			if a.annotateResets {
+				currentDist.Attributes().PutBool(datapointstorage.ResetDetectedAttribute, true)
+			}
 
-			referenceTsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-			previousTsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-			referenceTsi.ExponentialHistogram.SetStartTimestamp(resetStartTimeStamp)
-			referenceTsi.ExponentialHistogram.SetScale(currentDist.Scale())
-			referenceTsi.ExponentialHistogram.Positive().BucketCounts().FromRaw(make([]uint64, currentDist.Positive().BucketCounts().Len()))
-			referenceTsi.ExponentialHistogram.Negative().BucketCounts().FromRaw(make([]uint64, currentDist.Negative().BucketCounts().Len()))
-			minimalExponentialHistogramCopyTo(currentDist, previousTsi.ExponentialHistogram)
+			referenceTsi.ExponentialHistogram = &datapointstorage.ExponentialHistogramPoint{
+				StartTimestamp: resetStartTimeStamp,
+				Scale:          currentDist.Scale(),
+				Positive:       datapointstorage.ExponentialBuckets{BucketCounts: make([]uint64, currentDist.Positive().BucketCounts().Len())},
+				Negative:       datapointstorage.ExponentialBuckets{BucketCounts: make([]uint64, currentDist.Negative().BucketCounts().Len())},
+			}
+			previousTsi.ExponentialHistogram.CopyFrom(currentDist)
 		} else {
-			minimalExponentialHistogramCopyTo(currentDist, previousTsi.ExponentialHistogram)
+			previousTsi.ExponentialHistogram.CopyFrom(currentDist)
+			// A producer lowering its scale or widening its zero bucket is not a reset, the reference
+			// follows the buckets of the current point.
+			datapointstorage.ReaggregateExponentialHistogram(referenceTsi.ExponentialHistogram, currentDist.Scale(), currentDist.ZeroThreshold())
 			subtractExponentialHistogramDataPoint(currentDist, referenceTsi.ExponentialHistogram)
 		}
+		adjusted++
 		return false
 	})
+	return adjusted, resets
 }
 
-func adjustMetricSum(referenceTsm, previousValueTsm *datapointstorage.TimeseriesMap, metric pmetric.Metric) {
+func (a *Adjuster) adjustMetricSum(referenceTsm, previousValueTsm *datapointstorage.TimeseriesMap, metric pmetric.Metric) (adjusted, resets int) {
 	sum := metric.Sum()
 	if sum.AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
 		// Only handle cumulative temporality sums
-		return
+		return 0, 0
 	}
 
+	datapointstorage.SortDataPoints(metric)
 	sum.DataPoints().RemoveIf(func(currentSum pmetric.NumberDataPoint) bool {
+		if forgetIfStale(referenceTsm, previousValueTsm, metric, currentSum.Flags(), currentSum.Attributes()) {
+			return false
+		}
+
 		pointStartTime := currentSum.StartTimestamp()
 		if pointStartTime != 0 && pointStartTime != currentSum.Timestamp() {
 			// Report point as is.
@@ -216,44 +379,62 @@ func adjustMetricSum(referenceTsm, previousValueTsm *datapointstorage.Timeseries
 		}
 
 		referenceTsi, found := referenceTsm.Get(metric, currentSum.Attributes())
-		// previousTsi always exists when reference Tsi is found
-		previousTsi, _ := previousValueTsm.Get(metric, currentSum.Attributes())
-		if !found {
+		// The caches evict their least recently used timeseries independently, the timeseries
+		// is then started over from the current point.
+		previousTsi, previousFound := previousValueTsm.Get(metric, currentSum.Attributes())
+		if !found || !previousFound {
 			// First time we see this point. Skip it and use as a reference point for the next points.
-			referenceTsi.Number = pmetric.NewNumberDataPoint()
-			minimalSumCopyTo(currentSum, referenceTsi.Number)
+			referenceTsi.Number = datapointstorage.NewNumberPoint(currentSum)
 			// Use the timestamp of the dropped point as the start timestamp for future points.
-			referenceTsi.Number.SetStartTimestamp(referenceTsi.Number.Timestamp())
-			previousTsi.Number = pmetric.NewNumberDataPoint()
-			minimalSumCopyTo(currentSum, previousTsi.Number)
+			referenceTsi.Number.StartTimestamp = referenceTsi.Number.Timestamp
+			previousTsi.Number = datapointstorage.NewNumberPoint(currentSum)
 			return true
 		}
 
 		// Adjust the datapoint based on the reference value.
-		currentSum.SetStartTimestamp(referenceTsi.Number.StartTimestamp())
-		if currentSum.Flags().NoRecordedValue() {
+		currentSum.SetStartTimestamp(referenceTsi.Number.StartTimestamp)
+
+		if datapointstorage.IsOutOfOrder(currentSum.Timestamp(), previousTsi.Number.Timestamp) {
+			// The point is reported against the reference without being compared to the more recent
+			// previous point, or dropped when it precedes the reference too.
+			if currentSum.Timestamp() < referenceTsi.Number.StartTimestamp {
+				return true
+			}
+			currentSum.SetDoubleValue(currentSum.DoubleValue() - referenceTsi.Number.DoubleValue)
+			adjusted++
 			return false
 		}
 
-		if datapointstorage.IsResetSum(currentSum, previousTsi.Number) {
+		if referenceTsi.ConsumeRestart() || datapointstorage.IsResetSum(currentSum, previousTsi.Number) {
+			resets++
 			// reset re-initialize everything and use the non adjusted points start time.
 			resetStartTimeStamp := pcommon.NewTimestampFromTime(currentSum.Timestamp().AsTime().Add(-1 * time.Millisecond))
 			currentSum.SetStartTimestamp(resetStartTimeStamp)
+			if a.annotateResets {
+				currentSum.Attributes().PutBool(datapointstorage.ResetDetectedAttribute, true)
+			}
 
-			referenceTsi.Number = pmetric.NewNumberDataPoint()
-			previousTsi.Number = pmetric.NewNumberDataPoint()
-			referenceTsi.Number.SetStartTimestamp(resetStartTimeStamp)
-			minimalSumCopyTo(currentSum, previousTsi.Number)
+			referenceTsi.Number = &datapointstorage.NumberPoint{
+				StartTimestamp: resetStartTimeStamp,
+			}
+			previousTsi.Number.CopyFrom(currentSum)
 		} else {
-			minimalSumCopyTo(currentSum, previousTsi.Number)
-			currentSum.SetDoubleValue(currentSum.DoubleValue() - referenceTsi.Number.DoubleValue())
+			previousTsi.Number.CopyFrom(currentSum)
+			currentSum.SetDoubleValue(currentSum.DoubleValue() - referenceTsi.Number.DoubleValue)
 		}
+		adjusted++
 		return false
 	})
+	return adjusted, resets
 }
 
-func adjustMetricSummary(referenceTsm, previousValueTsm *datapointstorage.TimeseriesMap, metric pmetric.Metric) {
+func (a *Adjuster) adjustMetricSummary(referenceTsm, previousValueTsm *datapointstorage.TimeseriesMap, metric pmetric.Metric) (adjusted, resets int) {
+	datapointstorage.SortDataPoints(metric)
 	metric.Summary().DataPoints().RemoveIf(func(currentSummary pmetric.SummaryDataPoint) bool {
+		if forgetIfStale(referenceTsm, previousValueTsm, metric, currentSummary.Flags(), currentSummary.Attributes()) {
+			return false
+		}
+
 		pointStartTime := currentSummary.StartTimestamp()
 		if pointStartTime != 0 && pointStartTime != currentSummary.Timestamp() {
 			// Report point as is.
@@ -261,138 +442,97 @@ func adjustMetricSummary(referenceTsm, previousValueTsm *datapointstorage.Timese
 		}
 
 		referenceTsi, found := referenceTsm.Get(metric, currentSummary.Attributes())
-		// previousTsi always exists when reference Tsi is found
-		previousTsi, _ := previousValueTsm.Get(metric, currentSummary.Attributes())
-		if !found {
+		// The caches evict their least recently used timeseries independently, the timeseries
+		// is then started over from the current point.
+		previousTsi, previousFound := previousValueTsm.Get(metric, currentSummary.Attributes())
+		if !found || !previousFound {
 			// First time we see this point. Skip it and use as a reference point for the next points.
-			referenceTsi.Summary = pmetric.NewSummaryDataPoint()
-			minimalSummaryCopyTo(currentSummary, referenceTsi.Summary)
+			referenceTsi.Summary = datapointstorage.NewSummaryPoint(currentSummary)
 			// Use the timestamp of the dropped point as the start timestamp for future points.
-			referenceTsi.Summary.SetStartTimestamp(referenceTsi.Summary.Timestamp())
-			previousTsi.Summary = pmetric.NewSummaryDataPoint()
-			minimalSummaryCopyTo(currentSummary, previousTsi.Summary)
+			referenceTsi.Summary.StartTimestamp = referenceTsi.Summary.Timestamp
+			previousTsi.Summary = datapointstorage.NewSummaryPoint(currentSummary)
 			return true
 		}
 
 		// Adjust the datapoint based on the reference value.
-		currentSummary.SetStartTimestamp(referenceTsi.Summary.StartTimestamp())
-		if currentSummary.Flags().NoRecordedValue() {
+		currentSummary.SetStartTimestamp(referenceTsi.Summary.StartTimestamp)
+
+		if datapointstorage.IsOutOfOrder(currentSummary.Timestamp(), previousTsi.Summary.Timestamp) {
+			// The point is reported against the reference without being compared to the more recent
+			// previous point, or dropped when it precedes the reference too.
+			if currentSummary.Timestamp() < referenceTsi.Summary.StartTimestamp {
+				return true
+			}
+			currentSummary.SetCount(currentSummary.Count() - referenceTsi.Summary.Count)
+			currentSummary.SetSum(currentSummary.Sum() - referenceTsi.Summary.Sum)
+			adjusted++
 			return false
 		}
 
-		if datapointstorage.IsResetSummary(currentSummary, previousTsi.Summary) {
+		if referenceTsi.ConsumeRestart() || datapointstorage.IsResetSummary(currentSummary, previousTsi.Summary) {
+			resets++
 			// reset re-initialize everything and use the non adjusted points start time.
 			resetStartTimeStamp := pcommon.NewTimestampFromTime(currentSummary.Timestamp().AsTime().Add(-1 * time.Millisecond))
 			currentSummary.SetStartTimestamp(resetStartTimeStamp)
+			if a.annotateResets {
+				currentSummary.Attributes().PutBool(datapointstorage.ResetDetectedAttribute, true)
+			}
 
-			referenceTsi.Summary = pmetric.NewSummaryDataPoint()
-			previousTsi.Summary = pmetric.NewSummaryDataPoint()
-			referenceTsi.Summary.SetStartTimestamp(resetStartTimeStamp)
-			minimalSummaryCopyTo(currentSummary, previousTsi.Summary)
+			referenceTsi.Summary = &datapointstorage.SummaryPoint{
+				StartTimestamp: resetStartTimeStamp,
+			}
+			previousTsi.Summary.CopyFrom(currentSummary)
 		} else {
-			currentSummary.SetStartTimestamp(referenceTsi.Summary.StartTimestamp())
-			minimalSummaryCopyTo(currentSummary, previousTsi.Summary)
-			currentSummary.SetCount(currentSummary.Count() - referenceTsi.Summary.Count())
-			currentSummary.SetSum(currentSummary.Sum() - referenceTsi.Summary.Sum())
+			currentSummary.SetStartTimestamp(referenceTsi.Summary.StartTimestamp)
+			previousTsi.Summary.CopyFrom(currentSummary)
+			currentSummary.SetCount(currentSummary.Count() - referenceTsi.Summary.Count)
+			currentSummary.SetSum(currentSummary.Sum() - referenceTsi.Summary.Sum)
 		}
+		adjusted++
 		return false
 	})
+	return adjusted, resets
 }
 
 // subtractHistogramDataPoint subtracts b from a.
-func subtractHistogramDataPoint(a, b pmetric.HistogramDataPoint) {
-	a.SetStartTimestamp(b.StartTimestamp())
-	a.SetCount(a.Count() - b.Count())
-	a.SetSum(a.Sum() - b.Sum())
+func subtractHistogramDataPoint(a pmetric.HistogramDataPoint, b *datapointstorage.HistogramPoint) {
+	a.SetStartTimestamp(b.StartTimestamp)
+	a.SetCount(a.Count() - b.Count)
+	a.SetSum(a.Sum() - b.Sum)
 	aBuckets := a.BucketCounts()
-	bBuckets := b.BucketCounts()
-	if bBuckets.Len() != aBuckets.Len() {
+	if len(b.BucketCounts) != aBuckets.Len() {
 		// Post reset, the reference histogram will have no buckets.
 		return
 	}
 	newBuckets := make([]uint64, aBuckets.Len())
 	for i := 0; i < aBuckets.Len(); i++ {
-		newBuckets[i] = aBuckets.At(i) - bBuckets.At(i)
+		newBuckets[i] = aBuckets.At(i) - b.BucketCounts[i]
 	}
 	a.BucketCounts().FromRaw(newBuckets)
 }
 
 // subtractExponentialHistogramDataPoint subtracts b from a.
-func subtractExponentialHistogramDataPoint(a, b pmetric.ExponentialHistogramDataPoint) {
-	a.SetStartTimestamp(b.StartTimestamp())
-	a.SetCount(a.Count() - b.Count())
-	a.SetSum(a.Sum() - b.Sum())
-	a.SetZeroCount(a.ZeroCount() - b.ZeroCount())
-	if a.Positive().BucketCounts().Len() != b.Positive().BucketCounts().Len() ||
-		a.Negative().BucketCounts().Len() != b.Negative().BucketCounts().Len() {
-		// Post reset, the reference histogram will have no buckets.
-		return
-	}
-	a.Positive().BucketCounts().FromRaw(subtractExponentialBuckets(a.Positive(), b.Positive()))
-	a.Negative().BucketCounts().FromRaw(subtractExponentialBuckets(a.Negative(), b.Negative()))
+func subtractExponentialHistogramDataPoint(a pmetric.ExponentialHistogramDataPoint, b *datapointstorage.ExponentialHistogramPoint) {
+	a.SetStartTimestamp(b.StartTimestamp)
+	a.SetCount(a.Count() - b.Count)
+	a.SetSum(a.Sum() - b.Sum)
+	a.SetZeroCount(a.ZeroCount() - b.ZeroCount)
+	a.Positive().BucketCounts().FromRaw(subtractExponentialBuckets(a.Positive(), b.Positive))
+	a.Negative().BucketCounts().FromRaw(subtractExponentialBuckets(a.Negative(), b.Negative))
 }
 
 // subtractExponentialBuckets subtracts b from a.
-func subtractExponentialBuckets(a, b pmetric.ExponentialHistogramDataPointBuckets) []uint64 {
+func subtractExponentialBuckets(a pmetric.ExponentialHistogramDataPointBuckets, b datapointstorage.ExponentialBuckets) []uint64 {
 	newBuckets := make([]uint64, a.BucketCounts().Len())
-	offsetDiff := int(a.Offset() - b.Offset())
+	offsetDiff := int(a.Offset() - b.Offset)
 	for i := 0; i < a.BucketCounts().Len(); i++ {
 		bOffset := i + offsetDiff
 		// if there is no corresponding bucket for the starting BucketCounts, don't normalize
-		if bOffset < 0 || bOffset >= b.BucketCounts().Len() {
+		if bOffset < 0 || bOffset >= len(b.BucketCounts) {
 			newBuckets[i] = a.BucketCounts().At(i)
 		} else {
-			newBuckets[i] = a.BucketCounts().At(i) - b.BucketCounts().At(bOffset)
+			newBuckets[i] = a.BucketCounts().At(i) - b.BucketCounts[bOffset]
 		}
 	}
 	return newBuckets
 }
-
-// minimalHistogramCopyTo is equivalent to a.CopyTo(b) without copying attributes or exemplars
-func minimalHistogramCopyTo(a, b pmetric.HistogramDataPoint) {
-	// Copy attributes and exemplars to temporary structures so they are not copied to b.
-	tmpAttrs := pcommon.NewMap()
-	tmpExemplars := pmetric.NewExemplarSlice()
-	a.Attributes().MoveTo(tmpAttrs)
-	a.Exemplars().MoveAndAppendTo(tmpExemplars)
-	a.CopyTo(b)
-	// Restore attributes and exemplars.
-	tmpAttrs.MoveTo(a.Attributes())
-	tmpExemplars.MoveAndAppendTo(a.Exemplars())
-}
-
-// minimalExponentialHistogramCopyTo is equivalent to a.CopyTo(b) without copying attributes or exemplars
-func minimalExponentialHistogramCopyTo(a, b pmetric.ExponentialHistogramDataPoint) {
-	// Copy attributes and exemplars to temporary structures so they are not copied to b.
-	tmpAttrs := pcommon.NewMap()
-	tmpExemplars := pmetric.NewExemplarSlice()
-	a.Attributes().MoveTo(tmpAttrs)
-	a.Exemplars().MoveAndAppendTo(tmpExemplars)
-	a.CopyTo(b)
-	// Restore attributes and exemplars.
-	tmpAttrs.MoveTo(a.Attributes())
-	tmpExemplars.MoveAndAppendTo(a.Exemplars())
-}
-
-// minimalSumCopyTo is equivalent to a.CopyTo(b) without copying attributes or exemplars
-func minimalSumCopyTo(a, b pmetric.NumberDataPoint) {
-	// Copy attributes and exemplars to temporary structures so they are not copied to b.
-	tmpAttrs := pcommon.NewMap()
-	tmpExemplars := pmetric.NewExemplarSlice()
-	a.Attributes().MoveTo(tmpAttrs)
-	a.Exemplars().MoveAndAppendTo(tmpExemplars)
-	a.CopyTo(b)
-	// Restore attributes and exemplars.
-	tmpAttrs.MoveTo(a.Attributes())
-	tmpExemplars.MoveAndAppendTo(a.Exemplars())
-}
-
-// minimalSummaryCopyTo is equivalent to a.CopyTo(b) without copying attributes or exemplars
-func minimalSummaryCopyTo(a, b pmetric.SummaryDataPoint) {
-	// Copy attributes to a temporary map so they are not copied to b.
-	tmpAttrs := pcommon.NewMap()
-	a.Attributes().MoveTo(tmpAttrs)
-	a.CopyTo(b)
-	// Restore attributes.
-	tmpAttrs.MoveTo(a.Attributes())
-}
@@ -10,8 +10,10 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/testhelper"
 )
 
@@ -101,6 +103,139 @@ func TestSum(t *testing.T) {
 	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
 }
 
+func TestSumOutOfOrder(t *testing.T) {
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Sum: round 1 - points of a batch are sorted, the earliest one is the reference",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t3, 66), testhelper.DoublePoint(k1v1k2v2, t2, t2, 44))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t3, 22))),
+		},
+		{
+			Description: "Sum: round 2 - late point with a value less than the previous one is not a reset",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t2, 50))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t2, 6))),
+		},
+		{
+			Description: "Sum: round 3 - late point preceding the reference is dropped",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 40))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1)),
+		},
+		{
+			Description: "Sum: round 4 - instance adjusted based on round 1",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t4, t4, 60))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t4, 60))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
+}
+
+func TestSumMaxTrackedSeries(t *testing.T) {
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Sum: round 1 - initial instance, start time is established",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 44))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1)),
+		},
+		{
+			Description: "Sum: round 2 - other instance exceeds the maximum number of series",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum2, testhelper.DoublePoint(k1v1k2v2, t2, t2, 10))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum2)),
+		},
+		{
+			Description: "Sum: round 3 - evicted instance, start time is established again",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t3, 66))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1)),
+		},
+		{
+			Description: "Sum: round 4 - instance adjusted based on round 3",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t4, t4, 70))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t4, 4))),
+		},
+	}
+	a := NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute, WithCacheOptions(datapointstorage.WithMaxSeries(1)))
+	testhelper.RunScript(t, a, script)
+	assert.Equal(t, int64(2), a.referenceCache.Evictions())
+}
+
+func TestSumStale(t *testing.T) {
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Sum: round 1 - initial instance, start time is established",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 44))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1)),
+		},
+		{
+			Description: "Sum: round 2 - instance went stale, marker reported unchanged",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePointNoValue(k1v1k2v2, tUnknown, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePointNoValue(k1v1k2v2, tUnknown, t2))),
+		},
+		{
+			Description: "Sum: round 3 - instance returned, start time is established again rather than reset",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t3, 10))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1)),
+		},
+		{
+			Description: "Sum: round 4 - instance adjusted based on round 3",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t4, t4, 20))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t4, 10))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
+}
+
+func TestSumPreviousValueEvicted(t *testing.T) {
+	a := NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute)
+	testhelper.RunScript(t, a, []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Sum: round 1 - initial instance, start time is established",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 44))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1)),
+		},
+	})
+
+	// Drop the previous value only, as an eviction of the previous value cache alone would.
+	previousValueTsm, _ := a.previousValueCache.Get(pdatautil.MapHash(pcommon.NewMap()))
+	clear(previousValueTsm.TsiMap)
+
+	testhelper.RunScript(t, a, []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Sum: round 2 - instance without previous value, start time is established again",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t2, 66))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1)),
+		},
+		{
+			Description: "Sum: round 3 - instance adjusted based on round 2",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t3, 70))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t3, 4))),
+		},
+	})
+}
+
+func TestDeltaSum(t *testing.T) {
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Delta Sum: round 1 - passed through untouched",
+			Metrics:     testhelper.Metrics(testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 44)))),
+			Adjusted:    testhelper.Metrics(testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 44)))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
+
+	script = []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Delta Sum: round 1 - converted, first point kept",
+			Metrics:     testhelper.Metrics(testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 44)))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 44))),
+		},
+		{
+			Description: "Delta Sum: round 2 - converted based on round 1",
+			Metrics:     testhelper.Metrics(testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t3, 6)))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t3, 50))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute, WithDeltaToCumulative()), script)
+}
+
 func TestSumNoStartTimestamp(t *testing.T) {
 	script := []*testhelper.MetricsAdjusterTest{
 		{
@@ -198,9 +333,9 @@ func TestSummaryFlagNoRecordedValue(t *testing.T) {
 			Adjusted:    testhelper.Metrics(testhelper.SummaryMetric(summary1)),
 		},
 		{
-			Description: "Summary Flag NoRecordedValue: round 2 - instance adjusted based on round 1",
+			Description: "Summary Flag NoRecordedValue: round 2 - instance went stale, marker reported unchanged",
 			Metrics:     testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, t2, t2))),
-			Adjusted:    testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, t1, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, t2, t2))),
 		},
 	}
 
@@ -347,9 +482,9 @@ func TestHistogramFlagNoRecordedValue(t *testing.T) {
 			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1)),
 		},
 		{
-			Description: "Histogram: round 2 - instance adjusted based on round 1",
+			Description: "Histogram: round 2 - instance went stale, marker reported unchanged",
 			Metrics:     testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
-			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, t1, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
 		},
 	}
 
@@ -361,12 +496,12 @@ func TestHistogramFlagNoRecordedValueFirstObservation(t *testing.T) {
 		{
 			Description: "Histogram: round 1 - initial instance, start time is unknown",
 			Metrics:     testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, tUnknown, t1))),
-			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1)),
+			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, tUnknown, t1))),
 		},
 		{
 			Description: "Histogram: round 2 - instance unchanged",
 			Metrics:     testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
-			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, t1, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
 		},
 	}
 
@@ -400,6 +535,25 @@ func TestExponentialHistogram(t *testing.T) {
 	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
 }
 
+func TestExponentialHistogramDownscale(t *testing.T) {
+	withSum := func(dp pmetric.ExponentialHistogramDataPoint, sum float64) pmetric.ExponentialHistogramDataPoint {
+		dp.SetSum(sum)
+		return dp
+	}
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Exponential Histogram: round 1 - initial instance, start time is established",
+			Metrics:     testhelper.Metrics(testhelper.ExponentialHistogramMetric(exponentialHistogram1, testhelper.ExponentialHistogramPoint(k1v1k2v2, t1, t1, 3, 1, 0, []uint64{}, -2, []uint64{4, 2, 3, 7}))),
+			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(exponentialHistogram1)),
+		}, {
+			Description: "Exponential Histogram: round 2 - instance downscaled, adjusted based on round 1 re-aggregated to the lower scale",
+			Metrics:     testhelper.Metrics(testhelper.ExponentialHistogramMetric(exponentialHistogram1, withSum(testhelper.ExponentialHistogramPoint(k1v1k2v2, t2, t2, 2, 1, 0, []uint64{}, -1, []uint64{7, 12}), 35))),
+			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(exponentialHistogram1, withSum(testhelper.ExponentialHistogramPoint(k1v1k2v2, t1, t2, 2, 0, 0, []uint64{}, -1, []uint64{1, 2}), 6))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
+}
+
 func TestExponentialHistogramNoStartTimestamps(t *testing.T) {
 	script := []*testhelper.MetricsAdjusterTest{
 		{
@@ -431,9 +585,9 @@ func TestExponentialHistogramFlagNoRecordedValue(t *testing.T) {
 			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1)),
 		},
 		{
-			Description: "Histogram: round 2 - instance adjusted based on round 1",
+			Description: "Histogram: round 2 - instance went stale, marker reported unchanged",
 			Metrics:     testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
-			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, t1, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
 		},
 	}
 
@@ -445,12 +599,12 @@ func TestExponentialHistogramFlagNoRecordedValueFirstObservation(t *testing.T) {
 		{
 			Description: "Histogram: round 1 - initial instance, start time is unknown",
 			Metrics:     testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, tUnknown, t1))),
-			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1)),
+			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, tUnknown, t1))),
 		},
 		{
 			Description: "Histogram: round 2 - instance unchanged",
 			Metrics:     testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
-			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, t1, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
 		},
 	}
 
@@ -462,12 +616,12 @@ func TestSummaryFlagNoRecordedValueFirstObservation(t *testing.T) {
 		{
 			Description: "Summary: round 1 - initial instance, start time is unknown",
 			Metrics:     testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, tUnknown, t1))),
-			Adjusted:    testhelper.Metrics(testhelper.SummaryMetric(summary1)),
+			Adjusted:    testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, tUnknown, t1))),
 		},
 		{
 			Description: "Summary: round 2 - instance unchanged",
 			Metrics:     testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, tUnknown, t2))),
-			Adjusted:    testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, t1, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, tUnknown, t2))),
 		},
 	}
 
@@ -496,12 +650,12 @@ func TestSumFlagNoRecordedValueFirstObservation(t *testing.T) {
 		{
 			Description: "Sum: round 1 - initial instance, start time is unknown",
 			Metrics:     testhelper.Metrics(testhelper.SumMetric("sum1", testhelper.DoublePointNoValue(k1v1k2v2, tUnknown, t1))),
-			Adjusted:    testhelper.Metrics(testhelper.SumMetric("sum1")),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric("sum1", testhelper.DoublePointNoValue(k1v1k2v2, tUnknown, t1))),
 		},
 		{
 			Description: "Sum: round 2 - instance unchanged",
 			Metrics:     testhelper.Metrics(testhelper.SumMetric("sum1", testhelper.DoublePointNoValue(k1v1k2v2, tUnknown, t2))),
-			Adjusted:    testhelper.Metrics(testhelper.SumMetric("sum1", testhelper.DoublePointNoValue(k1v1k2v2, t1, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric("sum1", testhelper.DoublePointNoValue(k1v1k2v2, tUnknown, t2))),
 		},
 	}
 
@@ -261,6 +261,19 @@ func SumMetric(name string, points ...pmetric.NumberDataPoint) pmetric.Metric {
 	return metric
 }
 
+// Delta sets the aggregation temporality of the sum, histogram or exponential histogram metric to delta.
+func Delta(metric pmetric.Metric) pmetric.Metric {
+	switch metric.Type() {
+	case pmetric.MetricTypeSum:
+		metric.Sum().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	case pmetric.MetricTypeHistogram:
+		metric.Histogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	case pmetric.MetricTypeExponentialHistogram:
+		metric.ExponentialHistogram().SetAggregationTemporality(pmetric.AggregationTemporalityDelta)
+	}
+	return metric
+}
+
 func SummaryPointRaw(attributes []*KV, startTimestamp, timestamp pcommon.Timestamp) pmetric.SummaryDataPoint {
 	sdp := pmetric.NewSummaryDataPoint()
 	sdp.SetStartTimestamp(startTimestamp)
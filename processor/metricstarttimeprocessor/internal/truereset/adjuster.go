@@ -5,15 +5,18 @@ package truereset // import "github.com/open-telemetry/opentelemetry-collector-c
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
 
-	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/datapointstorage"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/metadata"
 )
 
 // Type is the value users can use to configure the true reset point adjuster.
@@ -27,57 +30,155 @@ import (
 // start time of the reset point as point timestamp - 1ms.
 const Type = "true_reset_point"
 
+// ZeroPointType is the value users can use to configure the true reset point adjuster emitting zero
+// points. On top of what the true reset point adjuster does, it emits a point with zero values at
+// the start time of each reset point, before it, for backends deriving rates from adjacent points.
+const ZeroPointType = "true_reset_point_with_zeros"
+
 // Adjuster takes a map from a metric instance to the initial point in the metrics instance
 // and provides AdjustMetric, which takes a sequence of metrics and adjust their start times based on
 // the initial points.
 type Adjuster struct {
 	startTimeCache *datapointstorage.Cache
 	set            component.TelemetrySettings
+	include        func(pmetric.Metric) bool
+	cacheOpts      []datapointstorage.CacheOption
+	// restartAttributes are the resource attributes whose change is a reset of all the
+	// timeseries of the resource.
+	restartAttributes []string
+	telemetry         *metadata.TelemetryBuilder
+	// deltaToCumulative converts the delta sums and histograms to cumulative ones instead of
+	// passing them through.
+	deltaToCumulative bool
+	// zeroPoints emits a point with zero values before each reset point.
+	zeroPoints bool
+	// strategy is the strategy reported in the telemetry.
+	strategy string
+	// annotateResets marks the reset points with datapointstorage.ResetDetectedAttribute.
+	annotateResets bool
+}
+
+// Option configures an Adjuster.
+type Option func(*Adjuster)
+
+// WithMetricFilter restricts the adjustment to the metrics for which include returns true,
+// the other metrics are left untouched.
+func WithMetricFilter(include func(pmetric.Metric) bool) Option {
+	return func(a *Adjuster) {
+		a.include = include
+	}
+}
+
+// WithCacheOptions configures the cache of the previous points of the timeseries.
+func WithCacheOptions(opts ...datapointstorage.CacheOption) Option {
+	return func(a *Adjuster) {
+		a.cacheOpts = append(a.cacheOpts, opts...)
+	}
+}
+
+// WithRestartAttributes treats a change of the values of the given resource attributes, e.g.
+// process.pid, as a reset of all the timeseries of the resource, whatever their values. The
+// attributes are left out of the identity of the resource.
+func WithRestartAttributes(attributes []string) Option {
+	return func(a *Adjuster) {
+		a.restartAttributes = attributes
+	}
+}
+
+// WithTelemetryBuilder records the adjusted datapoints and the detected resets with telemetry.
+func WithTelemetryBuilder(telemetry *metadata.TelemetryBuilder) Option {
+	return func(a *Adjuster) {
+		a.telemetry = telemetry
+	}
+}
+
+// WithDeltaToCumulative converts the delta sums and histograms to cumulative ones, accumulating
+// them in the cache of the adjuster, instead of passing them through untouched.
+func WithDeltaToCumulative() Option {
+	return func(a *Adjuster) {
+		a.deltaToCumulative = true
+	}
+}
+
+// WithResetZeroPoints emits a point with zero values at the start time of each reset point, as
+// the ZeroPointType strategy.
+func WithResetZeroPoints() Option {
+	return func(a *Adjuster) {
+		a.zeroPoints = true
+		a.strategy = ZeroPointType
+	}
+}
+
+// WithResetAnnotation marks the points detected as resets with the
+// datapointstorage.ResetDetectedAttribute attribute. The attribute is not kept in the cache.
+func WithResetAnnotation() Option {
+	return func(a *Adjuster) {
+		a.annotateResets = true
+	}
 }
 
 // NewAdjuster returns a new Adjuster which adjust metrics' start times based on the initial received points.
-func NewAdjuster(set component.TelemetrySettings, gcInterval time.Duration) *Adjuster {
-	return &Adjuster{
-		startTimeCache: datapointstorage.NewCache(gcInterval),
-		set:            set,
+func NewAdjuster(set component.TelemetrySettings, gcInterval time.Duration, opts ...Option) *Adjuster {
+	a := &Adjuster{
+		set:      set,
+		strategy: Type,
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	a.startTimeCache = datapointstorage.NewCache(gcInterval, a.cacheOpts...)
+	return a
 }
 
 // AdjustMetrics takes a sequence of metrics and adjust their start times based on the initial and
 // previous points in the timeseriesMap.
-func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pmetric.Metrics, error) {
+func (a *Adjuster) AdjustMetrics(ctx context.Context, metrics pmetric.Metrics) (pmetric.Metrics, error) {
 	for i := 0; i < metrics.ResourceMetrics().Len(); i++ {
 		rm := metrics.ResourceMetrics().At(i)
-		attrHash := pdatautil.MapHash(rm.Resource().Attributes())
+		attrHash, restartHash := datapointstorage.ResourceHashes(rm.Resource(), a.restartAttributes)
 		tsm, _ := a.startTimeCache.Get(attrHash)
 
 		// The lock on the relevant timeseriesMap is held throughout the adjustment process to ensure that
 		// nothing else can modify the data used for adjustment.
 		tsm.Lock()
+		tsm.ObserveRestartHash(restartHash)
 		for j := 0; j < rm.ScopeMetrics().Len(); j++ {
 			ilm := rm.ScopeMetrics().At(j)
 			for k := 0; k < ilm.Metrics().Len(); k++ {
 				metric := ilm.Metrics().At(k)
+				if a.include != nil && !a.include(metric) {
+					continue
+				}
+				var adjusted, resets int
 				switch dataType := metric.Type(); dataType {
 				case pmetric.MetricTypeGauge:
 					// gauges don't need to be adjusted so no additional processing is necessary
 
 				case pmetric.MetricTypeHistogram:
-					a.adjustMetricHistogram(tsm, metric)
+					if a.deltaToCumulative && metric.Histogram().AggregationTemporality() == pmetric.AggregationTemporalityDelta {
+						adjusted = datapointstorage.AccumulateHistogram(tsm, metric)
+						break
+					}
+					adjusted, resets = a.adjustMetricHistogram(tsm, metric)
 
 				case pmetric.MetricTypeSummary:
-					a.adjustMetricSummary(tsm, metric)
+					adjusted, resets = a.adjustMetricSummary(tsm, metric)
 
 				case pmetric.MetricTypeSum:
-					a.adjustMetricSum(tsm, metric)
+					if a.deltaToCumulative && metric.Sum().AggregationTemporality() == pmetric.AggregationTemporalityDelta {
+						adjusted = datapointstorage.AccumulateSum(tsm, metric)
+						break
+					}
+					adjusted, resets = a.adjustMetricSum(tsm, metric)
 
 				case pmetric.MetricTypeExponentialHistogram:
-					a.adjustMetricExponentialHistogram(tsm, metric)
+					adjusted, resets = a.adjustMetricExponentialHistogram(tsm, metric)
 
 				default:
 					// this shouldn't happen
 					a.set.Logger.Info("Adjust - skipping unexpected point", zap.String("type", dataType.String()))
 				}
+				a.record(ctx, metric.Type(), adjusted, resets)
 			}
 		}
 		tsm.Unlock()
@@ -85,147 +186,257 @@ func (a *Adjuster) AdjustMetrics(_ context.Context, metrics pmetric.Metrics) (pm
 	return metrics, nil
 }
 
-func (*Adjuster) adjustMetricHistogram(tsm *datapointstorage.TimeseriesMap, current pmetric.Metric) {
+// TrackedSeries returns the number of series the adjuster keeps the previous point of.
+func (a *Adjuster) TrackedSeries() int64 {
+	return a.startTimeCache.Series()
+}
+
+// EvictedSeries returns the number of series removed so far by the gc and for exceeding the
+// maximum number of series.
+func (a *Adjuster) EvictedSeries() (gc, maxSeries int64) {
+	return a.startTimeCache.GCEvictions(), a.startTimeCache.Evictions()
+}
+
+// record records the datapoints adjusted and the resets detected in a metric of metricType.
+func (a *Adjuster) record(ctx context.Context, metricType pmetric.MetricType, adjusted, resets int) {
+	if a.telemetry == nil {
+		return
+	}
+	strategy := attribute.String("strategy", a.strategy)
+	if adjusted > 0 {
+		a.telemetry.MetricstarttimeAdjustedDatapoints.Add(ctx, int64(adjusted), metric.WithAttributes(strategy))
+	}
+	if resets > 0 {
+		a.telemetry.MetricstarttimeResets.Add(ctx, int64(resets), metric.WithAttributes(strategy,
+			attribute.String("metric_type", strings.ToLower(metricType.String()))))
+	}
+}
+
+// forgetIfStale forgets the series of a stale marker, so that it is re-anchored as a new series
+// when it returns, and reports whether the point is one; the marker is reported unchanged.
+func forgetIfStale(tsm *datapointstorage.TimeseriesMap, metric pmetric.Metric, flags pmetric.DataPointFlags, attributes pcommon.Map) bool {
+	if !flags.NoRecordedValue() {
+		return false
+	}
+	tsm.Remove(metric, attributes)
+	return true
+}
+
+func (a *Adjuster) adjustMetricHistogram(tsm *datapointstorage.TimeseriesMap, current pmetric.Metric) (adjusted, resets int) {
 	histogram := current.Histogram()
 	if histogram.AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
 		// Only dealing with CumulativeDistributions.
-		return
+		return 0, 0
 	}
 
+	datapointstorage.SortDataPoints(current)
 	currentPoints := histogram.DataPoints()
+	zeros := pmetric.NewHistogramDataPointSlice()
 	for i := 0; i < currentPoints.Len(); i++ {
 		currentDist := currentPoints.At(i)
 
+		if forgetIfStale(tsm, current, currentDist.Flags(), currentDist.Attributes()) {
+			continue
+		}
 		tsi, found := tsm.Get(current, currentDist.Attributes())
 		if !found {
 			// initialize everything.
-			tsi.Histogram = pmetric.NewHistogramDataPoint()
-			currentDist.CopyTo(tsi.Histogram)
+			tsi.Histogram = datapointstorage.NewHistogramPoint(currentDist)
 			continue
 		}
+		adjusted++
 
-		if currentDist.Flags().NoRecordedValue() {
-			// TODO: Investigate why this does not reset.
-			currentDist.SetStartTimestamp(tsi.Histogram.StartTimestamp())
+		if datapointstorage.IsOutOfOrder(currentDist.Timestamp(), tsi.Histogram.Timestamp) {
+			// The point belongs to the stream of the more recent previous point, which is kept as is.
+			currentDist.SetStartTimestamp(tsi.Histogram.StartTimestamp)
 			continue
 		}
 
-		if datapointstorage.IsResetHistogram(currentDist, tsi.Histogram) {
+		if tsi.ConsumeRestart() || datapointstorage.IsResetHistogram(currentDist, tsi.Histogram) {
+			resets++
 			// reset re-initialize everything.
 			resetStartTimeStamp := pcommon.NewTimestampFromTime(currentDist.StartTimestamp().AsTime().Add(-1 * time.Millisecond))
+			if a.zeroPoints {
+				appendZeroHistogram(zeros, currentDist, resetStartTimeStamp)
+			}
 			currentDist.SetStartTimestamp(resetStartTimeStamp)
-			currentDist.CopyTo(tsi.Histogram)
+			tsi.Histogram.CopyFrom(currentDist)
+			if a.annotateResets {
+				currentDist.Attributes().PutBool(datapointstorage.ResetDetectedAttribute, true)
+			}
 			continue
 		}
 
 		// Update only previous values.
-		currentDist.SetStartTimestamp(tsi.Histogram.StartTimestamp())
-		currentDist.CopyTo(tsi.Histogram)
+		currentDist.SetStartTimestamp(tsi.Histogram.StartTimestamp)
+		tsi.Histogram.CopyFrom(currentDist)
 	}
+	if zeros.Len() > 0 {
+		zeros.MoveAndAppendTo(currentPoints)
+		datapointstorage.SortDataPoints(current)
+	}
+	return adjusted, resets
 }
 
-func (*Adjuster) adjustMetricExponentialHistogram(tsm *datapointstorage.TimeseriesMap, current pmetric.Metric) {
+func (a *Adjuster) adjustMetricExponentialHistogram(tsm *datapointstorage.TimeseriesMap, current pmetric.Metric) (adjusted, resets int) {
 	histogram := current.ExponentialHistogram()
 	if histogram.AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
 		// Only dealing with CumulativeDistributions.
-		return
+		return 0, 0
 	}
 
+	datapointstorage.SortDataPoints(current)
 	currentPoints := histogram.DataPoints()
+	zeros := pmetric.NewExponentialHistogramDataPointSlice()
 	for i := 0; i < currentPoints.Len(); i++ {
 		currentDist := currentPoints.At(i)
 
+		if forgetIfStale(tsm, current, currentDist.Flags(), currentDist.Attributes()) {
+			continue
+		}
 		tsi, found := tsm.Get(current, currentDist.Attributes())
 		if !found {
 			// initialize everything.
-			tsi.ExponentialHistogram = pmetric.NewExponentialHistogramDataPoint()
-			currentDist.CopyTo(tsi.ExponentialHistogram)
+			tsi.ExponentialHistogram = datapointstorage.NewExponentialHistogramPoint(currentDist)
 			continue
 		}
+		adjusted++
 
-		if currentDist.Flags().NoRecordedValue() {
-			// TODO: Investigate why this does not reset.
-			currentDist.SetStartTimestamp(tsi.ExponentialHistogram.StartTimestamp())
+		if datapointstorage.IsOutOfOrder(currentDist.Timestamp(), tsi.ExponentialHistogram.Timestamp) {
+			// The point belongs to the stream of the more recent previous point, which is kept as is.
+			currentDist.SetStartTimestamp(tsi.ExponentialHistogram.StartTimestamp)
 			continue
 		}
 
-		if datapointstorage.IsResetExponentialHistogram(currentDist, tsi.ExponentialHistogram) {
+		if tsi.ConsumeRestart() || datapointstorage.IsResetExponentialHistogram(currentDist, tsi.ExponentialHistogram) {
+			resets++
 			// reset re-initialize everything.
 			resetStartTimeStamp := pcommon.NewTimestampFromTime(currentDist.StartTimestamp().AsTime().Add(-1 * time.Millisecond))
+			if a.zeroPoints {
+				appendZeroExponentialHistogram(zeros, currentDist, resetStartTimeStamp)
+			}
 			currentDist.SetStartTimestamp(resetStartTimeStamp)
-			currentDist.CopyTo(tsi.ExponentialHistogram)
+			tsi.ExponentialHistogram.CopyFrom(currentDist)
+			if a.annotateResets {
+				currentDist.Attributes().PutBool(datapointstorage.ResetDetectedAttribute, true)
+			}
 			continue
 		}
 
 		// Update only previous values.
-		currentDist.SetStartTimestamp(tsi.ExponentialHistogram.StartTimestamp())
-		currentDist.CopyTo(tsi.ExponentialHistogram)
+		currentDist.SetStartTimestamp(tsi.ExponentialHistogram.StartTimestamp)
+		tsi.ExponentialHistogram.CopyFrom(currentDist)
 	}
+	if zeros.Len() > 0 {
+		zeros.MoveAndAppendTo(currentPoints)
+		datapointstorage.SortDataPoints(current)
+	}
+	return adjusted, resets
 }
 
-func (*Adjuster) adjustMetricSum(tsm *datapointstorage.TimeseriesMap, current pmetric.Metric) {
-	currentPoints := current.Sum().DataPoints()
+func (a *Adjuster) adjustMetricSum(tsm *datapointstorage.TimeseriesMap, current pmetric.Metric) (adjusted, resets int) {
+	sum := current.Sum()
+	if sum.AggregationTemporality() != pmetric.AggregationTemporalityCumulative {
+		// Delta sums carry their own start times, they are passed through untouched.
+		return 0, 0
+	}
+
+	datapointstorage.SortDataPoints(current)
+	currentPoints := sum.DataPoints()
+	zeros := pmetric.NewNumberDataPointSlice()
 	for i := 0; i < currentPoints.Len(); i++ {
 		currentSum := currentPoints.At(i)
 
+		if forgetIfStale(tsm, current, currentSum.Flags(), currentSum.Attributes()) {
+			continue
+		}
 		tsi, found := tsm.Get(current, currentSum.Attributes())
 		if !found {
 			// initialize everything.
-			tsi.Number = pmetric.NewNumberDataPoint()
-			currentSum.CopyTo(tsi.Number)
+			tsi.Number = datapointstorage.NewNumberPoint(currentSum)
 			continue
 		}
+		adjusted++
 
-		if currentSum.Flags().NoRecordedValue() {
-			// TODO: Investigate why this does not reset.
-			currentSum.SetStartTimestamp(tsi.Number.StartTimestamp())
+		if datapointstorage.IsOutOfOrder(currentSum.Timestamp(), tsi.Number.Timestamp) {
+			// The point belongs to the stream of the more recent previous point, which is kept as is.
+			currentSum.SetStartTimestamp(tsi.Number.StartTimestamp)
 			continue
 		}
 
-		if datapointstorage.IsResetSum(currentSum, tsi.Number) {
+		if tsi.ConsumeRestart() || datapointstorage.IsResetSum(currentSum, tsi.Number) {
+			resets++
 			// reset re-initialize everything.
 			resetStartTimeStamp := pcommon.NewTimestampFromTime(currentSum.StartTimestamp().AsTime().Add(-1 * time.Millisecond))
+			if a.zeroPoints {
+				appendZeroSum(zeros, currentSum, resetStartTimeStamp)
+			}
 			currentSum.SetStartTimestamp(resetStartTimeStamp)
-			currentSum.CopyTo(tsi.Number)
+			tsi.Number.CopyFrom(currentSum)
+			if a.annotateResets {
+				currentSum.Attributes().PutBool(datapointstorage.ResetDetectedAttribute, true)
+			}
 			continue
 		}
 
 		// Update only previous values.
-		currentSum.SetStartTimestamp(tsi.Number.StartTimestamp())
-		currentSum.CopyTo(tsi.Number)
+		currentSum.SetStartTimestamp(tsi.Number.StartTimestamp)
+		tsi.Number.CopyFrom(currentSum)
+	}
+	if zeros.Len() > 0 {
+		zeros.MoveAndAppendTo(currentPoints)
+		datapointstorage.SortDataPoints(current)
 	}
+	return adjusted, resets
 }
 
-func (*Adjuster) adjustMetricSummary(tsm *datapointstorage.TimeseriesMap, current pmetric.Metric) {
+func (a *Adjuster) adjustMetricSummary(tsm *datapointstorage.TimeseriesMap, current pmetric.Metric) (adjusted, resets int) {
+	datapointstorage.SortDataPoints(current)
 	currentPoints := current.Summary().DataPoints()
+	zeros := pmetric.NewSummaryDataPointSlice()
 
 	for i := 0; i < currentPoints.Len(); i++ {
 		currentSummary := currentPoints.At(i)
 
+		if forgetIfStale(tsm, current, currentSummary.Flags(), currentSummary.Attributes()) {
+			continue
+		}
 		tsi, found := tsm.Get(current, currentSummary.Attributes())
 		if !found {
 			// initialize everything.
-			tsi.Summary = pmetric.NewSummaryDataPoint()
-			currentSummary.CopyTo(tsi.Summary)
+			tsi.Summary = datapointstorage.NewSummaryPoint(currentSummary)
 			continue
 		}
+		adjusted++
 
-		if currentSummary.Flags().NoRecordedValue() {
-			// TODO: Investigate why this does not reset.
-			currentSummary.SetStartTimestamp(tsi.Summary.StartTimestamp())
+		if datapointstorage.IsOutOfOrder(currentSummary.Timestamp(), tsi.Summary.Timestamp) {
+			// The point belongs to the stream of the more recent previous point, which is kept as is.
+			currentSummary.SetStartTimestamp(tsi.Summary.StartTimestamp)
 			continue
 		}
 
-		if datapointstorage.IsResetSummary(currentSummary, tsi.Summary) {
+		if tsi.ConsumeRestart() || datapointstorage.IsResetSummary(currentSummary, tsi.Summary) {
+			resets++
 			// reset re-initialize everything.
 			resetStartTimeStamp := pcommon.NewTimestampFromTime(currentSummary.StartTimestamp().AsTime().Add(-1 * time.Millisecond))
+			if a.zeroPoints {
+				appendZeroSummary(zeros, currentSummary, resetStartTimeStamp)
+			}
 			currentSummary.SetStartTimestamp(resetStartTimeStamp)
-			currentSummary.CopyTo(tsi.Summary)
+			tsi.Summary.CopyFrom(currentSummary)
+			if a.annotateResets {
+				currentSummary.Attributes().PutBool(datapointstorage.ResetDetectedAttribute, true)
+			}
 			continue
 		}
 
 		// Update only previous values.
-		currentSummary.SetStartTimestamp(tsi.Summary.StartTimestamp())
-		currentSummary.CopyTo(tsi.Summary)
+		currentSummary.SetStartTimestamp(tsi.Summary.StartTimestamp)
+		tsi.Summary.CopyFrom(currentSummary)
+	}
+	if zeros.Len() > 0 {
+		zeros.MoveAndAppendTo(currentPoints)
+		datapointstorage.SortDataPoints(current)
 	}
+	return adjusted, resets
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/pdatautil"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/testhelper"
@@ -101,6 +102,84 @@ func TestSum(t *testing.T) {
 	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
 }
 
+func TestSumZeroPoints(t *testing.T) {
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Sum: round 1 - initial instance, start time is established",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 44))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 44))),
+		},
+		{
+			Description: "Sum: round 2 - instance adjusted based on round 1",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t2, 66))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 66))),
+		},
+		{
+			Description: "Sum: round 3 - instance reset, zero point emitted at the start time of the reset point",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t3, 55))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t2, 0), testhelper.DoublePoint(k1v1k2v2, t2, t3, 55))),
+		},
+		{
+			Description: "Sum: round 4 - instance adjusted based on round 3",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t4, t4, 72))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t4, 72))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute, WithResetZeroPoints()), script)
+}
+
+func TestSumStale(t *testing.T) {
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Sum: round 1 - initial instance, start time is established",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 44))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 44))),
+		},
+		{
+			Description: "Sum: round 2 - instance went stale, marker reported unchanged",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePointNoValue(k1v1k2v2, tUnknown, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePointNoValue(k1v1k2v2, tUnknown, t2))),
+		},
+		{
+			Description: "Sum: round 3 - instance returned, start time is established again rather than reset",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t3, 10))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t3, 10))),
+		},
+		{
+			Description: "Sum: round 4 - instance adjusted based on round 3",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t4, t4, 20))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t4, 20))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
+}
+
+func TestSumOutOfOrder(t *testing.T) {
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Sum: round 1 - points of a batch are sorted before adjusting them",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t2, 66), testhelper.DoublePoint(k1v1k2v2, t1, t1, 44))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 44), testhelper.DoublePoint(k1v1k2v2, t1, t2, 66))),
+		},
+		{
+			Description: "Sum: round 2 - instance adjusted based on round 1",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t4, t4, 80))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t4, 80))),
+		},
+		{
+			Description: "Sum: round 3 - late point with a value less than the previous one is not a reset",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t3, 70))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t3, 70))),
+		},
+		{
+			Description: "Sum: round 4 - instance reset based on round 2, the late point is not kept as the previous one",
+			Metrics:     testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t5, t5, 75))),
+			Adjusted:    testhelper.Metrics(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t4, t5, 75))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
+}
+
 func TestSumWithDifferentResources(t *testing.T) {
 	script := []*testhelper.MetricsAdjusterTest{
 		{
@@ -132,6 +211,96 @@ func TestSumWithDifferentResources(t *testing.T) {
 	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
 }
 
+func TestSumRestartAttributes(t *testing.T) {
+	withPID := func(pid string, metric pmetric.Metric) pmetric.Metrics {
+		rm := testhelper.ResourceMetrics("job1", "instance1", metric)
+		rm.Resource().Attributes().PutStr("process.pid", pid)
+		return testhelper.MetricsFromResourceMetrics(rm)
+	}
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Sum: round 1 - initial instance, start time is established",
+			Metrics:     withPID("1", testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 44))),
+			Adjusted:    withPID("1", testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 44))),
+		},
+		{
+			Description: "Sum: round 2 - instance adjusted based on round 1",
+			Metrics:     withPID("1", testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t2, 66))),
+			Adjusted:    withPID("1", testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 66))),
+		},
+		{
+			Description: "Sum: round 3 - restart attribute changed, instance reset even though its value increased",
+			Metrics:     withPID("2", testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t3, 70))),
+			Adjusted:    withPID("2", testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t3, 70))),
+		},
+		{
+			Description: "Sum: round 4 - instance adjusted based on round 3",
+			Metrics:     withPID("2", testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t4, t4, 80))),
+			Adjusted:    withPID("2", testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t4, 80))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute, WithRestartAttributes([]string{"process.pid"})), script)
+}
+
+func TestDeltaSumPassthrough(t *testing.T) {
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Delta Sum: round 1 - passed through untouched",
+			Metrics:     testhelper.Metrics(testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 44)))),
+			Adjusted:    testhelper.Metrics(testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 44)))),
+		},
+		{
+			Description: "Delta Sum: round 2 - lower value is not a reset, passed through untouched",
+			Metrics:     testhelper.Metrics(testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t3, 10)))),
+			Adjusted:    testhelper.Metrics(testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t3, 10)))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
+}
+
+func TestDeltaToCumulative(t *testing.T) {
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Delta: round 1 - first points start the accumulation",
+			Metrics: testhelper.Metrics(
+				testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 44))),
+				testhelper.Delta(testhelper.HistogramMetric(histogram1, testhelper.HistogramPoint(k1v1k2v2, t1, t2, bounds0, []uint64{4, 2, 3, 7}))),
+				testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 10)),
+			),
+			Adjusted: testhelper.Metrics(
+				testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 44)),
+				testhelper.HistogramMetric(histogram1, testhelper.HistogramPoint(k1v1k2v2, t1, t2, bounds0, []uint64{4, 2, 3, 7})),
+				testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t1, 10)),
+			),
+		},
+		{
+			Description: "Delta: round 2 - points added to the accumulation, cumulative sum of the same name kept apart",
+			Metrics: testhelper.Metrics(
+				testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t2, t3, 6))),
+				testhelper.Delta(testhelper.HistogramMetric(histogram1, testhelper.HistogramPoint(k1v1k2v2, t2, t3, bounds0, []uint64{1, 1, 0, 2}))),
+				testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t3, t3, 12)),
+			),
+			Adjusted: testhelper.Metrics(
+				testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t3, 50)),
+				testhelper.HistogramMetric(histogram1, testhelper.HistogramPoint(k1v1k2v2, t1, t3, bounds0, []uint64{5, 3, 3, 9})),
+				testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t3, 12)),
+			),
+		},
+		{
+			Description: "Delta: round 3 - points older than the accumulation are dropped",
+			Metrics: testhelper.Metrics(
+				testhelper.Delta(testhelper.SumMetric(sum1, testhelper.DoublePoint(k1v1k2v2, t1, t2, 44))),
+				testhelper.Delta(testhelper.HistogramMetric(histogram1, testhelper.HistogramPoint(k1v1k2v2, t1, t2, bounds0, []uint64{4, 2, 3, 7}))),
+			),
+			Adjusted: testhelper.Metrics(
+				testhelper.SumMetric(sum1),
+				testhelper.HistogramMetric(histogram1),
+			),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute, WithDeltaToCumulative()), script)
+}
+
 func TestSummaryNoCount(t *testing.T) {
 	script := []*testhelper.MetricsAdjusterTest{
 		{
@@ -167,9 +336,9 @@ func TestSummaryFlagNoRecordedValue(t *testing.T) {
 			Adjusted:    testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPoint(k1v1k2v2, t1, t1, 0, 40, percent0, []float64{1, 5, 8}))),
 		},
 		{
-			Description: "Summary Flag NoRecordedValue: round 2 - instance adjusted based on round 1",
+			Description: "Summary Flag NoRecordedValue: round 2 - instance went stale, marker reported unchanged",
 			Metrics:     testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, t2, t2))),
-			Adjusted:    testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, t1, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.SummaryMetric(summary1, testhelper.SummaryPointNoValue(k1v1k2v2, t2, t2))),
 		},
 	}
 
@@ -242,6 +411,23 @@ func TestHistogram(t *testing.T) {
 	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute), script)
 }
 
+func TestHistogramZeroPoints(t *testing.T) {
+	script := []*testhelper.MetricsAdjusterTest{
+		{
+			Description: "Histogram: round 1 - initial instance, start time is established",
+			Metrics:     testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPoint(k1v1k2v2, t1, t1, bounds0, []uint64{4, 2, 3, 7}))),
+			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPoint(k1v1k2v2, t1, t1, bounds0, []uint64{4, 2, 3, 7}))),
+		}, {
+			Description: "Histogram: round 2 - instance reset, zero point emitted at the start time of the reset point",
+			Metrics:     testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPoint(k1v1k2v2, t3, t3, bounds0, []uint64{1, 0, 1, 2}))),
+			Adjusted: testhelper.Metrics(testhelper.HistogramMetric(histogram1,
+				testhelper.HistogramPoint(k1v1k2v2, t2, t2, bounds0, []uint64{0, 0, 0, 0}),
+				testhelper.HistogramPoint(k1v1k2v2, t2, t3, bounds0, []uint64{1, 0, 1, 2}))),
+		},
+	}
+	testhelper.RunScript(t, NewAdjuster(componenttest.NewNopTelemetrySettings(), time.Minute, WithResetZeroPoints()), script)
+}
+
 func TestHistogramFlagNoRecordedValue(t *testing.T) {
 	script := []*testhelper.MetricsAdjusterTest{
 		{
@@ -250,9 +436,9 @@ func TestHistogramFlagNoRecordedValue(t *testing.T) {
 			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPoint(k1v1k2v2, t1, t1, bounds0, []uint64{7, 4, 2, 12}))),
 		},
 		{
-			Description: "Histogram: round 2 - instance adjusted based on round 1",
+			Description: "Histogram: round 2 - instance went stale, marker reported unchanged",
 			Metrics:     testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
-			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, t1, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.HistogramMetric(histogram1, testhelper.HistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
 		},
 	}
 
@@ -311,9 +497,9 @@ func TestExponentialHistogramFlagNoRecordedValue(t *testing.T) {
 			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPoint(k1v1k2v2, t1, t1, 0, 2, 2, []uint64{7, 4, 2, 12}, 3, []uint64{}))),
 		},
 		{
-			Description: "Histogram: round 2 - instance adjusted based on round 1",
+			Description: "Histogram: round 2 - instance went stale, marker reported unchanged",
 			Metrics:     testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
-			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, t1, t2))),
+			Adjusted:    testhelper.Metrics(testhelper.ExponentialHistogramMetric(histogram1, testhelper.ExponentialHistogramPointNoValue(k1v1k2v2, tUnknown, t2))),
 		},
 	}
 
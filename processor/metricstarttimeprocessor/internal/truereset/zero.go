@@ -0,0 +1,56 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package truereset // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/truereset"
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// The zero points share the series of the reset point they precede, and their start and end
+// timestamps are the start timestamp of the reset point: the series restarts from zero there.
+
+func appendZeroSum(zeros pmetric.NumberDataPointSlice, reset pmetric.NumberDataPoint, ts pcommon.Timestamp) {
+	zero := zeros.AppendEmpty()
+	reset.Attributes().CopyTo(zero.Attributes())
+	zero.SetStartTimestamp(ts)
+	zero.SetTimestamp(ts)
+	switch reset.ValueType() {
+	case pmetric.NumberDataPointValueTypeInt:
+		zero.SetIntValue(0)
+	case pmetric.NumberDataPointValueTypeDouble:
+		zero.SetDoubleValue(0)
+	}
+}
+
+func appendZeroHistogram(zeros pmetric.HistogramDataPointSlice, reset pmetric.HistogramDataPoint, ts pcommon.Timestamp) {
+	zero := zeros.AppendEmpty()
+	reset.Attributes().CopyTo(zero.Attributes())
+	zero.SetStartTimestamp(ts)
+	zero.SetTimestamp(ts)
+	reset.ExplicitBounds().CopyTo(zero.ExplicitBounds())
+	zero.BucketCounts().FromRaw(make([]uint64, reset.BucketCounts().Len()))
+	if reset.HasSum() {
+		zero.SetSum(0)
+	}
+}
+
+func appendZeroExponentialHistogram(zeros pmetric.ExponentialHistogramDataPointSlice, reset pmetric.ExponentialHistogramDataPoint, ts pcommon.Timestamp) {
+	zero := zeros.AppendEmpty()
+	reset.Attributes().CopyTo(zero.Attributes())
+	zero.SetStartTimestamp(ts)
+	zero.SetTimestamp(ts)
+	zero.SetScale(reset.Scale())
+	zero.SetZeroThreshold(reset.ZeroThreshold())
+	if reset.HasSum() {
+		zero.SetSum(0)
+	}
+}
+
+func appendZeroSummary(zeros pmetric.SummaryDataPointSlice, reset pmetric.SummaryDataPoint, ts pcommon.Timestamp) {
+	zero := zeros.AppendEmpty()
+	reset.Attributes().CopyTo(zero.Attributes())
+	zero.SetStartTimestamp(ts)
+	zero.SetTimestamp(ts)
+}
@@ -0,0 +1,101 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metricstarttimeprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor"
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"sync"
+
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+// maxRoutedNames bounds the number of metric names whose strategy is remembered.
+const maxRoutedNames = 10000
+
+// strategyRoute is the strategy used for the metrics whose name matches regex.
+type strategyRoute struct {
+	regex    *regexp.Regexp
+	strategy string
+}
+
+// strategyRouter adjusts each metric with the strategy routed to by its name. Every
+// strategy adjusts only the metrics routed to it, so that each metric is adjusted once.
+type strategyRouter struct {
+	routes          []strategyRoute
+	defaultStrategy string
+	strategies      []string
+	adjusters       map[string]processorhelper.ProcessMetricsFunc
+
+	mu sync.RWMutex
+	// names remembers the strategy of the metric names seen, so that
+	// the regexes are only evaluated once per name.
+	names map[string]string
+}
+
+func newStrategyRouter(cfg *Config) (*strategyRouter, error) {
+	r := &strategyRouter{
+		defaultStrategy: cfg.Strategy,
+		names:           make(map[string]string),
+	}
+	for _, ms := range cfg.MetricStrategies {
+		regex, err := regexp.Compile(ms.MetricNameRegex)
+		if err != nil {
+			return nil, err
+		}
+		r.routes = append(r.routes, strategyRoute{regex: regex, strategy: ms.Strategy})
+	}
+	r.strategies = append(r.strategies, cfg.Strategy)
+	for _, route := range r.routes {
+		if !slices.Contains(r.strategies, route.strategy) {
+			r.strategies = append(r.strategies, route.strategy)
+		}
+	}
+	return r, nil
+}
+
+// route returns the strategy of the metric with the given name.
+func (r *strategyRouter) route(name string) string {
+	r.mu.RLock()
+	strategy, ok := r.names[name]
+	r.mu.RUnlock()
+	if ok {
+		return strategy
+	}
+
+	strategy = r.defaultStrategy
+	for _, route := range r.routes {
+		if route.regex.MatchString(name) {
+			strategy = route.strategy
+			break
+		}
+	}
+	r.mu.Lock()
+	if len(r.names) >= maxRoutedNames {
+		clear(r.names)
+	}
+	r.names[name] = strategy
+	r.mu.Unlock()
+	return strategy
+}
+
+// filter returns the filter selecting the metrics routed to strategy.
+func (r *strategyRouter) filter(strategy string) func(pmetric.Metric) bool {
+	return func(metric pmetric.Metric) bool {
+		return r.route(metric.Name()) == strategy
+	}
+}
+
+// AdjustMetrics adjusts the metrics with each of the strategies in turn.
+func (r *strategyRouter) AdjustMetrics(ctx context.Context, metrics pmetric.Metrics) (pmetric.Metrics, error) {
+	var err error
+	for _, strategy := range r.strategies {
+		if metrics, err = r.adjusters[strategy](ctx, metrics); err != nil {
+			return metrics, err
+		}
+	}
+	return metrics, nil
+}
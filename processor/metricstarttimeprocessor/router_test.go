@@ -0,0 +1,87 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metricstarttimeprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processortest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/metadata"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/subtractinitial"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/testhelper"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/truereset"
+)
+
+func TestStrategyRouterRoute(t *testing.T) {
+	r, err := newStrategyRouter(&Config{
+		Strategy: truereset.Type,
+		MetricStrategies: []MetricStrategy{
+			{MetricNameRegex: "^container_", Strategy: subtractinitial.Type},
+			{MetricNameRegex: "^container_cpu", Strategy: truereset.Type},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{truereset.Type, subtractinitial.Type}, r.strategies)
+	assert.Equal(t, subtractinitial.Type, r.route("container_cpu_seconds_total"))
+	assert.Equal(t, truereset.Type, r.route("http_requests_total"))
+	// remembered names are routed the same
+	assert.Equal(t, subtractinitial.Type, r.route("container_cpu_seconds_total"))
+	assert.Len(t, r.names, 2)
+}
+
+func TestMetricStrategies(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MetricStrategies = []MetricStrategy{
+		{MetricNameRegex: "^container_", Strategy: subtractinitial.Type},
+	}
+	require.NoError(t, cfg.Validate())
+
+	sink := new(consumertest.MetricsSink)
+	p, err := NewFactory().CreateMetrics(context.Background(), processortest.NewNopSettings(metadata.Type), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+
+	batch := func(timestamp int64, container, requests float64) pmetric.Metrics {
+		return testhelper.Metrics(
+			testhelper.SumMetric("container_cpu_seconds_total", testhelper.DoublePoint(nil, 0, testhelper.TimestampFromMs(timestamp), container)),
+			testhelper.SumMetric("http_requests_total", testhelper.DoublePoint(nil, 0, testhelper.TimestampFromMs(timestamp), requests)),
+		)
+	}
+	points := func(md pmetric.Metrics) map[string]pmetric.NumberDataPointSlice {
+		ret := map[string]pmetric.NumberDataPointSlice{}
+		metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+		for i := 0; i < metrics.Len(); i++ {
+			ret[metrics.At(i).Name()] = metrics.At(i).Sum().DataPoints()
+		}
+		return ret
+	}
+
+	require.NoError(t, p.ConsumeMetrics(context.Background(), batch(1000, 10, 5)))
+	require.NoError(t, p.ConsumeMetrics(context.Background(), batch(2000, 15, 8)))
+	require.Len(t, sink.AllMetrics(), 2)
+
+	// the initial point of the container metric is dropped and subtracted from the
+	// following ones, the values of the other metric are preserved
+	first := points(sink.AllMetrics()[0])
+	assert.Equal(t, 0, first["container_cpu_seconds_total"].Len())
+	require.Equal(t, 1, first["http_requests_total"].Len())
+	assert.Equal(t, 5.0, first["http_requests_total"].At(0).DoubleValue())
+
+	second := points(sink.AllMetrics()[1])
+	require.Equal(t, 1, second["container_cpu_seconds_total"].Len())
+	assert.Equal(t, testhelper.TimestampFromMs(1000), second["container_cpu_seconds_total"].At(0).StartTimestamp())
+	assert.Equal(t, 5.0, second["container_cpu_seconds_total"].At(0).DoubleValue())
+	require.Equal(t, 1, second["http_requests_total"].Len())
+	assert.Equal(t, first["http_requests_total"].At(0).StartTimestamp(), second["http_requests_total"].At(0).StartTimestamp())
+	assert.Equal(t, 8.0, second["http_requests_total"].At(0).DoubleValue())
+}
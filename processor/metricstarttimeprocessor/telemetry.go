@@ -0,0 +1,67 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metricstarttimeprocessor // import "github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor"
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/metadata"
+)
+
+// seriesTracker is implemented by the adjusters keeping state per series.
+type seriesTracker interface {
+	TrackedSeries() int64
+	EvictedSeries() (gc, maxSeries int64)
+}
+
+// processorTelemetry reports the telemetry of the strategies of a processor.
+type processorTelemetry struct {
+	builder *metadata.TelemetryBuilder
+	// trackers are the adjusters keeping state per series by strategy, only
+	// modified until the callbacks are registered.
+	trackers map[string]seriesTracker
+}
+
+func newProcessorTelemetry(set component.TelemetrySettings) (*processorTelemetry, error) {
+	builder, err := metadata.NewTelemetryBuilder(set)
+	if err != nil {
+		return nil, err
+	}
+	return &processorTelemetry{builder: builder, trackers: make(map[string]seriesTracker)}, nil
+}
+
+// track reports the series the adjuster of strategy keeps state for.
+func (t *processorTelemetry) track(strategy string, tracker seriesTracker) {
+	t.trackers[strategy] = tracker
+}
+
+// registerCallbacks registers the callbacks observing the series of the tracked adjusters.
+func (t *processorTelemetry) registerCallbacks() error {
+	return errors.Join(
+		t.builder.RegisterMetricstarttimeTrackedSeriesCallback(func(_ context.Context, observer metric.Int64Observer) error {
+			for strategy, tracker := range t.trackers {
+				observer.Observe(tracker.TrackedSeries(), metric.WithAttributes(attribute.String("strategy", strategy)))
+			}
+			return nil
+		}),
+		t.builder.RegisterMetricstarttimeEvictedSeriesCallback(func(_ context.Context, observer metric.Int64Observer) error {
+			for strategy, tracker := range t.trackers {
+				gc, maxSeries := tracker.EvictedSeries()
+				observer.Observe(gc, metric.WithAttributes(attribute.String("strategy", strategy), attribute.String("reason", "gc")))
+				observer.Observe(maxSeries, metric.WithAttributes(attribute.String("strategy", strategy), attribute.String("reason", "max_tracked_series")))
+			}
+			return nil
+		}),
+	)
+}
+
+func (t *processorTelemetry) shutdown(context.Context) error {
+	t.builder.Shutdown()
+	return nil
+}
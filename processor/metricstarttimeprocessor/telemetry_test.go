@@ -0,0 +1,55 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package metricstarttimeprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/metadatatest"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/subtractinitial"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/processor/metricstarttimeprocessor/internal/testhelper"
+)
+
+func TestTelemetry(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Strategy = subtractinitial.Type
+	p, err := NewFactory().CreateMetrics(context.Background(), metadatatest.NewSettings(tel), cfg, new(consumertest.MetricsSink))
+	require.NoError(t, err)
+	require.NoError(t, p.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, p.Shutdown(context.Background())) }()
+
+	// the initial point is dropped, the second one adjusted and the third one is a reset
+	for i, value := range []float64{10, 15, 3} {
+		md := testhelper.Metrics(testhelper.SumMetric("requests_total", testhelper.DoublePoint(nil, 0, testhelper.TimestampFromMs(int64(i+1)*1000), value)))
+		require.NoError(t, p.ConsumeMetrics(context.Background(), md))
+	}
+
+	strategy := attribute.String("strategy", subtractinitial.Type)
+	metadatatest.AssertEqualMetricstarttimeAdjustedDatapoints(t, tel,
+		[]metricdata.DataPoint[int64]{{Value: 2, Attributes: attribute.NewSet(strategy)}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualMetricstarttimeResets(t, tel,
+		[]metricdata.DataPoint[int64]{{Value: 1, Attributes: attribute.NewSet(strategy, attribute.String("metric_type", "sum"))}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualMetricstarttimeTrackedSeries(t, tel,
+		[]metricdata.DataPoint[int64]{{Value: 1, Attributes: attribute.NewSet(strategy)}},
+		metricdatatest.IgnoreTimestamp())
+	metadatatest.AssertEqualMetricstarttimeEvictedSeries(t, tel,
+		[]metricdata.DataPoint[int64]{
+			{Value: 0, Attributes: attribute.NewSet(strategy, attribute.String("reason", "gc"))},
+			{Value: 0, Attributes: attribute.NewSet(strategy, attribute.String("reason", "max_tracked_series"))},
+		},
+		metricdatatest.IgnoreTimestamp())
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"regexp"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/elastic/go-libaudit/v2"
@@ -28,13 +29,36 @@ var (
 	PATTERN = regexp.MustCompile(`audit\((\d+)\.(\d+):(\d+)\):`)
 )
 
+// auditClient is the subset of the libaudit.AuditClient methods used to
+// receive the audit messages and tune the kernel, so that recorded messages can be replayed in tests.
+type auditClient interface {
+	Receive(nonBlocking bool) (*libaudit.RawAuditMessage, error)
+	AddRule(rule []byte) error
+	GetStatus() (*libaudit.AuditStatus, error)
+	SetEnabled(enabled bool, wm libaudit.WaitMode) error
+	SetPID(wm libaudit.WaitMode) error
+	SetRateLimit(perSecondLimit uint32, wm libaudit.WaitMode) error
+	Close() error
+}
+
+var _ auditClient = (*libaudit.AuditClient)(nil)
+
 type Auditd struct {
-	rules    []string
-	client   *libaudit.AuditClient
-	consumer consumer.Logs
-	logger   *zap.Logger
-	done     chan struct{}
-	internal metrics // Benchmark
+	rules     []string
+	client    auditClient
+	newClient func() (auditClient, error)
+	consumer  consumer.Logs
+	logger    *zap.Logger
+	settings  receiver.Settings
+	limits    SelfLimitsConfig
+	limiter   *selfLimiter
+	coverage  CoverageConfig
+	sequence  *sequenceCoverage
+	coalesce  CoalesceConfig
+	coalescer *eventCoalescer
+	done      chan struct{}
+	wg        sync.WaitGroup
+	internal  metrics // Benchmark
 }
 
 // Benchmark
@@ -45,13 +69,28 @@ type metrics struct {
 
 func newAuditd(cfg *AuditdReceiverConfig, consumer consumer.Logs, settings receiver.Settings) (*Auditd, error) {
 	return &Auditd{
-		rules:    cfg.Rules,
-		consumer: consumer,
-		logger:   settings.Logger,
-		internal: metrics{0, 0}, // Benchmark
+		rules:     cfg.Rules,
+		newClient: newNetlinkClient,
+		consumer:  consumer,
+		logger:    settings.Logger,
+		settings:  settings,
+		limits:    cfg.SelfLimits,
+		coverage:  cfg.Coverage,
+		coalesce:  cfg.Coalesce,
+		internal:  metrics{0, 0}, // Benchmark
 	}, nil
 }
 
+// newNetlinkClient returns a client receiving the audit messages from the kernel.
+func newNetlinkClient() (auditClient, error) {
+	var w io.Writer
+	client, err := libaudit.NewAuditClient(w)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
 func createLogs(ts time.Time, messageType auparse.AuditMessageType, messageID int64, messageData []byte) plog.Logs {
 	logs := plog.NewLogs()
 	resourceLogs := logs.ResourceLogs().AppendEmpty()
@@ -95,23 +134,39 @@ func (aud *Auditd) receive(ctx context.Context) {
 	aud.logger.Info("starting listening for events")
 	for {
 		select {
-		case _, ok := <-aud.done:
-			_ = ok
+		case <-aud.done:
 			return
 		case <-ctx.Done():
 			return
 		default:
 			rawEvent, err := aud.client.Receive(false)
 			if err != nil {
+				select {
+				case <-aud.done:
+					// The client was closed by Shutdown to unblock the Receive.
+					return
+				default:
+				}
 				aud.logger.Error("receive failed", zap.Error(err))
+				continue
 			}
 			s, ns, id := aud.parseMessageDetails(rawEvent.Data)
 			ts := time.Unix(s, ns)
+			if aud.sequence != nil {
+				aud.sequence.observe(id)
+			}
 			// Messages from 1100-2999 are valid audit messages.
 			if rawEvent.Type < auparse.AUDIT_USER_AUTH ||
 				rawEvent.Type > auparse.AUDIT_LAST_USER_MSG2 {
 				continue
 			}
+			if aud.limiter != nil && !aud.limiter.keep(ctx, id) {
+				continue
+			}
+			if aud.coalescer != nil {
+				aud.coalescer.add(ctx, time.Now(), ts, rawEvent.Type, id, rawEvent.Data)
+				continue
+			}
 			logs := createLogs(ts, rawEvent.Type, id, rawEvent.Data)
 			aud.consumer.ConsumeLogs(ctx, logs)
 		}
@@ -156,8 +211,7 @@ func (aud *Auditd) initAuditing() error {
 }
 
 func (aud *Auditd) Start(ctx context.Context, host component.Host) error {
-	var w io.Writer
-	client, err := libaudit.NewAuditClient(w)
+	client, err := aud.newClient()
 	if err != nil {
 		return fmt.Errorf("failed to create client %w", err)
 	}
@@ -173,16 +227,69 @@ func (aud *Auditd) Start(ctx context.Context, host component.Host) error {
 		return fmt.Errorf("failed to initialise auditing: %v", err)
 	}
 
-	go aud.receive(ctx)
+	if aud.limits.Enabled {
+		control, err := aud.newClient()
+		if err != nil {
+			return fmt.Errorf("failed to create control client %w", err)
+		}
+		aud.limiter, err = newSelfLimiter(aud.limits, control, aud.logger, aud.settings.MeterProvider)
+		if err != nil {
+			_ = control.Close()
+			return fmt.Errorf("failed to setup self limits: %w", err)
+		}
+		aud.limiter.start(ctx)
+	}
+
+	if aud.coverage.Enabled {
+		aud.sequence, err = newSequenceCoverage(aud.coverage, aud.logger, aud.settings.MeterProvider)
+		if err != nil {
+			return fmt.Errorf("failed to setup sequence coverage: %w", err)
+		}
+		aud.sequence.start()
+	}
+
+	if aud.coalesce.Enabled {
+		aud.coalescer, err = newEventCoalescer(aud.coalesce, aud.consumer, aud.logger, aud.settings.MeterProvider)
+		if err != nil {
+			return fmt.Errorf("failed to setup event coalescing: %w", err)
+		}
+		aud.coalescer.start(ctx)
+	}
+
+	aud.done = make(chan struct{})
+	aud.wg.Add(1)
+	go func() {
+		defer aud.wg.Done()
+		aud.receive(ctx)
+	}()
 	return nil
 }
 
-func (aud *Auditd) Shutdown(_ context.Context) error {
+func (aud *Auditd) Shutdown(ctx context.Context) error {
 	if aud.done != nil {
-		aud.done <- struct{}{}
 		close(aud.done)
-		aud.client.Close()
-		aud.done = nil
+	}
+	// Closing the client unblocks the pending Receive, the helpers below are only torn down once
+	// the receiving goroutine has returned since it uses them.
+	if aud.client != nil {
+		if err := aud.client.Close(); err != nil {
+			aud.logger.Warn("failed to close audit client", zap.Error(err))
+		}
+		aud.client = nil
+	}
+	aud.wg.Wait()
+	aud.done = nil
+	if aud.limiter != nil {
+		aud.limiter.shutdown(ctx)
+		aud.limiter = nil
+	}
+	if aud.sequence != nil {
+		aud.sequence.shutdown()
+		aud.sequence = nil
+	}
+	if aud.coalescer != nil {
+		aud.coalescer.shutdown(ctx)
+		aud.coalescer = nil
 	}
 	return nil
 }
@@ -0,0 +1,191 @@
+//go:build linux
+// +build linux
+
+package auditdreceiver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/elastic/go-libaudit/v2/auparse"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/olandr/opentelemetry-collector-contrib/receiver/auditdreceiver/internal/metadata"
+)
+
+// pendingEvent holds the messages received so far for an audit event.
+type pendingEvent struct {
+	logs      plog.Logs
+	records   plog.LogRecordSlice
+	firstSeen time.Time
+}
+
+// eventCoalescer reassembles the messages sharing a sequence number into a single batch of log
+// records. An event is forwarded once its EOE message is received, or once it waited for the
+// effective timeout. The timeout grows from MinTimeout to MaxTimeout with the number of events
+// waiting for their messages: short under low load to keep the latency low, longer under bursts
+// where the messages of an event arrive further apart, to avoid splitting it.
+type eventCoalescer struct {
+	cfg      CoalesceConfig
+	consumer consumer.Logs
+	logger   *zap.Logger
+
+	mu       sync.Mutex
+	inFlight map[int64]*pendingEvent
+	// peak is the highest number of events in flight since the last maintenance.
+	peak    int
+	timeout time.Duration
+
+	done chan struct{}
+	wg   sync.WaitGroup
+
+	timeoutGauge  metric.Float64Gauge
+	expiredEvents metric.Int64Counter
+}
+
+func newEventCoalescer(cfg CoalesceConfig, consumer consumer.Logs, logger *zap.Logger, mp metric.MeterProvider) (*eventCoalescer, error) {
+	meter := mp.Meter(metadata.ScopeName)
+	ec := &eventCoalescer{
+		cfg:      cfg,
+		consumer: consumer,
+		logger:   logger,
+		inFlight: make(map[int64]*pendingEvent),
+		timeout:  cfg.MinTimeout,
+	}
+	var err error
+	if ec.timeoutGauge, err = meter.Float64Gauge("otelcol_auditd_coalesce_timeout",
+		metric.WithDescription("Effective time the messages of an audit event are waited for before it is forwarded"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if ec.expiredEvents, err = meter.Int64Counter("otelcol_auditd_coalesce_expired_events",
+		metric.WithDescription("Number of audit events forwarded before their end of event message was received"),
+		metric.WithUnit("{events}")); err != nil {
+		return nil, err
+	}
+	return ec, nil
+}
+
+// start forwards the events whose timeout is over, checking a few times per minimum timeout.
+func (ec *eventCoalescer) start(ctx context.Context) {
+	ec.timeoutGauge.Record(ctx, ec.cfg.MinTimeout.Seconds())
+	done := make(chan struct{})
+	ec.done = done
+	ec.wg.Add(1)
+	go func() {
+		defer ec.wg.Done()
+		ticker := time.NewTicker(max(ec.cfg.MinTimeout/4, time.Millisecond))
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				ec.maintain(ctx, now)
+			}
+		}
+	}()
+}
+
+// shutdown stops the maintenance and forwards the events still in flight.
+func (ec *eventCoalescer) shutdown(ctx context.Context) {
+	if ec.done != nil {
+		close(ec.done)
+		ec.wg.Wait()
+		ec.done = nil
+	}
+	ec.mu.Lock()
+	pending := ec.inFlight
+	ec.inFlight = make(map[int64]*pendingEvent)
+	ec.mu.Unlock()
+	for _, e := range pending {
+		ec.forward(ctx, e.logs)
+	}
+}
+
+// add coalesces a received message with the other messages of its event, and forwards the
+// event once it is complete.
+func (ec *eventCoalescer) add(ctx context.Context, now, ts time.Time, messageType auparse.AuditMessageType, messageID int64, messageData []byte) {
+	// The user space messages, and the anomaly and response messages of the daemon, are events on their own.
+	if messageID <= 0 || !isMultiRecord(messageType) {
+		ec.forward(ctx, createLogs(ts, messageType, messageID, messageData))
+		return
+	}
+
+	ec.mu.Lock()
+	e, ok := ec.inFlight[messageID]
+	if !ok {
+		e = &pendingEvent{logs: plog.NewLogs(), firstSeen: now}
+		e.records = e.logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+		ec.inFlight[messageID] = e
+		ec.peak = max(ec.peak, len(ec.inFlight))
+	}
+	createLogs(ts, messageType, messageID, messageData).ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().MoveAndAppendTo(e.records)
+	complete := messageType == auparse.AUDIT_EOE
+	if complete {
+		delete(ec.inFlight, messageID)
+	}
+	ec.mu.Unlock()
+
+	if complete {
+		ec.forward(ctx, e.logs)
+	}
+}
+
+// maintain adapts the timeout to the burst size since the last maintenance, and forwards the
+// events that waited for longer than the timeout.
+func (ec *eventCoalescer) maintain(ctx context.Context, now time.Time) {
+	ec.mu.Lock()
+	timeout := ec.effectiveTimeout(ec.peak)
+	changed := timeout != ec.timeout
+	ec.timeout = timeout
+	var expired []*pendingEvent
+	for id, e := range ec.inFlight {
+		if now.Sub(e.firstSeen) >= timeout {
+			delete(ec.inFlight, id)
+			expired = append(expired, e)
+		}
+	}
+	ec.peak = len(ec.inFlight)
+	ec.mu.Unlock()
+
+	if changed {
+		ec.logger.Debug("audit event coalescing timeout adapted", zap.Duration("timeout", timeout))
+		ec.timeoutGauge.Record(ctx, timeout.Seconds())
+	}
+	if len(expired) > 0 {
+		ec.expiredEvents.Add(ctx, int64(len(expired)))
+	}
+	for _, e := range expired {
+		ec.forward(ctx, e.logs)
+	}
+}
+
+// effectiveTimeout interpolates the timeout between the minimum, for a single event in flight,
+// and the maximum, reached once BurstSize events are in flight.
+func (ec *eventCoalescer) effectiveTimeout(burst int) time.Duration {
+	if burst <= 1 {
+		return ec.cfg.MinTimeout
+	}
+	if burst >= ec.cfg.BurstSize {
+		return ec.cfg.MaxTimeout
+	}
+	span := ec.cfg.MaxTimeout - ec.cfg.MinTimeout
+	return ec.cfg.MinTimeout + span*time.Duration(burst-1)/time.Duration(ec.cfg.BurstSize-1)
+}
+
+func (ec *eventCoalescer) forward(ctx context.Context, logs plog.Logs) {
+	if err := ec.consumer.ConsumeLogs(ctx, logs); err != nil {
+		ec.logger.Error("failed to consume audit event", zap.Error(err))
+	}
+}
+
+// isMultiRecord reports whether messageType belongs to the kernel events made of several
+// messages terminated by an EOE message, e.g. SYSCALL, PATH and PROCTITLE.
+func isMultiRecord(messageType auparse.AuditMessageType) bool {
+	return messageType > auparse.AUDIT_LAST_DAEMON && messageType < auparse.AUDIT_ANOM_LOGIN_FAILURES
+}
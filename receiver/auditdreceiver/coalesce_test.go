@@ -0,0 +1,116 @@
+//go:build linux
+// +build linux
+
+package auditdreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-libaudit/v2/auparse"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/zap"
+)
+
+func newTestEventCoalescer(t *testing.T) (*eventCoalescer, *consumertest.LogsSink) {
+	cfg := createDefaultConfig().(*AuditdReceiverConfig).Coalesce
+	cfg.Enabled = true
+	cfg.MinTimeout = 100 * time.Millisecond
+	cfg.MaxTimeout = 500 * time.Millisecond
+	cfg.BurstSize = 5
+	sink := new(consumertest.LogsSink)
+	ec, err := newEventCoalescer(cfg, sink, zap.NewNop(), noop.NewMeterProvider())
+	require.NoError(t, err)
+	return ec, sink
+}
+
+func TestEventCoalescer(t *testing.T) {
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0)
+
+	t.Run("forwards an event once its EOE message is received", func(t *testing.T) {
+		ec, sink := newTestEventCoalescer(t)
+		ec.add(ctx, start, start, auparse.AUDIT_SYSCALL, 10, []byte("syscall"))
+		ec.add(ctx, start, start, auparse.AUDIT_PATH, 10, []byte("path"))
+		ec.add(ctx, start, start, auparse.AUDIT_SYSCALL, 11, []byte("syscall"))
+		require.Empty(t, sink.AllLogs())
+
+		ec.add(ctx, start, start, auparse.AUDIT_EOE, 10, []byte("eoe"))
+		require.Len(t, sink.AllLogs(), 1)
+		require.Equal(t, 3, sink.LogRecordCount())
+		require.Len(t, ec.inFlight, 1)
+	})
+
+	t.Run("forwards the user space messages right away", func(t *testing.T) {
+		ec, sink := newTestEventCoalescer(t)
+		ec.add(ctx, start, start, auparse.AUDIT_USER_AUTH, 10, []byte("auth"))
+		ec.add(ctx, start, start, auparse.AUDIT_ANOM_LOGIN_FAILURES, 11, []byte("login failures"))
+		require.Len(t, sink.AllLogs(), 2)
+		require.Empty(t, ec.inFlight)
+	})
+
+	t.Run("forwards incomplete events once the timeout is over", func(t *testing.T) {
+		ec, sink := newTestEventCoalescer(t)
+		ec.add(ctx, start, start, auparse.AUDIT_SYSCALL, 10, []byte("syscall"))
+		ec.maintain(ctx, start.Add(50*time.Millisecond))
+		require.Empty(t, sink.AllLogs())
+
+		ec.maintain(ctx, start.Add(125*time.Millisecond))
+		require.Len(t, sink.AllLogs(), 1)
+		require.Empty(t, ec.inFlight)
+	})
+
+	t.Run("extends the timeout under bursts", func(t *testing.T) {
+		ec, sink := newTestEventCoalescer(t)
+		for id := int64(1); id <= 3; id++ {
+			ec.add(ctx, start, start, auparse.AUDIT_SYSCALL, id, []byte("syscall"))
+		}
+		ec.maintain(ctx, start.Add(150*time.Millisecond))
+		require.Equal(t, 300*time.Millisecond, ec.timeout)
+		require.Empty(t, sink.AllLogs())
+
+		for id := int64(4); id <= 9; id++ {
+			ec.add(ctx, start, start, auparse.AUDIT_SYSCALL, id, []byte("syscall"))
+		}
+		ec.maintain(ctx, start.Add(400*time.Millisecond))
+		require.Equal(t, 500*time.Millisecond, ec.timeout)
+		require.Empty(t, sink.AllLogs())
+
+		ec.maintain(ctx, start.Add(500*time.Millisecond))
+		require.Len(t, sink.AllLogs(), 9)
+
+		// the timeout shortens once the burst is over
+		ec.maintain(ctx, start.Add(600*time.Millisecond))
+		require.Equal(t, 100*time.Millisecond, ec.timeout)
+	})
+
+	t.Run("forwards the events in flight on shutdown", func(t *testing.T) {
+		ec, sink := newTestEventCoalescer(t)
+		ec.start(ctx)
+		ec.add(ctx, time.Now(), start, auparse.AUDIT_SYSCALL, 10, []byte("syscall"))
+		ec.shutdown(ctx)
+		require.Len(t, sink.AllLogs(), 1)
+	})
+}
+
+func TestCoalesceValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*AuditdReceiverConfig)
+	cfg.Coalesce.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Coalesce.MaxTimeout = cfg.Coalesce.MinTimeout / 2
+	require.ErrorContains(t, cfg.Validate(), "coalesce.max_timeout")
+
+	cfg = createDefaultConfig().(*AuditdReceiverConfig)
+	cfg.Coalesce.Enabled = true
+	cfg.Coalesce.MinTimeout = 0
+	require.ErrorContains(t, cfg.Validate(), "coalesce.min_timeout")
+
+	cfg = createDefaultConfig().(*AuditdReceiverConfig)
+	cfg.Coalesce.Enabled = true
+	cfg.Coalesce.BurstSize = 0
+	require.ErrorContains(t, cfg.Validate(), "coalesce.burst_size")
+}
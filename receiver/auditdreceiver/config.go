@@ -1,11 +1,73 @@
 package auditdreceiver
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"go.opentelemetry.io/collector/component"
 )
 
 type AuditdReceiverConfig struct {
-	Rules []string `mapstructure:"rules,omitempty"`
+	// Profile is a preset of rules, self limits and coverage settings, applied before the rest of the
+	// configuration, which overrides it. See profiles.go.
+	Profile    string           `mapstructure:"profile,omitempty"`
+	Rules      []string         `mapstructure:"rules,omitempty"`
+	SelfLimits SelfLimitsConfig `mapstructure:"self_limits"`
+	Coverage   CoverageConfig   `mapstructure:"coverage"`
+	Coalesce   CoalesceConfig   `mapstructure:"coalesce"`
+
+	_ struct{}
+}
+
+// SelfLimitsConfig configures the self-protection of the receiver. When the CPU usage of the
+// collector process or the kernel audit backlog exceed their thresholds the receiver degrades:
+// the kernel audit rate limit is lowered and only a sample of the messages is forwarded. Full
+// fidelity is restored once the pressure has subsided for RecoveryChecks consecutive checks.
+type SelfLimitsConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// CheckInterval is how often the CPU usage and the audit backlog are checked.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// MaxCPUPercent is the CPU usage of the collector process, in percent of one core, above which
+	// the receiver degrades. Disabled when 0.
+	MaxCPUPercent float64 `mapstructure:"max_cpu_percent"`
+	// MaxQueueDepth is the number of messages waiting in the kernel audit backlog above which the
+	// receiver degrades. Disabled when 0.
+	MaxQueueDepth uint32 `mapstructure:"max_queue_depth"`
+	// DegradedRateLimit is the kernel audit rate limit, in messages per second, applied while
+	// degraded. The kernel rate limit is left untouched when 0.
+	DegradedRateLimit uint32 `mapstructure:"degraded_rate_limit"`
+	// SampleRatio forwards one of every SampleRatio audit events while degraded, with all their messages.
+	SampleRatio uint32 `mapstructure:"sample_ratio"`
+	// RecoveryChecks is the number of consecutive checks without pressure needed to restore full fidelity.
+	RecoveryChecks int `mapstructure:"recovery_checks"`
+
+	_ struct{}
+}
+
+// CoverageConfig configures the reporting of the audit sequence coverage: the ratio of the audit
+// sequence numbers seen by the receiver to the ones expected, as a measure of how complete the
+// received audit trail is.
+type CoverageConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// Interval is how often the coverage is reported, over the sequence numbers seen since the last report.
+	Interval time.Duration `mapstructure:"interval"`
+
+	_ struct{}
+}
+
+// CoalesceConfig configures the reassembly of the messages of the multi-record kernel audit events,
+// e.g. the SYSCALL, PATH and PROCTITLE messages of a system call, into a single batch of log records.
+// An event is forwarded once its EOE message is received, or once it waited for the effective
+// timeout, which grows from MinTimeout to MaxTimeout with the number of events in flight.
+type CoalesceConfig struct {
+	Enabled bool `mapstructure:"enabled"`
+	// MinTimeout is the time an event is waited for while it is the only one in flight.
+	MinTimeout time.Duration `mapstructure:"min_timeout"`
+	// MaxTimeout is the time an event is waited for once BurstSize events are in flight.
+	MaxTimeout time.Duration `mapstructure:"max_timeout"`
+	// BurstSize is the number of events in flight from which the timeout is MaxTimeout.
+	BurstSize int `mapstructure:"burst_size"`
 
 	_ struct{}
 }
@@ -13,5 +75,59 @@ type AuditdReceiverConfig struct {
 func createDefaultConfig() component.Config {
 	return &AuditdReceiverConfig{
 		Rules: []string{},
+		SelfLimits: SelfLimitsConfig{
+			CheckInterval:  5 * time.Second,
+			MaxCPUPercent:  50,
+			SampleRatio:    10,
+			RecoveryChecks: 3,
+		},
+		Coverage: CoverageConfig{
+			Interval: time.Minute,
+		},
+		Coalesce: CoalesceConfig{
+			MinTimeout: 100 * time.Millisecond,
+			MaxTimeout: 2 * time.Second,
+			BurstSize:  64,
+		},
+	}
+}
+
+func (cfg *AuditdReceiverConfig) Validate() error {
+	if _, found := profiles[cfg.Profile]; cfg.Profile != "" && !found {
+		return fmt.Errorf("unknown 'profile' %q, must be one of: %v", cfg.Profile, profileNames())
+	}
+	if cfg.Coverage.Enabled && cfg.Coverage.Interval <= 0 {
+		return errors.New("'coverage.interval' must be positive")
+	}
+	if co := cfg.Coalesce; co.Enabled {
+		if co.MinTimeout <= 0 {
+			return errors.New("'coalesce.min_timeout' must be positive")
+		}
+		if co.MaxTimeout < co.MinTimeout {
+			return errors.New("'coalesce.max_timeout' must not be lower than 'coalesce.min_timeout'")
+		}
+		if co.BurstSize < 1 {
+			return errors.New("'coalesce.burst_size' must be at least 1")
+		}
+	}
+	sl := cfg.SelfLimits
+	if !sl.Enabled {
+		return nil
+	}
+	if sl.CheckInterval <= 0 {
+		return errors.New("'self_limits.check_interval' must be positive")
+	}
+	if sl.MaxCPUPercent < 0 {
+		return errors.New("'self_limits.max_cpu_percent' must not be negative")
+	}
+	if sl.MaxCPUPercent == 0 && sl.MaxQueueDepth == 0 {
+		return errors.New("'self_limits' requires at least one of 'max_cpu_percent' or 'max_queue_depth'")
+	}
+	if sl.SampleRatio == 0 {
+		return errors.New("'self_limits.sample_ratio' must be at least 1")
+	}
+	if sl.RecoveryChecks < 1 {
+		return errors.New("'self_limits.recovery_checks' must be at least 1")
 	}
+	return nil
 }
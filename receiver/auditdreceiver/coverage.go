@@ -0,0 +1,140 @@
+//go:build linux
+// +build linux
+
+package auditdreceiver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/olandr/opentelemetry-collector-contrib/receiver/auditdreceiver/internal/metadata"
+)
+
+// sequenceCoverage detects gaps in the audit sequence numbers and periodically reports the
+// ratio of the sequence numbers seen to the ones expected over the last interval. All the
+// messages of an audit event share its sequence number, which the kernel increments for every
+// event, so any number missing in between means an event was lost before reaching the receiver.
+type sequenceCoverage struct {
+	cfg    CoverageConfig
+	logger *zap.Logger
+
+	mu sync.Mutex
+	// base is the highest sequence number accounted for by a previous interval, 0 until the first
+	// sequence number is observed.
+	base int64
+	// highest is the highest sequence number observed so far.
+	highest int64
+	// previous is the sequence number of the last observed message.
+	previous int64
+	// seen is the number of distinct sequence numbers above base observed in the current interval.
+	seen int64
+
+	done chan struct{}
+
+	coverageGauge    metric.Float64Gauge
+	missingSequences metric.Int64Counter
+}
+
+func newSequenceCoverage(cfg CoverageConfig, logger *zap.Logger, mp metric.MeterProvider) (*sequenceCoverage, error) {
+	meter := mp.Meter(metadata.ScopeName)
+	sc := &sequenceCoverage{
+		cfg:    cfg,
+		logger: logger,
+	}
+	var err error
+	if sc.coverageGauge, err = meter.Float64Gauge("otelcol_auditd_sequence_coverage",
+		metric.WithDescription("Ratio of the audit sequence numbers seen to the ones expected over the last interval"),
+		metric.WithUnit("1")); err != nil {
+		return nil, err
+	}
+	if sc.missingSequences, err = meter.Int64Counter("otelcol_auditd_missing_sequences",
+		metric.WithDescription("Number of audit sequence numbers that were expected but never seen"),
+		metric.WithUnit("{sequences}")); err != nil {
+		return nil, err
+	}
+	return sc, nil
+}
+
+// start reports the coverage every interval.
+func (sc *sequenceCoverage) start() {
+	done := make(chan struct{})
+	sc.done = done
+	go func() {
+		ticker := time.NewTicker(sc.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				sc.report(context.Background())
+			}
+		}
+	}()
+}
+
+// shutdown stops the reporting.
+func (sc *sequenceCoverage) shutdown() {
+	if sc.done != nil {
+		close(sc.done)
+		sc.done = nil
+	}
+}
+
+// observe records the sequence number of a received message.
+func (sc *sequenceCoverage) observe(id int64) {
+	if id <= 0 {
+		return
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if id == sc.previous {
+		return
+	}
+	sc.previous = id
+	if sc.base == 0 {
+		sc.base = id - 1
+		sc.highest = sc.base
+	}
+	if id <= sc.base {
+		return
+	}
+	sc.seen++
+	if id > sc.highest {
+		sc.highest = id
+	}
+}
+
+// next returns the number of sequence numbers seen and expected in the current interval, and
+// starts a new one.
+func (sc *sequenceCoverage) next() (seen, expected int64) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	expected = sc.highest - sc.base
+	seen = min(sc.seen, expected)
+	sc.base = sc.highest
+	sc.seen = 0
+	return seen, expected
+}
+
+// report records the coverage of the current interval and starts a new one. Nothing is recorded
+// when no sequence number was expected.
+func (sc *sequenceCoverage) report(ctx context.Context) {
+	seen, expected := sc.next()
+	if expected <= 0 {
+		return
+	}
+	coverage := float64(seen) / float64(expected)
+	sc.coverageGauge.Record(ctx, coverage)
+	if missing := expected - seen; missing > 0 {
+		sc.missingSequences.Add(ctx, missing)
+		sc.logger.Warn("audit sequence numbers are missing",
+			zap.Int64("missing", missing),
+			zap.Int64("expected", expected),
+			zap.Float64("coverage", coverage))
+	}
+}
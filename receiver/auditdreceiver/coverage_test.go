@@ -0,0 +1,91 @@
+//go:build linux
+// +build linux
+
+package auditdreceiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/zap"
+)
+
+func newTestSequenceCoverage(t *testing.T) *sequenceCoverage {
+	cfg := createDefaultConfig().(*AuditdReceiverConfig).Coverage
+	cfg.Enabled = true
+	sc, err := newSequenceCoverage(cfg, zap.NewNop(), noop.NewMeterProvider())
+	require.NoError(t, err)
+	return sc
+}
+
+func observeAll(sc *sequenceCoverage, ids ...int64) {
+	for _, id := range ids {
+		sc.observe(id)
+	}
+}
+
+func TestSequenceCoverage(t *testing.T) {
+	t.Run("nothing expected before the first sequence number", func(t *testing.T) {
+		sc := newTestSequenceCoverage(t)
+		seen, expected := sc.next()
+		require.Zero(t, seen)
+		require.Zero(t, expected)
+	})
+
+	t.Run("complete sequence", func(t *testing.T) {
+		sc := newTestSequenceCoverage(t)
+		// the messages of an event share its sequence number
+		observeAll(sc, 100, 100, 101, 102, 102, 102, 103)
+		seen, expected := sc.next()
+		require.Equal(t, int64(4), seen)
+		require.Equal(t, int64(4), expected)
+	})
+
+	t.Run("gaps within and across intervals", func(t *testing.T) {
+		sc := newTestSequenceCoverage(t)
+		observeAll(sc, 10, 11, 14)
+		seen, expected := sc.next()
+		require.Equal(t, int64(3), seen)
+		require.Equal(t, int64(5), expected)
+
+		observeAll(sc, 16, 17)
+		seen, expected = sc.next()
+		require.Equal(t, int64(2), seen)
+		require.Equal(t, int64(3), expected)
+
+		seen, expected = sc.next()
+		require.Zero(t, seen)
+		require.Zero(t, expected)
+	})
+
+	t.Run("late sequence numbers of a previous interval are ignored", func(t *testing.T) {
+		sc := newTestSequenceCoverage(t)
+		observeAll(sc, 1, 3)
+		sc.next()
+
+		observeAll(sc, 2, 4)
+		seen, expected := sc.next()
+		require.Equal(t, int64(1), seen)
+		require.Equal(t, int64(1), expected)
+	})
+
+	t.Run("messages without a sequence number are ignored", func(t *testing.T) {
+		sc := newTestSequenceCoverage(t)
+		observeAll(sc, 0, 5, 0, 6)
+		seen, expected := sc.next()
+		require.Equal(t, int64(2), seen)
+		require.Equal(t, int64(2), expected)
+		sc.report(context.Background())
+	})
+}
+
+func TestCoverageValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*AuditdReceiverConfig)
+	cfg.Coverage.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.Coverage.Interval = 0
+	require.ErrorContains(t, cfg.Validate(), "coverage.interval")
+}
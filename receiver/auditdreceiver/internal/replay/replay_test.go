@@ -0,0 +1,152 @@
+//go:build linux
+// +build linux
+
+package replay
+
+import (
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-libaudit/v2"
+	"github.com/elastic/go-libaudit/v2/auparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		expected *libaudit.RawAuditMessage
+		err      string
+	}{
+		{
+			name: "named type",
+			line: `type=SYSCALL msg=audit(1717412472.402:2385): arch=c000003e syscall=257 key="identity"`,
+			expected: &libaudit.RawAuditMessage{
+				Type: auparse.AUDIT_SYSCALL,
+				Data: []byte(`audit(1717412472.402:2385): arch=c000003e syscall=257 key="identity"`),
+			},
+		},
+		{
+			name: "unknown type",
+			line: `type=UNKNOWN[3001] msg=audit(1717412500.001:2391): res=1`,
+			expected: &libaudit.RawAuditMessage{
+				Type: auparse.AuditMessageType(3001),
+				Data: []byte(`audit(1717412500.001:2391): res=1`),
+			},
+		},
+		{
+			name: "numeric type",
+			line: `type=1300 msg=audit(1717412500.002:2392): syscall=59`,
+			expected: &libaudit.RawAuditMessage{
+				Type: auparse.AUDIT_SYSCALL,
+				Data: []byte(`audit(1717412500.002:2392): syscall=59`),
+			},
+		},
+		{
+			name: "missing type",
+			line: `msg=audit(1717412500.002:2392): syscall=59`,
+			err:  `missing "type="`,
+		},
+		{
+			name: "missing message",
+			line: `type=SYSCALL audit(1717412500.002:2392): syscall=59`,
+			err:  `missing "msg="`,
+		},
+		{
+			name: "invalid type",
+			line: `type=NOT_A_TYPE msg=audit(1717412500.002:2392): syscall=59`,
+			err:  `invalid message type "NOT_A_TYPE"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg, err := ParseLine(tt.line)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, msg)
+		})
+	}
+}
+
+func TestRead(t *testing.T) {
+	messages, err := Read(strings.NewReader(`# recorded on a test host
+
+type=LOGIN msg=audit(1717412467.131:2384): pid=4312 uid=0 auid=1000 ses=7 res=1
+type=EOE msg=audit(1717412472.402:2385):
+`))
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+	assert.Equal(t, auparse.AUDIT_LOGIN, messages[0].Type)
+	assert.Equal(t, auparse.AUDIT_EOE, messages[1].Type)
+
+	_, err = Read(strings.NewReader("type=LOGIN msg=audit(1717412467.131:2384):\nnot a message\n"))
+	require.ErrorContains(t, err, "line 2")
+}
+
+func TestLoad(t *testing.T) {
+	messages, err := Load("../../testdata/replay/ssh_login.log")
+	require.NoError(t, err)
+	assert.Len(t, messages, 10)
+
+	_, err = Load("../../testdata/replay/missing.log")
+	require.Error(t, err)
+}
+
+func TestClientReceive(t *testing.T) {
+	c := NewClient([]*libaudit.RawAuditMessage{
+		{Type: auparse.AUDIT_LOGIN, Data: []byte("audit(1717412467.131:2384): res=1")},
+	})
+
+	msg, err := c.Receive(false)
+	require.NoError(t, err)
+	assert.Equal(t, auparse.AUDIT_LOGIN, msg.Type)
+	assert.True(t, c.Replayed())
+
+	_, err = c.Receive(true)
+	require.ErrorIs(t, err, syscall.EAGAIN)
+
+	received := make(chan error)
+	go func() {
+		_, err := c.Receive(false)
+		received <- err
+	}()
+	select {
+	case <-received:
+		t.Fatal("Receive must block once the messages are replayed")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	require.NoError(t, c.Close())
+	require.ErrorIs(t, <-received, ErrClosed)
+	require.NoError(t, c.Close())
+	_, err = c.Receive(false)
+	require.ErrorIs(t, err, ErrClosed)
+}
+
+func TestClientControl(t *testing.T) {
+	c := NewClient(nil)
+
+	status, err := c.GetStatus()
+	require.NoError(t, err)
+	assert.Zero(t, status.Enabled)
+
+	require.NoError(t, c.SetEnabled(true, libaudit.WaitForReply))
+	require.NoError(t, c.SetRateLimit(100, libaudit.NoWait))
+	require.NoError(t, c.SetPID(libaudit.NoWait))
+	require.NoError(t, c.AddRule([]byte("rule")))
+
+	status, err = c.GetStatus()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), status.Enabled)
+	assert.Equal(t, uint32(100), status.RateLimit)
+	assert.NotZero(t, status.PID)
+	assert.True(t, c.PIDSet())
+	assert.Equal(t, [][]byte{[]byte("rule")}, c.Rules())
+}
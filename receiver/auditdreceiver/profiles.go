@@ -0,0 +1,88 @@
+package auditdreceiver
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	// PROFILE_MINIMAL watches the identity and privilege configuration files only.
+	PROFILE_MINIMAL = "minimal"
+	// PROFILE_DETECTION adds the rules commonly used to detect intrusions, e.g. program executions and kernel
+	// module loading, with self limits protecting the host from their volume.
+	PROFILE_DETECTION = "detection"
+	// PROFILE_COMPLIANCE adds the rules commonly required by compliance benchmarks, e.g. time, permission and
+	// audit configuration changes, at full fidelity and with the completeness of the audit trail reported.
+	PROFILE_COMPLIANCE = "compliance"
+)
+
+// identityRules watch the changes to the users, groups and privileges.
+var identityRules = []string{
+	"-w /etc/passwd -p wa -k identity",
+	"-w /etc/shadow -p wa -k identity",
+	"-w /etc/group -p wa -k identity",
+	"-w /etc/gshadow -p wa -k identity",
+	"-w /etc/sudoers -p wa -k privileges",
+	"-w /etc/sudoers.d -p wa -k privileges",
+}
+
+// profiles are the presets of the profiles, applied to the default configuration before the rest of the
+// configuration.
+var profiles = map[string]func(cfg *AuditdReceiverConfig){
+	PROFILE_MINIMAL: func(cfg *AuditdReceiverConfig) {
+		cfg.Rules = slices.Clone(identityRules)
+	},
+	PROFILE_DETECTION: func(cfg *AuditdReceiverConfig) {
+		cfg.Rules = append(slices.Clone(identityRules),
+			"-w /etc/ssh/sshd_config -p wa -k sshd",
+			"-a always,exit -F arch=b64 -S execve -k exec",
+			"-a always,exit -F arch=b64 -S init_module,finit_module,delete_module -k modules",
+			"-a always,exit -F arch=b64 -S ptrace -k tracing",
+		)
+		// program executions are voluminous on busy hosts
+		cfg.SelfLimits.Enabled = true
+		cfg.SelfLimits.MaxQueueDepth = 4096
+		cfg.SelfLimits.DegradedRateLimit = 1000
+	},
+	PROFILE_COMPLIANCE: func(cfg *AuditdReceiverConfig) {
+		cfg.Rules = append(slices.Clone(identityRules),
+			"-w /etc/audit -p wa -k audit-config",
+			"-w /etc/localtime -p wa -k time-change",
+			"-a always,exit -F arch=b64 -S adjtimex,settimeofday,clock_settime -k time-change",
+			"-a always,exit -F arch=b64 -S fchmod,fchmodat,fchown,fchownat,setxattr,removexattr -F auid>=1000 -F auid!=4294967295 -k perm-mod",
+			"-a always,exit -F arch=b64 -S unlinkat,renameat -F auid>=1000 -F auid!=4294967295 -k delete",
+			"-a always,exit -F arch=b64 -S mount -F auid>=1000 -F auid!=4294967295 -k mounts",
+		)
+		// the audit trail is expected to be complete, it is never sampled
+		cfg.SelfLimits.Enabled = false
+		cfg.Coverage.Enabled = true
+		cfg.Coverage.Interval = 5 * time.Minute
+	},
+}
+
+func profileNames() string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// Unmarshal applies the preset of the configured profile before the rest of the configuration, so that every
+// setting of the profile can be overridden.
+func (cfg *AuditdReceiverConfig) Unmarshal(conf *confmap.Conf) error {
+	if profile, ok := conf.Get("profile").(string); ok && profile != "" {
+		preset, found := profiles[profile]
+		if !found {
+			return fmt.Errorf("unknown 'profile' %q, must be one of: %v", profile, profileNames())
+		}
+		preset(cfg)
+	}
+	return conf.Unmarshal(cfg)
+}
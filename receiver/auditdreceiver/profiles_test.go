@@ -0,0 +1,73 @@
+//go:build linux
+// +build linux
+
+package auditdreceiver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-libaudit/v2/rule"
+	"github.com/elastic/go-libaudit/v2/rule/flags"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestProfileRules(t *testing.T) {
+	for name, preset := range profiles {
+		t.Run(name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*AuditdReceiverConfig)
+			preset(cfg)
+			require.NotEmpty(t, cfg.Rules)
+			for _, rawRule := range cfg.Rules {
+				r, err := flags.Parse(rawRule)
+				require.NoError(t, err, rawRule)
+				_, err = rule.Build(r)
+				require.NoError(t, err, rawRule)
+			}
+			cfg.Profile = name
+			require.NoError(t, cfg.Validate())
+		})
+	}
+}
+
+func TestProfileUnmarshal(t *testing.T) {
+	cm, err := confmaptest.LoadConf(filepath.Join("testdata", "config.yaml"))
+	require.NoError(t, err)
+
+	load := func(t *testing.T, id string) (*AuditdReceiverConfig, error) {
+		sub, err := cm.Sub(component.MustNewIDWithName(Type.String(), id).String())
+		require.NoError(t, err)
+		cfg := createDefaultConfig().(*AuditdReceiverConfig)
+		return cfg, sub.Unmarshal(cfg)
+	}
+
+	t.Run("applies the preset", func(t *testing.T) {
+		cfg, err := load(t, "detection")
+		require.NoError(t, err)
+		expected := createDefaultConfig().(*AuditdReceiverConfig)
+		profiles[PROFILE_DETECTION](expected)
+		expected.Profile = PROFILE_DETECTION
+		require.Equal(t, expected, cfg)
+	})
+
+	t.Run("overrides the preset", func(t *testing.T) {
+		cfg, err := load(t, "override")
+		require.NoError(t, err)
+		require.Equal(t, []string{"-w /etc/hosts -p wa -k hosts"}, cfg.Rules)
+		require.True(t, cfg.Coverage.Enabled)
+		require.Equal(t, time.Minute, cfg.Coverage.Interval)
+		require.False(t, cfg.SelfLimits.Enabled)
+	})
+
+	t.Run("rejects an unknown profile", func(t *testing.T) {
+		_, err := load(t, "unknown")
+		require.ErrorContains(t, err, `unknown 'profile' "paranoid", must be one of: compliance, detection, minimal`)
+
+		cfg := createDefaultConfig().(*AuditdReceiverConfig)
+		cfg.Profile = "paranoid"
+		require.ErrorContains(t, cfg.Validate(), "unknown 'profile'")
+	})
+}
@@ -0,0 +1,133 @@
+//go:build linux
+// +build linux
+
+package auditdreceiver
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-libaudit/v2/auparse"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.uber.org/zap"
+
+	"github.com/olandr/opentelemetry-collector-contrib/receiver/auditdreceiver/internal/replay"
+)
+
+// startReplay starts a receiver replaying the recorded messages of fixture, and
+// returns once all the messages are received.
+func startReplay(t *testing.T, cfg *AuditdReceiverConfig, fixture string) (*replay.Client, *consumertest.LogsSink) {
+	messages, err := replay.Load(filepath.Join("testdata", "replay", fixture))
+	require.NoError(t, err)
+	client := replay.NewClient(messages)
+
+	sink := new(consumertest.LogsSink)
+	settings := receivertest.NewNopSettings(component.MustNewType("auditd"))
+	settings.Logger = zap.NewNop()
+	aud, err := newAuditd(cfg, sink, settings)
+	require.NoError(t, err)
+	aud.newClient = func() (auditClient, error) {
+		return client, nil
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, client.Close())
+		require.NoError(t, aud.Shutdown(context.Background()))
+	})
+	require.NoError(t, aud.Start(ctx, componenttest.NewNopHost()))
+	require.Eventually(t, client.Replayed, time.Second, time.Millisecond)
+	return client, sink
+}
+
+// replayedRecords returns the log records received by sink once they stop changing.
+func replayedRecords(t *testing.T, sink *consumertest.LogsSink, expected int) []plog.LogRecord {
+	require.Eventually(t, func() bool {
+		return sink.LogRecordCount() == expected
+	}, time.Second, time.Millisecond)
+	var records []plog.LogRecord
+	for record := range logsIterator(sink.AllLogs()) {
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestReplaySSHLogin(t *testing.T) {
+	cfg := createDefaultConfig().(*AuditdReceiverConfig)
+	cfg.Rules = []string{"-w /etc/passwd -p wa -k identity"}
+	client, sink := startReplay(t, cfg, "ssh_login.log")
+
+	assert.Len(t, client.Rules(), 1)
+	assert.True(t, client.PIDSet())
+	status, err := client.GetStatus()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), status.Enabled, "auditing must be enabled")
+
+	// the LOGIN message type is below the range of forwarded audit messages
+	records := replayedRecords(t, sink, 9)
+	expected := []struct {
+		messageType auparse.AuditMessageType
+		id          int64
+		seconds     int64
+	}{
+		{auparse.AUDIT_USER_AUTH, 2381, 1717412467},
+		{auparse.AUDIT_USER_ACCT, 2382, 1717412467},
+		{auparse.AUDIT_CRED_ACQ, 2383, 1717412467},
+		{auparse.AUDIT_SYSCALL, 2385, 1717412472},
+		{auparse.AUDIT_CWD, 2385, 1717412472},
+		{auparse.AUDIT_PATH, 2385, 1717412472},
+		{auparse.AUDIT_PATH, 2385, 1717412472},
+		{auparse.AUDIT_PROCTITLE, 2385, 1717412472},
+		{auparse.AUDIT_EOE, 2385, 1717412472},
+	}
+	for i, record := range records {
+		typ, _ := record.Attributes().Get("type")
+		assert.Equal(t, expected[i].messageType.String(), typ.Str())
+		id, _ := record.Attributes().Get("id")
+		assert.Equal(t, expected[i].id, id.Int())
+		assert.Equal(t, expected[i].seconds, record.Timestamp().AsTime().Unix())
+		assert.Equal(t, plog.SeverityNumberInfo, record.SeverityNumber())
+	}
+	data, _ := records[5].Attributes().Get("data")
+	assert.Contains(t, data.Str(), `name="/etc/"`)
+}
+
+func TestReplayCoalescedEvents(t *testing.T) {
+	cfg := createDefaultConfig().(*AuditdReceiverConfig)
+	cfg.Coalesce.Enabled = true
+	_, sink := startReplay(t, cfg, "ssh_login.log")
+
+	// the user space messages are events on their own, the messages of the system call are coalesced
+	require.Eventually(t, func() bool {
+		return len(sink.AllLogs()) == 4
+	}, time.Second, time.Millisecond)
+	event := sink.AllLogs()[3]
+	require.Equal(t, 6, event.LogRecordCount())
+	records := event.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < records.Len(); i++ {
+		id, _ := records.At(i).Attributes().Get("id")
+		assert.Equal(t, int64(2385), id.Int())
+	}
+	typ, _ := records.At(records.Len() - 1).Attributes().Get("type")
+	assert.Equal(t, auparse.AUDIT_EOE.String(), typ.Str())
+}
+
+func TestReplayControlMessages(t *testing.T) {
+	cfg := createDefaultConfig().(*AuditdReceiverConfig)
+	cfg.Coverage.Enabled = true
+	client, sink := startReplay(t, cfg, "control.log")
+
+	records := replayedRecords(t, sink, 1)
+	typ, _ := records[0].Attributes().Get("type")
+	assert.Equal(t, auparse.AUDIT_SYSCALL.String(), typ.Str())
+	assert.True(t, client.Replayed())
+}
@@ -0,0 +1,226 @@
+//go:build linux
+// +build linux
+
+package auditdreceiver
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/elastic/go-libaudit/v2"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+
+	"github.com/olandr/opentelemetry-collector-contrib/receiver/auditdreceiver/internal/metadata"
+)
+
+// auditController is the subset of the audit client used to inspect and tune the kernel.
+// A dedicated client is used so the replies do not interleave with the received events.
+type auditController interface {
+	GetStatus() (*libaudit.AuditStatus, error)
+	SetRateLimit(perSecondLimit uint32, wm libaudit.WaitMode) error
+	Close() error
+}
+
+// selfLimiter monitors the pressure on the receiver and degrades it while the configured
+// thresholds are exceeded.
+type selfLimiter struct {
+	cfg     SelfLimitsConfig
+	control auditController
+	logger  *zap.Logger
+	cpuTime func() (time.Duration, error)
+
+	degraded atomic.Bool
+	sampled  atomic.Uint64
+	// lastDropped is the sequence number of the last event dropped, only used by the receiving goroutine.
+	lastDropped int64
+
+	lastCheck     time.Time
+	lastCPU       time.Duration
+	calmChecks    int
+	degradedSince time.Time
+	origRateLimit uint32
+	done          chan struct{}
+	wg            sync.WaitGroup
+
+	degradedGauge    metric.Int64Gauge
+	degradedPeriods  metric.Int64Counter
+	degradedDuration metric.Float64Counter
+	droppedEvents    metric.Int64Counter
+}
+
+func newSelfLimiter(cfg SelfLimitsConfig, control auditController, logger *zap.Logger, mp metric.MeterProvider) (*selfLimiter, error) {
+	meter := mp.Meter(metadata.ScopeName)
+	sl := &selfLimiter{
+		cfg:     cfg,
+		control: control,
+		logger:  logger,
+		cpuTime: processCPUTime,
+	}
+	var err error
+	if sl.degradedGauge, err = meter.Int64Gauge("otelcol_auditd_degraded",
+		metric.WithDescription("Whether the receiver is degraded because of its self limits (1) or not (0)"),
+		metric.WithUnit("1")); err != nil {
+		return nil, err
+	}
+	if sl.degradedPeriods, err = meter.Int64Counter("otelcol_auditd_degraded_periods",
+		metric.WithDescription("Number of times the receiver degraded because of its self limits"),
+		metric.WithUnit("{periods}")); err != nil {
+		return nil, err
+	}
+	if sl.degradedDuration, err = meter.Float64Counter("otelcol_auditd_degraded_duration",
+		metric.WithDescription("Time spent degraded because of the self limits"),
+		metric.WithUnit("s")); err != nil {
+		return nil, err
+	}
+	if sl.droppedEvents, err = meter.Int64Counter("otelcol_auditd_sampled_out_events",
+		metric.WithDescription("Number of audit events dropped by sampling while degraded"),
+		metric.WithUnit("{events}")); err != nil {
+		return nil, err
+	}
+	return sl, nil
+}
+
+// start records the baseline usage and starts checking the pressure every check interval.
+func (sl *selfLimiter) start(ctx context.Context) {
+	sl.lastCheck = time.Now()
+	sl.lastCPU, _ = sl.cpuTime()
+	sl.degradedGauge.Record(ctx, 0)
+	done := make(chan struct{})
+	sl.done = done
+	sl.wg.Add(1)
+	go func() {
+		defer sl.wg.Done()
+		ticker := time.NewTicker(sl.cfg.CheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case now := <-ticker.C:
+				sl.check(context.Background(), now)
+			}
+		}
+	}()
+}
+
+// shutdown stops the checks and restores full fidelity once the last check is over.
+func (sl *selfLimiter) shutdown(ctx context.Context) {
+	if sl.done != nil {
+		close(sl.done)
+		sl.wg.Wait()
+		sl.done = nil
+	}
+	if sl.degraded.Load() {
+		sl.restore(ctx, time.Now())
+	}
+	if err := sl.control.Close(); err != nil {
+		sl.logger.Warn("failed to close audit control client", zap.Error(err))
+	}
+}
+
+// keep reports whether a received message should be forwarded. While degraded, the decision is
+// taken once per audit event from its sequence number, so that all the messages of an event are
+// kept or dropped together. The messages without a sequence number are sampled on their own.
+func (sl *selfLimiter) keep(ctx context.Context, id int64) bool {
+	if !sl.degraded.Load() {
+		return true
+	}
+	n := uint64(id)
+	if id <= 0 {
+		n = sl.sampled.Add(1)
+	}
+	if n%uint64(sl.cfg.SampleRatio) == 0 {
+		return true
+	}
+	// The messages of an event are received one after the other, count the event once.
+	if id <= 0 || id != sl.lastDropped {
+		sl.droppedEvents.Add(ctx, 1)
+		sl.lastDropped = id
+	}
+	return false
+}
+
+func (sl *selfLimiter) check(ctx context.Context, now time.Time) {
+	pressure := false
+
+	if sl.cfg.MaxCPUPercent > 0 {
+		cpu, err := sl.cpuTime()
+		if err != nil {
+			sl.logger.Warn("failed to get process cpu usage", zap.Error(err))
+		} else {
+			if elapsed := now.Sub(sl.lastCheck); elapsed > 0 {
+				percent := 100 * float64(cpu-sl.lastCPU) / float64(elapsed)
+				if percent > sl.cfg.MaxCPUPercent {
+					sl.logger.Debug("cpu usage above self limit", zap.Float64("percent", percent))
+					pressure = true
+				}
+			}
+			sl.lastCPU = cpu
+		}
+	}
+	sl.lastCheck = now
+
+	status, err := sl.control.GetStatus()
+	if err != nil {
+		sl.logger.Warn("failed to get audit status", zap.Error(err))
+	} else if sl.cfg.MaxQueueDepth > 0 && status.Backlog > sl.cfg.MaxQueueDepth {
+		sl.logger.Debug("audit backlog above self limit", zap.Uint32("backlog", status.Backlog))
+		pressure = true
+	}
+
+	switch {
+	case pressure && !sl.degraded.Load():
+		sl.degrade(ctx, now, status)
+	case pressure:
+		sl.calmChecks = 0
+	case sl.degraded.Load():
+		sl.calmChecks++
+		if sl.calmChecks >= sl.cfg.RecoveryChecks {
+			sl.restore(ctx, now)
+		}
+	}
+}
+
+func (sl *selfLimiter) degrade(ctx context.Context, now time.Time, status *libaudit.AuditStatus) {
+	sl.logger.Warn("receiver is under pressure, degrading to sampled events",
+		zap.Uint32("sample_ratio", sl.cfg.SampleRatio),
+		zap.Uint32("rate_limit", sl.cfg.DegradedRateLimit))
+	sl.calmChecks = 0
+	sl.degradedSince = now
+	if sl.cfg.DegradedRateLimit > 0 && status != nil {
+		sl.origRateLimit = status.RateLimit
+		if err := sl.control.SetRateLimit(sl.cfg.DegradedRateLimit, libaudit.NoWait); err != nil {
+			sl.logger.Warn("failed to lower audit rate limit", zap.Error(err))
+		}
+	}
+	sl.degraded.Store(true)
+	sl.degradedGauge.Record(ctx, 1)
+	sl.degradedPeriods.Add(ctx, 1)
+}
+
+func (sl *selfLimiter) restore(ctx context.Context, now time.Time) {
+	sl.logger.Info("pressure subsided, restoring full fidelity", zap.Duration("degraded_for", now.Sub(sl.degradedSince)))
+	if sl.cfg.DegradedRateLimit > 0 {
+		if err := sl.control.SetRateLimit(sl.origRateLimit, libaudit.NoWait); err != nil {
+			sl.logger.Warn("failed to restore audit rate limit", zap.Error(err))
+		}
+	}
+	sl.degraded.Store(false)
+	sl.calmChecks = 0
+	sl.degradedGauge.Record(ctx, 0)
+	sl.degradedDuration.Add(ctx, now.Sub(sl.degradedSince).Seconds())
+}
+
+// processCPUTime returns the user and system CPU time consumed by the collector process.
+func processCPUTime() (time.Duration, error) {
+	var usage syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &usage); err != nil {
+		return 0, fmt.Errorf("getrusage: %w", err)
+	}
+	return time.Duration(usage.Utime.Nano() + usage.Stime.Nano()), nil
+}
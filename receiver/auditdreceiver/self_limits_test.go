@@ -0,0 +1,146 @@
+//go:build linux
+// +build linux
+
+package auditdreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-libaudit/v2"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/metric/noop"
+	"go.uber.org/zap"
+)
+
+type fakeController struct {
+	status     libaudit.AuditStatus
+	rateLimits []uint32
+	closed     bool
+}
+
+func (f *fakeController) GetStatus() (*libaudit.AuditStatus, error) {
+	status := f.status
+	return &status, nil
+}
+
+func (f *fakeController) SetRateLimit(limit uint32, _ libaudit.WaitMode) error {
+	f.rateLimits = append(f.rateLimits, limit)
+	f.status.RateLimit = limit
+	return nil
+}
+
+func (f *fakeController) Close() error {
+	f.closed = true
+	return nil
+}
+
+func newTestSelfLimiter(t *testing.T, cfg SelfLimitsConfig, control *fakeController, cpu *time.Duration) *selfLimiter {
+	sl, err := newSelfLimiter(cfg, control, zap.NewNop(), noop.NewMeterProvider())
+	require.NoError(t, err)
+	sl.cpuTime = func() (time.Duration, error) { return *cpu, nil }
+	return sl
+}
+
+func TestSelfLimiter(t *testing.T) {
+	ctx := context.Background()
+	start := time.Unix(1700000000, 0)
+	cfg := createDefaultConfig().(*AuditdReceiverConfig).SelfLimits
+	cfg.Enabled = true
+	cfg.MaxQueueDepth = 100
+	cfg.DegradedRateLimit = 50
+	cfg.SampleRatio = 4
+	cfg.RecoveryChecks = 2
+
+	t.Run("degrades on backlog and restores after recovery checks", func(t *testing.T) {
+		control := &fakeController{status: libaudit.AuditStatus{RateLimit: 1000}}
+		cpu := time.Duration(0)
+		sl := newTestSelfLimiter(t, cfg, control, &cpu)
+		sl.lastCheck = start
+
+		sl.check(ctx, start.Add(time.Second))
+		require.False(t, sl.degraded.Load())
+
+		control.status.Backlog = 500
+		sl.check(ctx, start.Add(2*time.Second))
+		require.True(t, sl.degraded.Load())
+		require.Equal(t, []uint32{50}, control.rateLimits)
+
+		// the messages of an event are kept or dropped together
+		var kept []int64
+		for id := int64(1); id <= 8; id++ {
+			keep := sl.keep(ctx, id)
+			for range 3 {
+				require.Equal(t, keep, sl.keep(ctx, id))
+			}
+			if keep {
+				kept = append(kept, id)
+			}
+		}
+		require.Equal(t, []int64{4, 8}, kept)
+
+		control.status.Backlog = 0
+		sl.check(ctx, start.Add(3*time.Second))
+		require.True(t, sl.degraded.Load())
+		sl.check(ctx, start.Add(4*time.Second))
+		require.False(t, sl.degraded.Load())
+		require.Equal(t, []uint32{50, 1000}, control.rateLimits)
+		require.True(t, sl.keep(ctx, 1))
+	})
+
+	t.Run("degrades on cpu usage", func(t *testing.T) {
+		control := &fakeController{}
+		cpu := time.Duration(0)
+		sl := newTestSelfLimiter(t, cfg, control, &cpu)
+		sl.lastCheck = start
+
+		cpu = 200 * time.Millisecond
+		sl.check(ctx, start.Add(time.Second))
+		require.False(t, sl.degraded.Load())
+
+		cpu += 900 * time.Millisecond
+		sl.check(ctx, start.Add(2*time.Second))
+		require.True(t, sl.degraded.Load())
+
+		sl.shutdown(ctx)
+		require.False(t, sl.degraded.Load())
+		require.True(t, control.closed)
+	})
+}
+
+func TestSelfLimiterShutdown(t *testing.T) {
+	cfg := createDefaultConfig().(*AuditdReceiverConfig).SelfLimits
+	cfg.Enabled = true
+	cfg.CheckInterval = time.Millisecond
+	cfg.MaxCPUPercent = 0
+	cfg.MaxQueueDepth = 100
+	cfg.DegradedRateLimit = 50
+	control := &fakeController{status: libaudit.AuditStatus{RateLimit: 1000, Backlog: 500}}
+	cpu := time.Duration(0)
+	sl := newTestSelfLimiter(t, cfg, control, &cpu)
+
+	sl.start(t.Context())
+	require.Eventually(t, sl.degraded.Load, time.Second, time.Millisecond)
+
+	// the rate limit is restored once the checks are over
+	sl.shutdown(t.Context())
+	require.False(t, sl.degraded.Load())
+	require.Equal(t, uint32(1000), control.status.RateLimit)
+	require.True(t, control.closed)
+}
+
+func TestSelfLimitsValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*AuditdReceiverConfig)
+	require.NoError(t, cfg.Validate())
+
+	cfg.SelfLimits.Enabled = true
+	require.NoError(t, cfg.Validate())
+
+	cfg.SelfLimits.SampleRatio = 0
+	require.ErrorContains(t, cfg.Validate(), "sample_ratio")
+
+	cfg.SelfLimits.SampleRatio = 1
+	cfg.SelfLimits.MaxCPUPercent = 0
+	require.ErrorContains(t, cfg.Validate(), "at least one of")
+}
@@ -18,6 +18,7 @@ import (
 	conventions "go.opentelemetry.io/otel/semconv/v1.27.0"
 	"go.uber.org/zap"
 
+	"github.com/open-telemetry/opentelemetry-collector-contrib/pkg/translator/azurelogs"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/azureeventhubreceiver/internal/metadata"
 )
 
@@ -49,7 +50,23 @@ type azureMetricRecord struct {
 	Average    float64 `json:"average"`
 }
 
-func newAzureResourceMetricsUnmarshaler(buildInfo component.BuildInfo, logger *zap.Logger, timeFormat []string) eventMetricsUnmarshaler {
+// azureResourceMetricsEventUnmarshaler translates the metric records with
+// the azurelogs translator, used when the
+// receiver.azureeventhubreceiver.UseAzureLogsMetrics feature gate is enabled.
+type azureResourceMetricsEventUnmarshaler struct {
+	unmarshaler *azurelogs.ResourceMetricsUnmarshaler
+}
+
+func newAzureResourceMetricsUnmarshaler(buildInfo component.BuildInfo, logger *zap.Logger, useAzureLogs bool, timeFormat []string) eventMetricsUnmarshaler {
+	if useAzureLogs {
+		return azureResourceMetricsEventUnmarshaler{
+			unmarshaler: &azurelogs.ResourceMetricsUnmarshaler{
+				Version:     buildInfo.Version,
+				Logger:      logger,
+				TimeFormats: timeFormat,
+			},
+		}
+	}
 	return azureResourceMetricsUnmarshaler{
 		buildInfo:  buildInfo,
 		logger:     logger,
@@ -167,3 +184,9 @@ func asTimestamp(s string, formats []string) (pcommon.Timestamp, error) {
 	}
 	return 0, err
 }
+
+// UnmarshalMetrics transforms the Azure metric records of the event into
+// OpenTelemetry metrics.
+func (r azureResourceMetricsEventUnmarshaler) UnmarshalMetrics(event *eventhub.Event) (pmetric.Metrics, error) {
+	return r.unmarshaler.UnmarshalMetrics(event.Data)
+}
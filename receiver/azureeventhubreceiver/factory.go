@@ -9,6 +9,7 @@ import (
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/featuregate"
 	"go.opentelemetry.io/collector/pipeline"
 	"go.opentelemetry.io/collector/receiver"
 
@@ -18,6 +19,14 @@ import (
 
 var errUnexpectedConfigurationType = errors.New("failed to cast configuration to azure event hub config")
 
+var useAzureLogsMetricsGate = featuregate.GlobalRegistry().MustRegister(
+	"receiver.azureeventhubreceiver.UseAzureLogsMetrics",
+	featuregate.StageAlpha,
+	featuregate.WithRegisterDescription("When enabled, the Azure metric records are translated by the azurelogs translator, "+
+		"which groups the metrics per resource and turns the total and count aggregations into delta sums"),
+	featuregate.WithRegisterFromVersion("v0.131.0"),
+)
+
 type eventhubReceiverFactory struct {
 	receivers *sharedcomponent.SharedComponents
 }
@@ -117,7 +126,7 @@ func (f *eventhubReceiverFactory) getReceiver(
 				metricsUnmarshaler = nil
 				err = errors.New("raw format not supported for Metrics")
 			} else {
-				metricsUnmarshaler = newAzureResourceMetricsUnmarshaler(settings.BuildInfo, settings.Logger, receiverConfig.TimeFormats.Metrics)
+				metricsUnmarshaler = newAzureResourceMetricsUnmarshaler(settings.BuildInfo, settings.Logger, useAzureLogsMetricsGate.IsEnabled(), receiverConfig.TimeFormats.Metrics)
 			}
 		case pipeline.SignalTraces:
 			if logFormat(receiverConfig.Format) == rawLogFormat {
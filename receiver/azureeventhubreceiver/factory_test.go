@@ -5,10 +5,16 @@ package azureeventhubreceiver // import "github.com/open-telemetry/opentelemetry
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
+	eventhub "github.com/Azure/azure-event-hubs-go/v3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/featuregate"
 	"go.opentelemetry.io/collector/receiver/receivertest"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/azureeventhubreceiver/internal/metadata"
@@ -32,3 +38,41 @@ func Test_NewMetricsReceiver(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, receiver)
 }
+
+func Test_NewMetricsReceiverUseAzureLogsMetrics(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "metric-records.json"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		enabled           bool
+		expectedResources int
+		expectedScope     string
+	}{
+		{enabled: false, expectedResources: 1, expectedScope: ""},
+		{enabled: true, expectedResources: 2, expectedScope: "otelcol/azureresourcemetrics"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("enabled=%v", tt.enabled), func(t *testing.T) {
+			require.NoError(t, featuregate.GlobalRegistry().Set(useAzureLogsMetricsGate.ID(), tt.enabled))
+			defer func() {
+				require.NoError(t, featuregate.GlobalRegistry().Set(useAzureLogsMetricsGate.ID(), false))
+			}()
+
+			f := NewFactory()
+			cfg := f.CreateDefaultConfig().(*Config)
+			cfg.Connection = "Endpoint=sb://namespace.servicebus.windows.net/;SharedAccessKeyName=RootManageSharedAccessKey;SharedAccessKey=superSecret1234=;EntityPath=hubName"
+			sink := new(consumertest.MetricsSink)
+			receiver, err := f.CreateMetrics(context.Background(), receivertest.NewNopSettings(metadata.Type), cfg, sink)
+			require.NoError(t, err)
+
+			require.NoError(t, receiver.(dataConsumer).consume(context.Background(), &eventhub.Event{Data: data}))
+			require.Len(t, sink.AllMetrics(), 1)
+			metrics := sink.AllMetrics()[0]
+			require.Equal(t, tt.expectedResources, metrics.ResourceMetrics().Len())
+			resourceMetrics := metrics.ResourceMetrics().At(0)
+			_, hasSDKName := resourceMetrics.Resource().Attributes().Get("telemetry.sdk.name")
+			assert.Equal(t, !tt.enabled, hasSDKName)
+			assert.Equal(t, tt.expectedScope, resourceMetrics.ScopeMetrics().At(0).Scope().Name())
+		})
+	}
+}
@@ -0,0 +1,167 @@
+package filewatchreceiver
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/olandr/notify"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	// ATTRIB_OPERATION is the operation reported for the changes of the metadata of a file, e.g. its mode or owner.
+	ATTRIB_OPERATION = "attrib"
+	// MODE_BEFORE_ATTRIBUTE is the log attribute holding the permissions of a file before an attrib event, in octal.
+	MODE_BEFORE_ATTRIBUTE = "file.mode.before"
+	// MODE_AFTER_ATTRIBUTE is the log attribute holding the permissions of a file after an attrib event, in octal.
+	MODE_AFTER_ATTRIBUTE = "file.mode.after"
+	// OWNER_ID_BEFORE_ATTRIBUTE is the log attribute holding the user id of the owner of a file before an attrib event.
+	OWNER_ID_BEFORE_ATTRIBUTE = "file.owner.id.before"
+	// OWNER_ID_AFTER_ATTRIBUTE is the log attribute holding the user id of the owner of a file after an attrib event.
+	OWNER_ID_AFTER_ATTRIBUTE = "file.owner.id.after"
+	// GROUP_ID_BEFORE_ATTRIBUTE is the log attribute holding the group id of a file before an attrib event.
+	GROUP_ID_BEFORE_ATTRIBUTE = "file.group.id.before"
+	// GROUP_ID_AFTER_ATTRIBUTE is the log attribute holding the group id of a file after an attrib event.
+	GROUP_ID_AFTER_ATTRIBUTE = "file.group.id.after"
+)
+
+// isAttrib returns whether event only reports the change of the metadata of a file.
+func isAttrib(event notify.Event) bool {
+	return chmodEvents != 0 && event != 0 && event&^chmodEvents == 0
+}
+
+// operationOf returns the operation reported for event.
+func operationOf(event notify.Event) string {
+	if isAttrib(event) {
+		return ATTRIB_OPERATION
+	}
+	return event.String()
+}
+
+// fileAttributes are the permissions and the ownership of a file.
+type fileAttributes struct {
+	mode          fs.FileMode
+	uid, gid      uint32
+	has_ownership bool
+}
+
+// attribTracker remembers the attributes of the files, so that the attrib events carry the attributes from before
+// the change. Only the files under the include roots watching the attrib events are tracked. It is not safe for
+// concurrent use.
+type attribTracker struct {
+	attributes map[string]fileAttributes
+	stat       func(path string) (fileAttributes, bool)
+	// tracked returns whether the attributes of path are tracked.
+	tracked func(path string) bool
+}
+
+func newAttribTracker(tracked func(path string) bool) *attribTracker {
+	return &attribTracker{
+		attributes: make(map[string]fileAttributes),
+		stat:       statAttributes,
+		tracked:    tracked,
+	}
+}
+
+// record remembers the current attributes of path, or forgets them when it no longer exists. ok is false when path
+// does not exist or is not tracked.
+func (a *attribTracker) record(path string) (attrs fileAttributes, ok bool) {
+	if !a.tracked(path) {
+		return attrs, false
+	}
+	if attrs, ok = a.stat(path); !ok {
+		a.forget(path)
+		return attrs, false
+	}
+	a.attributes[path] = attrs
+	return attrs, true
+}
+
+// forget forgets the attributes of path, and of the paths under it when it was a directory, e.g. one moved away
+// whose files get no event of their own.
+func (a *attribTracker) forget(path string) {
+	attrs, ok := a.attributes[path]
+	if !ok {
+		return
+	}
+	delete(a.attributes, path)
+	if !attrs.mode.IsDir() {
+		return
+	}
+	prefix := path + string(filepath.Separator)
+	for p := range a.attributes {
+		if strings.HasPrefix(p, prefix) {
+			delete(a.attributes, p)
+		}
+	}
+}
+
+// prune forgets the attributes of the paths no longer tracked, e.g. once their watch is removed.
+func (a *attribTracker) prune() {
+	for p := range a.attributes {
+		if !a.tracked(p) {
+			delete(a.attributes, p)
+		}
+	}
+}
+
+// annotate adds the attributes of path, before and after the change, to its log records in the last logs, the logs of
+// the event itself, when event is an attrib event. The attributes of the paths are remembered the first time they are
+// seen, and on their creations and attrib events, and forgotten on their removals and renames away.
+func (a *attribTracker) annotate(logs []plog.Logs, path string, event notify.Event) {
+	before, known := a.attributes[path]
+	if !isAttrib(event) {
+		if !known || event&(creationEvents|removalEvents) != 0 {
+			a.record(path)
+		}
+		return
+	}
+	after, ok := a.record(path)
+	if !ok || len(logs) == 0 {
+		return
+	}
+	last := logs[len(logs)-1]
+	for i := 0; i < last.ResourceLogs().Len(); i++ {
+		resourceLogs := last.ResourceLogs().At(i)
+		for j := 0; j < resourceLogs.ScopeLogs().Len(); j++ {
+			records := resourceLogs.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+				if p, found := record.Attributes().Get("path"); !found || p.Str() != path {
+					continue
+				}
+				attrs := record.Attributes()
+				attrs.PutStr(MODE_AFTER_ATTRIBUTE, fmt.Sprintf("%04o", after.mode.Perm()))
+				if after.has_ownership {
+					attrs.PutStr(OWNER_ID_AFTER_ATTRIBUTE, strconv.FormatUint(uint64(after.uid), 10))
+					attrs.PutStr(GROUP_ID_AFTER_ATTRIBUTE, strconv.FormatUint(uint64(after.gid), 10))
+				}
+				if !known {
+					continue
+				}
+				attrs.PutStr(MODE_BEFORE_ATTRIBUTE, fmt.Sprintf("%04o", before.mode.Perm()))
+				if before.has_ownership {
+					attrs.PutStr(OWNER_ID_BEFORE_ATTRIBUTE, strconv.FormatUint(uint64(before.uid), 10))
+					attrs.PutStr(GROUP_ID_BEFORE_ATTRIBUTE, strconv.FormatUint(uint64(before.gid), 10))
+				}
+			}
+		}
+	}
+}
+
+func statAttributes(path string) (fileAttributes, bool) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return fileAttributes{}, false
+	}
+	return attributesOf(fi), true
+}
+
+func attributesOf(fi os.FileInfo) fileAttributes {
+	ownership, ok := ownershipOf(fi)
+	return fileAttributes{mode: fi.Mode(), uid: ownership.uid, gid: ownership.gid, has_ownership: ok}
+}
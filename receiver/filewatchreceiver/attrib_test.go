@@ -0,0 +1,173 @@
+package filewatchreceiver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestOperationOf(t *testing.T) {
+	require.Equal(t, notify.Create.String(), operationOf(notify.Create))
+	if chmodEvents == 0 {
+		t.Skip("the changes of the metadata are not watched on this platform")
+	}
+	require.Equal(t, ATTRIB_OPERATION, operationOf(chmodEvents))
+	require.NotEqual(t, ATTRIB_OPERATION, operationOf(chmodEvents|notify.Create))
+}
+
+func TestAttribTracker(t *testing.T) {
+	if chmodEvents == 0 {
+		t.Skip("the changes of the metadata are not watched on this platform")
+	}
+	dir := t.TempDir()
+	ts := time.Unix(1700000000, 0)
+	a := newAttribTracker(func(path string) bool { return filepath.Base(path) != "untracked" })
+	attrib := func(path string) plog.LogRecord {
+		logs := []plog.Logs{createLogs(ts, path, ATTRIB_OPERATION)}
+		a.annotate(logs, path, chmodEvents)
+		return logs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	}
+
+	t.Run("the attributes before the change are carried", func(t *testing.T) {
+		path := filepath.Join(dir, "config")
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+		require.NoError(t, os.Chmod(path, 0o600))
+		a.annotate(nil, path, notify.Create)
+
+		require.NoError(t, os.Chmod(path, 0o644))
+		record := attrib(path)
+		requireAttr(t, record, MODE_BEFORE_ATTRIBUTE, "0600")
+		requireAttr(t, record, MODE_AFTER_ATTRIBUTE, "0644")
+		requireAttr(t, record, OWNER_ID_BEFORE_ATTRIBUTE, strconv.Itoa(os.Getuid()))
+		requireAttr(t, record, OWNER_ID_AFTER_ATTRIBUTE, strconv.Itoa(os.Getuid()))
+		requireAttr(t, record, GROUP_ID_AFTER_ATTRIBUTE, strconv.Itoa(os.Getgid()))
+
+		require.NoError(t, os.Chmod(path, 0o640))
+		record = attrib(path)
+		requireAttr(t, record, MODE_BEFORE_ATTRIBUTE, "0644")
+		requireAttr(t, record, MODE_AFTER_ATTRIBUTE, "0640")
+	})
+
+	t.Run("paths seen for the first time only carry the attributes after the change", func(t *testing.T) {
+		path := filepath.Join(dir, "unknown")
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+		require.NoError(t, os.Chmod(path, 0o600))
+		record := attrib(path)
+		requireAttr(t, record, MODE_AFTER_ATTRIBUTE, "0600")
+		_, found := record.Attributes().Get(MODE_BEFORE_ATTRIBUTE)
+		require.False(t, found)
+	})
+
+	t.Run("the writes of the paths seen before are not stat'ed", func(t *testing.T) {
+		path := filepath.Join(dir, "written")
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+		stats := 0
+		a.stat = func(path string) (fileAttributes, bool) {
+			stats++
+			return statAttributes(path)
+		}
+		defer func() { a.stat = statAttributes }()
+		a.annotate(nil, path, notify.Write)
+		a.annotate(nil, path, notify.Write)
+		require.Equal(t, 1, stats)
+	})
+
+	t.Run("removals forget the attributes", func(t *testing.T) {
+		path := filepath.Join(dir, "removed")
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+		a.annotate(nil, path, notify.Create)
+		require.Contains(t, a.attributes, path)
+		require.NoError(t, os.Remove(path))
+		a.annotate(nil, path, notify.Remove)
+		require.NotContains(t, a.attributes, path)
+	})
+
+	t.Run("renaming a directory away forgets the attributes of its files", func(t *testing.T) {
+		sub := filepath.Join(dir, "sub")
+		path := filepath.Join(sub, "file")
+		require.NoError(t, os.Mkdir(sub, 0o755))
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+		a.annotate(nil, sub, notify.Create)
+		a.annotate(nil, path, notify.Create)
+		require.Contains(t, a.attributes, path)
+		require.NoError(t, os.Rename(sub, filepath.Join(dir, "renamed")))
+		a.annotate(nil, sub, notify.Rename)
+		require.NotContains(t, a.attributes, sub)
+		require.NotContains(t, a.attributes, path)
+	})
+
+	t.Run("the paths not tracked are not remembered", func(t *testing.T) {
+		path := filepath.Join(dir, "untracked")
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+		a.annotate(nil, path, notify.Create)
+		require.NotContains(t, a.attributes, path)
+		_, ok := a.record(path)
+		require.False(t, ok)
+	})
+
+	t.Run("pruning forgets the paths no longer tracked", func(t *testing.T) {
+		path := filepath.Join(dir, "pruned")
+		require.NoError(t, os.WriteFile(path, nil, 0o600))
+		a.annotate(nil, path, notify.Create)
+		require.Contains(t, a.attributes, path)
+		tracked := a.tracked
+		defer func() { a.tracked = tracked }()
+		a.tracked = func(p string) bool { return p != path }
+		a.prune()
+		require.NotContains(t, a.attributes, path)
+	})
+}
+
+func TestAttribEvents(t *testing.T) {
+	if chmodEvents == 0 {
+		t.Skip("the changes of the metadata are not watched on this platform")
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	require.NoError(t, os.Chmod(path, 0o600))
+
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	cfg.Include = []string{dir}
+	cfg.Events = []string{"create", "chmod"}
+	sink := new(consumertest.LogsSink)
+	fsn, err := newNotify(cfg, sink, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	require.NotNil(t, fsn.attrib)
+
+	fsn.handle(context.Background(), fakeEvent{path: path, event: notify.Create})
+	require.NoError(t, os.Chmod(path, 0o666))
+	fsn.handle(context.Background(), fakeEvent{path: path, event: chmodEvents})
+	records := recordsOf(sink.AllLogs())
+	require.Len(t, records, 2)
+	requireAttr(t, records[1], "operation", ATTRIB_OPERATION)
+	requireAttr(t, records[1], MODE_BEFORE_ATTRIBUTE, "0600")
+	requireAttr(t, records[1], MODE_AFTER_ATTRIBUTE, "0666")
+}
+
+func TestAttribTrackedRoots(t *testing.T) {
+	if chmodEvents == 0 {
+		t.Skip("the changes of the metadata are not watched on this platform")
+	}
+	watched, other := t.TempDir(), t.TempDir()
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	cfg.Include = []string{watched, other}
+	cfg.PathEvents = map[string][]string{watched: {"create", "chmod"}}
+	fsn, err := newNotify(cfg, new(consumertest.LogsSink), receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	require.NotNil(t, fsn.attrib)
+
+	// only the files under the roots watching the attrib events are tracked
+	require.True(t, fsn.watchesAttrib(filepath.Join(watched, "file")))
+	require.False(t, fsn.watchesAttrib(filepath.Join(other, "file")))
+	require.False(t, fsn.watchesAttrib(filepath.Join(t.TempDir(), "file")))
+}
@@ -1,13 +1,72 @@
 package filewatchreceiver
 
 import (
+	"errors"
+	"time"
+
 	"go.opentelemetry.io/collector/component"
 )
 
 type FileWatchReceiverConfig struct {
 	Include []string `mapstructure:"include,omitempty"`
 	Exclude []string `mapstructure:"exclude,omitempty"`
-	Events  []string `mapstructure:"events,omitempty"`
+	// Events are the operations, e.g. "write", or the notify events, e.g. "notify.InCloseWrite", watched for the
+	// include paths. The create, write, remove and rename operations are watched when empty.
+	Events []string `mapstructure:"events,omitempty"`
+	// PathEvents overrides Events for some of the include paths, keyed by the include path as configured.
+	PathEvents map[string][]string `mapstructure:"path_events,omitempty"`
+	// IncludeFile is the path of a file listing paths to watch in addition to Include, one per line. The file is
+	// watched, and the watches follow its changes without restarting the receiver.
+	IncludeFile string `mapstructure:"include_file,omitempty"`
+	// ReadExisting emits an "existing" event for each file existing under the include paths once the watches are
+	// established, before the events happening since.
+	ReadExisting bool `mapstructure:"read_existing,omitempty"`
+	// ReplaceWindow is the time within which a removal followed by a creation of the same path
+	// is reported as a single "replaced" event. Disabled when 0.
+	ReplaceWindow time.Duration `mapstructure:"replace_window,omitempty"`
+	// MoveWindow is the time within which both sides of a rename are reported as a single "moved" event. Disabled
+	// when 0.
+	MoveWindow time.Duration `mapstructure:"move_window,omitempty"`
+	// DebounceWindow is the time within which the repeated events with the same path and operation are coalesced
+	// into a single event carrying their count. Disabled when 0.
+	DebounceWindow time.Duration `mapstructure:"debounce_window,omitempty"`
+	// StartTimeout bounds the time Start waits for the watches to be established. Waits indefinitely when 0.
+	StartTimeout time.Duration `mapstructure:"start_timeout,omitempty"`
+	// StartupBufferSize is the number of events, received while the watches are being established, held back
+	// until the receiver is started. Events beyond it are dropped.
+	StartupBufferSize int `mapstructure:"startup_buffer_size,omitempty"`
+	// Integrity compares the files listed in a baseline manifest to their expected hash when they are written or
+	// created.
+	Integrity IntegrityConfig `mapstructure:"integrity,omitempty"`
+	// FileMetadata adds the stat metadata of the files, e.g. their size, mode and owner, to their events. It costs a
+	// stat call per event.
+	FileMetadata bool `mapstructure:"file_metadata,omitempty"`
+	// ForcePolling polls the include paths rather than watching them natively, e.g. on network filesystems. The
+	// include paths which cannot be watched natively are polled regardless.
+	ForcePolling bool `mapstructure:"force_polling,omitempty"`
+	// PollInterval is the time between two scans of the polled include paths.
+	PollInterval time.Duration `mapstructure:"poll_interval,omitempty"`
+	// Tail emits the content appended to the files in the body of their write events.
+	Tail TailConfig `mapstructure:"tail,omitempty"`
+
+	_ struct{}
+}
+
+type IntegrityConfig struct {
+	// Manifest is the path of the baseline manifest, in the sha256sum format. Disabled when empty.
+	Manifest string `mapstructure:"manifest,omitempty"`
+
+	_ struct{}
+}
+
+type TailConfig struct {
+	// Enabled reads the content appended to the files when they are written.
+	Enabled bool `mapstructure:"enabled,omitempty"`
+	// MaxBytes bounds the content read on a single event, the rest is read on the following writes. Unbounded when 0.
+	MaxBytes int64 `mapstructure:"max_bytes,omitempty"`
+	// FullContentMaxSize is the size up to which the whole content of a file is read on each write rather than the
+	// appended content. Disabled when 0.
+	FullContentMaxSize int64 `mapstructure:"full_content_max_size,omitempty"`
 
 	_ struct{}
 }
@@ -17,5 +76,46 @@ func createDefaultConfig() component.Config {
 		Include: []string{},
 		Exclude: []string{},
 		Events:  []string{},
+
+		StartTimeout:      30 * time.Second,
+		StartupBufferSize: 1024,
+		PollInterval:      10 * time.Second,
+		Tail: TailConfig{
+			MaxBytes: 64 * 1024,
+		},
+	}
+}
+
+func (cfg *FileWatchReceiverConfig) Validate() error {
+	if _, _, err := parseIncludes(cfg.Include); err != nil {
+		return err
+	}
+	if _, err := newWatchEvents(cfg.Include, cfg.Events, cfg.PathEvents); err != nil {
+		return err
+	}
+	if cfg.ReplaceWindow < 0 {
+		return errors.New("'replace_window' must not be negative")
+	}
+	if cfg.MoveWindow < 0 {
+		return errors.New("'move_window' must not be negative")
+	}
+	if cfg.DebounceWindow < 0 {
+		return errors.New("'debounce_window' must not be negative")
+	}
+	if cfg.StartTimeout < 0 {
+		return errors.New("'start_timeout' must not be negative")
+	}
+	if cfg.StartupBufferSize < 0 {
+		return errors.New("'startup_buffer_size' must not be negative")
+	}
+	if cfg.PollInterval <= 0 {
+		return errors.New("'poll_interval' must be positive")
+	}
+	if cfg.Tail.MaxBytes < 0 {
+		return errors.New("'tail.max_bytes' must not be negative")
+	}
+	if cfg.Tail.FullContentMaxSize < 0 {
+		return errors.New("'tail.full_content_max_size' must not be negative")
 	}
+	return nil
 }
@@ -0,0 +1,121 @@
+package filewatchreceiver
+
+import (
+	"slices"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/olandr/notify"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// COUNT_ATTRIBUTE is the log attribute holding the number of events coalesced into a record by the debounce window.
+const COUNT_ATTRIBUTE = "count"
+
+// pendingEvent is the first of a burst of events with the same path and operation, held back until the debounce
+// window is over.
+type pendingEvent struct {
+	event    notify.EventInfo
+	count    int64
+	deadline time.Time
+}
+
+// debouncedEvent is an event to emit, standing for count events with the same path and operation.
+type debouncedEvent struct {
+	event notify.EventInfo
+	count int64
+}
+
+// debouncer coalesces the events with the same path and operation, happening within window of the first of them,
+// into a single event. The window starts at the first event, so that the events of a path written continuously are
+// still emitted once per window. It is not safe for concurrent use.
+type debouncer struct {
+	window  time.Duration
+	pending map[string]*pendingEvent
+	clock   clockwork.Clock
+}
+
+func newDebouncer(window time.Duration, clock clockwork.Clock) *debouncer {
+	return &debouncer{
+		window:  window,
+		pending: make(map[string]*pendingEvent),
+		clock:   clock,
+	}
+}
+
+// observe handles a single event and returns the events that are ready to be emitted. An event with another operation
+// than the one held back for its path emits the held back one first, so that the events of a path keep their order.
+func (d *debouncer) observe(event notify.EventInfo) []debouncedEvent {
+	var ret []debouncedEvent
+	if p, ok := d.pending[event.Path()]; ok {
+		if p.event.Event() == event.Event() {
+			p.count++
+			return nil
+		}
+		ret = append(ret, debouncedEvent{event: p.event, count: p.count})
+	}
+	d.pending[event.Path()] = &pendingEvent{event: event, count: 1, deadline: d.clock.Now().Add(d.window)}
+	return ret
+}
+
+// expire returns the held back events whose debounce window is over, in the order they were first received.
+func (d *debouncer) expire() []debouncedEvent {
+	now := d.clock.Now()
+	var expired []*pendingEvent
+	for path, p := range d.pending {
+		if now.Before(p.deadline) {
+			continue
+		}
+		delete(d.pending, path)
+		expired = append(expired, p)
+	}
+	return sortedEvents(expired)
+}
+
+// flush returns all the held back events, regardless of their deadline.
+func (d *debouncer) flush() []debouncedEvent {
+	pending := make([]*pendingEvent, 0, len(d.pending))
+	for _, p := range d.pending {
+		pending = append(pending, p)
+	}
+	clear(d.pending)
+	return sortedEvents(pending)
+}
+
+// nextDeadline returns the earliest deadline of the held back events, if any.
+func (d *debouncer) nextDeadline() (time.Time, bool) {
+	var next time.Time
+	for _, p := range d.pending {
+		if next.IsZero() || p.deadline.Before(next) {
+			next = p.deadline
+		}
+	}
+	return next, !next.IsZero()
+}
+
+func sortedEvents(pending []*pendingEvent) []debouncedEvent {
+	slices.SortStableFunc(pending, func(a, b *pendingEvent) int {
+		return a.deadline.Compare(b.deadline)
+	})
+	ret := make([]debouncedEvent, 0, len(pending))
+	for _, p := range pending {
+		ret = append(ret, debouncedEvent{event: p.event, count: p.count})
+	}
+	return ret
+}
+
+// annotateCount sets the number of events coalesced into the log records of path in logs.
+func annotateCount(logs plog.Logs, path string, count int64) {
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		resourceLogs := logs.ResourceLogs().At(i)
+		for j := 0; j < resourceLogs.ScopeLogs().Len(); j++ {
+			records := resourceLogs.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+				if p, found := record.Attributes().Get("path"); found && p.Str() == path {
+					record.Attributes().PutInt(COUNT_ATTRIBUTE, count)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package filewatchreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func requireDebounced(t *testing.T, events []debouncedEvent, expected ...debouncedEvent) {
+	require.Len(t, events, len(expected))
+	for i, e := range expected {
+		require.Equal(t, e.event.Path(), events[i].event.Path())
+		require.Equal(t, e.event.Event(), events[i].event.Event())
+		require.Equal(t, e.count, events[i].count)
+	}
+}
+
+func TestDebouncer(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+
+	t.Run("repeated events are coalesced", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(ts)
+		d := newDebouncer(time.Second, clock)
+		for range 3 {
+			require.Empty(t, d.observe(fakeEvent{path: "/a", event: notify.Write}))
+		}
+		deadline, ok := d.nextDeadline()
+		require.True(t, ok)
+		require.Equal(t, ts.Add(time.Second), deadline)
+
+		clock.Advance(500 * time.Millisecond)
+		require.Empty(t, d.observe(fakeEvent{path: "/a", event: notify.Write}))
+		require.Empty(t, d.expire())
+
+		clock.Advance(500 * time.Millisecond)
+		requireDebounced(t, d.expire(), debouncedEvent{fakeEvent{path: "/a", event: notify.Write}, 4})
+		_, ok = d.nextDeadline()
+		require.False(t, ok)
+	})
+
+	t.Run("another operation emits the held back event", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(ts)
+		d := newDebouncer(time.Second, clock)
+		require.Empty(t, d.observe(fakeEvent{path: "/a", event: notify.Write}))
+		require.Empty(t, d.observe(fakeEvent{path: "/a", event: notify.Write}))
+		requireDebounced(t, d.observe(fakeEvent{path: "/a", event: notify.Remove}),
+			debouncedEvent{fakeEvent{path: "/a", event: notify.Write}, 2})
+		requireDebounced(t, d.flush(), debouncedEvent{fakeEvent{path: "/a", event: notify.Remove}, 1})
+	})
+
+	t.Run("paths are debounced separately", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(ts)
+		d := newDebouncer(time.Second, clock)
+		require.Empty(t, d.observe(fakeEvent{path: "/a", event: notify.Write}))
+		clock.Advance(100 * time.Millisecond)
+		require.Empty(t, d.observe(fakeEvent{path: "/b", event: notify.Write}))
+		require.Empty(t, d.observe(fakeEvent{path: "/a", event: notify.Write}))
+
+		clock.Advance(900 * time.Millisecond)
+		requireDebounced(t, d.expire(), debouncedEvent{fakeEvent{path: "/a", event: notify.Write}, 2})
+		clock.Advance(100 * time.Millisecond)
+		requireDebounced(t, d.expire(), debouncedEvent{fakeEvent{path: "/b", event: notify.Write}, 1})
+	})
+
+	t.Run("flush returns the events in the order they were received", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(ts)
+		d := newDebouncer(time.Second, clock)
+		for _, path := range []string{"/c", "/a", "/b"} {
+			require.Empty(t, d.observe(fakeEvent{path: path, event: notify.Create}))
+			clock.Advance(time.Millisecond)
+		}
+		requireDebounced(t, d.flush(),
+			debouncedEvent{fakeEvent{path: "/c", event: notify.Create}, 1},
+			debouncedEvent{fakeEvent{path: "/a", event: notify.Create}, 1},
+			debouncedEvent{fakeEvent{path: "/b", event: notify.Create}, 1})
+	})
+}
+
+func TestDebounceWindowExpiry(t *testing.T) {
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	cfg.DebounceWindow = time.Second
+	sink := new(consumertest.LogsSink)
+	clock := clockwork.NewFakeClockAt(time.Unix(1700000000, 0))
+	fsn, err := newNotify(cfg, sink, receivertest.NewNopSettings(Type), withClock(clock))
+	require.NoError(t, err)
+
+	fsn.watcher = make(chan notify.EventInfo, 8)
+	fsn.done = make(chan struct{})
+	fsn.ready = make(chan struct{})
+	fsn.notify = notify.NewNotify()
+	go fsn.watch(context.Background(), fsn.watcher)
+
+	// the writes are held back until the debounce window expires, they are received while starting so that they
+	// are all handled before the window is scheduled
+	for range 3 {
+		fsn.watcher <- fakeEvent{path: "/tmp/a", event: notify.Write}
+	}
+	require.Eventually(t, func() bool { return len(fsn.watcher) == 0 }, 5*time.Second, 10*time.Millisecond)
+	close(fsn.ready)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, clock.BlockUntilContext(ctx, 1))
+	require.Zero(t, sink.LogRecordCount())
+
+	clock.Advance(cfg.DebounceWindow)
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	for lr := range logsIterator(sink.AllLogs()) {
+		requireAttr(t, lr, "operation", notify.Write.String())
+		requireAttr(t, lr, COUNT_ATTRIBUTE, int64(3))
+	}
+
+	// the events held back when shutting down are emitted
+	fsn.watcher <- fakeEvent{path: "/tmp/b", event: notify.Create}
+	require.NoError(t, clock.BlockUntilContext(ctx, 1))
+	require.NoError(t, fsn.Shutdown(context.Background()))
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+}
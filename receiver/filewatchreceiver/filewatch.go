@@ -3,27 +3,69 @@ package filewatchreceiver
 import (
 	"context"
 	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/olandr/notify"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/plog"
 	"go.opentelemetry.io/collector/receiver"
+	"go.opentelemetry.io/otel/metric"
 	"go.uber.org/zap"
+
+	"github.com/olandr/opentelemetry-collector-contrib/receiver/filewatchreceiver/internal/metadata"
 )
 
 type FileWatcher struct {
-	include  []string
-	exclude  []string
-	events   []string
-	consumer consumer.Logs
-	logger   *zap.Logger
-	watcher  chan notify.EventInfo
-	notify   notify.Notify
-	done     chan struct{}
-	internal metrics // Benchmark
+	// include are the paths to watch, the glob patterns being replaced by their base directory.
+	include []string
+	exclude []string
+	// filter filters the events by the glob patterns of the include paths, nil when there is none.
+	filter *pathFilter
+	// events are the events watched for each include path.
+	events watchEvents
+	// readExisting emits the files existing under the include paths once the watches are established.
+	readExisting bool
+	// excludePatterns are the compiled exclude paths, for the existing and the polled files.
+	excludePatterns []*regexp.Regexp
+	// forcePolling polls the include paths rather than watching them natively.
+	forcePolling bool
+	// poll polls the include paths which are not watched natively.
+	poll    *poller
+	replace *replaceCorrelator
+	// move pairs the sides of the renames, nil when disabled.
+	move *moveCorrelator
+	// debounce coalesces the bursts of events with the same path and operation, nil when disabled.
+	debounce *debouncer
+	// integrity checks the files against the baseline manifest, nil when disabled.
+	integrity *integrityChecker
+	// stat adds the metadata of the files, nil when disabled.
+	stat *statEnricher
+	// attrib remembers the attributes of the files for the attrib events, nil when they are not watched.
+	attrib *attribTracker
+	// tail reads the content appended to the written files, nil when disabled.
+	tail *tailer
+	// includeFile holds the watches of the paths listed in the include file, nil when disabled.
+	includeFile *includeFile
+	roots       watchRoots
+	consumer    consumer.Logs
+	logger      *zap.Logger
+	// clock provides the observed timestamps, the replace, move and debounce windows and the start timeout.
+	clock   clockwork.Clock
+	watcher chan notify.EventInfo
+	notify  notify.Notify
+	done    chan struct{}
+	// ready is closed once the watches are established, the events received before are held back until then.
+	ready          chan struct{}
+	startTimeout   time.Duration
+	bufferSize     int
+	startupDropped metric.Int64Counter
+	internal       metrics // Benchmark
 }
 
 // Benchmark
@@ -32,15 +74,87 @@ type metrics struct {
 	events_recorded int64
 }
 
-func newNotify(cfg *FileWatchReceiverConfig, consumer consumer.Logs, settings receiver.Settings) (*FileWatcher, error) {
-	return &FileWatcher{
-		include:  cfg.Include,
-		exclude:  cfg.Exclude,
-		events:   cfg.Events,
-		consumer: consumer,
-		logger:   settings.Logger,
-		internal: metrics{0, 0}, // Benchmark
-	}, nil
+// option configures a FileWatcher created by newNotify.
+type option func(*FileWatcher)
+
+// withClock replaces the real clock of the FileWatcher, e.g. by a fake clock in tests.
+func withClock(clock clockwork.Clock) option {
+	return func(fsn *FileWatcher) {
+		fsn.clock = clock
+	}
+}
+
+func newNotify(cfg *FileWatchReceiverConfig, consumer consumer.Logs, settings receiver.Settings, opts ...option) (*FileWatcher, error) {
+	include, filter, err := parseIncludes(cfg.Include)
+	if err != nil {
+		return nil, err
+	}
+	events, err := newWatchEvents(cfg.Include, cfg.Events, cfg.PathEvents)
+	if err != nil {
+		return nil, err
+	}
+	fsn := &FileWatcher{
+		events:       events,
+		include:      include,
+		exclude:      cfg.Exclude,
+		filter:       filter,
+		readExisting: cfg.ReadExisting,
+		roots:        newWatchRoots(include),
+		consumer:     consumer,
+		logger:       settings.Logger,
+		clock:        clockwork.NewRealClock(),
+		startTimeout: cfg.StartTimeout,
+		bufferSize:   cfg.StartupBufferSize,
+		internal:     metrics{0, 0}, // Benchmark
+	}
+	for _, opt := range opts {
+		opt(fsn)
+	}
+	if cfg.ReplaceWindow > 0 {
+		fsn.replace = newReplaceCorrelator(cfg.ReplaceWindow, fsn.clock)
+	}
+	if cfg.MoveWindow > 0 {
+		fsn.move = newMoveCorrelator(cfg.MoveWindow, fsn.clock)
+	}
+	if cfg.DebounceWindow > 0 {
+		fsn.debounce = newDebouncer(cfg.DebounceWindow, fsn.clock)
+	}
+	if cfg.IncludeFile != "" {
+		if fsn.includeFile, err = newIncludeFile(cfg.IncludeFile); err != nil {
+			return nil, err
+		}
+		fsn.roots = newWatchRoots(append(slices.Clone(include), fsn.includeFile.entries...))
+	}
+	if cfg.Integrity.Manifest != "" {
+		if fsn.integrity, err = newIntegrityChecker(cfg.Integrity.Manifest); err != nil {
+			return nil, err
+		}
+	}
+	for _, ex := range cfg.Exclude {
+		re, err := regexp.Compile(ex)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compile the exclude path %q: %w", ex, err)
+		}
+		fsn.excludePatterns = append(fsn.excludePatterns, re)
+	}
+	fsn.forcePolling = cfg.ForcePolling
+	fsn.poll = newPoller(cfg.PollInterval, fsn.clock, fsn.excludePatterns)
+	if cfg.FileMetadata {
+		fsn.stat = newStatEnricher()
+	}
+	if chmodEvents != 0 && events.all()&chmodEvents != 0 {
+		fsn.attrib = newAttribTracker(fsn.watchesAttrib)
+	}
+	if cfg.Tail.Enabled {
+		fsn.tail = newTailer(cfg.Tail)
+	}
+	fsn.startupDropped, err = settings.MeterProvider.Meter(metadata.ScopeName).Int64Counter("otelcol_filewatch_startup_dropped_events",
+		metric.WithDescription("Number of events received while the watches were being established that were dropped because the startup buffer was full"),
+		metric.WithUnit("{events}"))
+	if err != nil {
+		return nil, err
+	}
+	return fsn, nil
 }
 
 func createLogs(ts time.Time, path, operation string) plog.Logs {
@@ -53,69 +167,309 @@ func createLogs(ts time.Time, path, operation string) plog.Logs {
 	logRecord.SetTimestamp(pcommon.NewTimestampFromTime(ts))
 	logRecord.Attributes().PutStr("path", path)
 	logRecord.Attributes().PutStr("operation", operation)
-	logRecord.SetObservedTimestamp(pcommon.NewTimestampFromTime(time.Now()))
 	return logs
 }
 
+// consume emits logs, observed at the current time of the clock.
+func (fsn *FileWatcher) consume(ctx context.Context, logs []plog.Logs) {
+	observed := pcommon.NewTimestampFromTime(fsn.clock.Now())
+	for _, l := range logs {
+		rls := l.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			sls := rls.At(i).ScopeLogs()
+			for j := 0; j < sls.Len(); j++ {
+				lrs := sls.At(j).LogRecords()
+				for k := 0; k < lrs.Len(); k++ {
+					lrs.At(k).SetObservedTimestamp(observed)
+				}
+			}
+		}
+		fsn.roots.annotate(l)
+		fsn.consumer.ConsumeLogs(ctx, l)
+	}
+}
+
+// expiry returns a channel firing when the earliest held back removal, rename side or debounced event should be
+// emitted, or nil when there is none.
+func (fsn *FileWatcher) expiry() <-chan time.Time {
+	var next time.Time
+	earliest := func(deadline time.Time, ok bool) {
+		if ok && (next.IsZero() || deadline.Before(next)) {
+			next = deadline
+		}
+	}
+	if fsn.replace != nil {
+		earliest(fsn.replace.nextDeadline())
+	}
+	if fsn.move != nil {
+		earliest(fsn.move.nextDeadline())
+	}
+	if fsn.debounce != nil {
+		earliest(fsn.debounce.nextDeadline())
+	}
+	if next.IsZero() {
+		return nil
+	}
+	return fsn.clock.After(next.Sub(fsn.clock.Now()))
+}
+
+// expire emits the debounced events, the held back removals and the rename sides whose window is over. The debounced
+// events come first, as they can be the creations replacing the held back removals or the other sides of the renames.
+func (fsn *FileWatcher) expire(ctx context.Context) {
+	if fsn.debounce != nil {
+		for _, e := range fsn.debounce.expire() {
+			fsn.emit(ctx, e.event, e.count)
+		}
+	}
+	if fsn.replace != nil {
+		fsn.consume(ctx, fsn.replace.expire())
+	}
+	if fsn.move != nil {
+		fsn.consume(ctx, fsn.move.expire())
+	}
+}
+
 func (fsn *FileWatcher) watch(ctx context.Context, watcher chan (notify.EventInfo)) {
 	defer fsn.notify.Stop(fsn.watcher)
+	var expired <-chan time.Time
+	// the events received while the watches are being established are held back in early, up to the buffer size
+	ready := fsn.ready
+	// the changes of the include file are only handled once the watches are established
+	var reloads <-chan notify.EventInfo
+	var early []notify.EventInfo
+	var dropped int64
 	for {
 		select {
 		case <-ctx.Done():
 			return
 		case _, ok := <-fsn.done:
 			_ = ok
+			if fsn.debounce != nil {
+				for _, e := range fsn.debounce.flush() {
+					fsn.emit(ctx, e.event, e.count)
+				}
+			}
+			if fsn.replace != nil {
+				fsn.consume(ctx, fsn.replace.flush())
+			}
+			if fsn.move != nil {
+				fsn.consume(ctx, fsn.move.flush())
+			}
 			return
+		case <-ready:
+			ready = nil
+			if dropped > 0 {
+				fsn.startupDropped.Add(ctx, dropped)
+				fsn.logger.Warn("events received while establishing the watches were dropped, consider increasing 'startup_buffer_size'",
+					zap.Int64("dropped", dropped), zap.Int("buffered", len(early)))
+			}
+			if fsn.readExisting {
+				fsn.emitExisting(ctx)
+			}
+			for _, event := range early {
+				fsn.handle(ctx, event)
+			}
+			early = nil
+			expired = fsn.expiry()
+			if fsn.includeFile != nil {
+				reloads = fsn.includeFile.changes
+			}
+		case <-reloads:
+			fsn.reloadIncludeFile()
+		case <-expired:
+			fsn.expire(ctx)
+			expired = fsn.expiry()
 		case event := <-watcher:
-			b := time.Now() // Benchmark
-			// FIXME: this feels like a slow check; needs some benchmarking to see how this performs under load.
-			ts := time.Unix(event.Timestamp(), 0)
-			fsn.logger.Debug("event", zap.Time("ts", ts), zap.String("path", event.Path()), zap.String("operation", event.Event().String()))
-			logs := createLogs(ts, event.Path(), event.Event().String())
-			fsn.consumer.ConsumeLogs(ctx, logs)
-			// Benchmark
-			fsn.internal.total_duration += (time.Since(b).Microseconds())
-			fsn.internal.events_recorded++
+			if ready != nil {
+				if len(early) < fsn.bufferSize {
+					early = append(early, event)
+				} else {
+					dropped++
+				}
+				continue
+			}
+			fsn.handle(ctx, event)
+			expired = fsn.expiry()
 		}
 	}
 }
 
+// emitExisting emits an EXISTING_OPERATION record, with the metadata of the file, for each path existing under the
+// include paths.
+func (fsn *FileWatcher) emitExisting(ctx context.Context) {
+	stat := fsn.stat
+	if stat == nil {
+		stat = newStatEnricher()
+	}
+	include := fsn.include
+	if fsn.includeFile != nil {
+		include = append(slices.Clone(include), fsn.includeFile.entries...)
+	}
+	ts := fsn.clock.Now()
+	paths := existingPaths(include, fsn.excludePatterns)
+	if fsn.filter != nil {
+		paths = slices.DeleteFunc(paths, func(path string) bool { return !fsn.filter.match(path) })
+	}
+	fsn.logger.Info("emitting the existing files", zap.Int("files", len(paths)))
+	for _, path := range paths {
+		logs := createLogs(ts, path, EXISTING_OPERATION)
+		stat.annotate(logs, path)
+		if fsn.attrib != nil {
+			fsn.attrib.record(path)
+		}
+		fsn.consume(ctx, []plog.Logs{logs})
+	}
+}
+
+// watchesAttrib returns whether the include root of path watches the attrib events.
+func (fsn *FileWatcher) watchesAttrib(path string) bool {
+	root, _, ok := fsn.roots.resolve(path)
+	return ok && fsn.events.of(root)&chmodEvents != 0
+}
+
+// handle emits the logs of a single event, or holds it back when debouncing.
+func (fsn *FileWatcher) handle(ctx context.Context, event notify.EventInfo) {
+	if fsn.filter != nil && !fsn.filter.match(event.Path()) {
+		return
+	}
+	// the nested include paths can watch different events, the most specific one decides
+	if root, _, ok := fsn.roots.resolve(event.Path()); ok && event.Event()&fsn.events.of(root) == 0 {
+		return
+	}
+	b := fsn.clock.Now() // Benchmark
+	if fsn.debounce != nil {
+		for _, e := range fsn.debounce.observe(event) {
+			fsn.emit(ctx, e.event, e.count)
+		}
+	} else {
+		fsn.emit(ctx, event, 0)
+	}
+	// Benchmark
+	fsn.internal.total_duration += (fsn.clock.Since(b).Microseconds())
+	fsn.internal.events_recorded++
+}
+
+// emit emits the logs of event, standing for count events with the same path and operation when debouncing, 0
+// otherwise.
+func (fsn *FileWatcher) emit(ctx context.Context, event notify.EventInfo, count int64) {
+	// FIXME: this feels like a slow check; needs some benchmarking to see how this performs under load.
+	ts := time.Unix(event.Timestamp(), 0)
+	fsn.logger.Debug("event", zap.Time("ts", ts), zap.String("path", event.Path()), zap.String("operation", event.Event().String()))
+	var logs []plog.Logs
+	moved := false
+	if fsn.move != nil {
+		logs, moved = fsn.move.observe(ts, event)
+	}
+	switch {
+	case moved:
+		// the sides of the renames are paired, or held back, by the move correlator
+	case fsn.replace != nil:
+		logs = fsn.replace.observe(ts, event.Path(), event.Event())
+	default:
+		logs = []plog.Logs{createLogs(ts, event.Path(), operationOf(event.Event()))}
+	}
+	if len(logs) > 0 {
+		// the logs of the event itself come last, after any held back removal being emitted
+		if fsn.stat != nil {
+			fsn.stat.annotate(logs[len(logs)-1], event.Path())
+		}
+		if fsn.integrity != nil {
+			fsn.integrity.annotate(logs[len(logs)-1], event.Path(), event.Event())
+		}
+		if count > 0 {
+			annotateCount(logs[len(logs)-1], event.Path(), count)
+		}
+	}
+	if fsn.tail != nil {
+		// the offsets follow all the events, including the removals being held back
+		fsn.tail.annotate(logs, event.Path(), event.Event())
+	}
+	if fsn.attrib != nil {
+		// the attributes follow all the events too
+		fsn.attrib.annotate(logs, event.Path(), event.Event())
+	}
+	fsn.consume(ctx, logs)
+}
+
+// Start establishes the watches before returning, so that no event happening after Start is missed. The events
+// received while the watches are being established are emitted once they all are, bounded by the start timeout.
 func (fsn *FileWatcher) Start(ctx context.Context, host component.Host) error {
 	fsn.watcher = make(chan notify.EventInfo, 128)
 	fsn.done = make(chan struct{})
+	fsn.ready = make(chan struct{})
 	fsn.notify = notify.NewNotify()
-	go fsn.watch(ctx, fsn.watcher)
-	var err error
-	if len(fsn.include) == 0 {
+	// the watch loop outlives Start, it is stopped by Shutdown
+	go fsn.watch(context.WithoutCancel(ctx), fsn.watcher)
+	if len(fsn.include) == 0 && fsn.includeFile == nil {
+		close(fsn.ready)
 		return nil
 	}
 	// Setup watches by include paths and prepare exclusions
-	watches := len(fsn.include)
 	for _, ex := range fsn.exclude {
 		fsn.notify.Exclude(ex)
 	}
-	var events_to_watch notify.Event
-	for _, name := range fsn.events {
-		if ev, ok := notify.NewEventFromString(name); ok {
-			events_to_watch |= ev
-		} else {
-			return fmt.Errorf("cannot create watch for the supplied event name: %v", name)
+	established := make(chan int, 1)
+	go func() {
+		established <- fsn.establishWatches()
+	}()
+	var timeout <-chan time.Time
+	if fsn.startTimeout > 0 {
+		timer := fsn.clock.NewTimer(fsn.startTimeout)
+		defer timer.Stop()
+		timeout = timer.Chan()
+	}
+	select {
+	case watches := <-established:
+		if watches == 0 {
+			return fmt.Errorf("could not create any watches on the supplied 'include' paths and 'include_file'")
 		}
+	case <-timeout:
+		return fmt.Errorf("could not establish the watches on the supplied 'include' paths within %v", fsn.startTimeout)
+	}
+	if fsn.poll.polling() {
+		fsn.poll.start(fsn.watcher)
 	}
+	close(fsn.ready)
+	return nil
+}
+
+// establishWatches watches the include paths and the include file, and returns the number of paths watched.
+func (fsn *FileWatcher) establishWatches() int {
+	watches := len(fsn.include)
 	for _, f := range fsn.include {
-		fsn.logger.Info("setting up watches for", zap.String("events", fmt.Sprintf("%v", events_to_watch)))
+		if fsn.forcePolling {
+			fsn.logger.Info("setting up polling for", zap.String("path", f))
+			if !fsn.poll.add(f) {
+				fsn.logger.Error("cannot poll, skipping", zap.String("path", f))
+				watches--
+			}
+			continue
+		}
+		events := fsn.events.of(strings.TrimSuffix(f, recursiveSuffix))
+		fsn.logger.Info("setting up watches for", zap.String("path", f), zap.String("events", fmt.Sprintf("%v", events)))
 
-		err = fsn.notify.Watch(f, fsn.watcher, events_to_watch)
-		// We are more lenient with problematic include paths
-		if err != nil {
-			fsn.logger.Error("cannot create watch, skipping", zap.String("path", f), zap.Error(err))
-			watches--
+		err := fsn.notify.Watch(f, fsn.watcher, events)
+		if err == nil {
+			continue
+		}
+		// We are more lenient with problematic include paths, the existing ones are polled instead
+		if fsn.poll.add(f) {
+			fsn.logger.Warn("cannot create watch, falling back to polling", zap.String("path", f), zap.Error(err))
+			continue
 		}
+		fsn.logger.Error("cannot create watch, skipping", zap.String("path", f), zap.Error(err))
+		watches--
 	}
-	if watches == 0 {
-		return fmt.Errorf("could not create any watches on the supplied 'include' paths")
+	if fsn.includeFile != nil {
+		listed, err := fsn.watchIncludeFile()
+		if err != nil {
+			fsn.logger.Error("cannot watch the include file, skipping", zap.String("path", fsn.includeFile.path), zap.Error(err))
+		} else {
+			// the include file itself counts as a watch, so that it can list no paths yet
+			watches += 1 + listed
+		}
 	}
-	return nil
+	return watches
 }
 
 func (fsn *FileWatcher) Shutdown(_ context.Context) error {
@@ -123,6 +477,10 @@ func (fsn *FileWatcher) Shutdown(_ context.Context) error {
 		fsn.done <- struct{}{}
 		close(fsn.done)
 		fsn.notify.Stop(fsn.watcher)
+		if fsn.includeFile != nil {
+			fsn.stopIncludeFile()
+		}
+		fsn.poll.stop()
 		fsn.notify.Close()
 		close(fsn.watcher)
 		fsn.done = nil
@@ -21,14 +21,13 @@ type FactoryTestCase struct {
 	Error    error
 }
 
-func eventuallyExpect(t *testing.T, expected int, actual int) {
-	time.Sleep(300 * time.Millisecond)
-	require.Eventually(t, func() bool { return expected == actual }, 10*time.Second, 5*time.Millisecond,
-		"expected %d, but got %d", expected, actual)
+// eventuallyExpect waits until the receiver has emitted as many logs as expected.
+func eventuallyExpect(t *testing.T, expected, actual *consumertest.LogsSink) {
+	require.Eventually(t, func() bool { return expected.LogRecordCount() == actual.LogRecordCount() }, 10*time.Second, 5*time.Millisecond,
+		"expected %d, but got %d", expected.LogRecordCount(), actual.LogRecordCount())
 }
 
 func TestFilewatcherReceiver(t *testing.T) {
-	time.Sleep(300 * time.Millisecond)
 	TEST_RUNS := gofakeit.UintRange(2, 5)
 	t.Run("can do simple crud", func(t *testing.T) {
 		t.Parallel()
@@ -47,11 +46,9 @@ func TestFilewatcherReceiver(t *testing.T) {
 			consumeLogs(t, expectedLogsConsumer, Remove(createFiles[tc], true))
 
 			// Assert
-			time.Sleep(300 * time.Millisecond)
-
+			eventuallyExpect(t, expectedLogsConsumer, actualLogsConsumer)
 			expected := logsToMap(t, expectedLogsConsumer.AllLogs(), "expected")
 			actual := logsToMap(t, actualLogsConsumer.AllLogs(), "actual")
-			eventuallyExpect(t, expectedLogsConsumer.LogRecordCount(), actualLogsConsumer.LogRecordCount())
 			require.Equal(t, expected, actual)
 		}
 		require.NoError(t, logs.Shutdown(context.Background()))
@@ -65,7 +62,6 @@ func TestFilewatcherReceiver(t *testing.T) {
 		// We want to only listen to the outer path, but add files to a dir within
 		logs, actualLogsConsumer, cfg, root_dir := beforeEach(t, false)
 		wd := strings.Replace((cfg.Include[0]), "/...", "", -1)
-		time.Sleep(300 * time.Millisecond)
 		// Act
 		createFiles := make([]string, TEST_RUNS)
 		for tc := range TEST_RUNS {
@@ -78,10 +74,9 @@ func TestFilewatcherReceiver(t *testing.T) {
 			consumeLogs(t, expectedLogsConsumer, Remove(innerDir, true))
 
 			// Assert
-			time.Sleep(300 * time.Millisecond)
+			eventuallyExpect(t, expectedLogsConsumer, actualLogsConsumer)
 			expected := logsToMap(t, expectedLogsConsumer.AllLogs(), "expected")
 			actual := logsToMap(t, actualLogsConsumer.AllLogs(), "actual")
-			eventuallyExpect(t, expectedLogsConsumer.LogRecordCount(), actualLogsConsumer.LogRecordCount())
 			require.Equal(t, expected, actual)
 		}
 		require.NoError(t, logs.Shutdown(context.Background()))
@@ -102,18 +97,13 @@ func TestFilewatcherReceiver(t *testing.T) {
 
 			createFiles[tc] = fmt.Sprintf("%v/exclude/%v.skip", wd, gofakeit.LetterN(5))
 			Create(createFiles[tc], true)
-			time.Sleep(300 * time.Millisecond)
 			Write(createFiles[tc], true)
-			time.Sleep(300 * time.Millisecond)
 			Remove(createFiles[tc], true)
-			time.Sleep(300 * time.Millisecond)
 
 			// Assert
-			time.Sleep(300 * time.Millisecond)
-
+			require.Never(t, func() bool { return actualLogsConsumer.LogRecordCount() > 0 }, 300*time.Millisecond, 5*time.Millisecond)
 			expected := logsToMap(t, expectedLogsConsumer.AllLogs(), "expected")
 			actual := logsToMap(t, actualLogsConsumer.AllLogs(), "actual")
-			eventuallyExpect(t, expectedLogsConsumer.LogRecordCount(), actualLogsConsumer.LogRecordCount())
 			require.Equal(t, expected, actual)
 		}
 		require.NoError(t, logs.Shutdown(context.Background()))
@@ -132,7 +122,6 @@ func TestFilewatcherReceiver(t *testing.T) {
 		TEST_FILES := 1
 		createFiles := make([]string, TEST_FILES)
 		for tc := range TEST_FILES {
-			time.Sleep(300 * time.Millisecond)
 			createFiles[tc] = fmt.Sprintf("%v/%v.txt", wd_inner, gofakeit.LetterN(5))
 
 			consumeLogs(t, expectedLogsConsumer, Create(createFiles[tc], true))
@@ -140,9 +129,8 @@ func TestFilewatcherReceiver(t *testing.T) {
 			consumeLogs(t, expectedLogsConsumer, Remove(createFiles[tc], true))
 
 			// Assert
-			time.Sleep(300 * time.Millisecond)
+			eventuallyExpect(t, expectedLogsConsumer, actualLogsConsumer)
 			require.Equal(t, logsToMap(t, expectedLogsConsumer.AllLogs(), "expected"), logsToMap(t, actualLogsConsumer.AllLogs(), "actual"))
-			eventuallyExpect(t, expectedLogsConsumer.LogRecordCount(), actualLogsConsumer.LogRecordCount())
 
 		}
 		require.NoError(t, logs.Shutdown(context.Background()))
@@ -172,11 +160,9 @@ func TestFilewatcherReceiver(t *testing.T) {
 			consumeLogs(t, expectedLogsConsumer, Remove(innerDir, true))
 
 			// Assert
-			time.Sleep(300 * time.Millisecond)
-
+			eventuallyExpect(t, expectedLogsConsumer, actualLogsConsumer)
 			expected := logsToMap(t, expectedLogsConsumer.AllLogs(), "expected")
 			actual := logsToMap(t, actualLogsConsumer.AllLogs(), "actual")
-			eventuallyExpect(t, expectedLogsConsumer.LogRecordCount(), actualLogsConsumer.LogRecordCount())
 			require.Equal(t, expected, actual)
 
 		}
@@ -201,11 +187,9 @@ func TestFilewatcherReceiver(t *testing.T) {
 			consumeLogs(t, expectedLogsConsumer, Rename(createFiles[tc], newName, true))
 			consumeLogs(t, expectedLogsConsumer, RenameRemove(newName, true))
 			// Assert
-			time.Sleep(300 * time.Millisecond)
-
+			eventuallyExpect(t, expectedLogsConsumer, actualLogsConsumer)
 			expected := logsToMap(t, expectedLogsConsumer.AllLogs(), "expected")
 			actual := logsToMap(t, actualLogsConsumer.AllLogs(), "actual")
-			eventuallyExpect(t, expectedLogsConsumer.LogRecordCount(), actualLogsConsumer.LogRecordCount())
 			require.Equal(t, expected, actual)
 		}
 		require.NoError(t, logs.Shutdown(context.Background()))
@@ -233,11 +217,9 @@ func TestFilewatcherReceiver(t *testing.T) {
 		consumeLogs(t, expectedLogsConsumer, Rename(oldName, orignalName, true))
 		consumeLogs(t, expectedLogsConsumer, RenameRemove(orignalName, true))
 		// Assert
-		time.Sleep(300 * time.Millisecond)
-
+		eventuallyExpect(t, expectedLogsConsumer, actualLogsConsumer)
 		expected := logsToMap(t, expectedLogsConsumer.AllLogs(), "expected")
 		actual := logsToMap(t, actualLogsConsumer.AllLogs(), "actual")
-		eventuallyExpect(t, expectedLogsConsumer.LogRecordCount(), actualLogsConsumer.LogRecordCount())
 		require.Equal(t, expected, actual)
 		require.NoError(t, logs.Shutdown(context.Background()))
 		testTeardown(t, root_dir)
@@ -0,0 +1,119 @@
+package filewatchreceiver
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// negationPrefix marks an include pattern excluding the paths it matches.
+const negationPrefix = "!"
+
+// isGlob returns whether an include path is a glob pattern rather than a path.
+func isGlob(path string) bool {
+	return strings.HasPrefix(path, negationPrefix) || strings.ContainsAny(path, "*?[{")
+}
+
+// pathFilter keeps the events of the paths under the plain include paths or matching the include glob patterns, and
+// drops the events of the paths matching the negated ones. The patterns are matched against slash separated paths.
+type pathFilter struct {
+	plain   watchRoots
+	include []string
+	exclude []string
+}
+
+// parseIncludes splits the include paths into the paths to watch and the filter of their events. A glob pattern is
+// watched from its static base directory, see watchOf, and only the events of the paths it matches are kept. filter is
+// nil when there is no glob pattern.
+func parseIncludes(include []string) (watches []string, filter *pathFilter, err error) {
+	var plain []string
+	filter = &pathFilter{}
+	for _, path := range include {
+		if !isGlob(path) {
+			plain = append(plain, path)
+			continue
+		}
+		pattern, negated, err := resolvePattern(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		if negated {
+			filter.exclude = append(filter.exclude, pattern)
+			continue
+		}
+		filter.include = append(filter.include, pattern)
+		if watch := patternWatch(pattern); !slices.Contains(watches, watch) {
+			watches = append(watches, watch)
+		}
+	}
+	if len(filter.include) == 0 && len(filter.exclude) == 0 {
+		return include, nil, nil
+	}
+	filter.plain = newWatchRoots(plain)
+	return append(plain, watches...), filter, nil
+}
+
+// watchOf returns the path watched for an include path: the path itself, or the watch of a glob pattern.
+func watchOf(path string) (string, error) {
+	if !isGlob(path) {
+		return path, nil
+	}
+	pattern, _, err := resolvePattern(path)
+	if err != nil {
+		return "", err
+	}
+	return patternWatch(pattern), nil
+}
+
+// resolvePattern returns the slash separated glob pattern of an include path, and whether it is negated. Relative
+// patterns are resolved against the working directory, except for the negated ones starting with "**", which match
+// the paths at any depth.
+func resolvePattern(path string) (pattern string, negated bool, err error) {
+	pattern, negated = strings.CutPrefix(path, negationPrefix)
+	if !negated || !strings.HasPrefix(pattern, "**") {
+		if pattern, err = filepath.Abs(pattern); err != nil {
+			return "", false, err
+		}
+	}
+	pattern = filepath.ToSlash(pattern)
+	if !doublestar.ValidatePattern(pattern) {
+		return "", false, fmt.Errorf("invalid glob pattern in 'include': %q", path)
+	}
+	return pattern, negated, nil
+}
+
+// patternWatch returns the static base directory of pattern, watched recursively when the rest of the pattern spans
+// directories.
+func patternWatch(pattern string) string {
+	base, rest := doublestar.SplitPattern(pattern)
+	watch := filepath.FromSlash(base)
+	if strings.Contains(rest, "/") || strings.Contains(rest, "**") {
+		watch += recursiveSuffix
+	}
+	return watch
+}
+
+// match returns whether the events of path are kept.
+func (f *pathFilter) match(path string) bool {
+	slashed := filepath.ToSlash(path)
+	for _, pattern := range f.exclude {
+		if ok, _ := doublestar.Match(pattern, slashed); ok {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	if _, _, ok := f.plain.resolve(path); ok {
+		return true
+	}
+	for _, pattern := range f.include {
+		if ok, _ := doublestar.Match(pattern, slashed); ok {
+			return true
+		}
+	}
+	return false
+}
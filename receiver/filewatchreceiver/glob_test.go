@@ -0,0 +1,62 @@
+package filewatchreceiver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIncludes(t *testing.T) {
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+
+	t.Run("paths are watched as they are", func(t *testing.T) {
+		include := []string{"/var/log/...", "/etc/hosts"}
+		watches, filter, err := parseIncludes(include)
+		require.NoError(t, err)
+		require.Equal(t, include, watches)
+		require.Nil(t, filter)
+	})
+
+	t.Run("patterns are watched from their base directory", func(t *testing.T) {
+		watches, filter, err := parseIncludes([]string{"/var/log/**/*.log", "/etc/*.conf", "/var/log/app/*.log", "/etc/hosts"})
+		require.NoError(t, err)
+		require.Equal(t, []string{"/etc/hosts", "/var/log/...", "/etc", "/var/log/app"}, watches)
+		require.Equal(t, []string{"/var/log/**/*.log", "/etc/*.conf", "/var/log/app/*.log"}, filter.include)
+	})
+
+	t.Run("relative patterns are resolved against the working directory", func(t *testing.T) {
+		watches, filter, err := parseIncludes([]string{"logs/*.log", "!tmp/**", "!**/*.skip"})
+		require.NoError(t, err)
+		require.Equal(t, []string{filepath.Join(wd, "logs")}, watches)
+		require.Equal(t, []string{filepath.ToSlash(filepath.Join(wd, "tmp")) + "/**", "**/*.skip"}, filter.exclude)
+	})
+
+	t.Run("invalid patterns are rejected", func(t *testing.T) {
+		_, _, err := parseIncludes([]string{"/var/log/[*.log"})
+		require.ErrorContains(t, err, "invalid glob pattern")
+	})
+}
+
+func TestPathFilter(t *testing.T) {
+	_, filter, err := parseIncludes([]string{"/srv/data/...", "/var/log/**/*.log", "!**/tmp/**", "!**/*.skip"})
+	require.NoError(t, err)
+	for path, expected := range map[string]bool{
+		"/srv/data/file.txt":     true,
+		"/srv/data/tmp/file.txt": false,
+		"/srv/data/file.skip":    false,
+		"/var/log/app.log":       true,
+		"/var/log/app/app.log":   true,
+		"/var/log/app/app.txt":   false,
+		"/var/log/tmp/app.log":   false,
+	} {
+		require.Equal(t, expected, filter.match(path), path)
+	}
+
+	_, filter, err = parseIncludes([]string{"/srv/data/...", "!**/*.skip"})
+	require.NoError(t, err)
+	require.True(t, filter.match("/srv/data/file.txt"))
+	require.False(t, filter.match("/srv/data/file.skip"))
+}
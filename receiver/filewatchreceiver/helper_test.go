@@ -75,12 +75,11 @@ func beforeEach[A testing.TB](t A, should_create_inner_dir bool) (receiver.Logs,
 	time.Sleep(1000 * time.Millisecond)
 
 	include_path_0 := fmt.Sprintf("%v/...", include_dir)
-	include_path_1 := fmt.Sprintf(".*\\.ok")
+	include_path_1 := "!**/*.skip"
 	exclude_path_0 := fmt.Sprintf("%v/...", exclude_dir)
-	exclude_path_1 := fmt.Sprintf(".*\\.skip")
 	config := createDefaultConfig()
 	config.(*FileWatchReceiverConfig).Include = []string{include_path_0, include_path_1}
-	config.(*FileWatchReceiverConfig).Exclude = []string{exclude_path_0, exclude_path_1}
+	config.(*FileWatchReceiverConfig).Exclude = []string{exclude_path_0}
 	config.(*FileWatchReceiverConfig).Events = EVENTS_TO_WATCH
 
 	testLogsConsumer := new(consumertest.LogsSink)
@@ -0,0 +1,172 @@
+package filewatchreceiver
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/olandr/notify"
+	"go.uber.org/zap"
+)
+
+// includeFile holds the watches of the paths listed in an include file. The directory of the file is watched, rather
+// than the file itself, so that its replacement by editors or the symlink swaps of mounted ConfigMaps are noticed.
+type includeFile struct {
+	path string
+	// entries are the paths listed when the receiver was created.
+	entries []string
+	// changes receives the events of the directory of the include file.
+	changes chan notify.EventInfo
+
+	mu sync.Mutex
+	// subscriptions maps the watched paths to the channel of their watch. Each path is watched on a channel of its
+	// own, since notify can only remove the watches of a channel at once.
+	subscriptions map[string]chan notify.EventInfo
+	stopping      chan struct{}
+	wg            sync.WaitGroup
+}
+
+func newIncludeFile(path string) (*includeFile, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := readIncludeFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return &includeFile{
+		path:          path,
+		entries:       entries,
+		changes:       make(chan notify.EventInfo, 16),
+		subscriptions: make(map[string]chan notify.EventInfo),
+		stopping:      make(chan struct{}),
+	}, nil
+}
+
+// readIncludeFile reads the paths listed in an include file, one per line, skipping empty lines and comments. Relative
+// paths are resolved against the directory of the include file.
+func readIncludeFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the include file: %w", err)
+	}
+	defer f.Close()
+
+	dir := filepath.Dir(path)
+	var entries []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		entry := strings.TrimSpace(scanner.Text())
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		if !filepath.IsAbs(entry) {
+			entry = filepath.Join(dir, entry)
+		}
+		if !slices.Contains(entries, entry) {
+			entries = append(entries, entry)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read the include file: %w", err)
+	}
+	return entries, nil
+}
+
+// watchIncludeFile watches the directory of the include file and the paths it lists, and returns the number of listed
+// paths watched.
+func (fsn *FileWatcher) watchIncludeFile() (int, error) {
+	inc := fsn.includeFile
+	if err := fsn.notify.Watch(filepath.Dir(inc.path), inc.changes, notify.All); err != nil {
+		return 0, fmt.Errorf("cannot watch the include file: %w", err)
+	}
+	added, _ := fsn.syncIncludeFile(inc.entries)
+	return added, nil
+}
+
+// reloadIncludeFile reads the include file again and updates the watches to the paths it lists. The watches are kept
+// as they are when the file cannot be read, e.g. while it is being replaced.
+func (fsn *FileWatcher) reloadIncludeFile() {
+	entries, err := readIncludeFile(fsn.includeFile.path)
+	if err != nil {
+		fsn.logger.Warn("cannot reload the include file, keeping the current watches", zap.Error(err))
+		return
+	}
+	added, removed := fsn.syncIncludeFile(entries)
+	if added > 0 || removed > 0 {
+		fsn.logger.Info("reloaded the include file", zap.Int("added", added), zap.Int("removed", removed))
+		fsn.roots = newWatchRoots(append(slices.Clone(fsn.include), entries...))
+		if fsn.attrib != nil {
+			// the attributes of the paths no longer listed are not needed anymore
+			fsn.attrib.prune()
+		}
+	}
+}
+
+// syncIncludeFile watches the entries that are not watched yet, and stops watching the paths that are no longer listed.
+// The entries that are also include paths are skipped, their events would otherwise be emitted twice. It returns the
+// number of paths watched and unwatched.
+func (fsn *FileWatcher) syncIncludeFile(entries []string) (added, removed int) {
+	inc := fsn.includeFile
+	inc.mu.Lock()
+	defer inc.mu.Unlock()
+	for path, events := range inc.subscriptions {
+		if slices.Contains(entries, path) {
+			continue
+		}
+		fsn.notify.Stop(events)
+		close(events)
+		delete(inc.subscriptions, path)
+		removed++
+	}
+	for _, path := range entries {
+		if _, ok := inc.subscriptions[path]; ok || slices.Contains(fsn.include, path) {
+			continue
+		}
+		events := make(chan notify.EventInfo, 128)
+		// We are as lenient with the listed paths as with the include paths, a path failing to be watched is tried
+		// again on the next reload.
+		if err := fsn.notify.Watch(path, events, fsn.events.defaults); err != nil {
+			fsn.logger.Error("cannot create watch, skipping", zap.String("path", path), zap.Error(err))
+			continue
+		}
+		inc.subscriptions[path] = events
+		inc.wg.Add(1)
+		go inc.forward(events, fsn.watcher)
+		added++
+	}
+	return added, removed
+}
+
+// forward sends the events of a listed path to the watcher channel, until the path is unwatched or the receiver shut
+// down.
+func (inc *includeFile) forward(events <-chan notify.EventInfo, watcher chan<- notify.EventInfo) {
+	defer inc.wg.Done()
+	for event := range events {
+		select {
+		case watcher <- event:
+		case <-inc.stopping:
+			return
+		}
+	}
+}
+
+// stopIncludeFile removes the watches of the include file and of the paths it lists.
+func (fsn *FileWatcher) stopIncludeFile() {
+	inc := fsn.includeFile
+	close(inc.stopping)
+	fsn.notify.Stop(inc.changes)
+	inc.mu.Lock()
+	for path, events := range inc.subscriptions {
+		fsn.notify.Stop(events)
+		close(events)
+		delete(inc.subscriptions, path)
+	}
+	inc.mu.Unlock()
+	inc.wg.Wait()
+}
@@ -0,0 +1,77 @@
+package filewatchreceiver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestReadIncludeFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "watch.list")
+	content := "# watched paths\n/var/log/app\n\n  relative/...  \n/var/log/app\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	entries, err := readIncludeFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []string{"/var/log/app", filepath.Join(dir, "relative/...")}, entries)
+
+	_, err = readIncludeFile(filepath.Join(dir, "missing.list"))
+	require.ErrorContains(t, err, "cannot open the include file")
+}
+
+func TestIncludeFileReload(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first"), filepath.Join(dir, "second")
+	require.NoError(t, os.Mkdir(first, 0o777))
+	require.NoError(t, os.Mkdir(second, 0o777))
+	listDir := filepath.Join(dir, "list")
+	require.NoError(t, os.Mkdir(listDir, 0o777))
+	list := filepath.Join(listDir, "watch.list")
+	require.NoError(t, os.WriteFile(list, []byte(first+"\n"), 0o644))
+
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	cfg.IncludeFile = list
+	cfg.Events = EVENTS_TO_WATCH
+	sink := new(consumertest.LogsSink)
+	fsn, err := newNotify(cfg, sink, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	require.NoError(t, fsn.Start(t.Context(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, fsn.Shutdown(t.Context())) })
+
+	created := func(path string) func() bool {
+		return func() bool {
+			for lr := range logsIterator(sink.AllLogs()) {
+				if p, _ := lr.Attributes().Get("path"); p.Str() == path {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	require.NoError(t, os.WriteFile(filepath.Join(first, "a"), nil, 0o644))
+	require.Eventually(t, created(filepath.Join(first, "a")), 5*time.Second, 10*time.Millisecond)
+
+	// replacing the include file, as editors do, swaps the watches without restarting the receiver
+	replacement := filepath.Join(listDir, "watch.list.tmp")
+	require.NoError(t, os.WriteFile(replacement, []byte(second+"\n"), 0o644))
+	require.NoError(t, os.Rename(replacement, list))
+	require.Eventually(t, func() bool {
+		fsn.includeFile.mu.Lock()
+		defer fsn.includeFile.mu.Unlock()
+		_, watched := fsn.includeFile.subscriptions[second]
+		return watched && len(fsn.includeFile.subscriptions) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, os.WriteFile(filepath.Join(second, "b"), nil, 0o644))
+	require.Eventually(t, created(filepath.Join(second, "b")), 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(first, "c"), nil, 0o644))
+	require.Never(t, created(filepath.Join(first, "c")), 500*time.Millisecond, 10*time.Millisecond)
+}
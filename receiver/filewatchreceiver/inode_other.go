@@ -0,0 +1,9 @@
+//go:build !unix
+
+package filewatchreceiver
+
+import "os"
+
+func inodeOf(_ os.FileInfo) (uint64, bool) {
+	return 0, false
+}
@@ -0,0 +1,16 @@
+//go:build unix
+
+package filewatchreceiver
+
+import (
+	"os"
+	"syscall"
+)
+
+func inodeOf(fi os.FileInfo) (uint64, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Ino), true
+}
@@ -0,0 +1,159 @@
+package filewatchreceiver
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olandr/notify"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	// INTEGRITY_STATUS_ATTRIBUTE is the log attribute holding the result of the comparison of a file to the baseline.
+	INTEGRITY_STATUS_ATTRIBUTE = "file.integrity.status"
+	// HASH_ATTRIBUTE is the log attribute holding the SHA-256 hash of the content of a file, in hex.
+	HASH_ATTRIBUTE = "file.hash.sha256"
+
+	// INTEGRITY_OK is the status of a file whose content matches the baseline.
+	INTEGRITY_OK = "ok"
+	// INTEGRITY_MISMATCH is the status of a file whose content differs from the baseline.
+	INTEGRITY_MISMATCH = "mismatch"
+	// INTEGRITY_NEW is the status of a file created under a watched path without being listed in the baseline.
+	INTEGRITY_NEW = "new"
+)
+
+// integrityChecker compares the files listed in a baseline manifest to their expected hash when they are written or
+// created. It is not safe for concurrent use.
+type integrityChecker struct {
+	// baseline maps the absolute path of a file to its expected hash.
+	baseline map[string]string
+	hash     func(path string) (string, error)
+}
+
+func newIntegrityChecker(manifest string) (*integrityChecker, error) {
+	baseline, err := loadManifest(manifest)
+	if err != nil {
+		return nil, err
+	}
+	return &integrityChecker{baseline: baseline, hash: hashFile}, nil
+}
+
+// loadManifest reads a manifest in the sha256sum format, i.e. lines made of a hex hash, a space, a space or a '*',
+// and a path. Relative paths are resolved against the directory of the manifest.
+func loadManifest(manifest string) (map[string]string, error) {
+	f, err := os.Open(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the integrity manifest: %w", err)
+	}
+	defer f.Close()
+
+	dir := filepath.Dir(manifest)
+	baseline := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		hash, path, ok := strings.Cut(text, " ")
+		path = strings.TrimPrefix(strings.TrimPrefix(path, " "), "*")
+		if _, err := hex.DecodeString(hash); !ok || err != nil || len(hash) != 2*sha256.Size || path == "" {
+			return nil, fmt.Errorf("invalid integrity manifest entry on line %d of %v", line, manifest)
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if path, err = filepath.Abs(path); err != nil {
+			return nil, err
+		}
+		baseline[path] = strings.ToLower(hash)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read the integrity manifest: %w", err)
+	}
+	return baseline, nil
+}
+
+// check returns the integrity status of path after event, together with its hash when it was computed. Only the
+// paths listed in the baseline are hashed, on writes and creations, the creation of any other path is reported as
+// INTEGRITY_NEW. ok is false when the event is not subject to the check, or the file could not be read, e.g. because
+// it was removed since.
+func (c *integrityChecker) check(path string, event notify.Event) (status, hash string, ok bool) {
+	if event&(writeEvents|creationEvents) == 0 {
+		return "", "", false
+	}
+	expected, listed := c.baseline[path]
+	if !listed {
+		if event&creationEvents == 0 {
+			return "", "", false
+		}
+		if _, err := os.Lstat(path); err != nil {
+			return "", "", false
+		}
+		return INTEGRITY_NEW, "", true
+	}
+	hash, err := c.hash(path)
+	if err != nil {
+		return "", "", false
+	}
+	if hash != expected {
+		return INTEGRITY_MISMATCH, hash, true
+	}
+	return INTEGRITY_OK, hash, true
+}
+
+// annotate adds the integrity attributes to the log records of path in logs, elevating the severity of a mismatch.
+func (c *integrityChecker) annotate(logs plog.Logs, path string, event notify.Event) {
+	status, hash, ok := c.check(path, event)
+	if !ok {
+		return
+	}
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		resourceLogs := logs.ResourceLogs().At(i)
+		for j := 0; j < resourceLogs.ScopeLogs().Len(); j++ {
+			records := resourceLogs.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+				if p, found := record.Attributes().Get("path"); !found || p.Str() != path {
+					continue
+				}
+				record.Attributes().PutStr(INTEGRITY_STATUS_ATTRIBUTE, status)
+				if hash != "" {
+					record.Attributes().PutStr(HASH_ATTRIBUTE, hash)
+				}
+				if status == INTEGRITY_MISMATCH {
+					record.SetSeverityNumber(plog.SeverityNumberWarn)
+					record.SetSeverityText(plog.SeverityNumberWarn.String())
+				}
+			}
+		}
+	}
+}
+
+// hashFile returns the SHA-256 hash of the content of the regular file at path, in hex.
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !fi.Mode().IsRegular() {
+		return "", errors.New("not a regular file")
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,7 @@
+//go:build !linux
+
+package filewatchreceiver
+
+import "github.com/olandr/notify"
+
+var writeEvents = notify.Write
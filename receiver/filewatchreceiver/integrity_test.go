@@ -0,0 +1,112 @@
+package filewatchreceiver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func sha256Hex(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+func writeManifest(t *testing.T, dir string, entries map[string]string) string {
+	manifest := filepath.Join(dir, "manifest.sha256")
+	content := "# baseline\n"
+	for path, hash := range entries {
+		content += fmt.Sprintf("%v  %v\n", hash, path)
+	}
+	require.NoError(t, os.WriteFile(manifest, []byte(content), 0o600))
+	return manifest
+}
+
+func TestIntegrityChecker(t *testing.T) {
+	dir := t.TempDir()
+	listed := filepath.Join(dir, "listed")
+	require.NoError(t, os.WriteFile(listed, []byte("good"), 0o600))
+	manifest := writeManifest(t, dir, map[string]string{
+		"listed":                      sha256Hex("good"),
+		filepath.Join(dir, "missing"): sha256Hex("gone"),
+	})
+	c, err := newIntegrityChecker(manifest)
+	require.NoError(t, err)
+	ts := time.Unix(1700000000, 0)
+
+	t.Run("matching content is ok", func(t *testing.T) {
+		logs := createLogs(ts, listed, notify.Write.String())
+		c.annotate(logs, listed, notify.Write)
+		record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		requireAttr(t, record, INTEGRITY_STATUS_ATTRIBUTE, INTEGRITY_OK)
+		requireAttr(t, record, HASH_ATTRIBUTE, sha256Hex("good"))
+		require.Equal(t, plog.SeverityNumberInfo, record.SeverityNumber())
+	})
+
+	t.Run("different content is a mismatch with an elevated severity", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(listed, []byte("tampered"), 0o600))
+		t.Cleanup(func() { require.NoError(t, os.WriteFile(listed, []byte("good"), 0o600)) })
+
+		logs := createLogs(ts, listed, notify.Create.String())
+		c.annotate(logs, listed, notify.Create)
+		record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		requireAttr(t, record, INTEGRITY_STATUS_ATTRIBUTE, INTEGRITY_MISMATCH)
+		requireAttr(t, record, HASH_ATTRIBUTE, sha256Hex("tampered"))
+		require.Equal(t, plog.SeverityNumberWarn, record.SeverityNumber())
+		require.Equal(t, plog.SeverityNumberWarn.String(), record.SeverityText())
+	})
+
+	t.Run("created unlisted file is new", func(t *testing.T) {
+		status, hash, ok := c.check(manifest, notify.Create)
+		require.True(t, ok)
+		require.Equal(t, INTEGRITY_NEW, status)
+		require.Empty(t, hash)
+	})
+
+	t.Run("other events are not checked", func(t *testing.T) {
+		// a write to an unlisted file
+		_, _, ok := c.check(manifest, notify.Write)
+		require.False(t, ok)
+		// a removal of a listed file
+		_, _, ok = c.check(listed, notify.Remove)
+		require.False(t, ok)
+		// a listed file that cannot be read
+		_, _, ok = c.check(filepath.Join(dir, "missing"), notify.Write)
+		require.False(t, ok)
+	})
+}
+
+func TestLoadManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("parses the sha256sum format", func(t *testing.T) {
+		manifest := filepath.Join(dir, "valid")
+		hash := sha256Hex("a")
+		content := fmt.Sprintf("%v  /etc/a\n\n%v *b\n", hash, hash)
+		require.NoError(t, os.WriteFile(manifest, []byte(content), 0o600))
+
+		baseline, err := loadManifest(manifest)
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"/etc/a": hash, filepath.Join(dir, "b"): hash}, baseline)
+	})
+
+	t.Run("rejects invalid entries", func(t *testing.T) {
+		manifest := filepath.Join(dir, "invalid")
+		require.NoError(t, os.WriteFile(manifest, []byte("abc  /etc/a\n"), 0o600))
+
+		_, err := loadManifest(manifest)
+		require.ErrorContains(t, err, "invalid integrity manifest entry on line 1")
+	})
+
+	t.Run("fails on a missing manifest", func(t *testing.T) {
+		_, err := loadManifest(filepath.Join(dir, "missing"))
+		require.ErrorContains(t, err, "cannot open the integrity manifest")
+	})
+}
@@ -0,0 +1,122 @@
+package filewatchreceiver
+
+import (
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/olandr/notify"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	// MOVED_OPERATION is the operation reported when both sides of a rename are seen within the configured move
+	// window.
+	MOVED_OPERATION = "moved"
+	// PATH_FROM_ATTRIBUTE is the log attribute holding the path a moved file was renamed from.
+	PATH_FROM_ATTRIBUTE = "path.from"
+	// PATH_TO_ATTRIBUTE is the log attribute holding the path a moved file was renamed to.
+	PATH_TO_ATTRIBUTE = "path.to"
+)
+
+// pendingMove is a side of a rename that is held back until either the other side arrives, or the move window
+// expires.
+type pendingMove struct {
+	ts        time.Time
+	path      string
+	operation string
+	from      bool
+	deadline  time.Time
+}
+
+// moveCorrelator folds both sides of a rename, paired by the key the platform reports for them, into a single
+// MOVED_OPERATION event. The side seen alone within window is emitted as it is. It is not safe for concurrent use.
+type moveCorrelator struct {
+	window  time.Duration
+	pending map[uint64]*pendingMove
+	clock   clockwork.Clock
+	// side returns the key pairing the sides of the rename event is part of, and whether it is the side of the old
+	// path. ok is false for the events which are not part of a rename, or when the platform does not pair them.
+	side func(event notify.EventInfo) (key uint64, from bool, ok bool)
+}
+
+func newMoveCorrelator(window time.Duration, clock clockwork.Clock) *moveCorrelator {
+	return &moveCorrelator{
+		window:  window,
+		pending: make(map[uint64]*pendingMove),
+		clock:   clock,
+		side:    renameSide,
+	}
+}
+
+// observe handles a single event and returns the logs that are ready to be emitted. ok is false when the event is not
+// a side of a rename, and is left to be emitted as usual.
+func (m *moveCorrelator) observe(ts time.Time, event notify.EventInfo) (logs []plog.Logs, ok bool) {
+	key, from, ok := m.side(event)
+	if !ok {
+		return nil, false
+	}
+	p, found := m.pending[key]
+	if found && p.from != from {
+		delete(m.pending, key)
+		if from {
+			return []plog.Logs{createMovedLogs(p.ts, event.Path(), p.path)}, true
+		}
+		return []plog.Logs{createMovedLogs(p.ts, p.path, event.Path())}, true
+	}
+	if found {
+		// the same side seen twice is not the rename of a single file, the first one is emitted as it is
+		logs = append(logs, createLogs(p.ts, p.path, p.operation))
+	}
+	m.pending[key] = &pendingMove{
+		ts:        ts,
+		path:      event.Path(),
+		operation: operationOf(event.Event()),
+		from:      from,
+		deadline:  m.clock.Now().Add(m.window),
+	}
+	return logs, true
+}
+
+// expire returns the held back sides whose move window is over.
+func (m *moveCorrelator) expire() []plog.Logs {
+	now := m.clock.Now()
+	var ret []plog.Logs
+	for key, p := range m.pending {
+		if now.Before(p.deadline) {
+			continue
+		}
+		delete(m.pending, key)
+		ret = append(ret, createLogs(p.ts, p.path, p.operation))
+	}
+	return ret
+}
+
+// flush returns all the held back sides, regardless of their deadline.
+func (m *moveCorrelator) flush() []plog.Logs {
+	ret := make([]plog.Logs, 0, len(m.pending))
+	for _, p := range m.pending {
+		ret = append(ret, createLogs(p.ts, p.path, p.operation))
+	}
+	clear(m.pending)
+	return ret
+}
+
+// nextDeadline returns the earliest deadline of the held back sides, if any.
+func (m *moveCorrelator) nextDeadline() (time.Time, bool) {
+	var next time.Time
+	for _, p := range m.pending {
+		if next.IsZero() || p.deadline.Before(next) {
+			next = p.deadline
+		}
+	}
+	return next, !next.IsZero()
+}
+
+// createMovedLogs returns the logs of a move, whose path is the new path of the file.
+func createMovedLogs(ts time.Time, from, to string) plog.Logs {
+	logs := createLogs(ts, to, MOVED_OPERATION)
+	attrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	attrs.PutStr(PATH_FROM_ATTRIBUTE, from)
+	attrs.PutStr(PATH_TO_ATTRIBUTE, to)
+	return logs
+}
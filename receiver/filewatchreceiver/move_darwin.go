@@ -0,0 +1,23 @@
+//go:build darwin
+
+package filewatchreceiver
+
+import (
+	"errors"
+	"os"
+
+	"github.com/olandr/notify"
+)
+
+// renameSide pairs the sides of a rename by their FSEvents id: both sides have consecutive ids, the side of the old
+// path, which no longer exists, coming first.
+func renameSide(event notify.EventInfo) (uint64, bool, bool) {
+	fse, ok := event.Sys().(*notify.FSEvent)
+	if !ok || fse.Flags&uint32(notify.FSEventsRenamed) == 0 {
+		return 0, false, false
+	}
+	if _, err := os.Lstat(fse.Path); errors.Is(err, os.ErrNotExist) {
+		return fse.ID + 1, true, true
+	}
+	return fse.ID, false, true
+}
@@ -0,0 +1,23 @@
+//go:build linux
+
+package filewatchreceiver
+
+import (
+	"github.com/olandr/notify"
+	"golang.org/x/sys/unix"
+)
+
+// renameSide pairs the sides of a rename by their inotify cookie.
+func renameSide(event notify.EventInfo) (uint64, bool, bool) {
+	sys, ok := event.Sys().(*unix.InotifyEvent)
+	if !ok || sys.Cookie == 0 {
+		return 0, false, false
+	}
+	switch {
+	case sys.Mask&unix.IN_MOVED_FROM != 0:
+		return uint64(sys.Cookie), true, true
+	case sys.Mask&unix.IN_MOVED_TO != 0:
+		return uint64(sys.Cookie), false, true
+	}
+	return 0, false, false
+}
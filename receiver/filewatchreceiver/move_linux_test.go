@@ -0,0 +1,36 @@
+//go:build linux
+
+package filewatchreceiver
+
+import (
+	"testing"
+
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// inotifyEvent is an event reported by inotify.
+type inotifyEvent struct {
+	fakeEvent
+	sys unix.InotifyEvent
+}
+
+func (e inotifyEvent) Sys() interface{} { return &e.sys }
+
+func TestRenameSide(t *testing.T) {
+	key, from, ok := renameSide(inotifyEvent{fakeEvent{"/a", notify.InMovedFrom}, unix.InotifyEvent{Mask: unix.IN_MOVED_FROM, Cookie: 7}})
+	require.True(t, ok)
+	require.True(t, from)
+	require.Equal(t, uint64(7), key)
+
+	key, from, ok = renameSide(inotifyEvent{fakeEvent{"/b", notify.Create}, unix.InotifyEvent{Mask: unix.IN_MOVED_TO, Cookie: 7}})
+	require.True(t, ok)
+	require.False(t, from)
+	require.Equal(t, uint64(7), key)
+
+	_, _, ok = renameSide(inotifyEvent{fakeEvent{"/b", notify.Create}, unix.InotifyEvent{Mask: unix.IN_CREATE}})
+	require.False(t, ok)
+	_, _, ok = renameSide(fakeEvent{"/b", notify.Create})
+	require.False(t, ok)
+}
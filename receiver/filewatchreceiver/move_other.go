@@ -0,0 +1,10 @@
+//go:build !linux && !darwin
+
+package filewatchreceiver
+
+import "github.com/olandr/notify"
+
+// renameSide does not pair the sides of the renames, the platform does not report a key for them.
+func renameSide(_ notify.EventInfo) (uint64, bool, bool) {
+	return 0, false, false
+}
@@ -0,0 +1,109 @@
+package filewatchreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+)
+
+// renameEvent is a side of a rename, paired by its cookie.
+type renameEvent struct {
+	fakeEvent
+	cookie uint64
+	from   bool
+}
+
+func newTestMoveCorrelator(clock clockwork.Clock) *moveCorrelator {
+	m := newMoveCorrelator(time.Second, clock)
+	m.side = func(event notify.EventInfo) (uint64, bool, bool) {
+		e, ok := event.(renameEvent)
+		if !ok {
+			return 0, false, false
+		}
+		return e.cookie, e.from, true
+	}
+	return m
+}
+
+func TestMoveCorrelator(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+	from := renameEvent{fakeEvent{path: "/a", event: notify.Rename}, 1, true}
+	to := renameEvent{fakeEvent{path: "/b", event: notify.Create}, 1, false}
+
+	t.Run("both sides within window are moved", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(ts)
+		m := newTestMoveCorrelator(clock)
+
+		logs, ok := m.observe(ts, from)
+		require.True(t, ok)
+		require.Empty(t, logs)
+
+		clock.Advance(500 * time.Millisecond)
+		logs, ok = m.observe(ts.Add(time.Second), to)
+		require.True(t, ok)
+		records := recordsOf(logs)
+		require.Len(t, records, 1)
+		requireAttr(t, records[0], "path", "/b")
+		requireAttr(t, records[0], "operation", MOVED_OPERATION)
+		requireAttr(t, records[0], PATH_FROM_ATTRIBUTE, "/a")
+		requireAttr(t, records[0], PATH_TO_ATTRIBUTE, "/b")
+		require.True(t, ts.Equal(records[0].Timestamp().AsTime()), "the time of the first side")
+
+		_, ok = m.nextDeadline()
+		require.False(t, ok)
+	})
+
+	t.Run("the new path can be seen first", func(t *testing.T) {
+		m := newTestMoveCorrelator(clockwork.NewFakeClockAt(ts))
+		logs, _ := m.observe(ts, to)
+		require.Empty(t, logs)
+		logs, _ = m.observe(ts, from)
+		records := recordsOf(logs)
+		require.Len(t, records, 1)
+		requireAttr(t, records[0], PATH_FROM_ATTRIBUTE, "/a")
+		requireAttr(t, records[0], PATH_TO_ATTRIBUTE, "/b")
+	})
+
+	t.Run("a single side is emitted once the window expires", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(ts)
+		m := newTestMoveCorrelator(clock)
+
+		logs, _ := m.observe(ts, from)
+		require.Empty(t, logs)
+		deadline, ok := m.nextDeadline()
+		require.True(t, ok)
+		require.Equal(t, ts.Add(time.Second), deadline)
+		require.Empty(t, m.expire())
+
+		clock.Advance(time.Second)
+		records := recordsOf(m.expire())
+		require.Len(t, records, 1)
+		requireAttr(t, records[0], "path", "/a")
+		requireAttr(t, records[0], "operation", notify.Rename.String())
+	})
+
+	t.Run("other cookies are not paired", func(t *testing.T) {
+		m := newTestMoveCorrelator(clockwork.NewFakeClockAt(ts))
+		m.observe(ts, from)
+		m.observe(ts, renameEvent{fakeEvent{path: "/c", event: notify.Create}, 2, false})
+		require.Len(t, recordsOf(m.flush()), 2)
+	})
+
+	t.Run("the same side twice emits the first one", func(t *testing.T) {
+		m := newTestMoveCorrelator(clockwork.NewFakeClockAt(ts))
+		m.observe(ts, from)
+		logs, _ := m.observe(ts, renameEvent{fakeEvent{path: "/c", event: notify.Rename}, 1, true})
+		records := recordsOf(logs)
+		require.Len(t, records, 1)
+		requireAttr(t, records[0], "path", "/a")
+	})
+
+	t.Run("other events are left as they are", func(t *testing.T) {
+		m := newTestMoveCorrelator(clockwork.NewFakeClockAt(ts))
+		_, ok := m.observe(ts, fakeEvent{path: "/a", event: notify.Write})
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,103 @@
+package filewatchreceiver
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/olandr/notify"
+)
+
+// defaultOperations are the events watched when no event is configured.
+const defaultOperations = notify.Create | notify.Write | notify.Remove | notify.Rename
+
+// operations maps the platform independent operation names to their events. The "chmod" operation is mapped to
+// chmodEvents, which depend on the platform.
+var operations = map[string]notify.Event{
+	"create": notify.Create,
+	"write":  notify.Write,
+	"remove": notify.Remove,
+	"rename": notify.Rename,
+}
+
+// parseEvents returns the events to watch for names, either operation names, e.g. "write", or notify event names,
+// e.g. "notify.InCloseWrite". It returns defaultOperations when names is empty.
+func parseEvents(names []string) (notify.Event, error) {
+	if len(names) == 0 {
+		return defaultOperations, nil
+	}
+	var events notify.Event
+	for _, name := range names {
+		if strings.HasPrefix(name, "notify.") {
+			ev, ok := notify.NewEventFromString(name)
+			if !ok {
+				return 0, fmt.Errorf("cannot create watch for the supplied event name: %v", name)
+			}
+			events |= ev
+			continue
+		}
+		switch ev, ok := operations[name]; {
+		case ok:
+			events |= ev
+		case name == "chmod" && chmodEvents != 0:
+			events |= chmodEvents
+		case name == "chmod":
+			return 0, fmt.Errorf("the 'chmod' operation is not supported on this platform")
+		default:
+			return 0, fmt.Errorf("unknown operation %q, expected one of create, write, remove, rename or chmod", name)
+		}
+	}
+	return events, nil
+}
+
+// watchEvents holds the events watched for each include root, as resolved by watchRoots.
+type watchEvents struct {
+	// defaults are the events watched for the roots without events of their own.
+	defaults notify.Event
+	// roots maps the include roots to their events.
+	roots map[string]notify.Event
+}
+
+// newWatchEvents returns the events watched for the include paths, the ones listed in pathEvents, keyed by the include
+// path as configured, being watched for the events listed there rather than the default ones.
+func newWatchEvents(include []string, defaults []string, pathEvents map[string][]string) (watchEvents, error) {
+	w := watchEvents{roots: make(map[string]notify.Event)}
+	var err error
+	if w.defaults, err = parseEvents(defaults); err != nil {
+		return w, err
+	}
+	for path, names := range pathEvents {
+		if !slices.Contains(include, path) || strings.HasPrefix(path, negationPrefix) {
+			return w, fmt.Errorf("'path_events' lists %q, which is not an 'include' path", path)
+		}
+		events, err := parseEvents(names)
+		if err != nil {
+			return w, fmt.Errorf("'path_events' of %q: %w", path, err)
+		}
+		watch, err := watchOf(path)
+		if err != nil {
+			return w, err
+		}
+		root := strings.TrimSuffix(watch, recursiveSuffix)
+		// the patterns sharing their base directory are watched for the events of all of them
+		w.roots[root] |= events
+	}
+	return w, nil
+}
+
+// of returns the events watched for the include root.
+func (w watchEvents) of(root string) notify.Event {
+	if events, ok := w.roots[root]; ok {
+		return events
+	}
+	return w.defaults
+}
+
+// all returns the events watched for any of the include roots.
+func (w watchEvents) all() notify.Event {
+	events := w.defaults
+	for _, e := range w.roots {
+		events |= e
+	}
+	return events
+}
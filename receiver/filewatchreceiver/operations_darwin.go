@@ -0,0 +1,8 @@
+//go:build darwin
+
+package filewatchreceiver
+
+import "github.com/olandr/notify"
+
+// chmodEvents are the events of the changes of the metadata of the files, e.g. their mode or owner.
+var chmodEvents = notify.FSEventsInodeMetaMod | notify.FSEventsChangeOwner
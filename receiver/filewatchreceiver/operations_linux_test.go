@@ -0,0 +1,16 @@
+//go:build linux
+
+package filewatchreceiver
+
+import (
+	"testing"
+
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNotifyEvents(t *testing.T) {
+	events, err := parseEvents([]string{"write", "notify.InCloseWrite"})
+	require.NoError(t, err)
+	require.Equal(t, notify.Write|notify.InCloseWrite, events)
+}
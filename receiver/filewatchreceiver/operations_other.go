@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package filewatchreceiver
+
+import "github.com/olandr/notify"
+
+// chmodEvents is empty, the changes of the metadata of the files are not watched on these platforms.
+var chmodEvents notify.Event
@@ -0,0 +1,76 @@
+package filewatchreceiver
+
+import (
+	"context"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestParseEvents(t *testing.T) {
+	events, err := parseEvents(nil)
+	require.NoError(t, err)
+	require.Equal(t, defaultOperations, events)
+
+	events, err = parseEvents([]string{"create", "remove"})
+	require.NoError(t, err)
+	require.Equal(t, notify.Create|notify.Remove, events)
+
+	events, err = parseEvents([]string{"chmod"})
+	if runtime.GOOS == "linux" || runtime.GOOS == "darwin" {
+		require.NoError(t, err)
+		require.Equal(t, chmodEvents, events)
+	} else {
+		require.ErrorContains(t, err, "not supported on this platform")
+	}
+
+	_, err = parseEvents([]string{"modify"})
+	require.ErrorContains(t, err, `unknown operation "modify"`)
+	_, err = parseEvents([]string{"notify.Unknown"})
+	require.ErrorContains(t, err, "cannot create watch for the supplied event name")
+}
+
+func TestWatchEvents(t *testing.T) {
+	include := []string{"/srv/...", "/srv/data", "/var/log/**/*.log", "/var/log/*.txt", "!**/*.skip"}
+	events, err := newWatchEvents(include, []string{"create"}, map[string][]string{
+		"/srv/data":         {"write"},
+		"/var/log/**/*.log": {"write"},
+		"/var/log/*.txt":    {"remove"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, notify.Create, events.of("/srv"))
+	require.Equal(t, notify.Write, events.of("/srv/data"))
+	require.Equal(t, notify.Write|notify.Remove, events.of("/var/log"), "the patterns sharing their base directory")
+
+	_, err = newWatchEvents(include, nil, map[string][]string{"/etc": {"write"}})
+	require.ErrorContains(t, err, `"/etc", which is not an 'include' path`)
+	_, err = newWatchEvents(include, nil, map[string][]string{"!**/*.skip": {"write"}})
+	require.ErrorContains(t, err, "which is not an 'include' path")
+	_, err = newWatchEvents(include, nil, map[string][]string{"/srv/data": {"modify"}})
+	require.ErrorContains(t, err, `'path_events' of "/srv/data": unknown operation`)
+}
+
+func TestPathEventsFilter(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	cfg.Include = []string{dir + recursiveSuffix, filepath.Join(dir, "data")}
+	cfg.Events = []string{"create", "write"}
+	cfg.PathEvents = map[string][]string{filepath.Join(dir, "data"): {"create"}}
+	sink := new(consumertest.LogsSink)
+	fsn, err := newNotify(cfg, sink, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	// the most specific include path decides which events are kept
+	fsn.handle(context.Background(), fakeEvent{path: filepath.Join(dir, "file"), event: notify.Write})
+	fsn.handle(context.Background(), fakeEvent{path: filepath.Join(dir, "data", "file"), event: notify.Write})
+	fsn.handle(context.Background(), fakeEvent{path: filepath.Join(dir, "data", "file"), event: notify.Create})
+	records := recordsOf(sink.AllLogs())
+	require.Len(t, records, 2)
+	requireAttr(t, records[0], "path", filepath.Join(dir, "file"))
+	requireAttr(t, records[1], "operation", notify.Create.String())
+}
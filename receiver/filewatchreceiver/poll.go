@@ -0,0 +1,155 @@
+package filewatchreceiver
+
+import (
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/olandr/notify"
+)
+
+// pollEvent is an event found by the poller.
+type pollEvent struct {
+	path      string
+	event     notify.Event
+	timestamp int64
+}
+
+func (e pollEvent) Timestamp() int64    { return e.timestamp }
+func (e pollEvent) Event() notify.Event { return e.event }
+func (e pollEvent) Path() string        { return e.path }
+func (e pollEvent) Sys() interface{}    { return nil }
+
+// polledFile is the state of a polled path, compared from one scan to the next.
+type polledFile struct {
+	dir     bool
+	size    int64
+	modTime time.Time
+	attrs   fileAttributes
+}
+
+// poller scans the include paths which cannot be watched natively, e.g. on network filesystems, every interval, and
+// reports the differences between two scans as events. The paths are added before the poller is started.
+type poller struct {
+	interval time.Duration
+	clock    clockwork.Clock
+	exclude  []*regexp.Regexp
+	// paths are the polled include paths.
+	paths []string
+	// files is the state of the paths found by the last scan.
+	files    map[string]polledFile
+	stopping chan struct{}
+	wg       sync.WaitGroup
+}
+
+func newPoller(interval time.Duration, clock clockwork.Clock, exclude []*regexp.Regexp) *poller {
+	return &poller{
+		interval: interval,
+		clock:    clock,
+		exclude:  exclude,
+		files:    make(map[string]polledFile),
+		stopping: make(chan struct{}),
+	}
+}
+
+// add polls the include path, the paths existing under it being the baseline of the following scans. It returns false
+// when the path does not exist.
+func (p *poller) add(path string) bool {
+	if _, err := os.Stat(strings.TrimSuffix(path, recursiveSuffix)); err != nil {
+		return false
+	}
+	p.paths = append(p.paths, path)
+	for _, existing := range existingPaths([]string{path}, p.exclude) {
+		if f, ok := statPolled(existing); ok {
+			p.files[existing] = f
+		}
+	}
+	return true
+}
+
+// polling returns whether any path is polled.
+func (p *poller) polling() bool {
+	return len(p.paths) > 0
+}
+
+// scan returns the events of the differences between the paths found now and by the previous scan, ordered by path:
+// the creations, the removals, the writes, changing the size or the modification time of a file, and the changes of
+// the metadata of the paths on the platforms watching them.
+func (p *poller) scan() []notify.EventInfo {
+	now := p.clock.Now().Unix()
+	current := make(map[string]polledFile)
+	for _, path := range existingPaths(p.paths, p.exclude) {
+		if f, ok := statPolled(path); ok {
+			current[path] = f
+		}
+	}
+	var events []notify.EventInfo
+	for path, f := range current {
+		before, ok := p.files[path]
+		switch {
+		case !ok:
+			events = append(events, pollEvent{path: path, event: notify.Create, timestamp: now})
+		case !f.dir && (f.size != before.size || !f.modTime.Equal(before.modTime)):
+			events = append(events, pollEvent{path: path, event: notify.Write, timestamp: now})
+		case f.attrs != before.attrs && chmodEvents != 0:
+			events = append(events, pollEvent{path: path, event: chmodEvents, timestamp: now})
+		}
+	}
+	for path := range p.files {
+		if _, ok := current[path]; !ok {
+			events = append(events, pollEvent{path: path, event: notify.Remove, timestamp: now})
+		}
+	}
+	p.files = current
+	slices.SortFunc(events, func(a, b notify.EventInfo) int {
+		return strings.Compare(a.Path(), b.Path())
+	})
+	return events
+}
+
+// start scans the polled paths every interval, sending their events to watcher, until the poller is stopped.
+func (p *poller) start(watcher chan<- notify.EventInfo) {
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := p.clock.NewTicker(p.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stopping:
+				return
+			case <-ticker.Chan():
+				for _, event := range p.scan() {
+					select {
+					case watcher <- event:
+					case <-p.stopping:
+						return
+					}
+				}
+			}
+		}
+	}()
+}
+
+// stop stops the scans, and waits for the events being sent.
+func (p *poller) stop() {
+	close(p.stopping)
+	p.wg.Wait()
+}
+
+func statPolled(path string) (polledFile, bool) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return polledFile{}, false
+	}
+	return polledFile{
+		dir:     fi.IsDir(),
+		size:    fi.Size(),
+		modTime: fi.ModTime(),
+		attrs:   attributesOf(fi),
+	}, true
+}
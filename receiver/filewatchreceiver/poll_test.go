@@ -0,0 +1,87 @@
+package filewatchreceiver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func requirePolled(t *testing.T, events []notify.EventInfo, expected ...pollEvent) {
+	require.Len(t, events, len(expected))
+	for i, e := range expected {
+		require.Equal(t, e.path, events[i].Path())
+		require.Equal(t, e.event, events[i].Event())
+	}
+}
+
+func TestPoller(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "existing.log")
+	require.NoError(t, os.WriteFile(existing, []byte("a"), 0o600))
+	require.NoError(t, os.Chmod(existing, 0o600))
+	p := newPoller(time.Second, clockwork.NewFakeClock(), []*regexp.Regexp{regexp.MustCompile(`\.skip$`)})
+	require.True(t, p.add(dir+recursiveSuffix))
+	require.False(t, p.add(filepath.Join(dir, "missing")))
+	require.True(t, p.polling())
+
+	// the paths existing when added are the baseline
+	require.Empty(t, p.scan())
+
+	created := filepath.Join(dir, "sub", "created.log")
+	require.NoError(t, os.Mkdir(filepath.Dir(created), 0o755))
+	require.NoError(t, os.WriteFile(created, nil, 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "excluded.skip"), nil, 0o600))
+	requirePolled(t, p.scan(),
+		pollEvent{path: filepath.Dir(created), event: notify.Create},
+		pollEvent{path: created, event: notify.Create})
+
+	require.NoError(t, os.WriteFile(existing, []byte("ab"), 0o600))
+	require.NoError(t, os.Remove(created))
+	requirePolled(t, p.scan(),
+		pollEvent{path: existing, event: notify.Write},
+		pollEvent{path: created, event: notify.Remove})
+
+	require.NoError(t, os.Chmod(existing, 0o644))
+	if chmodEvents != 0 {
+		requirePolled(t, p.scan(), pollEvent{path: existing, event: chmodEvents})
+	} else {
+		require.Empty(t, p.scan())
+	}
+	require.Empty(t, p.scan())
+}
+
+func TestForcePolling(t *testing.T) {
+	dir := t.TempDir()
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	cfg.Include = []string{dir}
+	cfg.ForcePolling = true
+	cfg.PollInterval = time.Second
+	sink := new(consumertest.LogsSink)
+	clock := clockwork.NewFakeClock()
+	fsn, err := newNotify(cfg, sink, receivertest.NewNopSettings(Type), withClock(clock))
+	require.NoError(t, err)
+	require.NoError(t, fsn.Start(context.Background(), componenttest.NewNopHost()))
+
+	path := filepath.Join(dir, "created.log")
+	require.NoError(t, os.WriteFile(path, nil, 0o600))
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, clock.BlockUntilContext(ctx, 1))
+	clock.Advance(cfg.PollInterval)
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	records := recordsOf(sink.AllLogs())
+	requireAttr(t, records[0], "path", path)
+	requireAttr(t, records[0], "operation", notify.Create.String())
+
+	require.NoError(t, fsn.Shutdown(context.Background()))
+}
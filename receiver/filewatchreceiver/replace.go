@@ -0,0 +1,135 @@
+package filewatchreceiver
+
+import (
+	"errors"
+	"os"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/olandr/notify"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// REPLACED_OPERATION is the operation reported when a path is removed (or moved away) and
+// created again within the configured replace window.
+const REPLACED_OPERATION = "replaced"
+
+// pendingRemoval is a removal that is held back until either a creation of the same path
+// arrives, or the replace window expires.
+type pendingRemoval struct {
+	ts        time.Time
+	operation string
+	deadline  time.Time
+	inode     uint64
+	has_inode bool
+}
+
+// replaceCorrelator folds a removal and a creation of the same path, happening within window,
+// into a single REPLACED_OPERATION event. It is not safe for concurrent use.
+type replaceCorrelator struct {
+	window  time.Duration
+	pending map[string]*pendingRemoval
+	inodes  map[string]uint64
+	clock   clockwork.Clock
+	stat    func(path string) (inode uint64, exists bool, has_inode bool)
+}
+
+func newReplaceCorrelator(window time.Duration, clock clockwork.Clock) *replaceCorrelator {
+	return &replaceCorrelator{
+		window:  window,
+		pending: make(map[string]*pendingRemoval),
+		inodes:  make(map[string]uint64),
+		clock:   clock,
+		stat:    statInode,
+	}
+}
+
+// observe handles a single event and returns the logs that are ready to be emitted.
+func (r *replaceCorrelator) observe(ts time.Time, path string, event notify.Event) []plog.Logs {
+	inode, exists, has_inode := r.stat(path)
+	removal := event&removalEvents != 0 && !exists
+	creation := event&creationEvents != 0 && exists
+
+	switch {
+	case removal:
+		var ret []plog.Logs
+		if p, ok := r.pending[path]; ok {
+			ret = append(ret, createLogs(p.ts, path, p.operation))
+		}
+		p := &pendingRemoval{ts: ts, operation: operationOf(event), deadline: r.clock.Now().Add(r.window)}
+		p.inode, p.has_inode = r.inodes[path]
+		delete(r.inodes, path)
+		r.pending[path] = p
+		return ret
+	case creation:
+		if has_inode {
+			r.inodes[path] = inode
+		}
+		if p, ok := r.pending[path]; ok {
+			delete(r.pending, path)
+			return []plog.Logs{createReplacedLogs(ts, path, p, inode, has_inode)}
+		}
+	default:
+		if exists && has_inode {
+			r.inodes[path] = inode
+		}
+	}
+	return []plog.Logs{createLogs(ts, path, operationOf(event))}
+}
+
+// expire returns the held back removals whose replace window is over.
+func (r *replaceCorrelator) expire() []plog.Logs {
+	now := r.clock.Now()
+	var ret []plog.Logs
+	for path, p := range r.pending {
+		if now.Before(p.deadline) {
+			continue
+		}
+		delete(r.pending, path)
+		ret = append(ret, createLogs(p.ts, path, p.operation))
+	}
+	return ret
+}
+
+// flush returns all the held back removals, regardless of their deadline.
+func (r *replaceCorrelator) flush() []plog.Logs {
+	ret := make([]plog.Logs, 0, len(r.pending))
+	for path, p := range r.pending {
+		ret = append(ret, createLogs(p.ts, path, p.operation))
+	}
+	clear(r.pending)
+	return ret
+}
+
+// nextDeadline returns the earliest deadline of the held back removals, if any.
+func (r *replaceCorrelator) nextDeadline() (time.Time, bool) {
+	var next time.Time
+	for _, p := range r.pending {
+		if next.IsZero() || p.deadline.Before(next) {
+			next = p.deadline
+		}
+	}
+	return next, !next.IsZero()
+}
+
+func createReplacedLogs(ts time.Time, path string, removed *pendingRemoval, inode uint64, has_inode bool) plog.Logs {
+	logs := createLogs(ts, path, REPLACED_OPERATION)
+	attrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	attrs.PutStr("replaced.operation", removed.operation)
+	if removed.has_inode {
+		attrs.PutInt("inode.previous", int64(removed.inode))
+	}
+	if has_inode {
+		attrs.PutInt("inode.current", int64(inode))
+	}
+	return logs
+}
+
+func statInode(path string) (uint64, bool, bool) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return 0, !errors.Is(err, os.ErrNotExist), false
+	}
+	inode, ok := inodeOf(fi)
+	return inode, true, ok
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package filewatchreceiver
+
+import "github.com/olandr/notify"
+
+var (
+	removalEvents  = notify.Remove | notify.Rename
+	creationEvents = notify.Create | notify.Rename
+)
@@ -0,0 +1,105 @@
+package filewatchreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+type fakeFile struct {
+	inode  uint64
+	exists bool
+}
+
+func newTestReplaceCorrelator(files map[string]fakeFile, clock clockwork.Clock) *replaceCorrelator {
+	r := newReplaceCorrelator(time.Second, clock)
+	r.stat = func(path string) (uint64, bool, bool) {
+		f := files[path]
+		return f.inode, f.exists, f.exists
+	}
+	return r
+}
+
+func recordsOf(logs []plog.Logs) []plog.LogRecord {
+	ret := make([]plog.LogRecord, 0)
+	for lr := range logsIterator(logs) {
+		ret = append(ret, lr)
+	}
+	return ret
+}
+
+func requireAttr(t *testing.T, lr plog.LogRecord, key string, expected any) {
+	v, ok := lr.Attributes().Get(key)
+	require.True(t, ok, "missing attribute %v", key)
+	require.Equal(t, expected, v.AsRaw())
+}
+
+func TestReplaceCorrelator(t *testing.T) {
+	ts := time.Unix(1700000000, 0)
+
+	t.Run("remove and create within window is replaced", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(ts)
+		files := map[string]fakeFile{"/a": {inode: 10, exists: true}}
+		r := newTestReplaceCorrelator(files, clock)
+
+		require.Len(t, recordsOf(r.observe(ts, "/a", notify.Write)), 1)
+
+		files["/a"] = fakeFile{}
+		require.Empty(t, r.observe(ts, "/a", notify.Remove))
+
+		clock.Advance(500 * time.Millisecond)
+		files["/a"] = fakeFile{inode: 11, exists: true}
+		records := recordsOf(r.observe(ts, "/a", notify.Create))
+		require.Len(t, records, 1)
+		requireAttr(t, records[0], "path", "/a")
+		requireAttr(t, records[0], "operation", REPLACED_OPERATION)
+		requireAttr(t, records[0], "replaced.operation", notify.Remove.String())
+		requireAttr(t, records[0], "inode.previous", int64(10))
+		requireAttr(t, records[0], "inode.current", int64(11))
+
+		_, ok := r.nextDeadline()
+		require.False(t, ok)
+	})
+
+	t.Run("remove without create is emitted once the window expires", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(ts)
+		files := map[string]fakeFile{}
+		r := newTestReplaceCorrelator(files, clock)
+
+		require.Empty(t, r.observe(ts, "/a", notify.Remove))
+		deadline, ok := r.nextDeadline()
+		require.True(t, ok)
+		require.Equal(t, ts.Add(time.Second), deadline)
+		require.Empty(t, r.expire())
+
+		clock.Advance(deadline.Sub(clock.Now()))
+		records := recordsOf(r.expire())
+		require.Len(t, records, 1)
+		requireAttr(t, records[0], "operation", notify.Remove.String())
+
+		// A creation after the window is reported as is.
+		files["/a"] = fakeFile{inode: 11, exists: true}
+		records = recordsOf(r.observe(ts, "/a", notify.Create))
+		require.Len(t, records, 1)
+		requireAttr(t, records[0], "operation", notify.Create.String())
+	})
+
+	t.Run("create of another path is not correlated", func(t *testing.T) {
+		clock := clockwork.NewFakeClockAt(ts)
+		files := map[string]fakeFile{"/b": {inode: 12, exists: true}}
+		r := newTestReplaceCorrelator(files, clock)
+
+		require.Empty(t, r.observe(ts, "/a", notify.Remove))
+		records := recordsOf(r.observe(ts, "/b", notify.Create))
+		require.Len(t, records, 1)
+		requireAttr(t, records[0], "operation", notify.Create.String())
+
+		records = recordsOf(r.flush())
+		require.Len(t, records, 1)
+		requireAttr(t, records[0], "path", "/a")
+	})
+}
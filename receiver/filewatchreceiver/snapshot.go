@@ -0,0 +1,59 @@
+package filewatchreceiver
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// EXISTING_OPERATION is the operation of the records emitted on start for the files existing under the include paths.
+const EXISTING_OPERATION = "existing"
+
+// existingPaths returns the paths existing under the include paths, as their watches report them: the entries of the
+// directories, recursively for the include paths ending with "/...", and the files themselves. The paths matching one
+// of the exclude patterns are skipped, as notify skips their events. The include paths which do not exist are skipped.
+func existingPaths(include []string, exclude []*regexp.Regexp) []string {
+	var paths []string
+	seen := make(map[string]struct{})
+	add := func(path string) {
+		if _, ok := seen[path]; ok {
+			return
+		}
+		seen[path] = struct{}{}
+		for _, re := range exclude {
+			if re.MatchString(path) {
+				return
+			}
+		}
+		paths = append(paths, path)
+	}
+	for _, include := range include {
+		root, err := filepath.Abs(strings.TrimSuffix(include, recursiveSuffix))
+		if err != nil {
+			continue
+		}
+		fi, err := os.Stat(root)
+		switch {
+		case err != nil:
+			continue
+		case !fi.IsDir():
+			add(root)
+		case strings.HasSuffix(include, recursiveSuffix):
+			_ = filepath.WalkDir(root, func(path string, _ fs.DirEntry, err error) error {
+				// the directories which cannot be read are skipped, the walk goes on with the others
+				if err == nil && path != root {
+					add(path)
+				}
+				return nil
+			})
+		default:
+			entries, _ := os.ReadDir(root)
+			for _, entry := range entries {
+				add(filepath.Join(root, entry.Name()))
+			}
+		}
+	}
+	return paths
+}
@@ -0,0 +1,98 @@
+package filewatchreceiver
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+)
+
+func TestExistingPaths(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "c"), 0o755))
+	for _, path := range []string{"a/1.txt", "a/b/2.txt", "a/b/3.skip", "c/4.txt"} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(path), 0o600))
+	}
+	abs := func(paths ...string) []string {
+		ret := make([]string, 0, len(paths))
+		for _, path := range paths {
+			ret = append(ret, filepath.Join(dir, path))
+		}
+		return ret
+	}
+
+	t.Run("recursive include", func(t *testing.T) {
+		require.Equal(t, abs("a/1.txt", "a/b", "a/b/2.txt", "a/b/3.skip"),
+			existingPaths([]string{filepath.Join(dir, "a") + recursiveSuffix}, nil))
+	})
+
+	t.Run("directory include lists its entries", func(t *testing.T) {
+		require.Equal(t, abs("a/1.txt", "a/b"), existingPaths([]string{filepath.Join(dir, "a")}, nil))
+	})
+
+	t.Run("file include", func(t *testing.T) {
+		require.Equal(t, abs("c/4.txt"), existingPaths([]string{filepath.Join(dir, "c", "4.txt")}, nil))
+	})
+
+	t.Run("missing includes are skipped and overlapping ones listed once", func(t *testing.T) {
+		require.Equal(t, abs("c/4.txt"), existingPaths([]string{
+			filepath.Join(dir, "missing"),
+			filepath.Join(dir, "c"),
+			filepath.Join(dir, "c") + recursiveSuffix,
+		}, nil))
+	})
+
+	t.Run("excluded paths are skipped", func(t *testing.T) {
+		exclude := []*regexp.Regexp{regexp.MustCompile(`.*\.skip`), regexp.MustCompile(`/c/`)}
+		require.Equal(t, abs("a", "a/1.txt", "a/b", "a/b/2.txt", "c"), existingPaths([]string{dir + recursiveSuffix}, exclude))
+	})
+}
+
+func TestReadExisting(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "existing.txt")
+	require.NoError(t, os.WriteFile(path, []byte("content"), 0o600))
+
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	cfg.Include = []string{dir + recursiveSuffix}
+	cfg.ReadExisting = true
+	sink := new(consumertest.LogsSink)
+	fsn, err := newNotify(cfg, sink, receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+
+	fsn.watcher = make(chan notify.EventInfo, 8)
+	fsn.done = make(chan struct{})
+	fsn.ready = make(chan struct{})
+	fsn.notify = notify.NewNotify()
+	go fsn.watch(context.Background(), fsn.watcher)
+
+	// the existing files are emitted before the events received while starting
+	fsn.watcher <- fakeEvent{path: filepath.Join(dir, "created.txt"), event: notify.Create}
+	require.Eventually(t, func() bool { return len(fsn.watcher) == 0 }, 5*time.Second, 10*time.Millisecond)
+	close(fsn.ready)
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+	records := recordsOf(sink.AllLogs())
+	requireAttr(t, records[0], "path", path)
+	requireAttr(t, records[0], "operation", EXISTING_OPERATION)
+	requireAttr(t, records[0], SIZE_ATTRIBUTE, int64(7))
+	requireAttr(t, records[0], RELATIVE_PATH_ATTRIBUTE, "existing.txt")
+	requireAttr(t, records[1], "operation", notify.Create.String())
+
+	require.NoError(t, fsn.Shutdown(context.Background()))
+}
+
+func TestReadExistingInvalidExclude(t *testing.T) {
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	cfg.Exclude = []string{"("}
+	cfg.ReadExisting = true
+	_, err := newNotify(cfg, new(consumertest.LogsSink), receivertest.NewNopSettings(Type))
+	require.ErrorContains(t, err, "cannot compile the exclude path")
+}
@@ -0,0 +1,119 @@
+package filewatchreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/receiver/receivertest"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+type fakeEvent struct {
+	path  string
+	event notify.Event
+}
+
+func (e fakeEvent) Timestamp() int64    { return time.Now().Unix() }
+func (e fakeEvent) Event() notify.Event { return e.event }
+func (e fakeEvent) Path() string        { return e.path }
+func (e fakeEvent) Sys() interface{}    { return nil }
+
+func TestStartupBuffer(t *testing.T) {
+	tel := componenttest.NewTelemetry()
+	t.Cleanup(func() { require.NoError(t, tel.Shutdown(context.Background())) })
+	settings := receivertest.NewNopSettings(Type)
+	settings.TelemetrySettings = tel.NewTelemetrySettings()
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	cfg.StartupBufferSize = 2
+	sink := new(consumertest.LogsSink)
+	fsn, err := newNotify(cfg, sink, settings)
+	require.NoError(t, err)
+
+	fsn.watcher = make(chan notify.EventInfo, 8)
+	fsn.done = make(chan struct{})
+	fsn.ready = make(chan struct{})
+	fsn.notify = notify.NewNotify()
+	go fsn.watch(context.Background(), fsn.watcher)
+
+	// events received before the watches are established are held back, up to the buffer size
+	fsn.watcher <- fakeEvent{path: "/tmp/a", event: notify.Create}
+	fsn.watcher <- fakeEvent{path: "/tmp/b", event: notify.Create}
+	fsn.watcher <- fakeEvent{path: "/tmp/c", event: notify.Create}
+	require.Never(t, func() bool { return sink.LogRecordCount() > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+
+	close(fsn.ready)
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+	paths := make([]string, 0, 2)
+	for lr := range logsIterator(sink.AllLogs()) {
+		path, _ := lr.Attributes().Get("path")
+		paths = append(paths, path.Str())
+	}
+	require.Equal(t, []string{"/tmp/a", "/tmp/b"}, paths)
+
+	got, err := tel.GetMetric("otelcol_filewatch_startup_dropped_events")
+	require.NoError(t, err)
+	sum, ok := got.Data.(metricdata.Sum[int64])
+	require.True(t, ok)
+	require.Len(t, sum.DataPoints, 1)
+	require.Equal(t, int64(1), sum.DataPoints[0].Value)
+
+	// events received once ready are emitted right away
+	fsn.watcher <- fakeEvent{path: "/tmp/d", event: notify.Create}
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 3 }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, fsn.Shutdown(context.Background()))
+}
+
+func TestStartNoInclude(t *testing.T) {
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	fsn, err := newNotify(cfg, new(consumertest.LogsSink), receivertest.NewNopSettings(Type))
+	require.NoError(t, err)
+	require.NoError(t, fsn.Start(context.Background(), componenttest.NewNopHost()))
+	select {
+	case <-fsn.ready:
+	default:
+		t.Fatal("expected the receiver to be ready once started")
+	}
+	require.NoError(t, fsn.Shutdown(context.Background()))
+}
+
+func TestReplaceWindowExpiry(t *testing.T) {
+	cfg := createDefaultConfig().(*FileWatchReceiverConfig)
+	cfg.ReplaceWindow = time.Second
+	sink := new(consumertest.LogsSink)
+	clock := clockwork.NewFakeClockAt(time.Unix(1700000000, 0))
+	fsn, err := newNotify(cfg, sink, receivertest.NewNopSettings(Type), withClock(clock))
+	require.NoError(t, err)
+	fsn.replace.stat = func(string) (uint64, bool, bool) { return 0, false, false }
+
+	fsn.watcher = make(chan notify.EventInfo, 8)
+	fsn.done = make(chan struct{})
+	fsn.ready = make(chan struct{})
+	fsn.notify = notify.NewNotify()
+	close(fsn.ready)
+	go fsn.watch(context.Background(), fsn.watcher)
+
+	// the removal is held back until the replace window expires
+	fsn.watcher <- fakeEvent{path: "/tmp/a", event: notify.Remove}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, clock.BlockUntilContext(ctx, 1))
+	require.Zero(t, sink.LogRecordCount())
+
+	clock.Advance(cfg.ReplaceWindow)
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 1 }, 5*time.Second, 10*time.Millisecond)
+	for lr := range logsIterator(sink.AllLogs()) {
+		operation, _ := lr.Attributes().Get("operation")
+		require.Equal(t, notify.Remove.String(), operation.Str())
+		require.Equal(t, pcommon.NewTimestampFromTime(clock.Now()), lr.ObservedTimestamp())
+	}
+
+	require.NoError(t, fsn.Shutdown(context.Background()))
+}
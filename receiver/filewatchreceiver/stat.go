@@ -0,0 +1,122 @@
+package filewatchreceiver
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	// SIZE_ATTRIBUTE is the log attribute holding the size of a file, in bytes.
+	SIZE_ATTRIBUTE = "file.size"
+	// MODE_ATTRIBUTE is the log attribute holding the permissions of a file, in octal, e.g. "0644".
+	MODE_ATTRIBUTE = "file.mode"
+	// OWNER_ID_ATTRIBUTE is the log attribute holding the user id of the owner of a file.
+	OWNER_ID_ATTRIBUTE = "file.owner.id"
+	// OWNER_NAME_ATTRIBUTE is the log attribute holding the user name of the owner of a file.
+	OWNER_NAME_ATTRIBUTE = "file.owner.name"
+	// GROUP_ID_ATTRIBUTE is the log attribute holding the group id of a file.
+	GROUP_ID_ATTRIBUTE = "file.group.id"
+	// GROUP_NAME_ATTRIBUTE is the log attribute holding the group name of a file.
+	GROUP_NAME_ATTRIBUTE = "file.group.name"
+	// MODIFIED_ATTRIBUTE is the log attribute holding the time the content of a file was last modified, in RFC 3339.
+	MODIFIED_ATTRIBUTE = "file.modified"
+	// CHANGED_ATTRIBUTE is the log attribute holding the time the metadata of a file was last changed, in RFC 3339.
+	CHANGED_ATTRIBUTE = "file.changed"
+	// INODE_ATTRIBUTE is the log attribute holding the inode of a file.
+	INODE_ATTRIBUTE = "file.inode"
+)
+
+// fileOwnership is the part of the metadata of a file only available on some platforms.
+type fileOwnership struct {
+	uid, gid uint32
+	// changed is the zero time when the platform does not report it.
+	changed time.Time
+	inode   uint64
+}
+
+// statEnricher adds the metadata of the files to their events. The user and group names are resolved once per id.
+// It is not safe for concurrent use.
+type statEnricher struct {
+	users       map[uint32]string
+	groups      map[uint32]string
+	lookupUser  func(uid uint32) (string, error)
+	lookupGroup func(gid uint32) (string, error)
+}
+
+func newStatEnricher() *statEnricher {
+	return &statEnricher{
+		users:  make(map[uint32]string),
+		groups: make(map[uint32]string),
+		lookupUser: func(uid uint32) (string, error) {
+			u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
+			if err != nil {
+				return "", err
+			}
+			return u.Username, nil
+		},
+		lookupGroup: func(gid uint32) (string, error) {
+			g, err := user.LookupGroupId(strconv.FormatUint(uint64(gid), 10))
+			if err != nil {
+				return "", err
+			}
+			return g.Name, nil
+		},
+	}
+}
+
+// annotate adds the metadata of path to its log records in logs. Nothing is added when path cannot be stat'ed, e.g.
+// because it was removed. Symbolic links are not followed.
+func (s *statEnricher) annotate(logs plog.Logs, path string) {
+	fi, err := os.Lstat(path)
+	if err != nil {
+		return
+	}
+	ownership, hasOwnership := ownershipOf(fi)
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		resourceLogs := logs.ResourceLogs().At(i)
+		for j := 0; j < resourceLogs.ScopeLogs().Len(); j++ {
+			records := resourceLogs.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+				if p, found := record.Attributes().Get("path"); !found || p.Str() != path {
+					continue
+				}
+				attrs := record.Attributes()
+				attrs.PutInt(SIZE_ATTRIBUTE, fi.Size())
+				attrs.PutStr(MODE_ATTRIBUTE, fmt.Sprintf("%04o", fi.Mode().Perm()))
+				attrs.PutStr(MODIFIED_ATTRIBUTE, fi.ModTime().UTC().Format(time.RFC3339Nano))
+				if !hasOwnership {
+					continue
+				}
+				attrs.PutStr(OWNER_ID_ATTRIBUTE, strconv.FormatUint(uint64(ownership.uid), 10))
+				if name, ok := s.name(s.users, s.lookupUser, ownership.uid); ok {
+					attrs.PutStr(OWNER_NAME_ATTRIBUTE, name)
+				}
+				attrs.PutStr(GROUP_ID_ATTRIBUTE, strconv.FormatUint(uint64(ownership.gid), 10))
+				if name, ok := s.name(s.groups, s.lookupGroup, ownership.gid); ok {
+					attrs.PutStr(GROUP_NAME_ATTRIBUTE, name)
+				}
+				if !ownership.changed.IsZero() {
+					attrs.PutStr(CHANGED_ATTRIBUTE, ownership.changed.UTC().Format(time.RFC3339Nano))
+				}
+				attrs.PutStr(INODE_ATTRIBUTE, strconv.FormatUint(ownership.inode, 10))
+			}
+		}
+	}
+}
+
+// name returns the name of id, resolved with lookup and remembered in names. The ids without a name, e.g. of users
+// removed since, are remembered too so that they are only looked up once.
+func (s *statEnricher) name(names map[uint32]string, lookup func(uint32) (string, error), id uint32) (string, bool) {
+	name, ok := names[id]
+	if !ok {
+		name, _ = lookup(id)
+		names[id] = name
+	}
+	return name, name != ""
+}
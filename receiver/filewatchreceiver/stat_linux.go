@@ -0,0 +1,12 @@
+//go:build linux
+
+package filewatchreceiver
+
+import (
+	"syscall"
+	"time"
+)
+
+func changedOf(st *syscall.Stat_t) time.Time {
+	return time.Unix(st.Ctim.Unix())
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package filewatchreceiver
+
+import "os"
+
+func ownershipOf(_ os.FileInfo) (fileOwnership, bool) {
+	return fileOwnership{}, false
+}
@@ -0,0 +1,68 @@
+package filewatchreceiver
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatEnricher(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "file.txt")
+	require.NoError(t, os.WriteFile(path, []byte("content"), 0o640))
+	require.NoError(t, os.Chmod(path, 0o640))
+	modified := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, modified, modified))
+	ts := time.Unix(1700000000, 0)
+
+	s := newStatEnricher()
+	lookups := 0
+	s.lookupUser = func(uid uint32) (string, error) {
+		lookups++
+		return "user" + strconv.FormatUint(uint64(uid), 10), nil
+	}
+	s.lookupGroup = func(uint32) (string, error) {
+		return "", errors.New("unknown group")
+	}
+
+	t.Run("adds the metadata of the file", func(t *testing.T) {
+		logs := createLogs(ts, path, notify.Write.String())
+		s.annotate(logs, path)
+		record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		requireAttr(t, record, SIZE_ATTRIBUTE, int64(7))
+		requireAttr(t, record, MODE_ATTRIBUTE, "0640")
+		requireAttr(t, record, MODIFIED_ATTRIBUTE, "2024-01-02T03:04:05Z")
+		if runtime.GOOS == "windows" {
+			return
+		}
+		uid := strconv.Itoa(os.Getuid())
+		requireAttr(t, record, OWNER_ID_ATTRIBUTE, uid)
+		requireAttr(t, record, OWNER_NAME_ATTRIBUTE, "user"+uid)
+		requireAttr(t, record, GROUP_ID_ATTRIBUTE, strconv.Itoa(os.Getgid()))
+		_, found := record.Attributes().Get(GROUP_NAME_ATTRIBUTE)
+		require.False(t, found, "the group name cannot be resolved")
+		_, found = record.Attributes().Get(INODE_ATTRIBUTE)
+		require.True(t, found)
+	})
+
+	t.Run("names are resolved once", func(t *testing.T) {
+		lookups = 0
+		s.annotate(createLogs(ts, path, notify.Write.String()), path)
+		require.Zero(t, lookups)
+	})
+
+	t.Run("removed files are not annotated", func(t *testing.T) {
+		missing := filepath.Join(dir, "missing")
+		logs := createLogs(ts, missing, notify.Remove.String())
+		s.annotate(logs, missing)
+		record := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		require.Equal(t, 2, record.Attributes().Len(), "only the path and operation attributes")
+	})
+}
@@ -0,0 +1,21 @@
+//go:build unix
+
+package filewatchreceiver
+
+import (
+	"os"
+	"syscall"
+)
+
+func ownershipOf(fi os.FileInfo) (fileOwnership, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return fileOwnership{}, false
+	}
+	return fileOwnership{
+		uid:     st.Uid,
+		gid:     st.Gid,
+		changed: changedOf(st),
+		inode:   uint64(st.Ino),
+	}, true
+}
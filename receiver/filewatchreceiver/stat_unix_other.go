@@ -0,0 +1,13 @@
+//go:build unix && !linux && !darwin
+
+package filewatchreceiver
+
+import (
+	"syscall"
+	"time"
+)
+
+// changedOf returns the zero time, the change time is not reported on these platforms.
+func changedOf(_ *syscall.Stat_t) time.Time {
+	return time.Time{}
+}
@@ -0,0 +1,102 @@
+package filewatchreceiver
+
+import (
+	"errors"
+	"io"
+	"os"
+	"unicode/utf8"
+
+	"github.com/olandr/notify"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// OFFSET_ATTRIBUTE is the log attribute holding the offset, in the file, of the content in the log body.
+const OFFSET_ATTRIBUTE = "file.offset"
+
+// tailer reads the content appended to the files when they are written, tracking the offset read up to in each
+// file. It is not safe for concurrent use.
+type tailer struct {
+	// offsets maps the path of a file to the offset its content was read up to.
+	offsets map[string]int64
+	// maxBytes bounds the content read on a single event, unbounded when 0.
+	maxBytes int64
+	// fullContentMaxSize is the size up to which the whole content of a file is read on each write, disabled when 0.
+	fullContentMaxSize int64
+}
+
+func newTailer(cfg TailConfig) *tailer {
+	return &tailer{
+		offsets:            make(map[string]int64),
+		maxBytes:           cfg.MaxBytes,
+		fullContentMaxSize: cfg.FullContentMaxSize,
+	}
+}
+
+// read returns the content of path to emit after event, together with its offset in the file. The content appended
+// since the previous read is returned on writes, from the beginning of the files not read yet or truncated since.
+// The creation or the removal of a path forgets its offset. ok is false when there is no content to emit, or the file
+// could not be read, e.g. because it was removed since.
+func (t *tailer) read(path string, event notify.Event) (content []byte, offset int64, ok bool) {
+	if event&writeEvents == 0 {
+		if event&(removalEvents|creationEvents) != 0 {
+			delete(t.offsets, path)
+		}
+		return nil, 0, false
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, 0, false
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil || !fi.Mode().IsRegular() {
+		return nil, 0, false
+	}
+	size := fi.Size()
+	offset = t.offsets[path]
+	if size < offset || size <= t.fullContentMaxSize {
+		offset = 0
+	}
+	n := size - offset
+	if t.maxBytes > 0 && n > t.maxBytes {
+		n = t.maxBytes
+	}
+	if n == 0 {
+		return nil, 0, false
+	}
+	content = make([]byte, n)
+	read, err := io.ReadFull(io.NewSectionReader(f, offset, n), content)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, 0, false
+	}
+	t.offsets[path] = offset + int64(read)
+	return content[:read], offset, read > 0
+}
+
+// annotate puts the content of path read after event in the body of its log records in the last logs, the logs of
+// the event itself.
+func (t *tailer) annotate(logs []plog.Logs, path string, event notify.Event) {
+	content, offset, ok := t.read(path, event)
+	if !ok || len(logs) == 0 {
+		return
+	}
+	last := logs[len(logs)-1]
+	for i := 0; i < last.ResourceLogs().Len(); i++ {
+		resourceLogs := last.ResourceLogs().At(i)
+		for j := 0; j < resourceLogs.ScopeLogs().Len(); j++ {
+			records := resourceLogs.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				record := records.At(k)
+				if p, found := record.Attributes().Get("path"); !found || p.Str() != path {
+					continue
+				}
+				if utf8.Valid(content) {
+					record.Body().SetStr(string(content))
+				} else {
+					record.Body().SetEmptyBytes().FromRaw(content)
+				}
+				record.Attributes().PutInt(OFFSET_ATTRIBUTE, offset)
+			}
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package filewatchreceiver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/olandr/notify"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func appendFile(t *testing.T, path, content string) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	require.NoError(t, err)
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+}
+
+func requireRead(t *testing.T, tl *tailer, path, expected string, expectedOffset int64) {
+	content, offset, ok := tl.read(path, notify.Write)
+	require.True(t, ok)
+	require.Equal(t, expected, string(content))
+	require.Equal(t, expectedOffset, offset)
+}
+
+func TestTailer(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("appended content is read once", func(t *testing.T) {
+		path := filepath.Join(dir, "append.log")
+		tl := newTailer(TailConfig{})
+		appendFile(t, path, "first\n")
+		requireRead(t, tl, path, "first\n", 0)
+		appendFile(t, path, "second\n")
+		requireRead(t, tl, path, "second\n", 6)
+		_, _, ok := tl.read(path, notify.Write)
+		require.False(t, ok, "nothing was appended since")
+	})
+
+	t.Run("truncated file is read from the beginning", func(t *testing.T) {
+		path := filepath.Join(dir, "truncate.log")
+		tl := newTailer(TailConfig{})
+		appendFile(t, path, "some long content\n")
+		requireRead(t, tl, path, "some long content\n", 0)
+		require.NoError(t, os.WriteFile(path, []byte("short\n"), 0o600))
+		requireRead(t, tl, path, "short\n", 0)
+	})
+
+	t.Run("removal forgets the offset", func(t *testing.T) {
+		path := filepath.Join(dir, "removed.log")
+		tl := newTailer(TailConfig{})
+		appendFile(t, path, "before\n")
+		requireRead(t, tl, path, "before\n", 0)
+		require.NoError(t, os.Remove(path))
+		_, _, ok := tl.read(path, notify.Remove)
+		require.False(t, ok)
+		appendFile(t, path, "after removal\n")
+		requireRead(t, tl, path, "after removal\n", 0)
+	})
+
+	t.Run("content is bounded by max bytes", func(t *testing.T) {
+		path := filepath.Join(dir, "bounded.log")
+		tl := newTailer(TailConfig{MaxBytes: 4})
+		appendFile(t, path, "abcdefgh")
+		requireRead(t, tl, path, "abcd", 0)
+		requireRead(t, tl, path, "efgh", 4)
+	})
+
+	t.Run("small files are read whole", func(t *testing.T) {
+		path := filepath.Join(dir, "small.conf")
+		tl := newTailer(TailConfig{FullContentMaxSize: 16})
+		appendFile(t, path, "a=1\n")
+		requireRead(t, tl, path, "a=1\n", 0)
+		appendFile(t, path, "b=2\n")
+		requireRead(t, tl, path, "a=1\nb=2\n", 0)
+		appendFile(t, path, "grown beyond the size\n")
+		requireRead(t, tl, path, "grown beyond the size\n", 8)
+	})
+
+	t.Run("directories are not read", func(t *testing.T) {
+		tl := newTailer(TailConfig{})
+		_, _, ok := tl.read(dir, notify.Write)
+		require.False(t, ok)
+	})
+}
+
+func TestTailerAnnotate(t *testing.T) {
+	dir := t.TempDir()
+	ts := time.Unix(1700000000, 0)
+	tl := newTailer(TailConfig{})
+
+	text := filepath.Join(dir, "text.log")
+	appendFile(t, text, "hello\n")
+	logs := []plog.Logs{createLogs(ts, text, notify.Write.String())}
+	tl.annotate(logs, text, notify.Write)
+	record := logs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, "hello\n", record.Body().Str())
+	requireAttr(t, record, OFFSET_ATTRIBUTE, int64(0))
+
+	binary := filepath.Join(dir, "binary.bin")
+	appendFile(t, binary, "\xff\xfe")
+	logs = []plog.Logs{createLogs(ts, binary, notify.Write.String())}
+	tl.annotate(logs, binary, notify.Write)
+	record = logs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	require.Equal(t, []byte("\xff\xfe"), record.Body().Bytes().AsRaw())
+
+	// the offsets follow the events without logs, e.g. the removals being held back
+	require.NoError(t, os.Remove(text))
+	tl.annotate(nil, text, notify.Remove)
+	require.NotContains(t, tl.offsets, text)
+}
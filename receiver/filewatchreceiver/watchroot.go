@@ -0,0 +1,79 @@
+package filewatchreceiver
+
+import (
+	"path/filepath"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	// WATCH_ROOT_ATTRIBUTE is the resource attribute holding the include root an event was observed under.
+	WATCH_ROOT_ATTRIBUTE = "file.watch.root"
+	// RELATIVE_PATH_ATTRIBUTE is the log attribute holding the path of an event relative to its watch root.
+	RELATIVE_PATH_ATTRIBUTE = "file.path.relative"
+
+	// recursiveSuffix marks an include path that is watched recursively.
+	recursiveSuffix = "/..."
+)
+
+// watchRoot is an include path, as configured and as the absolute path events are reported under.
+type watchRoot struct {
+	configured string
+	absolute   string
+}
+
+// watchRoots resolves the include root of the paths events are reported for.
+type watchRoots []watchRoot
+
+func newWatchRoots(include []string) watchRoots {
+	roots := make(watchRoots, 0, len(include))
+	for _, path := range include {
+		configured := strings.TrimSuffix(path, recursiveSuffix)
+		absolute, err := filepath.Abs(configured)
+		if err != nil {
+			continue
+		}
+		roots = append(roots, watchRoot{configured: configured, absolute: absolute})
+	}
+	return roots
+}
+
+// resolve returns the configured include root of path, together with the path relative to it. When path is
+// under several roots the most specific one is picked.
+func (w watchRoots) resolve(path string) (root, relative string, ok bool) {
+	var best watchRoot
+	for _, r := range w {
+		rel, err := filepath.Rel(r.absolute, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		if !ok || len(r.absolute) > len(best.absolute) {
+			best, relative, ok = r, rel, true
+		}
+	}
+	return best.configured, relative, ok
+}
+
+// annotate adds the watch root and relative path attributes to the log records of logs, based on their path
+// attribute.
+func (w watchRoots) annotate(logs plog.Logs) {
+	for i := 0; i < logs.ResourceLogs().Len(); i++ {
+		resourceLogs := logs.ResourceLogs().At(i)
+		for j := 0; j < resourceLogs.ScopeLogs().Len(); j++ {
+			records := resourceLogs.ScopeLogs().At(j).LogRecords()
+			for k := 0; k < records.Len(); k++ {
+				path, found := records.At(k).Attributes().Get("path")
+				if !found {
+					continue
+				}
+				root, relative, ok := w.resolve(path.Str())
+				if !ok {
+					continue
+				}
+				resourceLogs.Resource().Attributes().PutStr(WATCH_ROOT_ATTRIBUTE, root)
+				records.At(k).Attributes().PutStr(RELATIVE_PATH_ATTRIBUTE, relative)
+			}
+		}
+	}
+}
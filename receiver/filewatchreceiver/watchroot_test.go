@@ -0,0 +1,73 @@
+package filewatchreceiver
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestWatchRoots(t *testing.T) {
+	wd, err := filepath.Abs(".")
+	require.NoError(t, err)
+
+	t.Run("resolves the most specific root", func(t *testing.T) {
+		roots := newWatchRoots([]string{"/data/...", "/data/logs/...", "/other"})
+
+		root, relative, ok := roots.resolve("/data/logs/app/a.log")
+		require.True(t, ok)
+		require.Equal(t, "/data/logs", root)
+		require.Equal(t, filepath.Join("app", "a.log"), relative)
+
+		root, relative, ok = roots.resolve("/data/b.log")
+		require.True(t, ok)
+		require.Equal(t, "/data", root)
+		require.Equal(t, "b.log", relative)
+
+		root, relative, ok = roots.resolve("/other")
+		require.True(t, ok)
+		require.Equal(t, "/other", root)
+		require.Equal(t, ".", relative)
+	})
+
+	t.Run("does not resolve paths outside of the roots", func(t *testing.T) {
+		roots := newWatchRoots([]string{"/data/logs/..."})
+
+		_, _, ok := roots.resolve("/data/logs2/a.log")
+		require.False(t, ok)
+		_, _, ok = roots.resolve("/data")
+		require.False(t, ok)
+	})
+
+	t.Run("resolves relative include paths", func(t *testing.T) {
+		roots := newWatchRoots([]string{TEST_INCLUDE_RECURSIVE_PATH})
+
+		root, relative, ok := roots.resolve(filepath.Join(wd, TEST_INNER_PATH, "a.txt"))
+		require.True(t, ok)
+		require.Equal(t, TEST_INCLUDE_PATH, root)
+		require.Equal(t, filepath.Join("inner", "a.txt"), relative)
+	})
+
+	t.Run("annotates logs", func(t *testing.T) {
+		roots := newWatchRoots([]string{"/data/..."})
+		logs := createLogs(time.Now(), "/data/logs/a.log", "create")
+		roots.annotate(logs)
+
+		root, ok := logs.ResourceLogs().At(0).Resource().Attributes().Get(WATCH_ROOT_ATTRIBUTE)
+		require.True(t, ok)
+		require.Equal(t, "/data", root.Str())
+		requireAttr(t, recordsOf([]plog.Logs{logs})[0], RELATIVE_PATH_ATTRIBUTE, filepath.Join("logs", "a.log"))
+	})
+
+	t.Run("leaves logs outside of the roots untouched", func(t *testing.T) {
+		roots := newWatchRoots([]string{"/data/..."})
+		logs := createLogs(time.Now(), "/other/a.log", "create")
+		roots.annotate(logs)
+
+		require.Equal(t, 0, logs.ResourceLogs().At(0).Resource().Attributes().Len())
+		_, ok := recordsOf([]plog.Logs{logs})[0].Attributes().Get(RELATIVE_PATH_ATTRIBUTE)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,68 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package solacereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+
+import (
+	"crypto/rand"
+	"slices"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Broker spans modes
+const (
+	// brokerSpansModeFull emits the broker spans as they are received
+	brokerSpansModeFull = "full"
+	// brokerSpansModeLinkOnly emits the delete spans as minimal spans linked to the application spans
+	brokerSpansModeLinkOnly = "link_only"
+)
+
+// transactionEventAttrKey holds the name of the transaction event aggregated into the attributes of a link only span
+const transactionEventAttrKey = "messaging.solace.transaction_event.name"
+
+// linkOnlyAttributes are the attributes kept on the spans emitted in the link only mode
+var linkOnlyAttributes = []string{
+	systemAttrKey,
+	operationNameAttrKey,
+	operationTypeAttrKey,
+	destinationNameAttrKey,
+	operationReasonAttrKey,
+}
+
+// toLinkOnlySpan turns a broker span into a minimal span, keeping only the attributes identifying its operation. A span
+// that is the child of an application span is moved to a trace of its own and linked to that application span instead,
+// so that it no longer adds to the size of the application trace. Its transaction event is aggregated into attributes.
+func toLinkOnlySpan(span ptrace.Span) {
+	attrs := span.Attributes()
+	attrs.RemoveIf(func(key string, _ pcommon.Value) bool {
+		return !slices.Contains(linkOnlyAttributes, key)
+	})
+
+	events := span.Events()
+	for i := 0; i < events.Len(); i++ {
+		event := events.At(i)
+		attrs.PutStr(transactionEventAttrKey, event.Name())
+		event.Attributes().Range(func(key string, value pcommon.Value) bool {
+			value.CopyTo(attrs.PutEmpty(key))
+			return true
+		})
+	}
+	events.RemoveIf(func(ptrace.SpanEvent) bool { return true })
+
+	if parentSpanID := span.ParentSpanID(); !parentSpanID.IsEmpty() {
+		link := span.Links().AppendEmpty()
+		link.SetTraceID(span.TraceID())
+		link.SetSpanID(parentSpanID)
+		span.SetParentSpanID(pcommon.NewSpanIDEmpty())
+		span.SetTraceID(newTraceID())
+	}
+}
+
+// newTraceID returns a random trace ID
+func newTraceID() pcommon.TraceID {
+	var traceID [16]byte
+	_, _ = rand.Read(traceID[:])
+	return traceID
+}
@@ -0,0 +1,29 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package solacereceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestToLinkOnlySpanWithoutParent(t *testing.T) {
+	traceID := pcommon.TraceID([16]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16})
+	span := ptrace.NewSpan()
+	span.SetTraceID(traceID)
+	span.SetSpanID([8]byte{1, 2, 3, 4, 5, 6, 7, 8})
+	span.Attributes().PutStr(systemAttrKey, systemAttrValue)
+	span.Attributes().PutStr(destinationTypeAttrKey, queueKind)
+	span.Attributes().PutInt(partitionNumberKey, 3)
+
+	toLinkOnlySpan(span)
+
+	// a span without parent is already a trace of its own
+	assert.Equal(t, traceID, span.TraceID())
+	assert.Equal(t, 0, span.Links().Len())
+	assert.Equal(t, map[string]any{systemAttrKey: systemAttrValue}, span.Attributes().AsRaw())
+}
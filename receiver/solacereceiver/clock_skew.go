@@ -0,0 +1,83 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package solacereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/metric"
+	"go.uber.org/zap"
+)
+
+// clockSkewAttrKey holds the number of milliseconds the end of a skewed span was ahead of the receive time
+const clockSkewAttrKey = "messaging.solace.clock_skew_ms"
+
+// handleClockSkew compares the end timestamp of every span with the time the message was received at. A span that
+// ends more than the configured threshold after the receive time was timestamped by a broker whose clock runs ahead,
+// it is annotated with the skew and, if enabled, has its timestamps shifted back by the skew.
+func (s *solaceTracesReceiver) handleClockSkew(ctx context.Context, traces ptrace.Traces, receiveTime time.Time) {
+	threshold := s.config.ClockSkew.Threshold
+	if threshold <= 0 || (traces == ptrace.Traces{}) {
+		return
+	}
+	received := pcommon.NewTimestampFromTime(receiveTime)
+	var skewed int64
+	var maxSkew time.Duration
+	rss := traces.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		sss := rss.At(i).ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				if span.EndTimestamp() <= received {
+					continue
+				}
+				skew := time.Duration(span.EndTimestamp() - received)
+				if skew <= threshold {
+					continue
+				}
+				skewed++
+				maxSkew = max(maxSkew, skew)
+				span.Attributes().PutInt(clockSkewAttrKey, skew.Milliseconds())
+				if s.config.ClockSkew.Correct {
+					correctClockSkew(span, skew)
+				}
+			}
+		}
+	}
+	if skewed == 0 {
+		return
+	}
+	s.telemetryBuilder.SolacereceiverClockSkewedSpans.Add(ctx, skewed, metric.WithAttributeSet(s.metricAttrs))
+	s.settings.Logger.Debug("Received spans timestamped ahead of the receive time, the broker clock may be skewed",
+		zap.Int64("skewed_spans", skewed),
+		zap.Duration("max_skew", maxSkew),
+		zap.Bool("corrected", s.config.ClockSkew.Correct))
+}
+
+// correctClockSkew shifts all the broker timestamps of the span back by skew
+func correctClockSkew(span ptrace.Span, skew time.Duration) {
+	span.SetStartTimestamp(shiftTimestamp(span.StartTimestamp(), skew))
+	span.SetEndTimestamp(shiftTimestamp(span.EndTimestamp(), skew))
+	events := span.Events()
+	for i := 0; i < events.Len(); i++ {
+		event := events.At(i)
+		event.SetTimestamp(shiftTimestamp(event.Timestamp(), skew))
+	}
+	if v, ok := span.Attributes().Get(receiveTimeAttrKey); ok && v.Type() == pcommon.ValueTypeInt && v.Int() > 0 {
+		v.SetInt(int64(shiftTimestamp(pcommon.Timestamp(v.Int()), skew)))
+	}
+}
+
+// shiftTimestamp moves ts back by d, without going before the epoch
+func shiftTimestamp(ts pcommon.Timestamp, d time.Duration) pcommon.Timestamp {
+	if uint64(ts) <= uint64(d) {
+		return 0
+	}
+	return ts - pcommon.Timestamp(d)
+}
@@ -0,0 +1,119 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package solacereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver/internal/metadatatest"
+)
+
+func TestHandleClockSkew(t *testing.T) {
+	receiveTime := time.Unix(1700000000, 0)
+	newTraces := func() ptrace.Traces {
+		traces := ptrace.NewTraces()
+		spans := traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+		// ends 5s ahead of the receive time
+		skewed := spans.AppendEmpty()
+		skewed.SetStartTimestamp(pcommon.NewTimestampFromTime(receiveTime.Add(4 * time.Second)))
+		skewed.SetEndTimestamp(pcommon.NewTimestampFromTime(receiveTime.Add(5 * time.Second)))
+		skewed.Events().AppendEmpty().SetTimestamp(pcommon.NewTimestampFromTime(receiveTime.Add(4500 * time.Millisecond)))
+		skewed.Attributes().PutInt(receiveTimeAttrKey, receiveTime.Add(4*time.Second).UnixNano())
+		// ends 100ms ahead of the receive time, within the threshold
+		tolerated := spans.AppendEmpty()
+		tolerated.SetStartTimestamp(pcommon.NewTimestampFromTime(receiveTime))
+		tolerated.SetEndTimestamp(pcommon.NewTimestampFromTime(receiveTime.Add(100 * time.Millisecond)))
+		// ends before the receive time
+		past := spans.AppendEmpty()
+		past.SetStartTimestamp(pcommon.NewTimestampFromTime(receiveTime.Add(-2 * time.Second)))
+		past.SetEndTimestamp(pcommon.NewTimestampFromTime(receiveTime.Add(-time.Second)))
+		return traces
+	}
+
+	tests := []struct {
+		name      string
+		clockSkew ClockSkew
+		// expected skew attribute of the first span, 0 if absent
+		expectedSkewMs int64
+		expectedStart  time.Time
+		expectedEnd    time.Time
+		expectedEvent  time.Time
+		expectedBroker time.Time
+	}{
+		{
+			name:           "disabled",
+			expectedStart:  receiveTime.Add(4 * time.Second),
+			expectedEnd:    receiveTime.Add(5 * time.Second),
+			expectedEvent:  receiveTime.Add(4500 * time.Millisecond),
+			expectedBroker: receiveTime.Add(4 * time.Second),
+		},
+		{
+			name:           "detected",
+			clockSkew:      ClockSkew{Threshold: time.Second},
+			expectedSkewMs: 5000,
+			expectedStart:  receiveTime.Add(4 * time.Second),
+			expectedEnd:    receiveTime.Add(5 * time.Second),
+			expectedEvent:  receiveTime.Add(4500 * time.Millisecond),
+			expectedBroker: receiveTime.Add(4 * time.Second),
+		},
+		{
+			name:           "corrected",
+			clockSkew:      ClockSkew{Threshold: time.Second, Correct: true},
+			expectedSkewMs: 5000,
+			expectedStart:  receiveTime.Add(-time.Second),
+			expectedEnd:    receiveTime,
+			expectedEvent:  receiveTime.Add(-500 * time.Millisecond),
+			expectedBroker: receiveTime.Add(-time.Second),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, _, _, tel := newReceiver(t)
+			receiver.config.ClockSkew = tt.clockSkew
+			traces := newTraces()
+
+			receiver.handleClockSkew(context.Background(), traces, receiveTime)
+
+			spans := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+			skewed := spans.At(0)
+			skew, ok := skewed.Attributes().Get(clockSkewAttrKey)
+			if tt.expectedSkewMs == 0 {
+				assert.False(t, ok)
+				_, err := tel.GetMetric("otelcol_solacereceiver_clock_skewed_spans")
+				assert.Error(t, err)
+			} else {
+				require.True(t, ok)
+				assert.Equal(t, tt.expectedSkewMs, skew.Int())
+				metadatatest.AssertEqualSolacereceiverClockSkewedSpans(t, tel, []metricdata.DataPoint[int64]{
+					{
+						Value: 1,
+					},
+				}, metricdatatest.IgnoreTimestamp())
+			}
+			assert.Equal(t, tt.expectedStart.UnixNano(), skewed.StartTimestamp().AsTime().UnixNano())
+			assert.Equal(t, tt.expectedEnd.UnixNano(), skewed.EndTimestamp().AsTime().UnixNano())
+			assert.Equal(t, tt.expectedEvent.UnixNano(), skewed.Events().At(0).Timestamp().AsTime().UnixNano())
+			broker, ok := skewed.Attributes().Get(receiveTimeAttrKey)
+			require.True(t, ok)
+			assert.Equal(t, tt.expectedBroker.UnixNano(), broker.Int())
+
+			// spans within the threshold or in the past are left untouched
+			for i := 1; i < spans.Len(); i++ {
+				_, ok = spans.At(i).Attributes().Get(clockSkewAttrKey)
+				assert.False(t, ok)
+			}
+			assert.Equal(t, receiveTime.Add(100*time.Millisecond).UnixNano(), spans.At(1).EndTimestamp().AsTime().UnixNano())
+		})
+	}
+}
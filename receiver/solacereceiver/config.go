@@ -5,11 +5,14 @@ package solacereceiver // import "github.com/open-telemetry/opentelemetry-collec
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"go.opentelemetry.io/collector/config/configopaque"
 	"go.opentelemetry.io/collector/config/configtls"
+
+	egress_v1 "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver/internal/model/egress/v1"
 )
 
 const (
@@ -25,6 +28,11 @@ var (
 	errMissingXauth2Params      = errors.New("missing xauth2 text auth params: Username, Bearer")
 	errMissingFlowControl       = errors.New("missing flow control configuration: DelayedRetry must be selected")
 	errInvalidDelayedRetryDelay = errors.New("delayed_retry.delay must > 0")
+	errInvalidDeadLetterMaxSize = errors.New("dead_letter.max_size must > 0")
+	errInvalidHeartbeatInterval = errors.New("heartbeat.interval must >= 0")
+	errInvalidClockSkew         = errors.New("clock_skew.threshold must >= 0")
+	errInvalidSendOutcome       = errors.New("span_status.error_send_outcomes must only hold send outcomes")
+	errInvalidBrokerSpansMode   = errors.New("broker_spans.mode must be either full or link_only")
 )
 
 // Config defines configuration for Solace receiver.
@@ -43,6 +51,21 @@ type Config struct {
 	Auth Authentication `mapstructure:"auth"`
 
 	Flow FlowControl `mapstructure:"flow_control"`
+
+	// DeadLetter configures the local spooling of span messages that could not be unmarshalled
+	DeadLetter DeadLetter `mapstructure:"dead_letter"`
+
+	// Heartbeat configures the periodic reporting of the number of received messages
+	Heartbeat Heartbeat `mapstructure:"heartbeat"`
+
+	// ClockSkew configures the detection and correction of spans timestamped ahead of the receive time
+	ClockSkew ClockSkew `mapstructure:"clock_skew"`
+
+	// SpanStatus configures the outcomes setting the status of the spans to error
+	SpanStatus SpanStatus `mapstructure:"span_status"`
+
+	// BrokerSpans configures how the spans generated by the broker are emitted
+	BrokerSpans BrokerSpans `mapstructure:"broker_spans"`
 }
 
 // Validate checks the receiver configuration is valid
@@ -71,6 +94,25 @@ func (cfg *Config) Validate() error {
 	} else if cfg.Flow.DelayedRetry.Delay <= 0 {
 		return errInvalidDelayedRetryDelay
 	}
+	if cfg.DeadLetter.Directory != "" && cfg.DeadLetter.MaxSize <= 0 {
+		return errInvalidDeadLetterMaxSize
+	}
+	if cfg.Heartbeat.Interval < 0 {
+		return errInvalidHeartbeatInterval
+	}
+	if cfg.ClockSkew.Threshold < 0 {
+		return errInvalidClockSkew
+	}
+	for _, outcome := range cfg.SpanStatus.ErrorSendOutcomes {
+		if _, ok := parseSendOutcome(outcome); !ok {
+			return fmt.Errorf("%w: %q", errInvalidSendOutcome, outcome)
+		}
+	}
+	switch cfg.BrokerSpans.Mode {
+	case "", brokerSpansModeFull, brokerSpansModeLinkOnly:
+	default:
+		return errInvalidBrokerSpansMode
+	}
 	return nil
 }
 
@@ -120,3 +162,70 @@ type FlowControlDelayedRetry struct {
 	// prevent unkeyed literal initialization
 	_ struct{}
 }
+
+// DeadLetter defines where span messages that are acked while their content is dropped because it could not be
+// unmarshalled are written to, so that they can be replayed later, e.g. after a collector upgrade, instead of being lost.
+type DeadLetter struct {
+	// Directory is the local directory the raw messages are written to. Spooling is disabled when empty.
+	Directory string `mapstructure:"directory"`
+	// MaxSize is the maximum total size in bytes of the spooled messages, messages are dropped once it is reached
+	MaxSize int64 `mapstructure:"max_size"`
+
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// Heartbeat defines the periodic reporting of the number of span messages received since the previous heartbeat,
+// allowing a connected receiver that silently stopped receiving telemetry to be detected.
+type Heartbeat struct {
+	// Interval is the time between two heartbeats. Heartbeats are disabled when 0.
+	Interval time.Duration `mapstructure:"interval"`
+
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// ClockSkew defines the handling of spans whose timestamps are ahead of the time they are received at, which happens
+// when the clock of the broker runs ahead of the clock of the collector. Such spans appear to be in the future and
+// break the latency computations downstream.
+type ClockSkew struct {
+	// Threshold is the duration by which the end of a span may be ahead of the receive time before it is considered
+	// skewed. The detection is disabled when 0.
+	Threshold time.Duration `mapstructure:"threshold"`
+	// Correct shifts the timestamps of the skewed spans back by the skew, so that they end at the receive time
+	Correct bool `mapstructure:"correct"`
+
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// SpanStatus defines which outcomes, besides the error descriptions reported by the broker, set the status of the
+// spans to error, so that failed deliveries are visible as errored spans.
+type SpanStatus struct {
+	// ErrorSendOutcomes lists the outcomes of the egress send spans setting their status to error, e.g. REJECTED,
+	// DELIVERY_FAILED or FLOW_UNBOUND
+	ErrorSendOutcomes []string `mapstructure:"error_send_outcomes"`
+
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// BrokerSpans defines how the spans generated by the broker are emitted, allowing the users finding them too noisy to
+// reduce the size of the application traces while preserving the key broker insight.
+type BrokerSpans struct {
+	// Mode is either full, emitting the broker spans as they are received, or link_only, emitting the send spans as they
+	// are received and the delete spans as minimal spans of their own traces, linked to the application spans. The broker
+	// reports no transaction spans of their own: the transaction events of the delete spans are aggregated into their
+	// attributes, while the receive spans and the transaction events of the send spans are emitted as they are received.
+	Mode string `mapstructure:"mode"`
+
+	// prevent unkeyed literal initialization
+	_ struct{}
+}
+
+// parseSendOutcome parses the name of a send outcome, e.g. DELIVERY_FAILED or "delivery failed"
+func parseSendOutcome(outcome string) (egress_v1.SpanData_SendSpan_Outcome, bool) {
+	name := strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(outcome), " ", "_"))
+	value, ok := egress_v1.SpanData_SendSpan_Outcome_value[name]
+	return egress_v1.SpanData_SendSpan_Outcome(value), ok
+}
@@ -50,6 +50,23 @@ func TestLoadConfig(t *testing.T) {
 						Delay: 1 * time.Second,
 					},
 				},
+				DeadLetter: DeadLetter{
+					Directory: "/var/lib/otelcol/solace",
+					MaxSize:   1048576,
+				},
+				Heartbeat: Heartbeat{
+					Interval: time.Minute,
+				},
+				ClockSkew: ClockSkew{
+					Threshold: 2 * time.Second,
+					Correct:   true,
+				},
+				SpanStatus: SpanStatus{
+					ErrorSendOutcomes: []string{"REJECTED", "DELIVERY_FAILED", "FLOW_UNBOUND"},
+				},
+				BrokerSpans: BrokerSpans{
+					Mode: brokerSpansModeLinkOnly,
+				},
 			},
 		},
 		{
@@ -60,6 +77,26 @@ func TestLoadConfig(t *testing.T) {
 			id:          component.NewIDWithName(metadata.Type, "noqueue"),
 			expectedErr: errMissingQueueName,
 		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "baddeadletter"),
+			expectedErr: errInvalidDeadLetterMaxSize,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "badheartbeat"),
+			expectedErr: errInvalidHeartbeatInterval,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "badclockskew"),
+			expectedErr: errInvalidClockSkew,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "badsendoutcome"),
+			expectedErr: errInvalidSendOutcome,
+		},
+		{
+			id:          component.NewIDWithName(metadata.Type, "badbrokerspans"),
+			expectedErr: errInvalidBrokerSpansMode,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,85 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package solacereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// deadLetterFileExtension is the extension of the spooled messages, each file holds a single
+// AMQP message encoded with amqp.Message.MarshalBinary including its topic and properties.
+const deadLetterFileExtension = ".amqp"
+
+var errDeadLetterSpoolFull = errors.New("dead letter spool is full")
+
+// deadLetterSpool writes the raw messages that could not be unmarshalled to a local directory.
+type deadLetterSpool struct {
+	directory string
+	maxSize   int64
+
+	mu   sync.Mutex
+	size int64
+	seq  uint64
+}
+
+// newDeadLetterSpool creates the spool directory if needed and accounts for the messages already spooled to it.
+func newDeadLetterSpool(cfg DeadLetter) (*deadLetterSpool, error) {
+	if err := os.MkdirAll(cfg.Directory, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create dead letter directory: %w", err)
+	}
+	spool := &deadLetterSpool{
+		directory: cfg.Directory,
+		maxSize:   cfg.MaxSize,
+	}
+	err := filepath.WalkDir(cfg.Directory, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(d.Name(), deadLetterFileExtension) {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		spool.size += info.Size()
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead letter directory: %w", err)
+	}
+	return spool, nil
+}
+
+// spool writes the message to the spool directory unless doing so would exceed the configured maximum size.
+func (s *deadLetterSpool) spool(msg *inboundMessage) error {
+	data, err := msg.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size+int64(len(data)) > s.maxSize {
+		return errDeadLetterSpoolFull
+	}
+	s.seq++
+	name := filepath.Join(s.directory, fmt.Sprintf("%d-%d%s", time.Now().UnixNano(), s.seq, deadLetterFileExtension))
+	// write to a temporary file first so that a partially written message is never replayed
+	tmp := name + ".tmp"
+	if err = os.WriteFile(tmp, data, 0o600); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	if err = os.Rename(tmp, name); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	s.size += int64(len(data))
+	return nil
+}
@@ -0,0 +1,130 @@
+// Copyright The OpenTelemetry Authors
+// SPDX-License-Identifier: Apache-2.0
+
+package solacereceiver // import "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver"
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Azure/go-amqp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestDeadLetterSpool(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "spool")
+	topic := "_telemetry/broker/trace/unknown/v1"
+	msg := &inboundMessage{
+		Data:       [][]byte{{1, 2, 3, 4}},
+		Properties: &amqp.MessageProperties{To: &topic},
+	}
+	data, err := msg.MarshalBinary()
+	require.NoError(t, err)
+
+	spool, err := newDeadLetterSpool(DeadLetter{Directory: dir, MaxSize: int64(2 * len(data))})
+	require.NoError(t, err)
+	require.NoError(t, spool.spool(msg))
+	require.NoError(t, spool.spool(msg))
+	assert.ErrorIs(t, spool.spool(msg), errDeadLetterSpoolFull)
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	// spooled messages can be replayed with their topic
+	raw, err := os.ReadFile(filepath.Join(dir, entries[0].Name()))
+	require.NoError(t, err)
+	replayed := &inboundMessage{}
+	require.NoError(t, replayed.UnmarshalBinary(raw))
+	assert.Equal(t, topic, *replayed.Properties.To)
+	assert.Equal(t, msg.Data, replayed.Data)
+
+	// the size of the existing messages is accounted for on restart
+	spool, err = newDeadLetterSpool(DeadLetter{Directory: dir, MaxSize: int64(2 * len(data))})
+	require.NoError(t, err)
+	assert.ErrorIs(t, spool.spool(msg), errDeadLetterSpoolFull)
+}
+
+func TestReceiveMessageSpoolsUnmarshalErrors(t *testing.T) {
+	dir := t.TempDir()
+	receiver, messagingService, unmarshaller, _ := newReceiver(t)
+	spool, err := newDeadLetterSpool(DeadLetter{Directory: dir, MaxSize: 1024})
+	require.NoError(t, err)
+	receiver.deadLetter = spool
+
+	messagingService.receiveMessageFunc = func(context.Context) (*inboundMessage, error) {
+		return &inboundMessage{Data: [][]byte{{1, 2, 3}}}, nil
+	}
+	messagingService.ackFunc = func(context.Context, *inboundMessage) error {
+		return nil
+	}
+	unmarshaller.unmarshalFunc = func(*inboundMessage) (ptrace.Traces, error) {
+		return ptrace.Traces{}, errUnknownTopic
+	}
+
+	require.NoError(t, receiver.receiveMessage(context.Background(), messagingService))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestReceiveMessageSpoolsDroppedSpans(t *testing.T) {
+	dir := t.TempDir()
+	receiver, messagingService, unmarshaller, _ := newReceiver(t)
+	spool, err := newDeadLetterSpool(DeadLetter{Directory: dir, MaxSize: 1024})
+	require.NoError(t, err)
+	receiver.deadLetter = spool
+	sink := new(consumertest.TracesSink)
+	receiver.nextConsumer = sink
+
+	messagingService.receiveMessageFunc = func(context.Context) (*inboundMessage, error) {
+		return &inboundMessage{Data: [][]byte{{1, 2, 3}}}, nil
+	}
+	messagingService.ackFunc = func(context.Context, *inboundMessage) error {
+		return nil
+	}
+	traces := ptrace.NewTraces()
+	traces.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	unmarshaller.unmarshalFunc = func(*inboundMessage) (ptrace.Traces, error) {
+		return traces, errDroppedSpans
+	}
+
+	require.NoError(t, receiver.receiveMessage(context.Background(), messagingService))
+	// the spans that could be unmarshalled are still forwarded
+	assert.Equal(t, 1, sink.SpanCount())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestReceiveMessageDoesNotSpoolRejectedMessages(t *testing.T) {
+	dir := t.TempDir()
+	receiver, messagingService, unmarshaller, _ := newReceiver(t)
+	spool, err := newDeadLetterSpool(DeadLetter{Directory: dir, MaxSize: 1024})
+	require.NoError(t, err)
+	receiver.deadLetter = spool
+
+	messagingService.receiveMessageFunc = func(context.Context) (*inboundMessage, error) {
+		return &inboundMessage{Data: [][]byte{{1, 2, 3}}}, nil
+	}
+	nacked := false
+	messagingService.nackFunc = func(context.Context, *inboundMessage) error {
+		nacked = true
+		return nil
+	}
+	unmarshaller.unmarshalFunc = func(*inboundMessage) (ptrace.Traces, error) {
+		return ptrace.Traces{}, errUpgradeRequired
+	}
+
+	// the message is redelivered by the broker once the receiver is upgraded, it must not be spooled
+	require.ErrorIs(t, receiver.receiveMessage(context.Background(), messagingService), errUpgradeRequired)
+	assert.True(t, nacked)
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -21,6 +21,8 @@ const (
 	defaultMaxUnacked int32 = 1000
 	// default value for host
 	defaultHost string = "localhost:5671"
+	// default value for the maximum size of the dead letter spool, 100MiB
+	defaultDeadLetterMaxSize int64 = 100 * 1024 * 1024
 )
 
 // NewFactory creates a factory for Solace receiver.
@@ -47,6 +49,12 @@ func createDefaultConfig() component.Config {
 				Delay: 10 * time.Millisecond,
 			},
 		},
+		DeadLetter: DeadLetter{
+			MaxSize: defaultDeadLetterMaxSize,
+		},
+		BrokerSpans: BrokerSpans{
+			Mode: brokerSpansModeFull,
+		},
 	}
 }
 
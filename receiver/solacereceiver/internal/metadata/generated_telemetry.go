@@ -26,10 +26,12 @@ type TelemetryBuilder struct {
 	meter                                                      metric.Meter
 	mu                                                         sync.Mutex
 	registrations                                              []metric.Registration
+	SolacereceiverClockSkewedSpans                             metric.Int64Counter
 	SolacereceiverDroppedEgressSpans                           metric.Int64Counter
 	SolacereceiverDroppedSpanMessages                          metric.Int64Counter
 	SolacereceiverFailedReconnections                          metric.Int64Counter
 	SolacereceiverFatalUnmarshallingErrors                     metric.Int64Counter
+	SolacereceiverHeartbeatReceivedSpanMessages                metric.Int64Gauge
 	SolacereceiverNeedUpgrade                                  metric.Int64Gauge
 	SolacereceiverReceivedSpanMessages                         metric.Int64Counter
 	SolacereceiverReceiverFlowControlRecentRetries             metric.Int64Gauge
@@ -70,6 +72,12 @@ func NewTelemetryBuilder(settings component.TelemetrySettings, options ...Teleme
 	}
 	builder.meter = Meter(settings)
 	var err, errs error
+	builder.SolacereceiverClockSkewedSpans, err = builder.meter.Int64Counter(
+		"otelcol_solacereceiver_clock_skewed_spans",
+		metric.WithDescription("Number of spans whose timestamps were ahead of the receive time by more than the clock skew threshold"),
+		metric.WithUnit("1"),
+	)
+	errs = errors.Join(errs, err)
 	builder.SolacereceiverDroppedEgressSpans, err = builder.meter.Int64Counter(
 		"otelcol_solacereceiver_dropped_egress_spans",
 		metric.WithDescription("Number of dropped egress spans"),
@@ -94,6 +102,12 @@ func NewTelemetryBuilder(settings component.TelemetrySettings, options ...Teleme
 		metric.WithUnit("1"),
 	)
 	errs = errors.Join(errs, err)
+	builder.SolacereceiverHeartbeatReceivedSpanMessages, err = builder.meter.Int64Gauge(
+		"otelcol_solacereceiver_heartbeat_received_span_messages",
+		metric.WithDescription("Number of span messages received since the previous heartbeat, recorded on every heartbeat"),
+		metric.WithUnit("1"),
+	)
+	errs = errors.Join(errs, err)
 	builder.SolacereceiverNeedUpgrade, err = builder.meter.Int64Gauge(
 		"otelcol_solacereceiver_need_upgrade",
 		metric.WithDescription("Indicates with value 1 that receiver requires an upgrade and is not compatible with messages received from a broker"),
@@ -20,10 +20,12 @@ func TestSetupTelemetry(t *testing.T) {
 	tb, err := metadata.NewTelemetryBuilder(testTel.NewTelemetrySettings())
 	require.NoError(t, err)
 	defer tb.Shutdown()
+	tb.SolacereceiverClockSkewedSpans.Add(context.Background(), 1)
 	tb.SolacereceiverDroppedEgressSpans.Add(context.Background(), 1)
 	tb.SolacereceiverDroppedSpanMessages.Add(context.Background(), 1)
 	tb.SolacereceiverFailedReconnections.Add(context.Background(), 1)
 	tb.SolacereceiverFatalUnmarshallingErrors.Add(context.Background(), 1)
+	tb.SolacereceiverHeartbeatReceivedSpanMessages.Record(context.Background(), 1)
 	tb.SolacereceiverNeedUpgrade.Record(context.Background(), 1)
 	tb.SolacereceiverReceivedSpanMessages.Add(context.Background(), 1)
 	tb.SolacereceiverReceiverFlowControlRecentRetries.Record(context.Background(), 1)
@@ -33,6 +35,9 @@ func TestSetupTelemetry(t *testing.T) {
 	tb.SolacereceiverReceiverStatus.Record(context.Background(), 1)
 	tb.SolacereceiverRecoverableUnmarshallingErrors.Add(context.Background(), 1)
 	tb.SolacereceiverReportedSpans.Add(context.Background(), 1)
+	AssertEqualSolacereceiverClockSkewedSpans(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
 	AssertEqualSolacereceiverDroppedEgressSpans(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
@@ -45,6 +50,9 @@ func TestSetupTelemetry(t *testing.T) {
 	AssertEqualSolacereceiverFatalUnmarshallingErrors(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
+	AssertEqualSolacereceiverHeartbeatReceivedSpanMessages(t, testTel,
+		[]metricdata.DataPoint[int64]{{Value: 1}},
+		metricdatatest.IgnoreTimestamp())
 	AssertEqualSolacereceiverNeedUpgrade(t, testTel,
 		[]metricdata.DataPoint[int64]{{Value: 1}},
 		metricdatatest.IgnoreTimestamp())
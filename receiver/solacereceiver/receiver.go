@@ -64,6 +64,12 @@ type solaceTracesReceiver struct {
 	retryTimeout time.Duration
 	// Other Attributes including the ID of the receiver Solace broker's component name
 	metricAttrs attribute.Set
+	// deadLetter is used to spool messages that could not be unmarshalled, nil if disabled
+	deadLetter *deadLetterSpool
+	// connected is used to indicate that the receiver is currently connected to the broker
+	connected atomic.Bool
+	// receivedSinceHeartbeat is the number of span messages received since the last heartbeat
+	receivedSinceHeartbeat atomic.Int64
 }
 
 // newTracesReceiver creates a new solaceTraceReceiver as a receiver.Traces
@@ -91,7 +97,7 @@ func newTracesReceiver(config *Config, set receiver.Settings, nextConsumer consu
 		attribute.String(brokerComponentNameAttr, receiverName),
 	)
 
-	unmarshaller := newTracesUnmarshaller(set.Logger, telemetryBuilder, solaceBrokerAttrs)
+	unmarshaller := newTracesUnmarshaller(set.Logger, telemetryBuilder, solaceBrokerAttrs, config.SpanStatus, config.BrokerSpans)
 
 	return &solaceTracesReceiver{
 		config:            config,
@@ -109,6 +115,13 @@ func newTracesReceiver(config *Config, set receiver.Settings, nextConsumer consu
 
 // Start implements component.Receiver::Start
 func (s *solaceTracesReceiver) Start(ctx context.Context, _ component.Host) error {
+	if s.config.DeadLetter.Directory != "" {
+		spool, err := newDeadLetterSpool(s.config.DeadLetter)
+		if err != nil {
+			return err
+		}
+		s.deadLetter = spool
+	}
 	// set the component name for the connected Solace broker
 	s.telemetryBuilder.SolacereceiverReceiverStatus.Record(ctx, int64(receiverStateStarting), metric.WithAttributeSet(s.metricAttrs))
 	s.telemetryBuilder.SolacereceiverReceiverFlowControlStatus.Record(ctx, int64(flowControlStateClear), metric.WithAttributeSet(s.metricAttrs))
@@ -118,6 +131,10 @@ func (s *solaceTracesReceiver) Start(ctx context.Context, _ component.Host) erro
 	s.settings.Logger.Info("Starting receiver")
 	// start the reconnection loop with a cancellable context and a factory to build new messaging services
 	go s.connectAndReceive(cancelableContext)
+	if s.config.Heartbeat.Interval > 0 {
+		s.shutdownWaitGroup.Add(1)
+		go s.heartbeat(cancelableContext)
+	}
 
 	s.settings.Logger.Info("Receiver successfully started")
 	return nil
@@ -202,6 +219,7 @@ reconnectionLoop:
 // is a best effort without mutex protection and additional state tracking, and in reality if
 // this state transition were to happen, it would be short lived.
 func (s *solaceTracesReceiver) recordConnectionState(state receiverState) {
+	s.connected.Store(state == receiverStateConnected)
 	if !s.terminating.Load() {
 		s.telemetryBuilder.SolacereceiverReceiverStatus.Record(context.Background(), int64(state), metric.WithAttributeSet(s.metricAttrs))
 	}
@@ -230,6 +248,7 @@ func (s *solaceTracesReceiver) receiveMessage(ctx context.Context, service messa
 		s.settings.Logger.Warn("Failed to receive message from messaging service", zap.Error(err))
 		return err // propagate any receive message error up to caller
 	}
+	receiveTime := time.Now()
 	// only set the disposition action after we have received a message successfully
 	disposition := service.accept
 	defer func() { // on return of receiveMessage, we want to either ack or nack the message
@@ -241,8 +260,14 @@ func (s *solaceTracesReceiver) receiveMessage(ctx context.Context, service messa
 	}()
 	// message received successfully
 	s.telemetryBuilder.SolacereceiverReceivedSpanMessages.Add(ctx, 1, metric.WithAttributeSet(s.metricAttrs))
+	s.receivedSinceHeartbeat.Add(1)
 	// unmarshal the message. unmarshalling errors are not fatal unless the version is unknown
 	traces, unmarshalErr := s.unmarshaller.unmarshal(msg)
+	// the spans that could be unmarshalled are still forwarded, the dropped ones are only kept in the spool once acked
+	droppedSpans := errors.Is(unmarshalErr, errDroppedSpans)
+	if droppedSpans {
+		unmarshalErr = nil
+	}
 	if unmarshalErr != nil {
 		s.settings.Logger.Error("Encountered error while unmarshalling message", zap.Error(unmarshalErr))
 		s.telemetryBuilder.SolacereceiverFatalUnmarshallingErrors.Add(ctx, 1, metric.WithAttributeSet(s.metricAttrs))
@@ -250,9 +275,11 @@ func (s *solaceTracesReceiver) receiveMessage(ctx context.Context, service messa
 			disposition = service.failed // if we don't know the version, reject the trace message since we will disable the receiver
 			return unmarshalErr
 		}
+		s.spoolDeadLetter(msg)
 		s.telemetryBuilder.SolacereceiverDroppedSpanMessages.Add(ctx, 1, metric.WithAttributeSet(s.metricAttrs)) // if the error is some other unmarshalling error, we will ack the message and drop the content
 		return nil                                                                                               // don't propagate error, but don't continue forwarding traces
 	}
+	s.handleClockSkew(ctx, traces, receiveTime)
 
 	var flowControlCount int64
 	var spanCount int
@@ -301,9 +328,56 @@ flowControlLoop:
 			s.telemetryBuilder.SolacereceiverReceiverFlowControlWithSingleSuccessfulRetry.Add(ctx, 1, metric.WithAttributeSet(s.metricAttrs))
 		}
 	}
+	if droppedSpans {
+		s.spoolDeadLetter(msg)
+	}
 	return nil
 }
 
+// spoolDeadLetter writes the message to the dead letter spool, if enabled. Only the messages that are acked while their
+// content is dropped are spooled, the rejected messages are redelivered by the broker.
+func (s *solaceTracesReceiver) spoolDeadLetter(msg *inboundMessage) {
+	if s.deadLetter == nil {
+		return
+	}
+	if err := s.deadLetter.spool(msg); err != nil {
+		s.settings.Logger.Warn("Failed to write message to the dead letter spool, message content will be lost", zap.Error(err))
+	}
+}
+
+// heartbeat periodically reports the number of span messages received since the previous heartbeat until ctx is done.
+// A connected receiver that does not receive any message, e.g. because telemetry was disabled on the broker, is
+// otherwise indistinguishable from a healthy idle receiver.
+func (s *solaceTracesReceiver) heartbeat(ctx context.Context) {
+	defer s.shutdownWaitGroup.Done()
+	ticker := time.NewTicker(s.config.Heartbeat.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.recordHeartbeat(ctx)
+		}
+	}
+}
+
+// recordHeartbeat records the number of span messages received since the previous heartbeat and resets it
+func (s *solaceTracesReceiver) recordHeartbeat(ctx context.Context) {
+	received := s.receivedSinceHeartbeat.Swap(0)
+	s.telemetryBuilder.SolacereceiverHeartbeatReceivedSpanMessages.Record(ctx, received, metric.WithAttributeSet(s.metricAttrs))
+	fields := []zap.Field{
+		zap.Int64("received_span_messages", received),
+		zap.Duration("interval", s.config.Heartbeat.Interval),
+		zap.Bool("connected", s.connected.Load()),
+	}
+	if received == 0 && s.connected.Load() {
+		s.settings.Logger.Warn("Receiver heartbeat, no span messages received from the broker since the previous heartbeat although connected, verify that telemetry is enabled on the broker", fields...)
+		return
+	}
+	s.settings.Logger.Info("Receiver heartbeat", fields...)
+}
+
 func sleep(ctx context.Context, d time.Duration) {
 	timer := time.NewTimer(d)
 	select {
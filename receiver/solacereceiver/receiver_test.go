@@ -22,6 +22,9 @@ import (
 	"go.opentelemetry.io/collector/receiver/receivertest"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver/internal/metadatatest"
@@ -295,6 +298,68 @@ func TestReceiverLifecycle(t *testing.T) {
 	}, metricdatatest.IgnoreTimestamp())
 }
 
+func TestReceiverHeartbeat(t *testing.T) {
+	receiver, _, _, tt := newReceiver(t)
+	core, logs := observer.New(zapcore.InfoLevel)
+	receiver.settings.Logger = zap.New(core)
+
+	// messages received while connected are reported and the count is reset
+	receiver.recordConnectionState(receiverStateConnected)
+	receiver.receivedSinceHeartbeat.Add(3)
+	receiver.recordHeartbeat(context.Background())
+	metadatatest.AssertEqualSolacereceiverHeartbeatReceivedSpanMessages(t, tt, []metricdata.DataPoint[int64]{
+		{
+			Value: 3,
+		},
+	}, metricdatatest.IgnoreTimestamp())
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zapcore.InfoLevel, entry.Level)
+	assert.Equal(t, int64(3), entry.ContextMap()["received_span_messages"])
+
+	// no messages received while connected is a silent broker
+	receiver.recordHeartbeat(context.Background())
+	metadatatest.AssertEqualSolacereceiverHeartbeatReceivedSpanMessages(t, tt, []metricdata.DataPoint[int64]{
+		{
+			Value: 0,
+		},
+	}, metricdatatest.IgnoreTimestamp())
+	require.Equal(t, 2, logs.Len())
+	entry = logs.All()[1]
+	assert.Equal(t, zapcore.WarnLevel, entry.Level)
+	assert.Equal(t, int64(0), entry.ContextMap()["received_span_messages"])
+
+	// no messages received while disconnected is expected
+	receiver.recordConnectionState(receiverStateConnecting)
+	receiver.recordHeartbeat(context.Background())
+	require.Equal(t, 3, logs.Len())
+	assert.Equal(t, zapcore.InfoLevel, logs.All()[2].Level)
+}
+
+func TestReceiverHeartbeatLifecycle(t *testing.T) {
+	receiver, messagingService, _, tt := newReceiver(t)
+	receiver.config.Heartbeat.Interval = time.Millisecond
+	messagingService.dialFunc = func(context.Context) error {
+		return nil
+	}
+	messagingService.closeFunc = func(context.Context) {}
+	messagingService.receiveMessageFunc = func(ctx context.Context) (*inboundMessage, error) {
+		<-ctx.Done()
+		return nil, errors.New("some error")
+	}
+	require.NoError(t, receiver.Start(context.Background(), nil))
+	assert.EventuallyWithT(t, func(c *assert.CollectT) {
+		_, err := tt.GetMetric("otelcol_solacereceiver_heartbeat_received_span_messages")
+		assert.NoError(c, err)
+	}, 10*time.Second, time.Millisecond)
+	require.NoError(t, receiver.Shutdown(context.Background()))
+	metadatatest.AssertEqualSolacereceiverHeartbeatReceivedSpanMessages(t, tt, []metricdata.DataPoint[int64]{
+		{
+			Value: 0,
+		},
+	}, metricdatatest.IgnoreTimestamp())
+}
+
 func TestReceiverDialFailureContinue(t *testing.T) {
 	receiver, msgService, _, tt := newReceiver(t)
 	dialErr := errors.New("Some dial error")
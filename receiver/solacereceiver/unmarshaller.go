@@ -16,17 +16,28 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver/internal/metadata"
+	egress_v1 "github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver/internal/model/egress/v1"
 )
 
 // tracesUnmarshaller deserializes the message body.
 type tracesUnmarshaller interface {
 	// unmarshal the amqp-message into traces.
-	// Only valid traces are produced or error is returned
+	// Only valid traces are produced or error is returned, except for errDroppedSpans which is returned along with the
+	// traces of the spans that could be unmarshalled
 	unmarshal(message *inboundMessage) (ptrace.Traces, error)
 }
 
 // newTracesUnmarshaller returns a new unmarshaller ready for message unmarshalling
-func newTracesUnmarshaller(logger *zap.Logger, telemetryBuilder *metadata.TelemetryBuilder, metricAttrs attribute.Set) tracesUnmarshaller {
+func newTracesUnmarshaller(logger *zap.Logger, telemetryBuilder *metadata.TelemetryBuilder, metricAttrs attribute.Set, spanStatus SpanStatus, brokerSpans BrokerSpans) tracesUnmarshaller {
+	var errorSendOutcomes map[egress_v1.SpanData_SendSpan_Outcome]struct{}
+	for _, name := range spanStatus.ErrorSendOutcomes {
+		if outcome, ok := parseSendOutcome(name); ok {
+			if errorSendOutcomes == nil {
+				errorSendOutcomes = make(map[egress_v1.SpanData_SendSpan_Outcome]struct{})
+			}
+			errorSendOutcomes[outcome] = struct{}{}
+		}
+	}
 	return &solaceTracesUnmarshaller{
 		logger:           logger,
 		telemetryBuilder: telemetryBuilder,
@@ -42,9 +53,11 @@ func newTracesUnmarshaller(logger *zap.Logger, telemetryBuilder *metadata.Teleme
 			metricAttrs:      metricAttrs,
 		},
 		egressUnmarshallerV1: &brokerTraceEgressUnmarshallerV1{
-			logger:           logger,
-			telemetryBuilder: telemetryBuilder,
-			metricAttrs:      metricAttrs,
+			logger:            logger,
+			telemetryBuilder:  telemetryBuilder,
+			metricAttrs:       metricAttrs,
+			errorSendOutcomes: errorSendOutcomes,
+			linkOnly:          brokerSpans.Mode == brokerSpansModeLinkOnly,
 		},
 	}
 }
@@ -62,6 +75,7 @@ var (
 	errUpgradeRequired = errors.New("unsupported trace message, upgrade required")
 	errUnknownTopic    = errors.New("unknown topic")
 	errEmptyPayload    = errors.New("no binary attachment")
+	errDroppedSpans    = errors.New("spans of unknown type were dropped")
 )
 
 // unmarshal will unmarshal an *solaceMessage into ptrace.Traces.
@@ -164,6 +178,7 @@ const (
 	hostPortAttrKey                    = "server.port"
 	peerIPAttrKey                      = "network.peer.address"
 	peerPortAttrKey                    = "network.peer.port"
+	operationReasonAttrKey             = "messaging.solace.operation.reason"
 )
 
 // constant attributes
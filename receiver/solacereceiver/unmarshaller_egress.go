@@ -25,6 +25,10 @@ type brokerTraceEgressUnmarshallerV1 struct {
 	logger           *zap.Logger
 	telemetryBuilder *metadata.TelemetryBuilder
 	metricAttrs      attribute.Set // other Otel attributes (to add to the metrics)
+	// errorSendOutcomes are the outcomes setting the status of the send spans to error
+	errorSendOutcomes map[egress_v1.SpanData_SendSpan_Outcome]struct{}
+	// linkOnly emits the delete spans as minimal spans linked to the application spans
+	linkOnly bool
 }
 
 // unmarshal implements tracesUnmarshaller.unmarshal
@@ -34,7 +38,9 @@ func (u *brokerTraceEgressUnmarshallerV1) unmarshal(message *inboundMessage) (pt
 		return ptrace.Traces{}, err
 	}
 	traces := ptrace.NewTraces()
-	u.populateTraces(spanData, traces)
+	if !u.populateTraces(spanData, traces) {
+		return traces, errDroppedSpans
+	}
 	return traces, nil
 }
 
@@ -54,22 +60,28 @@ func (*brokerTraceEgressUnmarshallerV1) unmarshalToSpanData(message *inboundMess
 
 // populateTraces will create a new Span from the given traces and map the given SpanData to the span.
 // This will set all required fields such as name version, trace and span ID, parent span ID (if applicable),
-// timestamps, errors and states.
-func (u *brokerTraceEgressUnmarshallerV1) populateTraces(spanData *egress_v1.SpanData, traces ptrace.Traces) {
+// timestamps, errors and states. Returns false if any of the egress spans was dropped.
+func (u *brokerTraceEgressUnmarshallerV1) populateTraces(spanData *egress_v1.SpanData, traces ptrace.Traces) bool {
 	// Append new resource span and map any attributes
 	resourceSpan := traces.ResourceSpans().AppendEmpty()
 	u.mapResourceSpanAttributes(spanData, resourceSpan.Resource().Attributes())
 	instrLibrarySpans := resourceSpan.ScopeSpans().AppendEmpty()
+	mapped := true
 	for _, egressSpanData := range spanData.EgressSpans {
-		u.mapEgressSpan(egressSpanData, instrLibrarySpans.Spans())
+		if !u.mapEgressSpan(egressSpanData, instrLibrarySpans.Spans()) {
+			mapped = false
+		}
 	}
+	return mapped
 }
 
 func (*brokerTraceEgressUnmarshallerV1) mapResourceSpanAttributes(spanData *egress_v1.SpanData, attrMap pcommon.Map) {
 	setResourceSpanAttributes(attrMap, spanData.RouterName, spanData.SolosVersion, spanData.MessageVpnName)
 }
 
-func (u *brokerTraceEgressUnmarshallerV1) mapEgressSpan(spanData *egress_v1.SpanData_EgressSpan, clientSpans ptrace.SpanSlice) {
+// mapEgressSpan maps the egress span to a new span of clientSpans. Returns false if the span was dropped because its type
+// is unknown.
+func (u *brokerTraceEgressUnmarshallerV1) mapEgressSpan(spanData *egress_v1.SpanData_EgressSpan, clientSpans ptrace.SpanSlice) bool {
 	// at least a support Egress span is found
 	if spanData.GetTypeData() != nil {
 		clientSpan := clientSpans.AppendEmpty()
@@ -88,17 +100,25 @@ func (u *brokerTraceEgressUnmarshallerV1) mapEgressSpan(spanData *egress_v1.Span
 			// unknown span type, drop the span
 			u.logger.Warn(fmt.Sprintf("Received egress span with unknown span type %T, is the collector out of date?", casted))
 			u.telemetryBuilder.SolacereceiverDroppedEgressSpans.Add(context.Background(), 1, metric.WithAttributeSet(u.metricAttrs))
+			clientSpans.RemoveIf(func(span ptrace.Span) bool { return span == clientSpan })
+			return false
 		}
 
 		// map any transaction events found
 		if transactionEvent := spanData.GetTransactionEvent(); transactionEvent != nil {
 			u.mapTransactionEvent(transactionEvent, clientSpan.Events().AppendEmpty())
 		}
-	} else {
-		// malformed/incomplete egress span received, drop the span
-		u.logger.Warn("Received egress span with no span type, could be malformed egress span?")
-		u.telemetryBuilder.SolacereceiverDroppedEgressSpans.Add(context.Background(), 1, metric.WithAttributeSet(u.metricAttrs))
+
+		// the send spans are emitted as is in the link only mode
+		if _, isDelete := spanData.TypeData.(*egress_v1.SpanData_EgressSpan_DeleteSpan); isDelete && u.linkOnly {
+			toLinkOnlySpan(clientSpan)
+		}
+		return true
 	}
+	// malformed/incomplete egress span received, drop the span
+	u.logger.Warn("Received egress span with no span type, could be malformed egress span?")
+	u.telemetryBuilder.SolacereceiverDroppedEgressSpans.Add(context.Background(), 1, metric.WithAttributeSet(u.metricAttrs))
+	return false
 }
 
 func (*brokerTraceEgressUnmarshallerV1) mapEgressSpanCommon(spanData *egress_v1.SpanData_EgressSpan, clientSpan ptrace.Span) {
@@ -204,12 +224,18 @@ func (u *brokerTraceEgressUnmarshallerV1) mapSendSpan(sendSpan *egress_v1.SpanDa
 		outcome = "transaction rollback"
 	}
 	attributes.PutStr(outcomeKey, outcome)
+
+	// the error description reported by the broker takes precedence over the status derived from the outcome
+	if _, isError := u.errorSendOutcomes[sendSpan.Outcome]; isError && span.Status().Code() != ptrace.StatusCodeError {
+		span.Status().SetCode(ptrace.StatusCodeError)
+		span.Status().SetMessage("send outcome: " + outcome)
+	}
 }
 
 func (u *brokerTraceEgressUnmarshallerV1) mapDeleteSpan(deleteSpan *egress_v1.SpanData_DeleteSpan, span ptrace.Span) {
 	const (
 		destinationNameKey       = "messaging.destination.name"
-		deleteOperationReasonKey = "messaging.solace.operation.reason"
+		deleteOperationReasonKey = operationReasonAttrKey
 	)
 	const (
 		spanOperationName     = "delete"
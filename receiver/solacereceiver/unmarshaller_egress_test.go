@@ -17,6 +17,7 @@ import (
 	"go.opentelemetry.io/otel/sdk/metric/metricdata"
 	"go.opentelemetry.io/otel/sdk/metric/metricdata/metricdatatest"
 	"go.uber.org/zap"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver/internal/metadata"
 	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/solacereceiver/internal/metadatatest"
@@ -37,6 +38,25 @@ func TestMsgWithUnknownOneof(t *testing.T) {
 	assert.Nil(t, spanData.EgressSpans[0].GetSendSpan())
 }
 
+func TestEgressUnmarshallerReportsDroppedSpans(t *testing.T) {
+	unmarshallerV1, _ := newTestEgressV1Unmarshaller(t)
+	data, err := proto.Marshal(&egress_v1.SpanData{
+		EgressSpans: []*egress_v1.SpanData_EgressSpan{
+			validEgressSpans[0].in,
+			{
+				TraceId: []byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 8, 7, 6, 5, 4, 3, 2},
+				SpanId:  []byte{1, 2, 3, 4, 5, 6, 7, 8},
+				// no span type the collector knows of
+			},
+		},
+	})
+	require.NoError(t, err)
+	// the span of unknown type is dropped, which is reported so that the message is spooled
+	traces, err := unmarshallerV1.unmarshal(amqp.NewMessage(data))
+	assert.ErrorIs(t, err, errDroppedSpans)
+	assert.Equal(t, 1, traces.SpanCount())
+}
+
 func TestEgressUnmarshallerMapResourceSpan(t *testing.T) {
 	var (
 		routerName = "someRouterName"
@@ -466,6 +486,168 @@ func TestEgressUnmarshallerSendSpanAttributes(t *testing.T) {
 	}
 }
 
+func TestEgressUnmarshallerSendSpanErrorOutcomes(t *testing.T) {
+	errorDescription := "some error"
+	tests := []struct {
+		name              string
+		errorSendOutcomes []string
+		outcome           egress_v1.SpanData_SendSpan_Outcome
+		errorDescription  *string
+		wantCode          ptrace.StatusCode
+		wantMessage       string
+	}{
+		{
+			name:     "Not configured",
+			outcome:  egress_v1.SpanData_SendSpan_REJECTED,
+			wantCode: ptrace.StatusCodeUnset,
+		},
+		{
+			name:              "Rejected",
+			errorSendOutcomes: []string{"REJECTED", "DELIVERY_FAILED"},
+			outcome:           egress_v1.SpanData_SendSpan_REJECTED,
+			wantCode:          ptrace.StatusCodeError,
+			wantMessage:       "send outcome: rejected",
+		},
+		{
+			name:              "Delivery failed",
+			errorSendOutcomes: []string{"REJECTED", "delivery failed"},
+			outcome:           egress_v1.SpanData_SendSpan_DELIVERY_FAILED,
+			wantCode:          ptrace.StatusCodeError,
+			wantMessage:       "send outcome: delivery failed",
+		},
+		{
+			name:              "Flow unbound",
+			errorSendOutcomes: []string{"flow_unbound"},
+			outcome:           egress_v1.SpanData_SendSpan_FLOW_UNBOUND,
+			wantCode:          ptrace.StatusCodeError,
+			wantMessage:       "send outcome: flow unbound",
+		},
+		{
+			name:              "Outcome not listed",
+			errorSendOutcomes: []string{"REJECTED"},
+			outcome:           egress_v1.SpanData_SendSpan_ACCEPTED,
+			wantCode:          ptrace.StatusCodeUnset,
+		},
+		{
+			name:              "Error description takes precedence",
+			errorSendOutcomes: []string{"REJECTED"},
+			outcome:           egress_v1.SpanData_SendSpan_REJECTED,
+			errorDescription:  &errorDescription,
+			wantCode:          ptrace.StatusCodeError,
+			wantMessage:       errorDescription,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			telemetryBuilder, err := metadata.NewTelemetryBuilder(componenttest.NewNopTelemetrySettings())
+			require.NoError(t, err)
+			u := newTracesUnmarshaller(zap.NewNop(), telemetryBuilder, attribute.NewSet(), SpanStatus{ErrorSendOutcomes: tt.errorSendOutcomes}, BrokerSpans{})
+			egress := u.(*solaceTracesUnmarshaller).egressUnmarshallerV1.(*brokerTraceEgressUnmarshallerV1)
+			actual := ptrace.NewSpanSlice()
+			egress.mapEgressSpan(&egress_v1.SpanData_EgressSpan{
+				TraceId:          []byte{1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23, 25, 27, 29, 31},
+				SpanId:           []byte{0, 1, 2, 3, 4, 5, 6, 7},
+				ErrorDescription: tt.errorDescription,
+				TypeData: &egress_v1.SpanData_EgressSpan_SendSpan{
+					SendSpan: &egress_v1.SpanData_SendSpan{
+						Source:  &egress_v1.SpanData_SendSpan_QueueName{QueueName: "someQueue"},
+						Outcome: tt.outcome,
+					},
+				},
+			}, actual)
+			require.Equal(t, 1, actual.Len())
+			assert.Equal(t, tt.wantCode, actual.At(0).Status().Code())
+			assert.Equal(t, tt.wantMessage, actual.At(0).Status().Message())
+		})
+	}
+}
+
+func TestEgressUnmarshallerLinkOnly(t *testing.T) {
+	traceID := []byte{1, 3, 5, 7, 9, 11, 13, 15, 17, 19, 21, 23, 25, 27, 29, 31}
+	parentSpanID := []byte{7, 6, 5, 4, 3, 2, 1, 0}
+	transactionEvent := &egress_v1.SpanData_TransactionEvent{
+		TimeUnixNano: 123456789,
+		Type:         egress_v1.SpanData_TransactionEvent_COMMIT,
+		Initiator:    egress_v1.SpanData_TransactionEvent_CLIENT,
+		TransactionId: &egress_v1.SpanData_TransactionEvent_LocalId{
+			LocalId: &egress_v1.SpanData_TransactionEvent_LocalTransactionId{
+				TransactionId: 12345,
+				SessionId:     67890,
+				SessionName:   "my-session-name",
+			},
+		},
+	}
+	spanData := &egress_v1.SpanData{
+		RouterName:   "someRouterName",
+		SolosVersion: "10.0.0",
+		EgressSpans: []*egress_v1.SpanData_EgressSpan{
+			{
+				TraceId:      traceID,
+				SpanId:       []byte{0, 1, 2, 3, 4, 5, 6, 7},
+				ParentSpanId: parentSpanID,
+				TypeData: &egress_v1.SpanData_EgressSpan_SendSpan{
+					SendSpan: &egress_v1.SpanData_SendSpan{
+						Source:  &egress_v1.SpanData_SendSpan_QueueName{QueueName: "someQueue"},
+						Outcome: egress_v1.SpanData_SendSpan_ACCEPTED,
+					},
+				},
+				TransactionEvent: transactionEvent,
+			},
+			{
+				TraceId:      traceID,
+				SpanId:       []byte{1, 2, 3, 4, 5, 6, 7, 8},
+				ParentSpanId: parentSpanID,
+				TypeData: &egress_v1.SpanData_EgressSpan_DeleteSpan{
+					DeleteSpan: &egress_v1.SpanData_DeleteSpan{
+						EndpointName: &egress_v1.SpanData_DeleteSpan_QueueName{QueueName: "someQueue"},
+						TypeInfo:     &egress_v1.SpanData_DeleteSpan_TtlExpiredInfo{TtlExpiredInfo: &egress_v1.SpanData_TtlExpiredInfo{}},
+					},
+				},
+				TransactionEvent: transactionEvent,
+			},
+		},
+	}
+	unmarshal := func(mode string) ptrace.SpanSlice {
+		telemetryBuilder, err := metadata.NewTelemetryBuilder(componenttest.NewNopTelemetrySettings())
+		require.NoError(t, err)
+		u := newTracesUnmarshaller(zap.NewNop(), telemetryBuilder, attribute.NewSet(), SpanStatus{}, BrokerSpans{Mode: mode})
+		egress := u.(*solaceTracesUnmarshaller).egressUnmarshallerV1.(*brokerTraceEgressUnmarshallerV1)
+		traces := ptrace.NewTraces()
+		egress.populateTraces(spanData, traces)
+		spans := traces.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+		require.Equal(t, 2, spans.Len())
+		return spans
+	}
+	full := unmarshal(brokerSpansModeFull)
+	linkOnly := unmarshal(brokerSpansModeLinkOnly)
+
+	// the send spans are emitted as is
+	assert.Equal(t, full.At(0), linkOnly.At(0))
+
+	// the delete spans are minimal spans of their own traces, linked to the application spans
+	deleteSpan := linkOnly.At(1)
+	assert.Equal(t, "someQueue delete", deleteSpan.Name())
+	assert.Equal(t, full.At(1).SpanID(), deleteSpan.SpanID())
+	assert.NotEqual(t, full.At(1).TraceID(), deleteSpan.TraceID())
+	assert.True(t, deleteSpan.ParentSpanID().IsEmpty())
+	require.Equal(t, 1, deleteSpan.Links().Len())
+	assert.Equal(t, full.At(1).TraceID(), deleteSpan.Links().At(0).TraceID())
+	assert.Equal(t, full.At(1).ParentSpanID(), deleteSpan.Links().At(0).SpanID())
+	assert.Equal(t, 0, deleteSpan.Events().Len())
+	assert.Equal(t, map[string]any{
+		"messaging.system":                         "SolacePubSub+",
+		"messaging.operation.name":                 "delete",
+		"messaging.operation.type":                 "delete",
+		"messaging.destination.name":               "someQueue",
+		"messaging.solace.operation.reason":        "ttl_expired",
+		"messaging.solace.transaction_event.name":  "commit",
+		"messaging.solace.transaction_initiator":   "client",
+		"messaging.solace.transaction_id":          int64(12345),
+		"messaging.solace.transacted_session_name": "my-session-name",
+		"messaging.solace.transacted_session_id":   int64(67890),
+	}, deleteSpan.Attributes().AsRaw())
+}
+
 func TestEgressUnmarshallerDeleteSpanAttributes(t *testing.T) {
 	// creates a base attribute map that additional data can be added to
 	// does not include outcome or source. Attributes will override all fields in base
@@ -916,5 +1098,5 @@ func newTestEgressV1Unmarshaller(t *testing.T) (*brokerTraceEgressUnmarshallerV1
 	builder, err := metadata.NewTelemetryBuilder(tt.NewTelemetrySettings())
 	require.NoError(t, err)
 	metricAttr := attribute.NewSet(attribute.String("receiver_name", ""))
-	return &brokerTraceEgressUnmarshallerV1{zap.NewNop(), builder, metricAttr}, tt
+	return &brokerTraceEgressUnmarshallerV1{logger: zap.NewNop(), telemetryBuilder: builder, metricAttrs: metricAttr}, tt
 }
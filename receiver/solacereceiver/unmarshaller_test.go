@@ -324,7 +324,7 @@ func TestSolaceMessageUnmarshallerUnmarshal(t *testing.T) {
 			telemetryBuilder, err := metadata.NewTelemetryBuilder(componenttest.NewNopTelemetrySettings())
 			require.NoError(t, err)
 			metricAttr := attribute.NewSet(attribute.String("receiver_name", metadata.Type.String()))
-			u := newTracesUnmarshaller(zap.NewNop(), telemetryBuilder, metricAttr)
+			u := newTracesUnmarshaller(zap.NewNop(), telemetryBuilder, metricAttr, SpanStatus{}, BrokerSpans{})
 			traces, err := u.unmarshal(tt.message)
 			if tt.err != nil {
 				assert.ErrorContains(t, err, tt.err.Error())